@@ -0,0 +1,474 @@
+// Package stats holds the statistics behind the health analyzers: t-tests,
+// regression, correlation, effect sizes, robust location and spread, and
+// running summaries of long series.
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// WelchTTest runs Welch's unequal-variance t-test on two samples and returns
+// the t statistic, the Welch-Satterthwaite degrees of freedom, and the
+// two-sided p-value. Samples with fewer than two values yield p = 1.
+func WelchTTest(a, b []float64) (t, df, p float64) {
+	if len(a) < 2 || len(b) < 2 {
+		return 0, 0, 1
+	}
+
+	meanA, varA := SampleMeanVariance(a)
+	meanB, varB := SampleMeanVariance(b)
+	nA, nB := float64(len(a)), float64(len(b))
+
+	seA := varA / nA
+	seB := varB / nB
+	se := math.Sqrt(seA + seB)
+	if se == 0 {
+		if meanA == meanB {
+			return 0, nA + nB - 2, 1
+		}
+		return math.Inf(sign(meanB - meanA)), nA + nB - 2, 0
+	}
+
+	t = (meanB - meanA) / se
+	df = (seA + seB) * (seA + seB) / (seA*seA/(nA-1) + seB*seB/(nB-1))
+	p = studentTTwoSidedP(t, df)
+	return t, df, p
+}
+
+// SampleMeanVariance returns the mean and unbiased sample variance
+func SampleMeanVariance(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	sumSquares := 0.0
+	for _, v := range values {
+		sumSquares += (v - mean) * (v - mean)
+	}
+	return mean, sumSquares / float64(len(values)-1)
+}
+
+// studentTTwoSidedP returns P(|T| >= |t|) for Student's t with df degrees of freedom
+func studentTTwoSidedP(t, df float64) float64 {
+	if df <= 0 || math.IsNaN(t) {
+		return 1
+	}
+	x := df / (df + t*t)
+	return regularizedIncompleteBeta(x, df/2, 0.5)
+}
+
+// regularizedIncompleteBeta computes I_x(a, b) using the continued fraction
+// expansion (Numerical Recipes betai/betacf)
+func regularizedIncompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	lgA, _ := math.Lgamma(a)
+	lgB, _ := math.Lgamma(b)
+	lgAB, _ := math.Lgamma(a + b)
+	front := math.Exp(lgAB - lgA - lgB + a*math.Log(x) + b*math.Log(1-x))
+
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b) / a
+	}
+	return 1 - front*betaContinuedFraction(1-x, b, a)/b
+}
+
+// betaContinuedFraction evaluates the continued fraction for the incomplete beta function
+func betaContinuedFraction(x, a, b float64) float64 {
+	const (
+		maxIterations = 200
+		epsilon       = 3e-14
+		tiny          = 1e-300
+	)
+
+	qab := a + b
+	qap := a + 1
+	qam := a - 1
+	c := 1.0
+	d := 1 - qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+
+		if math.Abs(del-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
+
+// sign returns 1 for non-negative values and -1 otherwise
+func sign(v float64) int {
+	if v < 0 {
+		return -1
+	}
+	return 1
+}
+
+// LinearRegression fits y = intercept + slope*x by ordinary least squares and
+// returns the slope, intercept, and coefficient of determination
+func LinearRegression(xs, ys []float64) (slope, intercept, rSquared float64) {
+	n := len(xs)
+	if n < 2 || n != len(ys) {
+		return 0, 0, 0
+	}
+
+	meanX, _ := SampleMeanVariance(xs)
+	meanY, _ := SampleMeanVariance(ys)
+
+	var sxx, sxy, syy float64
+	for i := range xs {
+		dx := xs[i] - meanX
+		dy := ys[i] - meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 {
+		return 0, meanY, 0
+	}
+
+	slope = sxy / sxx
+	intercept = meanY - slope*meanX
+	if syy > 0 {
+		rSquared = sxy * sxy / (sxx * syy)
+	}
+	return slope, intercept, rSquared
+}
+
+// MultipleLinearRegression fits y = b0 + b1*x1 + ... by ordinary least squares.
+// Each row holds one observation's predictors without the intercept term. It
+// returns the coefficients (intercept first), the residual standard deviation,
+// and false when there are too few observations or the predictors are collinear.
+func MultipleLinearRegression(rows [][]float64, ys []float64) ([]float64, float64, bool) {
+	if len(rows) == 0 || len(rows) != len(ys) {
+		return nil, 0, false
+	}
+	k := len(rows[0]) + 1
+	if len(rows) <= k {
+		return nil, 0, false
+	}
+
+	// Normal equations (XᵀX)β = Xᵀy as an augmented matrix
+	matrix := make([][]float64, k)
+	for i := range matrix {
+		matrix[i] = make([]float64, k+1)
+	}
+	for r, row := range rows {
+		x := append([]float64{1}, row...)
+		for i := 0; i < k; i++ {
+			for j := 0; j < k; j++ {
+				matrix[i][j] += x[i] * x[j]
+			}
+			matrix[i][k] += x[i] * ys[r]
+		}
+	}
+
+	// Gaussian elimination with partial pivoting
+	for col := 0; col < k; col++ {
+		pivot := col
+		for r := col + 1; r < k; r++ {
+			if math.Abs(matrix[r][col]) > math.Abs(matrix[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(matrix[pivot][col]) < 1e-10 {
+			return nil, 0, false
+		}
+		matrix[col], matrix[pivot] = matrix[pivot], matrix[col]
+		for r := col + 1; r < k; r++ {
+			factor := matrix[r][col] / matrix[col][col]
+			for c := col; c <= k; c++ {
+				matrix[r][c] -= factor * matrix[col][c]
+			}
+		}
+	}
+	coefficients := make([]float64, k)
+	for i := k - 1; i >= 0; i-- {
+		sum := matrix[i][k]
+		for j := i + 1; j < k; j++ {
+			sum -= matrix[i][j] * coefficients[j]
+		}
+		coefficients[i] = sum / matrix[i][i]
+	}
+
+	var ssResidual float64
+	for r, row := range rows {
+		residual := ys[r] - PredictLinear(coefficients, row)
+		ssResidual += residual * residual
+	}
+	return coefficients, math.Sqrt(ssResidual / float64(len(rows)-k)), true
+}
+
+// PredictLinear evaluates a model from MultipleLinearRegression for one row
+func PredictLinear(coefficients, row []float64) float64 {
+	prediction := coefficients[0]
+	for i, x := range row {
+		prediction += coefficients[i+1] * x
+	}
+	return prediction
+}
+
+// RegressionSlopeCI returns the 95% confidence interval for the least-squares slope
+func RegressionSlopeCI(xs, ys []float64) (float64, float64) {
+	n := len(xs)
+	slope, _, rSquared := LinearRegression(xs, ys)
+	if n < 3 || n != len(ys) {
+		return math.Inf(-1), math.Inf(1)
+	}
+
+	meanX, _ := SampleMeanVariance(xs)
+	_, varY := SampleMeanVariance(ys)
+	var sxx float64
+	for _, x := range xs {
+		sxx += (x - meanX) * (x - meanX)
+	}
+	if sxx == 0 {
+		return math.Inf(-1), math.Inf(1)
+	}
+
+	syy := varY * float64(n-1)
+	residualVariance := syy * (1 - rSquared) / float64(n-2)
+	se := math.Sqrt(residualVariance / sxx)
+	margin := studentTCritical(float64(n-2)) * se
+	return slope - margin, slope + margin
+}
+
+// studentTCritical returns the two-sided 95% critical value of Student's t
+func studentTCritical(df float64) float64 {
+	lo, hi := 0.0, 1000.0
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if studentTTwoSidedP(mid, df) > 0.05 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// PearsonCorrelation returns Pearson's r for paired samples
+func PearsonCorrelation(xs, ys []float64) float64 {
+	if len(xs) < 2 || len(xs) != len(ys) {
+		return 0
+	}
+	meanX, _ := SampleMeanVariance(xs)
+	meanY, _ := SampleMeanVariance(ys)
+
+	var sxy, sxx, syy float64
+	for i := range xs {
+		dx := xs[i] - meanX
+		dy := ys[i] - meanY
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+// SpearmanCorrelation returns Spearman's rho (Pearson's r on tie-averaged ranks)
+func SpearmanCorrelation(xs, ys []float64) float64 {
+	return PearsonCorrelation(ranks(xs), ranks(ys))
+}
+
+// ranks assigns 1-based ranks, averaging ties
+func ranks(values []float64) []float64 {
+	indices := make([]int, len(values))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.Slice(indices, func(a, b int) bool {
+		return values[indices[a]] < values[indices[b]]
+	})
+
+	result := make([]float64, len(values))
+	for i := 0; i < len(indices); {
+		j := i
+		for j+1 < len(indices) && values[indices[j+1]] == values[indices[i]] {
+			j++
+		}
+		rank := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			result[indices[k]] = rank
+		}
+		i = j + 1
+	}
+	return result
+}
+
+// CorrelationPValue tests r against zero using the t distribution with n-2 df
+func CorrelationPValue(r float64, n int) float64 {
+	if n < 3 {
+		return 1
+	}
+	if math.Abs(r) >= 1 {
+		return 0
+	}
+	t := r * math.Sqrt(float64(n-2)/(1-r*r))
+	return studentTTwoSidedP(t, float64(n-2))
+}
+
+// CorrelationCI returns the 95% confidence interval for r via the Fisher z-transform
+func CorrelationCI(r float64, n int) (float64, float64) {
+	if n < 4 {
+		return -1, 1
+	}
+	r = math.Max(math.Min(r, 0.999999), -0.999999)
+	z := math.Atanh(r)
+	se := 1 / math.Sqrt(float64(n-3))
+	return math.Tanh(z - 1.96*se), math.Tanh(z + 1.96*se)
+}
+
+// CohensD returns the standardized mean difference (b - a) using the pooled
+// standard deviation, or 0 when either sample has fewer than two values
+func CohensD(a, b []float64) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return 0
+	}
+	meanA, varA := SampleMeanVariance(a)
+	meanB, varB := SampleMeanVariance(b)
+	nA, nB := float64(len(a)), float64(len(b))
+	pooled := math.Sqrt(((nA-1)*varA + (nB-1)*varB) / (nA + nB - 2))
+	if pooled == 0 {
+		return 0
+	}
+	return (meanB - meanA) / pooled
+}
+
+// EffectSizeMagnitude labels |d| using Cohen's conventional thresholds
+func EffectSizeMagnitude(d float64) string {
+	switch a := math.Abs(d); {
+	case a < 0.2:
+		return "negligible"
+	case a < 0.5:
+		return "small"
+	case a < 0.8:
+		return "medium"
+	default:
+		return "large"
+	}
+}
+
+// Median returns the middle value of an unsorted slice
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// MedianAbsoluteDeviation returns the Median of absolute deviations from the Median
+func MedianAbsoluteDeviation(values []float64) float64 {
+	center := Median(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - center)
+	}
+	return Median(deviations)
+}
+
+// Running accumulates the count, mean, variance, and range of a series one
+// value at a time (Welford's algorithm), so long series can be summarized
+// without holding them in memory. The zero value is an empty series.
+type Running struct {
+	n        int
+	mean, m2 float64
+	min, max float64
+}
+
+// Add includes v in the series
+func (r *Running) Add(v float64) {
+	r.n++
+	if r.n == 1 {
+		r.min, r.max = v, v
+	} else {
+		r.min = math.Min(r.min, v)
+		r.max = math.Max(r.max, v)
+	}
+	delta := v - r.mean
+	r.mean += delta / float64(r.n)
+	r.m2 += delta * (v - r.mean)
+}
+
+// Count returns how many values were added
+func (r *Running) Count() int {
+	return r.n
+}
+
+// Mean returns the mean, or 0 for an empty series
+func (r *Running) Mean() float64 {
+	return r.mean
+}
+
+// Variance returns the unbiased sample variance, or 0 for fewer than two values
+func (r *Running) Variance() float64 {
+	if r.n < 2 {
+		return 0
+	}
+	return r.m2 / float64(r.n-1)
+}
+
+// Min returns the smallest value, or 0 for an empty series
+func (r *Running) Min() float64 {
+	return r.min
+}
+
+// Max returns the largest value, or 0 for an empty series
+func (r *Running) Max() float64 {
+	return r.max
+}
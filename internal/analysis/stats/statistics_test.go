@@ -0,0 +1,131 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWelchTTest(t *testing.T) {
+	a := []float64{19.8, 20.4, 19.6, 17.8, 18.5, 18.9, 18.3, 18.9, 19.5, 22.0}
+	b := []float64{28.2, 26.6, 20.1, 23.3, 25.2, 22.1, 17.7, 27.6, 20.6, 13.7, 23.2, 17.5, 20.6, 18.0, 23.9, 21.6, 24.3, 20.4, 24.0, 13.2}
+
+	tStat, df, p := WelchTTest(a, b)
+
+	// Reference values: Welch's t-test of b against a, p from numerically integrating the t density
+	if math.Abs(tStat-2.2192) > 0.001 {
+		t.Errorf("t = %.4f, want 2.2192", tStat)
+	}
+	if math.Abs(df-24.50) > 0.01 {
+		t.Errorf("df = %.2f, want 24.50", df)
+	}
+	if math.Abs(p-0.0360) > 0.0005 {
+		t.Errorf("p = %.4f, want 0.0360", p)
+	}
+}
+
+func TestWelchTTest_SmallSamples(t *testing.T) {
+	if _, _, p := WelchTTest([]float64{1}, []float64{2, 3}); p != 1 {
+		t.Errorf("Expected p = 1 for a single-value sample, got %v", p)
+	}
+}
+
+func TestStudentTCritical(t *testing.T) {
+	cases := map[float64]float64{1: 12.706, 10: 2.228, 30: 2.042}
+	for df, want := range cases {
+		if got := studentTCritical(df); math.Abs(got-want) > 0.001 {
+			t.Errorf("studentTCritical(%v) = %.3f, want %.3f", df, got, want)
+		}
+	}
+}
+
+func TestRegressionSlopeCI(t *testing.T) {
+	xs := []float64{1, 2, 3, 4, 5}
+	ys := []float64{2, 4, 5, 4, 5}
+
+	// slope 0.6, residual SE of slope sqrt(2.4/3/10), t(3) = 3.182
+	lo, hi := RegressionSlopeCI(xs, ys)
+	if math.Abs(lo-(-0.3001)) > 0.001 || math.Abs(hi-1.5001) > 0.001 {
+		t.Errorf("CI = [%.4f, %.4f], want [-0.3001, 1.5001]", lo, hi)
+	}
+}
+
+func TestMultipleLinearRegression(t *testing.T) {
+	rows := [][]float64{{1, 2}, {2, 1}, {3, 4}, {4, 3}, {5, 6}, {6, 5}}
+	var ys []float64
+	for i, row := range rows {
+		noise := []float64{0.1, -0.1, -0.1, 0.1, 0.1, -0.1}[i]
+		ys = append(ys, 3+2*row[0]-row[1]+noise)
+	}
+
+	coefficients, residualSD, ok := MultipleLinearRegression(rows, ys)
+	if !ok {
+		t.Fatal("Expected a fit")
+	}
+	want := []float64{3, 2, -1}
+	for i := range want {
+		if math.Abs(coefficients[i]-want[i]) > 0.1 {
+			t.Errorf("coefficient %d = %.3f, want %.1f", i, coefficients[i], want[i])
+		}
+	}
+	if residualSD > 0.2 {
+		t.Errorf("residual SD = %.3f, want < 0.2", residualSD)
+	}
+
+	if _, _, ok := MultipleLinearRegression([][]float64{{1, 2}, {2, 4}, {3, 6}, {4, 8}}, []float64{1, 2, 3, 4}); ok {
+		t.Error("Expected collinear predictors to fail")
+	}
+}
+
+func TestCohensD(t *testing.T) {
+	a := []float64{1, 2, 3, 4, 5}
+	b := []float64{3, 4, 5, 6, 7}
+	// Pooled SD is sqrt(2.5), so d = 2 / 1.581
+	if d := CohensD(a, b); math.Abs(d-1.2649) > 1e-3 {
+		t.Errorf("CohensD() = %.4f, want 1.2649", d)
+	}
+	if got := EffectSizeMagnitude(-0.6); got != "medium" {
+		t.Errorf("EffectSizeMagnitude(-0.6) = %s, want medium", got)
+	}
+}
+
+func TestSpearmanHandlesTies(t *testing.T) {
+	got := ranks([]float64{10, 20, 20, 30})
+	want := []float64{1, 2.5, 2.5, 4}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ranks() = %v, want %v", got, want)
+		}
+	}
+
+	// A monotonic but non-linear relationship is perfectly rank-correlated
+	xs := []float64{1, 2, 3, 4, 5, 6}
+	ys := []float64{1, 4, 9, 16, 25, 36}
+	if rho := SpearmanCorrelation(xs, ys); math.Abs(rho-1) > 1e-9 {
+		t.Errorf("SpearmanCorrelation() = %v, want 1", rho)
+	}
+}
+
+func TestRunning(t *testing.T) {
+	values := []float64{62, 48.5, 71, 55, 80.25, 39, 66}
+	var running Running
+	for _, v := range values {
+		running.Add(v)
+	}
+	mean, variance := SampleMeanVariance(values)
+	if running.Count() != len(values) || math.Abs(running.Mean()-mean) > 1e-9 || math.Abs(running.Variance()-variance) > 1e-9 {
+		t.Errorf("Running = %d values, mean %.4f, variance %.4f; want %d, %.4f, %.4f",
+			running.Count(), running.Mean(), running.Variance(), len(values), mean, variance)
+	}
+	if running.Min() != 39 || running.Max() != 80.25 {
+		t.Errorf("range = %v-%v, want 39-80.25", running.Min(), running.Max())
+	}
+
+	var empty Running
+	if empty.Count() != 0 || empty.Mean() != 0 || empty.Variance() != 0 {
+		t.Errorf("empty Running = %+v", empty)
+	}
+	empty.Add(-3)
+	if empty.Min() != -3 || empty.Max() != -3 || empty.Variance() != 0 {
+		t.Errorf("single value Running = %+v", empty)
+	}
+}
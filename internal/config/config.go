@@ -0,0 +1,280 @@
+// Package config resolves the server's settings from the config file, the
+// environment, and command-line flags into a Config the server reads them
+// from by their WHOOP_* names.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"whoop-mcp/internal/whoop"
+)
+
+// Config sources, lowest precedence first
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+	SourceOption  = "option"
+)
+
+// Config holds the settings that can come from the config file, the
+// environment, or command-line flags. Precedence, highest first: options set
+// by an embedding program, flags, the process environment, .env, the config
+// file, then the defaults below. The server looks the resolved values up
+// with Getenv, so every command sees the same settings without them being
+// written back to the environment. Secrets (tokens, client secrets,
+// passphrases) are deliberately not file settings; keep them in the
+// environment, .env, or the keychain.
+type Config struct {
+	APIBaseURL        string `yaml:"api_base_url" env:"WHOOP_API_BASE_URL" help:"Whoop API base URL"`
+	RateLimit         int    `yaml:"rate_limit" env:"WHOOP_RATE_LIMIT" help:"Whoop API requests per minute, 1-1000"`
+	RequestTimeout    int    `yaml:"request_timeout" env:"WHOOP_REQUEST_TIMEOUT" help:"Whoop API request timeout in seconds, 1-600"`
+	TokenStore        string `yaml:"token_store" env:"WHOOP_TOKEN_STORE" help:"Where OAuth tokens are kept: env, keyring, or file"`
+	DataDir           string `yaml:"data_dir" env:"WHOOP_DATA_DIR" help:"Directory for the local datastore"`
+	ReportsDir        string `yaml:"reports_dir" env:"WHOOP_REPORTS_DIR" help:"Directory for scheduled reports"`
+	TemplateDir       string `yaml:"template_dir" env:"WHOOP_TEMPLATE_DIR" help:"Directory of report template overrides"`
+	Locale            string `yaml:"locale" env:"WHOOP_LOCALE" help:"Language of the reports that take a locale argument"`
+	Units             string `yaml:"units" env:"WHOOP_UNITS" help:"Measurement units: metric or imperial"`
+	Timezone          string `yaml:"timezone" env:"WHOOP_TIMEZONE" help:"IANA timezone for date arguments"`
+	SafetySeverity    string `yaml:"safety_severity" env:"WHOOP_SAFETY_SEVERITY" help:"Lowest red flag severity that carries a safety notice"`
+	NotifySeverity    string `yaml:"notify_severity" env:"WHOOP_NOTIFY_SEVERITY" help:"Lowest red flag severity that sends a notification"`
+	RetentionMonths   int    `yaml:"retention_months" env:"WHOOP_RETENTION_MONTHS" help:"Months of journal entries to keep in full (0 keeps everything)"`
+	Offline           string `yaml:"offline" env:"WHOOP_OFFLINE" help:"Serve fixture data instead of calling the Whoop API: true or false"`
+	FixturesDir       string `yaml:"fixtures_dir" env:"WHOOP_FIXTURES_DIR" help:"Directory of offline fixtures (default: the bundled demo data)"`
+	OfflineScenario   string `yaml:"offline_scenario" env:"WHOOP_OFFLINE_SCENARIO" help:"Generated dataset to serve offline: steady, stress, illness, travel, training, or demo"`
+	StrictDecode      string `yaml:"strict_decode" env:"WHOOP_STRICT_DECODE" help:"Reject Whoop API responses with fields the server does not know: true or false"`
+	ToolRateLimit     int    `yaml:"tool_rate_limit" env:"WHOOP_TOOL_RATE_LIMIT" help:"Calls per minute allowed for each tool, 1-1000 (0 leaves tools unlimited)"`
+	ToolCacheTTL      int    `yaml:"tool_cache_ttl" env:"WHOOP_TOOL_CACHE_TTL" help:"Seconds to reuse the result of an identical tool call (0 disables the cache)"`
+	FetchWorkers      int    `yaml:"fetch_workers" env:"WHOOP_FETCH_WORKERS" help:"Whoop fetches allowed to run at once across all tool calls, 1-64 (default 4)"`
+	FetchesPerRequest int    `yaml:"fetches_per_request" env:"WHOOP_FETCHES_PER_REQUEST" help:"Whoop fetches one tool call may run at once, 1-64 (default 4)"`
+
+	// Path is the config file that was read; empty when there was none
+	Path string `yaml:"-"`
+	// Sources records where each setting came from, keyed by environment variable
+	Sources map[string]string `yaml:"-"`
+
+	// options holds variables set with Set that are not Config settings
+	options map[string]string
+}
+
+// Default returns the built-in defaults. Settings left empty fall back
+// to the defaults of the code that reads them.
+func Default() *Config {
+	return &Config{
+		APIBaseURL:     whoop.APIBaseURL,
+		RateLimit:      100,
+		RequestTimeout: 30,
+		Sources:        make(map[string]string),
+	}
+}
+
+// Path returns the config file to read: WHOOP_CONFIG, or config.yaml in
+// the whoop-mcp config directory. explicit reports whether it was configured.
+func Path() (path string, explicit bool, err error) {
+	if path = strings.TrimSpace(os.Getenv("WHOOP_CONFIG")); path != "" {
+		return path, true, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, "whoop-mcp", "config.yaml"), false, nil
+}
+
+// Load layers the config file at path over the defaults, then the
+// environment over both. A missing file is only an error when explicit.
+func Load(path string, explicit bool) (*Config, error) {
+	config := Default()
+	for _, field := range Fields() {
+		if !config.value(field).IsZero() {
+			config.Sources[field.Env] = SourceDefault
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := config.decodeFile(data); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		config.Path = path
+	case errors.Is(err, fs.ErrNotExist) && !explicit:
+	default:
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	for _, field := range Fields() {
+		if raw, ok := os.LookupEnv(field.Env); ok && raw != "" {
+			if err := config.set(field, raw, SourceEnv); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return config, nil
+}
+
+// decodeFile reads YAML settings, rejecting unknown keys so typos surface
+func (c *Config) decodeFile(data []byte) error {
+	var fromFile Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&fromFile); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	for _, field := range Fields() {
+		if value := reflect.ValueOf(fromFile).FieldByIndex(field.Index); !value.IsZero() {
+			c.value(field).Set(value)
+			c.Sources[field.Env] = SourceFile
+		}
+	}
+	return nil
+}
+
+// RegisterFlags adds a flag for every setting, named after its config
+// file key with dashes (rate_limit becomes --rate-limit)
+func RegisterFlags(flags *flag.FlagSet) {
+	for _, field := range Fields() {
+		flags.String(field.Flag, "", field.Help+" (overrides "+field.Env+")")
+	}
+}
+
+// ApplyFlags layers the flags that were set on the command line over the config
+func (c *Config) ApplyFlags(flags *flag.FlagSet) error {
+	set := make(map[string]string)
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = f.Value.String() })
+	for _, field := range Fields() {
+		if raw, ok := set[field.Flag]; ok {
+			if err := c.set(field, raw, SourceFlag); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Set overrides the variable name over every other source. Names that are
+// not Config settings, such as credentials, are kept for Getenv alone.
+func (c *Config) Set(name, value string) error {
+	for _, field := range Fields() {
+		if field.Env == name {
+			return c.set(field, value, SourceOption)
+		}
+	}
+	if c.options == nil {
+		c.options = make(map[string]string)
+	}
+	c.options[name] = value
+	return nil
+}
+
+// Getenv returns the variable name as the server should see it: the
+// resolved value of a setting, including one a flag set back to zero, a
+// value from Set, or else the process environment. An unresolved setting
+// reads as empty, so the code reading it falls back to its own default.
+func (c *Config) Getenv(name string) string {
+	for _, field := range Fields() {
+		if field.Env != name {
+			continue
+		}
+		if _, resolved := c.Sources[name]; !resolved {
+			return ""
+		}
+		return fmt.Sprint(c.value(field).Interface())
+	}
+	if value, ok := c.options[name]; ok {
+		return value
+	}
+	return os.Getenv(name)
+}
+
+// set parses raw into a setting and records its source
+func (c *Config) set(field Field, raw, source string) error {
+	raw = strings.TrimSpace(raw)
+	value := c.value(field)
+	switch value.Kind() {
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q from %s (expected a whole number)", field.Env, raw, source)
+		}
+		value.SetInt(int64(n))
+	default:
+		value.SetString(raw)
+	}
+	c.Sources[field.Env] = source
+	return nil
+}
+
+func (c *Config) value(field Field) reflect.Value {
+	return reflect.ValueOf(c).Elem().FieldByIndex(field.Index)
+}
+
+// Field describes one setting, from the Config struct tags
+type Field struct {
+	Index []int  // of the Config struct field
+	Key   string // config file key
+	Env   string // environment variable
+	Flag  string // command-line flag
+	Help  string
+}
+
+// Fields lists the settings in declaration order
+func Fields() []Field {
+	configType := reflect.TypeOf(Config{})
+	fields := make([]Field, 0, configType.NumField())
+	for i := 0; i < configType.NumField(); i++ {
+		structField := configType.Field(i)
+		env := structField.Tag.Get("env")
+		if env == "" {
+			continue
+		}
+		key := structField.Tag.Get("yaml")
+		fields = append(fields, Field{
+			Index: structField.Index,
+			Key:   key,
+			Env:   env,
+			Flag:  strings.ReplaceAll(key, "_", "-"),
+			Help:  structField.Tag.Get("help"),
+		})
+	}
+	return fields
+}
+
+// Resolve loads the config file (path, or WHOOP_CONFIG and the default
+// location when empty) and the environment, and layers any flags on top
+func Resolve(path string, flags *flag.FlagSet) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		var err error
+		if path, explicit, err = Path(); err != nil {
+			return nil, err
+		}
+	}
+	config, err := Load(path, explicit)
+	if err != nil {
+		return nil, err
+	}
+	if flags != nil {
+		if err := config.ApplyFlags(flags); err != nil {
+			return nil, err
+		}
+	}
+	if config.Path != "" {
+		log.Printf("Loaded configuration from %s", config.Path)
+	}
+	return config, nil
+}
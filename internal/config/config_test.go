@@ -0,0 +1,132 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// clearEnv unsets every config variable for the duration of a test
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, field := range Fields() {
+		t.Setenv(field.Env, "")
+		os.Unsetenv(field.Env)
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfig_Precedence(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, "rate_limit: 60\nlocale: es\nunits: imperial\n")
+	t.Setenv("WHOOP_LOCALE", "en")
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	RegisterFlags(flags)
+	if err := flags.Parse([]string{"--units", "metric"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	config, err := Load(path, true)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := config.ApplyFlags(flags); err != nil {
+		t.Fatalf("ApplyFlags() error = %v", err)
+	}
+
+	if config.RateLimit != 60 || config.Sources["WHOOP_RATE_LIMIT"] != SourceFile {
+		t.Errorf("RateLimit = %d from %s, want 60 from the file", config.RateLimit, config.Sources["WHOOP_RATE_LIMIT"])
+	}
+	if config.Locale != "en" || config.Sources["WHOOP_LOCALE"] != SourceEnv {
+		t.Errorf("Locale = %q from %s, want en from the environment", config.Locale, config.Sources["WHOOP_LOCALE"])
+	}
+	if config.Units != "metric" || config.Sources["WHOOP_UNITS"] != SourceFlag {
+		t.Errorf("Units = %q from %s, want metric from the flag", config.Units, config.Sources["WHOOP_UNITS"])
+	}
+	if config.RequestTimeout != 30 || config.Sources["WHOOP_REQUEST_TIMEOUT"] != SourceDefault {
+		t.Errorf("RequestTimeout = %d from %s, want the default 30", config.RequestTimeout, config.Sources["WHOOP_REQUEST_TIMEOUT"])
+	}
+	if _, ok := config.Sources["WHOOP_TIMEZONE"]; ok {
+		t.Error("Expected an unset setting to have no source")
+	}
+
+	for env, want := range map[string]string{"WHOOP_RATE_LIMIT": "60", "WHOOP_LOCALE": "en", "WHOOP_UNITS": "metric", "WHOOP_TIMEZONE": ""} {
+		if got := config.Getenv(env); got != want {
+			t.Errorf("Getenv(%s) = %q, want %q", env, got, want)
+		}
+	}
+	if got := os.Getenv("WHOOP_UNITS"); got != "" {
+		t.Errorf("WHOOP_UNITS = %q in the environment, want the flag kept out of it", got)
+	}
+}
+
+func TestConfig_Set(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("WHOOP_CLIENT_ID", "from-env")
+	t.Setenv("WHOOP_LOCALE", "en")
+
+	config, err := Load(filepath.Join(t.TempDir(), "config.yaml"), false)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := config.Getenv("WHOOP_CLIENT_ID"); got != "from-env" {
+		t.Errorf("Getenv(WHOOP_CLIENT_ID) = %q, want the process environment's", got)
+	}
+	if err := config.Set("WHOOP_LOCALE", "es"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := config.Set("WHOOP_CLIENT_ID", "from-option"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if config.Locale != "es" || config.Sources["WHOOP_LOCALE"] != SourceOption {
+		t.Errorf("Locale = %q from %s, want es from the option", config.Locale, config.Sources["WHOOP_LOCALE"])
+	}
+	if got := config.Getenv("WHOOP_CLIENT_ID"); got != "from-option" {
+		t.Errorf("Getenv(WHOOP_CLIENT_ID) = %q, want the option's", got)
+	}
+	if os.Getenv("WHOOP_LOCALE") != "en" || os.Getenv("WHOOP_CLIENT_ID") != "from-env" {
+		t.Error("Expected Set to leave the process environment alone")
+	}
+	if err := config.Set("WHOOP_RATE_LIMIT", "fast"); err == nil {
+		t.Error("Expected a non-numeric rate limit option to be rejected")
+	}
+}
+
+func TestLoadConfig_FileErrors(t *testing.T) {
+	clearEnv(t)
+
+	if _, err := Load(writeConfigFile(t, "rate_limt: 60\n"), true); err == nil || !strings.Contains(err.Error(), "rate_limt") {
+		t.Errorf("Expected an unknown key to be rejected, got %v", err)
+	}
+	if _, err := Load(writeConfigFile(t, "rate_limit: fast\n"), true); err == nil {
+		t.Error("Expected a non-numeric rate_limit to be rejected")
+	}
+
+	missing := filepath.Join(t.TempDir(), "config.yaml")
+	if _, err := Load(missing, true); err == nil {
+		t.Error("Expected a missing explicit config file to be an error")
+	}
+	config, err := Load(missing, false)
+	if err != nil || config.Path != "" || config.RateLimit != 100 {
+		t.Errorf("Load() with no default file = %+v, %v; want the defaults", config, err)
+	}
+}
+
+func TestLoadConfig_InvalidEnvironmentValue(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("WHOOP_REQUEST_TIMEOUT", "30s")
+	if _, err := Load(filepath.Join(t.TempDir(), "none.yaml"), false); err == nil || !strings.Contains(err.Error(), "WHOOP_REQUEST_TIMEOUT") {
+		t.Errorf("Expected WHOOP_REQUEST_TIMEOUT=30s to be rejected, got %v", err)
+	}
+}
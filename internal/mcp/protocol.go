@@ -0,0 +1,78 @@
+// Package mcp holds the Model Context Protocol wire types and the framing of
+// JSON-RPC messages over stdio: one JSON object per line.
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// MaxMessageBytes caps a single JSON-RPC message read from a stream
+const MaxMessageBytes = 4 * 1024 * 1024
+
+// JSON-RPC 2.0 error codes, and the MCP code for requests sent before
+// initialize
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeNotInitialized = -32002
+)
+
+// NewScanner splits r into messages, one per line, accepting lines up to
+// MaxMessageBytes
+func NewScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), MaxMessageBytes)
+	return scanner
+}
+
+// Request is a JSON-RPC 2.0 request, or a notification when ID is nil
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      interface{}     `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response answers a request with either Result or Error. A nil ID encodes
+// as null, for errors in messages whose ID could not be read.
+type Response struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      interface{} `json:"id"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   *Error      `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error object; Code is one of the constants above or
+// an application code
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Tool describes a callable tool in tools/list
+type Tool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InputSchema InputSchema `json:"inputSchema"`
+}
+
+// InputSchema is the JSON Schema of a tool's arguments
+type InputSchema struct {
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties"`
+	Required   []string               `json:"required,omitempty"`
+}
+
+// Resource describes a readable resource in resources/list
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
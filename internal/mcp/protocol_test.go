@@ -0,0 +1,42 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewScanner_LargeMessages(t *testing.T) {
+	large := `{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"note": "` + strings.Repeat("x", 1<<20) + `"}}`
+	scanner := NewScanner(strings.NewReader(large + "\n" + `{"jsonrpc": "2.0", "id": 2, "method": "tools/list"}` + "\n"))
+
+	var methods []string
+	for scanner.Scan() {
+		var request Request
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			t.Fatalf("failed to decode message: %v", err)
+		}
+		methods = append(methods, request.Method)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if strings.Join(methods, ",") != "tools/call,tools/list" {
+		t.Errorf("Expected both messages, got %v", methods)
+	}
+
+	scanner = NewScanner(strings.NewReader(strings.Repeat("x", MaxMessageBytes+1) + "\n"))
+	if scanner.Scan() || scanner.Err() == nil {
+		t.Error("Expected a message over MaxMessageBytes to fail")
+	}
+}
+
+func TestResponse_NullID(t *testing.T) {
+	data, err := json.Marshal(Response{JSONRPC: "2.0", Error: &Error{Code: CodeParseError, Message: "Parse error"}})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if want := `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"Parse error"}}`; string(data) != want {
+		t.Errorf("Response = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Annotation is a subjective journal note attached to a calendar day
+type Annotation struct {
+	ID        int            `json:"id"`
+	UserID    int            `json:"user_id"`
+	Date      string         `json:"date"` // YYYY-MM-DD
+	Note      string         `json:"note,omitempty"`
+	Mood      *int           `json:"mood,omitempty"`     // 1 (worst) to 10 (best)
+	Symptoms  map[string]int `json:"symptoms,omitempty"` // symptom → severity, 1 (mild) to 10 (severe)
+	Tags      []string       `json:"tags,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// HasTag reports whether the annotation carries tag, ignoring case
+func (a Annotation) HasTag(tag string) bool {
+	for _, t := range a.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnnotationStore persists journal annotations per user in the local datastore
+type AnnotationStore struct {
+	Path string
+	mu   sync.Mutex
+	storeMode
+}
+
+// NewAnnotationStoreFromEnv uses WHOOP_ANNOTATIONS_FILE or the default datastore path,
+// encrypting it with cipher when it is set
+func NewAnnotationStoreFromEnv(settings Settings, cipher *DataCipher) (*AnnotationStore, error) {
+	path, err := localDataPath(settings, "WHOOP_ANNOTATIONS_FILE", "annotations.json")
+	if err != nil {
+		return nil, err
+	}
+	return &AnnotationStore{Path: path, storeMode: storeMode{cipher: cipher}}, nil
+}
+
+// Add stores an annotation, assigning the next ID for its user
+func (a *AnnotationStore) Add(annotation Annotation) (Annotation, error) {
+	if err := a.writable(); err != nil {
+		return Annotation{}, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	all := make(map[string][]Annotation)
+	if err := readJSONFile(a.cipher, a.Path, &all); err != nil {
+		return Annotation{}, err
+	}
+
+	key := strconv.Itoa(annotation.UserID)
+	for _, existing := range all[key] {
+		if existing.ID >= annotation.ID {
+			annotation.ID = existing.ID + 1
+		}
+	}
+	if annotation.ID == 0 {
+		annotation.ID = 1
+	}
+	all[key] = append(all[key], annotation)
+
+	if err := writeJSONFile(a.cipher, a.Path, all); err != nil {
+		return Annotation{}, err
+	}
+	return annotation, nil
+}
+
+// List returns the user's annotations dated within [start, end], oldest first
+func (a *AnnotationStore) List(userID int, start, end time.Time) ([]Annotation, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	all := make(map[string][]Annotation)
+	if err := readJSONFile(a.cipher, a.Path, &all); err != nil {
+		return nil, err
+	}
+
+	from, to := dayKey(start), dayKey(end)
+	var annotations []Annotation
+	for _, annotation := range all[strconv.Itoa(userID)] {
+		if annotation.Date >= from && annotation.Date <= to {
+			annotations = append(annotations, annotation)
+		}
+	}
+	sort.SliceStable(annotations, func(i, j int) bool {
+		return annotations[i].Date < annotations[j].Date
+	})
+	return annotations, nil
+}
+
+// Get returns one of the user's annotations by ID
+func (a *AnnotationStore) Get(userID, id int) (Annotation, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	all := make(map[string][]Annotation)
+	if err := readJSONFile(a.cipher, a.Path, &all); err != nil {
+		return Annotation{}, false, err
+	}
+	for _, annotation := range all[strconv.Itoa(userID)] {
+		if annotation.ID == id {
+			return annotation, true, nil
+		}
+	}
+	return Annotation{}, false, nil
+}
+
+// FormatAnnotations renders annotations as a dated list, one line per note
+func FormatAnnotations(annotations []Annotation) string {
+	var builder strings.Builder
+	for _, annotation := range annotations {
+		date, _ := time.Parse("2006-01-02", annotation.Date)
+		builder.WriteString(fmt.Sprintf("- **%s (%s):**", annotation.Date, date.Format("Mon")))
+		if annotation.Note != "" {
+			builder.WriteString(" " + annotation.Note)
+		}
+		if ratings := formatRatings(annotation); ratings != "" {
+			builder.WriteString(" — " + ratings)
+		}
+		if len(annotation.Tags) > 0 {
+			builder.WriteString(fmt.Sprintf(" [%s]", strings.Join(annotation.Tags, ", ")))
+		}
+		builder.WriteString(fmt.Sprintf(" (#%d)\n", annotation.ID))
+	}
+	return builder.String()
+}
+
+// FormatAnnotationOverlay renders the journal section appended to analysis
+// reports, or an empty string when the period has no annotations
+func FormatAnnotationOverlay(annotations []Annotation) string {
+	if len(annotations) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString("## Journal Annotations\n\n")
+	builder.WriteString("Notes logged during this period. Consider them when reading changes around these dates.\n\n")
+	builder.WriteString(FormatAnnotations(annotations))
+	return builder.String()
+}
+
+// formatRatings renders an annotation's mood and symptom scores, e.g. "mood 6/10, anxiety 7/10"
+func formatRatings(annotation Annotation) string {
+	var ratings []string
+	if annotation.Mood != nil {
+		ratings = append(ratings, fmt.Sprintf("mood %d/10", *annotation.Mood))
+	}
+	symptoms := make([]string, 0, len(annotation.Symptoms))
+	for symptom := range annotation.Symptoms {
+		symptoms = append(symptoms, symptom)
+	}
+	sort.Strings(symptoms)
+	for _, symptom := range symptoms {
+		ratings = append(ratings, fmt.Sprintf("%s %d/10", symptom, annotation.Symptoms[symptom]))
+	}
+	return strings.Join(ratings, ", ")
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnnotationStore_AddList(t *testing.T) {
+	store := &AnnotationStore{Path: filepath.Join(t.TempDir(), "annotations.json")}
+
+	for _, annotation := range []Annotation{
+		{UserID: 1, Date: "2024-03-05", Note: "big work deadline", Tags: []string{"work"}},
+		{UserID: 1, Date: "2024-03-01", Note: "started new medication", Tags: []string{"medication"}},
+		{UserID: 2, Date: "2024-03-02", Note: "alcohol 3 drinks"},
+		{UserID: 1, Date: "2024-04-01", Note: "outside range"},
+	} {
+		if _, err := store.Add(annotation); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	annotations, err := store.List(1, start, end)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(annotations) != 2 {
+		t.Fatalf("Expected 2 annotations for user 1 in March, got %d", len(annotations))
+	}
+	if annotations[0].Date != "2024-03-01" || annotations[0].ID != 2 {
+		t.Errorf("Expected the medication note (#2) first, got %+v", annotations[0])
+	}
+	if !annotations[1].HasTag("WORK") {
+		t.Error("Expected case-insensitive tag match")
+	}
+
+	overlay := FormatAnnotationOverlay(annotations)
+	if !strings.Contains(overlay, "2024-03-01 (Fri)") || !strings.Contains(overlay, "[medication]") {
+		t.Errorf("Unexpected overlay: %s", overlay)
+	}
+	if FormatAnnotationOverlay(nil) != "" {
+		t.Error("Expected no overlay without annotations")
+	}
+}
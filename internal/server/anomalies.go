@@ -0,0 +1,175 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+// Anomaly detection methods
+const (
+	anomalyMethodMAD    = "mad"
+	anomalyMethodZScore = "zscore"
+)
+
+const (
+	// anomalyBaselineDays is the trailing window each day is compared against
+	anomalyBaselineDays = 28
+	// minAnomalyBaseline is the fewest prior observations needed to judge a day
+	minAnomalyBaseline = 7
+	// defaultAnomalyThreshold is the robust z-score beyond which a value is anomalous
+	defaultAnomalyThreshold = 3.0
+	// madScale makes the median absolute deviation comparable to a standard deviation
+	madScale = 1.4826
+)
+
+// anomalyMetrics are the metrics screened for anomalies
+var anomalyMetrics = []string{"recovery", "hrv", "resting_hr", "sleep_hours", "strain"}
+
+// MetricDeviation is one metric's departure from its rolling baseline on a day
+type MetricDeviation struct {
+	Metric   string  `json:"metric"`
+	Label    string  `json:"label"`
+	Unit     string  `json:"unit"`
+	Value    float64 `json:"value"`
+	Baseline float64 `json:"baseline"`
+	Score    float64 `json:"score"` // robust or classic z-score
+	Adverse  bool    `json:"adverse"`
+}
+
+// AnomalyDay is a day where at least one metric deviated sharply
+type AnomalyDay struct {
+	Date       string            `json:"date"`
+	Deviations []MetricDeviation `json:"deviations"` // strongest first
+	Primary    MetricDeviation   `json:"primary"`    // likely contributing metric
+}
+
+// AnomalyReport lists anomalous days found in the window
+type AnomalyReport struct {
+	Method    string       `json:"method"`
+	Threshold float64      `json:"threshold"`
+	Days      []AnomalyDay `json:"days"` // most recent first
+}
+
+// DetectAnomalies compares each day against the preceding anomalyBaselineDays of
+// the same metric and reports days whose score exceeds threshold
+func (h *HealthAnalyzer) DetectAnomalies(data *HealthData, method string, threshold float64) AnomalyReport {
+	if method != anomalyMethodZScore {
+		method = anomalyMethodMAD
+	}
+	if threshold <= 0 {
+		threshold = defaultAnomalyThreshold
+	}
+	report := AnomalyReport{Method: method, Threshold: threshold, Days: []AnomalyDay{}}
+
+	byDay := make(map[string][]MetricDeviation)
+	for _, key := range anomalyMetrics {
+		metric, ok := lookupMetric(key)
+		if !ok {
+			continue
+		}
+		for _, deviation := range h.metricAnomalies(metric, metric.extract(data), method, threshold) {
+			byDay[deviation.date] = append(byDay[deviation.date], deviation.MetricDeviation)
+		}
+	}
+
+	for date, deviations := range byDay {
+		sort.Slice(deviations, func(i, j int) bool {
+			return math.Abs(deviations[i].Score) > math.Abs(deviations[j].Score)
+		})
+		report.Days = append(report.Days, AnomalyDay{Date: date, Deviations: deviations, Primary: deviations[0]})
+	}
+	sort.Slice(report.Days, func(i, j int) bool {
+		return report.Days[i].Date > report.Days[j].Date
+	})
+	return report
+}
+
+// datedDeviation tags a deviation with its calendar day
+type datedDeviation struct {
+	MetricDeviation
+	date string
+}
+
+// metricAnomalies scores each day of one metric against its trailing baseline
+func (h *HealthAnalyzer) metricAnomalies(metric metricDefinition, series []datedValue, method string, threshold float64) []datedDeviation {
+	var anomalies []datedDeviation
+	for i, v := range series {
+		var baseline []float64
+		for j := i - 1; j >= 0; j-- {
+			if v.Date.Sub(series[j].Date).Hours() > anomalyBaselineDays*24 {
+				break
+			}
+			baseline = append(baseline, series[j].Value)
+		}
+		if len(baseline) < minAnomalyBaseline {
+			continue
+		}
+
+		var center, spread float64
+		if method == anomalyMethodZScore {
+			center, spread = h.calculateMean(baseline), h.calculateStdDev(baseline)
+		} else {
+			center, spread = stats.Median(baseline), madScale*stats.MedianAbsoluteDeviation(baseline)
+		}
+		if spread == 0 {
+			continue
+		}
+
+		score := (v.Value - center) / spread
+		if math.Abs(score) < threshold {
+			continue
+		}
+		anomalies = append(anomalies, datedDeviation{
+			MetricDeviation: MetricDeviation{
+				Metric:   metric.Key,
+				Label:    metric.Label,
+				Unit:     metric.Unit,
+				Value:    v.Value,
+				Baseline: center,
+				Score:    score,
+				Adverse:  (score > 0) != metric.HigherIsBetter,
+			},
+			date: dayKey(v.Date),
+		})
+	}
+	return anomalies
+}
+
+// FormatAnomalyReport renders anomalous days with their contributing metrics
+func (h *HealthAnalyzer) FormatAnomalyReport(report AnomalyReport) string {
+	var builder strings.Builder
+	builder.WriteString("# Daily Anomaly Detection\n\n")
+
+	methodLabel := "median ± MAD (robust z-score)"
+	if report.Method == anomalyMethodZScore {
+		methodLabel = "mean ± SD (z-score)"
+	}
+	builder.WriteString(fmt.Sprintf("**Method:** %s over the prior %d days, threshold %.1f\n\n", methodLabel, anomalyBaselineDays, report.Threshold))
+
+	if len(report.Days) == 0 {
+		builder.WriteString("No days deviated sharply from the rolling personal baseline.\n")
+		return builder.String()
+	}
+
+	builder.WriteString(fmt.Sprintf("Found %d anomalous day(s):\n\n", len(report.Days)))
+	for _, day := range report.Days {
+		icon := "📈"
+		if day.Primary.Adverse {
+			icon = "⚠️"
+		}
+		builder.WriteString(fmt.Sprintf("### %s %s — likely driver: %s\n", icon, day.Date, day.Primary.Label))
+		for _, deviation := range day.Deviations {
+			builder.WriteString(fmt.Sprintf("- %s: %.1f%s vs baseline %.1f%s (%+.1f)\n",
+				deviation.Label, deviation.Value, deviation.Unit, deviation.Baseline, deviation.Unit, deviation.Score))
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("## Therapy Notes\n")
+	builder.WriteString("Single-day outliers usually have a concrete cause — illness, alcohol, travel, a hard session, or an acutely stressful event. Asking about the day before each flagged date often surfaces it.\n")
+	return builder.String()
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+func TestMedianAbsoluteDeviation(t *testing.T) {
+	values := []float64{1, 1, 2, 2, 4, 6, 9}
+	if got := stats.Median(values); got != 2 {
+		t.Errorf("median = %v, want 2", got)
+	}
+	if got := stats.MedianAbsoluteDeviation(values); got != 1 {
+		t.Errorf("MAD = %v, want 1", got)
+	}
+}
+
+func TestHealthAnalyzer_DetectAnomalies(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	start := time.Date(2024, 6, 1, 7, 0, 0, 0, time.UTC)
+
+	data := &HealthData{}
+	for i := 0; i < 30; i++ {
+		var recovery WhoopRecovery
+		recovery.CreatedAt = start.AddDate(0, 0, i)
+		recovery.Score.RecoveryScore = 60 + float64(i%3)
+		recovery.Score.HRVRmssd = 50 + float64(i%4)
+		recovery.Score.RestingHeartRate = 55 + float64(i%2)
+		if i == 25 {
+			recovery.Score.RecoveryScore = 15
+			recovery.Score.HRVRmssd = 22
+			recovery.Score.RestingHeartRate = 57
+		}
+		data.Recoveries = append(data.Recoveries, recovery)
+	}
+
+	report := analyzer.DetectAnomalies(data, "", 0)
+
+	if report.Method != anomalyMethodMAD || report.Threshold != defaultAnomalyThreshold {
+		t.Errorf("Expected MAD defaults, got %s/%.1f", report.Method, report.Threshold)
+	}
+	if len(report.Days) != 1 {
+		t.Fatalf("Expected 1 anomalous day, got %d: %+v", len(report.Days), report.Days)
+	}
+	day := report.Days[0]
+	if day.Date != "2024-06-26" {
+		t.Errorf("Expected anomaly on 2024-06-26, got %s", day.Date)
+	}
+	if len(day.Deviations) != 2 || !day.Primary.Adverse {
+		t.Errorf("Expected adverse recovery and HRV deviations, got %+v", day.Deviations)
+	}
+}
@@ -0,0 +1,293 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// archiveSchemaVersion identifies the layout of NDJSON archives. Bump it when
+// the manifest fields or the record encoding change; readers should reject
+// versions they do not know.
+//
+// Version 1: one <dataset>.ndjson file per entry in exportDatasets, each line
+// a record exactly as returned by the Whoop v2 API (metric units, Whoop field
+// names, newest first), plus a manifest.json describing the files.
+const archiveSchemaVersion = 1
+
+const (
+	// archiveWindowDays is the span fetched per request batch
+	archiveWindowDays = 90
+	// archiveEmptyWindows ends a full-history walk after this many consecutive
+	// windows without records
+	archiveEmptyWindows = 4
+	// archiveManifestName is the manifest file written next to the data files
+	archiveManifestName = "manifest.json"
+)
+
+// whoopEpoch is the earliest date a full-history archive looks back to
+var whoopEpoch = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// archiveRecordSchemas names the Whoop v2 record type stored in each dataset
+var archiveRecordSchemas = map[string]string{
+	"recovery": "whoop.v2.recovery",
+	"sleep":    "whoop.v2.activity.sleep",
+	"workout":  "whoop.v2.activity.workout",
+	"cycle":    "whoop.v2.cycle",
+}
+
+// ArchiveManifest describes an NDJSON archive
+type ArchiveManifest struct {
+	SchemaVersion int           `json:"schema_version"`
+	GeneratedAt   time.Time     `json:"generated_at"`
+	UserID        int           `json:"user_id,omitempty"`
+	Start         time.Time     `json:"start"`
+	End           time.Time     `json:"end"`
+	Files         []ArchiveFile `json:"files"`
+}
+
+// ArchiveFile is one dataset in an archive
+type ArchiveFile struct {
+	Dataset string   `json:"dataset"`
+	Path    string   `json:"path"`   // relative to the manifest
+	Schema  string   `json:"schema"` // Whoop record type of each line
+	Fields  []string `json:"fields"` // top-level JSON fields of each record
+	Records int      `json:"records"`
+	SHA256  string   `json:"sha256"`
+}
+
+// archiveWriter streams records into one NDJSON file per dataset
+type archiveWriter struct {
+	dir      string
+	manifest ArchiveManifest
+	files    map[string]*os.File
+	encoders map[string]*json.Encoder
+	hashes   map[string]hash.Hash
+}
+
+// newArchiveWriter creates the archive directory and empty data files
+func newArchiveWriter(dir string, datasets []string) (*archiveWriter, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	w := &archiveWriter{
+		dir:      dir,
+		manifest: ArchiveManifest{SchemaVersion: archiveSchemaVersion, Files: []ArchiveFile{}},
+		files:    make(map[string]*os.File),
+		encoders: make(map[string]*json.Encoder),
+		hashes:   make(map[string]hash.Hash),
+	}
+	for _, dataset := range datasets {
+		name := dataset + ".ndjson"
+		file, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			w.close()
+			return nil, fmt.Errorf("failed to create %s: %w", name, err)
+		}
+		digest := sha256.New()
+		encoder := json.NewEncoder(io.MultiWriter(file, digest))
+		encoder.SetEscapeHTML(false)
+
+		w.files[dataset] = file
+		w.hashes[dataset] = digest
+		w.encoders[dataset] = encoder
+		w.manifest.Files = append(w.manifest.Files, ArchiveFile{
+			Dataset: dataset,
+			Path:    name,
+			Schema:  archiveRecordSchemas[dataset],
+			Fields:  recordFields(reflect.TypeOf(new(HealthData).records(dataset)).Elem()),
+		})
+	}
+	return w, nil
+}
+
+// Write appends the datasets' records from one fetch window
+func (w *archiveWriter) Write(data *HealthData) (int, error) {
+	written := 0
+	for i := range w.manifest.Files {
+		file := &w.manifest.Files[i]
+		records := reflect.ValueOf(data.records(file.Dataset))
+		for j := 0; j < records.Len(); j++ {
+			if err := w.encoders[file.Dataset].Encode(records.Index(j).Interface()); err != nil {
+				return written, fmt.Errorf("failed to write %s: %w", file.Path, err)
+			}
+		}
+		file.Records += records.Len()
+		written += records.Len()
+	}
+	return written, nil
+}
+
+// Finish closes the data files and writes the manifest
+func (w *archiveWriter) Finish(userID int, start, end time.Time) (*ArchiveManifest, error) {
+	if err := w.close(); err != nil {
+		return nil, err
+	}
+	w.manifest.GeneratedAt = time.Now().UTC()
+	w.manifest.UserID = userID
+	w.manifest.Start = start
+	w.manifest.End = end
+	for i := range w.manifest.Files {
+		w.manifest.Files[i].SHA256 = hex.EncodeToString(w.hashes[w.manifest.Files[i].Dataset].Sum(nil))
+	}
+	// The archive is an export, so the manifest stays plaintext like the data files
+	data, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := writePrivateFile(filepath.Join(w.dir, archiveManifestName), data); err != nil {
+		return nil, err
+	}
+	return &w.manifest, nil
+}
+
+// close closes every open data file, returning the first error
+func (w *archiveWriter) close() error {
+	var first error
+	for dataset, file := range w.files {
+		if err := file.Close(); err != nil && first == nil {
+			first = err
+		}
+		delete(w.files, dataset)
+	}
+	return first
+}
+
+// recordFields lists the top-level JSON field names of a record type
+func recordFields(t reflect.Type) []string {
+	fields := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		fields = append(fields, exportFieldName(t.Field(i)))
+	}
+	return fields
+}
+
+// writeArchive fetches [start, end] in archiveWindowDays windows, newest first,
+// and writes an NDJSON archive to dir. A zero start walks back toward
+// whoopEpoch until archiveEmptyWindows consecutive windows come back empty.
+func writeArchive(dir string, datasets []string, start, end time.Time, userID int, fetch func(start, end time.Time) (*HealthData, error)) (*ArchiveManifest, error) {
+	writer, err := newArchiveWriter(dir, datasets)
+	if err != nil {
+		return nil, err
+	}
+
+	fullHistory := start.IsZero()
+	floor := start
+	if fullHistory {
+		floor = whoopEpoch
+	}
+
+	earliest, empty := end, 0
+	// Windows step back one second short of the previous start so records on a
+	// boundary are not fetched twice
+	for windowEnd := end; windowEnd.After(floor); {
+		windowStart := windowEnd.AddDate(0, 0, -archiveWindowDays)
+		if windowStart.Before(floor) {
+			windowStart = floor
+		}
+
+		data, err := fetch(windowStart, windowEnd)
+		if err != nil {
+			writer.close()
+			return nil, err
+		}
+		written, err := writer.Write(data)
+		if err != nil {
+			writer.close()
+			return nil, err
+		}
+
+		earliest = windowStart
+		if written > 0 {
+			empty = 0
+		} else if empty++; fullHistory && empty >= archiveEmptyWindows {
+			break
+		}
+		windowEnd = windowStart.Add(-time.Second)
+	}
+
+	if fullHistory {
+		start = earliest
+	}
+	return writer.Finish(userID, start, end)
+}
+
+// runExportCommand implements `whoop-mcp-server export`: it archives the
+// account's history (or a date range) as NDJSON with a manifest
+func runExportCommand(settings Settings, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := fs.String("out", "", "Directory to write the archive to")
+	startFlag := fs.String("start", "", "First day to export in YYYY-MM-DD format (default: full history)")
+	endFlag := fs.String("end", "", "Last day to export in YYYY-MM-DD format (default: today)")
+	userID := fs.Int("user-id", 0, "User ID from whoop://accounts (default: authenticated user)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return fmt.Errorf("usage: whoop-mcp-server export -out DIR [-start YYYY-MM-DD] [-end YYYY-MM-DD]")
+	}
+
+	var start time.Time
+	end := time.Now()
+	var err error
+	if *startFlag != "" {
+		if start, err = time.Parse("2006-01-02", *startFlag); err != nil {
+			return fmt.Errorf("invalid -start: %w", err)
+		}
+	}
+	if *endFlag != "" {
+		if end, err = time.Parse("2006-01-02", *endFlag); err != nil {
+			return fmt.Errorf("invalid -end: %w", err)
+		}
+		end = end.AddDate(0, 0, 1).Add(-time.Second)
+	}
+
+	server, err := NewMCPServer(settings)
+	if err != nil {
+		return err
+	}
+	manifest, err := server.archive(context.Background(), *out, exportDatasets, start, end, userIDArg(*userID))
+	if err != nil {
+		return err
+	}
+	fmt.Println(formatArchiveSummary(*out, manifest))
+	return nil
+}
+
+// userIDArg converts a zero user ID flag to the authenticated user
+func userIDArg(userID int) *int {
+	if userID == 0 {
+		return nil
+	}
+	return &userID
+}
+
+// archive resolves the user and writes an NDJSON archive of their records
+func (s *MCPServer) archive(ctx context.Context, dir string, datasets []string, start, end time.Time, userID *int) (*ArchiveManifest, error) {
+	id, err := s.accountID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return writeArchive(dir, datasets, start, end, id, func(start, end time.Time) (*HealthData, error) {
+		return s.fetchHealthData(ctx, start, end, userID)
+	})
+}
+
+// formatArchiveSummary lists what an archive contains
+func formatArchiveSummary(dir string, manifest *ArchiveManifest) string {
+	summary := fmt.Sprintf("Archived %s to %s (schema v%d, manifest %s):",
+		formatPeriod(nil, DateRange{Start: manifest.Start, End: manifest.End}), dir, manifest.SchemaVersion, archiveManifestName)
+	for _, file := range manifest.Files {
+		summary += fmt.Sprintf("\n- %s: %d records", file.Path, file.Records)
+	}
+	return summary
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteArchive(t *testing.T) {
+	dir := t.TempDir()
+	end := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	var windows int
+	fetch := func(start, windowEnd time.Time) (*HealthData, error) {
+		windows++
+		data := &HealthData{}
+		// Records only in the two most recent windows
+		if windows <= 2 {
+			var recovery WhoopRecovery
+			recovery.CycleID = int64(windows)
+			recovery.CreatedAt = windowEnd
+			data.Recoveries = append(data.Recoveries, recovery)
+			var cycle WhoopCycle
+			cycle.ID = int64(windows)
+			data.Cycles = append(data.Cycles, cycle)
+		}
+		return data, nil
+	}
+
+	manifest, err := writeArchive(dir, []string{"recovery", "cycle"}, time.Time{}, end, 7, fetch)
+	if err != nil {
+		t.Fatalf("writeArchive() error: %v", err)
+	}
+
+	if windows != 2+archiveEmptyWindows {
+		t.Errorf("Expected the walk to stop after %d empty windows, fetched %d", archiveEmptyWindows, windows)
+	}
+	if manifest.SchemaVersion != archiveSchemaVersion || manifest.UserID != 7 || len(manifest.Files) != 2 {
+		t.Fatalf("Unexpected manifest %+v", manifest)
+	}
+	if !manifest.Start.Before(end.AddDate(0, 0, -archiveWindowDays*(1+archiveEmptyWindows))) {
+		t.Errorf("Expected the start to reflect the walk, got %s", manifest.Start)
+	}
+
+	recovery := manifest.Files[0]
+	if recovery.Dataset != "recovery" || recovery.Records != 2 || recovery.Schema != "whoop.v2.recovery" || recovery.Fields[0] != "cycle_id" {
+		t.Errorf("Unexpected recovery entry %+v", recovery)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, recovery.Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != recovery.SHA256 {
+		t.Error("Manifest checksum does not match the file")
+	}
+	file, _ := os.Open(filepath.Join(dir, recovery.Path))
+	defer file.Close()
+	lines := bufio.NewScanner(file)
+	var count int
+	for lines.Scan() {
+		var record WhoopRecovery
+		if err := json.Unmarshal(lines.Bytes(), &record); err != nil {
+			t.Fatalf("Line %d is not a recovery record: %v", count+1, err)
+		}
+		count++
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 NDJSON lines, got %d", count)
+	}
+
+	var onDisk ArchiveManifest
+	if err := readJSONFile(nil, filepath.Join(dir, archiveManifestName), &onDisk); err != nil || onDisk.Files[1].Records != 2 {
+		t.Errorf("Expected manifest.json on disk, got %+v (%v)", onDisk, err)
+	}
+}
+
+func TestWriteArchive_DateRangeStopsAtStart(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 30, 23, 59, 59, 0, time.UTC)
+
+	var earliest time.Time
+	var windows int
+	manifest, err := writeArchive(t.TempDir(), exportDatasets, start, end, 1, func(windowStart, windowEnd time.Time) (*HealthData, error) {
+		windows++
+		earliest = windowStart
+		if !windowEnd.After(windowStart) {
+			t.Errorf("Empty window %s - %s", windowStart, windowEnd)
+		}
+		return &HealthData{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if windows != 3 || !earliest.Equal(start) || !manifest.Start.Equal(start) {
+		t.Errorf("Expected 3 windows ending at the requested start, got %d ending %s", windows, earliest)
+	}
+}
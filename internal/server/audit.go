@@ -0,0 +1,240 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// auditLogLimit is how many of the most recent entries whoop://audit/log returns
+const auditLogLimit = 200
+
+// unauditedTools never read health data, so calling them is not logged
+var unauditedTools = map[string]bool{"setup_whoop_auth": true, "get_storage_footprint": true, "server_status": true}
+
+// auditedResources are the resources that return health data
+var auditedResources = map[string]bool{
+	"whoop://user/profile": true, "whoop://health/recent": true, "whoop://health/today": true,
+	"whoop://health/yesterday": true, "whoop://insights/burnout": true, "whoop://reports/latest": true,
+}
+
+// AuditEntry records one read of health data
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	Session  string    `json:"session"`          // random per server process
+	Client   string    `json:"client,omitempty"` // clientInfo name and version from initialize
+	Tool     string    `json:"tool,omitempty"`
+	Resource string    `json:"resource,omitempty"`
+	UserID   *int      `json:"user_id,omitempty"` // whose data; omitted for the authenticated account
+	Start    string    `json:"start,omitempty"`   // resolved range, YYYY-MM-DD
+	End      string    `json:"end,omitempty"`
+	Days     int       `json:"days,omitempty"` // when the range was given as a day count
+	Error    string    `json:"error,omitempty"`
+}
+
+// AuditLog appends entries to a local NDJSON file. When the datastore is
+// encrypted each line is sealed on its own, so appending never rewrites the
+// file, and every line is sealed under the salt of the file's first sealed
+// line, so reading and appending derive one key per file rather than per line.
+type AuditLog struct {
+	Path    string
+	Session string
+	cipher  *DataCipher // nil writes plaintext lines
+	mu      sync.Mutex
+	salt    []byte // shared by the file's sealed lines, once known
+}
+
+// NewAuditLogFromEnv uses WHOOP_AUDIT_LOG_FILE or the default datastore path,
+// sealing lines with cipher when it is set. Auditing is on unless
+// WHOOP_AUDIT_LOG is off, in which case it returns nil.
+func NewAuditLogFromEnv(settings Settings, cipher *DataCipher) (*AuditLog, error) {
+	switch toggle := strings.ToLower(strings.TrimSpace(settings("WHOOP_AUDIT_LOG"))); toggle {
+	case "off":
+		return nil, nil
+	case "", "on":
+	default:
+		return nil, fmt.Errorf("unknown WHOOP_AUDIT_LOG %q (expected on or off)", toggle)
+	}
+	path, err := localDataPath(settings, "WHOOP_AUDIT_LOG_FILE", "audit.ndjson")
+	if err != nil {
+		return nil, err
+	}
+	session := make([]byte, 8)
+	if _, err := rand.Read(session); err != nil {
+		return nil, fmt.Errorf("failed to generate audit session ID: %w", err)
+	}
+	return &AuditLog{Path: path, Session: hex.EncodeToString(session), cipher: cipher}, nil
+}
+
+// Append writes one entry, stamping the session
+func (a *AuditLog) Append(entry AuditEntry) error {
+	entry.Session = a.Session
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cipher != nil {
+		if a.salt == nil {
+			a.salt = a.fileSalt()
+		}
+		sealed, err := a.cipher.sealWithSalt(a.salt, line)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt audit entry: %w", err)
+		}
+		line = []byte(base64.StdEncoding.EncodeToString(sealed))
+	}
+	if err := os.MkdirAll(filepath.Dir(a.Path), 0700); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	file, err := os.OpenFile(a.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return file.Close()
+}
+
+// fileSalt returns the salt of the log's first sealed line, or the cipher's
+// own salt when no line is sealed yet
+func (a *AuditLog) fileSalt() []byte {
+	if file, err := os.Open(a.Path); err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 || line[0] == '{' {
+				continue
+			}
+			sealed, err := base64.StdEncoding.DecodeString(string(line))
+			header := len(encryptedFileMagic) + encryptionSaltSize
+			if err != nil || !isEncrypted(sealed) || len(sealed) < header {
+				break
+			}
+			return sealed[len(encryptedFileMagic):header]
+		}
+	}
+	return a.cipher.salt
+}
+
+// Recent returns up to limit of the newest entries, oldest first
+func (a *AuditLog) Recent(limit int) ([]AuditEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := os.ReadFile(a.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []AuditEntry{}, nil
+		}
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	entries := []AuditEntry{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] != '{' {
+			// An encrypted line
+			sealed, err := base64.StdEncoding.DecodeString(string(line))
+			if err != nil {
+				return nil, fmt.Errorf("malformed audit log line: %w", err)
+			}
+			if line, err = openDatastore(a.cipher, sealed); err != nil {
+				return nil, fmt.Errorf("failed to read audit log: %w", err)
+			}
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("malformed audit log line: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, scanner.Err()
+}
+
+// auditToolEntry describes a tool call from its arguments, resolving the date
+// range the same way the tools do
+func auditToolEntry(tool string, arguments json.RawMessage, now time.Time) AuditEntry {
+	entry := AuditEntry{Time: now.UTC(), Tool: tool}
+	var args struct {
+		DateRangeInput
+		WeekStart string `json:"week_start"`
+		UserID    *int   `json:"user_id"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return entry
+	}
+	entry.UserID = args.UserID
+	switch {
+	case args.StartDate != "":
+		if start, end, err := resolveDateRange(args.DateRangeInput, now); err == nil {
+			entry.Start, entry.End = dayKey(start), dayKey(end)
+		}
+	case args.WeekStart != "":
+		entry.Start = args.WeekStart
+	default:
+		entry.Days = args.Days
+	}
+	return entry
+}
+
+// auditToolCall logs a tool call that read health data; failures to log are
+// reported but never block the call
+func (s *MCPServer) auditToolCall(tool string, arguments json.RawMessage, callErr error) {
+	if s.audit == nil || unauditedTools[tool] {
+		return
+	}
+	entry := auditToolEntry(tool, arguments, s.now())
+	entry.Client = s.clientName()
+	if callErr != nil {
+		entry.Error = callErr.Error()
+	}
+	if err := s.audit.Append(entry); err != nil {
+		log.Printf("Failed to write audit log: %v", err)
+	}
+}
+
+// auditResourceRead logs a read of a resource that returns health data
+func (s *MCPServer) auditResourceRead(uri string, readErr error) {
+	if s.audit == nil || !auditedResources[uri] {
+		return
+	}
+	entry := AuditEntry{Time: s.now().UTC(), Resource: uri, Client: s.clientName()}
+	if readErr != nil {
+		entry.Error = readErr.Error()
+	}
+	if err := s.audit.Append(entry); err != nil {
+		log.Printf("Failed to write audit log: %v", err)
+	}
+}
+
+// clientName returns the client recorded at initialize
+func (s *MCPServer) clientName() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.client
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditToolEntry(t *testing.T) {
+	now := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+
+	entry := auditToolEntry("get_health_summary", json.RawMessage(`{"start_date": "last week", "user_id": 55501}`), now)
+	if entry.Start != "2024-06-03" || entry.End != "2024-06-09" || entry.UserID == nil || *entry.UserID != 55501 {
+		t.Errorf("entry = %+v, want last week for user 55501", entry)
+	}
+	if days := auditToolEntry("list_red_flags", json.RawMessage(`{"days": 30}`), now); days.Days != 30 || days.UserID != nil {
+		t.Errorf("entry = %+v, want a 30-day read of the authenticated account", days)
+	}
+	// Free-text arguments such as journal notes are never copied into the log
+	note := auditToolEntry("log_annotation", json.RawMessage(`{"date": "2024-06-11", "note": "panic attack at work"}`), now)
+	if line, _ := json.Marshal(note); strings.Contains(string(line), "panic") {
+		t.Errorf("audit entry leaked the note: %s", line)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	audit := &AuditLog{Path: filepath.Join(t.TempDir(), "audit.ndjson"), Session: "a1b2c3"}
+	userID := 55501
+	if err := audit.Append(AuditEntry{Time: time.Now(), Tool: "analyze_hrv", Days: 30, Client: "claude-ai 0.1.0"}); err != nil {
+		t.Fatalf("Append() error: %v", err)
+	}
+	audit.cipher = &DataCipher{key: make([]byte, 32)}
+	if err := audit.Append(AuditEntry{Time: time.Now(), Resource: "whoop://health/today", UserID: &userID}); err != nil {
+		t.Fatalf("Append() error: %v", err)
+	}
+
+	raw, _ := os.ReadFile(audit.Path)
+	if strings.Contains(string(raw), "whoop://health/today") {
+		t.Error("the entry written with encryption on is stored in plaintext")
+	}
+	entries, err := audit.Recent(10)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Recent() = %+v, %v, want both entries", entries, err)
+	}
+	if entries[0].Tool != "analyze_hrv" || entries[0].Session != "a1b2c3" || entries[1].Resource != "whoop://health/today" {
+		t.Errorf("entries = %+v", entries)
+	}
+	if latest, _ := audit.Recent(1); len(latest) != 1 || latest[0].Resource == "" {
+		t.Errorf("Recent(1) = %+v, want only the newest entry", latest)
+	}
+}
+
+func TestAuditLog_OneKeyPerFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	earlier, err := newPassphraseCipher("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (&AuditLog{Path: path, Session: "earlier", cipher: earlier}).Append(AuditEntry{Time: time.Now(), Tool: "analyze_hrv"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A later run has its own salt but keeps sealing under the file's
+	later, err := newPassphraseCipher("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	audit := &AuditLog{Path: path, Session: "later", cipher: later}
+	for i := 0; i < 100; i++ {
+		if err := audit.Append(AuditEntry{Time: time.Now(), Tool: "get_health_summary", Days: i + 1}); err != nil {
+			t.Fatalf("Append() error: %v", err)
+		}
+	}
+	entries, err := audit.Recent(auditLogLimit)
+	if err != nil || len(entries) != 101 || entries[100].Days != 100 {
+		t.Fatalf("Recent() = %d entries, %v, want all 101", len(entries), err)
+	}
+	if len(later.derived) != 1 {
+		t.Errorf("derived %d keys for 101 lines, want one for the file's salt", len(later.derived))
+	}
+}
+
+func BenchmarkAuditLogAppend_Passphrase(b *testing.B) {
+	cipher, err := newPassphraseCipher("correct horse battery staple")
+	if err != nil {
+		b.Fatal(err)
+	}
+	audit := &AuditLog{Path: filepath.Join(b.TempDir(), "audit.ndjson"), Session: "bench", cipher: cipher}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := audit.Append(AuditEntry{Time: time.Now(), Tool: "analyze_hrv", Days: 30}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
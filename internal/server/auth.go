@@ -0,0 +1,212 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	WhoopAuthURL     = "https://api.prod.whoop.com/oauth/oauth2/auth"
+	WhoopTokenURL    = "https://api.prod.whoop.com/oauth/oauth2/token"
+	WhoopRedirectURI = "http://localhost:3000/callback"
+	WhoopOAuthScopes = "read:recovery read:sleep read:workout read:cycles read:profile offline"
+)
+
+// AuthRequiredError signals that the stored credentials can no longer be used
+// and a human must re-run the OAuth flow
+type AuthRequiredError struct {
+	Reason string
+}
+
+func (e *AuthRequiredError) Error() string {
+	return fmt.Sprintf("Whoop re-authorization required: %s", e.Reason)
+}
+
+// oauthStateTTL bounds how long an issued state value stays valid
+const oauthStateTTL = 15 * time.Minute
+
+// buildAuthURL creates the Whoop OAuth authorization URL for a client ID and
+// the per-attempt state value
+func buildAuthURL(clientID, state string) string {
+	params := url.Values{}
+	params.Set("client_id", clientID)
+	params.Set("redirect_uri", WhoopRedirectURI)
+	params.Set("response_type", "code")
+	params.Set("scope", WhoopOAuthScopes)
+	params.Set("state", state)
+
+	return WhoopAuthURL + "?" + params.Encode()
+}
+
+// generateOAuthState returns a cryptographically random, URL-safe state value
+func generateOAuthState() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate OAuth state: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// oauthStateStore remembers issued state values in memory so the code exchange
+// can verify the callback belongs to an attempt this server started
+type oauthStateStore struct {
+	states map[string]time.Time
+	ttl    time.Duration
+	mu     sync.Mutex
+}
+
+// newOAuthStateStore creates an empty state store
+func newOAuthStateStore(ttl time.Duration) *oauthStateStore {
+	return &oauthStateStore{
+		states: make(map[string]time.Time),
+		ttl:    ttl,
+	}
+}
+
+// Issue generates and records a new state value
+func (o *oauthStateStore) Issue() (string, error) {
+	state, err := generateOAuthState()
+	if err != nil {
+		return "", err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.prune(time.Now())
+	o.states[state] = time.Now().Add(o.ttl)
+	return state, nil
+}
+
+// Consume validates a state value and removes it so it cannot be replayed
+func (o *oauthStateStore) Consume(state string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+	o.prune(now)
+
+	if state == "" {
+		return errors.New("missing OAuth state; copy the 'state' parameter from the callback URL along with the code")
+	}
+	for issued := range o.states {
+		if subtle.ConstantTimeCompare([]byte(issued), []byte(state)) == 1 {
+			delete(o.states, issued)
+			return nil
+		}
+	}
+	return errors.New("OAuth state does not match any pending authorization attempt (it may have expired); generate a new authorization URL")
+}
+
+// prune drops expired states; callers must hold the lock
+func (o *oauthStateStore) prune(now time.Time) {
+	for state, expiresAt := range o.states {
+		if now.After(expiresAt) {
+			delete(o.states, state)
+		}
+	}
+}
+
+// formatReauthInstructions renders the structured re-authorization message that
+// tools return while the server is in the degraded "needs auth" state. authURL
+// may be empty when no client ID is configured.
+func formatReauthInstructions(authErr *AuthRequiredError, authURL string) string {
+	authStep := `1. **Generate an authorization URL** by asking me:
+   "Generate Whoop auth URL for client_id: YOUR_CLIENT_ID"`
+	if authURL != "" {
+		authStep = fmt.Sprintf(`1. **Open this fresh authorization URL** and approve access:
+
+   %s`, authURL)
+	}
+
+	return fmt.Sprintf(`# 🔐 Whoop Re-Authorization Required
+
+**Status:** needs_auth
+**Reason:** %s
+
+The stored Whoop tokens have expired or been revoked and could not be refreshed automatically. Health data tools are paused until access is restored.
+
+## How to Restore Access
+
+%s
+2. **Copy the 'code' and 'state' parameters** from the callback URL
+3. **Exchange them** by asking me:
+   "Exchange Whoop code: YOUR_CODE with state: YOUR_STATE and secret: YOUR_SECRET"
+
+Once the exchange succeeds the server resumes normal operation without a restart.`, authErr.Reason, authStep)
+}
+
+// formatMissingScopeInstructions explains how to grant a scope the token lacks
+func formatMissingScopeInstructions(scopeErr *MissingScopeError) string {
+	return fmt.Sprintf(`# 🔒 Missing Whoop Permission
+
+**Missing scope:** %s
+**Endpoint:** %s
+
+The current Whoop token was authorized without %s, so this data cannot be read.
+
+## How to Fix
+
+Re-run the authorization flow and make sure the %s permission is approved on the Whoop consent screen. The server requests: %s`,
+		scopeErr.Scope, scopeErr.Endpoint, scopeErr.Scope, scopeErr.Scope, WhoopOAuthScopes)
+}
+
+// OAuthTokenResponse is the OAuth token endpoint response for a code exchange
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	Scope        string `json:"scope"`
+}
+
+// TokenExchangeError reports a non-200 response from the token endpoint
+type TokenExchangeError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *TokenExchangeError) Error() string {
+	return fmt.Sprintf("token exchange failed (status %d): %s", e.StatusCode, e.Body)
+}
+
+// exchangeAuthorizationCode trades an authorization code for access/refresh tokens
+func exchangeAuthorizationCode(httpClient *http.Client, clientID, clientSecret, authCode string) (*OAuthTokenResponse, error) {
+	data := url.Values{}
+	data.Set("grant_type", "authorization_code")
+	data.Set("client_id", clientID)
+	data.Set("client_secret", clientSecret)
+	data.Set("redirect_uri", WhoopRedirectURI)
+	data.Set("code", authCode)
+
+	resp, err := httpClient.Post(WhoopTokenURL, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, &TokenExchangeError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var tokenResp OAuthTokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+
+	return &tokenResp, nil
+}
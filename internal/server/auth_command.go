@@ -0,0 +1,173 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// runAuthCommand implements `whoop-mcp-server auth`: it opens a temporary
+// callback server, waits for the browser redirect, exchanges the code, and
+// persists the tokens to the configured token store. With -manual it reads
+// the redirect URL from stdin instead, for machines the browser cannot reach.
+func runAuthCommand(settings Settings, args []string) error {
+	fs := flag.NewFlagSet("auth", flag.ContinueOnError)
+	clientID := fs.String("client-id", settings("WHOOP_CLIENT_ID"), "Whoop app client ID (defaults to WHOOP_CLIENT_ID)")
+	clientSecret := fs.String("client-secret", settings("WHOOP_CLIENT_SECRET"), "Whoop app client secret (defaults to WHOOP_CLIENT_SECRET)")
+	manual := fs.Bool("manual", false, "Paste the redirect URL (or code) instead of listening for the callback")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *clientID == "" || *clientSecret == "" {
+		return fmt.Errorf("client ID and secret are required (flags or WHOOP_CLIENT_ID / WHOOP_CLIENT_SECRET)")
+	}
+
+	store, err := NewTokenStoreFromEnv(settings)
+	if err != nil {
+		return err
+	}
+
+	state, err := generateOAuthState()
+	if err != nil {
+		return err
+	}
+
+	var code string
+	if *manual {
+		fmt.Println("🔗 Open this URL in your browser to authorize the app:")
+		fmt.Println("")
+		fmt.Println(buildAuthURL(*clientID, state))
+		fmt.Println("")
+		fmt.Println("📋 Paste the URL your browser was redirected to (or just the code) and press Enter:")
+		if code, err = readAuthorizationCode(os.Stdin, state); err != nil {
+			return err
+		}
+	} else {
+		listener, callbackPath, err := listenOnRedirectURI(WhoopRedirectURI)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("🔗 Open this URL in your browser to authorize the app:")
+		fmt.Println("")
+		fmt.Println(buildAuthURL(*clientID, state))
+		fmt.Println("")
+		fmt.Printf("⏳ Waiting up to %s for the callback on %s ...\n", callbackTimeout, WhoopRedirectURI)
+
+		if code, err = waitForAuthorizationCode(context.Background(), listener, callbackPath, state); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("🔄 Exchanging authorization code for tokens...")
+	tokens, err := exchangeAuthorizationCode(http.DefaultClient, *clientID, *clientSecret, code)
+	if err != nil {
+		return err
+	}
+
+	if err := saveOAuthTokens(store, tokens); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Tokens stored in %s\n", store.Name())
+	fmt.Printf("Scopes:        %s\n", tokens.Scope)
+	fmt.Printf("Expires in:    %d seconds (%.1f hours)\n", tokens.ExpiresIn, float64(tokens.ExpiresIn)/3600)
+	return nil
+}
+
+// readAuthorizationCode reads one line holding either the full redirect URL,
+// whose state must match, or a bare authorization code
+func readAuthorizationCode(r io.Reader, expectedState string) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("no authorization code entered")
+	}
+	line = strings.TrimSpace(line)
+
+	redirect, err := url.Parse(line)
+	if err != nil || redirect.RawQuery == "" {
+		if line == "" {
+			return "", fmt.Errorf("no authorization code entered")
+		}
+		return line, nil
+	}
+	query := redirect.Query()
+	switch {
+	case query.Get("error") != "":
+		return "", fmt.Errorf("authorization denied: %s %s", query.Get("error"), query.Get("error_description"))
+	case query.Get("code") == "":
+		return "", fmt.Errorf("redirect URL did not include an authorization code")
+	case query.Get("state") != expectedState:
+		return "", fmt.Errorf("redirect state did not match this authorization attempt")
+	}
+	return query.Get("code"), nil
+}
+
+// runRefreshCommand implements `whoop-mcp-server refresh`: it exchanges the
+// refresh token for a new token pair and stores it in the token store
+func runRefreshCommand(settings Settings, args []string) error {
+	flags := flag.NewFlagSet("refresh", flag.ContinueOnError)
+	clientID := flags.String("client-id", settings("WHOOP_CLIENT_ID"), "Whoop app client ID (defaults to WHOOP_CLIENT_ID)")
+	clientSecret := flags.String("client-secret", settings("WHOOP_CLIENT_SECRET"), "Whoop app client secret (defaults to WHOOP_CLIENT_SECRET)")
+	refreshToken := flags.String("refresh-token", settings("WHOOP_REFRESH_TOKEN"), "Refresh token (defaults to WHOOP_REFRESH_TOKEN, then the token store)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *clientID == "" || *clientSecret == "" {
+		return fmt.Errorf("client ID and secret are required (flags or WHOOP_CLIENT_ID / WHOOP_CLIENT_SECRET)")
+	}
+
+	store, err := NewTokenStoreFromEnv(settings)
+	if err != nil {
+		return err
+	}
+	if *refreshToken == "" {
+		stored, err := store.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load tokens from %s: %w", store.Name(), err)
+		}
+		if stored != nil {
+			*refreshToken = stored.RefreshToken
+		}
+	}
+	if *refreshToken == "" {
+		return fmt.Errorf("no refresh token found; run `whoop-mcp-server auth` first")
+	}
+
+	_, timeout, err := clientLimitsFromEnv(settings)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("🔄 Refreshing access token...")
+	client := &WhoopClient{client: newHTTPClient(timeout), clientID: *clientID, clientSecret: *clientSecret}
+	refreshed, err := client.requestTokenRefresh(*refreshToken)
+	if err != nil {
+		return err
+	}
+	tokens := &OAuthTokenResponse{
+		AccessToken:  refreshed.AccessToken,
+		RefreshToken: refreshed.RefreshToken,
+		TokenType:    refreshed.TokenType,
+		ExpiresIn:    refreshed.ExpiresIn,
+		Scope:        refreshed.Scope,
+	}
+	// Keep the current refresh token unless a new one was issued
+	if tokens.RefreshToken == "" {
+		tokens.RefreshToken = *refreshToken
+	}
+	if err := saveOAuthTokens(store, tokens); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Tokens stored in %s\n", store.Name())
+	fmt.Printf("Expires in:    %d seconds (%.1f hours)\n", tokens.ExpiresIn, float64(tokens.ExpiresIn)/3600)
+	return nil
+}
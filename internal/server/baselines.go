@@ -0,0 +1,322 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+const (
+	// baselineShortDays and baselineLongDays are the personal baseline windows
+	baselineShortDays = 30
+	baselineLongDays  = 90
+	// minBaselineSamples is the fewest scored days needed before a window is trusted
+	minBaselineSamples = 14
+	// baselineMaxAge is how long a persisted baseline is reused before recomputing
+	baselineMaxAge = 24 * time.Hour
+)
+
+// Fixed cutoffs used until enough history exists for a personal baseline
+const (
+	defaultHRVElevationRatio   = 1.2 // HRV 20% above the running mean (legacy stress model)
+	defaultHRVSuppressionRatio = 0.8 // HRV 20% below the running mean
+	defaultMismatchStrain      = 14  // day strain that is hard going on a poor recovery
+	defaultRHRElevationBPM     = 10  // resting HR 10 bpm above the running mean
+	defaultPoorRecovery        = 33  // Whoop's red recovery zone
+	defaultRecoveryDrop        = 30  // points lost over three days
+	defaultSevereSleepHours    = 5   // hours per night
+)
+
+// BaselineStat summarizes one metric over a baseline window
+type BaselineStat struct {
+	Mean    float64 `json:"mean"`
+	StdDev  float64 `json:"std_dev"`
+	Median  float64 `json:"median"`
+	Samples int     `json:"samples"`
+}
+
+// BaselineWindow holds the personal baselines over one trailing window
+type BaselineWindow struct {
+	Days      int          `json:"days"`
+	HRV       BaselineStat `json:"hrv"`
+	RestingHR BaselineStat `json:"resting_hr"`
+	Recovery  BaselineStat `json:"recovery"`
+	SleepNeed BaselineStat `json:"sleep_need_hours"` // Whoop's baseline sleep need
+	Strain    BaselineStat `json:"strain"`
+
+	// StrainTolerance is the highest day strain routinely followed by a
+	// recovery at or above the window's median
+	StrainTolerance float64 `json:"strain_tolerance"`
+}
+
+// PersonalBaseline is a user's 30- and 90-day physiological baselines
+type PersonalBaseline struct {
+	UserID     int            `json:"user_id"`
+	ComputedAt time.Time      `json:"computed_at"`
+	Short      BaselineWindow `json:"short_term"`
+	Long       BaselineWindow `json:"long_term"`
+
+	// TravelDays are the local days around timezone changes left out of both windows
+	TravelDays []string `json:"travel_days,omitempty"`
+}
+
+// Stale reports whether the baseline should be recomputed
+func (b *PersonalBaseline) Stale(now time.Time) bool {
+	return b == nil || now.Sub(b.ComputedAt) > baselineMaxAge
+}
+
+// reference returns the window thresholds should be anchored to: the 90-day
+// window when it has enough data, otherwise the 30-day window
+func (b *PersonalBaseline) reference() (BaselineWindow, bool) {
+	if b == nil {
+		return BaselineWindow{}, false
+	}
+	if b.Long.Recovery.Samples >= minBaselineSamples {
+		return b.Long, true
+	}
+	if b.Short.Recovery.Samples >= minBaselineSamples {
+		return b.Short, true
+	}
+	return BaselineWindow{}, false
+}
+
+// BaselineThresholds are the cutoffs used by the stress model and red flags.
+// HRVLow, HRVHigh, and RHRHigh are zero when no baseline exists, in which case
+// each day is compared against the running mean of the analyzed window
+// instead; StrainHigh is zero without a strain tolerance.
+type BaselineThresholds struct {
+	Source           string  `json:"source"` // "personal_baseline" or "default"
+	HRVLow           float64 `json:"hrv_low"`
+	HRVHigh          float64 `json:"hrv_high"` // used by the legacy stress model only
+	RHRHigh          float64 `json:"rhr_high"`
+	StrainHigh       float64 `json:"strain_high"`
+	PoorRecovery     float64 `json:"poor_recovery"`
+	RecoveryDrop     float64 `json:"recovery_drop"`
+	SevereSleepHours float64 `json:"severe_sleep_hours"`
+}
+
+// defaultThresholds returns the fixed population cutoffs
+func defaultThresholds() BaselineThresholds {
+	return BaselineThresholds{
+		Source:           "default",
+		PoorRecovery:     defaultPoorRecovery,
+		RecoveryDrop:     defaultRecoveryDrop,
+		SevereSleepHours: defaultSevereSleepHours,
+	}
+}
+
+// Thresholds derives stress and red-flag cutoffs from the baseline, falling
+// back to fixed cutoffs when there is not enough history
+func (b *PersonalBaseline) Thresholds() BaselineThresholds {
+	window, ok := b.reference()
+	if !ok {
+		return defaultThresholds()
+	}
+
+	thresholds := BaselineThresholds{
+		Source:           "personal_baseline",
+		HRVLow:           window.HRV.Mean - math.Max(1.5*window.HRV.StdDev, 0.1*window.HRV.Mean),
+		HRVHigh:          window.HRV.Mean + 2*window.HRV.StdDev,
+		RHRHigh:          window.RestingHR.Mean + math.Max(2*window.RestingHR.StdDev, 3),
+		StrainHigh:       window.StrainTolerance,
+		PoorRecovery:     clamp(window.Recovery.Mean-1.5*window.Recovery.StdDev, 10, 50),
+		RecoveryDrop:     clamp(2*window.Recovery.StdDev, 15, 40),
+		SevereSleepHours: defaultSevereSleepHours,
+	}
+	if window.SleepNeed.Samples > 0 {
+		thresholds.SevereSleepHours = math.Max(window.SleepNeed.Mean-3, 4)
+	}
+	return thresholds
+}
+
+// clamp bounds v to [lo, hi]
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
+
+// ComputeBaseline builds 30- and 90-day baselines from data ending at now,
+// leaving out the days around timezone changes
+func (h *HealthAnalyzer) ComputeBaseline(data *HealthData, userID int, now time.Time) PersonalBaseline {
+	excluded := travelExcludedDays(h.DetectTravel(data.Sleeps))
+	baseline := PersonalBaseline{
+		UserID:     userID,
+		ComputedAt: now,
+		Short:      h.baselineWindow(data, now, baselineShortDays, excluded),
+		Long:       h.baselineWindow(data, now, baselineLongDays, excluded),
+	}
+	cutoff := dayKey(now.AddDate(0, 0, -baselineLongDays))
+	for day := range excluded {
+		if day >= cutoff && day <= dayKey(now) {
+			baseline.TravelDays = append(baseline.TravelDays, day)
+		}
+	}
+	sort.Strings(baseline.TravelDays)
+	return baseline
+}
+
+// baselineWindow computes baselines over the days before now, skipping the
+// excluded local days
+func (h *HealthAnalyzer) baselineWindow(data *HealthData, now time.Time, days int, excluded map[string]bool) BaselineWindow {
+	cutoff := now.AddDate(0, 0, -days)
+	inWindow := func(key string) []datedValue {
+		metric, _ := lookupMetric(key)
+		var values []datedValue
+		for _, v := range metric.extract(data) {
+			if !v.Date.Before(cutoff) && !v.Date.After(now) && !excluded[dayKey(v.Date)] {
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+
+	var sleepNeed []float64
+	mainSleeps, _ := splitNaps(data.Sleeps)
+	for _, sleep := range mainSleeps {
+		if sleep.End.Before(cutoff) || sleep.End.After(now) || sleep.Score.SleepNeeded.BaselineMilli == 0 ||
+			excluded[dayKey(localTime(sleep.End, sleep.TimezoneOffset))] {
+			continue
+		}
+		sleepNeed = append(sleepNeed, float64(sleep.Score.SleepNeeded.BaselineMilli)/(1000*60*60))
+	}
+
+	recovery := inWindow("recovery")
+	strain := inWindow("strain")
+	window := BaselineWindow{
+		Days:      days,
+		HRV:       h.baselineStat(valuesOf(inWindow("hrv"))),
+		RestingHR: h.baselineStat(valuesOf(inWindow("resting_hr"))),
+		Recovery:  h.baselineStat(valuesOf(recovery)),
+		SleepNeed: h.baselineStat(sleepNeed),
+		Strain:    h.baselineStat(valuesOf(strain)),
+	}
+	window.StrainTolerance = strainTolerance(strain, recovery, window.Recovery.Median)
+	return window
+}
+
+// baselineStat summarizes a set of values
+func (h *HealthAnalyzer) baselineStat(values []float64) BaselineStat {
+	return BaselineStat{
+		Mean:    h.calculateMean(values),
+		StdDev:  h.calculateStdDev(values),
+		Median:  stats.Median(values),
+		Samples: len(values),
+	}
+}
+
+// strainTolerance returns the 75th percentile of day strains followed by a
+// next-morning recovery at or above medianRecovery
+func strainTolerance(strain, recovery []datedValue, medianRecovery float64) float64 {
+	nextRecovery := dailyAverages(recovery)
+	var tolerated []float64
+	for _, day := range strain {
+		score, ok := nextRecovery[dayKey(day.Date.AddDate(0, 0, 1))]
+		if ok && score >= medianRecovery {
+			tolerated = append(tolerated, day.Value)
+		}
+	}
+	if len(tolerated) == 0 {
+		return 0
+	}
+	sort.Float64s(tolerated)
+	return tolerated[int(0.75*float64(len(tolerated)-1))]
+}
+
+// FormatPersonalBaseline renders both baseline windows and the derived thresholds
+func (h *HealthAnalyzer) FormatPersonalBaseline(baseline PersonalBaseline) string {
+	var builder strings.Builder
+	builder.WriteString("# Personal Baselines\n\n")
+	builder.WriteString(fmt.Sprintf("**Computed:** %s\n\n", baseline.ComputedAt.Format("2006-01-02 15:04")))
+
+	builder.WriteString("| Metric | 30-day | 90-day |\n|---|---|---|\n")
+	row := func(label, unit string, short, long BaselineStat) {
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s |\n", label, formatBaselineStat(short, unit), formatBaselineStat(long, unit)))
+	}
+	row("HRV", "ms", baseline.Short.HRV, baseline.Long.HRV)
+	row("Resting HR", "bpm", baseline.Short.RestingHR, baseline.Long.RestingHR)
+	row("Recovery", "%", baseline.Short.Recovery, baseline.Long.Recovery)
+	row("Sleep Need", "h", baseline.Short.SleepNeed, baseline.Long.SleepNeed)
+	row("Day Strain", "", baseline.Short.Strain, baseline.Long.Strain)
+	builder.WriteString(fmt.Sprintf("| Strain Tolerance | %.1f | %.1f |\n\n", baseline.Short.StrainTolerance, baseline.Long.StrainTolerance))
+	if len(baseline.TravelDays) > 0 {
+		builder.WriteString(fmt.Sprintf("_%d days around timezone changes are left out so jet lag does not skew the baselines._\n\n", len(baseline.TravelDays)))
+	}
+
+	thresholds := baseline.Thresholds()
+	builder.WriteString("## Alert Thresholds\n")
+	if thresholds.Source != "personal_baseline" {
+		builder.WriteString(fmt.Sprintf("Fewer than %d scored days are available, so fixed population cutoffs are still in use.\n", minBaselineSamples))
+		return builder.String()
+	}
+	builder.WriteString(fmt.Sprintf("- **Suppressed HRV:** below %.0f ms\n", thresholds.HRVLow))
+	builder.WriteString(fmt.Sprintf("- **Elevated Resting HR:** above %.0f bpm\n", thresholds.RHRHigh))
+	if thresholds.StrainHigh > 0 {
+		builder.WriteString(fmt.Sprintf("- **Strain Beyond Tolerance:** above %.1f\n", thresholds.StrainHigh))
+	}
+	builder.WriteString(fmt.Sprintf("- **Poor Recovery:** below %.0f%%\n", thresholds.PoorRecovery))
+	builder.WriteString(fmt.Sprintf("- **Dramatic Recovery Drop:** more than %.0f points\n", thresholds.RecoveryDrop))
+	builder.WriteString(fmt.Sprintf("- **Severe Sleep Deprivation:** under %.1f hours\n", thresholds.SevereSleepHours))
+	return builder.String()
+}
+
+// formatBaselineStat renders mean ± SD with the sample count
+func formatBaselineStat(stat BaselineStat, unit string) string {
+	if stat.Samples == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%s ± %.1f (n=%d)", stat.Mean, unit, stat.StdDev, stat.Samples)
+}
+
+// BaselineStore persists personal baselines in a private JSON file keyed by user ID
+type BaselineStore struct {
+	Path string
+	mu   sync.Mutex
+	storeMode
+}
+
+// NewBaselineStoreFromEnv uses WHOOP_BASELINE_FILE or the default datastore path,
+// encrypting it with cipher when it is set
+func NewBaselineStoreFromEnv(settings Settings, cipher *DataCipher) (*BaselineStore, error) {
+	path, err := localDataPath(settings, "WHOOP_BASELINE_FILE", "baselines.json")
+	if err != nil {
+		return nil, err
+	}
+	return &BaselineStore{Path: path, storeMode: storeMode{cipher: cipher}}, nil
+}
+
+// Load returns the stored baseline for a user, or nil when none exists
+func (b *BaselineStore) Load(userID int) (*PersonalBaseline, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	baselines := make(map[string]PersonalBaseline)
+	if err := readJSONFile(b.cipher, b.Path, &baselines); err != nil {
+		return nil, err
+	}
+	baseline, ok := baselines[strconv.Itoa(userID)]
+	if !ok {
+		return nil, nil
+	}
+	return &baseline, nil
+}
+
+// Save stores a baseline, replacing any previous one for the same user
+func (b *BaselineStore) Save(baseline PersonalBaseline) error {
+	if err := b.writable(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	baselines := make(map[string]PersonalBaseline)
+	if err := readJSONFile(b.cipher, b.Path, &baselines); err != nil {
+		return err
+	}
+	baselines[strconv.Itoa(baseline.UserID)] = baseline
+	return writeJSONFile(b.cipher, b.Path, baselines)
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func baselineTestData(now time.Time, days int) *HealthData {
+	data := &HealthData{}
+	for i := 0; i < days; i++ {
+		day := now.AddDate(0, 0, -i)
+
+		var recovery WhoopRecovery
+		recovery.CreatedAt = day
+		recovery.Score.RecoveryScore = 60 + float64(i%5)*4
+		recovery.Score.HRVRmssd = 60 + float64(i%3)
+		recovery.Score.RestingHeartRate = 50 + float64(i%2)
+		data.Recoveries = append(data.Recoveries, recovery)
+
+		var sleep WhoopSleep
+		sleep.End = day
+		sleep.Score.SleepNeeded.BaselineMilli = int(7.5 * float64(time.Hour/time.Millisecond))
+		data.Sleeps = append(data.Sleeps, sleep)
+	}
+	return data
+}
+
+func TestHealthAnalyzer_ComputeBaseline(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	now := time.Date(2024, 10, 1, 8, 0, 0, 0, time.UTC)
+
+	baseline := analyzer.ComputeBaseline(baselineTestData(now, 60), 42, now)
+
+	if baseline.Short.Recovery.Samples != 31 || baseline.Long.Recovery.Samples != 60 {
+		t.Errorf("Expected 31/60 samples, got %d/%d", baseline.Short.Recovery.Samples, baseline.Long.Recovery.Samples)
+	}
+	if baseline.Long.SleepNeed.Mean != 7.5 {
+		t.Errorf("Expected 7.5h sleep need, got %.2f", baseline.Long.SleepNeed.Mean)
+	}
+
+	thresholds := baseline.Thresholds()
+	if thresholds.Source != "personal_baseline" {
+		t.Fatalf("Expected personal thresholds, got %s", thresholds.Source)
+	}
+	// RHR alternates 50/51, so the 3 bpm floor applies
+	if thresholds.RHRHigh < 53 || thresholds.RHRHigh > 54 {
+		t.Errorf("Expected RHR threshold near 53.5, got %.2f", thresholds.RHRHigh)
+	}
+	if thresholds.SevereSleepHours != 4.5 {
+		t.Errorf("Expected severe sleep cutoff 4.5h, got %.2f", thresholds.SevereSleepHours)
+	}
+}
+
+func TestPersonalBaseline_ThresholdsFallback(t *testing.T) {
+	var baseline *PersonalBaseline
+	if got := baseline.Thresholds(); got != defaultThresholds() {
+		t.Errorf("Expected default thresholds for a nil baseline, got %+v", got)
+	}
+}
+
+func TestBaselineStore_SaveLoad(t *testing.T) {
+	store := &BaselineStore{Path: filepath.Join(t.TempDir(), "baselines.json")}
+
+	if loaded, err := store.Load(7); err != nil || loaded != nil {
+		t.Fatalf("Expected empty store, got %v, %v", loaded, err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	if err := store.Save(PersonalBaseline{UserID: 7, ComputedAt: now}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load(7)
+	if err != nil || loaded == nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !loaded.ComputedAt.Equal(now) || loaded.Stale(now) {
+		t.Errorf("Unexpected loaded baseline: %+v", loaded)
+	}
+}
+
+func TestPersonalBaseline_DefaultAccountKey(t *testing.T) {
+	mock := NewSyntheticMockWhoopAPI(SyntheticOptions{Seed: 5, Days: 120})
+	server := newMockServer(t, mock)
+
+	if _, err := server.executeTool(context.Background(), "get_health_summary", json.RawMessage(`{"start_date": "past 7 days"}`)); err != nil {
+		t.Fatalf("get_health_summary error = %v", err)
+	}
+	stored, err := server.baselines.Load(mock.User.UserID)
+	if err != nil || stored == nil {
+		t.Fatalf("baseline for user %d = %v, %v; want the summary to store it under the user's ID", mock.User.UserID, stored, err)
+	}
+
+	fetches := mock.Calls("GetRecoveryData")
+	if _, err := server.executeTool(context.Background(), "get_personal_baselines", json.RawMessage(`{}`)); err != nil {
+		t.Fatalf("get_personal_baselines error = %v", err)
+	}
+	if mock.Calls("GetRecoveryData") != fetches {
+		t.Error("Expected get_personal_baselines to reuse the baseline the summary stored")
+	}
+	if other, _ := server.baselines.Load(0); other != nil {
+		t.Error("Expected no second baseline keyed by 0 for the default account")
+	}
+}
@@ -0,0 +1,308 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// burnoutComponentMax is each component's share of the 0-100 score
+	burnoutComponentMax = 25.0
+	// minBurnoutWeeks is the fewest complete weeks needed to judge trends
+	minBurnoutWeeks = 3
+	// minDaysPerBurnoutWeek is the fewest scored days for a week to count
+	minDaysPerBurnoutWeek = 3
+	// chronicStrainWeeks is the trailing window checked for sustained high strain
+	chronicStrainWeeks = 4
+	// highWeeklyStrain is the average day strain that counts as a heavy week
+	highWeeklyStrain = 14.0
+	// burnoutHistoryLimit caps the snapshots kept per user
+	burnoutHistoryLimit = 104
+)
+
+// BurnoutWeek is one week's averages used by the burnout model
+type BurnoutWeek struct {
+	WeekStart        string  `json:"week_start"`
+	HRV              float64 `json:"hrv"`
+	RestingHR        float64 `json:"resting_hr"`
+	SleepConsistency float64 `json:"sleep_consistency"` // 0-100
+	Strain           float64 `json:"strain"`
+}
+
+// BurnoutComponent is one contributor to the burnout score
+type BurnoutComponent struct {
+	Key    string  `json:"key"`
+	Label  string  `json:"label"`
+	Score  float64 `json:"score"` // 0-25
+	Detail string  `json:"detail"`
+}
+
+// BurnoutSnapshot is the burnout score as of one week, as persisted
+type BurnoutSnapshot struct {
+	UserID     int                `json:"user_id"`
+	WeekStart  string             `json:"week_start"`
+	Score      float64            `json:"score"` // 0-100
+	Level      string             `json:"level"` // "insufficient_data", "low", "moderate", "high", "severe"
+	Components []BurnoutComponent `json:"components"`
+	RecordedAt time.Time          `json:"recorded_at"`
+}
+
+// BurnoutRisk is the current assessment with the weekly inputs and stored history
+type BurnoutRisk struct {
+	Current BurnoutSnapshot   `json:"current"`
+	Weeks   []BurnoutWeek     `json:"weeks"`
+	History []BurnoutSnapshot `json:"history"`
+}
+
+// AssessBurnoutRisk aggregates data into weeks and scores four slow-moving
+// signals: weeks of declining HRV, weeks of rising resting HR, poor sleep
+// consistency, and sustained high strain. Each contributes up to 25 points.
+func (h *HealthAnalyzer) AssessBurnoutRisk(data *HealthData, userID int) BurnoutRisk {
+	weeks := h.burnoutWeeks(data)
+	snapshot := BurnoutSnapshot{UserID: userID, Level: "insufficient_data", Components: []BurnoutComponent{}, RecordedAt: time.Now()}
+	risk := BurnoutRisk{Current: snapshot, Weeks: weeks, History: []BurnoutSnapshot{}}
+	if len(weeks) < minBurnoutWeeks {
+		return risk
+	}
+
+	latest := weeks[len(weeks)-1]
+	earlier := weeks[:len(weeks)-1]
+	snapshot.WeekStart = latest.WeekStart
+
+	hrvWeeks := 0
+	for i := len(weeks) - 1; i > 0 && weeks[i].HRV < weeks[i-1].HRV; i-- {
+		hrvWeeks++
+	}
+	hrvBaseline := h.calculateMean(burnoutColumn(earlier, func(w BurnoutWeek) float64 { return w.HRV }))
+	hrvChange := 0.0
+	if hrvBaseline > 0 {
+		hrvChange = (latest.HRV - hrvBaseline) / hrvBaseline * 100
+	}
+	snapshot.Components = append(snapshot.Components, BurnoutComponent{
+		Key:    "hrv_decline",
+		Label:  "Declining HRV",
+		Score:  clamp(float64(hrvWeeks)*4+maxFloat([]float64{0, -hrvChange}), 0, burnoutComponentMax),
+		Detail: fmt.Sprintf("%d consecutive week(s) of decline; latest week %+.0f%% vs earlier weeks", hrvWeeks, hrvChange),
+	})
+
+	rhrWeeks := 0
+	for i := len(weeks) - 1; i > 0 && weeks[i].RestingHR > weeks[i-1].RestingHR; i-- {
+		rhrWeeks++
+	}
+	rhrRise := latest.RestingHR - h.calculateMean(burnoutColumn(earlier, func(w BurnoutWeek) float64 { return w.RestingHR }))
+	snapshot.Components = append(snapshot.Components, BurnoutComponent{
+		Key:    "rhr_rise",
+		Label:  "Rising resting HR",
+		Score:  clamp(float64(rhrWeeks)*4+maxFloat([]float64{0, rhrRise})*3, 0, burnoutComponentMax),
+		Detail: fmt.Sprintf("%d consecutive week(s) of increase; latest week %+.1f bpm vs earlier weeks", rhrWeeks, rhrRise),
+	})
+
+	recentConsistency := h.calculateMean(burnoutColumn(weeks[len(weeks)-2:], func(w BurnoutWeek) float64 { return w.SleepConsistency }))
+	snapshot.Components = append(snapshot.Components, BurnoutComponent{
+		Key:    "sleep_inconsistency",
+		Label:  "Poor sleep consistency",
+		Score:  clamp((80-recentConsistency)/40*burnoutComponentMax, 0, burnoutComponentMax),
+		Detail: fmt.Sprintf("%.0f%% sleep consistency over the last two weeks", recentConsistency),
+	})
+
+	recentStrain := weeks
+	if len(recentStrain) > chronicStrainWeeks {
+		recentStrain = recentStrain[len(recentStrain)-chronicStrainWeeks:]
+	}
+	heavyWeeks := 0
+	for _, week := range recentStrain {
+		if week.Strain >= highWeeklyStrain {
+			heavyWeeks++
+		}
+	}
+	snapshot.Components = append(snapshot.Components, BurnoutComponent{
+		Key:    "chronic_strain",
+		Label:  "Sustained high strain",
+		Score:  float64(heavyWeeks) / float64(len(recentStrain)) * burnoutComponentMax,
+		Detail: fmt.Sprintf("%d of the last %d weeks averaged strain %.0f+", heavyWeeks, len(recentStrain), highWeeklyStrain),
+	})
+
+	for _, component := range snapshot.Components {
+		snapshot.Score += component.Score
+	}
+	switch {
+	case snapshot.Score >= 75:
+		snapshot.Level = "severe"
+	case snapshot.Score >= 50:
+		snapshot.Level = "high"
+	case snapshot.Score >= 25:
+		snapshot.Level = "moderate"
+	default:
+		snapshot.Level = "low"
+	}
+
+	risk.Current = snapshot
+	return risk
+}
+
+// burnoutWeeks averages the model inputs per Monday-start week, dropping sparse weeks
+func (h *HealthAnalyzer) burnoutWeeks(data *HealthData) []BurnoutWeek {
+	weekly := func(key string) map[string][]float64 {
+		metric, _ := lookupMetric(key)
+		byWeek := make(map[string][]float64)
+		for _, v := range metric.extract(data) {
+			week := dayKey(bucketStart(v.Date, granularityWeekly))
+			byWeek[week] = append(byWeek[week], v.Value)
+		}
+		return byWeek
+	}
+	hrv := weekly("hrv")
+	rhr := weekly("resting_hr")
+	strain := weekly("strain")
+	sleepHours := weekly("sleep_hours")
+
+	consistency := make(map[string][]float64)
+	for _, v := range mainSleepValues(data.Sleeps, func(s WhoopSleep) float64 { return s.Score.SleepConsistencyPercentage }) {
+		if v.Value > 0 {
+			week := dayKey(bucketStart(v.Date, granularityWeekly))
+			consistency[week] = append(consistency[week], v.Value)
+		}
+	}
+
+	var weeks []BurnoutWeek
+	for week, values := range hrv {
+		if len(values) < minDaysPerBurnoutWeek {
+			continue
+		}
+		entry := BurnoutWeek{
+			WeekStart: week,
+			HRV:       h.calculateMean(values),
+			RestingHR: h.calculateMean(rhr[week]),
+			Strain:    h.calculateMean(strain[week]),
+		}
+		if scores := consistency[week]; len(scores) > 0 {
+			entry.SleepConsistency = h.calculateMean(scores)
+		} else {
+			// Without Whoop's score, approximate consistency from night-to-night variation
+			entry.SleepConsistency = clamp(100-h.calculateStdDev(sleepHours[week])*25, 0, 100)
+		}
+		weeks = append(weeks, entry)
+	}
+	sort.Slice(weeks, func(i, j int) bool {
+		return weeks[i].WeekStart < weeks[j].WeekStart
+	})
+	return weeks
+}
+
+// burnoutColumn extracts one field across weeks
+func burnoutColumn(weeks []BurnoutWeek, field func(BurnoutWeek) float64) []float64 {
+	values := make([]float64, len(weeks))
+	for i, week := range weeks {
+		values[i] = field(week)
+	}
+	return values
+}
+
+// FormatBurnoutRisk renders the current score, its components, and the stored history
+func (h *HealthAnalyzer) FormatBurnoutRisk(risk BurnoutRisk) string {
+	var builder strings.Builder
+	builder.WriteString("# Burnout Risk\n\n")
+
+	current := risk.Current
+	if current.Level == "insufficient_data" {
+		builder.WriteString(fmt.Sprintf("At least %d weeks with %d+ scored days each are needed (found %d).\n", minBurnoutWeeks, minDaysPerBurnoutWeek, len(risk.Weeks)))
+		return builder.String()
+	}
+
+	icon := map[string]string{"low": "🟢", "moderate": "🟡", "high": "🟠", "severe": "🔴"}[current.Level]
+	builder.WriteString(fmt.Sprintf("**Score:** %.0f/100 %s %s (week of %s)\n\n", current.Score, icon, strings.ToUpper(current.Level), current.WeekStart))
+
+	builder.WriteString("## Components\n")
+	for _, component := range current.Components {
+		builder.WriteString(fmt.Sprintf("- **%s** (%.0f/25): %s\n", component.Label, component.Score, component.Detail))
+	}
+
+	if len(risk.History) > 1 {
+		builder.WriteString("\n## History\n")
+		for _, snapshot := range risk.History {
+			builder.WriteString(fmt.Sprintf("- %s: %.0f (%s)\n", snapshot.WeekStart, snapshot.Score, snapshot.Level))
+		}
+	}
+
+	builder.WriteString("\n## What This Means\n")
+	switch current.Level {
+	case "severe", "high":
+		builder.WriteString("Several slow-moving stress markers have been deteriorating together for weeks, the physiological signature of burnout. Explore workload, sleep protection, and recovery time; consider whether a break or reduced commitments are possible.\n")
+	case "moderate":
+		builder.WriteString("Some markers are drifting the wrong way. This is the window where small changes to workload and sleep routine are most effective.\n")
+	default:
+		builder.WriteString("Long-term stress markers are stable.\n")
+	}
+	builder.WriteString("\n*Burnout is a clinical and occupational construct; this score reflects physiology only.*\n")
+	return builder.String()
+}
+
+// BurnoutStore persists weekly burnout snapshots per user in the local datastore
+type BurnoutStore struct {
+	Path string
+	mu   sync.Mutex
+	storeMode
+}
+
+// NewBurnoutStoreFromEnv uses WHOOP_BURNOUT_FILE or the default datastore path,
+// encrypting it with cipher when it is set
+func NewBurnoutStoreFromEnv(settings Settings, cipher *DataCipher) (*BurnoutStore, error) {
+	path, err := localDataPath(settings, "WHOOP_BURNOUT_FILE", "burnout.json")
+	if err != nil {
+		return nil, err
+	}
+	return &BurnoutStore{Path: path, storeMode: storeMode{cipher: cipher}}, nil
+}
+
+// History returns the user's snapshots in chronological order
+func (b *BurnoutStore) History(userID int) ([]BurnoutSnapshot, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	all := make(map[string][]BurnoutSnapshot)
+	if err := readJSONFile(b.cipher, b.Path, &all); err != nil {
+		return nil, err
+	}
+	return all[strconv.Itoa(userID)], nil
+}
+
+// Record stores a snapshot, replacing any earlier one for the same week, and
+// returns the user's history in chronological order. In read-only mode the
+// history includes the snapshot but nothing is stored.
+func (b *BurnoutStore) Record(snapshot BurnoutSnapshot) ([]BurnoutSnapshot, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	all := make(map[string][]BurnoutSnapshot)
+	if err := readJSONFile(b.cipher, b.Path, &all); err != nil {
+		return nil, err
+	}
+
+	key := strconv.Itoa(snapshot.UserID)
+	history := all[key][:0:0]
+	for _, existing := range all[key] {
+		if existing.WeekStart != snapshot.WeekStart {
+			history = append(history, existing)
+		}
+	}
+	history = append(history, snapshot)
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].WeekStart < history[j].WeekStart
+	})
+	if len(history) > burnoutHistoryLimit {
+		history = history[len(history)-burnoutHistoryLimit:]
+	}
+
+	if b.readOnly.Load() {
+		return history, nil // reported, but not stored
+	}
+	all[key] = history
+	if err := writeJSONFile(b.cipher, b.Path, all); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
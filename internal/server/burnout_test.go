@@ -0,0 +1,101 @@
+package server
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func burnoutTestData(weeks int, decline bool) *HealthData {
+	hour := int(time.Hour / time.Millisecond)
+	start := time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC) // a Monday
+
+	data := &HealthData{}
+	for i := 0; i < weeks*7; i++ {
+		wake := start.AddDate(0, 0, i)
+		week := float64(i / 7)
+
+		hrv, rhr, strain, consistency := 60.0, 52.0, 10.0, 85.0
+		if decline {
+			hrv = 70 - 4*week
+			rhr = 50 + week
+			strain = 15
+			consistency = 55
+		}
+
+		var sleep WhoopSleep
+		sleep.ID = fmt.Sprintf("sleep-%d", i)
+		sleep.Start = wake.Add(-7 * time.Hour)
+		sleep.End = wake
+		sleep.Score.StageSummary.TotalInBedTimeMilli = 7 * hour
+		sleep.Score.SleepConsistencyPercentage = consistency
+		data.Sleeps = append(data.Sleeps, sleep)
+
+		var recovery WhoopRecovery
+		recovery.SleepID = sleep.ID
+		recovery.CreatedAt = wake
+		recovery.Score.HRVRmssd = hrv
+		recovery.Score.RestingHeartRate = rhr
+		data.Recoveries = append(data.Recoveries, recovery)
+
+		var cycle WhoopCycle
+		cycle.Start = wake.Add(-8 * time.Hour)
+		cycle.Score.Strain = strain
+		data.Cycles = append(data.Cycles, cycle)
+	}
+	return data
+}
+
+func TestHealthAnalyzer_AssessBurnoutRisk(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+
+	stable := analyzer.AssessBurnoutRisk(burnoutTestData(8, false), 0)
+	if stable.Current.Level != "low" {
+		t.Errorf("Expected low risk for stable data, got %s (%.0f)", stable.Current.Level, stable.Current.Score)
+	}
+	if len(stable.Weeks) != 8 {
+		t.Errorf("Expected 8 weeks, got %d", len(stable.Weeks))
+	}
+
+	declining := analyzer.AssessBurnoutRisk(burnoutTestData(8, true), 0)
+	if declining.Current.Level != "severe" {
+		t.Errorf("Expected severe risk for declining data, got %s (%.0f)", declining.Current.Level, declining.Current.Score)
+	}
+	if declining.Current.WeekStart != "2024-02-19" {
+		t.Errorf("Expected latest week 2024-02-19, got %s", declining.Current.WeekStart)
+	}
+	if len(declining.Current.Components) != 4 {
+		t.Errorf("Expected 4 components, got %d", len(declining.Current.Components))
+	}
+
+	short := analyzer.AssessBurnoutRisk(burnoutTestData(2, true), 0)
+	if short.Current.Level != "insufficient_data" {
+		t.Errorf("Expected insufficient_data for two weeks, got %s", short.Current.Level)
+	}
+}
+
+func TestBurnoutStore_Record(t *testing.T) {
+	store := &BurnoutStore{Path: filepath.Join(t.TempDir(), "burnout.json")}
+
+	for _, snapshot := range []BurnoutSnapshot{
+		{UserID: 1, WeekStart: "2024-01-08", Score: 30},
+		{UserID: 1, WeekStart: "2024-01-01", Score: 20},
+		{UserID: 2, WeekStart: "2024-01-01", Score: 70},
+	} {
+		if _, err := store.Record(snapshot); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	history, err := store.Record(BurnoutSnapshot{UserID: 1, WeekStart: "2024-01-08", Score: 40})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 snapshots for user 1, got %d", len(history))
+	}
+	if history[0].WeekStart != "2024-01-01" || history[1].Score != 40 {
+		t.Errorf("Expected chronological history with the week replaced, got %+v", history)
+	}
+}
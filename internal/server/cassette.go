@@ -0,0 +1,220 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cassette modes for WHOOP_CASSETTE_MODE
+const (
+	cassetteRecord = "record"
+	cassetteReplay = "replay"
+)
+
+// cassetteHeaders are the response headers kept in a cassette; the client
+// reads no others, and leaving the rest out keeps cookies off disk
+var cassetteHeaders = []string{"Content-Type", "Date", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}
+
+// cassetteVolatileParams are ignored when matching requests: they are
+// computed from the current time, so they differ between recording and replay
+var cassetteVolatileParams = []string{"start", "end"}
+
+// CassetteInteraction is one recorded Whoop API request and its response
+type CassetteInteraction struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"` // endpoint and query, relative to the API base URL
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// Cassette is a recorded Whoop API session. It holds the account's health
+// data but never its tokens: only API requests are recorded, without headers.
+type Cassette struct {
+	RecordedAt   time.Time             `json:"recorded_at"`
+	Interactions []CassetteInteraction `json:"interactions"`
+}
+
+// LoadCassette reads a cassette written in record mode
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette to path, readable only by the owner
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+	return writePrivateFile(path, data)
+}
+
+// cassetteFromEnv reads WHOOP_CASSETTE (the cassette file) and
+// WHOOP_CASSETTE_MODE (record, or replay by default). The mode is empty when
+// no cassette is configured.
+func cassetteFromEnv(settings Settings) (mode, path string, err error) {
+	path = strings.TrimSpace(settings("WHOOP_CASSETTE"))
+	if path == "" {
+		return "", "", nil
+	}
+	mode = strings.ToLower(strings.TrimSpace(settings("WHOOP_CASSETTE_MODE")))
+	switch mode {
+	case "":
+		return cassetteReplay, path, nil
+	case cassetteRecord, cassetteReplay:
+		return mode, path, nil
+	}
+	return "", "", fmt.Errorf("invalid WHOOP_CASSETTE_MODE %q: expected record or replay", mode)
+}
+
+// cassetteTransport records Whoop API traffic to a cassette, or replays it.
+// Recording saves after every interaction so a session that ends abruptly
+// keeps what it captured. Replay serves each interaction once, in recorded
+// order among requests that match.
+type cassetteTransport struct {
+	mode    string
+	path    string
+	baseURL string
+	next    http.RoundTripper // the real transport, used when recording
+
+	mu       sync.Mutex
+	cassette *Cassette
+	played   []bool
+}
+
+// newCassetteTransport starts a new cassette at path when recording, or
+// loads the one there when replaying
+func newCassetteTransport(mode, path, baseURL string, next http.RoundTripper) (*cassetteTransport, error) {
+	t := &cassetteTransport{mode: mode, path: path, baseURL: baseURL, next: next}
+	if mode == cassetteRecord {
+		t.cassette = &Cassette{RecordedAt: time.Now().UTC(), Interactions: []CassetteInteraction{}}
+		return t, nil
+	}
+
+	cassette, err := LoadCassette(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no cassette at %s; record one with WHOOP_CASSETTE_MODE=record", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.cassette = cassette
+	t.played = make([]bool, len(cassette.Interactions))
+	return t, nil
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Token requests carry secrets and are never recorded
+	relative, ok := strings.CutPrefix(req.URL.String(), t.baseURL)
+	if !ok {
+		if t.mode == cassetteRecord {
+			return t.next.RoundTrip(req)
+		}
+		return nil, fmt.Errorf("cassette replay only serves Whoop API requests, not %s", req.URL.Redacted())
+	}
+
+	if t.mode == cassetteReplay {
+		return t.replay(req, relative)
+	}
+	return t.record(req, relative)
+}
+
+// replay serves the first unplayed interaction matching the request
+func (t *cassetteTransport) replay(req *http.Request, relative string) (*http.Response, error) {
+	key := cassetteKey(req.Method, relative)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, interaction := range t.cassette.Interactions {
+		if t.played[i] || cassetteKey(interaction.Method, interaction.URL) != key {
+			continue
+		}
+		t.played[i] = true
+		return newHTTPResponse(req, interaction.Status, interaction.Header, []byte(interaction.Body)), nil
+	}
+	return nil, fmt.Errorf("cassette %s has no unplayed interaction for %s %s", t.path, req.Method, relative)
+}
+
+// record forwards the request and appends the exchange to the cassette
+func (t *cassetteTransport) record(req *http.Request, relative string) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := make(http.Header)
+	for _, name := range cassetteHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cassette.Interactions = append(t.cassette.Interactions, CassetteInteraction{
+		Method: req.Method,
+		URL:    relative,
+		Status: resp.StatusCode,
+		Header: header,
+		Body:   string(body),
+	})
+	if err := t.cassette.Save(t.path); err != nil {
+		return nil, fmt.Errorf("failed to save cassette: %w", err)
+	}
+	return resp, nil
+}
+
+// cassetteKey identifies a request for matching, without its volatile parameters
+func cassetteKey(method, relative string) string {
+	endpoint, rawQuery, _ := strings.Cut(relative, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return method + " " + relative
+	}
+	for _, param := range cassetteVolatileParams {
+		query.Del(param)
+	}
+	return method + " " + endpoint + "?" + query.Encode()
+}
+
+// newHTTPResponse builds a complete response around a canned body
+func newHTTPResponse(req *http.Request, status int, header http.Header, body []byte) *http.Response {
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fixtureAPI serves the bundled fixtures over HTTP, standing in for the Whoop API
+func fixtureAPI(t *testing.T, hits *atomic.Int64) *httptest.Server {
+	t.Helper()
+	t.Setenv("WHOOP_FIXTURES_DIR", "")
+	fixtures, err := LoadFixturesFromEnv(os.Getenv, time.Now())
+	if err != nil {
+		t.Fatalf("LoadFixturesFromEnv() error = %v", err)
+	}
+	transport := &fixtureTransport{fixtures: fixtures}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		resp, err := transport.RoundTrip(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// runCassetteSession builds a server from the environment and runs the tool pipeline
+func runCassetteSession(t *testing.T) string {
+	t.Helper()
+	t.Setenv("WHOOP_DATA_DIR", t.TempDir())
+	server, err := NewMCPServer(os.Getenv)
+	if err != nil {
+		t.Fatalf("NewMCPServer() error = %v", err)
+	}
+	var outputs []string
+	for _, call := range []struct{ tool, args string }{
+		{"get_health_summary", `{"start_date": "past 14 days", "end_date": "today"}`},
+		{"analyze_sleep_patterns", `{"start_date": "past 7 days", "end_date": "today"}`},
+	} {
+		output, err := server.executeTool(context.Background(), call.tool, json.RawMessage(call.args))
+		if err != nil {
+			t.Fatalf("%s error = %v", call.tool, err)
+		}
+		outputs = append(outputs, output)
+	}
+	return strings.Join(outputs, "\n")
+}
+
+func TestCassette_RecordThenReplay(t *testing.T) {
+	clearConfigEnv(t)
+	var hits atomic.Int64
+	api := fixtureAPI(t, &hits)
+	path := filepath.Join(t.TempDir(), "session.json")
+
+	t.Setenv("WHOOP_API_BASE_URL", api.URL)
+	t.Setenv("WHOOP_RATE_LIMIT", "1000")
+	t.Setenv("WHOOP_ACCESS_TOKEN", "recording-secret")
+	t.Setenv("WHOOP_CASSETTE", path)
+	t.Setenv("WHOOP_CASSETTE_MODE", cassetteRecord)
+	recorded := runCassetteSession(t)
+	if hits.Load() == 0 {
+		t.Fatal("Expected recording to reach the API")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read cassette: %v", err)
+	}
+	for _, secret := range []string{"recording-secret", "session=secret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Expected the cassette not to contain %q", secret)
+		}
+	}
+
+	// Replay needs neither credentials nor the API
+	api.Close()
+	before := hits.Load()
+	t.Setenv("WHOOP_ACCESS_TOKEN", "")
+	t.Setenv("WHOOP_CASSETTE_MODE", cassetteReplay)
+	replayed := runCassetteSession(t)
+	if hits.Load() != before {
+		t.Error("Expected replay not to reach the API")
+	}
+	if replayed != recorded {
+		t.Errorf("Replayed output differs from the recording:\n--- recorded\n%s\n--- replayed\n%s", recorded, replayed)
+	}
+}
+
+func TestCassette_ReplayMatching(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	cassette := &Cassette{Interactions: []CassetteInteraction{
+		{Method: "GET", URL: "/v2/cycle?end=2024-03-02T00%3A00%3A00Z&limit=25&start=2024-03-01T00%3A00%3A00Z", Status: 200, Body: `{"records": [{"id": 1}]}`},
+		{Method: "GET", URL: "/v2/cycle?limit=25&nextToken=abc", Status: 200, Body: `{"records": [{"id": 2}]}`},
+	}}
+	if err := cassette.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	transport, err := newCassetteTransport(cassetteReplay, path, "https://api.example.com", nil)
+	if err != nil {
+		t.Fatalf("newCassetteTransport() error = %v", err)
+	}
+	client := &http.Client{Transport: transport}
+
+	// A different date range still matches; pages match on nextToken
+	for _, want := range []struct{ url, body string }{
+		{"https://api.example.com/v2/cycle?limit=25&start=2026-01-01T00%3A00%3A00Z", `{"id": 1}`},
+		{"https://api.example.com/v2/cycle?limit=25&nextToken=abc", `{"id": 2}`},
+	} {
+		resp, err := client.Get(want.url)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", want.url, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(string(body), want.body) {
+			t.Errorf("GET %s = %s, want %s", want.url, body, want.body)
+		}
+	}
+
+	// Each interaction plays once
+	if _, err := client.Get("https://api.example.com/v2/cycle?limit=25"); err == nil || !strings.Contains(err.Error(), "no unplayed interaction") {
+		t.Errorf("Expected an exhausted cassette to fail, got %v", err)
+	}
+	if _, err := client.Get("https://other.example.com/oauth/token"); err == nil {
+		t.Error("Expected replay to refuse requests outside the API")
+	}
+}
+
+func TestCassetteFromEnv(t *testing.T) {
+	t.Setenv("WHOOP_CASSETTE", "")
+	if mode, _, err := cassetteFromEnv(os.Getenv); mode != "" || err != nil {
+		t.Errorf("cassetteFromEnv() = %q, %v; want off", mode, err)
+	}
+	t.Setenv("WHOOP_CASSETTE", "session.json")
+	t.Setenv("WHOOP_CASSETTE_MODE", "")
+	if mode, path, err := cassetteFromEnv(os.Getenv); mode != cassetteReplay || path != "session.json" || err != nil {
+		t.Errorf("cassetteFromEnv() = %q, %q, %v; want replay by default", mode, path, err)
+	}
+	t.Setenv("WHOOP_CASSETTE_MODE", "rewind")
+	if _, _, err := cassetteFromEnv(os.Getenv); err == nil {
+		t.Error("Expected WHOOP_CASSETTE_MODE=rewind to be rejected")
+	}
+}
@@ -0,0 +1,333 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	chartWidth  = 720
+	chartHeight = 320
+	// Plot area margins: room for y tick labels on the left and dates below
+	chartMarginLeft   = 56
+	chartMarginRight  = 32
+	chartMarginTop    = 36
+	chartMarginBottom = 36
+	// chartTicks is the target number of y-axis gridlines
+	chartTicks = 5
+)
+
+var (
+	chartBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	chartGrid       = color.RGBA{0xe5, 0xe7, 0xeb, 0xff}
+	chartAxis       = color.RGBA{0x6b, 0x72, 0x80, 0xff}
+	chartLine       = color.RGBA{0x25, 0x63, 0xeb, 0xff}
+)
+
+// chartFormats are the image formats render_chart can produce
+var chartFormats = []string{"png", "svg"}
+
+// Chart is one daily metric plotted over time
+type Chart struct {
+	Title  string
+	Unit   string
+	Points []datedValue
+}
+
+// buildChart collapses a metric to one point per day for plotting
+func buildChart(metric metricDefinition, data *HealthData) Chart {
+	chart := Chart{Title: metric.Label, Unit: metric.Unit}
+	for day, value := range dailyAverages(metric.extract(data)) {
+		date, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			continue
+		}
+		chart.Points = append(chart.Points, datedValue{Date: date, Value: value})
+	}
+	sort.Slice(chart.Points, func(i, j int) bool { return chart.Points[i].Date.Before(chart.Points[j].Date) })
+	return chart
+}
+
+// chartAxes holds the value range and gridlines of a chart
+type chartAxes struct {
+	min, max float64
+	ticks    []float64
+	start    time.Time
+	span     time.Duration
+}
+
+// axes picks rounded gridlines covering the values and the date span
+func (c Chart) axes() chartAxes {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, p := range c.Points {
+		lo, hi = math.Min(lo, p.Value), math.Max(hi, p.Value)
+	}
+	if lo == hi {
+		lo, hi = lo-1, hi+1
+	}
+
+	step := niceStep((hi - lo) / chartTicks)
+	axes := chartAxes{min: math.Floor(lo/step) * step, max: math.Ceil(hi/step) * step}
+	for v := axes.min; v <= axes.max+step/2; v += step {
+		axes.ticks = append(axes.ticks, v)
+	}
+	axes.start = c.Points[0].Date
+	axes.span = c.Points[len(c.Points)-1].Date.Sub(axes.start)
+	return axes
+}
+
+// niceStep rounds a raw tick interval up to 1, 2, or 5 times a power of ten
+func niceStep(raw float64) float64 {
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, multiple := range []float64{1, 2, 5, 10} {
+		if multiple*magnitude >= raw {
+			return multiple * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// x maps a date to a horizontal pixel position
+func (a chartAxes) x(date time.Time) float64 {
+	width := float64(chartWidth - chartMarginLeft - chartMarginRight)
+	if a.span == 0 {
+		return chartMarginLeft + width/2
+	}
+	return chartMarginLeft + width*float64(date.Sub(a.start))/float64(a.span)
+}
+
+// y maps a value to a vertical pixel position
+func (a chartAxes) y(value float64) float64 {
+	height := float64(chartHeight - chartMarginTop - chartMarginBottom)
+	return chartMarginTop + height*(1-(value-a.min)/(a.max-a.min))
+}
+
+// dateLabels picks the first, middle, and last dates for the x axis
+func (c Chart) dateLabels() []time.Time {
+	n := len(c.Points)
+	labels := []time.Time{c.Points[0].Date}
+	if n > 2 {
+		labels = append(labels, c.Points[n/2].Date)
+	}
+	if n > 1 {
+		labels = append(labels, c.Points[n-1].Date)
+	}
+	return labels
+}
+
+// formatTick renders a gridline value without trailing zeros
+func formatTick(v float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.1f", v), "0"), ".")
+}
+
+// Render draws the chart in the requested format
+func (c Chart) Render(format string) ([]byte, string, error) {
+	if len(c.Points) == 0 {
+		return nil, "", fmt.Errorf("no %s data to chart in this period", strings.ToLower(c.Title))
+	}
+	switch format {
+	case "", "png":
+		data, err := c.PNG()
+		return data, "image/png", err
+	case "svg":
+		return c.SVG(), "image/svg+xml", nil
+	default:
+		return nil, "", fmt.Errorf("invalid format %q (expected png or svg)", format)
+	}
+}
+
+// SVG renders the chart as a standalone SVG document
+func (c Chart) SVG() []byte {
+	axes := c.axes()
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`+"\n",
+		chartWidth, chartHeight, chartWidth, chartHeight)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", svgColor(chartBackground))
+	title := c.Title
+	if c.Unit != "" {
+		title += " (" + c.Unit + ")"
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="22" font-size="14" font-weight="bold" fill="#111827">%s</text>`+"\n", chartMarginLeft, svgEscape(title))
+
+	for _, tick := range axes.ticks {
+		y := axes.y(tick)
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="%s"/>`+"\n", chartMarginLeft, y, chartWidth-chartMarginRight, y, svgColor(chartGrid))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" fill="%s">%s</text>`+"\n", chartMarginLeft-6, y+4, svgColor(chartAxis), formatTick(tick))
+	}
+	for _, date := range c.dateLabels() {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle" fill="%s">%s</text>`+"\n", axes.x(date), chartHeight-chartMarginBottom+18, svgColor(chartAxis), date.Format("Jan 2"))
+	}
+
+	points := make([]string, len(c.Points))
+	for i, p := range c.Points {
+		points[i] = fmt.Sprintf("%.1f,%.1f", axes.x(p.Date), axes.y(p.Value))
+	}
+	fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`+"\n", svgColor(chartLine), strings.Join(points, " "))
+	for _, p := range c.Points {
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="2.5" fill="%s"/>`+"\n", axes.x(p.Date), axes.y(p.Value), svgColor(chartLine))
+	}
+	b.WriteString("</svg>\n")
+	return []byte(b.String())
+}
+
+// svgColor formats a color as #rrggbb
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// svgEscape escapes text for an SVG text node
+func svgEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// PNG renders the chart as a PNG. The standard library has no font
+// rendering, so tick labels use a small built-in digit font and dates are
+// shown as MM-DD; the title travels in the accompanying text block.
+func (c Chart) PNG() ([]byte, error) {
+	axes := c.axes()
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = chartBackground.R, chartBackground.G, chartBackground.B, chartBackground.A
+	}
+
+	for _, tick := range axes.ticks {
+		y := int(math.Round(axes.y(tick)))
+		drawLine(img, chartMarginLeft, y, chartWidth-chartMarginRight, y, chartGrid, 1)
+		label := formatTick(tick)
+		drawDigits(img, chartMarginLeft-6-digitsWidth(label), y-digitHeight/2, label, chartAxis)
+	}
+	for _, date := range c.dateLabels() {
+		label := date.Format("01-02")
+		x := int(axes.x(date)) - digitsWidth(label)/2
+		if maxX := chartWidth - digitsWidth(label) - 2; x > maxX {
+			x = maxX
+		}
+		drawDigits(img, x, chartHeight-chartMarginBottom+12, label, chartAxis)
+	}
+
+	for i := 1; i < len(c.Points); i++ {
+		prev, cur := c.Points[i-1], c.Points[i]
+		drawLine(img, int(axes.x(prev.Date)), int(axes.y(prev.Value)), int(axes.x(cur.Date)), int(axes.y(cur.Value)), chartLine, 2)
+	}
+	for _, p := range c.Points {
+		fillSquare(img, int(axes.x(p.Date)), int(axes.y(p.Value)), 2, chartLine)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawLine draws a line of the given thickness with Bresenham's algorithm
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA, thickness int) {
+	dx, dy := absInt(x1-x0), -absInt(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		fillSquare(img, x0, y0, thickness/2, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// fillSquare paints the pixels within radius of (x, y)
+func fillSquare(img *image.RGBA, x, y, radius int, c color.RGBA) {
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			img.SetRGBA(x+dx, y+dy, c)
+		}
+	}
+}
+
+// absInt returns the absolute value of v
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+const (
+	// digitScale enlarges the 3x5 glyphs
+	digitScale   = 2
+	digitHeight  = 5 * digitScale
+	digitAdvance = 4 * digitScale
+)
+
+// digitGlyphs are 3x5 bitmaps, one row per entry with the high bit on the left
+var digitGlyphs = map[rune][5]uint8{
+	'0': {7, 5, 5, 5, 7},
+	'1': {2, 6, 2, 2, 7},
+	'2': {7, 1, 7, 4, 7},
+	'3': {7, 1, 7, 1, 7},
+	'4': {5, 5, 7, 1, 1},
+	'5': {7, 4, 7, 1, 7},
+	'6': {7, 4, 7, 5, 7},
+	'7': {7, 1, 1, 1, 1},
+	'8': {7, 5, 7, 5, 7},
+	'9': {7, 5, 7, 1, 7},
+	'-': {0, 0, 7, 0, 0},
+	'.': {0, 0, 0, 0, 2},
+}
+
+// digitsWidth is the rendered width of a label in pixels
+func digitsWidth(label string) int {
+	return len(label)*digitAdvance - digitScale
+}
+
+// drawDigits renders a numeric label with its top-left corner at (x, y)
+func drawDigits(img *image.RGBA, x, y int, label string, c color.RGBA) {
+	for _, r := range label {
+		glyph := digitGlyphs[r]
+		for row, bits := range glyph {
+			for col := 0; col < 3; col++ {
+				if bits&(4>>col) == 0 {
+					continue
+				}
+				for sy := 0; sy < digitScale; sy++ {
+					for sx := 0; sx < digitScale; sx++ {
+						img.SetRGBA(x+col*digitScale+sx, y+row*digitScale+sy, c)
+					}
+				}
+			}
+		}
+		x += digitAdvance
+	}
+}
+
+// summary describes the plotted series for the text block sent with the image
+func (c Chart) summary(days int) string {
+	values := valuesOf(c.Points)
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	latest := c.Points[len(c.Points)-1]
+	return fmt.Sprintf("%s over the last %d days: %d days plotted, range %s-%s%s, latest %s%s on %s",
+		c.Title, days, len(c.Points), formatTick(lo), formatTick(hi), c.Unit, formatTick(latest.Value), c.Unit, latest.Date.Format("Jan 2"))
+}
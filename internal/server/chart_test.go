@@ -0,0 +1,73 @@
+package server
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testChart() Chart {
+	start := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	chart := Chart{Title: "Recovery", Unit: "%"}
+	for i, v := range []float64{44, 67, 81, 58, 72} {
+		chart.Points = append(chart.Points, datedValue{Date: start.AddDate(0, 0, i), Value: v})
+	}
+	return chart
+}
+
+func TestChart_Axes(t *testing.T) {
+	axes := testChart().axes()
+	if axes.min != 40 || axes.max != 90 {
+		t.Errorf("Expected rounded bounds 40-90, got %.0f-%.0f", axes.min, axes.max)
+	}
+	if len(axes.ticks) != 6 || axes.ticks[1] != 50 {
+		t.Errorf("Expected gridlines every 10, got %v", axes.ticks)
+	}
+	if x := axes.x(time.Date(2024, 7, 5, 0, 0, 0, 0, time.UTC)); x != chartWidth-chartMarginRight {
+		t.Errorf("Expected the last day at the right edge, got %.1f", x)
+	}
+}
+
+func TestChart_Render(t *testing.T) {
+	chart := testChart()
+
+	data, mimeType, err := chart.Render("png")
+	if err != nil || mimeType != "image/png" {
+		t.Fatalf("Render(png) = %s, %v", mimeType, err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Render(png) produced an invalid PNG: %v", err)
+	}
+	if bounds := img.Bounds(); bounds.Dx() != chartWidth || bounds.Dy() != chartHeight {
+		t.Errorf("Unexpected PNG size %v", bounds)
+	}
+
+	data, mimeType, err = chart.Render("svg")
+	if err != nil || mimeType != "image/svg+xml" {
+		t.Fatalf("Render(svg) = %s, %v", mimeType, err)
+	}
+	svg := string(data)
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, "Recovery (%)") || strings.Count(svg, "<circle") != 5 {
+		t.Errorf("Unexpected SVG:\n%s", svg)
+	}
+
+	if _, _, err := (Chart{Title: "HRV"}).Render("png"); err == nil {
+		t.Error("Expected an error for a chart without data")
+	}
+	if _, _, err := chart.Render("gif"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
+
+func TestChart_SinglePoint(t *testing.T) {
+	chart := Chart{Title: "Strain", Points: []datedValue{{Date: time.Now(), Value: 12}}}
+	if _, _, err := chart.Render("png"); err != nil {
+		t.Errorf("Render() with one point error: %v", err)
+	}
+	if !strings.Contains(chart.summary(7), "latest 12 on") {
+		t.Errorf("Unexpected summary %q", chart.summary(7))
+	}
+}
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultSleepNeedHours is used when Whoop has not reported a sleep need
+	defaultSleepNeedHours = 8.0
+	// minutesPerDay wraps clock arithmetic
+	minutesPerDay = 24 * 60
+	// noonMinutes anchors bedtimes so evenings and early mornings average correctly
+	noonMinutes = 12 * 60
+)
+
+// CircadianAnalysis describes sleep timing and regularity
+type CircadianAnalysis struct {
+	Nights              int     `json:"nights"`
+	AverageBedtime      string  `json:"average_bedtime"`
+	AverageWakeTime     string  `json:"average_wake_time"`
+	BedtimeVariability  float64 `json:"bedtime_variability_minutes"`
+	WakeTimeVariability float64 `json:"wake_time_variability_minutes"`
+	WorkdayMidSleep     string  `json:"workday_mid_sleep"`
+	FreeDayMidSleep     string  `json:"free_day_mid_sleep"`
+	SocialJetlagHours   float64 `json:"social_jetlag_hours"`
+	Chronotype          string  `json:"chronotype"` // "early", "intermediate", "late", "unknown"
+	SleepNeedHours      float64 `json:"sleep_need_hours"`
+	OptimalBedtime      string  `json:"optimal_bedtime"`
+	OptimalBedtimeBasis string  `json:"optimal_bedtime_basis"`
+	WorkdayNights       int     `json:"workday_nights"`
+	FreeDayNights       int     `json:"free_day_nights"`
+}
+
+// localTime converts t to the wall-clock time of a Whoop timezone_offset such as "-05:00"
+func localTime(t time.Time, offset string) time.Time {
+	if offset == "" || offset == "Z" {
+		return t.UTC()
+	}
+	parsed, err := time.Parse("-07:00", offset)
+	if err != nil {
+		return t
+	}
+	_, seconds := parsed.Zone()
+	return t.In(time.FixedZone(offset, seconds))
+}
+
+// clockMinutes returns minutes after midnight on the wall clock
+func clockMinutes(t time.Time) float64 {
+	return float64(t.Hour()*60+t.Minute()) + float64(t.Second())/60
+}
+
+// minutesAfterNoon maps a wall-clock time onto a noon-to-noon scale so a 23:30
+// and a 00:30 bedtime average to midnight rather than noon
+func minutesAfterNoon(t time.Time) float64 {
+	return math.Mod(clockMinutes(t)-noonMinutes+minutesPerDay, minutesPerDay)
+}
+
+// formatClock renders minutes after midnight as HH:MM
+func formatClock(minutes float64) string {
+	m := int(math.Round(math.Mod(minutes+minutesPerDay, minutesPerDay))) % minutesPerDay
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
+
+// isFreeDayWake reports whether a wake-up falls on a weekend morning
+func isFreeDayWake(wake time.Time) bool {
+	return wake.Weekday() == time.Saturday || wake.Weekday() == time.Sunday
+}
+
+// AnalyzeCircadian computes bed/wake timing, regularity, social jetlag (the
+// shift in mid-sleep between work and free days), and a wake-anchored optimal
+// bedtime from the user's own sleep need
+func (h *HealthAnalyzer) AnalyzeCircadian(sleepData []WhoopSleep) CircadianAnalysis {
+	mainSleeps, _ := splitNaps(sleepData)
+	if len(mainSleeps) == 0 {
+		return CircadianAnalysis{Chronotype: "unknown", OptimalBedtime: "22:00", OptimalBedtimeBasis: "default (no sleep data)"}
+	}
+
+	var bedtimes, wakes, needs, awakeInBed []float64
+	var workdayMid, freeDayMid, workdayWakes []float64
+
+	for _, sleep := range mainSleeps {
+		bed := localTime(sleep.Start, sleep.TimezoneOffset)
+		wake := localTime(sleep.End, sleep.TimezoneOffset)
+
+		bedAfterNoon := minutesAfterNoon(bed)
+		bedtimes = append(bedtimes, bedAfterNoon)
+		wakes = append(wakes, clockMinutes(wake))
+
+		midSleep := bedAfterNoon + sleep.End.Sub(sleep.Start).Minutes()/2
+		if isFreeDayWake(wake) {
+			freeDayMid = append(freeDayMid, midSleep)
+		} else {
+			workdayMid = append(workdayMid, midSleep)
+			workdayWakes = append(workdayWakes, clockMinutes(wake))
+		}
+
+		if need := sleep.Score.SleepNeeded.BaselineMilli; need > 0 {
+			needs = append(needs, float64(need)/(1000*60*60))
+		}
+		awakeInBed = append(awakeInBed, float64(sleep.Score.StageSummary.TotalAwakeTimeMilli)/(1000*60))
+	}
+
+	analysis := CircadianAnalysis{
+		Nights:              len(mainSleeps),
+		AverageBedtime:      formatClock(h.calculateMean(bedtimes) + noonMinutes),
+		AverageWakeTime:     formatClock(h.calculateMean(wakes)),
+		BedtimeVariability:  h.calculateStdDev(bedtimes),
+		WakeTimeVariability: h.calculateStdDev(wakes),
+		WorkdayNights:       len(workdayMid),
+		FreeDayNights:       len(freeDayMid),
+		Chronotype:          "unknown",
+		SleepNeedHours:      defaultSleepNeedHours,
+	}
+	if len(needs) > 0 {
+		analysis.SleepNeedHours = h.calculateMean(needs)
+	}
+
+	if len(workdayMid) > 0 {
+		analysis.WorkdayMidSleep = formatClock(h.calculateMean(workdayMid) + noonMinutes)
+	}
+	if len(freeDayMid) > 0 {
+		freeMid := h.calculateMean(freeDayMid)
+		analysis.FreeDayMidSleep = formatClock(freeMid + noonMinutes)
+
+		// Chronotype from mid-sleep on free days (Munich ChronoType Questionnaire convention)
+		freeMidClock := math.Mod(freeMid+noonMinutes, minutesPerDay)
+		switch {
+		case freeMidClock < 3*60 || freeMidClock > 20*60:
+			analysis.Chronotype = "early"
+		case freeMidClock <= 5*60:
+			analysis.Chronotype = "intermediate"
+		default:
+			analysis.Chronotype = "late"
+		}
+	}
+	if len(workdayMid) > 0 && len(freeDayMid) > 0 {
+		analysis.SocialJetlagHours = math.Abs(h.calculateMean(freeDayMid)-h.calculateMean(workdayMid)) / 60
+	}
+
+	analysis.OptimalBedtime, analysis.OptimalBedtimeBasis = h.optimalBedtime(workdayWakes, wakes, analysis.SleepNeedHours, h.calculateMean(awakeInBed))
+	return analysis
+}
+
+// optimalBedtime anchors on the typical workday wake time and counts back the
+// personal sleep need plus the time usually spent awake in bed
+func (h *HealthAnalyzer) optimalBedtime(workdayWakes, allWakes []float64, sleepNeedHours, awakeMinutes float64) (string, string) {
+	wakes := workdayWakes
+	basis := "workday"
+	if len(wakes) == 0 {
+		wakes = allWakes
+		basis = "typical"
+	}
+	if len(wakes) == 0 {
+		return "22:00", "default (no sleep data)"
+	}
+
+	sorted := append([]float64(nil), wakes...)
+	sort.Float64s(sorted)
+	medianWake := sorted[len(sorted)/2]
+
+	bedtime := medianWake - sleepNeedHours*60 - awakeMinutes
+	return formatClock(bedtime), fmt.Sprintf("%s wake time %s minus %.1fh sleep need and %.0f min typically awake in bed",
+		basis, formatClock(medianWake), sleepNeedHours, awakeMinutes)
+}
+
+// FormatCircadianAnalysis renders the circadian rhythm report
+func (h *HealthAnalyzer) FormatCircadianAnalysis(analysis CircadianAnalysis) string {
+	if analysis.Nights == 0 {
+		return "# Circadian Rhythm Analysis\n\nNo main sleep records were found for this period."
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("# Circadian Rhythm Analysis (%d nights)\n\n", analysis.Nights))
+
+	builder.WriteString("## Sleep Timing\n")
+	builder.WriteString(fmt.Sprintf("- **Average bedtime:** %s (±%.0f min)\n", analysis.AverageBedtime, analysis.BedtimeVariability))
+	builder.WriteString(fmt.Sprintf("- **Average wake time:** %s (±%.0f min)\n", analysis.AverageWakeTime, analysis.WakeTimeVariability))
+	builder.WriteString(fmt.Sprintf("- **Chronotype:** %s\n\n", analysis.Chronotype))
+
+	builder.WriteString("## Social Jetlag\n")
+	if analysis.WorkdayNights > 0 && analysis.FreeDayNights > 0 {
+		builder.WriteString(fmt.Sprintf("- Mid-sleep on workdays: %s (%d nights)\n", analysis.WorkdayMidSleep, analysis.WorkdayNights))
+		builder.WriteString(fmt.Sprintf("- Mid-sleep on free days: %s (%d nights)\n", analysis.FreeDayMidSleep, analysis.FreeDayNights))
+		builder.WriteString(fmt.Sprintf("- **Social jetlag:** %.1f hours\n\n", analysis.SocialJetlagHours))
+	} else {
+		builder.WriteString("Need both workday and weekend nights to estimate social jetlag.\n\n")
+	}
+
+	builder.WriteString("## Recommended Bedtime\n")
+	builder.WriteString(fmt.Sprintf("**%s** — based on %s.\n\n", analysis.OptimalBedtime, analysis.OptimalBedtimeBasis))
+
+	builder.WriteString("## Interpretation\n")
+	if analysis.BedtimeVariability > 60 {
+		builder.WriteString("- Bedtime varies by more than an hour night to night. Irregular sleep timing is linked to worse mood and daytime functioning independent of sleep duration.\n")
+	} else {
+		builder.WriteString("- Bedtime is fairly regular, which supports a stable circadian rhythm.\n")
+	}
+	if analysis.SocialJetlagHours >= 2 {
+		builder.WriteString("- Social jetlag of 2+ hours is like flying across time zones every weekend and is associated with low mood and fatigue. Narrowing the weekend shift usually helps.\n")
+	} else if analysis.SocialJetlagHours >= 1 {
+		builder.WriteString("- Moderate social jetlag: weekend sleep shifts by over an hour.\n")
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_AnalyzeCircadian(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+
+	// Two weeks starting Monday night: workdays 23:00-07:00, weekends 01:00-09:00 local (UTC-5)
+	zone := time.FixedZone("-05:00", -5*60*60)
+	var sleeps []WhoopSleep
+	for i := 0; i < 14; i++ {
+		wakeDay := time.Date(2024, 4, 2, 0, 0, 0, 0, zone).AddDate(0, 0, i) // Tuesday onward
+		var sleep WhoopSleep
+		sleep.TimezoneOffset = "-05:00"
+		if wakeDay.Weekday() == time.Saturday || wakeDay.Weekday() == time.Sunday {
+			sleep.Start = wakeDay.Add(1 * time.Hour)
+			sleep.End = wakeDay.Add(9 * time.Hour)
+		} else {
+			sleep.Start = wakeDay.Add(-1 * time.Hour)
+			sleep.End = wakeDay.Add(7 * time.Hour)
+		}
+		sleep.Start = sleep.Start.UTC()
+		sleep.End = sleep.End.UTC()
+		sleep.Score.SleepNeeded.BaselineMilli = 8 * 60 * 60 * 1000
+		sleeps = append(sleeps, sleep)
+	}
+
+	analysis := analyzer.AnalyzeCircadian(sleeps)
+
+	if analysis.SocialJetlagHours < 1.9 || analysis.SocialJetlagHours > 2.1 {
+		t.Errorf("Expected ~2h social jetlag, got %.2f", analysis.SocialJetlagHours)
+	}
+	if analysis.WorkdayMidSleep != "03:00" || analysis.FreeDayMidSleep != "05:00" {
+		t.Errorf("Unexpected mid-sleep times: workday %s, free day %s", analysis.WorkdayMidSleep, analysis.FreeDayMidSleep)
+	}
+	if analysis.OptimalBedtime != "23:00" {
+		t.Errorf("Expected optimal bedtime 23:00, got %s", analysis.OptimalBedtime)
+	}
+	if analysis.Chronotype != "intermediate" {
+		t.Errorf("Expected intermediate chronotype, got %s", analysis.Chronotype)
+	}
+}
+
+func TestFormatClockWrapsMidnight(t *testing.T) {
+	if got := formatClock(-30); got != "23:30" {
+		t.Errorf("formatClock(-30) = %s", got)
+	}
+	if got := formatClock(24*60 + 15); got != "00:15" {
+		t.Errorf("formatClock(1455) = %s", got)
+	}
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"whoop-mcp/internal/config"
+)
+
+// version is the release version, set at build time with
+// -ldflags "-X whoop-mcp/internal/server.version=1.2.3"
+var version = "1.0.0"
+
+// cliCommand is one whoop-mcp-server subcommand
+type cliCommand struct {
+	Name    string
+	Summary string
+	Failure string // prefix for the error message when Run fails
+	Run     func(settings Settings, args []string) error
+}
+
+// cliCommands lists the subcommands in the order usage shows them
+func cliCommands() []cliCommand {
+	return []cliCommand{
+		{"serve", "Serve MCP over stdio (the default), or write reports with --schedule", "Server error", runServeCommand},
+		{"auth", "Authorize a Whoop account and store its tokens", "Authorization failed", runAuthCommand},
+		{"refresh", "Exchange the stored refresh token for a new access token", "Refresh failed", runRefreshCommand},
+		{"sync", "Update personal baselines and burnout history, and apply retention", "Sync failed", runSyncCommand},
+		{"doctor", "Check configuration, credentials, clock, and API access", "Doctor", runDoctorCommand},
+		{"export", "Archive the account's history as NDJSON", "Export failed", runExportCommand},
+		{"purge", "Securely wipe the local datastore", "Purge failed", runPurgeCommand},
+		{"i18n", "Maintain report message catalogs", "i18n", runI18nCommand},
+		{"version", "Print version and build information", "Version", runVersionCommand},
+	}
+}
+
+// Main runs the whoop-mcp-server command line: a subcommand from os.Args,
+// or serving MCP over stdio by default
+func Main() {
+	// Set up logging to stderr to avoid interfering with stdio communication
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	// Settings from .env (or WHOOP_ENV_FILE) fill in anything not exported
+	envPath, explicit := dotEnvPath()
+	loaded, err := loadDotEnv(envPath)
+	if err != nil {
+		log.Fatalf("Invalid environment file: %v", err)
+	}
+	if len(loaded) > 0 {
+		log.Printf("Loaded %d setting(s) from %s", len(loaded), envPath)
+	} else if _, statErr := os.Stat(envPath); explicit && statErr != nil {
+		log.Printf("WHOOP_ENV_FILE %s not found; using the process environment only", envPath)
+	}
+
+	// With no subcommand, or only flags, the binary serves MCP so existing
+	// client configurations keep working
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		printUsage(os.Stdout)
+		return
+	}
+
+	for _, command := range cliCommands() {
+		if command.Name != name {
+			continue
+		}
+		// Serving resolves the configuration itself, after parsing its flags
+		var settings Settings
+		if name != "serve" {
+			resolved, err := config.Resolve("", nil)
+			if err != nil {
+				log.Fatalf("Invalid configuration: %v", err)
+			}
+			settings = resolved.Getenv
+		}
+		if err := command.Run(settings, args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			log.Fatalf("%s: %v", command.Failure, err)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+	printUsage(os.Stderr)
+	os.Exit(2)
+}
+
+// printUsage lists the subcommands
+func printUsage(out *os.File) {
+	fmt.Fprintln(out, "Usage: whoop-mcp-server [command] [flags]")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Commands:")
+	for _, command := range cliCommands() {
+		fmt.Fprintf(out, "  %-9s %s\n", command.Name, command.Summary)
+	}
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Run `whoop-mcp-server <command> -h` for a command's flags.")
+}
+
+// runServeCommand implements `whoop-mcp-server serve`: it serves MCP over
+// stdio until stdin closes, or writes reports on a schedule. It resolves its
+// own settings once its flags are parsed, so Main passes none.
+func runServeCommand(_ Settings, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	schedule := flags.String("schedule", "", "Write weekly or monthly reports on a schedule instead of serving MCP over stdio")
+	mode := flags.String("mode", serverModeFull, "full, or readonly to disable auth setup, journaling, goals, acknowledgements, exports to disk, and every datastore and token write")
+	configFile := flags.String("config", "", "Config file (default WHOOP_CONFIG or ~/.config/whoop-mcp/config.yaml)")
+	config.RegisterFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	resolved, err := config.Resolve(*configFile, flags)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Create and start the MCP server
+	server, err := NewMCPServer(resolved.Getenv)
+	if err != nil {
+		return fmt.Errorf("failed to create MCP server: %w", err)
+	}
+	if err := server.SetMode(*mode); err != nil {
+		return fmt.Errorf("invalid mode: %w", err)
+	}
+	// Export the trace spans still pending when serving stops
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Close(ctx)
+	}()
+
+	// Scheduler mode: whoop-mcp-server serve --schedule weekly
+	if *schedule != "" {
+		scheduler, err := NewReportScheduler(server, *schedule)
+		if err != nil {
+			return fmt.Errorf("invalid schedule: %w", err)
+		}
+		scheduler.Run()
+		return nil
+	}
+
+	log.Println("Starting Whoop MCP Server...")
+	log.Println("Server ready to accept JSON-RPC 2.0 requests via stdio")
+
+	// Run the server (blocks until stdin is closed)
+	if err := server.Run(); err != nil {
+		return err
+	}
+
+	log.Println("Whoop MCP Server shutting down")
+	return nil
+}
+
+// runVersionCommand implements `whoop-mcp-server version`
+func runVersionCommand(settings Settings, args []string) error {
+	fmt.Printf("whoop-mcp-server %s (%s, %s/%s)\n", version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" || setting.Key == "vcs.time" || setting.Key == "vcs.modified" {
+				fmt.Printf("%s: %s\n", setting.Key, setting.Value)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"strings"
+)
+
+// coachFormatter frames results for an athletic coach: readiness, training
+// load, and where the athlete sits in a periodization cycle
+type coachFormatter struct {
+	l *Localizer
+}
+
+func (f coachFormatter) HealthSummary(summary *HealthSummary) string {
+	var builder strings.Builder
+	t := f.l.T
+
+	builder.WriteString(t("# Athlete Readiness Report") + "\n\n")
+	builder.WriteString(t("**Period:** %s", formatPeriod(f.l, summary.DateRange)) + "\n\n")
+
+	phase, rationale := trainingPhase(summary.RecoveryTrend, summary.StressIndicators, summary.ActivityPatterns)
+	builder.WriteString(t("## Recommended Phase: %s", t(phase)) + "\n" + t(rationale) + "\n\n")
+	builder.WriteString(formatSparklines(f.l, summary.Sparklines))
+	builder.WriteString(formatPopulationContext(f.l, summary.Population))
+
+	builder.WriteString(t("## Readiness") + "\n")
+	builder.WriteString(t("- **Average Recovery:** %.0f%% — %s",
+		summary.RecoveryTrend.AverageScore, t(readinessLabel(summary.RecoveryTrend.AverageScore))) + "\n")
+	builder.WriteString(t("- **Recovery Trend:** %s (%s)",
+		t(summary.RecoveryTrend.Trend), formatTrendEstimate(f.l, summary.RecoveryTrend.Estimate, "%")) + "\n")
+	builder.WriteString(t("- **Autonomic Stress:** %s (%.0f/100)",
+		t(summary.StressIndicators.StressLevel), summary.StressIndicators.PhysiologicalStress) + "\n")
+	if summary.StressIndicators.PoorRecoveryStreak > 0 {
+		builder.WriteString(t("- **Consecutive Red Days:** %d", summary.StressIndicators.PoorRecoveryStreak) + "\n")
+	}
+	builder.WriteString("\n")
+
+	builder.WriteString(f.loadSection(summary.ActivityPatterns))
+	builder.WriteString(f.sleepSection(summary.SleepAnalysis))
+
+	if len(summary.RedFlags) > 0 {
+		builder.WriteString(t("## ⚠️ Hold Training If") + "\n")
+		for _, flag := range summary.RedFlags {
+			builder.WriteString(formatSafetyNotice(flag))
+			builder.WriteString("- " + flag.Description + " (" + t(flag.Severity) + ")\n")
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString(formatAcknowledgedFlags(f.l, summary.AcknowledgedFlags, "##"))
+
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+func (f coachFormatter) Stress(period DateRange, stress StressIndicators) string {
+	var builder strings.Builder
+	t := f.l.T
+
+	builder.WriteString(t("# Autonomic Load Report") + "\n\n")
+	builder.WriteString(t("**Period:** %s", formatPeriod(f.l, period)) + "\n\n")
+	builder.WriteString(t("## Markers") + "\n")
+	builder.WriteString(t("- **Systemic Stress:** %s (%.0f/100)", t(stress.StressLevel), stress.PhysiologicalStress) + "\n")
+	if stress.Model == stressModelLegacy {
+		builder.WriteString(t("- **Elevated HRV Days:** %d", stress.ElevatedHRVDays) + "\n")
+	} else {
+		builder.WriteString(t("- **Suppressed HRV Days:** %d", stress.SuppressedHRVDays) + "\n")
+	}
+	builder.WriteString(t("- **Elevated Resting HR Days:** %d", stress.HighRestingHRDays) + "\n")
+	if stress.Model != stressModelLegacy {
+		builder.WriteString(t("- **Sleep Debt:** %.1f h", stress.SleepDebtHours) + "\n")
+		builder.WriteString(t("- **High Strain on Low Recovery:** %d days", stress.StrainMismatchDays) + "\n")
+	}
+	builder.WriteString(t("- **Consecutive Red Days:** %d", stress.PoorRecoveryStreak) + "\n\n")
+
+	builder.WriteString(t("## Training Implication") + "\n")
+	switch stress.StressLevel {
+	case "critical":
+		builder.WriteString(t("Stop intensity work. Replace sessions with easy aerobic work or full rest until HRV and resting HR return to baseline.") + "\n")
+	case "high":
+		builder.WriteString(t("Drop planned high-intensity sessions this week and keep volume at or below the current level.") + "\n")
+	case "moderate":
+		builder.WriteString(t("Train as planned but move key sessions to green-recovery mornings.") + "\n")
+	default:
+		builder.WriteString(t("Autonomic markers support the planned training load.") + "\n")
+	}
+	builder.WriteString("\n" + cycleNote(f.l, stress.CycleAdjustedDays, t("resting HR rise(s)")))
+	return builder.String()
+}
+
+func (f coachFormatter) Sleep(period DateRange, sessions int, analysis SleepAnalysis) string {
+	var builder strings.Builder
+	builder.WriteString(f.l.T("# Sleep & Recovery Inputs") + "\n\n")
+	builder.WriteString(f.l.T("**Period:** %s (%d sleep sessions)", formatPeriod(f.l, period), sessions) + "\n\n")
+	builder.WriteString(f.sleepSection(analysis))
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+func (f coachFormatter) Activity(period DateRange, workouts int, patterns ActivityPatterns) string {
+	var builder strings.Builder
+	builder.WriteString(f.l.T("# Training Load Report") + "\n\n")
+	builder.WriteString(f.l.T("**Period:** %s (%d workouts)", formatPeriod(f.l, period), workouts) + "\n\n")
+	builder.WriteString(f.loadSection(patterns))
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// loadSection summarizes training volume, intensity, and overreaching risk
+func (f coachFormatter) loadSection(patterns ActivityPatterns) string {
+	var builder strings.Builder
+	t := f.l.T
+
+	builder.WriteString(t("## Training Load") + "\n")
+	builder.WriteString(t("- **Sessions per Week:** %d", patterns.WeeklyWorkouts) + "\n")
+	builder.WriteString(t("- **Average Day Strain:** %.1f", patterns.AverageStrain) + "\n")
+	builder.WriteString(t("- **Session Consistency:** %.0f%%", patterns.WorkoutConsistency*100) + "\n")
+	builder.WriteString(t("- **Intensity Distribution:** %s", t(patterns.IntensityBalance)) + "\n")
+	builder.WriteString(t("- **Active Recovery Days:** %d", patterns.ActiveRecoveryDays) + "\n")
+	builder.WriteString(t("- **Overreaching Risk:** %s", t(patterns.OvertrainingRisk)) + "\n")
+	if len(patterns.SportBreakdown) > 0 {
+		builder.WriteString(t("- **Sport Mix:** %s", FormatSportBreakdown(patterns.SportBreakdown)) + "\n")
+	}
+	balance := patterns.StrainBalance
+	if balance != nil {
+		builder.WriteString(t("- **Strain vs. Recovery:** %.0f/100 (%d overreached, %d under-utilized of %d days)",
+			balance.Score, balance.OverreachedDays, balance.UnderutilizedDays, balance.Days) + "\n")
+		builder.WriteString(t("- **Weekly Balance:** %s", FormatWeeklyBalance(balance.Weeks)) + "\n")
+	}
+	switch {
+	case patterns.OvertrainingRisk == "high":
+		builder.WriteString("\n" + t("Load is outpacing recovery; schedule a deload before adding volume.") + "\n")
+	case balance != nil && balance.Tendency == strainBalanceOverreaching:
+		builder.WriteString("\n" + t("Strain often exceeds what recovery supports; match hard sessions to green recovery days.") + "\n")
+	case balance != nil && balance.Tendency == strainBalanceUnderutilizing:
+		builder.WriteString("\n" + t("Recovery often supports more load than is used; place key sessions on high-recovery days.") + "\n")
+	case patterns.ActiveRecoveryDays == 0 && patterns.WeeklyWorkouts > 0:
+		builder.WriteString("\n" + t("No low-strain recovery days recorded; build at least one into each microcycle.") + "\n")
+	}
+	builder.WriteString("\n")
+	return builder.String()
+}
+
+// sleepSection summarizes sleep as a recovery input
+func (f coachFormatter) sleepSection(analysis SleepAnalysis) string {
+	var builder strings.Builder
+	t := f.l.T
+
+	builder.WriteString(t("## Sleep as a Recovery Input") + "\n")
+	builder.WriteString(t("- **Average Sleep:** %.1f hours (%.0f%% efficiency)", analysis.AverageHours, analysis.AverageEfficiency*100) + "\n")
+	builder.WriteString(t("- **Sleep Debt:** %.1f hours (%s, %s)", analysis.SleepDebtHours, t(analysis.DebtLedger.Trend), formatDaysToRepay(f.l, analysis.DebtLedger)) + "\n")
+	builder.WriteString(t("- **Schedule Consistency:** %s", formatSleepScore(f.l, analysis.ConsistencyScore, analysis.WhoopConsistency)) + "\n")
+	builder.WriteString(t("- **Sleep Performance:** %s", formatSleepScore(f.l, analysis.PerformanceScore, analysis.WhoopPerformance)) + "\n")
+	builder.WriteString(t("- **Wake After Sleep Onset:** %.0f minutes (%s, %s)", analysis.Wake.AverageWASOMinutes, t(analysis.Wake.Pattern), t(analysis.Wake.Trend)) + "\n")
+	for _, divergence := range analysis.ScoreDivergences {
+		builder.WriteString("- " + formatSleepDivergence(f.l, divergence) + "\n")
+	}
+	switch {
+	case analysis.ChronicNapCompensation:
+		builder.WriteString("\n" + t("Naps are covering %.0f%% of sleep need after short nights; consolidate sleep into the night rather than relying on naps before key sessions.", analysis.NapNeedShare*100) + "\n")
+	case analysis.AverageHours < 7:
+		builder.WriteString("\n" + t("Short sleep limits adaptation; protect 8+ hours in the sleep window before key sessions.") + "\n")
+	}
+	builder.WriteString("\n")
+	return builder.String()
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"whoop-mcp/internal/config"
+)
+
+// clearConfigEnv unsets every config variable for the duration of a test
+func clearConfigEnv(t *testing.T) {
+	t.Helper()
+	for _, field := range config.Fields() {
+		t.Setenv(field.Env, "")
+		os.Unsetenv(field.Env)
+	}
+}
+
+func TestClientLimitsFromEnv(t *testing.T) {
+	t.Setenv("WHOOP_RATE_LIMIT", "")
+	t.Setenv("WHOOP_REQUEST_TIMEOUT", "")
+	perMinute, timeout, err := clientLimitsFromEnv(os.Getenv)
+	if err != nil || perMinute != 100 || timeout != 30*time.Second {
+		t.Errorf("clientLimitsFromEnv() = %d, %s, %v; want the 100/min and 30s defaults", perMinute, timeout, err)
+	}
+
+	t.Setenv("WHOOP_RATE_LIMIT", "40")
+	t.Setenv("WHOOP_REQUEST_TIMEOUT", "90")
+	perMinute, timeout, err = clientLimitsFromEnv(os.Getenv)
+	if err != nil || perMinute != 40 || timeout != 90*time.Second {
+		t.Errorf("clientLimitsFromEnv() = %d, %s, %v; want 40/min and 90s", perMinute, timeout, err)
+	}
+
+	for name, value := range map[string]string{"WHOOP_RATE_LIMIT": "0", "WHOOP_REQUEST_TIMEOUT": "3600"} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if _, _, err := clientLimitsFromEnv(os.Getenv); err == nil || !strings.Contains(err.Error(), name) {
+				t.Errorf("Expected %s=%s to be rejected, got %v", name, value, err)
+			}
+		})
+	}
+}
+
+func TestStrictDecodeFromEnv(t *testing.T) {
+	t.Setenv("WHOOP_STRICT_DECODE", "")
+	if strict, err := strictDecodeFromEnv(os.Getenv); strict || err != nil {
+		t.Errorf("strictDecodeFromEnv() = %v, %v; want off by default", strict, err)
+	}
+	t.Setenv("WHOOP_STRICT_DECODE", "true")
+	if strict, err := strictDecodeFromEnv(os.Getenv); !strict || err != nil {
+		t.Errorf("strictDecodeFromEnv() = %v, %v; want on", strict, err)
+	}
+	t.Setenv("WHOOP_STRICT_DECODE", "sometimes")
+	if _, err := strictDecodeFromEnv(os.Getenv); err == nil {
+		t.Error("Expected an invalid value to be rejected")
+	}
+}
+
+func TestNewMCPServerWithAPI_ResolvedSettings(t *testing.T) {
+	clearConfigEnv(t)
+	resolved := config.Default()
+	for name, value := range map[string]string{"WHOOP_UNITS": "imperial", "WHOOP_DATA_DIR": t.TempDir()} {
+		if err := resolved.Set(name, value); err != nil {
+			t.Fatalf("Set(%s) error = %v", name, err)
+		}
+	}
+
+	server, err := NewMCPServerWithAPI(NewSyntheticMockWhoopAPI(SyntheticOptions{Seed: 3, Days: 30}), resolved.Getenv)
+	if err != nil {
+		t.Fatalf("NewMCPServerWithAPI() error = %v", err)
+	}
+	if server.healthAnalyzer.units != unitsImperial {
+		t.Errorf("units = %q, want the resolved imperial", server.healthAnalyzer.units)
+	}
+	if os.Getenv("WHOOP_UNITS") != "" || os.Getenv("WHOOP_DATA_DIR") != "" {
+		t.Error("Expected building the server to leave the environment alone")
+	}
+}
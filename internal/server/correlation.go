@@ -0,0 +1,161 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+// minCorrelationPairs is the fewest paired days worth reporting a correlation for
+const minCorrelationPairs = 7
+
+// CorrelationResult describes the relationship between two daily metrics at one lag
+type CorrelationResult struct {
+	MetricX        string  `json:"metric_x"`
+	MetricY        string  `json:"metric_y"`
+	Lag            int     `json:"lag_days"` // days metric_y is measured after metric_x
+	Pairs          int     `json:"pairs"`
+	Pearson        float64 `json:"pearson_r"`
+	PearsonP       float64 `json:"pearson_p"`
+	Spearman       float64 `json:"spearman_rho"`
+	SpearmanP      float64 `json:"spearman_p"`
+	CILow          float64 `json:"ci95_low"`
+	CIHigh         float64 `json:"ci95_high"`
+	Strength       string  `json:"strength"`   // "negligible", "weak", "moderate", "strong"
+	Confidence     string  `json:"confidence"` // "low", "moderate", "high"
+	Interpretation string  `json:"interpretation"`
+}
+
+// CorrelateMetrics pairs each day's x value with the y value lag days later and
+// computes Pearson and Spearman correlations with significance and a 95% CI
+func (h *HealthAnalyzer) CorrelateMetrics(data *HealthData, metricX, metricY metricDefinition, lag int) CorrelationResult {
+	xByDay := dailyAverages(metricX.extract(data))
+	yByDay := dailyAverages(metricY.extract(data))
+
+	days := make([]string, 0, len(xByDay))
+	for day := range xByDay {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	var xs, ys []float64
+	for _, day := range days {
+		date, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			continue
+		}
+		y, ok := yByDay[dayKey(date.AddDate(0, 0, lag))]
+		if !ok {
+			continue
+		}
+		xs = append(xs, xByDay[day])
+		ys = append(ys, y)
+	}
+
+	result := CorrelationResult{
+		MetricX:  metricX.Key,
+		MetricY:  metricY.Key,
+		Lag:      lag,
+		Pairs:    len(xs),
+		PearsonP: 1, SpearmanP: 1,
+		CILow: -1, CIHigh: 1,
+		Strength:   "negligible",
+		Confidence: "low",
+	}
+	if len(xs) < minCorrelationPairs {
+		result.Interpretation = fmt.Sprintf("Only %d paired days; at least %d are needed for a meaningful correlation.", len(xs), minCorrelationPairs)
+		return result
+	}
+
+	result.Pearson = stats.PearsonCorrelation(xs, ys)
+	result.PearsonP = stats.CorrelationPValue(result.Pearson, len(xs))
+	result.Spearman = stats.SpearmanCorrelation(xs, ys)
+	result.SpearmanP = stats.CorrelationPValue(result.Spearman, len(xs))
+	result.CILow, result.CIHigh = stats.CorrelationCI(result.Pearson, len(xs))
+	result.Strength = correlationStrength(result.Pearson)
+
+	switch {
+	case result.PearsonP < 0.01:
+		result.Confidence = "high"
+	case result.PearsonP < 0.05:
+		result.Confidence = "moderate"
+	}
+
+	result.Interpretation = interpretCorrelation(result, metricX, metricY)
+	return result
+}
+
+// correlationStrength labels |r| using conventional thresholds
+func correlationStrength(r float64) string {
+	switch a := math.Abs(r); {
+	case a < 0.1:
+		return "negligible"
+	case a < 0.3:
+		return "weak"
+	case a < 0.5:
+		return "moderate"
+	default:
+		return "strong"
+	}
+}
+
+// interpretCorrelation explains a correlation in plain language
+func interpretCorrelation(result CorrelationResult, metricX, metricY metricDefinition) string {
+	when := "on the same day"
+	switch {
+	case result.Lag == 1:
+		when = "the next day"
+	case result.Lag > 1:
+		when = fmt.Sprintf("%d days later", result.Lag)
+	}
+
+	if result.Strength == "negligible" || result.Confidence == "low" {
+		return fmt.Sprintf("No reliable relationship between %s and %s %s in this period.",
+			strings.ToLower(metricX.Label), strings.ToLower(metricY.Label), when)
+	}
+
+	direction, polarity := "higher", "positive"
+	if result.Pearson < 0 {
+		direction, polarity = "lower", "negative"
+	}
+	return fmt.Sprintf("Days with higher %s tend to be followed by %s %s %s (%s %s relationship, r=%.2f). Correlation shows the two move together, not that one causes the other.",
+		strings.ToLower(metricX.Label), direction, strings.ToLower(metricY.Label), when, result.Strength, polarity, result.Pearson)
+}
+
+// FormatCorrelationResults renders one or more lagged correlations
+func (h *HealthAnalyzer) FormatCorrelationResults(metricX, metricY metricDefinition, results []CorrelationResult) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("# Correlation: %s → %s\n\n", metricX.Label, metricY.Label))
+
+	builder.WriteString("| Lag | Pairs | Pearson r (95% CI) | p | Spearman ρ | p | Strength |\n")
+	builder.WriteString("|---|---|---|---|---|---|---|\n")
+
+	best := -1
+	for i, result := range results {
+		builder.WriteString(fmt.Sprintf("| %dd | %d | %.2f (%.2f to %.2f) | %.3f | %.2f | %.3f | %s |\n",
+			result.Lag, result.Pairs, result.Pearson, result.CILow, result.CIHigh, result.PearsonP,
+			result.Spearman, result.SpearmanP, result.Strength))
+		if result.Pairs >= minCorrelationPairs && (best < 0 || math.Abs(result.Pearson) > math.Abs(results[best].Pearson)) {
+			best = i
+		}
+	}
+
+	builder.WriteString("\n## Interpretation\n")
+	if best < 0 {
+		builder.WriteString(results[0].Interpretation + "\n")
+	} else {
+		if len(results) > 1 {
+			builder.WriteString(fmt.Sprintf("Strongest relationship at a %d-day lag.\n\n", results[best].Lag))
+		}
+		builder.WriteString(results[best].Interpretation + "\n")
+		if math.Abs(results[best].Pearson-results[best].Spearman) > 0.2 {
+			builder.WriteString("\nPearson and Spearman disagree noticeably, suggesting a few outlier days or a non-linear relationship.\n")
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_CorrelateMetrics_Lag(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	start := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	// High strain days are followed by low recovery the next morning
+	strains := []float64{8, 16, 10, 18, 9, 15, 11, 17, 8, 14, 12, 19, 10, 16}
+	data := &HealthData{}
+	for i, strain := range strains {
+		var cycle WhoopCycle
+		cycle.Start = start.AddDate(0, 0, i).Add(-time.Hour) // sleep onset the night before
+		cycle.Score.Strain = strain
+		data.Cycles = append(data.Cycles, cycle)
+
+		var recovery WhoopRecovery
+		recovery.CreatedAt = start.AddDate(0, 0, i+1).Add(7 * time.Hour)
+		recovery.Score.RecoveryScore = 100 - strain*4
+		data.Recoveries = append(data.Recoveries, recovery)
+	}
+
+	strain, _ := lookupMetric("strain")
+	recovery, _ := lookupMetric("recovery")
+
+	nextDay := analyzer.CorrelateMetrics(data, strain, recovery, 1)
+	if nextDay.Pairs != len(strains) {
+		t.Fatalf("Expected %d pairs, got %d", len(strains), nextDay.Pairs)
+	}
+	if nextDay.Pearson > -0.99 || nextDay.Strength != "strong" || nextDay.Confidence != "high" {
+		t.Errorf("Expected strong negative next-day correlation, got %+v", nextDay)
+	}
+
+	sameDay := analyzer.CorrelateMetrics(data, strain, recovery, 0)
+	if math.Abs(sameDay.Pearson) > math.Abs(nextDay.Pearson) {
+		t.Errorf("Expected the 1-day lag to be strongest, same-day r=%.2f", sameDay.Pearson)
+	}
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// AccountCredentials holds the OAuth tokens for one consenting Whoop account
+type AccountCredentials struct {
+	UserID       int    `json:"user_id"`
+	Alias        string `json:"alias,omitempty"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// AccountSummary is the token-free view of a configured account
+type AccountSummary struct {
+	UserID     int    `json:"user_id"`
+	Alias      string `json:"alias,omitempty"`
+	CanRefresh bool   `json:"can_refresh"`
+}
+
+// credentialsFile is the on-disk layout of the accounts file
+type credentialsFile struct {
+	Accounts []AccountCredentials `json:"accounts"`
+}
+
+// CredentialStore maps Whoop user IDs and aliases to separate OAuth tokens so
+// a therapist or coach can query multiple clients through the user_id
+// argument, which takes either an account's ID or its alias
+type CredentialStore struct {
+	path     string
+	accounts []AccountCredentials
+	readOnly bool // rotated tokens stay in memory
+	mu       sync.RWMutex
+}
+
+// LoadCredentialStore reads the accounts file at path. A missing file yields an
+// empty store that will be created on the first token update.
+func LoadCredentialStore(path string) (*CredentialStore, error) {
+	store := &CredentialStore{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read accounts file: %w", err)
+	}
+
+	var file credentialsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse accounts file: %w", err)
+	}
+
+	seen := make(map[int]bool)
+	aliases := make(map[string]bool)
+	for _, account := range file.Accounts {
+		if account.UserID == 0 {
+			return nil, fmt.Errorf("accounts file entry %q is missing user_id", account.Alias)
+		}
+		if account.AccessToken == "" {
+			return nil, fmt.Errorf("accounts file entry for user %d is missing access_token", account.UserID)
+		}
+		if seen[account.UserID] {
+			return nil, fmt.Errorf("accounts file lists user %d more than once", account.UserID)
+		}
+		seen[account.UserID] = true
+		if alias := strings.ToLower(account.Alias); alias != "" {
+			if aliases[alias] {
+				return nil, fmt.Errorf("accounts file lists alias %q more than once", account.Alias)
+			}
+			aliases[alias] = true
+		}
+	}
+
+	store.accounts = file.Accounts
+	return store, nil
+}
+
+// Len returns the number of configured accounts
+func (c *CredentialStore) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.accounts)
+}
+
+// Lookup returns a copy of the credentials for the given user ID
+func (c *CredentialStore) Lookup(userID int) (AccountCredentials, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, account := range c.accounts {
+		if account.UserID == userID {
+			return account, true
+		}
+	}
+	return AccountCredentials{}, false
+}
+
+// LookupAlias returns a copy of the credentials for the given alias (case-insensitive)
+func (c *CredentialStore) LookupAlias(alias string) (AccountCredentials, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, account := range c.accounts {
+		if account.Alias != "" && strings.EqualFold(account.Alias, alias) {
+			return account, true
+		}
+	}
+	return AccountCredentials{}, false
+}
+
+// ResolveArguments swaps an alias given as user_id in tool arguments for the
+// account's user ID, so the tools and resolveAccount only ever see IDs.
+// Arguments whose user_id is not a string are returned unchanged.
+func (c *CredentialStore) ResolveArguments(arguments json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(arguments, &fields); err != nil {
+		return arguments, nil
+	}
+	var alias string
+	if err := json.Unmarshal(fields["user_id"], &alias); err != nil {
+		return arguments, nil
+	}
+
+	var account AccountCredentials
+	ok := false
+	if c != nil {
+		account, ok = c.LookupAlias(alias)
+	}
+	if !ok {
+		return nil, fmt.Errorf("no account with alias %q (see whoop://accounts)", alias)
+	}
+	fields["user_id"] = json.RawMessage(strconv.Itoa(account.UserID))
+	resolved, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	}
+	return resolved, nil
+}
+
+// Summaries lists configured accounts without exposing any tokens
+func (c *CredentialStore) Summaries() []AccountSummary {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	summaries := make([]AccountSummary, 0, len(c.accounts))
+	for _, account := range c.accounts {
+		summaries = append(summaries, AccountSummary{
+			UserID:     account.UserID,
+			Alias:      account.Alias,
+			CanRefresh: account.RefreshToken != "",
+		})
+	}
+	return summaries
+}
+
+// SetReadOnly stops UpdateTokens from rewriting the accounts file
+func (c *CredentialStore) SetReadOnly(readOnly bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readOnly = readOnly
+}
+
+// UpdateTokens stores rotated tokens for a user and persists the accounts
+// file unless the store is read-only
+func (c *CredentialStore) UpdateTokens(userID int, accessToken, refreshToken string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range c.accounts {
+		if c.accounts[i].UserID != userID {
+			continue
+		}
+		c.accounts[i].AccessToken = accessToken
+		if refreshToken != "" {
+			c.accounts[i].RefreshToken = refreshToken
+		}
+		if c.readOnly {
+			return nil
+		}
+		return c.save()
+	}
+
+	return fmt.Errorf("no credentials configured for user %d", userID)
+}
+
+// save writes the accounts file; callers must hold the write lock
+func (c *CredentialStore) save() error {
+	data, err := json.MarshalIndent(credentialsFile{Accounts: c.accounts}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal accounts file: %w", err)
+	}
+
+	if err := os.WriteFile(c.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write accounts file: %w", err)
+	}
+	return nil
+}
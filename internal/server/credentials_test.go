@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCredentialStore_LookupAndUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	content := `{"accounts":[{"user_id":42,"alias":"Client A","access_token":"a1","refresh_token":"r1"}]}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write accounts file: %v", err)
+	}
+
+	store, err := LoadCredentialStore(path)
+	if err != nil {
+		t.Fatalf("LoadCredentialStore() error = %v", err)
+	}
+
+	if _, ok := store.Lookup(7); ok {
+		t.Error("Expected unknown user to be missing")
+	}
+
+	account, ok := store.Lookup(42)
+	if !ok || account.Alias != "Client A" {
+		t.Fatalf("Expected user 42 to be Client A, got %+v", account)
+	}
+
+	if err := store.UpdateTokens(42, "a2", ""); err != nil {
+		t.Fatalf("UpdateTokens() error = %v", err)
+	}
+
+	reloaded, err := LoadCredentialStore(path)
+	if err != nil {
+		t.Fatalf("reload error = %v", err)
+	}
+	account, _ = reloaded.Lookup(42)
+	if account.AccessToken != "a2" || account.RefreshToken != "r1" {
+		t.Errorf("Expected rotated access token with preserved refresh token, got %+v", account)
+	}
+}
+
+func TestLoadCredentialStore_RejectsDuplicates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	content := `{"accounts":[{"user_id":1,"access_token":"a"},{"user_id":1,"access_token":"b"}]}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write accounts file: %v", err)
+	}
+
+	if _, err := LoadCredentialStore(path); err == nil {
+		t.Error("Expected duplicate user IDs to be rejected")
+	}
+}
+
+func TestCredentialStore_Aliases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	content := `{"accounts":[{"user_id":42,"alias":"Client A","access_token":"a1"},{"user_id":43,"access_token":"b1"}]}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write accounts file: %v", err)
+	}
+	store, err := LoadCredentialStore(path)
+	if err != nil {
+		t.Fatalf("LoadCredentialStore() error = %v", err)
+	}
+
+	if account, ok := store.LookupAlias("client a"); !ok || account.UserID != 42 {
+		t.Errorf("LookupAlias(client a) = %+v, %v, want user 42", account, ok)
+	}
+	if _, ok := store.LookupAlias(""); ok {
+		t.Error("Expected an empty alias not to match accounts without one")
+	}
+
+	for arguments, want := range map[string]string{
+		`{"days": 7, "user_id": "CLIENT A"}`: `{"days":7,"user_id":42}`,
+		`{"days": 7, "user_id": 43}`:         `{"days": 7, "user_id": 43}`,
+		`{"days": 7}`:                        `{"days": 7}`,
+	} {
+		if resolved, err := store.ResolveArguments(json.RawMessage(arguments)); err != nil || string(resolved) != want {
+			t.Errorf("ResolveArguments(%s) = %s, %v, want %s", arguments, resolved, err, want)
+		}
+	}
+	if _, err := store.ResolveArguments(json.RawMessage(`{"user_id": "Client B"}`)); err == nil || !strings.Contains(err.Error(), `"Client B"`) {
+		t.Errorf("ResolveArguments(Client B) error = %v, want an unknown alias", err)
+	}
+	var none *CredentialStore
+	if _, err := none.ResolveArguments(json.RawMessage(`{"user_id": "Client A"}`)); err == nil {
+		t.Error("Expected an alias to fail without an accounts file")
+	}
+
+	duplicate := filepath.Join(t.TempDir(), "accounts.json")
+	content = `{"accounts":[{"user_id":1,"alias":"Sam","access_token":"a"},{"user_id":2,"alias":"sam","access_token":"b"}]}`
+	if err := os.WriteFile(duplicate, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write accounts file: %v", err)
+	}
+	if _, err := LoadCredentialStore(duplicate); err == nil {
+		t.Error("Expected duplicate aliases to be rejected")
+	}
+}
+
+func TestWhoopClient_ResolveAccount(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	content := `{"accounts":[{"user_id":42,"alias":"Client A","access_token":"a1","refresh_token":"r1"}]}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write accounts file: %v", err)
+	}
+	store, err := LoadCredentialStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, accounts := range []*CredentialStore{store, nil} {
+		client := &WhoopClient{accounts: accounts}
+		client.defaultUserID.Store(7)
+		own, unknown := 7, 9
+		if account, err := client.resolveAccount(context.Background(), &own); account != nil || err != nil {
+			t.Errorf("resolveAccount(7) = %+v, %v, want the default token", account, err)
+		}
+		if _, err := client.resolveAccount(context.Background(), &unknown); err == nil || err.Error() != "no credentials for user_id 9" {
+			t.Errorf("resolveAccount(9) error = %v, want no credentials", err)
+		}
+	}
+
+	client := &WhoopClient{accounts: store}
+	client.defaultUserID.Store(7)
+	client42 := 42
+	if account, err := client.resolveAccount(context.Background(), &client42); err != nil || account == nil || account.AccessToken != "a1" {
+		t.Errorf("resolveAccount(42) = %+v, %v, want Client A's credentials", account, err)
+	}
+}
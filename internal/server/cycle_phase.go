@@ -0,0 +1,185 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Menstrual cycle phases
+const (
+	phaseMenstrual  = "menstrual"
+	phaseFollicular = "follicular"
+	phaseOvulatory  = "ovulatory"
+	phaseLuteal     = "luteal"
+)
+
+// Cycle-aware mode sources
+const (
+	cycleModeCalendar    = "calendar"
+	cycleModeTemperature = "temperature"
+)
+
+const (
+	// defaultCycleLength is used when only the last period start is configured
+	defaultCycleLength = 28
+	// lutealPhaseDays is the typical, fairly fixed length of the luteal phase
+	lutealPhaseDays = 14
+	// menstrualPhaseDays is the typical length of menstruation
+	menstrualPhaseDays = 5
+
+	// lutealTempShift is the sustained skin temperature rise (°C) that marks ovulation
+	lutealTempShift = 0.2
+	// lutealShiftDays is how many consecutive raised readings confirm the shift
+	lutealShiftDays = 3
+	// lutealReferenceDays is how many prior readings form the follicular reference
+	lutealReferenceDays = 6
+
+	// lutealRHRAllowance is the expected luteal rise in resting heart rate (bpm)
+	lutealRHRAllowance = 3.0
+	// lutealTempAllowance is the expected luteal rise in skin temperature (°C)
+	lutealTempAllowance = 0.4
+)
+
+// MenstrualCycleConfig enables cycle-aware interpretation. Phases come either
+// from a calendar (last period start and cycle length) or are inferred from the
+// biphasic skin temperature pattern.
+type MenstrualCycleConfig struct {
+	Mode            string    `json:"mode"` // "calendar" or "temperature"
+	LastPeriodStart time.Time `json:"last_period_start,omitempty"`
+	CycleLength     int       `json:"cycle_length,omitempty"`
+}
+
+// LoadMenstrualCycleConfigFromEnv reads WHOOP_CYCLE_MODE (off, calendar,
+// temperature), WHOOP_CYCLE_LAST_PERIOD (YYYY-MM-DD), and WHOOP_CYCLE_LENGTH.
+// It returns nil when cycle-aware mode is off.
+func LoadMenstrualCycleConfigFromEnv(settings Settings) (*MenstrualCycleConfig, error) {
+	mode := strings.ToLower(strings.TrimSpace(settings("WHOOP_CYCLE_MODE")))
+	lastPeriod := strings.TrimSpace(settings("WHOOP_CYCLE_LAST_PERIOD"))
+
+	switch mode {
+	case "", "off":
+		if lastPeriod == "" {
+			return nil, nil
+		}
+		// A configured period date implies calendar mode
+		mode = cycleModeCalendar
+	case cycleModeCalendar, cycleModeTemperature:
+	default:
+		return nil, fmt.Errorf("unknown WHOOP_CYCLE_MODE %q (expected off, calendar, or temperature)", mode)
+	}
+
+	config := &MenstrualCycleConfig{Mode: mode, CycleLength: defaultCycleLength}
+	if length := strings.TrimSpace(settings("WHOOP_CYCLE_LENGTH")); length != "" {
+		days, err := strconv.Atoi(length)
+		if err != nil || days < 21 || days > 45 {
+			return nil, fmt.Errorf("invalid WHOOP_CYCLE_LENGTH %q (expected 21-45 days)", length)
+		}
+		config.CycleLength = days
+	}
+
+	if mode == cycleModeCalendar {
+		if lastPeriod == "" {
+			return nil, fmt.Errorf("WHOOP_CYCLE_MODE=calendar requires WHOOP_CYCLE_LAST_PERIOD")
+		}
+		start, err := time.Parse("2006-01-02", lastPeriod)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WHOOP_CYCLE_LAST_PERIOD: %w", err)
+		}
+		config.LastPeriodStart = start
+	}
+
+	return config, nil
+}
+
+// PhaseOn returns the calendar-predicted phase for a date
+func (c *MenstrualCycleConfig) PhaseOn(date time.Time) string {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	elapsed := int(day.Sub(c.LastPeriodStart).Hours() / 24)
+	cycleDay := ((elapsed % c.CycleLength) + c.CycleLength) % c.CycleLength
+
+	ovulation := c.CycleLength - lutealPhaseDays
+	switch {
+	case cycleDay < menstrualPhaseDays:
+		return phaseMenstrual
+	case cycleDay >= ovulation:
+		return phaseLuteal
+	case cycleDay >= ovulation-2:
+		return phaseOvulatory
+	default:
+		return phaseFollicular
+	}
+}
+
+// lutealDays returns the calendar days (see dayKey) in the luteal phase, or
+// nil when cycle-aware mode is off
+func (h *HealthAnalyzer) lutealDays(recoveries []WhoopRecovery) map[string]bool {
+	if h.cycle == nil {
+		return nil
+	}
+
+	if h.cycle.Mode == cycleModeTemperature {
+		var readings []datedValue
+		for _, v := range recoveryValues(recoveries, func(r WhoopRecovery) float64 { return r.Score.SkinTempCelsius }) {
+			if v.Value > 0 {
+				readings = append(readings, v)
+			}
+		}
+		return inferLutealDays(readings)
+	}
+
+	luteal := make(map[string]bool)
+	for _, recovery := range recoveries {
+		if h.cycle.PhaseOn(recovery.CreatedAt) == phaseLuteal {
+			luteal[dayKey(recovery.CreatedAt)] = true
+		}
+	}
+	return luteal
+}
+
+// inferLutealDays detects the post-ovulation temperature shift: lutealShiftDays
+// consecutive readings at least lutealTempShift above the mean of the preceding
+// lutealReferenceDays. The phase then lasts until temperature falls back to
+// the reference or lutealPhaseDays have passed.
+func inferLutealDays(readings []datedValue) map[string]bool {
+	luteal := make(map[string]bool)
+	for i := lutealReferenceDays; i+lutealShiftDays <= len(readings); {
+		var reference float64
+		for _, v := range readings[i-lutealReferenceDays : i] {
+			reference += v.Value
+		}
+		reference /= lutealReferenceDays
+
+		shifted := true
+		for _, v := range readings[i : i+lutealShiftDays] {
+			if v.Value < reference+lutealTempShift {
+				shifted = false
+				break
+			}
+		}
+		if !shifted {
+			i++
+			continue
+		}
+
+		start := readings[i].Date
+		j := i
+		for ; j < len(readings); j++ {
+			if readings[j].Date.Sub(start) >= lutealPhaseDays*24*time.Hour || readings[j].Value < reference+lutealTempShift/2 {
+				break
+			}
+			luteal[dayKey(readings[j].Date)] = true
+		}
+		i = j + lutealReferenceDays
+	}
+	return luteal
+}
+
+// cycleNote explains how many flagged days were attributed to the luteal phase
+func cycleNote(l *Localizer, adjusted int, what string) string {
+	if adjusted == 0 {
+		return ""
+	}
+	return l.T("*Cycle-aware mode: %d %s fell in the luteal phase and were not treated as stress.*", adjusted, what) + "\n\n"
+}
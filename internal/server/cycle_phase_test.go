@@ -0,0 +1,105 @@
+package server
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadMenstrualCycleConfigFromEnv(t *testing.T) {
+	t.Run("off by default", func(t *testing.T) {
+		t.Setenv("WHOOP_CYCLE_MODE", "")
+		t.Setenv("WHOOP_CYCLE_LAST_PERIOD", "")
+		config, err := LoadMenstrualCycleConfigFromEnv(os.Getenv)
+		if err != nil || config != nil {
+			t.Errorf("Expected nil config, got %+v, %v", config, err)
+		}
+	})
+
+	t.Run("period date implies calendar mode", func(t *testing.T) {
+		t.Setenv("WHOOP_CYCLE_MODE", "")
+		t.Setenv("WHOOP_CYCLE_LAST_PERIOD", "2024-09-01")
+		t.Setenv("WHOOP_CYCLE_LENGTH", "30")
+		config, err := LoadMenstrualCycleConfigFromEnv(os.Getenv)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.Mode != cycleModeCalendar || config.CycleLength != 30 {
+			t.Errorf("Unexpected config: %+v", config)
+		}
+	})
+
+	t.Run("calendar mode needs a date", func(t *testing.T) {
+		t.Setenv("WHOOP_CYCLE_MODE", "calendar")
+		t.Setenv("WHOOP_CYCLE_LAST_PERIOD", "")
+		if _, err := LoadMenstrualCycleConfigFromEnv(os.Getenv); err == nil {
+			t.Error("Expected an error without WHOOP_CYCLE_LAST_PERIOD")
+		}
+	})
+}
+
+func TestMenstrualCycleConfig_PhaseOn(t *testing.T) {
+	config := &MenstrualCycleConfig{
+		Mode:            cycleModeCalendar,
+		LastPeriodStart: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		CycleLength:     28,
+	}
+
+	cases := map[int]string{0: phaseMenstrual, 8: phaseFollicular, 12: phaseOvulatory, 14: phaseLuteal, 27: phaseLuteal, 28: phaseMenstrual}
+	for day, want := range cases {
+		if got := config.PhaseOn(config.LastPeriodStart.AddDate(0, 0, day)); got != want {
+			t.Errorf("Cycle day %d: got %s, want %s", day, got, want)
+		}
+	}
+}
+
+func TestInferLutealDays(t *testing.T) {
+	start := time.Date(2024, 9, 1, 7, 0, 0, 0, time.UTC)
+	var readings []datedValue
+	for i := 0; i < 28; i++ {
+		temp := 33.5
+		if i >= 14 {
+			temp = 33.9
+		}
+		readings = append(readings, datedValue{Date: start.AddDate(0, 0, i), Value: temp})
+	}
+
+	luteal := inferLutealDays(readings)
+
+	if luteal[dayKey(start.AddDate(0, 0, 13))] {
+		t.Error("Day before the shift should not be luteal")
+	}
+	for i := 14; i < 28; i++ {
+		if !luteal[dayKey(start.AddDate(0, 0, i))] {
+			t.Errorf("Day %d should be luteal", i)
+		}
+	}
+}
+
+func TestHealthAnalyzer_StressIndicators_CycleAware(t *testing.T) {
+	lastPeriod := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	var recoveries []WhoopRecovery
+	for i := 0; i < 28; i++ {
+		var recovery WhoopRecovery
+		recovery.CreatedAt = lastPeriod.AddDate(0, 0, i).Add(7 * time.Hour)
+		recovery.Score.RecoveryScore = 60
+		recovery.Score.HRVRmssd = 50
+		recovery.Score.RestingHeartRate = 55
+		if i >= 20 {
+			recovery.Score.RestingHeartRate = 57
+		}
+		recoveries = append(recoveries, recovery)
+	}
+	thresholds := BaselineThresholds{Source: "personal_baseline", HRVHigh: 70, RHRHigh: 56, PoorRecovery: 33}
+
+	analyzer := NewHealthAnalyzer()
+	if got := analyzer.analyzeStressIndicators(recoveries, nil, nil, thresholds); got.HighRestingHRDays != 8 {
+		t.Errorf("Expected 8 high RHR days without cycle mode, got %d", got.HighRestingHRDays)
+	}
+
+	analyzer.cycle = &MenstrualCycleConfig{Mode: cycleModeCalendar, LastPeriodStart: lastPeriod, CycleLength: 28}
+	got := analyzer.analyzeStressIndicators(recoveries, nil, nil, thresholds)
+	if got.HighRestingHRDays != 0 || got.CycleAdjustedDays != 8 {
+		t.Errorf("Expected luteal RHR rises to be adjusted, got %d high / %d adjusted", got.HighRestingHRDays, got.CycleAdjustedDays)
+	}
+}
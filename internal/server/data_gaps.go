@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// healthDatasets are the datasets fetchHealthData loads, in report order,
+// with the summary sections that depend on each
+var healthDatasets = []struct {
+	name     string
+	affected string
+}{
+	{"recovery", "recovery trend, stress indicators, and red flags"},
+	{"sleep", "sleep analysis, stress indicators, and red flags"},
+	{"workout", "activity patterns and training red flags"},
+	{"cycle", "day strain and calories"},
+}
+
+// DataGap is a dataset that could not be fetched for a report
+type DataGap struct {
+	Dataset string
+	Err     error
+}
+
+// fetchHealthDataPartial fetches recovery, sleep, workout, and cycle data
+// concurrently. Datasets that fail are left empty and reported as gaps, in
+// healthDatasets order, instead of failing the whole fetch. Only a failure
+// that needs re-authorization, and so fails every dataset, stops the rest.
+func (s *MCPServer) fetchHealthDataPartial(ctx context.Context, startDate, endDate time.Time, userID *int) (*HealthData, []DataGap) {
+	data, gaps, _ := s.fetchHealthDatasets(ctx, startDate, endDate, userID, func(err error) bool {
+		var authErr *AuthRequiredError
+		return errors.As(err, &authErr)
+	})
+	return data, gaps
+}
+
+// fetchHealthDatasets fetches recovery, sleep, workout, and cycle data
+// concurrently on the server's worker pool, reporting the datasets that fail
+// as gaps in healthDatasets order. The first failure that stop reports true
+// for cancels the fetches still running, which become gaps carrying that
+// failure, and is returned as err.
+func (s *MCPServer) fetchHealthDatasets(ctx context.Context, startDate, endDate time.Time, userID *int, stop func(error) bool) (data *HealthData, gaps []DataGap, err error) {
+	ctx, stage := startSpan(ctx, "fetch health data", spanInternal)
+	stage.SetAttribute("whoop.days", int(endDate.Sub(startDate).Hours()/24))
+	defer func() { stage.End(err) }()
+
+	// Each fetch fills its own field and error slot; Wait orders those writes
+	// before the reads below
+	data = &HealthData{}
+	errs := make([]error, len(healthDatasets))
+	stopped := make([]bool, len(healthDatasets))
+	group, ctx := s.fetchPool.group(ctx)
+
+	fetch := func(i int, get func() error) {
+		group.Go(func() error {
+			err := ctx.Err() // another dataset failed while this one queued
+			if err == nil {
+				err = get()
+			}
+			if err == nil {
+				return nil
+			}
+			if group.stopped(err) {
+				stopped[i] = true
+				return nil
+			}
+			errs[i] = fmt.Errorf("failed to get %s data: %w", healthDatasets[i].name, err)
+			if stop(err) {
+				return errs[i]
+			}
+			return nil
+		})
+	}
+	fetch(0, func() error {
+		return s.whoopClient.EachRecoveryPage(ctx, startDate, endDate, userID, collectPages(ctx, &data.Recoveries))
+	})
+	fetch(1, func() error {
+		return s.whoopClient.EachSleepPage(ctx, startDate, endDate, userID, collectPages(ctx, &data.Sleeps))
+	})
+	fetch(2, func() error {
+		return s.whoopClient.EachWorkoutPage(ctx, startDate, endDate, userID, collectPages(ctx, &data.Workouts))
+	})
+	fetch(3, func() error {
+		return s.whoopClient.EachCyclePage(ctx, startDate, endDate, userID, collectPages(ctx, &data.Cycles))
+	})
+	err = group.Wait()
+
+	for i := range healthDatasets {
+		switch {
+		case stopped[i]:
+			gaps = append(gaps, DataGap{Dataset: healthDatasets[i].name, Err: err})
+		case errs[i] != nil:
+			gaps = append(gaps, DataGap{Dataset: healthDatasets[i].name, Err: errs[i]})
+		}
+	}
+	stage.SetAttribute("whoop.gaps", len(gaps))
+	return data, gaps, err
+}
+
+// FormatDataGaps renders the section listing datasets missing from a
+// report and what they affect, or an empty string when nothing is missing
+func FormatDataGaps(gaps []DataGap) string {
+	if len(gaps) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString("## Data Gaps\n\n")
+	builder.WriteString("Some data could not be fetched, so this summary is partial. Sections that depend on it reflect missing data rather than the user's actual health.\n\n")
+	for _, gap := range gaps {
+		affected := ""
+		for _, dataset := range healthDatasets {
+			if dataset.name == gap.Dataset {
+				affected = dataset.affected
+			}
+		}
+		fmt.Fprintf(&builder, "- **%s**: %s", gap.Dataset, gapReason(gap.Err))
+		if affected != "" {
+			fmt.Fprintf(&builder, " (affects %s)", affected)
+		}
+		builder.WriteString("\n")
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// gapReason explains a fetch failure, pointing at re-authorization when the
+// token lacks a scope
+func gapReason(err error) string {
+	var scopeErr *MissingScopeError
+	if errors.As(err, &scopeErr) {
+		return fmt.Sprintf("the Whoop token was not granted %s; re-authorize and approve it", scopeErr.Scope)
+	}
+	return err.Error()
+}
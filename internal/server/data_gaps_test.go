@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFormatDataGaps(t *testing.T) {
+	if got := FormatDataGaps(nil); got != "" {
+		t.Errorf("FormatDataGaps(nil) = %q, want empty", got)
+	}
+
+	gaps := []DataGap{
+		{Dataset: "workout", Err: fmt.Errorf("failed to get workout data: %w", &MissingScopeError{Scope: "read:workout", Endpoint: "/v2/activity/workout"})},
+		{Dataset: "cycle", Err: errors.New("failed to get cycle data: API request failed with status 500")},
+	}
+	got := FormatDataGaps(gaps)
+
+	for _, want := range []string{
+		"## Data Gaps",
+		"- **workout**: the Whoop token was not granted read:workout; re-authorize and approve it (affects activity patterns and training red flags)",
+		"- **cycle**: failed to get cycle data: API request failed with status 500 (affects day strain and calories)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatDataGaps() missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.HasSuffix(got, "\n") {
+		t.Error("FormatDataGaps() should not end with a newline")
+	}
+}
+
+// failingSleepAPI fails sleep fetches after a short delay, while recoveries
+// arrive a page at a time for as long as the caller keeps taking them
+type failingSleepAPI struct {
+	*MockWhoopAPI
+	err               error
+	recoveryPages     atomic.Int64
+	maxRecoveryPages  int
+	recoveryPageDelay time.Duration
+}
+
+func (a *failingSleepAPI) EachSleepPage(ctx context.Context, startDate, endDate time.Time, userID *int, page func([]WhoopSleep) error) error {
+	time.Sleep(5 * time.Millisecond)
+	return a.err
+}
+
+func (a *failingSleepAPI) EachRecoveryPage(ctx context.Context, startDate, endDate time.Time, userID *int, page func([]WhoopRecovery) error) error {
+	for i := 0; i < a.maxRecoveryPages; i++ {
+		time.Sleep(a.recoveryPageDelay)
+		a.recoveryPages.Add(1)
+		if err := page([]WhoopRecovery{{SleepID: fmt.Sprint(i)}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestFetchHealthData_FirstErrorStopsOthers(t *testing.T) {
+	api := &failingSleepAPI{MockWhoopAPI: NewMockWhoopAPI(), err: errors.New("upstream down"), maxRecoveryPages: 200, recoveryPageDelay: time.Millisecond}
+	server := newMockServer(t, api.MockWhoopAPI)
+	server.whoopClient = api
+
+	_, err := server.fetchHealthData(context.Background(), time.Now().AddDate(0, 0, -30), time.Now(), nil)
+	if err == nil || !errors.Is(err, api.err) || !strings.Contains(err.Error(), "sleep") {
+		t.Fatalf("fetchHealthData() error = %v, want the sleep failure", err)
+	}
+	if pages := api.recoveryPages.Load(); pages >= int64(api.maxRecoveryPages) {
+		t.Errorf("recovery fetch ran all %d pages after sleep failed", pages)
+	}
+}
+
+func TestFetchHealthDataPartial_KeepsOtherDatasets(t *testing.T) {
+	api := &failingSleepAPI{MockWhoopAPI: NewMockWhoopAPI(), err: errors.New("upstream down"), maxRecoveryPages: 20}
+	server := newMockServer(t, api.MockWhoopAPI)
+	server.whoopClient = api
+
+	data, gaps := server.fetchHealthDataPartial(context.Background(), time.Now().AddDate(0, 0, -30), time.Now(), nil)
+	if len(gaps) != 1 || gaps[0].Dataset != "sleep" || len(data.Recoveries) != 20 {
+		t.Errorf("gaps = %v with %d recoveries, want only a sleep gap and every recovery", gaps, len(data.Recoveries))
+	}
+
+	api.err = &AuthRequiredError{Reason: "token revoked"}
+	api.recoveryPageDelay = time.Millisecond
+	api.maxRecoveryPages = 200
+	api.recoveryPages.Store(0)
+	_, gaps = server.fetchHealthDataPartial(context.Background(), time.Now().AddDate(0, 0, -30), time.Now(), nil)
+	var authErr *AuthRequiredError
+	if len(gaps) == 0 || !errors.As(gaps[0].Err, &authErr) || api.recoveryPages.Load() >= int64(api.maxRecoveryPages) {
+		t.Errorf("gaps = %v after %d recovery pages, want re-authorization to stop every fetch", gaps, api.recoveryPages.Load())
+	}
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxListedGaps caps the missing-day runs listed in a data quality note
+const maxListedGaps = 5
+
+// Confidence levels of an analysis, from how much of its range has data
+const (
+	confidenceHigh   = "high"
+	confidenceMedium = "medium"
+	confidenceLow    = "low"
+)
+
+// DatasetCoverage is how many days of a range have a scored record of a
+// dataset
+type DatasetCoverage struct {
+	Dataset string
+	Days    int
+	Total   int
+}
+
+// DataQuality describes how complete the data behind an analysis is, so sparse
+// data is not read as a confident trend
+type DataQuality struct {
+	Label      string // period name when a report covers several periods
+	Coverage   []DatasetCoverage
+	Gaps       []DateRange // runs of days without any of the covered datasets
+	Confidence string
+	Unscored   UnscoredRecords
+}
+
+// coverageDays returns the local days with a scored record of dataset:
+// recoveries and main sleeps by wake day, cycles by the day they cover
+func coverageDays(data *HealthData, dataset string) map[string]bool {
+	var values []datedValue
+	switch dataset {
+	case "recovery":
+		values = localRecoveryValues(data, func(WhoopRecovery) float64 { return 0 })
+	case "sleep":
+		values = mainSleepValues(data.Sleeps, func(WhoopSleep) float64 { return 0 })
+	case "cycle":
+		strain, _ := lookupMetric("strain")
+		values = strain.extract(data)
+	}
+	days := make(map[string]bool, len(values))
+	for _, value := range values {
+		days[dayKey(value.Date)] = true
+	}
+	return days
+}
+
+// assessDataQuality measures the coverage of datasets over the whole days
+// from start to end. A start after midnight begins the next day, since that
+// day's records were not fetched in full.
+func assessDataQuality(data *HealthData, start, end time.Time, unscored UnscoredRecords, datasets ...string) DataQuality {
+	covered := make(map[string]map[string]bool, len(datasets))
+	for _, dataset := range datasets {
+		covered[dataset] = coverageDays(data, dataset)
+	}
+	return assessCoverage(covered, start, end, unscored, datasets...)
+}
+
+// assessCoverage is assessDataQuality over the days already found to have a
+// record of each dataset, for analyses that never hold all the records
+func assessCoverage(covered map[string]map[string]bool, start, end time.Time, unscored UnscoredRecords, datasets ...string) DataQuality {
+	quality := DataQuality{Unscored: unscored}
+
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	if first.Before(start) {
+		first = first.AddDate(0, 0, 1)
+	}
+	end = end.In(start.Location())
+	last := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, start.Location())
+	if last.Before(first) {
+		return quality
+	}
+
+	for _, dataset := range datasets {
+		quality.Coverage = append(quality.Coverage, DatasetCoverage{Dataset: dataset})
+	}
+
+	var gap *DateRange
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		key := dayKey(day)
+		found := false
+		for i, dataset := range datasets {
+			quality.Coverage[i].Total++
+			if covered[dataset][key] {
+				quality.Coverage[i].Days++
+				found = true
+			}
+		}
+		switch {
+		case found:
+			gap = nil
+		case gap == nil:
+			quality.Gaps = append(quality.Gaps, DateRange{Start: day, End: day})
+			gap = &quality.Gaps[len(quality.Gaps)-1]
+		default:
+			gap.End = day
+		}
+	}
+
+	quality.Confidence = confidenceLevel(quality.Coverage)
+	return quality
+}
+
+// confidenceLevel rates the least covered dataset: high with 80% of days and
+// at least a week of data, medium with half the days and at least four
+func confidenceLevel(coverage []DatasetCoverage) string {
+	if len(coverage) == 0 {
+		return ""
+	}
+	level := confidenceHigh
+	for _, c := range coverage {
+		fraction := float64(c.Days) / float64(c.Total)
+		switch {
+		case fraction >= 0.8 && c.Days >= 7:
+		case fraction >= 0.5 && c.Days >= 4:
+			if level == confidenceHigh {
+				level = confidenceMedium
+			}
+		default:
+			return confidenceLow
+		}
+	}
+	return level
+}
+
+// fetchScoredHealthData fetches all datasets like fetchHealthData, drops the
+// records Whoop has not scored, and assesses recovery and sleep coverage
+func (s *MCPServer) fetchScoredHealthData(ctx context.Context, startDate, endDate time.Time, userID *int) (*HealthData, DataQuality, error) {
+	data, err := s.fetchHealthData(ctx, startDate, endDate, userID)
+	if err != nil {
+		return nil, DataQuality{}, err
+	}
+	_, stage := startSpan(ctx, "score health data", spanInternal)
+	defer stage.End(nil)
+	scoredData, unscored := scoredHealthData(data)
+	return scoredData, assessDataQuality(scoredData, startDate, endDate, unscored, "recovery", "sleep"), nil
+}
+
+// FormatDataQuality renders the coverage and excluded-record notes for an
+// analysis
+func FormatDataQuality(quality DataQuality) string {
+	var lines []string
+	if len(quality.Coverage) > 0 {
+		parts := make([]string, len(quality.Coverage))
+		for i, c := range quality.Coverage {
+			parts[i] = fmt.Sprintf("%s %d of %d days", c.Dataset, c.Days, c.Total)
+		}
+		heading := "Data coverage"
+		if quality.Label != "" {
+			heading += " (" + quality.Label + ")"
+		}
+		line := fmt.Sprintf("_%s: %s (confidence: %s).", heading, strings.Join(parts, ", "), quality.Confidence)
+		if len(quality.Gaps) > 0 {
+			gaps := make([]string, 0, maxListedGaps)
+			for i, gap := range quality.Gaps {
+				if i == maxListedGaps {
+					gaps = append(gaps, fmt.Sprintf("and %d more", len(quality.Gaps)-maxListedGaps))
+					break
+				}
+				if gap.Start.Equal(gap.End) {
+					gaps = append(gaps, dayKey(gap.Start))
+				} else {
+					gaps = append(gaps, dayKey(gap.Start)+" to "+dayKey(gap.End))
+				}
+			}
+			line += " Missing days: " + strings.Join(gaps, ", ") + "."
+		}
+		if quality.Confidence == confidenceLow {
+			line += " With this much missing data, trends and averages may not reflect the user's actual pattern."
+		}
+		lines = append(lines, line+"_")
+	}
+	if note := FormatUnscoredNote(quality.Unscored); note != "" {
+		lines = append(lines, note)
+	}
+	return strings.Join(lines, "\n\n")
+}
+
+// withDataQuality appends the data quality notes to an analysis report
+func withDataQuality(report string, quality DataQuality) string {
+	if notes := FormatDataQuality(quality); notes != "" {
+		return report + "\n\n" + notes
+	}
+	return report
+}
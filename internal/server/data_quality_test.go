@@ -0,0 +1,74 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAssessDataQuality(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 7, 0, 0, 0, time.UTC) }
+	data := &HealthData{}
+	for _, d := range []int{1, 2, 3, 7, 8, 9, 10} {
+		data.Sleeps = append(data.Sleeps, WhoopSleep{ID: string(rune('a' + d)), End: day(d), ScoreState: "SCORED"})
+	}
+	for _, d := range []int{1, 2, 3, 9, 10} {
+		data.Recoveries = append(data.Recoveries, WhoopRecovery{SleepID: string(rune('a' + d)), CreatedAt: day(d), ScoreState: "SCORED"})
+	}
+
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 5, 10, 23, 59, 59, 0, time.UTC)
+	quality := assessDataQuality(data, start, end, nil, "recovery", "sleep")
+
+	want := []DatasetCoverage{{"recovery", 5, 10}, {"sleep", 7, 10}}
+	for i, c := range want {
+		if quality.Coverage[i] != c {
+			t.Errorf("Coverage[%d] = %+v, want %+v", i, quality.Coverage[i], c)
+		}
+	}
+	if len(quality.Gaps) != 1 || dayKey(quality.Gaps[0].Start) != "2024-05-04" || dayKey(quality.Gaps[0].End) != "2024-05-06" {
+		t.Errorf("Gaps = %+v, want 2024-05-04 to 2024-05-06", quality.Gaps)
+	}
+	if quality.Confidence != confidenceMedium {
+		t.Errorf("Confidence = %q, want %q", quality.Confidence, confidenceMedium)
+	}
+
+	got := FormatDataQuality(quality)
+	if !strings.Contains(got, "_Data coverage: recovery 5 of 10 days, sleep 7 of 10 days (confidence: medium). Missing days: 2024-05-04 to 2024-05-06._") {
+		t.Errorf("FormatDataQuality() = %q", got)
+	}
+}
+
+func TestAssessDataQualityPartialFirstDay(t *testing.T) {
+	// A range starting mid-afternoon, like "last 7 days" from now, begins the next day
+	start := time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 5, 8, 15, 0, 0, 0, time.UTC)
+	quality := assessDataQuality(&HealthData{}, start, end, nil, "sleep")
+
+	if quality.Coverage[0].Total != 7 {
+		t.Errorf("Total = %d, want 7", quality.Coverage[0].Total)
+	}
+	if quality.Confidence != confidenceLow {
+		t.Errorf("Confidence = %q, want %q", quality.Confidence, confidenceLow)
+	}
+	if got := FormatDataQuality(quality); !strings.Contains(got, "Missing days: 2024-05-02 to 2024-05-08. With this much missing data") {
+		t.Errorf("FormatDataQuality() = %q", got)
+	}
+}
+
+func TestConfidenceLevel(t *testing.T) {
+	tests := []struct {
+		coverage []DatasetCoverage
+		want     string
+	}{
+		{[]DatasetCoverage{{"recovery", 14, 14}, {"sleep", 12, 14}}, confidenceHigh},
+		{[]DatasetCoverage{{"recovery", 5, 5}}, confidenceMedium}, // complete but under a week
+		{[]DatasetCoverage{{"recovery", 14, 14}, {"sleep", 6, 14}}, confidenceLow},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := confidenceLevel(tt.coverage); got != tt.want {
+			t.Errorf("confidenceLevel(%+v) = %q, want %q", tt.coverage, got, tt.want)
+		}
+	}
+}
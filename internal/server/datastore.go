@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// localDataFiles lists every datastore entry as its override variable and
+// default name; `whoop-mcp purge` wipes all of them
+var localDataFiles = [][2]string{
+	{"WHOOP_BASELINE_FILE", "baselines.json"},
+	{"WHOOP_BURNOUT_FILE", "burnout.json"},
+	{"WHOOP_ANNOTATIONS_FILE", "annotations.json"},
+	{"WHOOP_ANNOTATION_SUMMARIES_FILE", "annotation_summaries.json"},
+	{"WHOOP_GOALS_FILE", "goals.json"},
+	{"WHOOP_RED_FLAG_ACKS_FILE", "red_flag_acks.json"},
+	{"WHOOP_NOTIFICATIONS_FILE", "notifications.json"},
+	{"WHOOP_REPORTS_DIR", "reports"},
+	{"WHOOP_AUDIT_LOG_FILE", "audit.ndjson"},
+}
+
+// errReadOnlyDatastore is returned by datastore writes in read-only mode
+var errReadOnlyDatastore = errors.New("the datastore is read-only because the server runs in read-only mode")
+
+// storeMode is embedded in every datastore store so read-only mode refuses
+// writes at the store itself, whichever tool or background job asks for them.
+// It also holds the server's datastore cipher, nil when files are plaintext.
+type storeMode struct {
+	readOnly atomic.Bool
+	cipher   *DataCipher
+}
+
+// SetReadOnly makes the store refuse writes
+func (m *storeMode) SetReadOnly(readOnly bool) {
+	m.readOnly.Store(readOnly)
+}
+
+// writable returns errReadOnlyDatastore in read-only mode
+func (m *storeMode) writable() error {
+	if m.readOnly.Load() {
+		return errReadOnlyDatastore
+	}
+	return nil
+}
+
+// localDataPath resolves a file in the local datastore: the path in envVar
+// when set, otherwise name under WHOOP_DATA_DIR, or under
+// $XDG_CONFIG_HOME/whoop-mcp (or the OS equivalent)
+func localDataPath(settings Settings, envVar, name string) (string, error) {
+	if path := settings(envVar); path != "" {
+		return path, nil
+	}
+	if dir := settings("WHOOP_DATA_DIR"); dir != "" {
+		return filepath.Join(dir, name), nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, "whoop-mcp", name), nil
+}
+
+// readJSONFile decodes the datastore file at path into v, decrypting it with
+// c if needed, and leaves v untouched when the file does not exist
+func readJSONFile(c *DataCipher, path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	if data, err = openDatastore(c, data); err != nil {
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// writeJSONFile atomically replaces the datastore file at path with v,
+// encrypted with c when it is set and readable only by the owner
+func writeJSONFile(c *DataCipher, path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", filepath.Base(path), err)
+	}
+	if data, err = sealDatastore(c, data); err != nil {
+		return fmt.Errorf("failed to encrypt %s: %w", filepath.Base(path), err)
+	}
+	return writePrivateFile(path, data)
+}
+
+// writePrivateFile atomically replaces path with data, readable only by the owner
+func writePrivateFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,350 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateArgumentPairs are the start/end tool arguments that accept date
+// expressions, echoed back when they were not literal dates
+var dateArgumentPairs = [][2]string{
+	{"start_date", "end_date"},
+	{"period_a_start", "period_a_end"},
+	{"period_b_start", "period_b_end"},
+}
+
+// recentRangeDays is how many days back from today each tool that analyzes
+// recent history covers when called without a start_date or days
+var recentRangeDays = map[string]int{
+	"analyze_health_trends":     14,
+	"analyze_hrv":               60, // long enough for a stable baseline
+	"analyze_resting_hr":        60, // two month-over-month windows
+	"analyze_vitals":            30,
+	"predict_illness_risk":      30,
+	"analyze_circadian":         28, // four weekends for social jetlag
+	"detect_travel":             60,
+	"analyze_sleep_debt":        28,
+	"correlate_metrics":         60,
+	"analyze_weekly_rhythm":     56, // eight of each weekday
+	"detect_anomalies":          60,
+	"analyze_training_load":     42, // a full chronic window plus two weeks of ratios
+	"analyze_energy":            28,
+	"forecast_recovery":         60,
+	"correlate_mood":            60,
+	"analyze_late_night_impact": 90, // three months of frequency
+	"analyze_late_exercise":     90, // enough evening sessions to compare
+	"list_red_flags":            14,
+	"render_chart":              30,
+}
+
+var (
+	isoDatePattern      = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	relativeAgoPattern  = regexp.MustCompile(`^(\d+|a|an|one) (day|week|month|year)s? ago$`)
+	relativePastPattern = regexp.MustCompile(`^(?:past|last|previous) (?:(\d+|a|one) )?(day|week|month|year)s?$`)
+	monthDayPattern     = regexp.MustCompile(`^([a-z]+)\.? (\d{1,2})(?:st|nd|rd|th)?(?:,? (\d{4}))?$`)
+	monthYearPattern    = regexp.MustCompile(`^([a-z]+)\.?(?:,? (\d{4}))?$`)
+)
+
+// dateSpan is the days a date expression covers (midnight in the caller's
+// location, inclusive)
+type dateSpan struct {
+	First, Last time.Time
+	// Point marks a single date, such as 2024-03-05 or "3 days ago", that runs
+	// through today when used as a range start without an end
+	Point bool
+}
+
+// parseDateExpression resolves a date or date expression to the days it
+// covers. Accepted forms:
+//
+//   - 2024-03-05
+//   - today, yesterday, 3 days ago, 2 weeks ago
+//   - this week, last week (Monday to Sunday), this month, last month,
+//     this year, last year
+//   - past 30 days, last 2 weeks, past month (ending today)
+//   - March, March 2024 (the whole month; the most recent March without a year)
+//   - March 5, Mar 5th, March 5, 2024
+func parseDateExpression(expr string, now time.Time) (dateSpan, error) {
+	text := strings.Join(strings.Fields(strings.ToLower(expr)), " ")
+	location := now.Location()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+
+	if isoDatePattern.MatchString(text) {
+		date, err := time.ParseInLocation("2006-01-02", text, location)
+		return dateSpan{First: date, Last: date, Point: true}, err
+	}
+
+	switch text {
+	case "today":
+		return dateSpan{First: today, Last: today}, nil
+	case "yesterday":
+		yesterday := today.AddDate(0, 0, -1)
+		return dateSpan{First: yesterday, Last: yesterday}, nil
+	case "this week":
+		return dateSpan{First: weekStart(today), Last: today}, nil
+	case "last week", "previous week":
+		start := weekStart(today).AddDate(0, 0, -7)
+		return dateSpan{First: start, Last: start.AddDate(0, 0, 6)}, nil
+	case "this month":
+		return dateSpan{First: today.AddDate(0, 0, 1-today.Day()), Last: today}, nil
+	case "last month", "previous month":
+		start := today.AddDate(0, -1, 1-today.Day())
+		return dateSpan{First: start, Last: start.AddDate(0, 1, -1)}, nil
+	case "this year":
+		return dateSpan{First: time.Date(today.Year(), 1, 1, 0, 0, 0, 0, location), Last: today}, nil
+	case "last year", "previous year":
+		start := time.Date(today.Year()-1, 1, 1, 0, 0, 0, 0, location)
+		return dateSpan{First: start, Last: start.AddDate(1, 0, -1)}, nil
+	}
+
+	if match := relativeAgoPattern.FindStringSubmatch(text); match != nil {
+		day := shiftDate(today, match[2], -countWord(match[1]))
+		return dateSpan{First: day, Last: day, Point: true}, nil
+	}
+	if match := relativePastPattern.FindStringSubmatch(text); match != nil {
+		// "last 7 days" is the 7 days ending today, like days: 7; "past month"
+		// runs from the day after the same date last month
+		first := shiftDate(today, match[2], -countWord(match[1])).AddDate(0, 0, 1)
+		return dateSpan{First: first, Last: today}, nil
+	}
+	if match := monthDayPattern.FindStringSubmatch(text); match != nil {
+		if month, ok := parseMonthName(match[1]); ok {
+			day, _ := strconv.Atoi(match[2])
+			year := today.Year()
+			if match[3] != "" {
+				year, _ = strconv.Atoi(match[3])
+			}
+			date := time.Date(year, month, day, 0, 0, 0, 0, location)
+			if date.Day() != day {
+				return dateSpan{}, fmt.Errorf("invalid date %q", expr)
+			}
+			if match[3] == "" && date.After(today) {
+				date = date.AddDate(-1, 0, 0)
+			}
+			return dateSpan{First: date, Last: date, Point: true}, nil
+		}
+	}
+	if match := monthYearPattern.FindStringSubmatch(text); match != nil {
+		if month, ok := parseMonthName(match[1]); ok {
+			year := today.Year()
+			if match[2] != "" {
+				year, _ = strconv.Atoi(match[2])
+			} else if month > today.Month() {
+				year--
+			}
+			start := time.Date(year, month, 1, 0, 0, 0, 0, location)
+			return dateSpan{First: start, Last: start.AddDate(0, 1, -1)}, nil
+		}
+	}
+
+	return dateSpan{}, fmt.Errorf("unrecognized date %q (use YYYY-MM-DD or an expression like \"last week\", \"past 30 days\", \"March\", or \"yesterday\")", expr)
+}
+
+// parseDate resolves a single-date argument to the first day its expression
+// covers, so "last week" means that week's Monday
+func parseDate(expr string, now time.Time) (time.Time, error) {
+	span, err := parseDateExpression(expr, now)
+	return span.First, err
+}
+
+// weekStart is the Monday of day's week, in day's location
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// localDayBounds turns a day range into the instants the API is queried
+// with: local midnight of the first day through the last second of the last
+// day. Days are built on the wall clock, so a range spanning a DST change is
+// still whole local days.
+func localDayBounds(first, last time.Time) (time.Time, time.Time) {
+	return first, last.AddDate(0, 0, 1).Add(-time.Second)
+}
+
+// resolveDateRange resolves a tool's date range to whole days: start_date
+// through end_date, or days days from start_date. Without either, a range
+// expression such as "last week" covers itself and a single date runs
+// through today. Ranges may not start in the future; ends past today are
+// clamped to today.
+func resolveDateRange(input DateRangeInput, now time.Time) (time.Time, time.Time, error) {
+	start, err := parseDateExpression(input.StartDate, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start_date: %w", err)
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	first, last := start.First, start.Last
+	if start.Point {
+		last = today
+	}
+
+	switch {
+	case input.Days < 0:
+		return time.Time{}, time.Time{}, fmt.Errorf("days must be positive")
+	case input.Days > 0 && strings.TrimSpace(input.EndDate) != "":
+		return time.Time{}, time.Time{}, fmt.Errorf("use either end_date or days, not both")
+	case input.Days > 0:
+		last = first.AddDate(0, 0, input.Days-1)
+	case strings.TrimSpace(input.EndDate) != "":
+		end, err := parseDateExpression(input.EndDate, now)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end_date: %w", err)
+		}
+		last = end.Last
+	}
+
+	if first.After(today) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start_date %s is in the future (today is %s in %s); Whoop only has data up to now",
+			dayKey(first), dayKey(today), now.Location())
+	}
+	if last.Before(first) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end_date %s is before start_date %s", dayKey(last), dayKey(first))
+	}
+	if last.After(today) {
+		last = today
+	}
+	return first, last, nil
+}
+
+// recentStartDateProperty is the start_date argument of the tools in
+// recentRangeDays
+func recentStartDateProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Optional start date in YYYY-MM-DD format or an expression such as \"last month\" or \"March\", instead of the last days; days then counts from here",
+	}
+}
+
+// recentEndDateProperty is the end_date argument of the tools in
+// recentRangeDays
+func recentEndDateProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Optional end date in YYYY-MM-DD format or an expression such as \"yesterday\" (default: today, or the end of a start_date range like \"last month\")",
+	}
+}
+
+// resolveRecentRange resolves the range of a tool in recentRangeDays. With a
+// start_date it is resolveDateRange; otherwise it covers days whole days, or
+// defaultDays, ending at end_date or today.
+func resolveRecentRange(input DateRangeInput, defaultDays int, now time.Time) (time.Time, time.Time, error) {
+	if strings.TrimSpace(input.StartDate) != "" {
+		return resolveDateRange(input, now)
+	}
+	if input.Days < 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("days must be positive")
+	}
+	days := input.Days
+	if days == 0 {
+		days = defaultDays
+	}
+
+	last := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if strings.TrimSpace(input.EndDate) != "" {
+		end, err := parseDateExpression(input.EndDate, now)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end_date: %w", err)
+		}
+		if end.Last.Before(last) {
+			last = end.Last
+		}
+	}
+	return last.AddDate(0, 0, 1-days), last, nil
+}
+
+// rangeDays counts the local days from start through end
+func rangeDays(start, end time.Time) int {
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	return int(last.Sub(first).Hours()/24) + 1
+}
+
+// shiftDate moves day by n units of day, week, month, or year
+func shiftDate(day time.Time, unit string, n int) time.Time {
+	switch unit {
+	case "week":
+		return day.AddDate(0, 0, 7*n)
+	case "month":
+		return day.AddDate(0, n, 0)
+	case "year":
+		return day.AddDate(n, 0, 0)
+	default:
+		return day.AddDate(0, 0, n)
+	}
+}
+
+// countWord parses a count that may be spelled "a", "an", "one", or omitted
+func countWord(word string) int {
+	if n, err := strconv.Atoi(word); err == nil {
+		return n
+	}
+	return 1
+}
+
+// parseMonthName matches full or three-letter English month names
+func parseMonthName(name string) (time.Month, bool) {
+	for month := time.January; month <= time.December; month++ {
+		full := strings.ToLower(month.String())
+		if name == full || (len(name) >= 3 && strings.HasPrefix(full, name)) {
+			return month, true
+		}
+	}
+	return 0, false
+}
+
+// describeResolvedDates lists how the date arguments of a tool call were
+// resolved, so the model can confirm the range it asked for. Calls that only
+// pass literal YYYY-MM-DD start and end dates are not echoed; tools in
+// recentRangeDays called without a start_date echo the days they covered.
+func describeResolvedDates(tool string, arguments json.RawMessage, now time.Time) string {
+	var args map[string]interface{}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return ""
+	}
+	var lines []string
+	if defaultDays, ok := recentRangeDays[tool]; ok {
+		if start, _ := args["start_date"].(string); start == "" {
+			input := DateRangeInput{}
+			input.EndDate, _ = args["end_date"].(string)
+			if days, ok := args["days"].(float64); ok {
+				input.Days = int(days)
+			}
+			first, last, err := resolveRecentRange(input, defaultDays, now)
+			if err != nil {
+				return ""
+			}
+			through := "today"
+			if input.EndDate != "" {
+				through = fmt.Sprintf("%q", input.EndDate)
+			}
+			return fmt.Sprintf("_Resolved %d days through %s as %s to %s (%s)_", rangeDays(first, last), through, dayKey(first), dayKey(last), now.Location())
+		}
+	}
+	for i, pair := range dateArgumentPairs {
+		input := DateRangeInput{}
+		input.StartDate, _ = args[pair[0]].(string)
+		input.EndDate, _ = args[pair[1]].(string)
+		if days, ok := args["days"].(float64); ok && i == 0 {
+			input.Days = int(days)
+		}
+		if input.StartDate == "" || (isoDatePattern.MatchString(input.StartDate) && isoDatePattern.MatchString(input.EndDate)) {
+			continue
+		}
+		first, last, err := resolveDateRange(input, now)
+		if err != nil {
+			continue
+		}
+
+		quoted := fmt.Sprintf("%q", input.StartDate)
+		switch {
+		case input.Days > 0:
+			quoted += fmt.Sprintf(" + %d days", input.Days)
+		case input.EndDate != "" && input.EndDate != input.StartDate:
+			quoted += fmt.Sprintf(" to %q", input.EndDate)
+		}
+		lines = append(lines, fmt.Sprintf("_Resolved %s as %s to %s (%s)_", quoted, dayKey(first), dayKey(last), now.Location()))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDateExpression(t *testing.T) {
+	// Wednesday
+	now := time.Date(2024, 5, 15, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr       string
+		start, end string
+	}{
+		{"2024-03-05", "2024-03-05", "2024-03-05"},
+		{"Today", "2024-05-15", "2024-05-15"},
+		{"yesterday", "2024-05-14", "2024-05-14"},
+		{"3 days ago", "2024-05-12", "2024-05-12"},
+		{"a week ago", "2024-05-08", "2024-05-08"},
+		{"this week", "2024-05-13", "2024-05-15"},
+		{"last week", "2024-05-06", "2024-05-12"},
+		{"past 30 days", "2024-04-16", "2024-05-15"},
+		{"last 2 weeks", "2024-05-02", "2024-05-15"},
+		{"past month", "2024-04-16", "2024-05-15"},
+		{"past day", "2024-05-15", "2024-05-15"},
+		{"this month", "2024-05-01", "2024-05-15"},
+		{"last month", "2024-04-01", "2024-04-30"},
+		{"last year", "2023-01-01", "2023-12-31"},
+		{"March", "2024-03-01", "2024-03-31"},
+		{"september", "2023-09-01", "2023-09-30"},
+		{"Feb 2023", "2023-02-01", "2023-02-28"},
+		{"March 5th", "2024-03-05", "2024-03-05"},
+		{"Dec 24", "2023-12-24", "2023-12-24"},
+		{"March 5, 2022", "2022-03-05", "2022-03-05"},
+	}
+	for _, tt := range tests {
+		span, err := parseDateExpression(tt.expr, now)
+		if err != nil {
+			t.Errorf("parseDateExpression(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if dayKey(span.First) != tt.start || dayKey(span.Last) != tt.end {
+			t.Errorf("parseDateExpression(%q) = %s to %s, want %s to %s", tt.expr, dayKey(span.First), dayKey(span.Last), tt.start, tt.end)
+		}
+	}
+
+	for _, expr := range []string{"someday", "February 30", "2024-13-01", ""} {
+		if _, err := parseDateExpression(expr, now); err == nil {
+			t.Errorf("Expected an error for %q", expr)
+		}
+	}
+}
+
+func TestResolveDateRange(t *testing.T) {
+	now := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		input      DateRangeInput
+		start, end string
+	}{
+		{"literal range", DateRangeInput{StartDate: "2024-05-01", EndDate: "2024-05-07"}, "2024-05-01", "2024-05-07"},
+		{"open-ended date runs through today", DateRangeInput{StartDate: "2024-05-01"}, "2024-05-01", "2024-05-15"},
+		{"open-ended expression date", DateRangeInput{StartDate: "3 days ago"}, "2024-05-12", "2024-05-15"},
+		{"named day covers itself", DateRangeInput{StartDate: "yesterday"}, "2024-05-14", "2024-05-14"},
+		{"range expression covers itself", DateRangeInput{StartDate: "last month"}, "2024-04-01", "2024-04-30"},
+		{"start of one expression to end of another", DateRangeInput{StartDate: "March", EndDate: "last week"}, "2024-03-01", "2024-05-12"},
+		{"start plus days", DateRangeInput{StartDate: "2024-04-01", Days: 14}, "2024-04-01", "2024-04-14"},
+		{"days past today are clamped", DateRangeInput{StartDate: "2024-05-10", Days: 30}, "2024-05-10", "2024-05-15"},
+		{"current month ends today", DateRangeInput{StartDate: "May"}, "2024-05-01", "2024-05-15"},
+		{"past days cover as many days as the days argument", DateRangeInput{StartDate: "past 30 days"}, "2024-04-16", "2024-05-15"},
+	}
+	for _, tt := range tests {
+		start, end, err := resolveDateRange(tt.input, now)
+		if err != nil {
+			t.Errorf("%s: resolveDateRange() error = %v", tt.name, err)
+			continue
+		}
+		if dayKey(start) != tt.start || dayKey(end) != tt.end {
+			t.Errorf("%s: got %s to %s, want %s to %s", tt.name, dayKey(start), dayKey(end), tt.start, tt.end)
+		}
+	}
+
+	errors := []struct {
+		input DateRangeInput
+		want  string
+	}{
+		{DateRangeInput{StartDate: "2024-05-01", EndDate: "whenever"}, "invalid end_date"},
+		{DateRangeInput{StartDate: "2024-06-01"}, "start_date 2024-06-01 is in the future (today is 2024-05-15 in UTC)"},
+		{DateRangeInput{StartDate: "2024-05-10", EndDate: "2024-05-01"}, "end_date 2024-05-01 is before start_date 2024-05-10"},
+		{DateRangeInput{StartDate: "2024-05-01", EndDate: "2024-05-07", Days: 7}, "either end_date or days"},
+		{DateRangeInput{StartDate: "2024-05-01", Days: -1}, "days must be positive"},
+	}
+	for _, tt := range errors {
+		if _, _, err := resolveDateRange(tt.input, now); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("resolveDateRange(%+v) error = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestResolveRecentRange(t *testing.T) {
+	now := time.Date(2024, 5, 15, 22, 30, 0, 0, time.FixedZone("EDT", -4*60*60))
+
+	tests := []struct {
+		name       string
+		input      DateRangeInput
+		start, end string
+	}{
+		{"default days through today", DateRangeInput{}, "2024-04-16", "2024-05-15"},
+		{"days through today", DateRangeInput{Days: 7}, "2024-05-09", "2024-05-15"},
+		{"days ending at end_date", DateRangeInput{Days: 7, EndDate: "last week"}, "2024-05-06", "2024-05-12"},
+		{"end_date past today is clamped", DateRangeInput{Days: 2, EndDate: "2024-06-01"}, "2024-05-14", "2024-05-15"},
+		{"start_date resolves like other tools", DateRangeInput{StartDate: "last month"}, "2024-04-01", "2024-04-30"},
+	}
+	for _, tt := range tests {
+		first, last, err := resolveRecentRange(tt.input, 30, now)
+		if err != nil {
+			t.Errorf("%s: resolveRecentRange() error = %v", tt.name, err)
+			continue
+		}
+		if dayKey(first) != tt.start || dayKey(last) != tt.end {
+			t.Errorf("%s: got %s to %s, want %s to %s", tt.name, dayKey(first), dayKey(last), tt.start, tt.end)
+		}
+		if start, end := localDayBounds(first, last); rangeDays(start, end) != rangeDays(first, last) || end.Hour() != 23 {
+			t.Errorf("%s: local bounds %s to %s", tt.name, start, end)
+		}
+	}
+
+	if _, _, err := resolveRecentRange(DateRangeInput{StartDate: "2024-06-01"}, 30, now); err == nil {
+		t.Error("expected a future start_date to fail")
+	}
+	if _, _, err := resolveRecentRange(DateRangeInput{Days: -3}, 30, now); err == nil {
+		t.Error("expected negative days to fail")
+	}
+}
+
+func TestDescribeResolvedDates(t *testing.T) {
+	now := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+
+	if got := describeResolvedDates("get_health_summary", json.RawMessage(`{"start_date":"2024-05-01","end_date":"2024-05-07"}`), now); got != "" {
+		t.Errorf("Expected no echo for literal dates, got %q", got)
+	}
+
+	got := describeResolvedDates("compare_periods", json.RawMessage(`{"period_a_start":"last month","period_b_start":"2024-05-01","period_b_end":"today"}`), now)
+	want := "_Resolved \"last month\" as 2024-04-01 to 2024-04-30 (UTC)_\n_Resolved \"2024-05-01\" to \"today\" as 2024-05-01 to 2024-05-15 (UTC)_"
+	if got != want {
+		t.Errorf("describeResolvedDates() =\n%s\nwant\n%s", got, want)
+	}
+
+	got = describeResolvedDates("get_health_summary", json.RawMessage(`{"start_date":"2024-05-01","days":7}`), now)
+	want = "_Resolved \"2024-05-01\" + 7 days as 2024-05-01 to 2024-05-07 (UTC)_"
+	if got != want {
+		t.Errorf("describeResolvedDates() =\n%s\nwant\n%s", got, want)
+	}
+
+	got = describeResolvedDates("analyze_hrv", json.RawMessage(`{"days": 14}`), now)
+	want = "_Resolved 14 days through today as 2024-05-02 to 2024-05-15 (UTC)_"
+	if got != want {
+		t.Errorf("describeResolvedDates() =\n%s\nwant\n%s", got, want)
+	}
+	got = describeResolvedDates("analyze_hrv", json.RawMessage(`{}`), now)
+	want = "_Resolved 60 days through today as 2024-03-17 to 2024-05-15 (UTC)_"
+	if got != want {
+		t.Errorf("describeResolvedDates() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,244 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"whoop-mcp/internal/config"
+)
+
+// Doctor check outcomes
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+const (
+	// clockSkewWarn and clockSkewFail bound the difference between the local
+	// clock and Whoop's; token expiry and date ranges assume they agree
+	clockSkewWarn = 30 * time.Second
+	clockSkewFail = 5 * time.Minute
+)
+
+// doctorEndpoints are pinged with a single-record request each
+var doctorEndpoints = []struct {
+	name     string
+	endpoint string
+}{
+	{"profile", "/v2/user/profile/basic"},
+	{"recovery", "/v2/recovery"},
+	{"sleep", "/v2/activity/sleep"},
+	{"workout", "/v2/activity/workout"},
+	{"cycle", "/v2/cycle"},
+}
+
+// DoctorCheck is one line of the doctor report
+type DoctorCheck struct {
+	Name   string
+	Status string
+	Detail string
+}
+
+// DoctorReport collects check outcomes in the order they ran
+type DoctorReport struct {
+	Checks []DoctorCheck
+}
+
+func (r *DoctorReport) add(name, status, detail string) {
+	r.Checks = append(r.Checks, DoctorCheck{Name: name, Status: status, Detail: detail})
+}
+
+// count returns how many checks ended with status
+func (r *DoctorReport) count(status string) int {
+	n := 0
+	for _, check := range r.Checks {
+		if check.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// FormatDoctorReport renders the report as aligned pass/warn/fail lines
+func FormatDoctorReport(r *DoctorReport) string {
+	width := 0
+	for _, check := range r.Checks {
+		width = max(width, len(check.Name))
+	}
+	icons := map[string]string{doctorPass: "✅ PASS", doctorWarn: "⚠️ WARN", doctorFail: "❌ FAIL"}
+
+	var builder strings.Builder
+	builder.WriteString("Whoop MCP Server diagnostics\n\n")
+	for _, check := range r.Checks {
+		builder.WriteString(fmt.Sprintf("%s  %-*s  %s\n", icons[check.Status], width, check.Name, check.Detail))
+	}
+	builder.WriteString(fmt.Sprintf("\n%d passed, %d warning(s), %d failed\n", r.count(doctorPass), r.count(doctorWarn), r.count(doctorFail)))
+	return builder.String()
+}
+
+// runDoctorCommand implements `whoop-mcp-server doctor`: it checks the
+// configuration, credentials, clock, every Whoop endpoint, token scopes, and
+// stdio hygiene, and prints a pass/fail report. It fails when any check fails.
+func runDoctorCommand(settings Settings, args []string) error {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	report := runDoctorChecks(settings)
+	fmt.Print(FormatDoctorReport(report))
+	if failed := report.count(doctorFail); failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// runDoctorChecks runs every check against the resolved configuration
+func runDoctorChecks(settings Settings) *DoctorReport {
+	report := &DoctorReport{}
+
+	configFile := "none; using defaults and the environment"
+	if path, _, err := config.Path(); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			configFile = path
+		}
+	}
+	report.add("config file", doctorPass, configFile)
+
+	// Building the server validates every setting and loads the tokens
+	server, err := NewMCPServer(settings)
+	if err != nil {
+		report.add("configuration", doctorFail, err.Error())
+		return report
+	}
+	// Diagnostics exercise the live client's transport and token handling
+	client, ok := server.whoopClient.(*WhoopClient)
+	if !ok {
+		report.add("configuration", doctorFail, "the server is not connected to the Whoop API")
+		return report
+	}
+	report.add("configuration", doctorPass, fmt.Sprintf("settings valid; API %s, token store %s", client.baseURL, client.tokenStore.Name()))
+
+	if client.CanRefreshToken() {
+		report.add("token refresh", doctorPass, "refresh token and client credentials configured")
+	} else {
+		report.add("token refresh", doctorWarn, "no refresh token or client credentials; the access token cannot be renewed when it expires")
+	}
+
+	skew, err := checkClockSkew(client.client, client.baseURL, time.Now())
+	switch {
+	case err != nil:
+		report.add("clock", doctorWarn, err.Error())
+	case skew.Abs() > clockSkewFail:
+		report.add("clock", doctorFail, fmt.Sprintf("local clock is off by %s; fix the system time", skew.Round(time.Second)))
+	case skew.Abs() > clockSkewWarn:
+		report.add("clock", doctorWarn, fmt.Sprintf("local clock is off by %s", skew.Round(time.Second)))
+	default:
+		report.add("clock", doctorPass, fmt.Sprintf("within %s of Whoop", clockSkewWarn))
+	}
+
+	params := url.Values{}
+	params.Set("limit", "1")
+	for _, ping := range doctorEndpoints {
+		started := time.Now()
+		_, err := client.makeRequest(context.Background(), ping.endpoint, params, nil)
+		if err != nil {
+			report.add("endpoint "+ping.name, doctorFail, err.Error())
+			continue
+		}
+		report.add("endpoint "+ping.name, doctorPass, fmt.Sprintf("%s in %s", ping.endpoint, time.Since(started).Round(time.Millisecond)))
+	}
+
+	// The pings recorded which scopes the token holds
+	status := client.scopes.Status()
+	switch {
+	case len(status.Missing) > 0:
+		report.add("scopes", doctorFail, fmt.Sprintf("missing %s; run `whoop-mcp-server auth` again", strings.Join(status.Missing, ", ")))
+	case len(status.Unused) > 0:
+		report.add("scopes", doctorWarn, fmt.Sprintf("token also grants %s, which this server does not use", strings.Join(status.Unused, ", ")))
+	default:
+		report.add("scopes", doctorPass, fmt.Sprintf("granted %s", strings.Join(status.Granted, ", ")))
+	}
+
+	if err := checkStdioSeparation(server); err != nil {
+		report.add("stdio", doctorFail, err.Error())
+	} else {
+		report.add("stdio", doctorPass, "stdout carries only JSON-RPC; logs go to stderr")
+	}
+	return report
+}
+
+// checkClockSkew compares the local clock with the Date header of a Whoop
+// response; a positive skew means the local clock is ahead
+func checkClockSkew(client *http.Client, baseURL string, now time.Time) (time.Duration, error) {
+	resp, err := client.Head(baseURL)
+	if err != nil {
+		return 0, fmt.Errorf("could not reach %s to compare clocks: %w", baseURL, err)
+	}
+	resp.Body.Close()
+
+	remote, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, fmt.Errorf("Whoop response had no usable Date header")
+	}
+	// Date has one-second resolution
+	return now.Sub(remote).Truncate(time.Second), nil
+}
+
+// checkStdioSeparation sends protocol requests that need no API access through
+// the server with stdout captured, and verifies every line written is a
+// JSON-RPC message. Anything else would corrupt the MCP stream.
+func checkStdioSeparation(server *MCPServer) error {
+	if log.Writer() == os.Stdout {
+		return fmt.Errorf("logs are written to stdout, which carries the MCP protocol")
+	}
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to capture stdout: %w", err)
+	}
+	captured := make(chan []byte, 1)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		captured <- data
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = writer
+	methods := []string{"tools/list", "resources/list", "doctor/ping"}
+	for i, method := range methods {
+		if response := server.handleRequest(context.Background(), &MCPRequest{JSONRPC: "2.0", ID: i + 1, Method: method}); response != nil {
+			server.writeMessage(os.Stdout, response)
+		}
+	}
+	os.Stdout = stdout
+	writer.Close()
+	data := <-captured
+	reader.Close()
+
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	if len(lines) != len(methods) {
+		return fmt.Errorf("expected %d JSON-RPC responses on stdout, got %d line(s)", len(methods), len(lines))
+	}
+	for i, line := range lines {
+		var response MCPResponse
+		if err := json.Unmarshal(line, &response); err != nil || response.JSONRPC != "2.0" {
+			return fmt.Errorf("stdout line %d is not JSON-RPC: %.80q", i+1, line)
+		}
+		if fmt.Sprint(response.ID) != fmt.Sprint(i+1) {
+			return fmt.Errorf("stdout line %d answers request %v, expected %d", i+1, response.ID, i+1)
+		}
+	}
+	return nil
+}
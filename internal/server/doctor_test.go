@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckClockSkew(t *testing.T) {
+	remote := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	api := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Date", remote.Format(http.TimeFormat))
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	defer api.Close()
+
+	skew, err := checkClockSkew(api.Client(), api.URL, remote.Add(90*time.Second+400*time.Millisecond))
+	if err != nil {
+		t.Fatalf("checkClockSkew() error = %v", err)
+	}
+	if skew != 90*time.Second {
+		t.Errorf("checkClockSkew() = %s, want 1m30s", skew)
+	}
+}
+
+func TestCheckStdioSeparation(t *testing.T) {
+	server := &MCPServer{tools: defineMCPTools(), whoopClient: &WhoopClient{}}
+	if err := checkStdioSeparation(server); err != nil {
+		t.Errorf("checkStdioSeparation() error = %v", err)
+	}
+}
+
+func TestFormatDoctorReport(t *testing.T) {
+	report := &DoctorReport{}
+	report.add("config file", doctorPass, "none")
+	report.add("token refresh", doctorWarn, "no refresh token")
+	report.add("endpoint sleep", doctorFail, "missing scope read:sleep")
+
+	output := FormatDoctorReport(report)
+	for _, want := range []string{"✅ PASS  config file     none", "❌ FAIL  endpoint sleep  missing scope", "1 passed, 1 warning(s), 1 failed"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, output)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// dotEnvPath returns the dotenv file to load at startup: WHOOP_ENV_FILE from
+// the process environment, or .env in the working directory. It is the same
+// file the env token store writes refreshed tokens to. explicit reports
+// whether the path was configured rather than defaulted.
+func dotEnvPath() (path string, explicit bool) {
+	if path = strings.TrimSpace(os.Getenv("WHOOP_ENV_FILE")); path != "" {
+		return path, true
+	}
+	return ".env", false
+}
+
+// loadDotEnv copies the entries of a dotenv file into the process
+// environment. Variables already set in the process win, so an exported value
+// always overrides the file. A missing file is not an error, since the first
+// `auth` run creates it. It returns the keys it set.
+func loadDotEnv(path string) ([]string, error) {
+	values, err := readEnvFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+
+	var loaded []string
+	for key, value := range values {
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return loaded, fmt.Errorf("failed to set %s from %s: %w", key, path, err)
+		}
+		loaded = append(loaded, key)
+	}
+	return loaded, nil
+}
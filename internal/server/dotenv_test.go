@@ -0,0 +1,84 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadDotEnv_ProcessEnvironmentWins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	content := "# settings\nWHOOP_TEST_EXPORTED=from-file\nexport WHOOP_TEST_FILE_ONLY=\"quoted value\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write env file: %v", err)
+	}
+	t.Setenv("WHOOP_TEST_EXPORTED", "from-process")
+	t.Setenv("WHOOP_TEST_FILE_ONLY", "")
+	os.Unsetenv("WHOOP_TEST_FILE_ONLY")
+
+	loaded, err := loadDotEnv(path)
+	if err != nil {
+		t.Fatalf("loadDotEnv() error = %v", err)
+	}
+	if len(loaded) != 1 || loaded[0] != "WHOOP_TEST_FILE_ONLY" {
+		t.Errorf("loadDotEnv() loaded %v, want only WHOOP_TEST_FILE_ONLY", loaded)
+	}
+	if got := os.Getenv("WHOOP_TEST_EXPORTED"); got != "from-process" {
+		t.Errorf("Expected the exported value to win, got %q", got)
+	}
+	if got := os.Getenv("WHOOP_TEST_FILE_ONLY"); got != "quoted value" {
+		t.Errorf("Expected the file value to fill in, got %q", got)
+	}
+}
+
+func TestLoadDotEnv_MissingFile(t *testing.T) {
+	loaded, err := loadDotEnv(filepath.Join(t.TempDir(), ".env"))
+	if err != nil || loaded != nil {
+		t.Errorf("loadDotEnv() = %v, %v; want nothing loaded and no error", loaded, err)
+	}
+}
+
+func TestDotEnvPath(t *testing.T) {
+	t.Setenv("WHOOP_ENV_FILE", "")
+	if path, explicit := dotEnvPath(); path != ".env" || explicit {
+		t.Errorf("dotEnvPath() = %q, %v; want .env, false", path, explicit)
+	}
+	t.Setenv("WHOOP_ENV_FILE", "/etc/whoop.env")
+	if path, explicit := dotEnvPath(); path != "/etc/whoop.env" || !explicit {
+		t.Errorf("dotEnvPath() = %q, %v; want /etc/whoop.env, true", path, explicit)
+	}
+}
+
+func TestEnvFileTokenStore_SaveKeepsAccessTokenName(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "whoop.env")
+	original := "WHOOP_ACCESS_TOKEN=old\nWHOOP_REFRESH_TOKEN=old-refresh\nOTHER=kept\n"
+	if err := os.WriteFile(target, []byte(original), 0600); err != nil {
+		t.Fatalf("failed to write env file: %v", err)
+	}
+	link := filepath.Join(dir, ".env")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	store := &EnvFileTokenStore{Path: link}
+	if err := store.Save(TokenSet{AccessToken: "new", RefreshToken: "new-refresh"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Expected %s to stay a symlink", link)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("failed to read env file: %v", err)
+	}
+	want := "WHOOP_ACCESS_TOKEN=new\nWHOOP_REFRESH_TOKEN=new-refresh\nOTHER=kept\n"
+	if string(data) != want {
+		t.Errorf("env file =\n%s\nwant\n%s", data, want)
+	}
+	if strings.Contains(string(data), "WHOOP_API_KEY") {
+		t.Error("Expected no WHOOP_API_KEY alongside WHOOP_ACCESS_TOKEN")
+	}
+}
@@ -0,0 +1,383 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"whoop-mcp/internal/mcp"
+)
+
+// e2eServerEnv makes the test binary run Main() instead of the tests, so the
+// harness can spawn a real server process without building one
+const e2eServerEnv = "WHOOP_E2E_SERVER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(e2eServerEnv) == "1" {
+		Main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// rpcMessage is one line the server wrote to stdout
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *MCPError       `json:"error"`
+}
+
+// mcpHarness drives a server process over JSON-RPC on its stdin and stdout
+type mcpHarness struct {
+	t      *testing.T
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	lines  chan []byte
+	stderr *lockedBuffer
+	nextID int
+}
+
+// lockedBuffer collects the server's log output while it runs
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// startMCPHarness spawns an offline server in a scratch home directory; env
+// adds or overrides settings
+func startMCPHarness(t *testing.T, env ...string) *mcpHarness {
+	t.Helper()
+	home := t.TempDir()
+	cmd := exec.Command(os.Args[0])
+	cmd.Dir = home
+	cmd.Env = append([]string{
+		e2eServerEnv + "=1",
+		"HOME=" + home,
+		"WHOOP_DATA_DIR=" + filepath.Join(home, "data"),
+		"WHOOP_OFFLINE=1",
+		"WHOOP_RATE_LIMIT=1000",
+	}, env...)
+
+	h := &mcpHarness{t: t, cmd: cmd, lines: make(chan []byte, 128), stderr: &lockedBuffer{}}
+	cmd.Stderr = h.stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatalf("StdinPipe() error = %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("StdoutPipe() error = %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	h.stdin = stdin
+
+	go func() {
+		defer close(h.lines)
+		scanner := mcp.NewScanner(stdout)
+		for scanner.Scan() {
+			h.lines <- append([]byte(nil), scanner.Bytes()...)
+		}
+	}()
+
+	t.Cleanup(func() {
+		stdin.Close()
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			cmd.Process.Kill()
+			<-done
+		}
+		if t.Failed() {
+			t.Logf("server log:\n%s", h.stderr.String())
+		}
+	})
+	return h
+}
+
+// send writes raw lines to the server as-is
+func (h *mcpHarness) send(lines ...string) {
+	h.t.Helper()
+	if _, err := io.WriteString(h.stdin, strings.Join(lines, "\n")+"\n"); err != nil {
+		h.t.Fatalf("failed to write to server: %v", err)
+	}
+}
+
+// request builds a JSON-RPC request line with the next numeric ID
+func (h *mcpHarness) request(method string, params interface{}) (int, string) {
+	h.t.Helper()
+	h.nextID++
+	line, err := json.Marshal(MCPRequest{JSONRPC: "2.0", ID: h.nextID, Method: method, Params: mustMarshal(h.t, params)})
+	if err != nil {
+		h.t.Fatalf("failed to encode %s: %v", method, err)
+	}
+	return h.nextID, string(line)
+}
+
+// read waits for the next message and checks its framing: one JSON-RPC 2.0
+// object per line carrying exactly one of result or error
+func (h *mcpHarness) read() rpcMessage {
+	h.t.Helper()
+	select {
+	case line, ok := <-h.lines:
+		if !ok {
+			h.t.Fatalf("server closed stdout")
+		}
+		var message rpcMessage
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&message); err != nil || decoder.More() {
+			h.t.Fatalf("server wrote a line that is not one JSON-RPC message (%v): %s", err, line)
+		}
+		if message.JSONRPC != "2.0" {
+			h.t.Errorf("Expected jsonrpc 2.0, got %q in %s", message.JSONRPC, line)
+		}
+		if (message.Result == nil) == (message.Error == nil) {
+			h.t.Errorf("Expected exactly one of result and error in %s", line)
+		}
+		return message
+	case <-time.After(30 * time.Second):
+		h.t.Fatalf("timed out waiting for the server; log:\n%s", h.stderr.String())
+	}
+	return rpcMessage{}
+}
+
+// call sends one request and reads its response
+func (h *mcpHarness) call(method string, params interface{}) rpcMessage {
+	h.t.Helper()
+	id, line := h.request(method, params)
+	h.send(line)
+	response := h.read()
+	if string(response.ID) != fmt.Sprint(id) {
+		h.t.Fatalf("%s: response ID = %s, want %d", method, response.ID, id)
+	}
+	return response
+}
+
+// initialize completes the handshake
+func (h *mcpHarness) initialize() {
+	h.t.Helper()
+	response := h.call("initialize", map[string]interface{}{
+		"protocolVersion": "2024-11-05",
+		"clientInfo":      map[string]string{"name": "e2e", "version": "1"},
+	})
+	if response.Error != nil {
+		h.t.Fatalf("initialize error = %+v", response.Error)
+	}
+	h.send(`{"jsonrpc": "2.0", "method": "notifications/initialized"}`)
+}
+
+func mustMarshal(t *testing.T, v interface{}) json.RawMessage {
+	t.Helper()
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	return data
+}
+
+// toolText returns the text of a tools/call or resources/read result
+func toolText(t *testing.T, response rpcMessage) string {
+	t.Helper()
+	if response.Error != nil {
+		t.Fatalf("unexpected error %+v", response.Error)
+	}
+	var result struct {
+		Content  []struct{ Text string } `json:"content"`
+		Contents []struct{ Text string } `json:"contents"`
+		IsError  bool                    `json:"isError"`
+	}
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	var texts []string
+	for _, item := range append(result.Content, result.Contents...) {
+		texts = append(texts, item.Text)
+	}
+	if result.IsError {
+		t.Errorf("Expected success, got isError: %s", strings.Join(texts, "\n"))
+	}
+	return strings.Join(texts, "\n")
+}
+
+func TestE2E_SessionFlow(t *testing.T) {
+	h := startMCPHarness(t)
+
+	if response := h.call("tools/list", nil); response.Error == nil || response.Error.Code != -32002 {
+		t.Errorf("tools/list before initialize = %+v, want -32002", response.Error)
+	}
+
+	h.initialize()
+
+	var tools struct{ Tools []MCPTool }
+	if err := json.Unmarshal(h.call("tools/list", nil).Result, &tools); err != nil {
+		t.Fatalf("failed to decode tools/list: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, tool := range tools.Tools {
+		names[tool.Name] = true
+		if tool.InputSchema.Type != "object" {
+			t.Errorf("Tool %s has input schema type %q, want object", tool.Name, tool.InputSchema.Type)
+		}
+	}
+	for _, want := range []string{"get_health_summary", "server_status"} {
+		if !names[want] {
+			t.Errorf("Expected tools/list to include %s", want)
+		}
+	}
+
+	summary := toolText(t, h.call("tools/call", map[string]interface{}{
+		"name":      "get_health_summary",
+		"arguments": map[string]string{"start_date": "past 14 days", "end_date": "today"},
+	}))
+	if !strings.Contains(summary, "Health Summary") {
+		t.Errorf("Expected a health summary, got:\n%s", summary)
+	}
+
+	var resources struct{ Resources []MCPResource }
+	if err := json.Unmarshal(h.call("resources/list", nil).Result, &resources); err != nil || len(resources.Resources) == 0 {
+		t.Fatalf("resources/list = %+v, %v", resources, err)
+	}
+	profile := toolText(t, h.call("resources/read", map[string]string{"uri": "whoop://user/profile"}))
+	if !strings.Contains(profile, "Demo") {
+		t.Errorf("Expected the fixture profile, got:\n%s", profile)
+	}
+}
+
+func TestE2E_ErrorCodes(t *testing.T) {
+	h := startMCPHarness(t)
+	h.initialize()
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		params interface{}
+		code   int
+	}{
+		{"unknown method", "tools/explode", nil, -32601},
+		{"params of the wrong shape", "tools/call", []int{1}, -32602},
+		{"unknown tool", "tools/call", map[string]string{"name": "no_such_tool"}, -32603},
+		{"unknown resource", "resources/read", map[string]string{"uri": "whoop://nowhere"}, -32603},
+	} {
+		response := h.call(tc.method, tc.params)
+		if response.Error == nil || response.Error.Code != tc.code {
+			t.Errorf("%s: error = %+v, want code %d", tc.name, response.Error, tc.code)
+		}
+	}
+}
+
+func TestE2E_Framing(t *testing.T) {
+	h := startMCPHarness(t)
+	h.initialize()
+
+	// Parse errors and invalid requests are answered with a null ID
+	h.send(`{"jsonrpc": "2.0", "id": 1, "method": `)
+	if response := h.read(); string(response.ID) != "null" || response.Error == nil || response.Error.Code != -32700 {
+		t.Errorf("Malformed JSON: got ID %s, error %+v; want null and -32700", response.ID, response.Error)
+	}
+	h.send(`{"id": "no-version", "method": "tools/list"}`)
+	if response := h.read(); string(response.ID) != `"no-version"` || response.Error == nil || response.Error.Code != -32600 {
+		t.Errorf("Missing jsonrpc: got ID %s, error %+v; want -32600", response.ID, response.Error)
+	}
+
+	// Notifications and blank lines get no response, so the next message
+	// answers the next request
+	h.send(`{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": {"requestId": 9}}`, "", "   ")
+	if response := h.call("tools/list", nil); response.Error != nil {
+		t.Errorf("tools/list after a notification error = %+v", response.Error)
+	}
+
+	// String IDs are echoed verbatim
+	h.send(`{"jsonrpc": "2.0", "id": "req-7", "method": "resources/list"}`)
+	if response := h.read(); string(response.ID) != `"req-7"` {
+		t.Errorf("Expected the string ID back, got %s", response.ID)
+	}
+
+	// Messages larger than the default scanner buffer still arrive whole
+	note := strings.Repeat("x", 512*1024)
+	response := h.call("tools/call", map[string]interface{}{
+		"name":      "no_such_tool",
+		"arguments": map[string]string{"note": note},
+	})
+	if response.Error == nil || response.Error.Code != -32603 {
+		t.Errorf("Large request: error = %+v, want the unknown tool error", response.Error)
+	}
+}
+
+func TestE2E_PipelinedRequests(t *testing.T) {
+	h := startMCPHarness(t)
+	h.initialize()
+
+	// Write every request before reading any response, as clients that
+	// pipeline do; each is answered exactly once, in order
+	var ids []int
+	var batch []string
+	for i := 0; i < 40; i++ {
+		method, params := "tools/list", interface{}(nil)
+		switch i % 3 {
+		case 1:
+			method, params = "resources/read", map[string]string{"uri": "whoop://sports"}
+		case 2:
+			method, params = "tools/call", map[string]interface{}{"name": "server_status", "arguments": map[string]string{}}
+		}
+		id, line := h.request(method, params)
+		ids = append(ids, id)
+		batch = append(batch, line)
+	}
+	h.send(batch...)
+
+	for _, id := range ids {
+		response := h.read()
+		if string(response.ID) != fmt.Sprint(id) {
+			t.Fatalf("Expected the response to request %d, got ID %s", id, response.ID)
+		}
+		if response.Error != nil {
+			t.Errorf("Request %d error = %+v", id, response.Error)
+		}
+	}
+}
+
+func TestE2E_ShutdownOnEOF(t *testing.T) {
+	h := startMCPHarness(t)
+	h.initialize()
+	h.stdin.Close()
+
+	select {
+	case _, ok := <-h.lines:
+		if ok {
+			t.Error("Expected no output after stdin closed")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected the server to exit when stdin closes")
+	}
+	if err := h.cmd.Wait(); err != nil {
+		t.Errorf("Expected a clean exit, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package server
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// encryptedFileMagic starts every encrypted datastore file; files without
+	// it are read as plaintext so an existing datastore keeps working
+	encryptedFileMagic = "WHOOPENC1"
+	encryptionSaltSize = 16
+	// passphraseIterations is the PBKDF2-HMAC-SHA256 work factor (OWASP 2023)
+	passphraseIterations = 600000
+	// derivedKeyCacheSize caps the keys kept for files sealed under earlier
+	// runs' salts
+	derivedKeyCacheSize = 8
+	// keyringDataKeyUser holds the datastore key next to the tokens in the keychain
+	keyringDataKeyUser = "datastore-key"
+)
+
+// DataCipher seals datastore files with AES-256-GCM. The key comes from the
+// OS keychain, or is derived from a passphrase and a salt. Deriving a key is
+// deliberately slow, so a passphrase cipher derives one for a salt it picks
+// at startup and seals every file with it, recording the salt in each file's
+// header. Only reading a file sealed under another salt, by an earlier run,
+// derives another key.
+type DataCipher struct {
+	key        []byte // keychain key, or the passphrase key for salt
+	salt       []byte // written to every file; zero in keychain mode
+	passphrase string // empty in keychain mode
+
+	mu      sync.Mutex
+	derived map[string][]byte // keys for other salts, at most derivedKeyCacheSize
+}
+
+// newPassphraseCipher derives the key for a fresh salt from passphrase
+func newPassphraseCipher(passphrase string) (*DataCipher, error) {
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate datastore salt: %w", err)
+	}
+	return &DataCipher{
+		key:        deriveKey(passphrase, salt),
+		salt:       salt,
+		passphrase: passphrase,
+		derived:    make(map[string][]byte),
+	}, nil
+}
+
+// NewDataCipherFromEnv reads WHOOP_DATA_ENCRYPTION: off (default), keyring
+// (a random key created in the OS keychain on first use), or passphrase (a
+// key derived from WHOOP_DATA_PASSPHRASE). It returns nil when off.
+func NewDataCipherFromEnv(settings Settings) (*DataCipher, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(settings("WHOOP_DATA_ENCRYPTION"))); mode {
+	case "", "off":
+		return nil, nil
+	case "keyring", "keychain":
+		key, err := keyringDataKey()
+		if err != nil {
+			return nil, err
+		}
+		return &DataCipher{key: key}, nil
+	case "passphrase":
+		passphrase := settings("WHOOP_DATA_PASSPHRASE")
+		if passphrase == "" {
+			return nil, fmt.Errorf("WHOOP_DATA_ENCRYPTION=passphrase requires WHOOP_DATA_PASSPHRASE")
+		}
+		return newPassphraseCipher(passphrase)
+	default:
+		return nil, fmt.Errorf("unknown WHOOP_DATA_ENCRYPTION %q (expected off, keyring, or passphrase)", mode)
+	}
+}
+
+// keyringDataKey loads the datastore key from the OS keychain, creating it on first use
+func keyringDataKey() ([]byte, error) {
+	secret, err := keyring.Get(keyringService, keyringDataKeyUser)
+	if err == nil {
+		key, err := base64.StdEncoding.DecodeString(secret)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("datastore key in keychain is malformed")
+		}
+		return key, nil
+	}
+	if !errors.Is(err, keyring.ErrNotFound) {
+		return nil, fmt.Errorf("failed to read datastore key from keychain: %w", err)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate datastore key: %w", err)
+	}
+	if err := keyring.Set(keyringService, keyringDataKeyUser, base64.StdEncoding.EncodeToString(key)); err != nil {
+		return nil, fmt.Errorf("failed to store datastore key in keychain: %w", err)
+	}
+	return key, nil
+}
+
+// keyFor returns the AES key for a file's salt
+func (c *DataCipher) keyFor(salt []byte) []byte {
+	if c.passphrase == "" || bytes.Equal(salt, c.salt) {
+		return c.key
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key, ok := c.derived[string(salt)]; ok {
+		return key
+	}
+	if len(c.derived) >= derivedKeyCacheSize {
+		for old := range c.derived {
+			delete(c.derived, old)
+			break
+		}
+	}
+	key := deriveKey(c.passphrase, salt)
+	c.derived[string(salt)] = key
+	return key
+}
+
+// deriveKey derives the AES key for a passphrase and salt
+func deriveKey(passphrase string, salt []byte) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt, passphraseIterations, 32, sha256.New)
+}
+
+// Seal encrypts plaintext into the encrypted file layout:
+// magic | salt | nonce | ciphertext and tag. Each call uses a fresh nonce.
+func (c *DataCipher) Seal(plaintext []byte) ([]byte, error) {
+	return c.sealWithSalt(c.salt, plaintext)
+}
+
+// sealWithSalt is Seal under the key for salt, for files such as the audit
+// log whose sealed parts share the salt of the file's first one
+func (c *DataCipher) sealWithSalt(salt, plaintext []byte) ([]byte, error) {
+	if salt == nil {
+		salt = make([]byte, encryptionSaltSize) // unused with a keychain key
+	}
+	aead, err := newGCM(c.keyFor(salt))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte(encryptedFileMagic), salt...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, []byte(encryptedFileMagic)), nil
+}
+
+// Open decrypts a file written by Seal
+func (c *DataCipher) Open(data []byte) ([]byte, error) {
+	header := len(encryptedFileMagic) + encryptionSaltSize
+	if !isEncrypted(data) || len(data) < header {
+		return nil, fmt.Errorf("not an encrypted datastore file")
+	}
+	salt := data[len(encryptedFileMagic):header]
+	aead, err := newGCM(c.keyFor(salt))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < header+aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted datastore file is truncated")
+	}
+	nonce, ciphertext := data[header:header+aead.NonceSize()], data[header+aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(encryptedFileMagic))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt (wrong key or passphrase?)")
+	}
+	return plaintext, nil
+}
+
+// isEncrypted reports whether data was written by DataCipher.Seal
+func isEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(encryptedFileMagic))
+}
+
+// sealDatastore encrypts data for the datastore with c; a nil c stores
+// plaintext
+func sealDatastore(c *DataCipher, data []byte) ([]byte, error) {
+	if c == nil {
+		return data, nil
+	}
+	return c.Seal(data)
+}
+
+// openDatastore decrypts datastore content, passing plaintext files through
+func openDatastore(c *DataCipher, data []byte) ([]byte, error) {
+	if !isEncrypted(data) {
+		return data, nil
+	}
+	if c == nil {
+		return nil, fmt.Errorf("file is encrypted; set WHOOP_DATA_ENCRYPTION to read it")
+	}
+	return c.Open(data)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
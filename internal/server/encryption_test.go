@@ -0,0 +1,164 @@
+package server
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDatastoreEncryption(t *testing.T) {
+	dir := t.TempDir()
+	plainPath := filepath.Join(dir, "goals.json")
+	if err := writeJSONFile(nil, plainPath, map[string]string{"note": "existing plaintext"}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("WHOOP_DATA_ENCRYPTION", "passphrase")
+	t.Setenv("WHOOP_DATA_PASSPHRASE", "correct horse battery staple")
+	cipher, err := NewDataCipherFromEnv(os.Getenv)
+	if err != nil {
+		t.Fatalf("NewDataCipherFromEnv() error: %v", err)
+	}
+
+	// Plaintext written before encryption was turned on still reads
+	var existing map[string]string
+	if err := readJSONFile(cipher, plainPath, &existing); err != nil || existing["note"] != "existing plaintext" {
+		t.Errorf("reading plaintext file = %v, %v", existing, err)
+	}
+
+	path := filepath.Join(dir, "baselines.json")
+	if err := writeJSONFile(cipher, path, map[string]float64{"hrv": 61.5}); err != nil {
+		t.Fatalf("writeJSONFile() error: %v", err)
+	}
+	raw, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(raw), encryptedFileMagic) || strings.Contains(string(raw), "hrv") {
+		t.Errorf("file on disk is not encrypted: %q", raw)
+	}
+	var baseline map[string]float64
+	if err := readJSONFile(cipher, path, &baseline); err != nil || baseline["hrv"] != 61.5 {
+		t.Errorf("round trip = %v, %v", baseline, err)
+	}
+
+	wrong, err := newPassphraseCipher("wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := readJSONFile(wrong, path, &baseline); err == nil {
+		t.Error("expected the wrong passphrase to fail")
+	}
+	if err := readJSONFile(nil, path, &baseline); err == nil || !strings.Contains(err.Error(), "WHOOP_DATA_ENCRYPTION") {
+		t.Errorf("reading without a key error = %v, want a hint to set WHOOP_DATA_ENCRYPTION", err)
+	}
+}
+
+func TestDataCipher_DerivesOnce(t *testing.T) {
+	cipher, err := newPassphraseCipher("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := len(encryptedFileMagic) + encryptionSaltSize
+	var sealed [][]byte
+	for i := 0; i < 50; i++ {
+		data, err := cipher.Seal([]byte("entry"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data[len(encryptedFileMagic):header], cipher.salt) {
+			t.Fatalf("seal %d wrote another salt", i)
+		}
+		sealed = append(sealed, data)
+	}
+	if bytes.Equal(sealed[0][header:header+12], sealed[1][header:header+12]) {
+		t.Error("Expected a fresh nonce for every seal")
+	}
+	for _, data := range sealed {
+		if plaintext, err := cipher.Open(data); err != nil || string(plaintext) != "entry" {
+			t.Fatalf("Open() = %q, %v", plaintext, err)
+		}
+	}
+	if len(cipher.derived) != 0 {
+		t.Errorf("derived %d extra keys for files sealed under the cipher's own salt", len(cipher.derived))
+	}
+
+	// A later run picks a new salt but still reads the earlier run's files
+	later, err := newPassphraseCipher("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plaintext, err := later.Open(sealed[0]); err != nil || string(plaintext) != "entry" {
+		t.Errorf("later Open() = %q, %v", plaintext, err)
+	}
+	if len(later.derived) != 1 {
+		t.Errorf("later run cached %d keys, want 1", len(later.derived))
+	}
+}
+
+func TestDatastoreEncryption_PerServer(t *testing.T) {
+	// Servers embedded in one process each seal their datastore with their
+	// own key, whichever was built last
+	newServer := func(passphrase string) *MCPServer {
+		settings := map[string]string{
+			"WHOOP_DATA_DIR":        t.TempDir(),
+			"WHOOP_DATA_ENCRYPTION": "passphrase",
+			"WHOOP_DATA_PASSPHRASE": passphrase,
+		}
+		server, err := NewMCPServerWithAPI(NewMockWhoopAPI(), func(name string) string { return settings[name] })
+		if err != nil {
+			t.Fatalf("NewMCPServerWithAPI() error = %v", err)
+		}
+		return server
+	}
+	first := newServer("first passphrase")
+	second := newServer("second passphrase")
+
+	if _, err := first.goals.Add(Goal{UserID: 1, Metric: "recovery", Type: "average", Target: 60}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if goals, err := first.goals.List(1); err != nil || len(goals) != 1 {
+		t.Errorf("first server's goals = %+v, %v", goals, err)
+	}
+	sealed, _ := os.ReadFile(first.goals.Path)
+	if _, err := second.cipher.Open(sealed); err == nil {
+		t.Error("Expected the first server's goals not to be sealed with the second server's key")
+	}
+}
+
+func TestNewDataCipherFromEnv(t *testing.T) {
+	if cipher, err := NewDataCipherFromEnv(os.Getenv); cipher != nil || err != nil {
+		t.Errorf("default = %v, %v, want encryption off", cipher, err)
+	}
+	t.Setenv("WHOOP_DATA_ENCRYPTION", "passphrase")
+	if _, err := NewDataCipherFromEnv(os.Getenv); err == nil {
+		t.Error("expected passphrase mode without WHOOP_DATA_PASSPHRASE to fail")
+	}
+	t.Setenv("WHOOP_DATA_ENCRYPTION", "rot13")
+	if _, err := NewDataCipherFromEnv(os.Getenv); err == nil {
+		t.Error("expected an unknown mode to fail")
+	}
+}
+
+func TestPurgeDatastore(t *testing.T) {
+	dir := t.TempDir()
+	goals := filepath.Join(dir, "goals.json")
+	reports := filepath.Join(dir, "reports")
+	for path, content := range map[string]string{
+		goals:          `{"1": []}`,
+		goals + ".tmp": `{"1": [`,
+		filepath.Join(reports, "weekly-2024-06-03.md"): "# Weekly Report",
+	} {
+		os.MkdirAll(filepath.Dir(path), 0700)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wiped, err := purgeDatastore([]string{goals, reports, filepath.Join(dir, "missing.json")})
+	if err != nil || wiped != 3 {
+		t.Fatalf("purgeDatastore() = %d, %v, want 3 files", wiped, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("datastore directory still holds %v", entries)
+	}
+}
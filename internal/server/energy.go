@@ -0,0 +1,186 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+const (
+	// largeDeficitKcal is the daily shortfall between intake and expenditure treated as large
+	largeDeficitKcal = 500.0
+	// underFuelingWindow is how many recent days are screened for under-fueling
+	underFuelingWindow = 7
+	// underFuelingMinDays is how many deficit days within the window make it sustained
+	underFuelingMinDays = 5
+	// underFuelingStrain is the average day strain above which a deficit is risky
+	underFuelingStrain = 14.0
+)
+
+// EnergyDay is one day's energy expenditure, and intake when reported
+type EnergyDay struct {
+	Date        string  `json:"date"`
+	TotalKcal   float64 `json:"total_kcal"`
+	ActiveKcal  float64 `json:"active_kcal"` // from recorded workouts
+	Strain      float64 `json:"strain"`
+	IntakeKcal  float64 `json:"intake_kcal,omitempty"`
+	DeficitKcal float64 `json:"deficit_kcal,omitempty"` // expenditure minus intake
+}
+
+// EnergyAnalysis summarizes expenditure trends and the under-fueling screen
+type EnergyAnalysis struct {
+	Days                []EnergyDay `json:"days"`
+	AverageTotalKcal    float64     `json:"average_total_kcal"`
+	AverageActiveKcal   float64     `json:"average_active_kcal"`
+	ActiveShare         float64     `json:"active_share_pct"`
+	TotalTrendPerWeek   float64     `json:"total_trend_kcal_per_week"`
+	ActiveTrendPerWeek  float64     `json:"active_trend_kcal_per_week"`
+	IntakeReported      bool        `json:"intake_reported"`
+	RecentDeficitDays   int         `json:"recent_deficit_days"`
+	RecentAverageStrain float64     `json:"recent_average_strain"`
+	UnderFueling        bool        `json:"under_fueling"`
+	RedFlags            []RedFlag   `json:"red_flags"`
+}
+
+// kilojoulesToKcal converts Whoop energy readings to dietary calories
+func kilojoulesToKcal(kj float64) float64 {
+	return kj / kilojoulesPerKcal
+}
+
+// AnalyzeEnergy builds daily expenditure from cycles (total) and workouts
+// (active). intake maps dayKey dates to reported intake in kcal; with it,
+// sustained large deficits on high-strain days are flagged as under-fueling.
+func (h *HealthAnalyzer) AnalyzeEnergy(cycles []WhoopCycle, workouts []WhoopWorkout, intake map[string]float64) EnergyAnalysis {
+	analysis := EnergyAnalysis{Days: []EnergyDay{}, RedFlags: []RedFlag{}}
+
+	active := make(map[string]float64)
+	for _, workout := range workouts {
+		if workout.ScoreState != "" && workout.ScoreState != "SCORED" {
+			continue
+		}
+		active[dayKey(localTime(workout.Start, workout.TimezoneOffset))] += kilojoulesToKcal(workout.Score.Kilojoule)
+	}
+
+	byDay := make(map[string]*EnergyDay)
+	for _, cycle := range cycles {
+		if (cycle.ScoreState != "" && cycle.ScoreState != "SCORED") || cycle.Score.Kilojoule == 0 {
+			continue
+		}
+		// Cycles begin at sleep onset the night before; date them by their midday
+		key := dayKey(localTime(cycle.Start, cycle.TimezoneOffset).Add(12 * time.Hour))
+		day, ok := byDay[key]
+		if !ok {
+			day = &EnergyDay{Date: key, ActiveKcal: active[key]}
+			byDay[key] = day
+		}
+		day.TotalKcal += kilojoulesToKcal(cycle.Score.Kilojoule)
+		day.Strain = cycle.Score.Strain
+	}
+
+	for _, day := range byDay {
+		if kcal, ok := intake[day.Date]; ok && kcal > 0 {
+			day.IntakeKcal = kcal
+			day.DeficitKcal = day.TotalKcal - kcal
+			analysis.IntakeReported = true
+		}
+		analysis.Days = append(analysis.Days, *day)
+	}
+	sort.Slice(analysis.Days, func(i, j int) bool {
+		return analysis.Days[i].Date < analysis.Days[j].Date
+	})
+	if len(analysis.Days) == 0 {
+		return analysis
+	}
+
+	var totals, actives, xs []float64
+	for i, day := range analysis.Days {
+		totals = append(totals, day.TotalKcal)
+		actives = append(actives, day.ActiveKcal)
+		xs = append(xs, float64(i))
+	}
+	analysis.AverageTotalKcal = h.calculateMean(totals)
+	analysis.AverageActiveKcal = h.calculateMean(actives)
+	if analysis.AverageTotalKcal > 0 {
+		analysis.ActiveShare = analysis.AverageActiveKcal / analysis.AverageTotalKcal * 100
+	}
+	totalSlope, _, _ := stats.LinearRegression(xs, totals)
+	activeSlope, _, _ := stats.LinearRegression(xs, actives)
+	analysis.TotalTrendPerWeek = totalSlope * 7
+	analysis.ActiveTrendPerWeek = activeSlope * 7
+
+	recent := analysis.Days
+	if len(recent) > underFuelingWindow {
+		recent = recent[len(recent)-underFuelingWindow:]
+	}
+	var recentStrain []float64
+	for _, day := range recent {
+		recentStrain = append(recentStrain, day.Strain)
+		if day.IntakeKcal > 0 && day.DeficitKcal >= largeDeficitKcal {
+			analysis.RecentDeficitDays++
+		}
+	}
+	analysis.RecentAverageStrain = h.calculateMean(recentStrain)
+
+	if analysis.RecentDeficitDays >= underFuelingMinDays && analysis.RecentAverageStrain >= underFuelingStrain {
+		analysis.UnderFueling = true
+		analysis.RedFlags = append(analysis.RedFlags, RedFlag{
+			Type: "under_fueling",
+			Description: fmt.Sprintf("Intake fell %.0f+ kcal short of expenditure on %d of the last %d days while average strain was %.1f",
+				largeDeficitKcal, analysis.RecentDeficitDays, len(recent), analysis.RecentAverageStrain),
+			Severity:       "high",
+			DetectedAt:     time.Now(),
+			Recommendation: "Sustained low energy availability under heavy training impairs recovery, hormones, and bone health, and can accompany restrictive eating. Explore eating patterns and body-image concerns gently; consider referral to a sports dietitian or eating-disorder specialist",
+		})
+	}
+
+	analysis.RedFlags = h.reviewRedFlags(analysis.RedFlags)
+	return analysis
+}
+
+// FormatEnergyAnalysis renders expenditure trends and any under-fueling flag
+func (h *HealthAnalyzer) FormatEnergyAnalysis(analysis EnergyAnalysis) string {
+	var builder strings.Builder
+	builder.WriteString("# Energy Expenditure Analysis\n\n")
+
+	if len(analysis.Days) == 0 {
+		builder.WriteString("No scored energy data for this period.\n")
+		return builder.String()
+	}
+
+	builder.WriteString("## Daily Averages\n")
+	builder.WriteString(fmt.Sprintf("- **Total expenditure:** %.0f kcal/day (%+.0f kcal/day per week)\n", analysis.AverageTotalKcal, analysis.TotalTrendPerWeek))
+	builder.WriteString(fmt.Sprintf("- **Active (workouts):** %.0f kcal/day (%+.0f kcal/day per week)\n", analysis.AverageActiveKcal, analysis.ActiveTrendPerWeek))
+	builder.WriteString(fmt.Sprintf("- **Active share:** %.0f%%\n\n", analysis.ActiveShare))
+
+	builder.WriteString("## Recent Days\n")
+	recent := analysis.Days
+	if len(recent) > underFuelingWindow {
+		recent = recent[len(recent)-underFuelingWindow:]
+	}
+	for _, day := range recent {
+		line := fmt.Sprintf("- %s: %.0f kcal total, %.0f active, strain %.1f", day.Date, day.TotalKcal, day.ActiveKcal, day.Strain)
+		if day.IntakeKcal > 0 {
+			line += fmt.Sprintf(", intake %.0f (balance %+.0f)", day.IntakeKcal, -day.DeficitKcal)
+		}
+		builder.WriteString(line + "\n")
+	}
+
+	builder.WriteString("\n## Fueling Screen\n")
+	switch {
+	case !analysis.IntakeReported:
+		builder.WriteString("No intake was provided, so energy balance cannot be assessed. Pass typical or per-day intake to screen for under-fueling.\n")
+	case analysis.UnderFueling:
+		for _, flag := range analysis.RedFlags {
+			builder.WriteString(formatSafetyNotice(flag) + "\n")
+			builder.WriteString(fmt.Sprintf("🚨 **%s:** %s\n\n*Recommendation:* %s\n", strings.ToUpper(flag.Severity), flag.Description, flag.Recommendation))
+		}
+	default:
+		builder.WriteString(fmt.Sprintf("✅ No sustained large deficits paired with high strain (%d large-deficit days in the last %d).\n", analysis.RecentDeficitDays, len(recent)))
+	}
+
+	builder.WriteString("\n*Wearable calorie estimates can be off by 10-20%; use trends rather than single-day numbers.*\n")
+	return builder.String()
+}
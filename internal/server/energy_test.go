@@ -0,0 +1,51 @@
+package server
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_AnalyzeEnergy(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	start := time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)
+
+	var cycles []WhoopCycle
+	var workouts []WhoopWorkout
+	for i := 0; i < 10; i++ {
+		var cycle WhoopCycle
+		cycle.Start = start.AddDate(0, 0, i)
+		cycle.Score.Kilojoule = 3000 * kilojoulesPerKcal
+		cycle.Score.Strain = 16
+		cycles = append(cycles, cycle)
+
+		var workout WhoopWorkout
+		workout.Start = cycle.Start.Add(10 * time.Hour)
+		workout.Score.Kilojoule = 800 * kilojoulesPerKcal
+		workouts = append(workouts, workout)
+	}
+
+	t.Run("without intake", func(t *testing.T) {
+		analysis := analyzer.AnalyzeEnergy(cycles, workouts, nil)
+		if len(analysis.Days) != 10 {
+			t.Fatalf("Expected 10 days, got %d", len(analysis.Days))
+		}
+		if math.Abs(analysis.AverageTotalKcal-3000) > 0.01 || math.Abs(analysis.AverageActiveKcal-800) > 0.01 {
+			t.Errorf("Expected 3000/800 kcal, got %.0f/%.0f", analysis.AverageTotalKcal, analysis.AverageActiveKcal)
+		}
+		if analysis.IntakeReported || analysis.UnderFueling {
+			t.Error("Under-fueling cannot be assessed without intake")
+		}
+	})
+
+	t.Run("sustained deficit under high strain", func(t *testing.T) {
+		intake := make(map[string]float64)
+		for _, day := range analyzer.AnalyzeEnergy(cycles, workouts, nil).Days {
+			intake[day.Date] = 2200
+		}
+		analysis := analyzer.AnalyzeEnergy(cycles, workouts, intake)
+		if !analysis.UnderFueling || len(analysis.RedFlags) != 1 || analysis.RedFlags[0].Type != "under_fueling" {
+			t.Errorf("Expected an under-fueling red flag, got %+v", analysis.RedFlags)
+		}
+	})
+}
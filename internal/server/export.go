@@ -0,0 +1,164 @@
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportDatasets are the raw record types export_data can write, in output order
+var exportDatasets = []string{"recovery", "sleep", "workout", "cycle"}
+
+// exportTable is one dataset flattened to CSV columns
+type exportTable struct {
+	Dataset string
+	Header  []string
+	Rows    [][]string
+}
+
+// records returns the raw records for a dataset as a slice value
+func (d *HealthData) records(dataset string) interface{} {
+	switch dataset {
+	case "recovery":
+		return d.Recoveries
+	case "sleep":
+		return d.Sleeps
+	case "workout":
+		return d.Workouts
+	default:
+		return d.Cycles
+	}
+}
+
+// buildExportTable flattens records into one row per record. Columns follow
+// the Whoop JSON field names, with nested objects joined by underscores and
+// the score prefix dropped (e.g. recovery_score, stage_summary_total_in_bed_time_milli).
+// Imperial units rename and convert metric fields the same way structured
+// results do.
+func buildExportTable(dataset string, records interface{}, units UnitSystem) exportTable {
+	table := exportTable{Dataset: dataset}
+	value := reflect.ValueOf(records)
+	table.Header = exportColumns(value.Type().Elem(), "", units)
+	for i := 0; i < value.Len(); i++ {
+		table.Rows = append(table.Rows, exportValues(value.Index(i), units))
+	}
+	return table
+}
+
+// exportColumns lists the flattened column names of a record type
+func exportColumns(t reflect.Type, prefix string, units UnitSystem) []string {
+	var columns []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := exportFieldName(field)
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			columns = append(columns, exportColumns(field.Type, nestedPrefix(prefix, name), units)...)
+			continue
+		}
+		if replacement, ok := imperialFields[name]; ok && units == unitsImperial {
+			name = replacement.name
+		}
+		columns = append(columns, prefix+name)
+	}
+	return columns
+}
+
+// exportValues renders a record's fields in exportColumns order
+func exportValues(v reflect.Value, units UnitSystem) []string {
+	var values []string
+	for i := 0; i < v.NumField(); i++ {
+		field, fieldType := v.Field(i), v.Type().Field(i)
+		if field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				values = append(values, "")
+				continue
+			}
+			field = field.Elem()
+		}
+		switch field.Kind() {
+		case reflect.Struct:
+			if t, ok := field.Interface().(time.Time); ok {
+				values = append(values, t.Format(time.RFC3339))
+			} else {
+				values = append(values, exportValues(field, units)...)
+			}
+		case reflect.Float64:
+			number := field.Float()
+			if replacement, ok := imperialFields[exportFieldName(fieldType)]; ok && units == unitsImperial {
+				number = replacement.convert(number)
+			}
+			values = append(values, strconv.FormatFloat(number, 'f', -1, 64))
+		default:
+			values = append(values, fmt.Sprint(field.Interface()))
+		}
+	}
+	return values
+}
+
+// exportFieldName is a field's JSON name without options
+func exportFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// nestedPrefix extends a column prefix for a nested object; the score wrapper
+// every Whoop record shares is left out
+func nestedPrefix(prefix, name string) string {
+	if name == "score" {
+		return prefix
+	}
+	return prefix + name + "_"
+}
+
+// CSV encodes the table with a header row
+func (t exportTable) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(t.Header); err != nil {
+		return nil, err
+	}
+	if err := writer.WriteAll(t.Rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// exportPath resolves where a dataset is written: path itself for a single
+// dataset named *.csv, otherwise <dataset>.csv inside the path directory
+func exportPath(path, dataset string, datasets int) string {
+	if datasets == 1 && strings.EqualFold(filepath.Ext(path), ".csv") {
+		return path
+	}
+	return filepath.Join(path, dataset+".csv")
+}
+
+// parseExportDatasets validates the requested datasets, defaulting to all
+func parseExportDatasets(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return exportDatasets, nil
+	}
+	wanted := make(map[string]bool)
+	for _, dataset := range requested {
+		dataset = strings.ToLower(strings.TrimSpace(dataset))
+		if !slices.Contains(exportDatasets, dataset) {
+			return nil, fmt.Errorf("unknown dataset %q (expected one of: %s)", dataset, strings.Join(exportDatasets, ", "))
+		}
+		wanted[dataset] = true
+	}
+	var datasets []string
+	for _, dataset := range exportDatasets {
+		if wanted[dataset] {
+			datasets = append(datasets, dataset)
+		}
+	}
+	return datasets, nil
+}
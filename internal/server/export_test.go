@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/csv"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildExportTable(t *testing.T) {
+	var recovery WhoopRecovery
+	recovery.CycleID = 42
+	recovery.SleepID = "abc"
+	recovery.CreatedAt = time.Date(2024, 7, 1, 8, 0, 0, 0, time.UTC)
+	recovery.ScoreState = "SCORED"
+	recovery.Score.RecoveryScore = 71
+	recovery.Score.SkinTempCelsius = 33.5
+
+	table := buildExportTable("recovery", []WhoopRecovery{recovery}, unitsMetric)
+	wantHeader := []string{"cycle_id", "sleep_id", "user_id", "created_at", "updated_at", "score_state",
+		"user_calibrating", "recovery_score", "resting_heart_rate", "hrv_rmssd_milli", "skin_temp_celsius", "spo2_percentage"}
+	if !reflect.DeepEqual(table.Header, wantHeader) {
+		t.Errorf("Header = %v, want %v", table.Header, wantHeader)
+	}
+	if len(table.Rows) != 1 || len(table.Rows[0]) != len(wantHeader) {
+		t.Fatalf("Expected one row matching the header, got %v", table.Rows)
+	}
+	row := table.Rows[0]
+	if row[0] != "42" || row[3] != "2024-07-01T08:00:00Z" || row[7] != "71" || row[10] != "33.5" {
+		t.Errorf("Unexpected row %v", row)
+	}
+
+	imperial := buildExportTable("recovery", []WhoopRecovery{recovery}, unitsImperial)
+	if imperial.Header[10] != "skin_temp_fahrenheit" || imperial.Rows[0][10] != "92.3" {
+		t.Errorf("Expected skin temperature in °F, got %s=%s", imperial.Header[10], imperial.Rows[0][10])
+	}
+}
+
+func TestBuildExportTable_NestedAndOptional(t *testing.T) {
+	var workout WhoopWorkout
+	workout.Score.ZoneDurations.ZoneTwoMilli = 60000
+	table := buildExportTable("workout", []WhoopWorkout{workout}, unitsMetric)
+
+	content, err := table.CSV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("Expected a header and one row, got %v (%v)", records, err)
+	}
+	columns := make(map[string]string)
+	for i, name := range records[0] {
+		columns[name] = records[1][i]
+	}
+	if columns["zone_durations_zone_two_milli"] != "60000" {
+		t.Errorf("Expected nested zone columns, got %v", records[0])
+	}
+	if value, ok := columns["v1_id"]; !ok || value != "" {
+		t.Errorf("Expected an empty v1_id column for a nil pointer, got %q", value)
+	}
+}
+
+func TestExportPath(t *testing.T) {
+	if got := exportPath("/tmp/recovery-2024.csv", "recovery", 1); got != "/tmp/recovery-2024.csv" {
+		t.Errorf("Expected the file path for a single dataset, got %s", got)
+	}
+	if got := exportPath("/tmp/export", "sleep", 2); got != filepath.Join("/tmp/export", "sleep.csv") {
+		t.Errorf("Expected a file inside the directory, got %s", got)
+	}
+}
+
+func TestParseExportDatasets(t *testing.T) {
+	datasets, err := parseExportDatasets([]string{"cycle", "Recovery"})
+	if err != nil || !reflect.DeepEqual(datasets, []string{"recovery", "cycle"}) {
+		t.Errorf("parseExportDatasets() = %v, %v", datasets, err)
+	}
+	if _, err := parseExportDatasets([]string{"steps"}); err == nil {
+		t.Error("Expected an error for an unknown dataset")
+	}
+}
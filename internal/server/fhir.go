@@ -0,0 +1,209 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	fhirLOINC    = "http://loinc.org"
+	fhirUCUM     = "http://unitsofmeasure.org"
+	fhirCategory = "http://terminology.hl7.org/CodeSystem/observation-category"
+	// fhirWhoopSystem identifies Whoop-specific codes and user identifiers,
+	// used where LOINC has no equivalent (recovery score, RMSSD)
+	fhirWhoopSystem = "https://api.prod.whoop.com/developer"
+)
+
+// FHIRBundle is a FHIR R4 collection Bundle
+type FHIRBundle struct {
+	ResourceType string            `json:"resourceType"`
+	Type         string            `json:"type"`
+	Timestamp    string            `json:"timestamp"`
+	Entry        []FHIRBundleEntry `json:"entry"`
+}
+
+// FHIRBundleEntry wraps one resource in a Bundle
+type FHIRBundleEntry struct {
+	Resource FHIRObservation `json:"resource"`
+}
+
+// FHIRObservation is the subset of the R4 Observation resource the export uses
+type FHIRObservation struct {
+	ResourceType      string                `json:"resourceType"`
+	ID                string                `json:"id"`
+	Meta              *FHIRMeta             `json:"meta,omitempty"`
+	Status            string                `json:"status"`
+	Category          []FHIRCodeableConcept `json:"category"`
+	Code              FHIRCodeableConcept   `json:"code"`
+	Subject           FHIRReference         `json:"subject"`
+	EffectiveDateTime string                `json:"effectiveDateTime,omitempty"`
+	EffectivePeriod   *FHIRPeriod           `json:"effectivePeriod,omitempty"`
+	Issued            string                `json:"issued"`
+	ValueQuantity     FHIRQuantity          `json:"valueQuantity"`
+	Device            *FHIRReference        `json:"device,omitempty"`
+}
+
+type FHIRMeta struct {
+	Profile []string `json:"profile"`
+}
+
+type FHIRCoding struct {
+	System  string `json:"system"`
+	Code    string `json:"code"`
+	Display string `json:"display,omitempty"`
+}
+
+type FHIRCodeableConcept struct {
+	Coding []FHIRCoding `json:"coding"`
+	Text   string       `json:"text,omitempty"`
+}
+
+type FHIRIdentifier struct {
+	System string `json:"system"`
+	Value  string `json:"value"`
+}
+
+type FHIRReference struct {
+	Identifier *FHIRIdentifier `json:"identifier,omitempty"`
+	Display    string          `json:"display,omitempty"`
+}
+
+type FHIRPeriod struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type FHIRQuantity struct {
+	Value  float64 `json:"value"`
+	Unit   string  `json:"unit"`
+	System string  `json:"system"`
+	Code   string  `json:"code"`
+}
+
+// fhirObservationType describes how one Whoop metric is coded
+type fhirObservationType struct {
+	id       string
+	category string
+	profile  string
+	coding   []FHIRCoding
+	text     string
+	unit     string // UCUM code
+}
+
+var (
+	fhirRecovery = fhirObservationType{
+		id: "recovery", category: "activity", text: "Whoop recovery score", unit: "%",
+		coding: []FHIRCoding{{System: fhirWhoopSystem, Code: "recovery_score", Display: "Recovery score"}},
+	}
+	fhirHRV = fhirObservationType{
+		id: "hrv", category: "vital-signs", text: "Heart rate variability (RMSSD) during sleep", unit: "ms",
+		coding: []FHIRCoding{{System: fhirWhoopSystem, Code: "hrv_rmssd", Display: "Heart rate variability RMSSD"}},
+	}
+	fhirRestingHR = fhirObservationType{
+		id: "rhr", category: "vital-signs", text: "Resting heart rate", unit: "/min",
+		profile: "http://hl7.org/fhir/StructureDefinition/heartrate",
+		coding: []FHIRCoding{
+			{System: fhirLOINC, Code: "40443-4", Display: "Heart rate --resting"},
+			{System: fhirLOINC, Code: "8867-4", Display: "Heart rate"},
+		},
+	}
+	fhirSpO2 = fhirObservationType{
+		id: "spo2", category: "vital-signs", text: "Oxygen saturation during sleep", unit: "%",
+		profile: "http://hl7.org/fhir/StructureDefinition/oxygensat",
+		coding: []FHIRCoding{
+			{System: fhirLOINC, Code: "2708-6", Display: "Oxygen saturation in Arterial blood"},
+			{System: fhirLOINC, Code: "59408-5", Display: "Oxygen saturation in Arterial blood by Pulse oximetry"},
+		},
+	}
+	fhirSleepDuration = fhirObservationType{
+		id: "sleep", category: "activity", text: "Sleep duration", unit: "h",
+		coding: []FHIRCoding{{System: fhirLOINC, Code: "93832-4", Display: "Sleep duration"}},
+	}
+)
+
+// observation builds an Observation of this type for one value
+func (t fhirObservationType) observation(recordID string, subject FHIRReference, value float64, issued time.Time) FHIRObservation {
+	obs := FHIRObservation{
+		ResourceType: "Observation",
+		ID:           t.id + "-" + recordID,
+		Status:       "final",
+		Category:     []FHIRCodeableConcept{{Coding: []FHIRCoding{{System: fhirCategory, Code: t.category}}}},
+		Code:         FHIRCodeableConcept{Coding: t.coding, Text: t.text},
+		Subject:      subject,
+		Issued:       issued.UTC().Format(time.RFC3339),
+		ValueQuantity: FHIRQuantity{
+			Value: value, Unit: t.unit, System: fhirUCUM, Code: t.unit,
+		},
+		Device: &FHIRReference{Display: "WHOOP"},
+	}
+	if t.profile != "" {
+		obs.Meta = &FHIRMeta{Profile: []string{t.profile}}
+	}
+	return obs
+}
+
+// buildFHIRBundle converts scored recoveries (recovery, HRV, resting HR, SpO2)
+// and main sleeps (duration) into a collection Bundle of R4 Observations.
+// Recovery metrics are effective when Whoop scored them; sleep duration
+// covers the sleep period.
+func buildFHIRBundle(data *HealthData, userID int, now time.Time) FHIRBundle {
+	subject := FHIRReference{Identifier: &FHIRIdentifier{System: fhirWhoopSystem + "/user", Value: strconv.Itoa(userID)}}
+	bundle := FHIRBundle{ResourceType: "Bundle", Type: "collection", Timestamp: now.UTC().Format(time.RFC3339), Entry: []FHIRBundleEntry{}}
+	add := func(obs FHIRObservation) {
+		bundle.Entry = append(bundle.Entry, FHIRBundleEntry{Resource: obs})
+	}
+
+	for _, recovery := range data.Recoveries {
+		if !scored(recovery.ScoreState) {
+			continue
+		}
+		id := strconv.FormatInt(recovery.CycleID, 10)
+		effective := recovery.CreatedAt.UTC().Format(time.RFC3339)
+		score := recovery.Score
+		for _, metric := range []struct {
+			kind  fhirObservationType
+			value float64
+		}{
+			{fhirRecovery, score.RecoveryScore},
+			{fhirHRV, score.HRVRmssd},
+			{fhirRestingHR, score.RestingHeartRate},
+			{fhirSpO2, score.SpO2Percentage},
+		} {
+			// Older straps do not measure SpO2 and report zero
+			if metric.value <= 0 {
+				continue
+			}
+			obs := metric.kind.observation(id, subject, metric.value, recovery.UpdatedAt)
+			obs.EffectiveDateTime = effective
+			add(obs)
+		}
+	}
+
+	for _, sleep := range data.Sleeps {
+		if sleep.Nap || !scored(sleep.ScoreState) {
+			continue
+		}
+		stages := sleep.Score.StageSummary
+		hours := float64(stages.TotalInBedTimeMilli-stages.TotalAwakeTimeMilli) / float64(time.Hour/time.Millisecond)
+		obs := fhirSleepDuration.observation(sleep.ID, subject, math.Round(hours*100)/100, sleep.UpdatedAt)
+		obs.EffectivePeriod = &FHIRPeriod{
+			Start: localTime(sleep.Start, sleep.TimezoneOffset).Format(time.RFC3339),
+			End:   localTime(sleep.End, sleep.TimezoneOffset).Format(time.RFC3339),
+		}
+		add(obs)
+	}
+
+	return bundle
+}
+
+// JSON encodes the bundle as indented FHIR JSON
+func (b FHIRBundle) JSON() ([]byte, error) {
+	body, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode FHIR bundle: %w", err)
+	}
+	return append(body, '\n'), nil
+}
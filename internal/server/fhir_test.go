@@ -0,0 +1,64 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestBuildFHIRBundle(t *testing.T) {
+	data := healthArchiveFixture()
+
+	var recovery WhoopRecovery
+	recovery.CycleID = 42
+	recovery.ScoreState = "SCORED"
+	recovery.CreatedAt = time.Date(2024, 5, 6, 10, 30, 0, 0, time.UTC)
+	recovery.Score.RecoveryScore = 71
+	recovery.Score.HRVRmssd = 48.2
+	recovery.Score.RestingHeartRate = 54
+	data.Recoveries = []WhoopRecovery{recovery, {ScoreState: "PENDING_SCORE"}}
+	data.Sleeps[0].Score.StageSummary.TotalInBedTimeMilli = 7 * 60 * 60 * 1000
+	data.Sleeps[0].Score.StageSummary.TotalAwakeTimeMilli = 30 * 60 * 1000
+	data.Sleeps = append(data.Sleeps, WhoopSleep{ID: "nap", Nap: true})
+
+	content, name, err := encodeHealthArchive("fhir", data, 1234, NewSportsCatalog(), time.Now())
+	if err != nil {
+		t.Fatalf("encodeHealthArchive() error = %v", err)
+	}
+	if name != "fhir_bundle.json" {
+		t.Errorf("Expected fhir_bundle.json, got %s", name)
+	}
+
+	var bundle FHIRBundle
+	if err := json.Unmarshal(content, &bundle); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if bundle.ResourceType != "Bundle" || bundle.Type != "collection" {
+		t.Errorf("Unexpected bundle header: %+v", bundle)
+	}
+
+	// SpO2 is zero on this recovery, the pending recovery and the nap are skipped
+	ids := make(map[string]FHIRObservation)
+	for _, entry := range bundle.Entry {
+		ids[entry.Resource.ID] = entry.Resource
+	}
+	if len(ids) != 4 {
+		t.Fatalf("Expected 4 observations, got %v", ids)
+	}
+
+	rhr := ids["rhr-42"]
+	if rhr.Code.Coding[0].Code != "40443-4" || rhr.ValueQuantity.Value != 54 || rhr.ValueQuantity.Code != "/min" {
+		t.Errorf("Unexpected resting HR observation: %+v", rhr)
+	}
+	if rhr.Meta == nil || rhr.Meta.Profile[0] != "http://hl7.org/fhir/StructureDefinition/heartrate" {
+		t.Error("Expected the vital signs heart rate profile")
+	}
+	if rhr.Subject.Identifier.Value != "1234" || rhr.EffectiveDateTime != "2024-05-06T10:30:00Z" {
+		t.Errorf("Unexpected subject or effective time: %+v", rhr)
+	}
+
+	sleep := ids["sleep-sleep-1"]
+	if sleep.ValueQuantity.Value != 6.5 || sleep.EffectivePeriod == nil || sleep.EffectivePeriod.Start != "2024-05-05T22:00:00-05:00" {
+		t.Errorf("Unexpected sleep observation: %+v", sleep)
+	}
+}
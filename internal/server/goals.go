@@ -0,0 +1,299 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	goalAverage = "average" // weekly mean of a daily metric
+	goalDays    = "days"    // days per week a daily metric meets the target
+	goalCount   = "count"   // workouts per week
+
+	goalMetricWorkouts = "workouts"
+)
+
+// Goal is a weekly target the user is working towards
+type Goal struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	Metric      string    `json:"metric"` // daily metric key or "workouts"
+	Type        string    `json:"type"`   // "average", "days", "count"
+	Target      float64   `json:"target"`
+	AtMost      bool      `json:"at_most,omitempty"`       // target is a ceiling rather than a floor
+	DaysPerWeek int       `json:"days_per_week,omitempty"` // for "days" goals
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Validate checks that the goal's metric, type, and targets fit together
+func (g Goal) Validate() error {
+	switch g.Type {
+	case goalAverage, goalDays:
+		if _, ok := lookupMetric(g.Metric); !ok {
+			return fmt.Errorf("unsupported metric for %s goals: %s", g.Type, g.Metric)
+		}
+		if g.Type == goalDays && (g.DaysPerWeek < 1 || g.DaysPerWeek > 7) {
+			return fmt.Errorf("days_per_week must be between 1 and 7")
+		}
+	case goalCount:
+		if g.Metric != goalMetricWorkouts {
+			return fmt.Errorf("count goals are only supported for workouts")
+		}
+		if g.Target < 1 {
+			return fmt.Errorf("target must be at least 1 workout")
+		}
+	default:
+		return fmt.Errorf("unsupported goal type: %s", g.Type)
+	}
+	return nil
+}
+
+// Describe renders the goal in words, e.g. "Recovery ≥ 60% on 5 days a week"
+func (g Goal) Describe() string {
+	return g.describe(nil)
+}
+
+// describe is Describe in the localizer's language
+func (g Goal) describe(l *Localizer) string {
+	if g.Type == goalCount {
+		return l.T("%.0f workouts a week", g.Target)
+	}
+	metric, _ := lookupMetric(g.Metric)
+	comparison := "≥"
+	if g.AtMost {
+		comparison = "≤"
+	}
+	if g.Type == goalDays {
+		return l.T("%s %s %g%s on %d days a week", l.T(metric.Label), comparison, g.Target, metric.Unit, g.DaysPerWeek)
+	}
+	return l.T("Average %s %s %g%s", strings.ToLower(l.T(metric.Label)), comparison, g.Target, metric.Unit)
+}
+
+// meets reports whether a value satisfies the goal's target
+func (g Goal) meets(value float64) bool {
+	if g.AtMost {
+		return value <= g.Target
+	}
+	return value >= g.Target
+}
+
+// GoalWeek is a goal's result for one Monday-start week
+type GoalWeek struct {
+	WeekStart string  `json:"week_start"`
+	Value     float64 `json:"value"` // weekly average, qualifying days, or workouts
+	Days      int     `json:"days"`  // days with data
+	Met       bool    `json:"met"`
+	Complete  bool    `json:"complete"`
+}
+
+// GoalProgress tracks a goal week by week
+type GoalProgress struct {
+	Goal          Goal       `json:"goal"`
+	Description   string     `json:"description"`
+	Weeks         []GoalWeek `json:"weeks"`
+	CurrentStreak int        `json:"current_streak"` // consecutive weeks met up to now
+	BestStreak    int        `json:"best_streak"`
+}
+
+// EvaluateGoal scores a goal for each week from start to end. Weeks that are
+// still in progress at now, or cut off by the data range, are marked incomplete
+// and only extend the streak once already met.
+func (h *HealthAnalyzer) EvaluateGoal(goal Goal, data *HealthData, start, end, now time.Time) GoalProgress {
+	progress := GoalProgress{Goal: goal, Description: goal.describe(h.locale)}
+
+	daily := make(map[string]float64)
+	if goal.Type == goalCount {
+		for _, workout := range data.Workouts {
+			daily[dayKey(localTime(workout.Start, workout.TimezoneOffset))]++
+		}
+	} else {
+		metric, _ := lookupMetric(goal.Metric)
+		daily = dailyAverages(metric.extract(data))
+	}
+
+	firstDay, lastDay := dayKey(start), dayKey(end)
+	for week := bucketStart(start, granularityWeekly); !week.After(end); week = week.AddDate(0, 0, 7) {
+		weekEnd := week.AddDate(0, 0, 6)
+		entry := GoalWeek{
+			WeekStart: dayKey(week),
+			Complete:  dayKey(week) >= firstDay && dayKey(weekEnd) <= lastDay && dayKey(weekEnd) < dayKey(now),
+		}
+
+		var values []float64
+		for day := week; !day.After(weekEnd); day = day.AddDate(0, 0, 1) {
+			if value, ok := daily[dayKey(day)]; ok {
+				values = append(values, value)
+			}
+		}
+		entry.Days = len(values)
+
+		switch goal.Type {
+		case goalAverage:
+			entry.Value = h.calculateMean(values)
+			entry.Met = len(values) > 0 && goal.meets(entry.Value)
+		case goalDays:
+			for _, value := range values {
+				if goal.meets(value) {
+					entry.Value++
+				}
+			}
+			entry.Met = entry.Value >= float64(goal.DaysPerWeek)
+		case goalCount:
+			for _, value := range values {
+				entry.Value += value
+			}
+			entry.Met = entry.Value >= goal.Target
+		}
+		progress.Weeks = append(progress.Weeks, entry)
+	}
+
+	streak := 0
+	for _, week := range progress.Weeks {
+		switch {
+		case week.Met:
+			streak++
+		case week.Complete:
+			streak = 0
+		}
+		if streak > progress.BestStreak {
+			progress.BestStreak = streak
+		}
+	}
+	progress.CurrentStreak = streak
+	return progress
+}
+
+// formatGoalValue renders a week's value in the goal's terms
+func formatGoalValue(l *Localizer, goal Goal, week GoalWeek) string {
+	switch goal.Type {
+	case goalDays:
+		return l.T("%.0f/%d days", week.Value, goal.DaysPerWeek)
+	case goalCount:
+		return l.T("%.0f/%.0f workouts", week.Value, goal.Target)
+	default:
+		if week.Days == 0 {
+			return l.T("no data")
+		}
+		metric, _ := lookupMetric(goal.Metric)
+		return fmt.Sprintf("%.1f%s", week.Value, metric.Unit)
+	}
+}
+
+// FormatGoalProgress renders progress for each goal in the localizer's
+// language; heading sets the markdown level
+func FormatGoalProgress(l *Localizer, progress []GoalProgress, heading string) string {
+	var builder strings.Builder
+	builder.WriteString(l.T("%s Goals", heading) + "\n\n")
+	if len(progress) == 0 {
+		builder.WriteString(l.T("No goals set. Use set_goal to add one.") + "\n")
+		return builder.String()
+	}
+
+	for _, p := range progress {
+		builder.WriteString(fmt.Sprintf("**#%d %s**\n", p.Goal.ID, p.Description))
+
+		var history []string
+		for _, week := range p.Weeks {
+			icon := "❌"
+			switch {
+			case week.Met:
+				icon = "✅"
+			case !week.Complete:
+				icon = "⏳"
+			}
+			history = append(history, fmt.Sprintf("%s %s %s", icon, week.WeekStart, formatGoalValue(l, p.Goal, week)))
+		}
+		for _, line := range history {
+			builder.WriteString("- " + line + "\n")
+		}
+		builder.WriteString("- " + l.T("Streak: %d week(s) (best %d)", p.CurrentStreak, p.BestStreak) + "\n\n")
+	}
+	return builder.String()
+}
+
+// GoalStore persists goals per user in the local datastore
+type GoalStore struct {
+	Path string
+	mu   sync.Mutex
+	storeMode
+}
+
+// NewGoalStoreFromEnv uses WHOOP_GOALS_FILE or the default datastore path,
+// encrypting it with cipher when it is set
+func NewGoalStoreFromEnv(settings Settings, cipher *DataCipher) (*GoalStore, error) {
+	path, err := localDataPath(settings, "WHOOP_GOALS_FILE", "goals.json")
+	if err != nil {
+		return nil, err
+	}
+	return &GoalStore{Path: path, storeMode: storeMode{cipher: cipher}}, nil
+}
+
+// Add stores a goal, assigning the next ID for its user
+func (g *GoalStore) Add(goal Goal) (Goal, error) {
+	if err := g.writable(); err != nil {
+		return Goal{}, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	all := make(map[string][]Goal)
+	if err := readJSONFile(g.cipher, g.Path, &all); err != nil {
+		return Goal{}, err
+	}
+
+	key := strconv.Itoa(goal.UserID)
+	goal.ID = 1
+	for _, existing := range all[key] {
+		if existing.ID >= goal.ID {
+			goal.ID = existing.ID + 1
+		}
+	}
+	all[key] = append(all[key], goal)
+
+	if err := writeJSONFile(g.cipher, g.Path, all); err != nil {
+		return Goal{}, err
+	}
+	return goal, nil
+}
+
+// Remove deletes one of the user's goals, reporting whether it existed
+func (g *GoalStore) Remove(userID, id int) (bool, error) {
+	if err := g.writable(); err != nil {
+		return false, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	all := make(map[string][]Goal)
+	if err := readJSONFile(g.cipher, g.Path, &all); err != nil {
+		return false, err
+	}
+
+	key := strconv.Itoa(userID)
+	kept := all[key][:0:0]
+	for _, goal := range all[key] {
+		if goal.ID != id {
+			kept = append(kept, goal)
+		}
+	}
+	if len(kept) == len(all[key]) {
+		return false, nil
+	}
+	all[key] = kept
+	return true, writeJSONFile(g.cipher, g.Path, all)
+}
+
+// List returns the user's goals in the order they were set
+func (g *GoalStore) List(userID int) ([]Goal, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	all := make(map[string][]Goal)
+	if err := readJSONFile(g.cipher, g.Path, &all); err != nil {
+		return nil, err
+	}
+	return all[strconv.Itoa(userID)], nil
+}
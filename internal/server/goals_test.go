@@ -0,0 +1,103 @@
+package server
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_EvaluateGoal(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	hour := int(time.Hour / time.Millisecond)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) // a Monday
+	now := time.Date(2024, 1, 24, 12, 0, 0, 0, time.UTC)
+
+	data := &HealthData{}
+	for i := 0; i < 24; i++ {
+		wake := start.AddDate(0, 0, i).Add(7 * time.Hour)
+		// Week one falls short; weeks two to four hit the targets
+		hours, recovery := 8.0, 70.0
+		if i < 7 {
+			hours, recovery = 6.5, 50
+		}
+
+		var sleep WhoopSleep
+		sleep.ID = fmt.Sprintf("sleep-%d", i)
+		sleep.Start = wake.Add(-time.Duration(hours * float64(time.Hour)))
+		sleep.End = wake
+		sleep.Score.StageSummary.TotalInBedTimeMilli = int(hours * float64(hour))
+		data.Sleeps = append(data.Sleeps, sleep)
+
+		var r WhoopRecovery
+		r.SleepID = sleep.ID
+		r.CreatedAt = wake
+		r.Score.RecoveryScore = recovery
+		data.Recoveries = append(data.Recoveries, r)
+
+		if i%2 == 0 {
+			var workout WhoopWorkout
+			workout.Start = wake.Add(10 * time.Hour)
+			data.Workouts = append(data.Workouts, workout)
+		}
+	}
+
+	sleepGoal := Goal{Metric: "sleep_hours", Type: goalAverage, Target: 7.5}
+	progress := analyzer.EvaluateGoal(sleepGoal, data, start, now, now)
+	if len(progress.Weeks) != 4 {
+		t.Fatalf("Expected 4 weeks, got %d", len(progress.Weeks))
+	}
+	if progress.Weeks[0].Met || !progress.Weeks[1].Met || progress.Weeks[3].Complete {
+		t.Errorf("Unexpected weeks: %+v", progress.Weeks)
+	}
+	if progress.CurrentStreak != 3 || progress.BestStreak != 3 {
+		t.Errorf("Expected a 3-week streak including the met current week, got %d (best %d)", progress.CurrentStreak, progress.BestStreak)
+	}
+
+	recoveryGoal := Goal{Metric: "recovery", Type: goalDays, Target: 60, DaysPerWeek: 5}
+	if weeks := analyzer.EvaluateGoal(recoveryGoal, data, start, now, now).Weeks; weeks[1].Value != 7 || !weeks[1].Met {
+		t.Errorf("Expected 7 qualifying days in week two, got %+v", weeks[1])
+	}
+
+	workoutGoal := Goal{Metric: goalMetricWorkouts, Type: goalCount, Target: 4}
+	if weeks := analyzer.EvaluateGoal(workoutGoal, data, start, now, now).Weeks; weeks[0].Value != 4 || weeks[1].Value != 3 {
+		t.Errorf("Expected 4 then 3 workouts, got %+v", weeks[:2])
+	}
+}
+
+func TestGoal_ValidateDescribe(t *testing.T) {
+	if err := (Goal{Metric: "recovery", Type: goalDays, Target: 60}).Validate(); err == nil {
+		t.Error("Expected days goals to require days_per_week")
+	}
+	if err := (Goal{Metric: "hrv", Type: goalCount, Target: 3}).Validate(); err == nil {
+		t.Error("Expected count goals to require workouts")
+	}
+	goal := Goal{Metric: "recovery", Type: goalDays, Target: 60, DaysPerWeek: 5}
+	if got := goal.Describe(); got != "Recovery ≥ 60% on 5 days a week" {
+		t.Errorf("Describe() = %q", got)
+	}
+}
+
+func TestGoalStore_AddRemove(t *testing.T) {
+	store := &GoalStore{Path: filepath.Join(t.TempDir(), "goals.json")}
+	first, err := store.Add(Goal{UserID: 1, Metric: "sleep_hours", Type: goalAverage, Target: 7.5})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	second, _ := store.Add(Goal{UserID: 1, Metric: goalMetricWorkouts, Type: goalCount, Target: 3})
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("Expected IDs 1 and 2, got %d and %d", first.ID, second.ID)
+	}
+
+	if removed, err := store.Remove(1, 1); err != nil || !removed {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	goals, _ := store.List(1)
+	if len(goals) != 1 || goals[0].ID != 2 {
+		t.Errorf("Expected only goal #2 to remain, got %+v", goals)
+	}
+	if !strings.Contains(FormatGoalProgress(nil, nil, "#"), "No goals set") {
+		t.Error("Expected an empty-state message")
+	}
+}
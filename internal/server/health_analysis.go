@@ -0,0 +1,950 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HealthAnalyzer provides health data analysis for therapeutic insights
+type HealthAnalyzer struct {
+	// In-memory cache for analysis results
+	cache map[string]interface{}
+	// Sport catalog used to label workouts
+	sports *SportsCatalog
+	// Menstrual cycle configuration; nil disables cycle-aware interpretation
+	cycle *MenstrualCycleConfig
+	// Report body templates, embedded defaults plus user overrides
+	templates *ReportTemplates
+	// Output language; nil means English
+	locale *Localizer
+	// Measurement units for formatted output; empty means metric
+	units UnitSystem
+	// Stress model; empty means the baseline model
+	stressModel string
+	// Age and sex for population norms; nil keeps reports self-referenced
+	norms *PopulationNormsConfig
+	// Safety notices before serious red flags; nil turns them off
+	safety *SafetyConfig
+}
+
+// NewHealthAnalyzer creates a new health analyzer instance
+func NewHealthAnalyzer() *HealthAnalyzer {
+	return &HealthAnalyzer{
+		cache:     make(map[string]interface{}),
+		sports:    NewSportsCatalog(),
+		templates: defaultReportTemplates(),
+		safety:    &SafetyConfig{MinSeverity: "critical"},
+	}
+}
+
+// WithLocale returns a copy of the analyzer that writes insights and reports
+// in the localizer's language
+func (h *HealthAnalyzer) WithLocale(locale *Localizer) *HealthAnalyzer {
+	localized := *h
+	localized.locale = locale
+	return &localized
+}
+
+// WithUnits returns a copy of the analyzer that formats measurements in the
+// given unit system
+func (h *HealthAnalyzer) WithUnits(units UnitSystem) *HealthAnalyzer {
+	converted := *h
+	converted.units = units
+	return &converted
+}
+
+// AnalyzeHealthSummary creates a comprehensive health summary for therapy sessions.
+// baseline may be nil, in which case fixed population cutoffs are used.
+func (h *HealthAnalyzer) AnalyzeHealthSummary(ctx context.Context, recoveries []WhoopRecovery, sleepData []WhoopSleep, workouts []WhoopWorkout, cycles []WhoopCycle, startDate, endDate time.Time, userID int, baseline *PersonalBaseline) (*HealthSummary, error) {
+	thresholds := baseline.Thresholds()
+
+	// Analyze recovery trends
+	_, stage := startSpan(ctx, "analyze recovery trend", spanInternal)
+	recoveryTrend := h.analyzeRecoveryTrend(recoveries)
+	stage.End(nil)
+
+	// Analyze sleep patterns
+	_, stage = startSpan(ctx, "analyze sleep patterns", spanInternal)
+	sleepAnalysis := h.analyzeSleepPatterns(sleepData)
+	stage.End(nil)
+
+	// Analyze stress indicators
+	_, stage = startSpan(ctx, "analyze stress indicators", spanInternal)
+	stressIndicators := h.analyzeStressIndicators(recoveries, sleepData, cycles, thresholds)
+	stage.End(nil)
+
+	// Analyze activity patterns
+	_, stage = startSpan(ctx, "analyze activity patterns", spanInternal)
+	activityPatterns := h.analyzeActivityPatterns(workouts, cycles, recoveries)
+	stage.End(nil)
+
+	// Generate therapy insights
+	therapyInsights := h.generateTherapyInsights(recoveryTrend, sleepAnalysis, stressIndicators, activityPatterns)
+
+	// Detect red flags
+	_, stage = startSpan(ctx, "detect red flags", spanInternal)
+	redFlags := h.reviewRedFlags(h.detectRedFlags(recoveries, sleepData, workouts, stressIndicators, thresholds))
+	stage.End(nil)
+
+	summary := &HealthSummary{
+		UserID: userID,
+		DateRange: DateRange{
+			Start: startDate,
+			End:   endDate,
+		},
+		RecoveryTrend:    recoveryTrend,
+		SleepAnalysis:    sleepAnalysis,
+		StressIndicators: stressIndicators,
+		ActivityPatterns: activityPatterns,
+		TherapyInsights:  therapyInsights,
+		RedFlags:         redFlags,
+		Thresholds:       thresholds,
+		Population:       h.populationContext(&HealthData{Recoveries: recoveries, Sleeps: sleepData}),
+		Sparklines: buildSparklines(&HealthData{Recoveries: recoveries, Sleeps: sleepData, Workouts: workouts, Cycles: cycles},
+			endDate, int(endDate.Sub(startDate).Hours()/24)+1),
+	}
+
+	return summary, nil
+}
+
+// analyzeRecoveryTrend analyzes recovery score trends and patterns
+func (h *HealthAnalyzer) analyzeRecoveryTrend(recoveries []WhoopRecovery) RecoveryTrend {
+	// Sort by creation date
+	sort.Slice(recoveries, func(i, j int) bool {
+		return recoveries[i].CreatedAt.Before(recoveries[j].CreatedAt)
+	})
+
+	series := make([]datedValue, len(recoveries))
+	for i, recovery := range recoveries {
+		series[i] = datedValue{Date: recovery.CreatedAt, Value: recovery.Score.RecoveryScore}
+	}
+	return h.recoveryTrendOf(series)
+}
+
+// recoveryTrendOf is analyzeRecoveryTrend over recovery scores dated by
+// when each recovery was created, oldest first
+func (h *HealthAnalyzer) recoveryTrendOf(series []datedValue) RecoveryTrend {
+	if len(series) == 0 {
+		return RecoveryTrend{
+			Trend: "no_data",
+		}
+	}
+
+	scores := valuesOf(series)
+	// Last 7 days for trend analysis
+	lastSevenDays := scores[max(0, len(scores)-7):]
+
+	// Calculate average
+	average := h.calculateMean(scores)
+
+	// Calculate consistency (standard deviation)
+	consistency := 1.0 - (h.calculateStdDev(scores) / 100.0)
+	if consistency < 0 {
+		consistency = 0
+	}
+
+	// Label the trend only when the regression supports it
+	estimate := estimateTrend(series)
+	weeklyChange := 0.0
+
+	if len(scores) >= 7 {
+		firstHalf := scores[:len(scores)/2]
+		secondHalf := scores[len(scores)/2:]
+		weeklyChange = h.calculateMean(secondHalf) - h.calculateMean(firstHalf)
+	}
+
+	return RecoveryTrend{
+		AverageScore:     average,
+		Trend:            estimate.Label(true),
+		Estimate:         estimate,
+		WeeklyChange:     weeklyChange,
+		ConsistencyScore: consistency,
+		LastSevenDays:    lastSevenDays,
+	}
+}
+
+// analyzeSleepPatterns analyzes sleep quality and patterns for mental health indicators.
+// Naps are reported separately and credited against the following night's sleep debt.
+func (h *HealthAnalyzer) analyzeSleepPatterns(sleepData []WhoopSleep) SleepAnalysis {
+	if len(sleepData) == 0 {
+		return SleepAnalysis{
+			SleepQualityTrend: "no_data",
+		}
+	}
+
+	mainSleeps, naps := splitNaps(sleepData)
+	napSummary := h.analyzeNaps(naps, sleepData)
+
+	if len(mainSleeps) == 0 {
+		return SleepAnalysis{
+			SleepQualityTrend: "no_data",
+			NapCount:          napSummary.count,
+			NapsPerWeek:       napSummary.perWeek,
+			AverageNapMinutes: napSummary.averageMinutes,
+		}
+	}
+
+	var totalSleepHours []float64
+	var efficiencies []float64
+	var efficiencySeries []datedValue
+	var napCredits []float64
+	var disturbances []int
+
+	for _, sleep := range mainSleeps {
+		// Calculate sleep duration in hours
+		sleepDuration := h.sleepHours(sleep)
+		totalSleepHours = append(totalSleepHours, sleepDuration)
+
+		// Sleep efficiency (changed in V2)
+		efficiency := sleep.Score.SleepEfficiencyPercentage / 100.0 // Convert percentage to decimal
+		efficiencies = append(efficiencies, efficiency)
+		efficiencySeries = append(efficiencySeries, datedValue{Date: sleep.End, Value: sleep.Score.SleepEfficiencyPercentage})
+
+		// Naps in the preceding day count as sleep obtained
+		napCredits = append(napCredits, h.napCreditBefore(sleep, naps))
+
+		// Disturbances
+		disturbances = append(disturbances, sleep.Score.StageSummary.DisturbanceCount)
+	}
+
+	avgHours := h.calculateMean(totalSleepHours)
+	avgEfficiency := h.calculateMean(efficiencies)
+	debtLedger := h.BuildSleepDebtLedger(sleepData)
+	avgDisturbances := float64(h.calculateMeanInt(disturbances))
+
+	// Calculate consistency based on variance in sleep times
+	consistency := 1.0 - (h.calculateStdDev(totalSleepHours) / 8.0) // Normalize to 8-hour baseline
+	if consistency < 0 {
+		consistency = 0
+	}
+
+	// Naps making up for short nights
+	napCompensation := analyzeNapCompensation(debtLedger)
+
+	// Whoop's own consistency and performance, reported next to the computed ones
+	whoopConsistency, whoopPerformance := h.whoopSleepScores(mainSleeps)
+
+	// Wake-anchored bedtime from the user's own timing and sleep need
+	optimalBedtime := h.AnalyzeCircadian(mainSleeps).OptimalBedtime
+
+	// Sleep quality trend in efficiency percentage points
+	qualityEstimate := estimateTrend(efficiencySeries)
+
+	analysis := SleepAnalysis{
+		AverageHours:           avgHours,
+		AverageEfficiency:      avgEfficiency,
+		SleepDebtHours:         debtLedger.CurrentDebtHours,
+		DaysToRepay:            debtLedger.DaysToRepay,
+		DebtLedger:             debtLedger,
+		ConsistencyScore:       consistency,
+		WhoopConsistency:       whoopConsistency,
+		PerformanceScore:       h.computedSleepPerformance(mainSleeps),
+		WhoopPerformance:       whoopPerformance,
+		DisturbanceFrequency:   avgDisturbances,
+		Wake:                   h.analyzeWake(mainSleeps),
+		OptimalBedtime:         optimalBedtime,
+		SleepQualityTrend:      qualityEstimate.Label(true),
+		QualityEstimate:        qualityEstimate,
+		NapCount:               napSummary.count,
+		NapsPerWeek:            napSummary.perWeek,
+		AverageNapMinutes:      napSummary.averageMinutes,
+		NapCompensationHours:   h.calculateMean(napCredits),
+		NapNeedShare:           napCompensation.needShare,
+		NapCompensatedNights:   napCompensation.compensatedNights,
+		ChronicNapCompensation: napCompensation.chronic,
+	}
+	analysis.ScoreDivergences = sleepScoreDivergences(analysis)
+	return analysis
+}
+
+// napSummary holds aggregate nap statistics
+type napSummary struct {
+	count          int
+	perWeek        float64
+	averageMinutes float64
+}
+
+// splitNaps separates main sleeps from naps
+func splitNaps(sleepData []WhoopSleep) (mainSleeps, naps []WhoopSleep) {
+	for _, sleep := range sleepData {
+		if sleep.Nap {
+			naps = append(naps, sleep)
+		} else {
+			mainSleeps = append(mainSleeps, sleep)
+		}
+	}
+	return mainSleeps, naps
+}
+
+// analyzeNaps computes nap frequency and duration over the span covered by all sleep records
+func (h *HealthAnalyzer) analyzeNaps(naps, allSleeps []WhoopSleep) napSummary {
+	if len(naps) == 0 {
+		return napSummary{}
+	}
+
+	var minutes []float64
+	for _, nap := range naps {
+		minutes = append(minutes, h.sleepHours(nap)*60)
+	}
+
+	first, last := allSleeps[0].Start, allSleeps[0].End
+	for _, sleep := range allSleeps {
+		if sleep.Start.Before(first) {
+			first = sleep.Start
+		}
+		if sleep.End.After(last) {
+			last = sleep.End
+		}
+	}
+
+	spanDays := math.Max(last.Sub(first).Hours()/24, 1)
+
+	return napSummary{
+		count:          len(naps),
+		perWeek:        float64(len(naps)) * 7 / math.Max(spanDays, 7),
+		averageMinutes: h.calculateMean(minutes),
+	}
+}
+
+// napCreditBefore returns the hours napped in the 24 hours before a main sleep started
+func (h *HealthAnalyzer) napCreditBefore(sleep WhoopSleep, naps []WhoopSleep) float64 {
+	credit := 0.0
+	windowStart := sleep.Start.Add(-24 * time.Hour)
+	for _, nap := range naps {
+		if !nap.End.After(sleep.Start) && nap.End.After(windowStart) {
+			credit += h.sleepHours(nap)
+		}
+	}
+	return credit
+}
+
+// sleepHours returns the time actually asleep for a sleep record, in hours
+func (h *HealthAnalyzer) sleepHours(sleep WhoopSleep) float64 {
+	return float64(sleep.Score.StageSummary.TotalInBedTimeMilli-sleep.Score.StageSummary.TotalAwakeTimeMilli) / (1000 * 60 * 60)
+}
+
+// analyzeStressIndicators identifies physiological stress markers relative to
+// the given thresholds: HRV suppressed below baseline, resting HR elevated
+// above it, accumulated sleep debt, and days that piled high strain on a poor
+// recovery. The legacy stress model is used instead when configured.
+func (h *HealthAnalyzer) analyzeStressIndicators(recoveries []WhoopRecovery, sleepData []WhoopSleep, cycles []WhoopCycle, thresholds BaselineThresholds) StressIndicators {
+	if h.stressModel == stressModelLegacy {
+		return h.analyzeLegacyStressIndicators(recoveries, thresholds)
+	}
+	if len(recoveries) == 0 {
+		return StressIndicators{
+			Model:       stressModelBaseline,
+			StressLevel: "unknown",
+		}
+	}
+
+	sorted := append([]WhoopRecovery(nil), recoveries...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].CreatedAt.Before(sorted[j].CreatedAt) })
+
+	indicators := StressIndicators{Model: stressModelBaseline}
+	var hrvValues []float64
+	var restingHRValues []float64
+	luteal := h.lutealDays(sorted)
+	currentPoorStreak := 0
+	recoveryByCycle := make(map[int64]float64, len(sorted))
+
+	for _, recovery := range sorted {
+		hrv := recovery.Score.HRVRmssd
+		rhr := recovery.Score.RestingHeartRate
+		score := recovery.Score.RecoveryScore
+		recoveryByCycle[recovery.CycleID] = score
+
+		// Suppressed HRV is the autonomic stress signal; high HRV is not
+		hrvLimit := thresholds.HRVLow
+		if hrvLimit <= 0 && len(hrvValues) > 0 {
+			hrvLimit = h.calculateMean(hrvValues) * defaultHRVSuppressionRatio
+		}
+		if hrv > 0 && hrv < hrvLimit {
+			indicators.SuppressedHRVDays++
+		}
+
+		// Check for elevated resting heart rate, allowing for the expected luteal rise
+		rhrLimit := thresholds.RHRHigh
+		if rhrLimit <= 0 && len(restingHRValues) > 0 {
+			rhrLimit = h.calculateMean(restingHRValues) + defaultRHRElevationBPM
+		}
+		if rhrLimit > 0 && rhr > rhrLimit {
+			if luteal[dayKey(recovery.CreatedAt)] && rhr <= rhrLimit+lutealRHRAllowance {
+				indicators.CycleAdjustedDays++
+			} else {
+				indicators.HighRestingHRDays++
+			}
+		}
+		if hrv > 0 {
+			hrvValues = append(hrvValues, hrv)
+		}
+		restingHRValues = append(restingHRValues, rhr)
+
+		// Track poor recovery streaks
+		if score < thresholds.PoorRecovery {
+			currentPoorStreak++
+			if currentPoorStreak > indicators.PoorRecoveryStreak {
+				indicators.PoorRecoveryStreak = currentPoorStreak
+			}
+		} else {
+			currentPoorStreak = 0
+		}
+	}
+
+	if ledger := h.BuildSleepDebtLedger(sleepData); ledger.Trend != "no_data" {
+		indicators.SleepDebtHours = ledger.CurrentDebtHours
+	}
+
+	// A cycle's recovery is scored the morning it starts, so strain above
+	// tolerance on a poor recovery is load the body was not ready for
+	strainLimit := thresholds.StrainHigh
+	if strainLimit <= 0 {
+		strainLimit = defaultMismatchStrain
+	}
+	for _, cycle := range cycles {
+		score, ok := recoveryByCycle[cycle.ID]
+		if ok && score < mismatchRecovery && cycle.Score.Strain > strainLimit {
+			indicators.StrainMismatchDays++
+		}
+	}
+
+	// Calculate physiological stress score (0-100)
+	days := float64(len(sorted))
+	stressFactors := float64(indicators.SuppressedHRVDays) / days * 30                // 30% weight
+	stressFactors += float64(indicators.HighRestingHRDays) / days * 25                // 25% weight
+	stressFactors += math.Min(indicators.SleepDebtHours/stressSleepDebtHours, 1) * 20 // 20% weight
+	stressFactors += math.Min(float64(indicators.StrainMismatchDays)/days, 1) * 15    // 15% weight
+	stressFactors += math.Min(float64(indicators.PoorRecoveryStreak)/7.0, 1) * 10     // 10% weight
+
+	indicators.PhysiologicalStress = stressFactors
+	indicators.StressLevel = stressLevel(stressFactors)
+	return indicators
+}
+
+// analyzeActivityPatterns analyzes workout patterns and exercise habits, and
+// how day strain matched recovery
+func (h *HealthAnalyzer) analyzeActivityPatterns(workouts []WhoopWorkout, cycles []WhoopCycle, recoveries []WhoopRecovery) ActivityPatterns {
+	if len(workouts) == 0 && len(cycles) == 0 {
+		return ActivityPatterns{
+			IntensityBalance: "unknown",
+			OvertrainingRisk: "unknown",
+		}
+	}
+
+	weeklyWorkouts := len(workouts)
+	if len(workouts) > 0 {
+		// Estimate weekly count based on data duration
+		firstWorkout := workouts[0].Start
+		lastWorkout := workouts[len(workouts)-1].Start
+		daysDiff := lastWorkout.Sub(firstWorkout).Hours() / 24
+		if daysDiff > 0 {
+			weeklyWorkouts = int(float64(len(workouts)) * 7.0 / daysDiff)
+		}
+	}
+
+	var strainValues []float64
+	for _, workout := range workouts {
+		strainValues = append(strainValues, workout.Score.Strain)
+	}
+
+	// Add cycle strain data
+	for _, cycle := range cycles {
+		strainValues = append(strainValues, cycle.Score.Strain)
+	}
+
+	avgStrain := 0.0
+	if len(strainValues) > 0 {
+		avgStrain = h.calculateMean(strainValues)
+	}
+
+	// Calculate workout consistency
+	consistency := 0.0
+	if len(workouts) > 1 {
+		var daysBetweenWorkouts []float64
+		for i := 1; i < len(workouts); i++ {
+			days := workouts[i].Start.Sub(workouts[i-1].Start).Hours() / 24
+			daysBetweenWorkouts = append(daysBetweenWorkouts, days)
+		}
+		consistency = 1.0 - (h.calculateStdDev(daysBetweenWorkouts) / 7.0) // Normalize to weekly
+		if consistency < 0 {
+			consistency = 0
+		}
+	}
+
+	// Determine overtraining risk
+	overtrainingRisk := "low"
+	if avgStrain > 18 && weeklyWorkouts > 6 {
+		overtrainingRisk = "high"
+	} else if avgStrain > 15 && weeklyWorkouts > 5 {
+		overtrainingRisk = "moderate"
+	}
+
+	// Count active recovery days (low strain days)
+	activeRecoveryDays := 0
+	for _, strain := range strainValues {
+		if strain > 0 && strain < 10 {
+			activeRecoveryDays++
+		}
+	}
+
+	// Determine intensity balance
+	intensityBalance := "balanced"
+	highIntensityDays := 0
+	for _, strain := range strainValues {
+		if strain > 15 {
+			highIntensityDays++
+		}
+	}
+
+	if len(strainValues) > 0 {
+		highIntensityRatio := float64(highIntensityDays) / float64(len(strainValues))
+		if highIntensityRatio > 0.5 {
+			intensityBalance = "high_intensity_focused"
+		} else if highIntensityRatio < 0.2 {
+			intensityBalance = "low_intensity_focused"
+		}
+	}
+
+	return ActivityPatterns{
+		WeeklyWorkouts:     weeklyWorkouts,
+		AverageStrain:      avgStrain,
+		WorkoutConsistency: consistency,
+		OvertrainingRisk:   overtrainingRisk,
+		ActiveRecoveryDays: activeRecoveryDays,
+		IntensityBalance:   intensityBalance,
+		SportBreakdown:     h.analyzeSportBreakdown(workouts),
+		StrainBalance:      h.analyzeStrainBalance(cycles, recoveries),
+	}
+}
+
+// analyzeSportBreakdown groups workouts by sport, ordered by share of sessions
+func (h *HealthAnalyzer) analyzeSportBreakdown(workouts []WhoopWorkout) []SportShare {
+	if len(workouts) == 0 {
+		return nil
+	}
+
+	bySport := make(map[string]*SportShare)
+	for _, workout := range workouts {
+		name := h.sports.SportFor(workout)
+		share, ok := bySport[name]
+		if !ok {
+			share = &SportShare{Sport: name}
+			bySport[name] = share
+		}
+		share.Workouts++
+		share.TotalStrain += workout.Score.Strain
+	}
+
+	shares := make([]SportShare, 0, len(bySport))
+	for _, share := range bySport {
+		share.Percentage = float64(share.Workouts) / float64(len(workouts)) * 100
+		shares = append(shares, *share)
+	}
+
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Workouts != shares[j].Workouts {
+			return shares[i].Workouts > shares[j].Workouts
+		}
+		return shares[i].Sport < shares[j].Sport
+	})
+
+	return shares
+}
+
+// generateTherapyInsights creates actionable insights for therapy sessions
+func (h *HealthAnalyzer) generateTherapyInsights(recovery RecoveryTrend, sleep SleepAnalysis, stress StressIndicators, activity ActivityPatterns) []TherapyInsight {
+	var insights []TherapyInsight
+
+	// Recovery insights
+	if recovery.Trend == "declining" {
+		insights = append(insights, TherapyInsight{
+			Category:   "recovery",
+			Insight:    h.locale.T("Recovery scores have declined by %.1f%% recently, which may indicate increased stress or inadequate rest", math.Abs(recovery.WeeklyChange)),
+			Severity:   "concern",
+			Priority:   insightPriority("concern", math.Min(math.Abs(recovery.WeeklyChange)*2, 30)),
+			Topic:      "recovery_decline",
+			Actionable: true,
+			Suggestion: h.locale.T("Consider discussing stress management techniques and sleep hygiene improvements"),
+		})
+	}
+
+	if recovery.ConsistencyScore < 0.6 {
+		insights = append(insights, TherapyInsight{
+			Category:   "recovery",
+			Insight:    h.locale.T("Recovery scores show high variability, suggesting inconsistent stress levels or sleep patterns"),
+			Severity:   "info",
+			Priority:   insightPriority("info", (0.6-recovery.ConsistencyScore)*50),
+			Topic:      "recovery_variability",
+			Actionable: true,
+			Suggestion: h.locale.T("Explore daily routine consistency and identify potential stressors causing fluctuations"),
+		})
+	}
+
+	// Sleep insights
+	if sleep.AverageHours < 7 {
+		severity := "concern"
+		if sleep.AverageHours < 6 {
+			severity = "alert"
+		}
+		insights = append(insights, TherapyInsight{
+			Category:   "sleep",
+			Insight:    h.locale.T("Average sleep duration of %.1f hours is below recommended 7-9 hours", sleep.AverageHours),
+			Severity:   severity,
+			Priority:   insightPriority(severity, (7-sleep.AverageHours)*15),
+			Topic:      "sleep_duration",
+			Actionable: true,
+			Suggestion: h.locale.T("Discuss sleep barriers and develop a personalized sleep improvement plan"),
+		})
+	}
+
+	if sleep.ChronicNapCompensation {
+		severity := "info"
+		if sleep.NapNeedShare >= napNeedConcernShare {
+			severity = "concern"
+		}
+		insights = append(insights, TherapyInsight{
+			Category:   "sleep",
+			Insight:    h.locale.T("Naps are making up for short nights: %d nights fell well short of sleep need and were followed by naps, which covered %.0f%% of total sleep need", sleep.NapCompensatedNights, sleep.NapNeedShare*100),
+			Severity:   severity,
+			Priority:   insightPriority(severity, sleep.NapNeedShare*100),
+			Topic:      "nap_compensation",
+			Actionable: true,
+			Suggestion: h.locale.T("Discuss consolidating sleep into one nighttime block: protect a sleep window that covers the full need, keep a fixed wake time, and limit naps to 20-30 minutes before mid-afternoon while nighttime sleep recovers"),
+		})
+	}
+
+	if note := wakePatternNote(h.locale, sleep.Wake); note != "" {
+		insights = append(insights, TherapyInsight{
+			Category:   "sleep",
+			Insight:    note,
+			Severity:   "concern",
+			Priority:   insightPriority("concern", math.Min(sleep.Wake.AverageWASOMinutes-wasoElevatedMinutes, 30)),
+			Topic:      "sleep_maintenance",
+			Actionable: true,
+			Suggestion: h.locale.T("Explore what happens during night awakenings; CBT-I techniques such as stimulus control target sleep-maintenance insomnia"),
+		})
+	}
+
+	if sleep.AverageEfficiency < 0.85 {
+		insights = append(insights, TherapyInsight{
+			Category:   "sleep",
+			Insight:    h.locale.T("Sleep efficiency of %.1f%% indicates difficulty staying asleep", sleep.AverageEfficiency*100),
+			Severity:   "concern",
+			Priority:   insightPriority("concern", (0.85-sleep.AverageEfficiency)*150),
+			Topic:      "sleep_quality",
+			Actionable: true,
+			Suggestion: h.locale.T("Explore factors affecting sleep quality such as anxiety, environment, or habits"),
+		})
+	}
+
+	if sleep.SleepQualityTrend == "declining" {
+		insights = append(insights, TherapyInsight{
+			Category:   "sleep",
+			Insight:    h.locale.T("Sleep quality has been declining, which may impact mood and cognitive function"),
+			Severity:   "concern",
+			Priority:   insightPriority("concern", 10),
+			Topic:      "sleep_quality",
+			Actionable: true,
+			Suggestion: h.locale.T("Investigate recent life changes or stressors that might be affecting sleep"),
+		})
+	}
+
+	// Stress insights
+	if stress.StressLevel == "critical" || stress.StressLevel == "high" {
+		magnitude := 20.0
+		if stress.StressLevel == "critical" {
+			magnitude = 30
+		}
+		insights = append(insights, TherapyInsight{
+			Category:   "stress",
+			Insight:    h.locale.T("Physiological markers indicate elevated stress levels that may be impacting overall well-being"),
+			Severity:   "alert",
+			Priority:   insightPriority("alert", magnitude),
+			Topic:      "physiological_stress",
+			Actionable: true,
+			Suggestion: h.locale.T("Prioritize stress reduction techniques and consider addressing underlying stressors"),
+		})
+	}
+
+	if stress.PoorRecoveryStreak >= 3 {
+		insights = append(insights, TherapyInsight{
+			Category:   "stress",
+			Insight:    h.locale.T("Extended period of poor recovery (%d days) suggests chronic stress or burnout", stress.PoorRecoveryStreak),
+			Severity:   "alert",
+			Priority:   insightPriority("alert", math.Min(float64(stress.PoorRecoveryStreak)*3, 30)),
+			Topic:      "recovery_decline",
+			Actionable: true,
+			Suggestion: h.locale.T("Evaluate workload, relationships, and coping mechanisms for signs of overwhelm"),
+		})
+	}
+
+	// Activity insights
+	if activity.OvertrainingRisk == "high" {
+		insights = append(insights, TherapyInsight{
+			Category:   "activity",
+			Insight:    h.locale.T("High training load may be contributing to physical and mental stress"),
+			Severity:   "concern",
+			Priority:   insightPriority("concern", 15),
+			Topic:      "training_load",
+			Actionable: true,
+			Suggestion: h.locale.T("Discuss the role of exercise in stress management and potential need for recovery time"),
+		})
+	}
+
+	if activity.WeeklyWorkouts == 0 {
+		insights = append(insights, TherapyInsight{
+			Category:   "activity",
+			Insight:    h.locale.T("Lack of recorded physical activity may indicate low energy or motivation"),
+			Severity:   "info",
+			Priority:   insightPriority("info", 5),
+			Topic:      "inactivity",
+			Actionable: true,
+			Suggestion: h.locale.T("Explore barriers to physical activity and discuss gentle movement as mood support"),
+		})
+	}
+
+	return rankInsights(insights)
+}
+
+// detectRedFlags identifies critical health patterns requiring immediate attention
+func (h *HealthAnalyzer) detectRedFlags(recoveries []WhoopRecovery, sleepData []WhoopSleep, workouts []WhoopWorkout, stress StressIndicators, thresholds BaselineThresholds) []RedFlag {
+	var redFlags []RedFlag
+
+	// Critical stress indicators
+	if stress.StressLevel == "critical" {
+		redFlags = append(redFlags, RedFlag{
+			Type:           "chronic_stress",
+			Description:    h.locale.T("Multiple physiological stress markers indicate potential burnout or chronic stress condition"),
+			Severity:       "critical",
+			DetectedAt:     time.Now(),
+			Recommendation: h.locale.T("Consider immediate stress intervention and possible medical evaluation"),
+		})
+	}
+
+	// Extended poor recovery
+	if stress.PoorRecoveryStreak >= 7 {
+		redFlags = append(redFlags, RedFlag{
+			Type:           "extended_poor_recovery",
+			Description:    h.locale.T("Recovery scores have been poor for %d consecutive days", stress.PoorRecoveryStreak),
+			Severity:       "high",
+			DetectedAt:     time.Now(),
+			Recommendation: h.locale.T("Evaluate for signs of depression, anxiety, or physical health issues"),
+		})
+	}
+
+	// Severe sleep deprivation (main sleeps only; naps would drag the average down)
+	mainSleeps, _ := splitNaps(sleepData)
+	if len(mainSleeps) > 0 {
+		var recentSleep []float64
+		recentDays := 3
+		if len(mainSleeps) < recentDays {
+			recentDays = len(mainSleeps)
+		}
+
+		for i := len(mainSleeps) - recentDays; i < len(mainSleeps); i++ {
+			recentSleep = append(recentSleep, h.sleepHours(mainSleeps[i]))
+		}
+
+		avgRecentSleep := h.calculateMean(recentSleep)
+		if avgRecentSleep < thresholds.SevereSleepHours {
+			redFlags = append(redFlags, RedFlag{
+				Type:           "severe_sleep_deprivation",
+				Description:    h.locale.T("Average sleep in recent %d days is critically low (%.1f hours)", recentDays, avgRecentSleep),
+				Severity:       "critical",
+				DetectedAt:     time.Now(),
+				Recommendation: h.locale.T("Immediate sleep assessment and intervention required"),
+			})
+		}
+	}
+
+	// Sudden dramatic changes in recovery
+	if len(recoveries) >= 7 {
+		recentScores := make([]float64, 0, 3)
+		baselineScores := make([]float64, 0, 7)
+
+		for i := len(recoveries) - 3; i < len(recoveries); i++ {
+			recentScores = append(recentScores, recoveries[i].Score.RecoveryScore)
+		}
+
+		for i := len(recoveries) - 10; i < len(recoveries)-3 && i >= 0; i++ {
+			baselineScores = append(baselineScores, recoveries[i].Score.RecoveryScore)
+		}
+
+		if len(baselineScores) > 0 {
+			recentAvg := h.calculateMean(recentScores)
+			baselineAvg := h.calculateMean(baselineScores)
+
+			if recentAvg < baselineAvg-thresholds.RecoveryDrop {
+				redFlags = append(redFlags, RedFlag{
+					Type:           "dramatic_recovery_decline",
+					Description:    h.locale.T("Recovery scores dropped dramatically from %.1f to %.1f", baselineAvg, recentAvg),
+					Severity:       "high",
+					DetectedAt:     time.Now(),
+					Recommendation: h.locale.T("Investigate sudden life changes, illness, or acute stressors"),
+				})
+			}
+		}
+	}
+
+	// Overnight vital-sign anomalies (respiratory rate, SpO2, skin temperature)
+	redFlags = append(redFlags, h.vitalsRedFlags(h.AnalyzeVitals(recoveries, sleepData))...)
+
+	return redFlags
+}
+
+// Helper functions for statistical calculations
+func (h *HealthAnalyzer) calculateMean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func (h *HealthAnalyzer) calculateMeanInt(values []int) int {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sum := 0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / len(values)
+}
+
+func (h *HealthAnalyzer) calculateStdDev(values []float64) float64 {
+	if len(values) <= 1 {
+		return 0
+	}
+
+	mean := h.calculateMean(values)
+	sumSquaredDiff := 0.0
+
+	for _, v := range values {
+		diff := v - mean
+		sumSquaredDiff += diff * diff
+	}
+
+	variance := sumSquaredDiff / float64(len(values)-1)
+	return math.Sqrt(variance)
+}
+
+// FormatInsightsForTherapy formats insights into a readable text for therapy sessions
+func (h *HealthAnalyzer) FormatInsightsForTherapy(summary *HealthSummary) string {
+	var builder strings.Builder
+	t := h.locale.T
+
+	builder.WriteString(t("# Health Summary for Therapy Session") + "\n\n")
+	builder.WriteString(t("**Analysis Period:** %s to %s",
+		summary.DateRange.Start.Format("2006-01-02"),
+		summary.DateRange.End.Format("2006-01-02")) + "\n\n")
+	builder.WriteString(formatSparklines(h.locale, summary.Sparklines))
+	builder.WriteString(formatPopulationContext(h.locale, summary.Population))
+
+	// Recovery Section
+	builder.WriteString(t("## Recovery Trends") + "\n")
+	builder.WriteString(t("- **Average Score:** %.1f%% (%s trend)",
+		summary.RecoveryTrend.AverageScore, t(summary.RecoveryTrend.Trend)) + "\n")
+	builder.WriteString(t("- **Trend Estimate:** %s", formatTrendEstimate(h.locale, summary.RecoveryTrend.Estimate, "%")) + "\n")
+	builder.WriteString(t("- **Consistency:** %.1f%% (higher is better)",
+		summary.RecoveryTrend.ConsistencyScore*100) + "\n")
+	if summary.RecoveryTrend.WeeklyChange != 0 {
+		builder.WriteString(t("- **Recent Change:** %.1f points",
+			summary.RecoveryTrend.WeeklyChange) + "\n")
+	}
+	builder.WriteString("\n")
+
+	// Sleep Section
+	builder.WriteString(t("## Sleep Analysis") + "\n")
+	builder.WriteString(t("- **Average Duration:** %.1f hours", summary.SleepAnalysis.AverageHours) + "\n")
+	builder.WriteString(t("- **Sleep Efficiency:** %.1f%%", summary.SleepAnalysis.AverageEfficiency*100) + "\n")
+	builder.WriteString(t("- **Sleep Consistency:** %s", formatSleepScore(h.locale, summary.SleepAnalysis.ConsistencyScore, summary.SleepAnalysis.WhoopConsistency)) + "\n")
+	builder.WriteString(t("- **Sleep Performance:** %s", formatSleepScore(h.locale, summary.SleepAnalysis.PerformanceScore, summary.SleepAnalysis.WhoopPerformance)) + "\n")
+	builder.WriteString(t("- **Wake After Sleep Onset:** %.0f minutes (%s, %s)", summary.SleepAnalysis.Wake.AverageWASOMinutes,
+		t(summary.SleepAnalysis.Wake.Pattern), t(summary.SleepAnalysis.Wake.Trend)) + "\n")
+	builder.WriteString(t("- **Sleep Debt:** %.1f hours (%s, %s)", summary.SleepAnalysis.SleepDebtHours,
+		t(summary.SleepAnalysis.DebtLedger.Trend), formatDaysToRepay(h.locale, summary.SleepAnalysis.DebtLedger)) + "\n")
+	builder.WriteString(t("- **Quality Trend:** %s (%s)", t(summary.SleepAnalysis.SleepQualityTrend),
+		formatTrendEstimate(h.locale, summary.SleepAnalysis.QualityEstimate, "%")) + "\n")
+	if summary.SleepAnalysis.NapCount > 0 {
+		builder.WriteString(t("- **Naps:** %d (%.1f per week, %.0f min average)",
+			summary.SleepAnalysis.NapCount, summary.SleepAnalysis.NapsPerWeek, summary.SleepAnalysis.AverageNapMinutes) + "\n")
+	}
+	builder.WriteString("\n")
+
+	// Stress Section
+	builder.WriteString(t("## Stress Indicators") + "\n")
+	builder.WriteString(t("- **Stress Level:** %s", t(summary.StressIndicators.StressLevel)) + "\n")
+	if summary.StressIndicators.PoorRecoveryStreak > 0 {
+		builder.WriteString(t("- **Poor Recovery Streak:** %d days", summary.StressIndicators.PoorRecoveryStreak) + "\n")
+	}
+	builder.WriteString("\n")
+
+	// Activity Section
+	builder.WriteString(t("## Activity Patterns") + "\n")
+	builder.WriteString(t("- **Weekly Workouts:** %d", summary.ActivityPatterns.WeeklyWorkouts) + "\n")
+	builder.WriteString(t("- **Average Strain:** %.1f", summary.ActivityPatterns.AverageStrain) + "\n")
+	builder.WriteString(t("- **Overtraining Risk:** %s", t(summary.ActivityPatterns.OvertrainingRisk)) + "\n")
+	if len(summary.ActivityPatterns.SportBreakdown) > 0 {
+		builder.WriteString(t("- **Sport Mix:** %s", FormatSportBreakdown(summary.ActivityPatterns.SportBreakdown)) + "\n")
+	}
+	if balance := summary.ActivityPatterns.StrainBalance; balance != nil {
+		builder.WriteString(t("- **Strain-Recovery Balance:** %.0f/100 (%s)", balance.Score, t(balance.Tendency)) + "\n")
+	}
+	builder.WriteString("\n")
+
+	// Red Flags Section
+	if len(summary.RedFlags) > 0 {
+		builder.WriteString(t("## ⚠️ Red Flags Requiring Attention") + "\n")
+		for _, flag := range summary.RedFlags {
+			builder.WriteString(formatSafetyNotice(flag))
+			builder.WriteString(fmt.Sprintf("- **%s** (%s): %s\n",
+				t(strings.Title(strings.ReplaceAll(flag.Type, "_", " "))),
+				t(flag.Severity), flag.Description))
+			builder.WriteString(t("  *Recommendation:* %s", flag.Recommendation) + "\n")
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString(formatAcknowledgedFlags(h.locale, summary.AcknowledgedFlags, "##"))
+
+	// Therapy Insights Section
+	if len(summary.TherapyInsights) > 0 {
+		builder.WriteString(t("## 💡 Therapy Discussion Points") + "\n")
+		for _, insight := range summary.TherapyInsights {
+			severity := ""
+			switch insight.Severity {
+			case "alert":
+				severity = "⚠️ "
+			case "concern":
+				severity = "⚡ "
+			case "info":
+				severity = "ℹ️ "
+			}
+
+			builder.WriteString(fmt.Sprintf("- %s**%s**: %s\n",
+				severity, t(strings.Title(insight.Category)), insight.Insight))
+			if insight.Suggestion != "" {
+				builder.WriteString(t("  *Suggestion:* %s", insight.Suggestion) + "\n")
+			}
+		}
+		if len(summary.OmittedInsights) > 0 {
+			builder.WriteString(t("- *…and %d more lower-priority point(s): %s*",
+				len(summary.OmittedInsights), strings.Join(insightCategories(summary.OmittedInsights), ", ")) + "\n")
+		}
+	}
+
+	if summary.Thresholds.Source == "personal_baseline" {
+		builder.WriteString("\n" + t("*Stress and red-flag thresholds are relative to this user's personal baseline.*") + "\n")
+	}
+
+	return builder.String()
+}
@@ -1,4 +1,4 @@
-package main
+package server
 
 import (
 	"testing"
@@ -56,29 +56,11 @@ func TestHealthAnalyzer_AnalyzeRecoveryTrend(t *testing.T) {
 		recoveries := []WhoopRecovery{
 			{
 				CreatedAt: time.Now().AddDate(0, 0, -7),
-				Score: struct {
-					UserCalibrating  bool    `json:"user_calibrating"`
-					RecoveryScore    float64 `json:"recovery_score"`
-					RestingHeartRate float64 `json:"resting_heart_rate"`
-					HRVRmssd         float64 `json:"hrv_rmssd_milli"`
-					SkinTempCelsius  float64 `json:"skin_temp_celsius"`
-					SpO2Percentage   float64 `json:"spo2_percentage"`
-				}{
-					RecoveryScore: 75.0,
-				},
+				Score:     WhoopRecoveryScore{RecoveryScore: 75.0},
 			},
 			{
 				CreatedAt: time.Now().AddDate(0, 0, -6),
-				Score: struct {
-					UserCalibrating  bool    `json:"user_calibrating"`
-					RecoveryScore    float64 `json:"recovery_score"`
-					RestingHeartRate float64 `json:"resting_heart_rate"`
-					HRVRmssd         float64 `json:"hrv_rmssd_milli"`
-					SkinTempCelsius  float64 `json:"skin_temp_celsius"`
-					SpO2Percentage   float64 `json:"spo2_percentage"`
-				}{
-					RecoveryScore: 80.0,
-				},
+				Score:     WhoopRecoveryScore{RecoveryScore: 80.0},
 			},
 		}
 
@@ -164,3 +146,54 @@ func TestGenerateTherapyInsights(t *testing.T) {
 		}
 	}
 }
+
+func TestAnalyzeSportBreakdown(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+
+	sportIDYoga := 44
+	workouts := []WhoopWorkout{
+		{SportName: "running"},
+		{SportName: "running"},
+		{SportName: "running"},
+		{SportID: &sportIDYoga},
+	}
+
+	shares := analyzer.analyzeSportBreakdown(workouts)
+	if len(shares) != 2 {
+		t.Fatalf("Expected 2 sports, got %d", len(shares))
+	}
+
+	if shares[0].Sport != "Running" || shares[0].Percentage != 75 {
+		t.Errorf("Expected Running at 75%%, got %s at %.1f%%", shares[0].Sport, shares[0].Percentage)
+	}
+
+	if got := FormatSportBreakdown(shares); got != "75% running, 25% yoga" {
+		t.Errorf("FormatSportBreakdown() = %q", got)
+	}
+}
+
+func TestAnalyzeSleepPatterns_Naps(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+
+	night := time.Date(2025, 3, 10, 23, 0, 0, 0, time.UTC)
+	hour := int(time.Hour / time.Millisecond)
+
+	mainSleep := WhoopSleep{Start: night, End: night.Add(7 * time.Hour)}
+	mainSleep.Score.StageSummary.TotalInBedTimeMilli = 7 * hour
+	mainSleep.Score.SleepNeeded.BaselineMilli = 8 * hour
+
+	nap := WhoopSleep{Nap: true, Start: night.Add(-8 * time.Hour), End: night.Add(-7 * time.Hour)}
+	nap.Score.StageSummary.TotalInBedTimeMilli = hour
+
+	analysis := analyzer.analyzeSleepPatterns([]WhoopSleep{nap, mainSleep})
+
+	if analysis.AverageHours != 7 {
+		t.Errorf("Expected naps excluded from average duration, got %.2f", analysis.AverageHours)
+	}
+	if analysis.NapCount != 1 || analysis.AverageNapMinutes != 60 {
+		t.Errorf("Expected one 60 minute nap, got %d naps averaging %.1f minutes", analysis.NapCount, analysis.AverageNapMinutes)
+	}
+	if analysis.SleepDebtHours != 0 {
+		t.Errorf("Expected nap to pay off the 1 hour deficit, got debt %.2f", analysis.SleepDebtHours)
+	}
+}
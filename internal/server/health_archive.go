@@ -0,0 +1,358 @@
+package server
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// healthArchiveFormats are the ecosystems and clinical formats export_health_archive can target
+var healthArchiveFormats = []string{"apple_health", "google_fit", "fhir"}
+
+// healthArchiveSource names the app that recorded the data in exported files
+const healthArchiveSource = "WHOOP"
+
+// appleHealthDateLayout is the timestamp format of Apple Health's export.xml
+const appleHealthDateLayout = "2006-01-02 15:04:05 -0700"
+
+// appleWorkoutTypes maps Whoop sport names to HealthKit workout activity
+// types; anything missing is exported as HKWorkoutActivityTypeOther
+var appleWorkoutTypes = map[string]string{
+	"Running": "Running", "Cycling": "Cycling", "Spin": "Cycling", "Mountain Biking": "Cycling",
+	"Assault Bike": "Cycling", "Walking": "Walking", "Hiking/Rucking": "Hiking", "Swimming": "Swimming",
+	"Rowing": "Rowing", "Yoga": "Yoga", "Pilates": "Pilates", "Stretching": "Flexibility",
+	"Meditation": "MindAndBody", "Weightlifting": "TraditionalStrengthTraining",
+	"Powerlifting": "TraditionalStrengthTraining", "Strength Trainer": "TraditionalStrengthTraining",
+	"Functional Fitness": "FunctionalStrengthTraining", "HIIT": "HighIntensityIntervalTraining",
+	"Elliptical": "Elliptical", "Stairmaster": "StairClimbing", "Jumping Rope": "JumpRope",
+	"Boxing": "Boxing", "Kickboxing": "Kickboxing", "Martial Arts": "MartialArts",
+	"Rock Climbing": "Climbing", "Tennis": "Tennis", "Pickleball": "Pickleball", "Soccer": "Soccer",
+	"Basketball": "Basketball", "Golf": "Golf",
+}
+
+// googleFitActivityTypes maps Whoop sport names to Google Fit activity type
+// codes; anything missing is exported as 108 (other)
+var googleFitActivityTypes = map[string]int{
+	"Running": 8, "Cycling": 1, "Mountain Biking": 15, "Spin": 17, "Walking": 7,
+	"Hiking/Rucking": 35, "Swimming": 82, "Yoga": 100, "Meditation": 45, "Elliptical": 25,
+	"Weightlifting": 80, "Powerlifting": 80, "Strength Trainer": 80,
+}
+
+const (
+	googleFitSleep = 72
+	googleFitOther = 108
+)
+
+// appleHealthExport is the root of an Apple Health export.xml
+type appleHealthExport struct {
+	XMLName    xml.Name             `xml:"HealthData"`
+	Locale     string               `xml:"locale,attr"`
+	ExportDate appleHealthDate      `xml:"ExportDate"`
+	Records    []appleHealthRecord  `xml:"Record"`
+	Workouts   []appleHealthWorkout `xml:"Workout"`
+}
+
+type appleHealthDate struct {
+	Value string `xml:"value,attr"`
+}
+
+type appleHealthMetadata struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type appleHealthRecord struct {
+	Type       string                `xml:"type,attr"`
+	SourceName string                `xml:"sourceName,attr"`
+	Unit       string                `xml:"unit,attr,omitempty"`
+	Creation   string                `xml:"creationDate,attr"`
+	Start      string                `xml:"startDate,attr"`
+	End        string                `xml:"endDate,attr"`
+	Value      string                `xml:"value,attr"`
+	Metadata   []appleHealthMetadata `xml:"MetadataEntry"`
+}
+
+type appleHealthWorkout struct {
+	ActivityType string                        `xml:"workoutActivityType,attr"`
+	Duration     string                        `xml:"duration,attr"`
+	DurationUnit string                        `xml:"durationUnit,attr"`
+	Distance     string                        `xml:"totalDistance,attr,omitempty"`
+	DistanceUnit string                        `xml:"totalDistanceUnit,attr,omitempty"`
+	Energy       string                        `xml:"totalEnergyBurned,attr,omitempty"`
+	EnergyUnit   string                        `xml:"totalEnergyBurnedUnit,attr,omitempty"`
+	SourceName   string                        `xml:"sourceName,attr"`
+	Creation     string                        `xml:"creationDate,attr"`
+	Start        string                        `xml:"startDate,attr"`
+	End          string                        `xml:"endDate,attr"`
+	Metadata     []appleHealthMetadata         `xml:"MetadataEntry"`
+	Statistics   []appleHealthWorkoutStatistic `xml:"WorkoutStatistics"`
+}
+
+type appleHealthWorkoutStatistic struct {
+	Type    string `xml:"type,attr"`
+	Start   string `xml:"startDate,attr"`
+	End     string `xml:"endDate,attr"`
+	Average string `xml:"average,attr"`
+	Maximum string `xml:"maximum,attr"`
+	Unit    string `xml:"unit,attr"`
+}
+
+// googleFitExport mirrors the Google Fit REST API's session and dataset
+// resources so records can be replayed with sessions.update and
+// datasets.patch
+type googleFitExport struct {
+	Sessions []googleFitSession `json:"session"`
+	Datasets []googleFitDataset `json:"dataset"`
+}
+
+type googleFitApplication struct {
+	Name string `json:"name"`
+}
+
+type googleFitSession struct {
+	ID              string               `json:"id"`
+	Name            string               `json:"name"`
+	Description     string               `json:"description,omitempty"`
+	StartTimeMillis string               `json:"startTimeMillis"`
+	EndTimeMillis   string               `json:"endTimeMillis"`
+	ActivityType    int                  `json:"activityType"`
+	Application     googleFitApplication `json:"application"`
+}
+
+type googleFitDataset struct {
+	DataTypeName string           `json:"dataTypeName"`
+	Points       []googleFitPoint `json:"point"`
+}
+
+type googleFitPoint struct {
+	StartTimeNanos string           `json:"startTimeNanos"`
+	EndTimeNanos   string           `json:"endTimeNanos"`
+	DataTypeName   string           `json:"dataTypeName"`
+	Value          []googleFitValue `json:"value"`
+}
+
+type googleFitValue struct {
+	FpVal float64 `json:"fpVal"`
+}
+
+// scored reports whether a record's score fields are populated
+func scored(scoreState string) bool {
+	return scoreState == "" || scoreState == "SCORED"
+}
+
+// appleHealthTime formats t in the record's own timezone offset
+func appleHealthTime(t time.Time, offset string) string {
+	return localTime(t, offset).Format(appleHealthDateLayout)
+}
+
+// formatNumber renders v rounded to decimals places without trailing zeros
+func formatNumber(v float64, decimals int) string {
+	scale := math.Pow(10, float64(decimals))
+	return strconv.FormatFloat(math.Round(v*scale)/scale, 'f', -1, 64)
+}
+
+// millisToMinutes converts a Whoop millisecond duration to minutes
+func millisToMinutes(ms int) float64 {
+	return float64(ms) / float64(time.Minute/time.Millisecond)
+}
+
+// buildAppleHealthExport converts sleeps into sleep analysis and respiratory
+// rate records and workouts into HealthKit workouts. Whoop reports sleep
+// stages only as totals, so each sleep is one in-bed interval with the stage
+// durations attached as metadata rather than invented stage intervals.
+func buildAppleHealthExport(sleeps []WhoopSleep, workouts []WhoopWorkout, sports *SportsCatalog, now time.Time) appleHealthExport {
+	export := appleHealthExport{Locale: "en_US", ExportDate: appleHealthDate{Value: now.Format(appleHealthDateLayout)}}
+
+	for _, sleep := range sleeps {
+		start, end := appleHealthTime(sleep.Start, sleep.TimezoneOffset), appleHealthTime(sleep.End, sleep.TimezoneOffset)
+		record := appleHealthRecord{
+			Type:       "HKCategoryTypeIdentifierSleepAnalysis",
+			SourceName: healthArchiveSource,
+			Creation:   appleHealthTime(sleep.CreatedAt, sleep.TimezoneOffset),
+			Start:      start,
+			End:        end,
+			Value:      "HKCategoryValueSleepAnalysisInBed",
+			Metadata: []appleHealthMetadata{
+				{Key: "HKExternalUUID", Value: sleep.ID},
+				{Key: "WHOOPNap", Value: strconv.FormatBool(sleep.Nap)},
+			},
+		}
+		if !scored(sleep.ScoreState) {
+			export.Records = append(export.Records, record)
+			continue
+		}
+		stages := sleep.Score.StageSummary
+		record.Metadata = append(record.Metadata,
+			appleHealthMetadata{Key: "WHOOPLightSleepMinutes", Value: formatNumber(millisToMinutes(stages.TotalLightSleepTimeMilli), 1)},
+			appleHealthMetadata{Key: "WHOOPDeepSleepMinutes", Value: formatNumber(millisToMinutes(stages.TotalSlowWaveSleepTimeMilli), 1)},
+			appleHealthMetadata{Key: "WHOOPREMSleepMinutes", Value: formatNumber(millisToMinutes(stages.TotalRemSleepTimeMilli), 1)},
+			appleHealthMetadata{Key: "WHOOPAwakeMinutes", Value: formatNumber(millisToMinutes(stages.TotalAwakeTimeMilli), 1)},
+			appleHealthMetadata{Key: "WHOOPSleepPerformance", Value: formatNumber(sleep.Score.SleepPerformancePercentage, 1)},
+		)
+		export.Records = append(export.Records, record)
+
+		if sleep.Score.RespiratoryRate > 0 {
+			export.Records = append(export.Records, appleHealthRecord{
+				Type:       "HKQuantityTypeIdentifierRespiratoryRate",
+				SourceName: healthArchiveSource,
+				Unit:       "count/min",
+				Creation:   record.Creation,
+				Start:      start,
+				End:        end,
+				Value:      formatNumber(sleep.Score.RespiratoryRate, 2),
+			})
+		}
+	}
+
+	for _, workout := range workouts {
+		sport := sports.SportFor(workout)
+		activity, ok := appleWorkoutTypes[sport]
+		if !ok {
+			activity = "Other"
+		}
+		start, end := appleHealthTime(workout.Start, workout.TimezoneOffset), appleHealthTime(workout.End, workout.TimezoneOffset)
+		entry := appleHealthWorkout{
+			ActivityType: "HKWorkoutActivityType" + activity,
+			Duration:     formatNumber(workout.End.Sub(workout.Start).Minutes(), 3),
+			DurationUnit: "min",
+			SourceName:   healthArchiveSource,
+			Creation:     appleHealthTime(workout.CreatedAt, workout.TimezoneOffset),
+			Start:        start,
+			End:          end,
+			Metadata: []appleHealthMetadata{
+				{Key: "HKExternalUUID", Value: workout.ID},
+				{Key: "WHOOPSport", Value: sport},
+			},
+		}
+		if scored(workout.ScoreState) {
+			score := workout.Score
+			if score.DistanceMeter > 0 {
+				entry.Distance, entry.DistanceUnit = formatNumber(score.DistanceMeter/1000, 3), "km"
+			}
+			if score.Kilojoule > 0 {
+				entry.Energy, entry.EnergyUnit = formatNumber(kilojoulesToKcal(score.Kilojoule), 1), "kcal"
+			}
+			entry.Metadata = append(entry.Metadata, appleHealthMetadata{Key: "WHOOPStrain", Value: formatNumber(score.Strain, 1)})
+			if score.AverageHeartRate > 0 {
+				entry.Statistics = append(entry.Statistics, appleHealthWorkoutStatistic{
+					Type:    "HKQuantityTypeIdentifierHeartRate",
+					Start:   start,
+					End:     end,
+					Average: strconv.Itoa(score.AverageHeartRate),
+					Maximum: strconv.Itoa(score.MaxHeartRate),
+					Unit:    "count/min",
+				})
+			}
+		}
+		export.Workouts = append(export.Workouts, entry)
+	}
+	return export
+}
+
+// XML encodes the export as an Apple Health export.xml document
+func (e appleHealthExport) XML() ([]byte, error) {
+	body, err := xml.MarshalIndent(e, "", " ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(body, '\n')...), nil
+}
+
+// buildGoogleFitExport converts sleeps into sleep sessions and workouts into
+// activity sessions, with energy and distance as data points over each workout
+func buildGoogleFitExport(sleeps []WhoopSleep, workouts []WhoopWorkout, sports *SportsCatalog) googleFitExport {
+	export := googleFitExport{Sessions: []googleFitSession{}, Datasets: []googleFitDataset{}}
+	calories := googleFitDataset{DataTypeName: "com.google.calories.expended"}
+	distance := googleFitDataset{DataTypeName: "com.google.distance.delta"}
+
+	for _, sleep := range sleeps {
+		name := "Sleep"
+		if sleep.Nap {
+			name = "Nap"
+		}
+		export.Sessions = append(export.Sessions, googleFitSession{
+			ID:              "whoop-sleep-" + sleep.ID,
+			Name:            name,
+			StartTimeMillis: strconv.FormatInt(sleep.Start.UnixMilli(), 10),
+			EndTimeMillis:   strconv.FormatInt(sleep.End.UnixMilli(), 10),
+			ActivityType:    googleFitSleep,
+			Application:     googleFitApplication{Name: healthArchiveSource},
+		})
+	}
+
+	for _, workout := range workouts {
+		sport := sports.SportFor(workout)
+		activity, ok := googleFitActivityTypes[sport]
+		if !ok {
+			activity = googleFitOther
+		}
+		session := googleFitSession{
+			ID:              "whoop-workout-" + workout.ID,
+			Name:            sport,
+			StartTimeMillis: strconv.FormatInt(workout.Start.UnixMilli(), 10),
+			EndTimeMillis:   strconv.FormatInt(workout.End.UnixMilli(), 10),
+			ActivityType:    activity,
+			Application:     googleFitApplication{Name: healthArchiveSource},
+		}
+		if !scored(workout.ScoreState) {
+			export.Sessions = append(export.Sessions, session)
+			continue
+		}
+		session.Description = fmt.Sprintf("Strain %.1f, average HR %d bpm", workout.Score.Strain, workout.Score.AverageHeartRate)
+		export.Sessions = append(export.Sessions, session)
+
+		if workout.Score.Kilojoule > 0 {
+			calories.Points = append(calories.Points, googleFitWorkoutPoint(workout, calories.DataTypeName, math.Round(kilojoulesToKcal(workout.Score.Kilojoule)*10)/10))
+		}
+		if workout.Score.DistanceMeter > 0 {
+			distance.Points = append(distance.Points, googleFitWorkoutPoint(workout, distance.DataTypeName, workout.Score.DistanceMeter))
+		}
+	}
+
+	for _, dataset := range []googleFitDataset{calories, distance} {
+		if len(dataset.Points) > 0 {
+			export.Datasets = append(export.Datasets, dataset)
+		}
+	}
+	return export
+}
+
+// googleFitWorkoutPoint is one value spanning a workout
+func googleFitWorkoutPoint(workout WhoopWorkout, dataType string, value float64) googleFitPoint {
+	return googleFitPoint{
+		StartTimeNanos: strconv.FormatInt(workout.Start.UnixNano(), 10),
+		EndTimeNanos:   strconv.FormatInt(workout.End.UnixNano(), 10),
+		DataTypeName:   dataType,
+		Value:          []googleFitValue{{FpVal: value}},
+	}
+}
+
+// JSON encodes the export as indented JSON
+func (e googleFitExport) JSON() ([]byte, error) {
+	body, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(body, '\n'), nil
+}
+
+// encodeHealthArchive renders the data in the requested format, returning the
+// encoded file and its conventional file name
+func encodeHealthArchive(format string, data *HealthData, userID int, sports *SportsCatalog, now time.Time) ([]byte, string, error) {
+	switch format {
+	case "apple_health":
+		content, err := buildAppleHealthExport(data.Sleeps, data.Workouts, sports, now).XML()
+		return content, "export.xml", err
+	case "google_fit":
+		content, err := buildGoogleFitExport(data.Sleeps, data.Workouts, sports).JSON()
+		return content, "google_fit.json", err
+	case "fhir":
+		content, err := buildFHIRBundle(data, userID, now).JSON()
+		return content, "fhir_bundle.json", err
+	default:
+		return nil, "", fmt.Errorf("invalid format %q (expected apple_health, google_fit, or fhir)", format)
+	}
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func healthArchiveFixture() *HealthData {
+	start := time.Date(2024, 5, 6, 11, 0, 0, 0, time.UTC)
+
+	var sleep WhoopSleep
+	sleep.ID = "sleep-1"
+	sleep.Start, sleep.End = start.Add(-8*time.Hour), start.Add(-time.Hour)
+	sleep.CreatedAt = sleep.End
+	sleep.TimezoneOffset = "-05:00"
+	sleep.ScoreState = "SCORED"
+	sleep.Score.StageSummary.TotalRemSleepTimeMilli = 90 * 60 * 1000
+	sleep.Score.RespiratoryRate = 14.5
+
+	var run WhoopWorkout
+	run.ID = "workout-1"
+	run.SportName = "running"
+	run.Start, run.End = start, start.Add(45*time.Minute)
+	run.CreatedAt = run.End
+	run.TimezoneOffset = "-05:00"
+	run.ScoreState = "SCORED"
+	run.Score.Strain = 12.4
+	run.Score.AverageHeartRate = 152
+	run.Score.MaxHeartRate = 178
+	run.Score.Kilojoule = 2092
+	run.Score.DistanceMeter = 8000
+
+	var unscored WhoopWorkout
+	unscored.ID = "workout-2"
+	unscored.SportName = "sauna"
+	unscored.Start, unscored.End = start.Add(2*time.Hour), start.Add(150*time.Minute)
+	unscored.ScoreState = "PENDING_SCORE"
+
+	return &HealthData{Sleeps: []WhoopSleep{sleep}, Workouts: []WhoopWorkout{run, unscored}}
+}
+
+func TestAppleHealthExport(t *testing.T) {
+	data := healthArchiveFixture()
+	content, name, err := encodeHealthArchive("apple_health", data, 0, NewSportsCatalog(), time.Date(2024, 5, 7, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("encodeHealthArchive() error = %v", err)
+	}
+	if name != "export.xml" {
+		t.Errorf("Expected export.xml, got %s", name)
+	}
+
+	xml := string(content)
+	for _, want := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<Record type="HKCategoryTypeIdentifierSleepAnalysis" sourceName="WHOOP" creationDate="2024-05-06 05:00:00 -0500" startDate="2024-05-05 22:00:00 -0500" endDate="2024-05-06 05:00:00 -0500" value="HKCategoryValueSleepAnalysisInBed">`,
+		`<MetadataEntry key="WHOOPREMSleepMinutes" value="90"></MetadataEntry>`,
+		`<Record type="HKQuantityTypeIdentifierRespiratoryRate" sourceName="WHOOP" unit="count/min"`,
+		`<Workout workoutActivityType="HKWorkoutActivityTypeRunning" duration="45" durationUnit="min" totalDistance="8" totalDistanceUnit="km" totalEnergyBurned="500" totalEnergyBurnedUnit="kcal"`,
+		`average="152" maximum="178" unit="count/min"`,
+		`<Workout workoutActivityType="HKWorkoutActivityTypeOther" duration="30"`,
+	} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("Expected export.xml to contain %s\n%s", want, xml)
+		}
+	}
+	if strings.Count(xml, "totalEnergyBurned=") != 1 {
+		t.Error("Expected no energy on the unscored workout")
+	}
+}
+
+func TestGoogleFitExport(t *testing.T) {
+	content, name, err := encodeHealthArchive("google_fit", healthArchiveFixture(), 0, NewSportsCatalog(), time.Now())
+	if err != nil {
+		t.Fatalf("encodeHealthArchive() error = %v", err)
+	}
+	if name != "google_fit.json" {
+		t.Errorf("Expected google_fit.json, got %s", name)
+	}
+
+	var export googleFitExport
+	if err := json.Unmarshal(content, &export); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(export.Sessions) != 3 {
+		t.Fatalf("Expected 3 sessions, got %d", len(export.Sessions))
+	}
+	if export.Sessions[0].ActivityType != googleFitSleep || export.Sessions[1].ActivityType != 8 || export.Sessions[2].ActivityType != googleFitOther {
+		t.Errorf("Unexpected activity types: %+v", export.Sessions)
+	}
+	if export.Sessions[1].StartTimeMillis != "1714993200000" {
+		t.Errorf("Expected epoch millis start, got %s", export.Sessions[1].StartTimeMillis)
+	}
+	if len(export.Datasets) != 2 || export.Datasets[0].Points[0].Value[0].FpVal != 500 || export.Datasets[1].Points[0].Value[0].FpVal != 8000 {
+		t.Errorf("Unexpected datasets: %+v", export.Datasets)
+	}
+}
+
+func TestEncodeHealthArchive_InvalidFormat(t *testing.T) {
+	if _, _, err := encodeHealthArchive("garmin", &HealthData{}, 0, NewSportsCatalog(), time.Now()); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
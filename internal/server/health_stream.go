@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// streamScoredHealthData is fetchScoredHealthData for analyses over long
+// ranges: rather than collecting every record, it passes each page of scored
+// records to consume as the page arrives, with the healthDatasets name of the
+// records it holds, and keeps only what the data quality notes need.
+//
+// Sleeps are streamed first, keeping each sleep's timezone offset, so the
+// recovery pages that follow can carry stand-in sleeps holding just the IDs
+// and offsets localRecoveryValues dates them by. Recoveries, workouts, and
+// cycles then stream concurrently, so consume must be safe for concurrent use,
+// and the first of them to fail stops the others. Every fetch runs on the
+// server's worker pool. Memory grows with the days in the range rather than the records fetched.
+func (s *MCPServer) streamScoredHealthData(ctx context.Context, startDate, endDate time.Time, userID *int, consume func(dataset string, page *HealthData)) (quality DataQuality, err error) {
+	ctx, stage := startSpan(ctx, "stream health data", spanInternal)
+	stage.SetAttribute("whoop.days", int(endDate.Sub(startDate).Hours()/24))
+	defer func() { stage.End(err) }()
+
+	var mu sync.Mutex
+	unscored := make(UnscoredRecords)
+	covered := map[string]map[string]bool{"recovery": {}, "sleep": {}}
+	cover := func(dataset string, page *HealthData) {
+		for day := range coverageDays(page, dataset) {
+			covered[dataset][day] = true
+		}
+	}
+
+	offsets := make(map[string]string)
+	sleeps, _ := s.fetchPool.group(ctx)
+	sleeps.Go(func() error {
+		return s.whoopClient.EachSleepPage(ctx, startDate, endDate, userID, func(page []WhoopSleep) error {
+			mu.Lock()
+			data := &HealthData{Sleeps: scoredSleeps(page, unscored)}
+			for _, sleep := range data.Sleeps {
+				offsets[sleep.ID] = sleep.TimezoneOffset
+			}
+			cover("sleep", data)
+			mu.Unlock()
+			consume("sleep", data)
+			return nil
+		})
+	})
+	if err = sleeps.Wait(); err != nil {
+		return DataQuality{}, fmt.Errorf("failed to get sleep data: %w", err)
+	}
+
+	group, ctx := s.fetchPool.group(ctx)
+	streams := []struct {
+		dataset string
+		each    func() error
+	}{
+		{"recovery", func() error {
+			return s.whoopClient.EachRecoveryPage(ctx, startDate, endDate, userID, func(page []WhoopRecovery) error {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				mu.Lock()
+				data := &HealthData{Recoveries: scoredRecoveries(page, unscored)}
+				for _, recovery := range data.Recoveries {
+					data.Sleeps = append(data.Sleeps, WhoopSleep{ID: recovery.SleepID, TimezoneOffset: offsets[recovery.SleepID]})
+				}
+				cover("recovery", data)
+				mu.Unlock()
+				consume("recovery", data)
+				return nil
+			})
+		}},
+		{"workout", func() error {
+			return s.whoopClient.EachWorkoutPage(ctx, startDate, endDate, userID, func(page []WhoopWorkout) error {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				mu.Lock()
+				data := &HealthData{Workouts: scoredWorkouts(page, unscored)}
+				mu.Unlock()
+				consume("workout", data)
+				return nil
+			})
+		}},
+		{"cycle", func() error {
+			return s.whoopClient.EachCyclePage(ctx, startDate, endDate, userID, func(page []WhoopCycle) error {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				mu.Lock()
+				data := &HealthData{Cycles: scoredCycles(page, unscored)}
+				mu.Unlock()
+				consume("cycle", data)
+				return nil
+			})
+		}},
+	}
+	for _, stream := range streams {
+		dataset, each := stream.dataset, stream.each
+		group.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return nil // another dataset failed while this one queued
+			}
+			if err := each(); err != nil && !group.stopped(err) {
+				return fmt.Errorf("failed to get %s data: %w", dataset, err)
+			}
+			return nil
+		})
+	}
+	if err = group.Wait(); err != nil {
+		return DataQuality{}, err
+	}
+
+	return assessCoverage(covered, startDate, endDate, unscored, "recovery", "sleep"), nil
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStreamScoredHealthData_MatchesBatch(t *testing.T) {
+	end := time.Date(2024, 9, 30, 12, 0, 0, 0, time.UTC)
+	mock := NewSyntheticMockWhoopAPI(SyntheticOptions{Seed: 11, End: end, Days: 420, Location: time.FixedZone("", -5*60*60)})
+	server := newMockServer(t, mock)
+	start := end.AddDate(0, -14, 0)
+
+	data, batchQuality, err := server.fetchScoredHealthData(context.Background(), start, end, nil)
+	if err != nil {
+		t.Fatalf("fetchScoredHealthData() error = %v", err)
+	}
+	batch := server.healthAnalyzer.AnalyzeSeasonal(data, hemisphereNorth)
+
+	var mu sync.Mutex
+	largest := 0
+	seasons := newSeasonalAccumulator(hemisphereNorth)
+	quality, err := server.streamScoredHealthData(context.Background(), start, end, nil, func(dataset string, page *HealthData) {
+		mu.Lock()
+		largest = max(largest, len(page.Recoveries), len(page.Workouts), len(page.Cycles))
+		mu.Unlock()
+		seasons.add(dataset, page)
+	})
+	if err != nil {
+		t.Fatalf("streamScoredHealthData() error = %v", err)
+	}
+	streamed := seasons.analysis()
+
+	if largest == 0 || largest > apiPageSize {
+		t.Errorf("largest page = %d records, want 1-%d", largest, apiPageSize)
+	}
+	if !reflect.DeepEqual(quality, batchQuality) {
+		t.Errorf("streamed quality = %+v, want %+v", quality, batchQuality)
+	}
+	if !batch.Sufficient || len(streamed.Buckets) != len(batch.Buckets) || len(streamed.YearOverYear) != len(batch.YearOverYear) {
+		t.Fatalf("streamed %d buckets and %d changes, want %d and %d", len(streamed.Buckets), len(streamed.YearOverYear), len(batch.Buckets), len(batch.YearOverYear))
+	}
+	for i, bucket := range batch.Buckets {
+		got := streamed.Buckets[i]
+		if got.Label != bucket.Label || got.Days != bucket.Days || math.Abs(got.WorkoutsPerWeek-bucket.WorkoutsPerWeek) > 1e-9 {
+			t.Errorf("bucket %d = %+v, want %+v", i, got, bucket)
+		}
+		for key, mean := range bucket.Means {
+			if math.Abs(got.Means[key]-mean) > 1e-9 {
+				t.Errorf("%s %s = %v, want %v", bucket.Label, key, got.Means[key], mean)
+			}
+		}
+	}
+}
+
+func TestStreamScoredHealthData_Error(t *testing.T) {
+	mock := NewSyntheticMockWhoopAPI(SyntheticOptions{Seed: 2, Days: 60})
+	mock.Err = errors.New("upstream down")
+	server := newMockServer(t, mock)
+
+	_, err := server.streamScoredHealthData(context.Background(), time.Now().AddDate(0, -6, 0), time.Now(), nil, func(string, *HealthData) {})
+	if err == nil || !errors.Is(err, mock.Err) {
+		t.Errorf("streamScoredHealthData() error = %v, want the API failure", err)
+	}
+}
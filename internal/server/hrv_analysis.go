@@ -0,0 +1,142 @@
+package server
+
+import (
+	"time"
+)
+
+const (
+	// hrvRollingWindow is the number of days in the rolling HRV average
+	hrvRollingWindow = 7
+	// hrvSustainedDays is how many consecutive suppressed days count as sustained suppression
+	hrvSustainedDays = 3
+	// hrvSuppressedRollingPct flags a rolling average this far below baseline as suppressed
+	hrvSuppressedRollingPct = -10.0
+)
+
+// HRVAnalysis summarizes heart rate variability against the user's own baseline
+type HRVAnalysis struct {
+	Days                   int          `json:"days"`
+	Baseline               float64      `json:"baseline_ms"`
+	BaselineStdDev         float64      `json:"baseline_std_dev_ms"`
+	NormalRangeLow         float64      `json:"normal_range_low_ms"`
+	NormalRangeHigh        float64      `json:"normal_range_high_ms"`
+	CoefficientOfVariation float64      `json:"coefficient_of_variation"`
+	RollingCV              float64      `json:"rolling_7_day_cv"`
+	RollingAverage         float64      `json:"rolling_7_day_average_ms"`
+	RollingVsBaselinePct   float64      `json:"rolling_vs_baseline_pct"`
+	Latest                 float64      `json:"latest_ms"`
+	LatestDate             time.Time    `json:"latest_date"`
+	SuppressedDays         int          `json:"suppressed_days"`
+	CurrentSuppressionRun  int          `json:"current_suppression_run"`
+	LongestSuppressionRun  int          `json:"longest_suppression_run"`
+	SustainedSuppression   bool         `json:"sustained_suppression"`
+	CycleAdjusted          bool         `json:"cycle_adjusted,omitempty"` // current dip attributed to the luteal phase
+	Status                 string       `json:"status"`                   // "no_data", "suppressed", "below_baseline", "normal", "elevated"
+	Series                 []datedValue `json:"-"`
+}
+
+// AnalyzeHRV computes a personal HRV baseline and flags sustained suppression.
+// The baseline excludes the most recent week when enough history exists so the
+// rolling average is compared against prior behaviour rather than itself.
+func (h *HealthAnalyzer) AnalyzeHRV(recoveries []WhoopRecovery) HRVAnalysis {
+	series := recoveryValues(recoveries, func(r WhoopRecovery) float64 { return r.Score.HRVRmssd })
+
+	// Zero HRV means the band did not capture a reading
+	filtered := series[:0]
+	for _, v := range series {
+		if v.Value > 0 {
+			filtered = append(filtered, v)
+		}
+	}
+	series = filtered
+
+	if len(series) == 0 {
+		return HRVAnalysis{Status: "no_data"}
+	}
+
+	values := valuesOf(series)
+	baselineValues := values
+	if len(values) >= 2*hrvRollingWindow {
+		baselineValues = values[:len(values)-hrvRollingWindow]
+	}
+
+	recent := values
+	if len(values) > hrvRollingWindow {
+		recent = values[len(values)-hrvRollingWindow:]
+	}
+
+	analysis := HRVAnalysis{
+		Days:           len(values),
+		Baseline:       h.calculateMean(baselineValues),
+		BaselineStdDev: h.calculateStdDev(baselineValues),
+		RollingAverage: h.calculateMean(recent),
+		Latest:         values[len(values)-1],
+		LatestDate:     series[len(series)-1].Date,
+		Series:         series,
+	}
+	analysis.NormalRangeLow = analysis.Baseline - analysis.BaselineStdDev
+	analysis.NormalRangeHigh = analysis.Baseline + analysis.BaselineStdDev
+
+	if mean := h.calculateMean(values); mean > 0 {
+		analysis.CoefficientOfVariation = h.calculateStdDev(values) / mean * 100
+	}
+	if analysis.RollingAverage > 0 {
+		analysis.RollingCV = h.calculateStdDev(recent) / analysis.RollingAverage * 100
+	}
+	if analysis.Baseline > 0 {
+		analysis.RollingVsBaselinePct = (analysis.RollingAverage - analysis.Baseline) / analysis.Baseline * 100
+	}
+
+	// A day is suppressed when it falls below the baseline normal range
+	run := 0
+	for _, v := range values {
+		if analysis.BaselineStdDev > 0 && v < analysis.NormalRangeLow {
+			analysis.SuppressedDays++
+			run++
+			if run > analysis.LongestSuppressionRun {
+				analysis.LongestSuppressionRun = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	analysis.CurrentSuppressionRun = run
+
+	analysis.SustainedSuppression = analysis.CurrentSuppressionRun >= hrvSustainedDays ||
+		(len(values) >= 2*hrvRollingWindow && analysis.RollingVsBaselinePct <= hrvSuppressedRollingPct)
+
+	// HRV normally dips in the luteal phase; a run confined to it is not treated as stress
+	if analysis.SustainedSuppression && run > 0 {
+		if luteal := h.lutealDays(recoveries); len(luteal) > 0 {
+			withinPhase := true
+			for _, v := range series[len(series)-run:] {
+				if !luteal[dayKey(v.Date)] {
+					withinPhase = false
+					break
+				}
+			}
+			if withinPhase && analysis.RollingVsBaselinePct > 2*hrvSuppressedRollingPct {
+				analysis.SustainedSuppression = false
+				analysis.CycleAdjusted = true
+			}
+		}
+	}
+
+	switch {
+	case analysis.SustainedSuppression:
+		analysis.Status = "suppressed"
+	case analysis.RollingAverage < analysis.NormalRangeLow:
+		analysis.Status = "below_baseline"
+	case analysis.RollingAverage > analysis.NormalRangeHigh:
+		analysis.Status = "elevated"
+	default:
+		analysis.Status = "normal"
+	}
+
+	return analysis
+}
+
+// FormatHRVAnalysis renders the HRV deep-dive report
+func (h *HealthAnalyzer) FormatHRVAnalysis(analysis HRVAnalysis) string {
+	return h.templates.Render(h.locale, "hrv_analysis", analysis)
+}
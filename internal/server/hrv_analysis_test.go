@@ -0,0 +1,59 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// recoveriesWithHRV builds scored recoveries with one HRV reading per day
+func recoveriesWithHRV(values []float64) []WhoopRecovery {
+	start := time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+	recoveries := make([]WhoopRecovery, len(values))
+	for i, v := range values {
+		recoveries[i].ScoreState = "SCORED"
+		recoveries[i].CreatedAt = start.AddDate(0, 0, i)
+		recoveries[i].Score.HRVRmssd = v
+	}
+	return recoveries
+}
+
+func TestHealthAnalyzer_AnalyzeHRV(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+
+	t.Run("no data", func(t *testing.T) {
+		if got := analyzer.AnalyzeHRV(nil); got.Status != "no_data" {
+			t.Errorf("Expected no_data, got %s", got.Status)
+		}
+	})
+
+	t.Run("stable", func(t *testing.T) {
+		values := []float64{60, 62, 58, 61, 59, 63, 60, 61, 59, 62, 60, 58, 61, 60, 62, 59, 61, 60, 58, 62, 60}
+		analysis := analyzer.AnalyzeHRV(recoveriesWithHRV(values))
+		if analysis.Status != "normal" || analysis.SustainedSuppression {
+			t.Errorf("Expected normal HRV, got %+v", analysis)
+		}
+	})
+
+	t.Run("sustained suppression", func(t *testing.T) {
+		values := []float64{60, 62, 58, 61, 59, 63, 60, 61, 59, 62, 60, 58, 61, 60, 45, 44, 46, 43, 45, 44, 42}
+		analysis := analyzer.AnalyzeHRV(recoveriesWithHRV(values))
+		if !analysis.SustainedSuppression || analysis.Status != "suppressed" {
+			t.Errorf("Expected sustained suppression, got %+v", analysis)
+		}
+		if analysis.CurrentSuppressionRun != 7 {
+			t.Errorf("Expected a 7-day suppression run, got %d", analysis.CurrentSuppressionRun)
+		}
+		if analysis.Baseline < 59 || analysis.Baseline > 62 {
+			t.Errorf("Expected baseline to exclude the recent week, got %.1f", analysis.Baseline)
+		}
+
+		spanish, err := NewLocalizer("es")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out := analyzer.WithLocale(spanish).FormatHRVAnalysis(analysis); !strings.Contains(out, "Supresión sostenida de la VFC") {
+			t.Errorf("Expected the Spanish report, got:\n%s", out)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"whoop-mcp/internal/mcp"
+)
+
+// HTTPHandler serves MCP over HTTP: each POST carries one JSON-RPC message
+// and is answered with its response, or 202 Accepted when none is due.
+// Browser requests from other origins are refused so a web page cannot reach
+// a server listening on localhost.
+func (s *MCPServer) HTTPHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "MCP messages must be sent with POST", http.StatusMethodNotAllowed)
+			return
+		}
+		if !sameOrigin(r) {
+			http.Error(w, "cross-origin requests are not allowed", http.StatusForbidden)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, mcp.MaxMessageBytes))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("message exceeds %d bytes", mcp.MaxMessageBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		response := s.HandleMessage(r.Context(), body)
+		if response == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Printf("Error writing HTTP response: %v", err)
+		}
+	})
+}
+
+// sameOrigin reports whether a request comes from a non-browser client or a
+// page served by the same host
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	return err == nil && parsed.Host == r.Host
+}
+
+// ListenAndServe serves HTTPHandler on addr until ctx is done, then shuts
+// down gracefully
+func (s *MCPServer) ListenAndServe(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return s.serveListener(ctx, listener)
+}
+
+// serveListener serves HTTPHandler on listener until ctx is done
+func (s *MCPServer) serveListener(ctx context.Context, listener net.Listener) error {
+	s.applyRetention(time.Now())
+	server := &http.Server{Handler: s.HTTPHandler(), ReadHeaderTimeout: 10 * time.Second}
+
+	errs := make(chan error, 1)
+	go func() { errs <- server.Serve(listener) }()
+	log.Printf("Serving MCP over HTTP on %s", listener.Addr())
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdown); err != nil {
+			return fmt.Errorf("failed to shut down HTTP server: %w", err)
+		}
+		if err := <-errs; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,222 @@
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultLocale is the source language; its messages are the catalog keys
+const defaultLocale = "en"
+
+// embeddedCatalogs maps English source messages to translations, one file per locale
+//
+//go:embed locales/*.json
+var embeddedCatalogs embed.FS
+
+// Localizer translates report text for one locale. A nil Localizer, or one for
+// the default locale, returns the English source text.
+type Localizer struct {
+	Locale   string
+	messages map[string]string
+}
+
+// NewLocalizer loads the catalog for a locale such as "es", "es-MX", or
+// "es_MX.UTF-8"; an empty locale selects English
+func NewLocalizer(locale string) (*Localizer, error) {
+	locale = normalizeLocale(locale)
+	if locale == defaultLocale {
+		return &Localizer{Locale: defaultLocale}, nil
+	}
+
+	data, err := embeddedCatalogs.ReadFile("locales/" + locale + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("unsupported locale %q (available: %s)", locale, strings.Join(availableLocales(), ", "))
+	}
+	var messages map[string]string
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("invalid catalog for %s: %w", locale, err)
+	}
+	return &Localizer{Locale: locale, messages: messages}, nil
+}
+
+// NewLocalizerFromEnv uses WHOOP_LOCALE, defaulting to English
+func NewLocalizerFromEnv(settings Settings) (*Localizer, error) {
+	return NewLocalizer(settings("WHOOP_LOCALE"))
+}
+
+// localeProperty is the shared input schema for the locale argument
+func localeProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": fmt.Sprintf("Report language (%s); defaults to WHOOP_LOCALE or English", strings.Join(availableLocales(), ", ")),
+	}
+}
+
+// normalizeLocale reduces a locale tag to its lowercase primary language
+func normalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_.@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if locale == "" || locale == "c" || locale == "posix" {
+		return defaultLocale
+	}
+	return locale
+}
+
+// availableLocales lists English plus every embedded catalog
+func availableLocales() []string {
+	locales := []string{defaultLocale}
+	entries, _ := embeddedCatalogs.ReadDir("locales")
+	for _, entry := range entries {
+		locales = append(locales, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	return locales
+}
+
+// T translates a source message and formats it with args like fmt.Sprintf.
+// Messages without a translation fall back to the English source.
+func (l *Localizer) T(message string, args ...interface{}) string {
+	if l != nil {
+		if translated := l.messages[message]; translated != "" {
+			message = translated
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Lang returns the locale code, treating a nil Localizer as English
+func (l *Localizer) Lang() string {
+	if l == nil {
+		return defaultLocale
+	}
+	return l.Locale
+}
+
+// markMessage flags a string for extraction without translating it. Reports
+// print some enumerated values (trends, severities, stress levels) verbatim;
+// listing them in translatableValues puts them in the catalogs so T can
+// translate them at render time.
+func markMessage(message string) string {
+	return message
+}
+
+var translatableValues = []string{
+	markMessage("improving"), markMessage("declining"), markMessage("stable"), markMessage("no_data"),
+	markMessage("clear"), markMessage("repaying"), markMessage("accumulating"),
+	markMessage("balanced"), markMessage("high_intensity_focused"), markMessage("low_intensity_focused"),
+	markMessage("consolidated"), markMessage("fragmented"), markMessage("prolonged_wake"),
+	markMessage("overreaching"), markMessage("underutilizing"), markMessage("in_balance"),
+	markMessage("negligible"), markMessage("small"), markMessage("medium"), markMessage("large"),
+	markMessage("low"), markMessage("moderate"), markMessage("high"), markMessage("critical"),
+	markMessage("alert"), markMessage("concern"), markMessage("info"),
+	markMessage("Recovery"), markMessage("Sleep"), markMessage("Stress"), markMessage("Activity"),
+	markMessage("HRV (RMSSD)"), markMessage("Resting HR"), markMessage("Sleep Duration"), markMessage("Day Strain"),
+	markMessage("Chronic Stress"), markMessage("Extended Poor Recovery"), markMessage("Severe Sleep Deprivation"),
+	markMessage("Dramatic Recovery Decline"), markMessage("Respiratory Rate Spike"), markMessage("Spo2 Drop"),
+	markMessage("Skin Temp Elevation"), markMessage("Possible Illness Onset"),
+	markMessage("Sleep Performance"),
+	markMessage("Monday"), markMessage("Tuesday"), markMessage("Wednesday"), markMessage("Thursday"),
+	markMessage("Friday"), markMessage("Saturday"), markMessage("Sunday"),
+}
+
+// extractMessages collects the string literals passed to T (or a local t
+// alias) and markMessage in the Go files under dir, sorted and deduplicated
+func extractMessages(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		ast.Inspect(parsed, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			var name string
+			switch fun := call.Fun.(type) {
+			case *ast.SelectorExpr:
+				name = fun.Sel.Name
+			case *ast.Ident:
+				name = fun.Name
+			}
+			// t is the conventional local alias for a Localizer's T method
+			if name != "T" && name != "t" && name != "markMessage" {
+				return true
+			}
+			if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				if message, err := strconv.Unquote(lit.Value); err == nil {
+					seen[message] = true
+				}
+			}
+			return true
+		})
+	}
+
+	messages := make([]string, 0, len(seen))
+	for message := range seen {
+		messages = append(messages, message)
+	}
+	sort.Strings(messages)
+	return messages, nil
+}
+
+// runI18nCommand implements `whoop-mcp-server i18n extract`: it prints a
+// catalog for a locale containing every message in the source, keeping
+// existing translations and leaving new messages empty for translators
+func runI18nCommand(settings Settings, args []string) error {
+	if len(args) == 0 || args[0] != "extract" {
+		return fmt.Errorf("usage: whoop-mcp-server i18n extract -locale LOCALE [-src DIR]")
+	}
+	fs := flag.NewFlagSet("i18n extract", flag.ContinueOnError)
+	locale := fs.String("locale", "", "Locale of the catalog to update, e.g. es")
+	src := fs.String("src", ".", "Directory containing the Go sources")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if normalizeLocale(*locale) == defaultLocale {
+		return fmt.Errorf("-locale must name a non-English locale")
+	}
+
+	messages, err := extractMessages(*src)
+	if err != nil {
+		return fmt.Errorf("failed to extract messages: %w", err)
+	}
+
+	existing := make(map[string]string)
+	if localizer, err := NewLocalizer(*locale); err == nil {
+		existing = localizer.messages
+	}
+	catalog := make(map[string]string, len(messages))
+	for _, message := range messages {
+		catalog[message] = existing[message]
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(catalog)
+}
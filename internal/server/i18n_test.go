@@ -0,0 +1,92 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := map[string]string{
+		"":            "en",
+		"C":           "en",
+		"es":          "es",
+		"es-MX":       "es",
+		"es_MX.UTF-8": "es",
+		" EN_us ":     "en",
+	}
+	for input, want := range tests {
+		if got := normalizeLocale(input); got != want {
+			t.Errorf("normalizeLocale(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestNewLocalizer_Unsupported(t *testing.T) {
+	if _, err := NewLocalizer("xx"); err == nil || !strings.Contains(err.Error(), "unsupported locale") {
+		t.Errorf("Expected an unsupported locale error, got %v", err)
+	}
+}
+
+func TestLocalizer_T(t *testing.T) {
+	var english *Localizer
+	if got := english.T("- **Stress Level:** %s", "high"); got != "- **Stress Level:** high" {
+		t.Errorf("nil Localizer T() = %q", got)
+	}
+
+	spanish, err := NewLocalizer("es_ES.UTF-8")
+	if err != nil {
+		t.Fatalf("NewLocalizer() error: %v", err)
+	}
+	if got := spanish.T("- **Stress Level:** %s", spanish.T("high")); got != "- **Nivel de estrés:** alto" {
+		t.Errorf("es T() = %q", got)
+	}
+	if got := spanish.T("Not in the catalog"); got != "Not in the catalog" {
+		t.Errorf("Expected untranslated messages to fall back to English, got %q", got)
+	}
+}
+
+// Every extracted message must have a translation so Spanish sessions never
+// drop back to English mid-report
+func TestCatalogsComplete(t *testing.T) {
+	messages, err := extractMessages(".")
+	if err != nil {
+		t.Fatalf("extractMessages() error: %v", err)
+	}
+	for _, locale := range availableLocales()[1:] {
+		localizer, err := NewLocalizer(locale)
+		if err != nil {
+			t.Fatalf("NewLocalizer(%q) error: %v", locale, err)
+		}
+		for _, message := range messages {
+			if localizer.messages[message] == "" {
+				t.Errorf("%s catalog is missing %q; run `whoop-mcp-server i18n extract -locale %s`", locale, message, locale)
+			}
+		}
+	}
+}
+
+func TestLocalizedReports(t *testing.T) {
+	spanish, err := NewLocalizer("es")
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyzer := NewHealthAnalyzer().WithLocale(spanish)
+
+	summary := &HealthSummary{
+		StressIndicators: StressIndicators{StressLevel: "high"},
+		TherapyInsights:  []TherapyInsight{{Category: "sleep", Severity: "concern", Insight: "x", Suggestion: "y"}},
+	}
+	report := analyzer.FormatInsightsForTherapy(summary)
+	if !strings.Contains(report, "# Resumen de salud para la sesión de terapia") || !strings.Contains(report, "**Nivel de estrés:** alto") {
+		t.Errorf("Expected a Spanish summary, got:\n%s", report)
+	}
+
+	formatter, err := analyzer.reportFormatter(audienceTherapist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stress := formatter.Stress(DateRange{}, StressIndicators{StressLevel: "moderate"})
+	if !strings.HasPrefix(stress, "# Informe de análisis del estrés") || !strings.Contains(stress, "**Nivel general de estrés:** moderado") {
+		t.Errorf("Expected the Spanish stress template, got:\n%s", stress)
+	}
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// icsTimeLayout is the RFC 5545 UTC date-time format
+const icsTimeLayout = "20060102T150405Z"
+
+// icsMaxLineOctets is where RFC 5545 content lines must be folded
+const icsMaxLineOctets = 75
+
+// icsEvent is one VEVENT
+type icsEvent struct {
+	UID         string
+	Start, End  time.Time
+	Summary     string
+	Description string
+	Categories  string
+}
+
+// buildCalendar converts workouts, and sleeps when includeSleep is set, into
+// an iCalendar document with one event per record. Events are in UTC so
+// calendar apps place them in the viewer's own timezone.
+func buildCalendar(workouts []WhoopWorkout, sleeps []WhoopSleep, includeSleep bool, sports *SportsCatalog, units UnitSystem, now time.Time) []byte {
+	var events []icsEvent
+	for _, workout := range workouts {
+		sport := sports.SportFor(workout)
+		event := icsEvent{
+			UID:        "workout-" + workout.ID + "@whoop-mcp",
+			Start:      workout.Start,
+			End:        workout.End,
+			Summary:    sport,
+			Categories: "Workout",
+		}
+		if scored(workout.ScoreState) {
+			score := workout.Score
+			event.Summary = fmt.Sprintf("%s (strain %.1f)", sport, score.Strain)
+			details := []string{
+				fmt.Sprintf("Strain: %.1f", score.Strain),
+				fmt.Sprintf("Heart rate: %d avg / %d max bpm", score.AverageHeartRate, score.MaxHeartRate),
+				fmt.Sprintf("Calories: %.0f kcal", kilojoulesToKcal(score.Kilojoule)),
+			}
+			if score.DistanceMeter > 0 {
+				details = append(details, fmt.Sprintf("Distance: %.2f %s", units.Distance(score.DistanceMeter/1000), units.DistanceUnit()))
+			}
+			event.Description = strings.Join(details, "\n")
+		}
+		events = append(events, event)
+	}
+
+	if includeSleep {
+		for _, sleep := range sleeps {
+			event := icsEvent{
+				UID:        "sleep-" + sleep.ID + "@whoop-mcp",
+				Start:      sleep.Start,
+				End:        sleep.End,
+				Summary:    "Sleep",
+				Categories: "Sleep",
+			}
+			if sleep.Nap {
+				event.Summary = "Nap"
+			}
+			if scored(sleep.ScoreState) {
+				stages := sleep.Score.StageSummary
+				asleep := float64(stages.TotalInBedTimeMilli-stages.TotalAwakeTimeMilli) / float64(time.Hour/time.Millisecond)
+				event.Summary = fmt.Sprintf("%s (%.1fh, %.0f%% performance)", event.Summary, asleep, sleep.Score.SleepPerformancePercentage)
+				event.Description = fmt.Sprintf("Asleep: %.1f hours\nEfficiency: %.0f%%\nDisturbances: %d",
+					asleep, sleep.Score.SleepEfficiencyPercentage, stages.DisturbanceCount)
+			}
+			events = append(events, event)
+		}
+	}
+
+	var builder strings.Builder
+	writeLine := func(line string) {
+		builder.WriteString(foldICSLine(line))
+	}
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//whoop-mcp//Whoop History//EN")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("X-WR-CALNAME:Whoop")
+	stamp := now.UTC().Format(icsTimeLayout)
+	for _, event := range events {
+		writeLine("BEGIN:VEVENT")
+		writeLine("UID:" + event.UID)
+		writeLine("DTSTAMP:" + stamp)
+		writeLine("DTSTART:" + event.Start.UTC().Format(icsTimeLayout))
+		writeLine("DTEND:" + event.End.UTC().Format(icsTimeLayout))
+		writeLine("SUMMARY:" + escapeICSText(event.Summary))
+		if event.Description != "" {
+			writeLine("DESCRIPTION:" + escapeICSText(event.Description))
+		}
+		writeLine("CATEGORIES:" + event.Categories)
+		writeLine("TRANSP:TRANSPARENT")
+		writeLine("END:VEVENT")
+	}
+	writeLine("END:VCALENDAR")
+	return []byte(builder.String())
+}
+
+// escapeICSText escapes a TEXT property value per RFC 5545 section 3.3.11
+func escapeICSText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(text)
+}
+
+// foldICSLine terminates a content line with CRLF, folding it onto
+// continuation lines that start with a space once it exceeds 75 octets.
+// Folds never split a UTF-8 sequence.
+func foldICSLine(line string) string {
+	var builder strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > icsMaxLineOctets {
+			builder.WriteString("\r\n ")
+			width = 1
+		}
+		builder.WriteRune(r)
+		width += size
+	}
+	builder.WriteString("\r\n")
+	return builder.String()
+}
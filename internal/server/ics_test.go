@@ -0,0 +1,48 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildCalendar(t *testing.T) {
+	data := healthArchiveFixture()
+	data.Sleeps[0].Score.StageSummary.TotalInBedTimeMilli = 7 * 60 * 60 * 1000
+	data.Sleeps[0].Score.SleepPerformancePercentage = 88
+
+	ics := string(buildCalendar(data.Workouts, data.Sleeps, true, NewSportsCatalog(), unitsImperial, time.Date(2024, 5, 7, 0, 0, 0, 0, time.UTC)))
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"UID:workout-workout-1@whoop-mcp\r\n",
+		"DTSTAMP:20240507T000000Z\r\n",
+		"DTSTART:20240506T110000Z\r\nDTEND:20240506T114500Z\r\n",
+		"SUMMARY:Running (strain 12.4)\r\n",
+		`Distance: 4.97 mi`,
+		"SUMMARY:Sauna\r\n",
+		"SUMMARY:Sleep (7.0h\\, 88% performance)\r\n",
+		"CATEGORIES:Sleep\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("Expected calendar to contain %q\n%s", want, ics)
+		}
+	}
+
+	if withoutSleep := string(buildCalendar(data.Workouts, nil, false, NewSportsCatalog(), unitsMetric, time.Now())); strings.Contains(withoutSleep, "CATEGORIES:Sleep") {
+		t.Error("Expected no sleep events unless requested")
+	}
+}
+
+func TestFoldICSLine(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("é", 40)
+	folded := foldICSLine(line)
+	for _, part := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		if len(part) > icsMaxLineOctets {
+			t.Errorf("Line of %d octets exceeds the limit: %q", len(part), part)
+		}
+	}
+	if unfolded := strings.ReplaceAll(strings.TrimSuffix(folded, "\r\n"), "\r\n ", ""); unfolded != line {
+		t.Errorf("Unfolding did not restore the line: %q", unfolded)
+	}
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// illnessRecentNights is how many of the latest nights are averaged for the score
+	illnessRecentNights = 2
+	// illnessSignalWeight is the maximum contribution of each signal to the 0-100 score
+	illnessSignalWeight = 25.0
+)
+
+// illnessSignal describes one physiological input to the early-warning score
+type illnessSignal struct {
+	key         string
+	label       string
+	unit        string
+	higherIsBad bool
+	// minStdDev keeps z-scores sane for users with very stable baselines
+	minStdDev float64
+	// lutealAllowance is the expected rise during the luteal phase in cycle-aware mode
+	lutealAllowance float64
+	extract         func(recoveries []WhoopRecovery, sleeps []WhoopSleep) []datedValue
+}
+
+// illnessSignals are the inputs Whoop's own health monitor watches for
+var illnessSignals = []illnessSignal{
+	{
+		key: "skin_temp", label: "Skin temperature", unit: "°C", higherIsBad: true, minStdDev: 0.2, lutealAllowance: lutealTempAllowance,
+		extract: func(recoveries []WhoopRecovery, _ []WhoopSleep) []datedValue {
+			return recoveryValues(recoveries, func(r WhoopRecovery) float64 { return r.Score.SkinTempCelsius })
+		},
+	},
+	{
+		key: "respiratory_rate", label: "Respiratory rate", unit: " brpm", higherIsBad: true, minStdDev: 0.3,
+		extract: func(_ []WhoopRecovery, sleeps []WhoopSleep) []datedValue {
+			return mainSleepValues(sleeps, func(s WhoopSleep) float64 { return s.Score.RespiratoryRate })
+		},
+	},
+	{
+		key: "resting_hr", label: "Resting heart rate", unit: " bpm", higherIsBad: true, minStdDev: 1.5, lutealAllowance: lutealRHRAllowance,
+		extract: func(recoveries []WhoopRecovery, _ []WhoopSleep) []datedValue {
+			return recoveryValues(recoveries, func(r WhoopRecovery) float64 { return r.Score.RestingHeartRate })
+		},
+	},
+	{
+		key: "hrv", label: "HRV", unit: " ms", higherIsBad: false, minStdDev: 3,
+		extract: func(recoveries []WhoopRecovery, _ []WhoopSleep) []datedValue {
+			return recoveryValues(recoveries, func(r WhoopRecovery) float64 { return r.Score.HRVRmssd })
+		},
+	},
+}
+
+// IllnessRiskFactor is one signal's contribution to the early-warning score
+type IllnessRiskFactor struct {
+	Signal       string  `json:"signal"`
+	Label        string  `json:"label"`
+	Baseline     float64 `json:"baseline"`
+	Recent       float64 `json:"recent"`
+	ZScore       float64 `json:"z_score"` // positive means in the unhealthy direction
+	Contribution float64 `json:"contribution"`
+	Explanation  string  `json:"explanation"`
+	Available    bool    `json:"available"`
+}
+
+// IllnessRisk is the combined "strain on the system" early-warning score
+type IllnessRisk struct {
+	Score           float64             `json:"score"` // 0-100
+	Level           string              `json:"level"` // "insufficient_data", "low", "moderate", "elevated", "high"
+	SignalsElevated int                 `json:"signals_elevated"`
+	Factors         []IllnessRiskFactor `json:"factors"`
+	AsOf            time.Time           `json:"as_of"`
+}
+
+// PredictIllnessRisk combines deviations in skin temperature, respiratory
+// rate, resting heart rate, and HRV into a 0-100 early-warning score. Each
+// signal contributes up to 25 points as its recent average moves beyond half a
+// standard deviation from baseline in the unhealthy direction, saturating at
+// three standard deviations.
+func (h *HealthAnalyzer) PredictIllnessRisk(recoveries []WhoopRecovery, sleeps []WhoopSleep) IllnessRisk {
+	risk := IllnessRisk{Factors: []IllnessRiskFactor{}}
+	available := 0
+	luteal := h.lutealDays(recoveries)
+
+	for _, signal := range illnessSignals {
+		factor := IllnessRiskFactor{Signal: signal.key, Label: signal.label}
+
+		var readings []datedValue
+		for _, v := range signal.extract(recoveries, sleeps) {
+			if v.Value > 0 {
+				readings = append(readings, v)
+			}
+		}
+		if len(readings) < illnessRecentNights+5 {
+			factor.Explanation = "not enough history for a baseline"
+			risk.Factors = append(risk.Factors, factor)
+			continue
+		}
+
+		values := valuesOf(readings)
+		baselineValues := values[:len(values)-illnessRecentNights]
+		recentValues := values[len(values)-illnessRecentNights:]
+		if last := readings[len(readings)-1].Date; last.After(risk.AsOf) {
+			risk.AsOf = last
+		}
+
+		factor.Available = true
+		factor.Baseline = h.calculateMean(baselineValues)
+		factor.Recent = h.calculateMean(recentValues)
+		stdDev := math.Max(h.calculateStdDev(baselineValues), signal.minStdDev)
+
+		// Discount the expected luteal rise when the latest night is in that phase
+		expected := factor.Baseline
+		if luteal[dayKey(readings[len(readings)-1].Date)] {
+			expected += signal.lutealAllowance
+		}
+
+		z := (factor.Recent - expected) / stdDev
+		if !signal.higherIsBad {
+			z = -z
+		}
+		factor.ZScore = z
+		factor.Contribution = illnessSignalWeight * math.Min(math.Max((z-0.5)/2.5, 0), 1)
+
+		direction := "above"
+		if factor.Recent < factor.Baseline {
+			direction = "below"
+		}
+		recent, baseline, unit := factor.Recent, factor.Baseline, signal.unit
+		if signal.key == "skin_temp" {
+			recent, baseline, unit = h.units.Temperature(recent), h.units.Temperature(baseline), h.units.TemperatureUnit()
+		}
+		factor.Explanation = fmt.Sprintf("%.1f%s over the last %d nights vs %.1f%s baseline (%.1f SD %s)",
+			recent, unit, illnessRecentNights, baseline, unit, math.Abs(factor.ZScore), direction)
+		if expected != factor.Baseline {
+			factor.Explanation += "; adjusted for the luteal phase"
+		}
+
+		if factor.Contribution > 0 {
+			risk.SignalsElevated++
+		}
+		risk.Score += factor.Contribution
+		available++
+		risk.Factors = append(risk.Factors, factor)
+	}
+
+	switch {
+	case available < 2:
+		risk.Level = "insufficient_data"
+	case risk.Score >= 60 || (risk.SignalsElevated >= 3 && risk.Score >= 40):
+		risk.Level = "high"
+	case risk.Score >= 40:
+		risk.Level = "elevated"
+	case risk.Score >= 20:
+		risk.Level = "moderate"
+	default:
+		risk.Level = "low"
+	}
+
+	return risk
+}
+
+// FormatIllnessRisk renders the early-warning score with per-signal explanations
+func (h *HealthAnalyzer) FormatIllnessRisk(risk IllnessRisk) string {
+	var builder strings.Builder
+	builder.WriteString("# Illness Early-Warning Score\n\n")
+
+	if risk.Level == "insufficient_data" {
+		builder.WriteString("Not enough overnight history to build personal baselines. At least a week of scored nights is needed.\n")
+		return builder.String()
+	}
+
+	icon := map[string]string{"low": "🟢", "moderate": "🟡", "elevated": "🟠", "high": "🔴"}[risk.Level]
+	builder.WriteString(fmt.Sprintf("**Score:** %.0f/100 %s %s\n", risk.Score, icon, strings.ToUpper(risk.Level)))
+	builder.WriteString(fmt.Sprintf("**Signals deviating:** %d of %d\n", risk.SignalsElevated, len(risk.Factors)))
+	if !risk.AsOf.IsZero() {
+		builder.WriteString(fmt.Sprintf("**Most recent night:** %s\n", risk.AsOf.Format("Mon Jan 2")))
+	}
+	builder.WriteString("\n## Contributing Signals\n")
+
+	for _, factor := range risk.Factors {
+		if !factor.Available {
+			builder.WriteString(fmt.Sprintf("- **%s:** %s\n", factor.Label, factor.Explanation))
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("- **%s** (+%.0f): %s\n", factor.Label, factor.Contribution, factor.Explanation))
+	}
+
+	builder.WriteString("\n## What This Means\n")
+	switch risk.Level {
+	case "high":
+		builder.WriteString("Several systems are under strain at once, the pattern typically seen 1-2 days before cold or flu symptoms. Rest, hydrate, skip hard training, and watch for symptoms.\n")
+	case "elevated":
+		builder.WriteString("The body is working harder than usual overnight. This can be early illness, but also alcohol, travel, or acute stress. Go easy today and re-check tomorrow.\n")
+	case "moderate":
+		builder.WriteString("One or two signals are drifting. Usually benign, but worth noting alongside how you feel.\n")
+	default:
+		builder.WriteString("Overnight physiology is in line with your baselines.\n")
+	}
+
+	builder.WriteString("\n*This score is an early-warning heuristic, not a diagnosis.*\n")
+	return builder.String()
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_PredictIllnessRisk(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	start := time.Date(2024, 1, 10, 7, 0, 0, 0, time.UTC)
+
+	build := func(sick bool) ([]WhoopRecovery, []WhoopSleep) {
+		var recoveries []WhoopRecovery
+		var sleeps []WhoopSleep
+		for i := 0; i < 20; i++ {
+			var recovery WhoopRecovery
+			recovery.ScoreState = "SCORED"
+			recovery.CreatedAt = start.AddDate(0, 0, i)
+			recovery.Score.SkinTempCelsius = 33.4 + float64(i%3)*0.1
+			recovery.Score.RestingHeartRate = 52 + float64(i%2)
+			recovery.Score.HRVRmssd = 65 + float64(i%4)
+
+			var sleep WhoopSleep
+			sleep.ScoreState = "SCORED"
+			sleep.End = recovery.CreatedAt
+			sleep.Score.RespiratoryRate = 14.2 + float64(i%2)*0.2
+
+			if sick && i >= 18 {
+				recovery.Score.SkinTempCelsius = 34.6
+				recovery.Score.RestingHeartRate = 60
+				recovery.Score.HRVRmssd = 45
+				sleep.Score.RespiratoryRate = 16
+			}
+			recoveries = append(recoveries, recovery)
+			sleeps = append(sleeps, sleep)
+		}
+		return recoveries, sleeps
+	}
+
+	recoveries, sleeps := build(false)
+	if risk := analyzer.PredictIllnessRisk(recoveries, sleeps); risk.Level != "low" {
+		t.Errorf("Expected low risk for stable vitals, got %s (%.0f)", risk.Level, risk.Score)
+	}
+
+	recoveries, sleeps = build(true)
+	risk := analyzer.PredictIllnessRisk(recoveries, sleeps)
+	if risk.Level != "high" || risk.SignalsElevated != 4 {
+		t.Errorf("Expected high risk with 4 signals, got %s with %d (%.0f)", risk.Level, risk.SignalsElevated, risk.Score)
+	}
+
+	if risk := analyzer.PredictIllnessRisk(nil, nil); risk.Level != "insufficient_data" {
+		t.Errorf("Expected insufficient_data without history, got %s", risk.Level)
+	}
+}
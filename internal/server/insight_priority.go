@@ -0,0 +1,100 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	defaultMaxInsights = 5
+	maxInsightsLimit   = 20
+)
+
+// insightSeverityRank orders severities for the min_severity filter
+var insightSeverityRank = map[string]int{"info": 0, "concern": 1, "alert": 2}
+
+// insightSeverityBase is the starting priority for each severity; the
+// magnitude of the underlying signal adds up to 30 points on top
+var insightSeverityBase = map[string]float64{"info": 10, "concern": 35, "alert": 60}
+
+// insightPriority scores an insight from 0 to 100 so an alert always outranks
+// a concern and a concern always outranks an info point
+func insightPriority(severity string, magnitude float64) float64 {
+	return clamp(insightSeverityBase[severity]+clamp(magnitude, 0, 30), 0, 100)
+}
+
+// rankInsights merges insights that share a topic, keeping the most pressing
+// one and recording the others' text as related, then sorts by priority
+func rankInsights(insights []TherapyInsight) []TherapyInsight {
+	var ranked []TherapyInsight
+	byTopic := make(map[string]int)
+	for _, insight := range insights {
+		i, seen := byTopic[insight.Topic]
+		if insight.Topic == "" || !seen {
+			if insight.Topic != "" {
+				byTopic[insight.Topic] = len(ranked)
+			}
+			ranked = append(ranked, insight)
+			continue
+		}
+		kept := ranked[i]
+		if insight.Priority > kept.Priority {
+			kept, insight = insight, kept
+		}
+		kept.Related = append(append(kept.Related, insight.Insight), insight.Related...)
+		ranked[i] = kept
+	}
+
+	sort.SliceStable(ranked, func(a, b int) bool {
+		return ranked[a].Priority > ranked[b].Priority
+	})
+	return ranked
+}
+
+// PrioritizeInsights drops insights below minSeverity and caps the rest at
+// limit, returning the kept insights and the lower-priority overflow.
+// insights must already be ranked.
+func PrioritizeInsights(insights []TherapyInsight, minSeverity string, limit int) ([]TherapyInsight, []TherapyInsight) {
+	minRank := insightSeverityRank[minSeverity]
+	var filtered []TherapyInsight
+	for _, insight := range insights {
+		if insightSeverityRank[insight.Severity] >= minRank {
+			filtered = append(filtered, insight)
+		}
+	}
+	if limit <= 0 || len(filtered) <= limit {
+		return filtered, nil
+	}
+	return filtered[:limit], filtered[limit:]
+}
+
+// insightLimits validates the min_severity and max_insights arguments,
+// applying the default cap when max_insights is omitted
+func insightLimits(minSeverity string, maxInsights int) (string, int, error) {
+	if minSeverity == "" {
+		minSeverity = "info"
+	}
+	if _, ok := insightSeverityRank[minSeverity]; !ok {
+		return "", 0, fmt.Errorf("min_severity must be one of info, concern, alert")
+	}
+	if maxInsights == 0 {
+		maxInsights = defaultMaxInsights
+	}
+	if maxInsights < 1 || maxInsights > maxInsightsLimit {
+		return "", 0, fmt.Errorf("max_insights must be between 1 and %d", maxInsightsLimit)
+	}
+	return minSeverity, maxInsights, nil
+}
+
+// insightCategories lists the distinct categories of insights in order
+func insightCategories(insights []TherapyInsight) []string {
+	var categories []string
+	seen := make(map[string]bool)
+	for _, insight := range insights {
+		if !seen[insight.Category] {
+			seen[insight.Category] = true
+			categories = append(categories, insight.Category)
+		}
+	}
+	return categories
+}
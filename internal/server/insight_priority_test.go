@@ -0,0 +1,90 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateTherapyInsights_RankedAndDeduplicated(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+
+	insights := analyzer.generateTherapyInsights(
+		RecoveryTrend{Trend: "declining", WeeklyChange: -12, ConsistencyScore: 0.4},
+		SleepAnalysis{AverageHours: 5.5, AverageEfficiency: 0.75, SleepQualityTrend: "declining"},
+		StressIndicators{StressLevel: "high", PoorRecoveryStreak: 5},
+		ActivityPatterns{WeeklyWorkouts: 0, OvertrainingRisk: "low"},
+	)
+
+	topics := make(map[string]bool)
+	for i, insight := range insights {
+		if topics[insight.Topic] {
+			t.Errorf("Topic %s listed twice", insight.Topic)
+		}
+		topics[insight.Topic] = true
+		if i > 0 && insight.Priority > insights[i-1].Priority {
+			t.Errorf("Insight %d (%.0f) outranks insight %d (%.0f)", i, insight.Priority, i-1, insights[i-1].Priority)
+		}
+	}
+
+	// The 5-day poor-recovery streak (alert) absorbs the recovery decline (concern)
+	for _, insight := range insights {
+		if insight.Topic == "recovery_decline" {
+			if insight.Severity != "alert" || len(insight.Related) != 1 {
+				t.Errorf("Expected the alert to keep the decline as related, got %+v", insight)
+			}
+		}
+		if insight.Topic == "sleep_quality" && len(insight.Related) != 1 {
+			t.Errorf("Expected efficiency and quality trend to merge, got %+v", insight)
+		}
+	}
+	if insights[0].Severity != "alert" || insights[len(insights)-1].Severity != "info" {
+		t.Errorf("Expected alerts first and info last, got %s ... %s", insights[0].Severity, insights[len(insights)-1].Severity)
+	}
+}
+
+func TestPrioritizeInsights(t *testing.T) {
+	insights := rankInsights([]TherapyInsight{
+		{Category: "activity", Severity: "info", Priority: insightPriority("info", 5)},
+		{Category: "sleep", Severity: "alert", Priority: insightPriority("alert", 15)},
+		{Category: "recovery", Severity: "concern", Priority: insightPriority("concern", 20)},
+		{Category: "stress", Severity: "alert", Priority: insightPriority("alert", 30)},
+	})
+
+	kept, omitted := PrioritizeInsights(insights, "info", 2)
+	if len(kept) != 2 || kept[0].Category != "stress" || kept[1].Category != "sleep" {
+		t.Errorf("Expected stress then sleep, got %+v", kept)
+	}
+	if got := strings.Join(insightCategories(omitted), ","); got != "recovery,activity" {
+		t.Errorf("Omitted categories = %s, want recovery,activity", got)
+	}
+
+	kept, omitted = PrioritizeInsights(insights, "concern", 5)
+	if len(kept) != 3 || omitted != nil {
+		t.Errorf("Expected 3 insights at concern or above with no overflow, got %d and %d", len(kept), len(omitted))
+	}
+}
+
+func TestInsightLimits(t *testing.T) {
+	if severity, limit, err := insightLimits("", 0); err != nil || severity != "info" || limit != defaultMaxInsights {
+		t.Errorf("insightLimits defaults = %s, %d, %v", severity, limit, err)
+	}
+	if _, _, err := insightLimits("urgent", 3); err == nil {
+		t.Error("Expected an error for an unknown severity")
+	}
+	if _, _, err := insightLimits("alert", maxInsightsLimit+1); err == nil {
+		t.Error("Expected an error for max_insights above the limit")
+	}
+}
+
+func TestFormatInsightsForTherapy_Overflow(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	summary := &HealthSummary{
+		TherapyInsights: []TherapyInsight{{Category: "stress", Insight: "Elevated stress", Severity: "alert"}},
+		OmittedInsights: []TherapyInsight{{Category: "sleep"}, {Category: "activity"}, {Category: "sleep"}},
+	}
+
+	report := analyzer.FormatInsightsForTherapy(summary)
+	if !strings.Contains(report, "…and 3 more lower-priority point(s): sleep, activity") {
+		t.Errorf("Expected an overflow summary, got:\n%s", report)
+	}
+}
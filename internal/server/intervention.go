@@ -0,0 +1,191 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+const (
+	// defaultInterventionWindow is the days compared on each side of the start date
+	defaultInterventionWindow = 28
+	// minInterventionDays is the fewest days per side for an effect estimate
+	minInterventionDays = 5
+)
+
+// interventionMetrics are the outcomes compared before and after an intervention
+var interventionMetrics = []string{"sleep_hours", "sleep_performance", "hrv", "resting_hr", "recovery"}
+
+// InterventionEffect is the before/after change in one metric
+type InterventionEffect struct {
+	Metric        string  `json:"metric"`
+	Label         string  `json:"label"`
+	Unit          string  `json:"unit"`
+	PreMean       float64 `json:"pre_mean"`
+	PostMean      float64 `json:"post_mean"`
+	Delta         float64 `json:"delta"`
+	PercentChange float64 `json:"percent_change"`
+	PreCount      int     `json:"pre_count"`
+	PostCount     int     `json:"post_count"`
+	CohensD       float64 `json:"cohens_d"`
+	Magnitude     string  `json:"magnitude"` // "negligible", "small", "medium", "large"
+	PValue        float64 `json:"p_value"`
+	Direction     string  `json:"direction"` // "better", "worse", "unchanged", "insufficient_data"
+}
+
+// InterventionAnalysis compares windows before and after an intervention started
+type InterventionAnalysis struct {
+	Name         string               `json:"name"`
+	Start        string               `json:"start"`
+	WashoutDays  int                  `json:"washout_days"`
+	Pre          DateRange            `json:"pre"`
+	Post         DateRange            `json:"post"`
+	PostComplete bool                 `json:"post_complete"`
+	Effects      []InterventionEffect `json:"effects"`
+}
+
+// interventionWindows returns the pre window ending the day before start and
+// the post window beginning after the washout, each windowDays long
+func interventionWindows(start time.Time, windowDays, washoutDays int) (DateRange, DateRange) {
+	pre := DateRange{Start: start.AddDate(0, 0, -windowDays), End: start.AddDate(0, 0, -1)}
+	postStart := start.AddDate(0, 0, washoutDays)
+	post := DateRange{Start: postStart, End: postStart.AddDate(0, 0, windowDays-1)}
+	return pre, post
+}
+
+// AnalyzeIntervention compares sleep, HRV, resting HR, and recovery in the
+// windows before and after an intervention, skipping washout days after the
+// start while the change takes effect. asOf marks how much of the post window
+// has elapsed.
+func (h *HealthAnalyzer) AnalyzeIntervention(data *HealthData, name string, start time.Time, windowDays, washoutDays int, asOf time.Time) InterventionAnalysis {
+	pre, post := interventionWindows(start, windowDays, washoutDays)
+	analysis := InterventionAnalysis{
+		Name:         name,
+		Start:        dayKey(start),
+		WashoutDays:  washoutDays,
+		Pre:          pre,
+		Post:         post,
+		PostComplete: dayKey(asOf) > dayKey(post.End),
+	}
+
+	inRange := func(series []datedValue, r DateRange) []float64 {
+		var values []float64
+		for _, v := range series {
+			if day := dayKey(v.Date); day >= dayKey(r.Start) && day <= dayKey(r.End) {
+				values = append(values, v.Value)
+			}
+		}
+		return values
+	}
+
+	for _, key := range interventionMetrics {
+		metric, _ := lookupMetric(key)
+		series := metric.extract(data)
+		before := inRange(series, pre)
+		after := inRange(series, post)
+
+		effect := InterventionEffect{
+			Metric:    metric.Key,
+			Label:     metric.Label,
+			Unit:      metric.Unit,
+			PreCount:  len(before),
+			PostCount: len(after),
+			PValue:    1,
+			Magnitude: "negligible",
+			Direction: "insufficient_data",
+		}
+		if len(before) < minInterventionDays || len(after) < minInterventionDays {
+			analysis.Effects = append(analysis.Effects, effect)
+			continue
+		}
+
+		effect.PreMean = h.calculateMean(before)
+		effect.PostMean = h.calculateMean(after)
+		effect.Delta = effect.PostMean - effect.PreMean
+		if effect.PreMean != 0 {
+			effect.PercentChange = effect.Delta / math.Abs(effect.PreMean) * 100
+		}
+		effect.CohensD = stats.CohensD(before, after)
+		effect.Magnitude = stats.EffectSizeMagnitude(effect.CohensD)
+		_, _, effect.PValue = stats.WelchTTest(before, after)
+
+		switch {
+		case effect.PValue >= significanceLevel || effect.Magnitude == "negligible":
+			effect.Direction = "unchanged"
+		case (effect.Delta > 0) == metric.HigherIsBetter:
+			effect.Direction = "better"
+		default:
+			effect.Direction = "worse"
+		}
+		analysis.Effects = append(analysis.Effects, effect)
+	}
+
+	return analysis
+}
+
+// FormatInterventionAnalysis renders the before/after effects of an intervention
+func (h *HealthAnalyzer) FormatInterventionAnalysis(analysis InterventionAnalysis) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("# Intervention Analysis: %s\n\n", analysis.Name))
+	builder.WriteString(fmt.Sprintf("**Started:** %s\n", analysis.Start))
+	builder.WriteString(fmt.Sprintf("**Before:** %s to %s\n", dayKey(analysis.Pre.Start), dayKey(analysis.Pre.End)))
+	if analysis.WashoutDays > 0 {
+		builder.WriteString(fmt.Sprintf("**Washout:** %d day(s) excluded after the start\n", analysis.WashoutDays))
+	}
+	builder.WriteString(fmt.Sprintf("**After:** %s to %s", dayKey(analysis.Post.Start), dayKey(analysis.Post.End)))
+	if !analysis.PostComplete {
+		builder.WriteString(" (in progress)")
+	}
+	builder.WriteString("\n\n")
+
+	builder.WriteString("| Metric | Before | After | Change | Effect size (d) | p-value | |\n")
+	builder.WriteString("|---|---|---|---|---|---|---|\n")
+
+	var better, worse []string
+	for _, effect := range analysis.Effects {
+		if effect.Direction == "insufficient_data" {
+			builder.WriteString(fmt.Sprintf("| %s | n=%d | n=%d | - | - | - | too few days |\n", effect.Label, effect.PreCount, effect.PostCount))
+			continue
+		}
+
+		marker := ""
+		switch effect.Direction {
+		case "better":
+			marker = "✅ better"
+			better = append(better, fmt.Sprintf("%s (%s effect)", effect.Label, effect.Magnitude))
+		case "worse":
+			marker = "⚠️ worse"
+			worse = append(worse, fmt.Sprintf("%s (%s effect)", effect.Label, effect.Magnitude))
+		}
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s | %+.1f%s (%+.0f%%) | %+.2f %s | %.3f | %s |\n",
+			effect.Label,
+			formatMetricMean(effect.PreMean, effect.PreCount, effect.Unit),
+			formatMetricMean(effect.PostMean, effect.PostCount, effect.Unit),
+			effect.Delta, effect.Unit, effect.PercentChange,
+			effect.CohensD, effect.Magnitude, effect.PValue, marker))
+	}
+
+	builder.WriteString("\n## Summary\n\n")
+	switch {
+	case len(better) == 0 && len(worse) == 0:
+		builder.WriteString("No metric shows a meaningful, statistically reliable change since the intervention started.")
+		if !analysis.PostComplete {
+			builder.WriteString(" The after window is still filling; effects may emerge with more days.")
+		}
+		builder.WriteString("\n")
+	default:
+		if len(better) > 0 {
+			builder.WriteString(fmt.Sprintf("- **Improved:** %s\n", strings.Join(better, ", ")))
+		}
+		if len(worse) > 0 {
+			builder.WriteString(fmt.Sprintf("- **Declined:** %s\n", strings.Join(worse, ", ")))
+		}
+	}
+
+	builder.WriteString(fmt.Sprintf("\n*Effect sizes are Cohen's d (0.2 small, 0.5 medium, 0.8 large); changes count when p < %.2f and d is at least small. "+
+		"A before/after comparison cannot rule out other changes made at the same time, and medication decisions belong with the prescriber.*\n", significanceLevel))
+	return builder.String()
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_AnalyzeIntervention(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	hour := int(time.Hour / time.Millisecond)
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	intervention := start.AddDate(0, 0, 14)
+
+	data := &HealthData{}
+	for i := 0; i < 35; i++ {
+		wake := start.AddDate(0, 0, i).Add(7 * time.Hour)
+		jitter := float64(i%3) - 1
+
+		hrv := 50 + 2*jitter
+		hours := 6.5 + 0.2*jitter
+		if !wake.Before(intervention.AddDate(0, 0, 7)) {
+			hrv += 10 // effect after a one-week washout
+			hours += 1
+		} else if !wake.Before(intervention) {
+			hrv -= 20 // side effects during the washout should be ignored
+		}
+
+		var sleep WhoopSleep
+		sleep.ID = fmt.Sprintf("sleep-%d", i)
+		sleep.Start = wake.Add(-time.Duration(hours * float64(time.Hour)))
+		sleep.End = wake
+		sleep.Score.StageSummary.TotalInBedTimeMilli = int(hours * float64(hour))
+		data.Sleeps = append(data.Sleeps, sleep)
+
+		var recovery WhoopRecovery
+		recovery.SleepID = sleep.ID
+		recovery.CreatedAt = wake
+		recovery.Score.HRVRmssd = hrv
+		recovery.Score.RecoveryScore = 60 + jitter
+		recovery.Score.RestingHeartRate = 55 + jitter
+		data.Recoveries = append(data.Recoveries, recovery)
+	}
+
+	analysis := analyzer.AnalyzeIntervention(data, "magnesium", intervention, 14, 7, start.AddDate(0, 0, 40))
+	if !analysis.PostComplete {
+		t.Error("Expected the after window to be complete")
+	}
+
+	effects := make(map[string]InterventionEffect)
+	for _, effect := range analysis.Effects {
+		effects[effect.Metric] = effect
+	}
+	if hrv := effects["hrv"]; hrv.Direction != "better" || hrv.Magnitude != "large" || hrv.PreCount != 14 || hrv.PostCount != 14 {
+		t.Errorf("Expected a large HRV improvement over 14 days each side, got %+v", hrv)
+	}
+	if sleep := effects["sleep_hours"]; sleep.Direction != "better" {
+		t.Errorf("Expected longer sleep, got %+v", sleep)
+	}
+	if recovery := effects["recovery"]; recovery.Direction != "unchanged" {
+		t.Errorf("Expected unchanged recovery, got %+v", recovery)
+	}
+}
@@ -0,0 +1,249 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+const (
+	// defaultLateExerciseWindow is the hours before bed that count as late training
+	defaultLateExerciseWindow = 3.0
+	// maxLateExerciseGap is the furthest before bed a workout is linked to that night
+	maxLateExerciseGap = 8.0
+	// minLateExerciseNights is the fewest scored nights needed for the analysis
+	minLateExerciseNights = 14
+	// minCloseNights is the fewest nights after late training needed for a comparison
+	minCloseNights = 3
+	// disruptiveEffectSize is the Cohen's d in the harmful direction that counts as disruption
+	disruptiveEffectSize = 0.5
+)
+
+// SleepInterferenceMetric compares one sleep measure after late training with other nights
+type SleepInterferenceMetric struct {
+	Metric     string  `json:"metric"`
+	Label      string  `json:"label"`
+	Unit       string  `json:"unit"`
+	Close      float64 `json:"close_mean"`
+	Other      float64 `json:"other_mean"`
+	CloseCount int     `json:"close_count"`
+	OtherCount int     `json:"other_count"`
+	CohensD    float64 `json:"cohens_d"`
+	PValue     float64 `json:"p_value"`
+	Disrupted  bool    `json:"disrupted"`
+}
+
+// LateExerciseAnalysis relates workout timing to that night's sleep
+type LateExerciseAnalysis struct {
+	Status         string                    `json:"status"` // "ok", "insufficient_data", "no_late_workouts"
+	Nights         int                       `json:"nights"`
+	CloseNights    int                       `json:"close_nights"`
+	WindowHours    float64                   `json:"window_hours"`
+	Metrics        []SleepInterferenceMetric `json:"metrics"`
+	TypicalBedtime string                    `json:"typical_bedtime"`
+	CutoffHours    float64                   `json:"cutoff_hours"` // 0 when no disruptive gap was found
+	TrainBefore    string                    `json:"train_before,omitempty"`
+}
+
+// exerciseNight is one main sleep with the gap since the last workout ended
+type exerciseNight struct {
+	gapHours     float64 // -1 when no workout ended within maxLateExerciseGap
+	awakeMinutes float64
+	efficiency   float64
+	disturbances float64
+}
+
+// sleepInterferenceMeasures are compared between late-training nights and the rest;
+// harmful is the sign of a harmful change
+var sleepInterferenceMeasures = []struct {
+	key, label, unit string
+	harmful          float64
+	value            func(exerciseNight) float64
+}{
+	{"awake_minutes", "Time Awake in Bed (latency proxy)", " min", 1, func(n exerciseNight) float64 { return n.awakeMinutes }},
+	{"efficiency", "Sleep Efficiency", "%", -1, func(n exerciseNight) float64 { return n.efficiency }},
+	{"disturbances", "Disturbances", "", 1, func(n exerciseNight) float64 { return n.disturbances }},
+}
+
+// AnalyzeLateExercise compares sleep after workouts ending within windowHours
+// of bedtime against other nights, then searches one-hour gaps for where the
+// disruption peaks to recommend a personal cutoff time
+func (h *HealthAnalyzer) AnalyzeLateExercise(sleepData []WhoopSleep, workouts []WhoopWorkout, windowHours float64) LateExerciseAnalysis {
+	mainSleeps, _ := splitNaps(sleepData)
+	analysis := LateExerciseAnalysis{Status: "insufficient_data", WindowHours: windowHours}
+
+	var nights []exerciseNight
+	var bedtimes []float64
+	for _, sleep := range mainSleeps {
+		if sleep.ScoreState != "" && sleep.ScoreState != "SCORED" {
+			continue
+		}
+		night := exerciseNight{
+			gapHours:     -1,
+			awakeMinutes: float64(sleep.Score.StageSummary.TotalAwakeTimeMilli) / (1000 * 60),
+			efficiency:   sleep.Score.SleepEfficiencyPercentage,
+			disturbances: float64(sleep.Score.StageSummary.DisturbanceCount),
+		}
+		for _, workout := range workouts {
+			gap := sleep.Start.Sub(workout.End).Hours()
+			if gap >= 0 && gap <= maxLateExerciseGap && (night.gapHours < 0 || gap < night.gapHours) {
+				night.gapHours = gap
+			}
+		}
+		nights = append(nights, night)
+		bedtimes = append(bedtimes, minutesAfterNoon(localTime(sleep.Start, sleep.TimezoneOffset)))
+	}
+
+	analysis.Nights = len(nights)
+	if len(nights) < minLateExerciseNights {
+		return analysis
+	}
+	typicalBedtime := stats.Median(bedtimes)
+	analysis.TypicalBedtime = formatClock(typicalBedtime + noonMinutes)
+
+	metrics, closeCount := h.compareSleepAfterExercise(nights, windowHours)
+	analysis.Metrics = metrics
+	analysis.CloseNights = closeCount
+	if closeCount < minCloseNights {
+		analysis.Status = "no_late_workouts"
+		return analysis
+	}
+	analysis.Status = "ok"
+
+	// Widening the gap past the disruptive range dilutes the effect with harmless
+	// sessions, so the cutoff is the widest gap at which the harm peaks
+	bestHarm := 0.0
+	for gap := 1.0; gap <= maxLateExerciseGap-2; gap++ {
+		metrics, count := h.compareSleepAfterExercise(nights, gap)
+		if count < minCloseNights || !anyDisrupted(metrics) {
+			continue
+		}
+		if harm := worstHarm(metrics); harm >= bestHarm-1e-9 {
+			bestHarm = harm
+			analysis.CutoffHours = gap
+		}
+	}
+	if analysis.CutoffHours > 0 {
+		analysis.TrainBefore = formatClock(typicalBedtime + noonMinutes - analysis.CutoffHours*60)
+	}
+	return analysis
+}
+
+// compareSleepAfterExercise splits nights by whether a workout ended within
+// windowHours of bed and compares each sleep measure between the groups
+func (h *HealthAnalyzer) compareSleepAfterExercise(nights []exerciseNight, windowHours float64) ([]SleepInterferenceMetric, int) {
+	var closeNights, otherNights []exerciseNight
+	for _, night := range nights {
+		if night.gapHours >= 0 && night.gapHours <= windowHours {
+			closeNights = append(closeNights, night)
+		} else {
+			otherNights = append(otherNights, night)
+		}
+	}
+
+	var metrics []SleepInterferenceMetric
+	for _, measure := range sleepInterferenceMeasures {
+		var closeValues, otherValues []float64
+		for _, night := range closeNights {
+			closeValues = append(closeValues, measure.value(night))
+		}
+		for _, night := range otherNights {
+			otherValues = append(otherValues, measure.value(night))
+		}
+
+		metric := SleepInterferenceMetric{
+			Metric:     measure.key,
+			Label:      measure.label,
+			Unit:       measure.unit,
+			Close:      h.calculateMean(closeValues),
+			Other:      h.calculateMean(otherValues),
+			CloseCount: len(closeValues),
+			OtherCount: len(otherValues),
+			PValue:     1,
+		}
+		if len(closeValues) >= 2 && len(otherValues) >= 2 {
+			metric.CohensD = stats.CohensD(otherValues, closeValues)
+			_, _, metric.PValue = stats.WelchTTest(otherValues, closeValues)
+			metric.Disrupted = metric.CohensD*measure.harmful >= disruptiveEffectSize
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics, len(closeNights)
+}
+
+// worstHarm returns the largest effect size in a harmful direction
+func worstHarm(metrics []SleepInterferenceMetric) float64 {
+	worst := 0.0
+	for _, metric := range metrics {
+		for _, measure := range sleepInterferenceMeasures {
+			if measure.key == metric.Metric {
+				worst = math.Max(worst, metric.CohensD*measure.harmful)
+			}
+		}
+	}
+	return worst
+}
+
+// anyDisrupted reports whether any sleep measure was harmed
+func anyDisrupted(metrics []SleepInterferenceMetric) bool {
+	for _, metric := range metrics {
+		if metric.Disrupted {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatLateExerciseAnalysis renders the late-training comparison and cutoff recommendation
+func (h *HealthAnalyzer) FormatLateExerciseAnalysis(analysis LateExerciseAnalysis) string {
+	var builder strings.Builder
+	builder.WriteString("# Evening Training and Sleep\n\n")
+
+	switch analysis.Status {
+	case "insufficient_data":
+		builder.WriteString(fmt.Sprintf("At least %d scored nights are needed (found %d).\n", minLateExerciseNights, analysis.Nights))
+		return builder.String()
+	case "no_late_workouts":
+		builder.WriteString(fmt.Sprintf("Only %d of %d nights followed a workout ending within %.0f hours of bed; at least %d are needed to judge the effect.\n",
+			analysis.CloseNights, analysis.Nights, analysis.WindowHours, minCloseNights))
+		return builder.String()
+	}
+
+	builder.WriteString(fmt.Sprintf("**Nights analyzed:** %d (%d after training within %.0f h of bed)\n", analysis.Nights, analysis.CloseNights, analysis.WindowHours))
+	builder.WriteString(fmt.Sprintf("**Typical bedtime:** %s\n\n", analysis.TypicalBedtime))
+
+	builder.WriteString("| Measure | After late training | Other nights | Effect size (d) | p-value | |\n")
+	builder.WriteString("|---|---|---|---|---|---|\n")
+	for _, metric := range analysis.Metrics {
+		marker := ""
+		if metric.Disrupted {
+			marker = "⚠️ worse"
+		}
+		builder.WriteString(fmt.Sprintf("| %s | %.1f%s | %.1f%s | %+.2f | %.3f | %s |\n",
+			metric.Label, metric.Close, metric.Unit, metric.Other, metric.Unit, metric.CohensD, metric.PValue, marker))
+	}
+
+	builder.WriteString("\n## Recommendation\n")
+	if analysis.TrainBefore != "" {
+		builder.WriteString(fmt.Sprintf("Workouts ending within %.0f hours of bed are followed by noticeably worse sleep. **Aim to finish training before %s** on nights you go to bed around %s.\n",
+			analysis.CutoffHours, analysis.TrainBefore, analysis.TypicalBedtime))
+	} else {
+		builder.WriteString("Evening training does not measurably disrupt your sleep; schedule workouts whenever suits you.\n")
+	}
+
+	builder.WriteString(fmt.Sprintf("\n*Whoop does not report sleep latency directly; time awake in bed stands in for it. Caffeine is not tracked, so pre-workout stimulants may contribute to any effect. Effects count as disruptive at d ≥ %.1f in the harmful direction.*\n", disruptiveEffectSize))
+	return builder.String()
+}
+
+// lateExerciseWindow validates the window_hours argument
+func lateExerciseWindow(hours float64) (float64, error) {
+	if hours == 0 {
+		return defaultLateExerciseWindow, nil
+	}
+	if hours < 1 || hours > maxLateExerciseGap || math.IsNaN(hours) {
+		return 0, fmt.Errorf("window_hours must be between 1 and %.0f", maxLateExerciseGap)
+	}
+	return hours, nil
+}
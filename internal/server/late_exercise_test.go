@@ -0,0 +1,55 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_AnalyzeLateExercise(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	minute := int(time.Minute / time.Millisecond)
+	start := time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC)
+
+	var sleeps []WhoopSleep
+	var workouts []WhoopWorkout
+	for i := 0; i < 28; i++ {
+		bed := start.AddDate(0, 0, i)
+		jitter := float64(i%3) - 1
+		awake, efficiency, disturbances := 20+2*jitter, 92+jitter, 6
+
+		var workout WhoopWorkout
+		switch i % 4 {
+		case 0: // finishes 1.5h before bed and disrupts sleep
+			workout.End = bed.Add(-90 * time.Minute)
+			awake, efficiency, disturbances = 45+2*jitter, 82+jitter, 12
+		case 1: // finishes 5h before bed with no effect
+			workout.End = bed.Add(-5 * time.Hour)
+		default:
+			workout.End = bed.Add(-12 * time.Hour)
+		}
+		workouts = append(workouts, workout)
+
+		var sleep WhoopSleep
+		sleep.Start = bed
+		sleep.End = bed.Add(8 * time.Hour)
+		sleep.Score.StageSummary.TotalAwakeTimeMilli = int(awake) * minute
+		sleep.Score.StageSummary.DisturbanceCount = disturbances
+		sleep.Score.SleepEfficiencyPercentage = efficiency
+		sleeps = append(sleeps, sleep)
+	}
+
+	analysis := analyzer.AnalyzeLateExercise(sleeps, workouts, 3)
+	if analysis.Status != "ok" || analysis.CloseNights != 7 {
+		t.Fatalf("Expected 7 late-training nights, got %s/%d", analysis.Status, analysis.CloseNights)
+	}
+	if !anyDisrupted(analysis.Metrics) {
+		t.Errorf("Expected late training to disrupt sleep, got %+v", analysis.Metrics)
+	}
+	// Gaps of 2-4h only include the disruptive 1.5h sessions; from 5h the harmless ones dilute them
+	if analysis.CutoffHours != 4 || analysis.TrainBefore != "19:00" {
+		t.Errorf("Expected a 4h cutoff (train before 19:00), got %.0fh (%s)", analysis.CutoffHours, analysis.TrainBefore)
+	}
+	if analysis.TypicalBedtime != "23:00" {
+		t.Errorf("Expected typical bedtime 23:00, got %s", analysis.TypicalBedtime)
+	}
+}
@@ -0,0 +1,233 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+const (
+	// minImpactNights is the fewest scored nights needed for personal baselines
+	minImpactNights = 14
+	// impactRHRMinRise is the smallest resting HR rise (bpm) that counts as elevated
+	impactRHRMinRise = 3.0
+	// impactHRVMinDrop is the smallest HRV drop, as a fraction of baseline, that counts as suppressed
+	impactHRVMinDrop = 0.10
+	// impactREMMinDrop is the smallest drop in REM share (fraction of sleep) that counts as reduced
+	impactREMMinDrop = 0.03
+	// impactLateBedtimeMinutes is how far past the usual bedtime counts as a late night
+	impactLateBedtimeMinutes = 60.0
+)
+
+// ImpactNight is a night whose physiology matches the alcohol/late-night signature
+type ImpactNight struct {
+	Date              string   `json:"date"` // evening the night began
+	Bedtime           string   `json:"bedtime"`
+	RHRDelta          float64  `json:"rhr_delta_bpm"`
+	HRVDeltaPercent   float64  `json:"hrv_delta_percent"`
+	REMDeltaPoints    float64  `json:"rem_delta_points"` // percentage points of sleep
+	BedtimeShift      float64  `json:"bedtime_shift_minutes"`
+	Signals           []string `json:"signals"`
+	Recovery          float64  `json:"recovery"`
+	RecoveryAvailable bool     `json:"recovery_available"`
+}
+
+// MonthlyImpact counts flagged nights in one calendar month
+type MonthlyImpact struct {
+	Month   string `json:"month"` // YYYY-MM
+	Nights  int    `json:"nights"`
+	Flagged int    `json:"flagged"`
+}
+
+// LateNightImpact summarizes probable alcohol or late-night nights and their cost
+type LateNightImpact struct {
+	Status          string          `json:"status"` // "ok", "insufficient_data"
+	Nights          int             `json:"nights"`
+	Flagged         []ImpactNight   `json:"flagged"`
+	FlaggedRecovery float64         `json:"flagged_recovery"`
+	TypicalRecovery float64         `json:"typical_recovery"`
+	RecoveryPenalty float64         `json:"recovery_penalty"` // typical minus flagged, in points
+	Months          []MonthlyImpact `json:"months"`
+}
+
+// impactNight holds one main sleep's overnight physiology
+type impactNight struct {
+	date        string
+	bedtime     float64 // minutes after noon
+	rhr, hrv    float64
+	remShare    float64
+	recovery    float64
+	hasRecovery bool
+}
+
+// DetectLateNightImpact flags nights showing the physiological signature of
+// alcohol or a late night out: resting HR elevated and HRV suppressed against
+// the user's medians, together with reduced REM or a later-than-usual bedtime.
+// It reports the average recovery penalty on those nights and how often they
+// occur each month.
+func (h *HealthAnalyzer) DetectLateNightImpact(recoveries []WhoopRecovery, sleepData []WhoopSleep) LateNightImpact {
+	bySleep := make(map[string]WhoopRecovery, len(recoveries))
+	for _, recovery := range recoveries {
+		if recovery.ScoreState == "" || recovery.ScoreState == "SCORED" {
+			bySleep[recovery.SleepID] = recovery
+		}
+	}
+
+	mainSleeps, _ := splitNaps(sleepData)
+	var nights []impactNight
+	for _, sleep := range mainSleeps {
+		recovery, ok := bySleep[sleep.ID]
+		if !ok || recovery.Score.RestingHeartRate == 0 || recovery.Score.HRVRmssd == 0 {
+			continue
+		}
+		stages := sleep.Score.StageSummary
+		asleep := float64(stages.TotalInBedTimeMilli - stages.TotalAwakeTimeMilli)
+		if asleep <= 0 {
+			continue
+		}
+		nights = append(nights, impactNight{
+			date:        dayKey(localTime(sleep.End, sleep.TimezoneOffset).AddDate(0, 0, -1)),
+			bedtime:     minutesAfterNoon(localTime(sleep.Start, sleep.TimezoneOffset)),
+			rhr:         recovery.Score.RestingHeartRate,
+			hrv:         recovery.Score.HRVRmssd,
+			remShare:    float64(stages.TotalRemSleepTimeMilli) / asleep,
+			recovery:    recovery.Score.RecoveryScore,
+			hasRecovery: recovery.Score.RecoveryScore > 0,
+		})
+	}
+	sort.Slice(nights, func(i, j int) bool {
+		return nights[i].date < nights[j].date
+	})
+
+	result := LateNightImpact{Status: "insufficient_data", Nights: len(nights), Flagged: []ImpactNight{}}
+	if len(nights) < minImpactNights {
+		return result
+	}
+	result.Status = "ok"
+
+	column := func(field func(impactNight) float64) []float64 {
+		values := make([]float64, len(nights))
+		for i, night := range nights {
+			values[i] = field(night)
+		}
+		return values
+	}
+	rhrs := column(func(n impactNight) float64 { return n.rhr })
+	hrvs := column(func(n impactNight) float64 { return n.hrv })
+	rems := column(func(n impactNight) float64 { return n.remShare })
+	bedtimes := column(func(n impactNight) float64 { return n.bedtime })
+
+	rhrBase, rhrSD := stats.Median(rhrs), h.calculateStdDev(rhrs)
+	hrvBase, hrvSD := stats.Median(hrvs), h.calculateStdDev(hrvs)
+	remBase, remSD := stats.Median(rems), h.calculateStdDev(rems)
+	bedBase := stats.Median(bedtimes)
+
+	months := make(map[string]*MonthlyImpact)
+	var flaggedRecoveries, typicalRecoveries []float64
+	for _, night := range nights {
+		month := night.date[:7]
+		if months[month] == nil {
+			months[month] = &MonthlyImpact{Month: month}
+		}
+		months[month].Nights++
+
+		rhrDelta := night.rhr - rhrBase
+		hrvDelta := night.hrv - hrvBase
+		remDelta := night.remShare - remBase
+		bedShift := night.bedtime - bedBase
+
+		elevatedRHR := rhrDelta >= math.Max(impactRHRMinRise, rhrSD)
+		suppressedHRV := -hrvDelta >= math.Max(impactHRVMinDrop*hrvBase, hrvSD)
+		reducedREM := -remDelta >= math.Max(impactREMMinDrop, remSD)
+		lateBedtime := bedShift >= impactLateBedtimeMinutes
+
+		if !(elevatedRHR && suppressedHRV && (reducedREM || lateBedtime)) {
+			if night.hasRecovery {
+				typicalRecoveries = append(typicalRecoveries, night.recovery)
+			}
+			continue
+		}
+
+		signals := []string{"elevated resting HR", "suppressed HRV"}
+		if reducedREM {
+			signals = append(signals, "reduced REM")
+		}
+		if lateBedtime {
+			signals = append(signals, "late bedtime")
+		}
+		flagged := ImpactNight{
+			Date:              night.date,
+			Bedtime:           formatClock(night.bedtime + noonMinutes),
+			RHRDelta:          rhrDelta,
+			REMDeltaPoints:    remDelta * 100,
+			BedtimeShift:      bedShift,
+			Signals:           signals,
+			Recovery:          night.recovery,
+			RecoveryAvailable: night.hasRecovery,
+		}
+		if hrvBase > 0 {
+			flagged.HRVDeltaPercent = hrvDelta / hrvBase * 100
+		}
+		result.Flagged = append(result.Flagged, flagged)
+		months[month].Flagged++
+		if night.hasRecovery {
+			flaggedRecoveries = append(flaggedRecoveries, night.recovery)
+		}
+	}
+
+	if len(flaggedRecoveries) > 0 && len(typicalRecoveries) > 0 {
+		result.FlaggedRecovery = h.calculateMean(flaggedRecoveries)
+		result.TypicalRecovery = h.calculateMean(typicalRecoveries)
+		result.RecoveryPenalty = result.TypicalRecovery - result.FlaggedRecovery
+	}
+	for _, month := range months {
+		result.Months = append(result.Months, *month)
+	}
+	sort.Slice(result.Months, func(i, j int) bool {
+		return result.Months[i].Month < result.Months[j].Month
+	})
+	return result
+}
+
+// FormatLateNightImpact renders flagged nights, the recovery penalty, and monthly frequency
+func (h *HealthAnalyzer) FormatLateNightImpact(impact LateNightImpact) string {
+	var builder strings.Builder
+	builder.WriteString("# Alcohol / Late-Night Impact\n\n")
+
+	if impact.Status == "insufficient_data" {
+		builder.WriteString(fmt.Sprintf("At least %d scored nights are needed to establish personal baselines (found %d).\n", minImpactNights, impact.Nights))
+		return builder.String()
+	}
+
+	builder.WriteString(fmt.Sprintf("**Nights analyzed:** %d\n", impact.Nights))
+	builder.WriteString(fmt.Sprintf("**Probable impact nights:** %d\n", len(impact.Flagged)))
+	if impact.RecoveryPenalty != 0 {
+		builder.WriteString(fmt.Sprintf("**Next-day recovery penalty:** %.0f points (%.0f%% after impact nights vs %.0f%% otherwise)\n",
+			impact.RecoveryPenalty, impact.FlaggedRecovery, impact.TypicalRecovery))
+	}
+
+	builder.WriteString("\n## Frequency by Month\n")
+	for _, month := range impact.Months {
+		builder.WriteString(fmt.Sprintf("- %s: %d of %d nights\n", month.Month, month.Flagged, month.Nights))
+	}
+
+	if len(impact.Flagged) > 0 {
+		builder.WriteString("\n## Flagged Nights\n")
+		builder.WriteString("| Night of | Bedtime | RHR | HRV | REM | Recovery | Signals |\n")
+		builder.WriteString("|---|---|---|---|---|---|---|\n")
+		for _, night := range impact.Flagged {
+			recovery := "-"
+			if night.RecoveryAvailable {
+				recovery = fmt.Sprintf("%.0f%%", night.Recovery)
+			}
+			builder.WriteString(fmt.Sprintf("| %s | %s | %+.0f bpm | %+.0f%% | %+.0f pts | %s | %s |\n",
+				night.Date, night.Bedtime, night.RHRDelta, night.HRVDeltaPercent, night.REMDeltaPoints, recovery, strings.Join(night.Signals, ", ")))
+		}
+	}
+
+	builder.WriteString("\n*This is a heuristic: illness, late meals, heat, and stress can produce the same signature. Confirm against what actually happened, for example with log_annotation.*\n")
+	return builder.String()
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_DetectLateNightImpact(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	hour := int(time.Hour / time.Millisecond)
+	start := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+
+	var recoveries []WhoopRecovery
+	var sleeps []WhoopSleep
+	for i := 0; i < 40; i++ {
+		wake := start.AddDate(0, 0, i)
+		jitter := float64(i%3) - 1
+		bedtime := wake.Add(-8 * time.Hour) // 23:00
+		rhr, hrv, rem, score := 52+jitter, 60+2*jitter, 1.8, 70+jitter
+
+		// Every Saturday night: a late, drinking-style night
+		if wake.Weekday() == time.Sunday {
+			bedtime = wake.Add(-6 * time.Hour) // 01:00
+			rhr, hrv, rem, score = 60, 42, 0.9, 40
+		}
+
+		var sleep WhoopSleep
+		sleep.ID = fmt.Sprintf("sleep-%d", i)
+		sleep.Start = bedtime
+		sleep.End = wake
+		sleep.Score.StageSummary.TotalInBedTimeMilli = int(wake.Sub(bedtime) / time.Millisecond)
+		sleep.Score.StageSummary.TotalRemSleepTimeMilli = int(rem * float64(hour))
+		sleeps = append(sleeps, sleep)
+
+		var recovery WhoopRecovery
+		recovery.SleepID = sleep.ID
+		recovery.Score.RestingHeartRate = rhr
+		recovery.Score.HRVRmssd = hrv
+		recovery.Score.RecoveryScore = score
+		recoveries = append(recoveries, recovery)
+	}
+
+	impact := analyzer.DetectLateNightImpact(recoveries, sleeps)
+	if impact.Status != "ok" {
+		t.Fatalf("Expected ok, got %s", impact.Status)
+	}
+	if len(impact.Flagged) != 6 {
+		t.Fatalf("Expected the 6 Saturday nights to be flagged, got %d", len(impact.Flagged))
+	}
+	for _, night := range impact.Flagged {
+		if night.Bedtime != "01:00" || len(night.Signals) != 4 {
+			t.Errorf("Unexpected flagged night %+v", night)
+		}
+	}
+	if math.Abs(impact.RecoveryPenalty-30) > 1 {
+		t.Errorf("Expected a ~30 point recovery penalty, got %.1f", impact.RecoveryPenalty)
+	}
+	if len(impact.Months) != 3 || impact.Months[1].Month != "2024-05" || impact.Months[1].Flagged != 4 {
+		t.Errorf("Expected April (first night) through June with 4 flagged in May, got %+v", impact.Months)
+	}
+}
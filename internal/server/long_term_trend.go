@@ -0,0 +1,229 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+// Trend aggregation granularities
+const (
+	granularityWeekly    = "weekly"
+	granularityMonthly   = "monthly"
+	granularityQuarterly = "quarterly"
+)
+
+// maxTrendDays is the longest window the trend tool accepts
+const maxTrendDays = 365
+
+// trendGranularity picks a bucket size that keeps the table to roughly a dozen rows
+func trendGranularity(requested string, days int) string {
+	switch requested {
+	case granularityWeekly, granularityMonthly, granularityQuarterly:
+		return requested
+	}
+	if days <= 120 {
+		return granularityWeekly
+	}
+	return granularityMonthly
+}
+
+// TrendBucket aggregates a metric over one week, month, or quarter
+type TrendBucket struct {
+	Label string    `json:"label"`
+	Start time.Time `json:"start"`
+	Mean  float64   `json:"mean"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Count int       `json:"count"`
+}
+
+// LongTermTrend is a regression-based trend over an aggregated series
+type LongTermTrend struct {
+	Metric      string        `json:"metric"`
+	Label       string        `json:"label"`
+	Unit        string        `json:"unit"`
+	Granularity string        `json:"granularity"`
+	Buckets     []TrendBucket `json:"buckets"`
+
+	// Slope is per day; CI is the 95% interval on that slope
+	Slope     float64 `json:"slope_per_day"`
+	SlopeLow  float64 `json:"slope_ci_low"`
+	SlopeHigh float64 `json:"slope_ci_high"`
+	RSquared  float64 `json:"r_squared"`
+	Samples   int     `json:"samples"`
+	Direction string  `json:"direction"` // "rising", "falling", "flat"
+}
+
+// bucketStart returns the first day of the bucket containing t
+func bucketStart(t time.Time, granularity string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch granularity {
+	case granularityMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case granularityQuarterly:
+		quarterMonth := time.Month((int(t.Month())-1)/3*3 + 1)
+		return time.Date(t.Year(), quarterMonth, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		// Weeks start on Monday
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	}
+}
+
+// bucketLabel names a bucket for display
+func bucketLabel(start time.Time, granularity string) string {
+	switch granularity {
+	case granularityMonthly:
+		return start.Format("Jan 2006")
+	case granularityQuarterly:
+		return fmt.Sprintf("Q%d %d", (int(start.Month())-1)/3+1, start.Year())
+	default:
+		return "Week of " + start.Format("Jan 2")
+	}
+}
+
+// AnalyzeLongTermTrend fits a linear trend to the daily series and aggregates it
+// into weekly, monthly, or quarterly buckets
+func (h *HealthAnalyzer) AnalyzeLongTermTrend(metric metricDefinition, series []datedValue, granularity string) LongTermTrend {
+	trend := LongTermTrend{
+		Metric:      metric.Key,
+		Label:       metric.Label,
+		Unit:        metric.Unit,
+		Granularity: granularity,
+		Samples:     len(series),
+		Direction:   "flat",
+	}
+	if len(series) == 0 {
+		return trend
+	}
+
+	var buckets []TrendBucket
+	var bucketValues [][]float64
+	for _, v := range series {
+		start := bucketStart(v.Date, granularity)
+		if len(buckets) == 0 || !buckets[len(buckets)-1].Start.Equal(start) {
+			buckets = append(buckets, TrendBucket{Label: bucketLabel(start, granularity), Start: start})
+			bucketValues = append(bucketValues, nil)
+		}
+		bucketValues[len(bucketValues)-1] = append(bucketValues[len(bucketValues)-1], v.Value)
+	}
+	for i := range buckets {
+		values := bucketValues[i]
+		buckets[i].Mean = h.calculateMean(values)
+		buckets[i].Min = minFloat(values)
+		buckets[i].Max = maxFloat(values)
+		buckets[i].Count = len(values)
+	}
+	trend.Buckets = buckets
+
+	// Regress on the daily values, not the bucket means, so sparse buckets aren't overweighted
+	first := series[0].Date
+	xs := make([]float64, len(series))
+	for i, v := range series {
+		xs[i] = v.Date.Sub(first).Hours() / 24
+	}
+	ys := valuesOf(series)
+	trend.Slope, _, trend.RSquared = stats.LinearRegression(xs, ys)
+	trend.SlopeLow, trend.SlopeHigh = stats.RegressionSlopeCI(xs, ys)
+
+	if trend.SlopeLow > 0 {
+		trend.Direction = "rising"
+	} else if trend.SlopeHigh < 0 {
+		trend.Direction = "falling"
+	}
+	return trend
+}
+
+// trendSeries collects what the trend tool reports from streamed pages: the
+// metric's daily values and, for its summary, the recovery scores or the
+// sleeps, which the sleep analysis needs whole. Pages of the other datasets
+// are dropped as they arrive. It is safe for concurrent use.
+type trendSeries struct {
+	metric metricDefinition
+
+	mu     sync.Mutex
+	values []datedValue // the metric by local day
+	scores []datedValue // recovery scores by creation time
+	sleeps []WhoopSleep
+}
+
+func newTrendSeries(metric metricDefinition) *trendSeries {
+	return &trendSeries{metric: metric}
+}
+
+// add keeps what the report needs from a page of records of dataset
+func (t *trendSeries) add(dataset string, page *HealthData) {
+	if dataset != t.metric.dataset {
+		return
+	}
+	values := t.metric.extract(page)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.values = append(t.values, values...)
+	switch dataset {
+	case "recovery":
+		t.scores = append(t.scores, recoveryValues(page.Recoveries, func(r WhoopRecovery) float64 { return r.Score.RecoveryScore })...)
+	case "sleep":
+		t.sleeps = append(t.sleeps, page.Sleeps...)
+	}
+}
+
+// minFloat returns the smallest value, or 0 for an empty slice
+func minFloat(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	result := values[0]
+	for _, v := range values[1:] {
+		result = math.Min(result, v)
+	}
+	return result
+}
+
+// maxFloat returns the largest value, or 0 for an empty slice
+func maxFloat(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	result := values[0]
+	for _, v := range values[1:] {
+		result = math.Max(result, v)
+	}
+	return result
+}
+
+// FormatLongTermTrend renders the bucket table and regression summary
+func (h *HealthAnalyzer) FormatLongTermTrend(trend LongTermTrend) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("## Long-Term Trend (%s)\n", trend.Granularity))
+
+	if trend.Samples < 3 {
+		builder.WriteString("Not enough scored days to fit a trend.\n")
+		return builder.String()
+	}
+
+	builder.WriteString(fmt.Sprintf("- **Slope:** %+.2f%s per week (95%% CI %+.2f to %+.2f)\n",
+		trend.Slope*7, trend.Unit, trend.SlopeLow*7, trend.SlopeHigh*7))
+	builder.WriteString(fmt.Sprintf("- **Per Month:** %+.1f%s\n", trend.Slope*30, trend.Unit))
+	builder.WriteString(fmt.Sprintf("- **Fit (R²):** %.2f over %d days\n", trend.RSquared, trend.Samples))
+
+	switch trend.Direction {
+	case "rising", "falling":
+		builder.WriteString(fmt.Sprintf("- **Direction:** %s — the confidence interval excludes zero\n", trend.Direction))
+	default:
+		builder.WriteString("- **Direction:** no clear trend — the confidence interval includes zero\n")
+	}
+
+	builder.WriteString("\n| Period | Mean | Range | Days |\n|---|---|---|---|\n")
+	for _, bucket := range trend.Buckets {
+		builder.WriteString(fmt.Sprintf("| %s | %.1f%s | %.1f–%.1f | %d |\n",
+			bucket.Label, bucket.Mean, trend.Unit, bucket.Min, bucket.Max, bucket.Count))
+	}
+	return builder.String()
+}
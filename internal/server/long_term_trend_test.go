@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_AnalyzeLongTermTrend(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	metric, _ := lookupMetric("recovery")
+	start := time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC)
+
+	var series []datedValue
+	for i := 0; i < 180; i++ {
+		noise := float64(i%5) - 2
+		series = append(series, datedValue{Date: start.AddDate(0, 0, i), Value: 40 + 0.1*float64(i) + noise})
+	}
+
+	trend := analyzer.AnalyzeLongTermTrend(metric, series, trendGranularity("", 180))
+
+	if trend.Granularity != granularityMonthly {
+		t.Errorf("Expected monthly buckets for 180 days, got %s", trend.Granularity)
+	}
+	if len(trend.Buckets) != 6 {
+		t.Errorf("Expected 6 monthly buckets, got %d", len(trend.Buckets))
+	}
+	if trend.Direction != "rising" {
+		t.Errorf("Expected rising trend, got %s", trend.Direction)
+	}
+	if trend.SlopeLow > 0.1 || trend.SlopeHigh < 0.1 {
+		t.Errorf("Expected CI [%.3f, %.3f] to contain the true slope 0.1", trend.SlopeLow, trend.SlopeHigh)
+	}
+}
+
+func TestBucketStart(t *testing.T) {
+	wednesday := time.Date(2024, 8, 14, 9, 0, 0, 0, time.UTC)
+
+	if got := bucketStart(wednesday, granularityWeekly); got.Weekday() != time.Monday || got.Day() != 12 {
+		t.Errorf("Weekly bucket starts %v, want Monday Aug 12", got)
+	}
+	if got := bucketLabel(bucketStart(wednesday, granularityQuarterly), granularityQuarterly); got != "Q3 2024" {
+		t.Errorf("Quarter label = %s, want Q3 2024", got)
+	}
+}
+
+func TestTrendSeries_MatchesBatch(t *testing.T) {
+	end := time.Date(2024, 9, 30, 12, 0, 0, 0, time.UTC)
+	mock := NewSyntheticMockWhoopAPI(SyntheticOptions{Seed: 5, End: end, Days: 400, Location: time.FixedZone("", 2*60*60)})
+	server := newMockServer(t, mock)
+	start := end.AddDate(0, 0, -maxTrendDays)
+	analyzer := server.healthAnalyzer
+
+	data, _, err := server.fetchScoredHealthData(context.Background(), start, end, nil)
+	if err != nil {
+		t.Fatalf("fetchScoredHealthData() error = %v", err)
+	}
+	for _, key := range []string{"recovery", "sleep_hours", "strain"} {
+		metric, _ := lookupMetric(key)
+		collected := newTrendSeries(metric)
+		if _, err := server.streamScoredHealthData(context.Background(), start, end, nil, collected.add); err != nil {
+			t.Fatalf("streamScoredHealthData() error = %v", err)
+		}
+		batch := analyzer.AnalyzeLongTermTrend(metric, metric.extract(data), granularityMonthly)
+		streamed := analyzer.AnalyzeLongTermTrend(metric, sortDatedValues(collected.values), granularityMonthly)
+		if batch.Samples == 0 || !reflect.DeepEqual(streamed, batch) {
+			t.Errorf("streamed %s trend = %+v, want %+v", key, streamed, batch)
+		}
+
+		switch key {
+		case "recovery":
+			if got, want := analyzer.recoveryTrendOf(sortDatedValues(collected.scores)), analyzer.analyzeRecoveryTrend(data.Recoveries); !reflect.DeepEqual(got, want) {
+				t.Errorf("streamed recovery summary = %+v, want %+v", got, want)
+			}
+		case "sleep_hours":
+			if got, want := analyzer.analyzeSleepPatterns(collected.sleeps), analyzer.analyzeSleepPatterns(data.Sleeps); !reflect.DeepEqual(got, want) {
+				t.Errorf("streamed sleep summary = %+v, want %+v", got, want)
+			}
+			if len(collected.scores) != 0 {
+				t.Errorf("kept %d recovery scores for a sleep trend", len(collected.scores))
+			}
+		}
+	}
+}
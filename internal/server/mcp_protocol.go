@@ -0,0 +1,4391 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"whoop-mcp/internal/mcp"
+)
+
+// MCPServer handles the Model Context Protocol communication
+type MCPServer struct {
+	whoopClient    WhoopAPI
+	settings       Settings
+	healthAnalyzer *HealthAnalyzer
+	baselines      *BaselineStore
+	burnout        *BurnoutStore
+	annotations    *AnnotationStore
+	// annotationSummaries holds journal weeks folded away by retention
+	annotationSummaries *AnnotationSummaryStore
+	retention           *RetentionPolicy
+	goals               *GoalStore
+	redFlagAcks         *RedFlagAckStore
+	notifier            *Notifier
+	redactor            *Redactor
+	audit               *AuditLog
+	cipher              *DataCipher // seals the datastore; nil when it is plaintext
+	client              string      // clientInfo from initialize, for the audit log
+	readOnly            bool        // see SetMode
+	started             time.Time
+	reports             *ReportArchive
+	tools               []MCPTool
+	resources           []MCPResource
+	initialized         bool
+	authFlow            *authFlow
+	oauthStates         *oauthStateStore
+	toolLimits          *toolRateLimits  // per-tool limits; nil when unlimited
+	toolCache           *toolCache       // recent results; nil when disabled
+	fetchPool           *workerPool      // bounds concurrent Whoop fetches
+	tracer              *Tracer          // exports request spans; nil when tracing is off
+	middlewares         []ToolMiddleware // registered with Use
+	toolChain           ToolHandler      // built from middlewares on first call
+	mu                  sync.RWMutex
+	writeMu             sync.Mutex // serializes messages written by Serve
+}
+
+// Settings looks up a WHOOP_* setting, or another variable the server reads,
+// by its environment variable name. The command passes the resolved
+// config.Config's Getenv; os.Getenv reads the process environment directly.
+type Settings func(name string) string
+
+// NewMCPServer creates a new MCP server instance backed by the Whoop API
+func NewMCPServer(settings Settings) (*MCPServer, error) {
+	whoopClient, err := NewWhoopClient(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Whoop client: %w", err)
+	}
+	return NewMCPServerWithAPI(whoopClient, settings)
+}
+
+// NewMCPServerWithAPI creates an MCP server that reads health data through
+// whoopClient; the rest of its configuration comes from settings
+func NewMCPServerWithAPI(whoopClient WhoopAPI, settings Settings) (*MCPServer, error) {
+	cipher, err := NewDataCipherFromEnv(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure datastore encryption: %w", err)
+	}
+	traces, err := NewTracerFromEnv(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure tracing: %w", err)
+	}
+
+	healthAnalyzer := NewHealthAnalyzer()
+	healthAnalyzer.sports = whoopClient.Sports()
+
+	cycle, err := LoadMenstrualCycleConfigFromEnv(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure cycle-aware mode: %w", err)
+	}
+	healthAnalyzer.cycle = cycle
+
+	norms, err := LoadPopulationNormsConfigFromEnv(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure population norms: %w", err)
+	}
+	healthAnalyzer.norms = norms
+
+	safety, err := LoadSafetyConfigFromEnv(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure safety notices: %w", err)
+	}
+	healthAnalyzer.safety = safety
+
+	templates, err := NewReportTemplatesFromEnv(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure report templates: %w", err)
+	}
+	healthAnalyzer.templates = templates
+
+	locale, err := NewLocalizerFromEnv(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure locale: %w", err)
+	}
+	healthAnalyzer.locale = locale
+
+	units, err := NewUnitSystemFromEnv(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure units: %w", err)
+	}
+	healthAnalyzer.units = units
+
+	stressModel, err := NewStressModelFromEnv(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure stress model: %w", err)
+	}
+	healthAnalyzer.stressModel = stressModel
+
+	baselines, err := NewBaselineStoreFromEnv(settings, cipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure baseline store: %w", err)
+	}
+
+	burnout, err := NewBurnoutStoreFromEnv(settings, cipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure burnout store: %w", err)
+	}
+
+	annotations, err := NewAnnotationStoreFromEnv(settings, cipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure annotation store: %w", err)
+	}
+
+	annotationSummaries, err := NewAnnotationSummaryStoreFromEnv(settings, cipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure annotation summary store: %w", err)
+	}
+
+	retention, err := LoadRetentionPolicyFromEnv(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure data retention: %w", err)
+	}
+
+	goals, err := NewGoalStoreFromEnv(settings, cipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure goal store: %w", err)
+	}
+
+	redFlagAcks, err := NewRedFlagAckStoreFromEnv(settings, cipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure red flag acknowledgements: %w", err)
+	}
+
+	notifier, err := NewNotifierFromEnv(settings, cipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure red flag notifications: %w", err)
+	}
+
+	redactor, err := NewRedactorFromEnv(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure PII redaction: %w", err)
+	}
+
+	audit, err := NewAuditLogFromEnv(settings, cipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure audit log: %w", err)
+	}
+
+	reports, err := NewReportArchiveFromEnv(settings, cipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure report archive: %w", err)
+	}
+
+	toolLimits, err := NewToolRateLimitsFromEnv(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure tool rate limits: %w", err)
+	}
+
+	toolCache, err := NewToolCacheFromEnv(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure tool result cache: %w", err)
+	}
+	fetchPool, err := NewWorkerPoolFromEnv(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure fetch workers: %w", err)
+	}
+
+	tools := defineMCPTools()
+	if toolCache != nil {
+		tools = withForceRefresh(tools)
+	}
+
+	server := &MCPServer{
+		whoopClient:         whoopClient,
+		settings:            settings,
+		healthAnalyzer:      healthAnalyzer,
+		baselines:           baselines,
+		burnout:             burnout,
+		annotations:         annotations,
+		annotationSummaries: annotationSummaries,
+		retention:           retention,
+		goals:               goals,
+		redFlagAcks:         redFlagAcks,
+		notifier:            notifier,
+		redactor:            redactor,
+		audit:               audit,
+		cipher:              cipher,
+		reports:             reports,
+		toolLimits:          toolLimits,
+		toolCache:           toolCache,
+		fetchPool:           fetchPool,
+		tracer:              traces,
+		tools:               tools,
+		resources:           defineMCPResources(),
+		initialized:         false,
+		oauthStates:         newOAuthStateStore(oauthStateTTL),
+		started:             time.Now(),
+	}
+	if toolCache != nil {
+		toolCache.scope = server.toolCacheScope
+		toolCache.tokens = whoopClient.TokenGeneration
+	}
+
+	return server, nil
+}
+
+// Run starts the MCP server and handles stdio communication
+func (s *MCPServer) Run() error {
+	return s.Serve(context.Background(), os.Stdin, os.Stdout)
+}
+
+// Close stops the server's tracer, exporting the spans it still holds, or
+// gives up when ctx is done. Call it once the server is no longer serving.
+func (s *MCPServer) Close(ctx context.Context) error {
+	return s.tracer.Shutdown(ctx)
+}
+
+// Serve answers newline-delimited JSON-RPC messages from in on out until in
+// closes or ctx is done
+func (s *MCPServer) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.applyRetention(time.Now())
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := mcp.NewScanner(in)
+		for scanner.Scan() {
+			select {
+			case lines <- append([]byte(nil), scanner.Bytes()...):
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+		close(lines)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case line, ok := <-lines:
+			if !ok {
+				if err := <-readErr; err != nil {
+					return fmt.Errorf("error reading messages: %w", err)
+				}
+				return nil
+			}
+			if response := s.HandleMessage(ctx, line); response != nil {
+				s.writeMessage(out, response)
+			}
+		}
+	}
+}
+
+// HandleMessage answers one JSON-RPC message, returning nil when no response
+// is due (blank lines and failed notifications). Without an ID to answer,
+// parse errors and invalid requests are reported with a null ID.
+func (s *MCPServer) HandleMessage(ctx context.Context, line []byte) *MCPResponse {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return nil
+	}
+	var request MCPRequest
+	if err := json.Unmarshal(line, &request); err != nil {
+		return &MCPResponse{JSONRPC: "2.0", Error: &MCPError{Code: mcp.CodeParseError, Message: "Parse error", Data: err.Error()}}
+	}
+	if request.JSONRPC != "2.0" || request.Method == "" {
+		return &MCPResponse{JSONRPC: "2.0", ID: request.ID, Error: &MCPError{Code: mcp.CodeInvalidRequest, Message: "Invalid Request", Data: `expected "jsonrpc": "2.0" and a method`}}
+	}
+
+	ctx, span := s.tracer.Start(ctx, "mcp "+request.Method, spanServer)
+	span.SetAttribute("rpc.system", "jsonrpc")
+	span.SetAttribute("rpc.method", request.Method)
+	response := s.handleRequest(ctx, &request)
+	if response != nil && response.Error != nil {
+		span.SetAttribute("rpc.jsonrpc.error_code", response.Error.Code)
+		span.End(errors.New(response.Error.Message))
+	} else {
+		span.End(nil)
+	}
+	return response
+}
+
+// handleRequest processes incoming MCP requests
+func (s *MCPServer) handleRequest(ctx context.Context, request *MCPRequest) *MCPResponse {
+	switch request.Method {
+	case "initialize":
+		return s.handleInitialize(request)
+	case "tools/list":
+		return s.handleToolsList(request)
+	case "tools/call":
+		return s.handleToolsCall(ctx, request)
+	case "resources/list":
+		return s.handleResourcesList(request)
+	case "resources/read":
+		return s.handleResourcesRead(ctx, request)
+	default:
+		return s.errorResponse(request.ID, mcp.CodeMethodNotFound, "Method not found", fmt.Sprintf("Unknown method: %s", request.Method))
+	}
+}
+
+// handleInitialize processes the initialize request
+func (s *MCPServer) handleInitialize(request *MCPRequest) *MCPResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Validate API connection; expired credentials leave the server usable in a
+	// degraded state so tools can walk the user through re-authorization
+	if err := s.whoopClient.ValidateConnection(); err != nil {
+		var authErr *AuthRequiredError
+		if !errors.As(err, &authErr) {
+			return s.errorResponse(request.ID, mcp.CodeInternalError, "Internal error", fmt.Sprintf("Failed to connect to Whoop API: %v", err))
+		}
+		log.Printf("Starting in needs-auth state: %v", authErr)
+	} else {
+		// Learn which scopes were granted so tools can fail fast on missing ones
+		s.whoopClient.DetectScopes()
+	}
+
+	s.initialized = true
+
+	var params struct {
+		ClientInfo struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"clientInfo"`
+	}
+	if json.Unmarshal(request.Params, &params) == nil {
+		s.client = strings.TrimSpace(params.ClientInfo.Name + " " + params.ClientInfo.Version)
+	}
+
+	result := map[string]interface{}{
+		"protocolVersion": "2024-11-05",
+		"capabilities": map[string]interface{}{
+			"tools":     map[string]interface{}{},
+			"resources": map[string]interface{}{},
+		},
+		"serverInfo": map[string]interface{}{
+			"name":    "whoop-mcp-server",
+			"version": version,
+		},
+	}
+
+	return s.response(request.ID, result)
+}
+
+// handleToolsList returns the list of available tools
+func (s *MCPServer) handleToolsList(request *MCPRequest) *MCPResponse {
+	if !s.isInitialized() {
+		return s.errorResponse(request.ID, mcp.CodeNotInitialized, "Not initialized", "Server not initialized")
+	}
+
+	result := map[string]interface{}{
+		"tools": s.tools,
+	}
+
+	return s.response(request.ID, result)
+}
+
+// handleToolsCall executes a tool call
+func (s *MCPServer) handleToolsCall(ctx context.Context, request *MCPRequest) *MCPResponse {
+	if !s.isInitialized() {
+		return s.errorResponse(request.ID, mcp.CodeNotInitialized, "Not initialized", "Server not initialized")
+	}
+
+	var params struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+
+	if err := json.Unmarshal(request.Params, &params); err != nil {
+		return s.errorResponse(request.ID, mcp.CodeInvalidParams, "Invalid params", err.Error())
+	}
+
+	content, err := s.chain()(ctx, params.Name, params.Arguments)
+	if err != nil {
+		var authErr *AuthRequiredError
+		if errors.As(err, &authErr) {
+			return s.response(request.ID, map[string]interface{}{
+				"content": []map[string]interface{}{
+					{
+						"type": "text",
+						"text": formatReauthInstructions(authErr, s.freshAuthURL()),
+					},
+				},
+				"isError": true,
+			})
+		}
+		var scopeErr *MissingScopeError
+		if errors.As(err, &scopeErr) {
+			return s.response(request.ID, map[string]interface{}{
+				"content": []map[string]interface{}{
+					{
+						"type": "text",
+						"text": formatMissingScopeInstructions(scopeErr),
+					},
+				},
+				"isError": true,
+			})
+		}
+		var limitErr *ToolRateLimitError
+		if errors.As(err, &limitErr) {
+			return s.response(request.ID, map[string]interface{}{
+				"content": []map[string]interface{}{
+					{
+						"type": "text",
+						"text": limitErr.Error(),
+					},
+				},
+				"isError": true,
+			})
+		}
+		return s.errorResponse(request.ID, mcp.CodeInternalError, "Internal error", s.redact(err.Error()))
+	}
+	return s.response(request.ID, map[string]interface{}{"content": content})
+}
+
+// CallTool runs a tool through the middleware chain, as tools/call does, and
+// returns its content blocks
+func (s *MCPServer) CallTool(ctx context.Context, name string, arguments json.RawMessage) ([]map[string]interface{}, error) {
+	ctx, span := s.tracer.Start(ctx, "call "+name, spanServer)
+	content, err := s.chain()(ctx, name, arguments)
+	span.End(err)
+	return content, err
+}
+
+// Tools lists the tools tools/list advertises
+func (s *MCPServer) Tools() []MCPTool {
+	return append([]MCPTool(nil), s.tools...)
+}
+
+// handleResourcesList returns the list of available resources
+func (s *MCPServer) handleResourcesList(request *MCPRequest) *MCPResponse {
+	if !s.isInitialized() {
+		return s.errorResponse(request.ID, mcp.CodeNotInitialized, "Not initialized", "Server not initialized")
+	}
+
+	result := map[string]interface{}{
+		"resources": s.resources,
+	}
+
+	return s.response(request.ID, result)
+}
+
+// handleResourcesRead reads a specific resource
+func (s *MCPServer) handleResourcesRead(ctx context.Context, request *MCPRequest) *MCPResponse {
+	if !s.isInitialized() {
+		return s.errorResponse(request.ID, mcp.CodeNotInitialized, "Not initialized", "Server not initialized")
+	}
+
+	var params struct {
+		URI string `json:"uri"`
+	}
+
+	if err := json.Unmarshal(request.Params, &params); err != nil {
+		return s.errorResponse(request.ID, mcp.CodeInvalidParams, "Invalid params", err.Error())
+	}
+
+	// Read the resource
+	content, err := s.readResource(ctx, params.URI)
+	s.auditResourceRead(params.URI, err)
+	if err != nil {
+		var authErr *AuthRequiredError
+		if errors.As(err, &authErr) {
+			return s.errorResponse(request.ID, mcp.CodeInternalError, "Re-authorization required", s.reauthErrorData(authErr))
+		}
+		return s.errorResponse(request.ID, mcp.CodeInternalError, "Internal error", s.redact(err.Error()))
+	}
+	content = s.redact(content)
+
+	mimeType := "application/json"
+	for _, resource := range s.resources {
+		if resource.URI == params.URI && resource.MimeType != "" {
+			mimeType = resource.MimeType
+		}
+	}
+
+	result := map[string]interface{}{
+		"contents": []map[string]interface{}{
+			{
+				"uri":      params.URI,
+				"mimeType": mimeType,
+				"text":     content,
+			},
+		},
+	}
+
+	return s.response(request.ID, result)
+}
+
+// redact masks personal details in text bound for the client when redaction
+// is on, learning the account holder's name on first use
+func (s *MCPServer) redact(text string) string {
+	if s.redactor == nil {
+		return text
+	}
+	if s.redactor.NeedsProfile() {
+		if user, err := s.whoopClient.GetUser(context.Background()); err == nil {
+			s.redactor.LearnUser(user)
+		}
+	}
+	if store := s.whoopClient.Accounts(); store != nil {
+		s.redactor.LearnAccounts(store.Summaries())
+	}
+	return s.redactor.Redact(text)
+}
+
+// response builds a successful JSON-RPC response
+func (s *MCPServer) response(id interface{}, result interface{}) *MCPResponse {
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Result:  result,
+	}
+}
+
+// errorResponse builds a JSON-RPC error response, or nil for a notification
+func (s *MCPServer) errorResponse(id interface{}, code int, message string, data interface{}) *MCPResponse {
+	// Don't send error responses for notifications (null or missing ID)
+	if id == nil {
+		log.Printf("Error for notification (no response sent): %s - %v", message, data)
+		return nil
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error: &MCPError{
+			Code:    code,
+			Message: message,
+			Data:    data,
+		},
+	}
+}
+
+// writeMessage writes a message to out as one line
+func (s *MCPServer) writeMessage(out io.Writer, message interface{}) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if _, err := fmt.Fprintf(out, "%s\n", data); err != nil {
+		log.Printf("Error writing message: %v", err)
+	}
+}
+
+// reauthErrorData builds the structured error payload for the needs-auth state
+func (s *MCPServer) reauthErrorData(authErr *AuthRequiredError) map[string]interface{} {
+	data := map[string]interface{}{
+		"status": "needs_auth",
+		"reason": authErr.Reason,
+		"tool":   "setup_whoop_auth",
+	}
+	if authURL := s.freshAuthURL(); authURL != "" {
+		data["auth_url"] = authURL
+	}
+	return data
+}
+
+// freshAuthURL issues a new state and builds an authorization URL for the
+// configured client ID, or returns "" when none is configured
+func (s *MCPServer) freshAuthURL() string {
+	clientID := s.whoopClient.ClientID()
+	if clientID == "" {
+		return ""
+	}
+	state, err := s.oauthStates.Issue()
+	if err != nil {
+		log.Printf("Failed to issue OAuth state: %v", err)
+		return ""
+	}
+	return buildAuthURL(clientID, state)
+}
+
+// isInitialized checks if the server is initialized
+func (s *MCPServer) isInitialized() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.initialized
+}
+
+// defineMCPTools defines the available MCP tools
+func defineMCPTools() []MCPTool {
+	return []MCPTool{
+		{
+			Name:        "get_health_summary",
+			Description: "Get a comprehensive health summary for therapy sessions including recovery trends, sleep analysis, stress indicators, and actionable insights; datasets that cannot be fetched are listed in a data gaps section instead of failing the summary",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": map[string]interface{}{
+						"type":        "string",
+						"description": "Start date in YYYY-MM-DD format or an expression such as \"last week\", \"past 30 days\", \"March\", or \"yesterday\"",
+					},
+					"end_date": map[string]interface{}{
+						"type":        "string",
+						"description": "End date in YYYY-MM-DD format or an expression such as \"yesterday\" (default: today, or the end of a range like \"last week\")",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days starting at start_date, instead of end_date",
+						"minimum":     1,
+					},
+					"min_severity": map[string]interface{}{
+						"type":        "string",
+						"description": "Only include discussion points at or above this severity (default: info)",
+						"enum":        []string{"info", "concern", "alert"},
+					},
+					"max_insights": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum discussion points to list, highest priority first; the rest are summarized (default: 5)",
+						"minimum":     1,
+						"maximum":     maxInsightsLimit,
+					},
+					"audience":     audienceProperty(),
+					"locale":       localeProperty(),
+					"units":        unitsProperty(),
+					"stress_model": stressModelProperty(),
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+				Required: []string{"start_date"},
+			},
+		},
+		{
+			Name:        "analyze_stress_indicators",
+			Description: "Analyze physiological stress markers from HRV suppression, resting heart rate elevation, sleep debt, and strain taken on poor recoveries to identify mental health concerns",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": map[string]interface{}{
+						"type":        "string",
+						"description": "Start date in YYYY-MM-DD format or an expression such as \"last week\", \"past 30 days\", \"March\", or \"yesterday\"",
+					},
+					"end_date": map[string]interface{}{
+						"type":        "string",
+						"description": "End date in YYYY-MM-DD format or an expression such as \"yesterday\" (default: today, or the end of a range like \"last week\")",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days starting at start_date, instead of end_date",
+						"minimum":     1,
+					},
+					"audience":     audienceProperty(),
+					"locale":       localeProperty(),
+					"stress_model": stressModelProperty(),
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+				Required: []string{"start_date"},
+			},
+		},
+		{
+			Name:        "analyze_sleep_patterns",
+			Description: "Analyze sleep quality, patterns, and their impact on mental health for therapeutic conversations",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": map[string]interface{}{
+						"type":        "string",
+						"description": "Start date in YYYY-MM-DD format or an expression such as \"last week\", \"past 30 days\", \"March\", or \"yesterday\"",
+					},
+					"end_date": map[string]interface{}{
+						"type":        "string",
+						"description": "End date in YYYY-MM-DD format or an expression such as \"yesterday\" (default: today, or the end of a range like \"last week\")",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days starting at start_date, instead of end_date",
+						"minimum":     1,
+					},
+					"audience": audienceProperty(),
+					"locale":   localeProperty(),
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+				Required: []string{"start_date"},
+			},
+		},
+		{
+			Name:        "analyze_activity_patterns",
+			Description: "Analyze workout patterns, exercise habits, strain-recovery balance, and their relationship to mental health and behavioral insights",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": map[string]interface{}{
+						"type":        "string",
+						"description": "Start date in YYYY-MM-DD format or an expression such as \"last week\", \"past 30 days\", \"March\", or \"yesterday\"",
+					},
+					"end_date": map[string]interface{}{
+						"type":        "string",
+						"description": "End date in YYYY-MM-DD format or an expression such as \"yesterday\" (default: today, or the end of a range like \"last week\")",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days starting at start_date, instead of end_date",
+						"minimum":     1,
+					},
+					"audience": audienceProperty(),
+					"locale":   localeProperty(),
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+				Required: []string{"start_date"},
+			},
+		},
+		{
+			Name:        "analyze_health_trends",
+			Description: "Analyze trends in recovery, sleep, or strain over up to a year, with weekly/monthly/quarterly aggregation and a regression slope with confidence interval",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"metric": map[string]interface{}{
+						"type":        "string",
+						"description": "Metric to analyze: recovery, sleep, or strain",
+						"enum":        []string{"recovery", "sleep", "strain"},
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days to analyze (default: 14)",
+						"minimum":     7,
+						"maximum":     maxTrendDays,
+					},
+					"granularity": map[string]interface{}{
+						"type":        "string",
+						"description": "Aggregation bucket size (default: weekly up to 120 days, monthly beyond)",
+						"enum":        []string{granularityWeekly, granularityMonthly, granularityQuarterly},
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+				Required: []string{"metric"},
+			},
+		},
+		{
+			Name:        "analyze_hrv",
+			Description: "Deep-dive into heart rate variability: personal baseline, day-to-day variability, 7-day rolling average vs. baseline, and sustained suppression as an autonomic stress signal",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days of history to analyze (default: 60)",
+						"minimum":     14,
+						"maximum":     180,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+					"locale": localeProperty(),
+				},
+			},
+		},
+		{
+			Name:        "analyze_resting_hr",
+			Description: "Track resting heart rate baseline drift, overnight elevations, and whether late workouts or high-strain days raise next-morning RHR, with week-over-week and month-over-month summaries",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days of history to analyze (default: 60)",
+						"minimum":     14,
+						"maximum":     180,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "analyze_vitals",
+			Description: "Analyze overnight respiratory rate, blood oxygen (SpO2), and skin temperature against personal baselines to spot spikes, drops, and possible illness onset",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days of history to analyze (default: 30)",
+						"minimum":     7,
+						"maximum":     180,
+					},
+					"units": unitsProperty(),
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "predict_illness_risk",
+			Description: "Early-warning score combining skin temperature, respiratory rate, resting heart rate, and HRV deviations from baseline, with an explanation of each contributing signal",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Days of history used for baselines (default: 30)",
+						"minimum":     10,
+						"maximum":     90,
+					},
+					"units": unitsProperty(),
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "analyze_circadian",
+			Description: "Analyze sleep timing: average bed and wake times, bedtime variability, social jetlag between workdays and weekends, chronotype, and a personalized optimal bedtime",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days of history to analyze (default: 28)",
+						"minimum":     7,
+						"maximum":     180,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "detect_travel",
+			Description: "Detect timezone changes across sleep records, estimate how many nights sleep timing took to adapt after each (jet lag), and optionally log travel days to the journal. Days around each change are left out of personal baselines",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days of history to analyze (default: 60)",
+						"minimum":     7,
+						"maximum":     180,
+					},
+					"annotate": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Log a travel-tagged journal annotation on each travel day not already annotated (default: false)",
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "analyze_sleep_debt",
+			Description: "Build a nightly sleep-debt ledger that accumulates deficits and surpluses, projects how many nights it will take to repay the debt at current habits, and returns a chart-ready daily series",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days of history to include (default: 28)",
+						"minimum":     7,
+						"maximum":     180,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "correlate_metrics",
+			Description: "Compute Pearson and Spearman correlations, optionally lagged, between two daily metrics (e.g. strain → next-day recovery, sleep duration → HRV) with confidence intervals and a plain-language interpretation",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"metric_x": map[string]interface{}{
+						"type":        "string",
+						"description": "Leading metric",
+						"enum":        metricKeys(),
+					},
+					"metric_y": map[string]interface{}{
+						"type":        "string",
+						"description": "Following metric",
+						"enum":        metricKeys(),
+					},
+					"lag": map[string]interface{}{
+						"type":        "integer",
+						"description": "Days metric_y is measured after metric_x (default: 0)",
+						"minimum":     0,
+						"maximum":     7,
+					},
+					"max_lag": map[string]interface{}{
+						"type":        "integer",
+						"description": "Scan every lag from 0 to max_lag and highlight the strongest",
+						"minimum":     0,
+						"maximum":     7,
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days of history to analyze (default: 60)",
+						"minimum":     14,
+						"maximum":     180,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+				Required: []string{"metric_x", "metric_y"},
+			},
+		},
+		{
+			Name:        "analyze_weekly_rhythm",
+			Description: "Break recovery, HRV, sleep, and strain down by day of the week to surface recurring patterns like Sunday-night insomnia or Monday recovery crashes",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days of history to analyze (default: 56)",
+						"minimum":     14,
+						"maximum":     180,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "detect_anomalies",
+			Description: "Flag individual days where recovery, HRV, resting HR, sleep, or strain deviated sharply from the user's rolling 28-day baseline, listing the likely contributing metric for each",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days to screen (default: 60)",
+						"minimum":     14,
+						"maximum":     365,
+					},
+					"method": map[string]interface{}{
+						"type":        "string",
+						"description": "Baseline method: median ± MAD (robust, default) or mean ± SD z-scores",
+						"enum":        []string{anomalyMethodMAD, anomalyMethodZScore},
+					},
+					"threshold": map[string]interface{}{
+						"type":        "number",
+						"description": "Score beyond which a day is flagged (default: 3.0)",
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "get_personal_baselines",
+			Description: "Show the user's 30- and 90-day personal baselines for HRV, resting HR, recovery, sleep need, and strain tolerance, and the personalized alert thresholds derived from them",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"refresh": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Recompute from the latest data instead of using the stored baseline (default: false)",
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "analyze_training_load",
+			Description: "Training load management: acute:chronic workload ratio (ACWR) over day strain, monotony, and strain variability, with detraining/optimal/overreaching zones and load guidance",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Days of strain history to analyze (default: 42)",
+						"minimum":     chronicLoadDays,
+						"maximum":     120,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "get_workout_details",
+			Description: "List individual workouts in a date range with sport, duration, strain, average/max heart rate, calories, and distance, plus per-sport aggregates for comparing activities",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": map[string]interface{}{
+						"type":        "string",
+						"description": "Start date in YYYY-MM-DD format or an expression such as \"last week\", \"past 30 days\", \"March\", or \"yesterday\"",
+					},
+					"end_date": map[string]interface{}{
+						"type":        "string",
+						"description": "End date in YYYY-MM-DD format or an expression such as \"yesterday\" (default: today, or the end of a range like \"last week\")",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days starting at start_date, instead of end_date",
+						"minimum":     1,
+					},
+					"sport": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional sport filter, e.g. \"running\" or \"weightlifting\"",
+					},
+					"units": unitsProperty(),
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+				Required: []string{"start_date"},
+			},
+		},
+		{
+			Name:        "analyze_energy",
+			Description: "Analyze daily total and active energy expenditure in kcal with trends, and screen for under-fueling (sustained large deficits during high strain) when intake is provided",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days to analyze (default: 28)",
+						"minimum":     7,
+						"maximum":     120,
+					},
+					"intake_kcal": map[string]interface{}{
+						"type":        "number",
+						"description": "Optional typical daily calorie intake, applied to every day",
+					},
+					"intake_by_date": map[string]interface{}{
+						"type":                 "object",
+						"description":          "Optional per-day intake in kcal keyed by YYYY-MM-DD; overrides intake_kcal",
+						"additionalProperties": map[string]interface{}{"type": "number"},
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "forecast_recovery",
+			Description: "Project tomorrow's likely recovery range from recent strain, sleep debt, and the user's own recovery dynamics, with the sleep needed tonight to reach a green recovery and the dominant factor",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Days of history to fit the model on (default: 60)",
+						"minimum":     21,
+						"maximum":     180,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "burnout_risk",
+			Description: "Score longitudinal burnout risk (0-100) from weeks of declining HRV, rising resting HR, poor sleep consistency, and chronic strain; each run stores a weekly snapshot so the score's history can be tracked",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"weeks": map[string]interface{}{
+						"type":        "integer",
+						"description": "Weeks of history to analyze (default: 12)",
+						"minimum":     4,
+						"maximum":     26,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "log_annotation",
+			Description: "Record a subjective journal note on a date (e.g. \"started new medication\", \"big work deadline\", \"alcohol 3 drinks\"), optionally with a 1-10 mood rating and symptom severities; notes are shown alongside every analysis covering that date",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"note": map[string]interface{}{
+						"type":        "string",
+						"description": "The note to record (optional when mood or symptoms are given)",
+					},
+					"mood": map[string]interface{}{
+						"type":        "integer",
+						"description": "Overall mood from 1 (worst) to 10 (best)",
+						"minimum":     minRating,
+						"maximum":     maxRating,
+					},
+					"symptoms": map[string]interface{}{
+						"type":                 "object",
+						"additionalProperties": map[string]interface{}{"type": "integer", "minimum": minRating, "maximum": maxRating},
+						"description":          "Symptom severities from 1 (mild) to 10 (severe), e.g. {\"anxiety\": 7, \"headache\": 3}",
+					},
+					"date": map[string]interface{}{
+						"type":        "string",
+						"description": "Date the note applies to in YYYY-MM-DD format or an expression like \"yesterday\" (default: today)",
+					},
+					"tags": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Optional tags such as medication, work, alcohol, illness",
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "list_annotations",
+			Description: "List journal notes recorded with log_annotation, optionally filtered by date range and tag",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": map[string]interface{}{
+						"type":        "string",
+						"description": "Start date in YYYY-MM-DD format (default: 90 days ago)",
+					},
+					"end_date": map[string]interface{}{
+						"type":        "string",
+						"description": "End date in YYYY-MM-DD format (default: today)",
+					},
+					"tag": map[string]interface{}{
+						"type":        "string",
+						"description": "Only list notes with this tag",
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "correlate_mood",
+			Description: "Relate mood or symptom ratings logged with log_annotation to recovery, HRV, resting HR, and sleep on the same and following day",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"scale": map[string]interface{}{
+						"type":        "string",
+						"description": "\"mood\" or a logged symptom name such as anxiety (default: mood)",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days of history to analyze (default: 60)",
+						"minimum":     14,
+						"maximum":     180,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "analyze_intervention",
+			Description: "Evaluate whether a medication, supplement, or habit change is helping: compare sleep, HRV, resting HR, and recovery before and after its start date with effect sizes and significance, optionally skipping a washout period",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": map[string]interface{}{
+						"type":        "string",
+						"description": "Date the intervention started in YYYY-MM-DD format or an expression like \"3 weeks ago\"",
+					},
+					"annotation_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "Use the date and note of a logged annotation instead of start_date",
+					},
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the intervention, e.g. \"sertraline 50mg\"",
+					},
+					"window_days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Days compared on each side of the start (default: 28)",
+						"minimum":     7,
+						"maximum":     90,
+					},
+					"washout_days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Days after the start to exclude while the change takes effect (default: 0)",
+						"minimum":     0,
+						"maximum":     42,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "analyze_late_night_impact",
+			Description: "Detect probable alcohol or late-night nights from elevated resting HR, suppressed HRV, reduced REM, and later bedtimes, quantify the next-day recovery penalty, and report how often they happen each month",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days of history to analyze (default: 90)",
+						"minimum":     21,
+						"maximum":     365,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "analyze_late_exercise",
+			Description: "Analyze how workouts finishing close to bedtime affect that night's time awake in bed, sleep efficiency, and disturbances, with a personalized \"finish training before\" time",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"window_hours": map[string]interface{}{
+						"type":        "number",
+						"description": "Workouts ending within this many hours of bed count as late (default: 3)",
+						"minimum":     1,
+						"maximum":     maxLateExerciseGap,
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days of history to analyze (default: 90)",
+						"minimum":     21,
+						"maximum":     365,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "set_goal",
+			Description: "Set a weekly goal such as \"average 7.5h sleep\" (metric=sleep_hours, type=average, target=7.5), \"recovery ≥ 60% five days a week\" (metric=recovery, type=days, target=60, days_per_week=5), or \"3 workouts a week\" (metric=workouts, type=count, target=3); or remove one by ID",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"metric": map[string]interface{}{
+						"type":        "string",
+						"description": "Daily metric, or workouts for count goals",
+						"enum":        append(metricKeys(), goalMetricWorkouts),
+					},
+					"type": map[string]interface{}{
+						"type":        "string",
+						"description": "average: weekly mean meets the target; days: the target is met on days_per_week days; count: number of workouts per week",
+						"enum":        []string{goalAverage, goalDays, goalCount},
+					},
+					"target": map[string]interface{}{
+						"type":        "number",
+						"description": "Target value in the metric's unit, or workouts per week",
+					},
+					"days_per_week": map[string]interface{}{
+						"type":        "integer",
+						"description": "Days each week the target must be met (days goals only)",
+						"minimum":     1,
+						"maximum":     7,
+					},
+					"comparison": map[string]interface{}{
+						"type":        "string",
+						"description": "Whether the target is a floor or a ceiling (default: at_least, or at_most for resting HR and strain)",
+						"enum":        []string{"at_least", "at_most"},
+					},
+					"remove_goal_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "Remove the goal with this ID instead of adding one",
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "check_goals",
+			Description: "Report weekly progress and streaks for every goal set with set_goal",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"weeks": map[string]interface{}{
+						"type":        "integer",
+						"description": "Weeks of history to show, including the current week (default: 4)",
+						"minimum":     1,
+						"maximum":     26,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "acknowledge_red_flag",
+			Description: "Acknowledge a known, already-discussed red flag (e.g. short sleep with a newborn) so later reports list it under Acknowledged Issues instead of raising it again; or withdraw an acknowledgement by ID",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"flag_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Red flag type to acknowledge, as shown by list_red_flags",
+						"enum":        redFlagTypes,
+					},
+					"reason": map[string]interface{}{
+						"type":        "string",
+						"description": "Why the flag is expected, shown next to it in reports",
+					},
+					"until": map[string]interface{}{
+						"type":        "string",
+						"description": "Last day the acknowledgement covers: YYYY-MM-DD or an expression like \"in 6 weeks\" (default: until withdrawn)",
+					},
+					"remove_acknowledgement_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "Withdraw the acknowledgement with this ID instead of adding one",
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "list_red_flags",
+			Description: "List current red flags, marking those demoted by acknowledge_red_flag, together with every acknowledgement and its ID",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Days of data to check for red flags (default: 14)",
+						"minimum":     3,
+						"maximum":     90,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "generate_weekly_report",
+			Description: "Produce a consistent Monday-to-Sunday markdown report (recovery, sleep, strain, highlights, red flags, goal progress, journal notes, and discussion prompts) ready to paste into a therapy or coaching session agenda",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"week_start": map[string]interface{}{
+						"type":        "string",
+						"description": "Any date in the week to report in YYYY-MM-DD format or an expression like \"last week\" (default: last complete week)",
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+					"locale": localeProperty(),
+				},
+			},
+		},
+		{
+			Name:        "render_chart",
+			Description: "Render a line chart of a daily metric (recovery, HRV, resting HR, sleep duration, strain) as a PNG or SVG image",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": recentStartDateProperty(),
+					"end_date":   recentEndDateProperty(),
+					"metric": map[string]interface{}{
+						"type":        "string",
+						"description": "Metric to plot",
+						"enum":        metricKeys(),
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days to plot (default: 30)",
+						"minimum":     7,
+						"maximum":     365,
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"description": "Image format (default: png)",
+						"enum":        chartFormats,
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+				Required: []string{"metric"},
+			},
+		},
+		{
+			Name:        "export_data",
+			Description: "Export raw recovery, sleep, workout, and cycle records for a date range as CSV (inline or written to a file path) for spreadsheets or R, or as a versioned NDJSON archive with a manifest",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": map[string]interface{}{
+						"type":        "string",
+						"description": "Start date in YYYY-MM-DD format or an expression such as \"last week\", \"past 30 days\", \"March\", or \"yesterday\"",
+					},
+					"end_date": map[string]interface{}{
+						"type":        "string",
+						"description": "End date in YYYY-MM-DD format or an expression such as \"yesterday\" (default: today, or the end of a range like \"last week\")",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days starting at start_date, instead of end_date",
+						"minimum":     1,
+					},
+					"datasets": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string", "enum": exportDatasets},
+						"description": "Record types to export (default: all)",
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"description": "csv, or ndjson for an archive of raw Whoop records plus manifest.json; ndjson requires path (default: csv)",
+						"enum":        []string{"csv", "ndjson"},
+					},
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional output location: a .csv file when exporting one dataset, otherwise a directory that receives <dataset>.csv files. Omit to return the CSV inline",
+					},
+					"units": unitsProperty(),
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+				Required: []string{"start_date"},
+			},
+		},
+		{
+			Name:        "export_health_archive",
+			Description: "Convert Whoop workouts and sleep for a date range into an Apple Health export.xml or Google Fit sessions and datasets JSON, for users moving their history to another ecosystem, or recovery, HRV, resting HR, SpO2, and sleep into a FHIR R4 Observation bundle for clinicians",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": map[string]interface{}{
+						"type":        "string",
+						"description": "Start date in YYYY-MM-DD format or an expression such as \"last week\", \"past 30 days\", \"March\", or \"yesterday\"",
+					},
+					"end_date": map[string]interface{}{
+						"type":        "string",
+						"description": "End date in YYYY-MM-DD format or an expression such as \"yesterday\" (default: today, or the end of a range like \"last week\")",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days starting at start_date, instead of end_date",
+						"minimum":     1,
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"description": "apple_health for HealthKit export.xml, google_fit for Fit REST API sessions and datasets, or fhir for a FHIR R4 Bundle of Observations",
+						"enum":        healthArchiveFormats,
+					},
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional output file, or a directory that receives export.xml / google_fit.json / fhir_bundle.json. Omit to return the archive inline",
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+				Required: []string{"start_date", "end_date", "format"},
+			},
+		},
+		{
+			Name:        "export_workout_tcx",
+			Description: "Export each workout with distance in a date range as its own TCX file (time, distance, heart rate, calories, and strain; no GPS) for importing into Strava, TrainingPeaks, or Garmin Connect",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": map[string]interface{}{
+						"type":        "string",
+						"description": "Start date in YYYY-MM-DD format or an expression such as \"last week\", \"past 30 days\", \"March\", or \"yesterday\"",
+					},
+					"end_date": map[string]interface{}{
+						"type":        "string",
+						"description": "End date in YYYY-MM-DD format or an expression such as \"yesterday\" (default: today, or the end of a range like \"last week\")",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days starting at start_date, instead of end_date",
+						"minimum":     1,
+					},
+					"sport": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional sport name filter, e.g. running",
+					},
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional directory that receives one .tcx file per workout. Omit to return the files inline",
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+				Required: []string{"start_date"},
+			},
+		},
+		{
+			Name:        "export_calendar",
+			Description: "Export workouts, and optionally sleep, for a date range as an iCalendar (.ics) file to overlay physiological history on a personal calendar",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"start_date": map[string]interface{}{
+						"type":        "string",
+						"description": "Start date in YYYY-MM-DD format or an expression such as \"last week\", \"past 30 days\", \"March\", or \"yesterday\"",
+					},
+					"end_date": map[string]interface{}{
+						"type":        "string",
+						"description": "End date in YYYY-MM-DD format or an expression such as \"yesterday\" (default: today, or the end of a range like \"last week\")",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days starting at start_date, instead of end_date",
+						"minimum":     1,
+					},
+					"include_sleep": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Also add sleeps and naps as events (default: false)",
+					},
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional output .ics file, or a directory that receives whoop.ics. Omit to return the calendar inline",
+					},
+					"units": unitsProperty(),
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+				Required: []string{"start_date"},
+			},
+		},
+		{
+			Name:        "get_storage_footprint",
+			Description: "Report how much health data the server keeps on local disk, whether it is encrypted, and the retention policy that folds old journal entries into weekly summaries",
+			InputSchema: MCPInputSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "server_status",
+			Description: "Troubleshoot failing queries: report the server version, authorization state, token expiry, granted scopes, rate-limit budget, cache contents, and how fresh the locally synced baselines, burnout history, and reports are",
+			InputSchema: MCPInputSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "analyze_seasonal",
+			Description: "Compare seasons within and across years (e.g. winter vs summer sleep, year-over-year HRV, training volume cycles) for users with at least 6 months of history",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"months": map[string]interface{}{
+						"type":        "integer",
+						"description": "Months of history to analyze (default: 24)",
+						"minimum":     minSeasonalMonths,
+						"maximum":     maxSeasonalMonths,
+					},
+					"hemisphere": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{hemisphereNorth, hemisphereSouth},
+						"description": "Hemisphere used to name seasons (default: north)",
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+			},
+		},
+		rawDataTool("get_raw_recovery", "recovery"),
+		rawDataTool("get_raw_sleep", "sleep"),
+		rawDataTool("get_raw_workouts", "workout"),
+		rawDataTool("get_raw_cycles", "cycle"),
+		{
+			Name:        "compare_periods",
+			Description: "Compare two date ranges side by side (e.g. two weeks before vs. after starting a medication) across recovery, HRV, resting HR, sleep, and strain, with statistical significance of each change",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"period_a_start": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the first (baseline) period in YYYY-MM-DD format or an expression such as \"last month\"",
+					},
+					"period_a_end": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the first period in YYYY-MM-DD format or an expression; omit when the start is a range like \"last month\"",
+					},
+					"period_a_label": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional label for the first period (default: \"Before\")",
+					},
+					"period_b_start": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the second (comparison) period in YYYY-MM-DD format or an expression such as \"last month\"",
+					},
+					"period_b_end": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the second period in YYYY-MM-DD format or an expression; omit when the start is a range like \"last month\"",
+					},
+					"period_b_label": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional label for the second period (default: \"After\")",
+					},
+					"user_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+					},
+				},
+				Required: []string{"period_a_start", "period_b_start"},
+			},
+		},
+		{
+			Name:        "setup_whoop_auth",
+			Description: "Guide user through Whoop OAuth setup process",
+			InputSchema: MCPInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"client_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Whoop app client ID (optional, will generate URL if provided)",
+					},
+					"authorization_code": map[string]interface{}{
+						"type":        "string",
+						"description": "Authorization code from Whoop (optional, for token exchange)",
+					},
+					"client_secret": map[string]interface{}{
+						"type":        "string",
+						"description": "Whoop app client secret (required if authorization_code provided)",
+					},
+					"state": map[string]interface{}{
+						"type":        "string",
+						"description": "State parameter from the callback URL (required with authorization_code)",
+					},
+					"auto_callback": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Start a temporary localhost callback server that captures the code and stores tokens automatically (requires client_id and client_secret)",
+					},
+					"check_status": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Report the status of a pending automatic authorization",
+					},
+					"reveal_tokens": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Include the full access and refresh tokens in the response instead of masked previews (they will appear in the chat transcript)",
+						"default":     false,
+					},
+				},
+			},
+		},
+	}
+}
+
+// defineMCPResources defines the available MCP resources
+func defineMCPResources() []MCPResource {
+	return []MCPResource{
+		{
+			URI:         "whoop://user/profile",
+			Name:        "User Profile",
+			Description: "Basic user profile information",
+			MimeType:    "application/json",
+		},
+		{
+			URI:         "whoop://health/recent",
+			Name:        "Recent Health Data",
+			Description: "Most recent recovery, sleep, and activity data",
+			MimeType:    "application/json",
+		},
+		{
+			URI:         "whoop://health/today",
+			Name:        "Today Snapshot",
+			Description: "Current cycle strain, this morning's recovery, and last night's sleep in one compact document",
+			MimeType:    "application/json",
+		},
+		{
+			URI:         "whoop://health/yesterday",
+			Name:        "Yesterday Snapshot",
+			Description: "Previous cycle strain, recovery, and sleep in one compact document",
+			MimeType:    "application/json",
+		},
+		{
+			URI:         "whoop://sports",
+			Name:        "Sports Catalog",
+			Description: "Whoop sport ID to name mapping used to label workouts",
+			MimeType:    "application/json",
+		},
+		{
+			URI:         "whoop://debug/rate-limit",
+			Name:        "Rate Limit Status",
+			Description: "Current limiter state, requests made this minute, and estimated remaining Whoop quota",
+			MimeType:    "application/json",
+		},
+		{
+			URI:         "whoop://accounts",
+			Name:        "Configured Accounts",
+			Description: "Additional consenting accounts that can be queried via the user_id argument",
+			MimeType:    "application/json",
+		},
+		{
+			URI:         "whoop://auth/scopes",
+			Name:        "Granted Scopes",
+			Description: "OAuth scopes granted to the current token, missing scopes, and granted scopes this server does not use",
+			MimeType:    "application/json",
+		},
+		{
+			URI:         "whoop://insights/burnout",
+			Name:        "Burnout Risk",
+			Description: "Current burnout-risk score, its components, and the stored weekly history",
+			MimeType:    "application/json",
+		},
+		{
+			URI:         "whoop://reports/latest",
+			Name:        "Latest Scheduled Report",
+			Description: "The most recent weekly or monthly report written by scheduler mode (--schedule)",
+			MimeType:    "text/markdown",
+		},
+		{
+			URI:         "whoop://audit/log",
+			Name:        "Audit Log",
+			Description: "The most recent reads of health data: tool or resource, date range, whose data, client, session, and time",
+			MimeType:    "application/json",
+		},
+	}
+}
+
+// executeToolContent runs a tool and returns its MCP content blocks. Most
+// tools produce a single text block; render_chart returns an image.
+func (s *MCPServer) executeToolContent(ctx context.Context, toolName string, arguments json.RawMessage) ([]map[string]interface{}, error) {
+	if toolName == "render_chart" {
+		return s.executeRenderChartTool(ctx, arguments)
+	}
+	result, err := s.executeTool(ctx, toolName, arguments)
+	if err != nil {
+		return nil, err
+	}
+	if resolved := describeResolvedDates(toolName, arguments, s.now()); resolved != "" {
+		result = resolved + "\n\n" + result
+	}
+	return []map[string]interface{}{{"type": "text", "text": result}}, nil
+}
+
+// executeTool executes a specific tool with the given arguments
+func (s *MCPServer) executeTool(ctx context.Context, toolName string, arguments json.RawMessage) (string, error) {
+	switch toolName {
+	case "get_health_summary":
+		return s.executeHealthSummaryTool(ctx, arguments)
+	case "analyze_stress_indicators":
+		return s.executeStressAnalysisTool(ctx, arguments)
+	case "analyze_sleep_patterns":
+		return s.executeSleepAnalysisTool(ctx, arguments)
+	case "analyze_activity_patterns":
+		return s.executeActivityAnalysisTool(ctx, arguments)
+	case "analyze_health_trends":
+		return s.executeTrendAnalysisTool(ctx, arguments)
+	case "analyze_hrv":
+		return s.executeHRVAnalysisTool(ctx, arguments)
+	case "analyze_resting_hr":
+		return s.executeRHRAnalysisTool(ctx, arguments)
+	case "analyze_vitals":
+		return s.executeVitalsAnalysisTool(ctx, arguments)
+	case "predict_illness_risk":
+		return s.executeIllnessRiskTool(ctx, arguments)
+	case "analyze_circadian":
+		return s.executeCircadianAnalysisTool(ctx, arguments)
+	case "detect_travel":
+		return s.executeDetectTravelTool(ctx, arguments)
+	case "analyze_sleep_debt":
+		return s.executeSleepDebtTool(ctx, arguments)
+	case "correlate_metrics":
+		return s.executeCorrelateMetricsTool(ctx, arguments)
+	case "analyze_weekly_rhythm":
+		return s.executeWeeklyRhythmTool(ctx, arguments)
+	case "detect_anomalies":
+		return s.executeDetectAnomaliesTool(ctx, arguments)
+	case "get_personal_baselines":
+		return s.executePersonalBaselinesTool(ctx, arguments)
+	case "analyze_training_load":
+		return s.executeTrainingLoadTool(ctx, arguments)
+	case "get_workout_details":
+		return s.executeWorkoutDetailsTool(ctx, arguments)
+	case "analyze_energy":
+		return s.executeEnergyAnalysisTool(ctx, arguments)
+	case "forecast_recovery":
+		return s.executeRecoveryForecastTool(ctx, arguments)
+	case "burnout_risk":
+		return s.executeBurnoutRiskTool(ctx, arguments)
+	case "log_annotation":
+		return s.executeLogAnnotationTool(ctx, arguments)
+	case "list_annotations":
+		return s.executeListAnnotationsTool(ctx, arguments)
+	case "correlate_mood":
+		return s.executeCorrelateMoodTool(ctx, arguments)
+	case "analyze_intervention":
+		return s.executeInterventionTool(ctx, arguments)
+	case "analyze_late_night_impact":
+		return s.executeLateNightImpactTool(ctx, arguments)
+	case "analyze_late_exercise":
+		return s.executeLateExerciseTool(ctx, arguments)
+	case "set_goal":
+		return s.executeSetGoalTool(ctx, arguments)
+	case "check_goals":
+		return s.executeCheckGoalsTool(ctx, arguments)
+	case "acknowledge_red_flag":
+		return s.executeAcknowledgeRedFlagTool(ctx, arguments)
+	case "list_red_flags":
+		return s.executeListRedFlagsTool(ctx, arguments)
+	case "generate_weekly_report":
+		return s.executeWeeklyReportTool(ctx, arguments)
+	case "export_data":
+		return s.executeExportDataTool(ctx, arguments)
+	case "export_health_archive":
+		return s.executeExportHealthArchiveTool(ctx, arguments)
+	case "export_workout_tcx":
+		return s.executeExportWorkoutTCXTool(ctx, arguments)
+	case "export_calendar":
+		return s.executeExportCalendarTool(ctx, arguments)
+	case "get_storage_footprint":
+		return s.executeStorageFootprintTool(ctx, arguments)
+	case "server_status":
+		return s.executeServerStatusTool(ctx, arguments)
+	case "analyze_seasonal":
+		return s.executeSeasonalAnalysisTool(ctx, arguments)
+	case "get_raw_recovery", "get_raw_sleep", "get_raw_workouts", "get_raw_cycles":
+		return s.executeRawDataTool(ctx, rawDataTools[toolName], arguments)
+	case "compare_periods":
+		return s.executeComparePeriodsTool(ctx, arguments)
+	case "setup_whoop_auth":
+		return s.executeWhoopAuthSetupTool(ctx, arguments)
+	default:
+		return "", fmt.Errorf("unknown tool: %s", toolName)
+	}
+}
+
+// parseDateRange resolves a tool's date range (see resolveDateRange) to
+// whole days in the user's timezone
+func (s *MCPServer) parseDateRange(input DateRangeInput) (time.Time, time.Time, error) {
+	first, last, err := resolveDateRange(input, s.now())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	startDate, endDate := localDayBounds(first, last)
+	return startDate, endDate, nil
+}
+
+// recentRange resolves the range of a tool in recentRangeDays (see
+// resolveRecentRange) to whole days in the user's timezone
+func (s *MCPServer) recentRange(tool string, input DateRangeInput) (time.Time, time.Time, error) {
+	first, last, err := resolveRecentRange(input, recentRangeDays[tool], s.now())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	startDate, endDate := localDayBounds(first, last)
+	return startDate, endDate, nil
+}
+
+// lastDays is the range of the last days whole days through today in the
+// user's timezone
+func (s *MCPServer) lastDays(days int) (time.Time, time.Time) {
+	today := s.today()
+	return localDayBounds(today.AddDate(0, 0, 1-days), today)
+}
+
+// today is local midnight of the current day in the user's timezone
+func (s *MCPServer) today() time.Time {
+	now := s.now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// now is the current time in the user's timezone
+func (s *MCPServer) now() time.Time {
+	return time.Now().In(s.whoopClient.Timezone().Location())
+}
+
+// executeHealthSummaryTool implements the health summary tool
+func (s *MCPServer) executeHealthSummaryTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input HealthSummaryInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	// Parse dates
+	startDate, endDate, err := s.parseDateRange(input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	minSeverity, maxInsights, err := insightLimits(input.MinSeverity, input.MaxInsights)
+	if err != nil {
+		return "", err
+	}
+	analyzer, err := s.analyzerFor(input.Locale, input.Units)
+	if err != nil {
+		return "", err
+	}
+	if analyzer, err = analyzer.WithStressModel(input.StressModel); err != nil {
+		return "", err
+	}
+	formatter, err := analyzer.reportFormatter(input.Audience)
+	if err != nil {
+		return "", err
+	}
+
+	userID, err := s.accountID(ctx, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	// A failed dataset leaves a gap in the summary rather than failing it
+	data, gaps := s.fetchHealthDataPartial(ctx, startDate, endDate, &userID)
+	if len(gaps) == len(healthDatasets) {
+		return "", gaps[0].Err
+	}
+	data, unscored := scoredHealthData(data)
+	quality := assessDataQuality(data, startDate, endDate, unscored, "recovery", "sleep")
+
+	// Analyze the data
+	baseline := s.personalBaseline(ctx, &userID, false)
+	analysisCtx, stage := startSpan(ctx, "analyze health summary", spanInternal)
+	summary, err := analyzer.AnalyzeHealthSummary(analysisCtx, data.Recoveries, data.Sleeps, data.Workouts, data.Cycles, startDate, endDate, userID, baseline)
+	stage.End(err)
+	if err != nil {
+		return "", fmt.Errorf("failed to analyze health data: %w", err)
+	}
+	summary.TherapyInsights, summary.OmittedInsights = PrioritizeInsights(summary.TherapyInsights, minSeverity, maxInsights)
+	summary.RedFlags, summary.AcknowledgedFlags = partitionRedFlags(summary.RedFlags, s.acknowledgements(input.UserID), s.now())
+	s.notifyRedFlags(summary.RedFlags, endDate, input.UserID)
+
+	_, stage = startSpan(ctx, "format health summary", spanInternal)
+	report := formatter.HealthSummary(summary)
+	stage.End(nil)
+	if section := FormatDataGaps(gaps); section != "" {
+		report += "\n\n" + section
+	}
+	report = withDataQuality(report, quality)
+	if goals := s.goalStatus(analyzer, data, startDate, endDate, input.UserID, "##"); goals != "" {
+		report += "\n\n" + goals
+	}
+	return s.withReportContext(ctx, report, startDate, endDate, input.UserID), nil
+}
+
+// fetchHealthData fetches recovery, sleep, workout, and cycle data
+// concurrently, failing with the first error, which stops the other fetches
+func (s *MCPServer) fetchHealthData(ctx context.Context, startDate, endDate time.Time, userID *int) (*HealthData, error) {
+	data, _, err := s.fetchHealthDatasets(ctx, startDate, endDate, userID, func(error) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// analyzerFor returns the health analyzer for the locale and units tool
+// arguments, keeping the server's configured language and units for any
+// argument left empty
+func (s *MCPServer) analyzerFor(locale, units string) (*HealthAnalyzer, error) {
+	analyzer := s.healthAnalyzer
+	if locale != "" {
+		localizer, err := NewLocalizer(locale)
+		if err != nil {
+			return nil, err
+		}
+		analyzer = analyzer.WithLocale(localizer)
+	}
+	if units != "" {
+		system, err := ParseUnitSystem(units)
+		if err != nil {
+			return nil, err
+		}
+		analyzer = analyzer.WithUnits(system)
+	}
+	return analyzer, nil
+}
+
+// accountID returns *userID, or the authenticated user's ID when userID is
+// nil, so what is stored per account has one key whether or not a call named
+// the account
+func (s *MCPServer) accountID(ctx context.Context, userID *int) (int, error) {
+	if userID != nil {
+		return *userID, nil
+	}
+	user, err := s.whoopClient.GetUser(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user.UserID, nil
+}
+
+// personalBaseline returns the user's persisted baseline, recomputing it from the
+// last baselineLongDays of data when missing, stale, or refresh is set. Failures
+// are logged and yield nil so callers fall back to fixed thresholds.
+func (s *MCPServer) personalBaseline(ctx context.Context, userID *int, refresh bool) *PersonalBaseline {
+	key, err := s.accountID(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to load personal baseline: %v", err)
+		return nil
+	}
+
+	now := s.now()
+	stored, err := s.baselines.Load(key)
+	if err != nil {
+		log.Printf("Failed to load personal baseline: %v", err)
+	}
+	if !refresh && !stored.Stale(now) {
+		return stored
+	}
+
+	baseline, err := s.refreshBaseline(ctx, userID, now)
+	if err != nil {
+		log.Printf("Failed to fetch data for personal baseline: %v", err)
+		return stored
+	}
+	return &baseline
+}
+
+// refreshBaseline recomputes the user's baseline from the last
+// baselineLongDays of data and persists it; only fetch failures are returned
+func (s *MCPServer) refreshBaseline(ctx context.Context, userID *int, now time.Time) (PersonalBaseline, error) {
+	key, err := s.accountID(ctx, userID)
+	if err != nil {
+		return PersonalBaseline{}, err
+	}
+	startDate, endDate := s.lastDays(baselineLongDays)
+	data, _, err := s.fetchScoredHealthData(ctx, startDate, endDate, userID)
+	if err != nil {
+		return PersonalBaseline{}, err
+	}
+
+	baseline := s.healthAnalyzer.ComputeBaseline(data, key, now)
+	if err := s.baselines.Save(baseline); err != nil && !errors.Is(err, errReadOnlyDatastore) {
+		log.Printf("Failed to persist personal baseline: %v", err)
+	}
+	return baseline, nil
+}
+
+// executeStressAnalysisTool implements the stress analysis tool
+func (s *MCPServer) executeStressAnalysisTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input StressAnalysisInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.parseDateRange(input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	analyzer, err := s.analyzerFor(input.Locale, "")
+	if err != nil {
+		return "", err
+	}
+	if analyzer, err = analyzer.WithStressModel(input.StressModel); err != nil {
+		return "", err
+	}
+	formatter, err := analyzer.reportFormatter(input.Audience)
+	if err != nil {
+		return "", err
+	}
+
+	userID, err := s.accountID(ctx, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	// Get recovery data for stress analysis
+	recoveries, err := s.whoopClient.GetRecoveryData(ctx, startDate, endDate, &userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get recovery data: %w", err)
+	}
+
+	sleepData, err := s.whoopClient.GetSleepData(ctx, startDate, endDate, &userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get sleep data: %w", err)
+	}
+
+	// Cycles carry the day strain compared against each recovery
+	cycles, err := s.whoopClient.GetCycleData(ctx, startDate, endDate, &userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cycle data: %w", err)
+	}
+
+	unscored := make(UnscoredRecords)
+	recoveries = scoredRecoveries(recoveries, unscored)
+	sleepData = scoredSleeps(sleepData, unscored)
+	cycles = scoredCycles(cycles, unscored)
+	quality := assessDataQuality(&HealthData{Recoveries: recoveries, Sleeps: sleepData}, startDate, endDate, unscored, "recovery", "sleep")
+
+	// Analyze stress indicators
+	thresholds := s.personalBaseline(ctx, &userID, false).Thresholds()
+	stressIndicators := analyzer.analyzeStressIndicators(recoveries, sleepData, cycles, thresholds)
+
+	report := withDataQuality(formatter.Stress(DateRange{Start: startDate, End: endDate}, stressIndicators), quality)
+
+	return s.withReportContext(ctx, report, startDate, endDate, input.UserID), nil
+}
+
+// executeSleepAnalysisTool implements the sleep analysis tool
+func (s *MCPServer) executeSleepAnalysisTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input SleepAnalysisInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.parseDateRange(input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	analyzer, err := s.analyzerFor(input.Locale, "")
+	if err != nil {
+		return "", err
+	}
+	formatter, err := analyzer.reportFormatter(input.Audience)
+	if err != nil {
+		return "", err
+	}
+
+	userID := 0
+	if input.UserID != nil {
+		userID = *input.UserID
+	}
+
+	sleepData, err := s.whoopClient.GetSleepData(ctx, startDate, endDate, &userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get sleep data: %w", err)
+	}
+
+	unscored := make(UnscoredRecords)
+	sleepData = scoredSleeps(sleepData, unscored)
+	quality := assessDataQuality(&HealthData{Sleeps: sleepData}, startDate, endDate, unscored, "sleep")
+	analysis := s.healthAnalyzer.analyzeSleepPatterns(sleepData)
+
+	report := withDataQuality(formatter.Sleep(DateRange{Start: startDate, End: endDate}, len(sleepData), analysis), quality)
+
+	return s.withReportContext(ctx, report, startDate, endDate, input.UserID), nil
+}
+
+// executeActivityAnalysisTool implements the activity analysis tool
+func (s *MCPServer) executeActivityAnalysisTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input SleepAnalysisInput // Reusing same input structure
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.parseDateRange(input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	analyzer, err := s.analyzerFor(input.Locale, "")
+	if err != nil {
+		return "", err
+	}
+	formatter, err := analyzer.reportFormatter(input.Audience)
+	if err != nil {
+		return "", err
+	}
+
+	userID := 0
+	if input.UserID != nil {
+		userID = *input.UserID
+	}
+
+	workouts, err := s.whoopClient.GetWorkoutData(ctx, startDate, endDate, &userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get workout data: %w", err)
+	}
+
+	cycles, err := s.whoopClient.GetCycleData(ctx, startDate, endDate, &userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cycle data: %w", err)
+	}
+
+	recoveries, err := s.whoopClient.GetRecoveryData(ctx, startDate, endDate, &userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get recovery data: %w", err)
+	}
+
+	unscored := make(UnscoredRecords)
+	workouts = scoredWorkouts(workouts, unscored)
+	cycles = scoredCycles(cycles, unscored)
+	recoveries = scoredRecoveries(recoveries, unscored)
+	quality := assessDataQuality(&HealthData{Workouts: workouts, Cycles: cycles}, startDate, endDate, unscored, "cycle")
+	patterns := s.healthAnalyzer.analyzeActivityPatterns(workouts, cycles, recoveries)
+
+	report := withDataQuality(formatter.Activity(DateRange{Start: startDate, End: endDate}, len(workouts), patterns), quality)
+
+	return s.withReportContext(ctx, report, startDate, endDate, input.UserID), nil
+}
+
+// executeTrendAnalysisTool implements the trend analysis tool
+func (s *MCPServer) executeTrendAnalysisTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input TrendAnalysisInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.recentRange("analyze_health_trends", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+	days := rangeDays(startDate, endDate)
+	if days > maxTrendDays {
+		return "", fmt.Errorf("the range must cover at most %d days", maxTrendDays)
+	}
+
+	metricKey, ok := map[string]string{"recovery": "recovery", "sleep": "sleep_hours", "strain": "strain"}[input.Metric]
+	if !ok {
+		return "", fmt.Errorf("unsupported metric: %s", input.Metric)
+	}
+
+	// A year of records is streamed, keeping only what the report needs
+	metric, _ := lookupMetric(metricKey)
+	collected := newTrendSeries(metric)
+	quality, err := s.streamScoredHealthData(ctx, startDate, endDate, input.UserID, collected.add)
+	if err != nil {
+		return "", err
+	}
+	series := sortDatedValues(collected.values)
+
+	spark := trendSparkline{Days: days}
+	if spark.Days > sparklineMaxDays {
+		spark.Days = sparklineMaxDays
+	}
+	spark.Line = sparkline(dailySeries(series, endDate, spark.Days))
+
+	var summary string
+	switch input.Metric {
+	case "recovery":
+		summary = s.formatRecoveryTrend(s.healthAnalyzer.recoveryTrendOf(sortDatedValues(collected.scores)), days, spark)
+	case "sleep":
+		summary = s.formatSleepTrend(s.healthAnalyzer.analyzeSleepPatterns(collected.sleeps), days, spark)
+	case "strain":
+		summary = s.formatStrainTrend(valuesOf(series), days, spark)
+	}
+
+	trend := s.healthAnalyzer.AnalyzeLongTermTrend(metric, series, trendGranularity(input.Granularity, days))
+	return s.withReportContext(ctx, withDataQuality(summary+"\n\n"+s.healthAnalyzer.FormatLongTermTrend(trend), quality), startDate, endDate, input.UserID), nil
+}
+
+// executeHRVAnalysisTool implements the HRV deep-dive tool
+func (s *MCPServer) executeHRVAnalysisTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input HRVAnalysisInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.recentRange("analyze_hrv", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+	analyzer, err := s.analyzerFor(input.Locale, "")
+	if err != nil {
+		return "", err
+	}
+
+	unscored := make(UnscoredRecords)
+	recoveries, err := s.whoopClient.GetRecoveryData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get recovery data: %w", err)
+	}
+	recoveries = scoredRecoveries(recoveries, unscored)
+	quality := assessDataQuality(&HealthData{Recoveries: recoveries}, startDate, endDate, unscored, "recovery")
+
+	return s.withReportContext(ctx, withDataQuality(analyzer.FormatHRVAnalysis(analyzer.AnalyzeHRV(recoveries)), quality), startDate, endDate, input.UserID), nil
+}
+
+// executeRHRAnalysisTool implements the resting heart rate trend tool
+func (s *MCPServer) executeRHRAnalysisTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input RHRAnalysisInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.recentRange("analyze_resting_hr", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	data, quality, err := s.fetchScoredHealthData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	analysis := s.healthAnalyzer.AnalyzeRestingHR(data.Recoveries, data.Workouts, data.Cycles)
+	return s.withReportContext(ctx, withDataQuality(s.healthAnalyzer.FormatRHRAnalysis(analysis), quality), startDate, endDate, input.UserID), nil
+}
+
+// executeVitalsAnalysisTool implements the overnight vitals tool
+func (s *MCPServer) executeVitalsAnalysisTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input VitalsAnalysisInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	analyzer, err := s.analyzerFor("", input.Units)
+	if err != nil {
+		return "", err
+	}
+
+	startDate, endDate, err := s.recentRange("analyze_vitals", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	unscored := make(UnscoredRecords)
+	recoveries, err := s.whoopClient.GetRecoveryData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get recovery data: %w", err)
+	}
+	recoveries = scoredRecoveries(recoveries, unscored)
+	sleepData, err := s.whoopClient.GetSleepDataFiltered(ctx, startDate, endDate, input.UserID, SleepMainOnly)
+	if err != nil {
+		return "", fmt.Errorf("failed to get sleep data: %w", err)
+	}
+	sleepData = scoredSleeps(sleepData, unscored)
+	quality := assessDataQuality(&HealthData{Recoveries: recoveries, Sleeps: sleepData}, startDate, endDate, unscored, "recovery", "sleep")
+
+	return s.withReportContext(ctx, withDataQuality(analyzer.FormatVitalsAnalysis(analyzer.AnalyzeVitals(recoveries, sleepData)), quality), startDate, endDate, input.UserID), nil
+}
+
+// executeIllnessRiskTool implements the illness early-warning tool
+func (s *MCPServer) executeIllnessRiskTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input IllnessRiskInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	analyzer, err := s.analyzerFor("", input.Units)
+	if err != nil {
+		return "", err
+	}
+
+	startDate, endDate, err := s.recentRange("predict_illness_risk", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	unscored := make(UnscoredRecords)
+	recoveries, err := s.whoopClient.GetRecoveryData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get recovery data: %w", err)
+	}
+	recoveries = scoredRecoveries(recoveries, unscored)
+	sleepData, err := s.whoopClient.GetSleepDataFiltered(ctx, startDate, endDate, input.UserID, SleepMainOnly)
+	if err != nil {
+		return "", fmt.Errorf("failed to get sleep data: %w", err)
+	}
+	sleepData = scoredSleeps(sleepData, unscored)
+	quality := assessDataQuality(&HealthData{Recoveries: recoveries, Sleeps: sleepData}, startDate, endDate, unscored, "recovery", "sleep")
+
+	return s.withReportContext(ctx, withDataQuality(analyzer.FormatIllnessRisk(analyzer.PredictIllnessRisk(recoveries, sleepData)), quality), startDate, endDate, input.UserID), nil
+}
+
+// executeCircadianAnalysisTool implements the circadian rhythm tool
+func (s *MCPServer) executeCircadianAnalysisTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input CircadianAnalysisInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.recentRange("analyze_circadian", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	unscored := make(UnscoredRecords)
+	sleepData, err := s.whoopClient.GetSleepDataFiltered(ctx, startDate, endDate, input.UserID, SleepMainOnly)
+	if err != nil {
+		return "", fmt.Errorf("failed to get sleep data: %w", err)
+	}
+	sleepData = scoredSleeps(sleepData, unscored)
+	quality := assessDataQuality(&HealthData{Sleeps: sleepData}, startDate, endDate, unscored, "sleep")
+
+	return s.withReportContext(ctx, withDataQuality(s.healthAnalyzer.FormatCircadianAnalysis(s.healthAnalyzer.AnalyzeCircadian(sleepData)), quality), startDate, endDate, input.UserID), nil
+}
+
+// executeDetectTravelTool implements the travel detection tool, logging
+// travel days to the journal when asked
+func (s *MCPServer) executeDetectTravelTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input DetectTravelInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.recentRange("detect_travel", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	sleepData, err := s.whoopClient.GetSleepDataFiltered(ctx, startDate, endDate, input.UserID, SleepMainOnly)
+	if err != nil {
+		return "", fmt.Errorf("failed to get sleep data: %w", err)
+	}
+	events := s.healthAnalyzer.DetectTravel(sleepData)
+	report := s.healthAnalyzer.FormatTravelAnalysis(events)
+
+	if input.Annotate && len(events) > 0 {
+		logged, err := s.annotateTravel(events, input.UserID)
+		if err != nil {
+			return "", err
+		}
+		report += fmt.Sprintf("\n\nLogged %d travel annotation(s) to the journal.", logged)
+	}
+
+	return s.withAnnotations(report, startDate, endDate, input.UserID), nil
+}
+
+// annotateTravel logs a travel-tagged annotation for each event whose day has
+// none yet, returning how many were logged
+func (s *MCPServer) annotateTravel(events []TravelEvent, userID *int) (int, error) {
+	key := 0
+	if userID != nil {
+		key = *userID
+	}
+
+	logged := 0
+	for _, event := range events {
+		day, err := time.Parse("2006-01-02", event.Date)
+		if err != nil {
+			continue
+		}
+		existing, err := s.annotations.List(key, day, day)
+		if err != nil {
+			return logged, fmt.Errorf("failed to load annotations: %w", err)
+		}
+		if slices.ContainsFunc(existing, func(a Annotation) bool { return a.HasTag("travel") }) {
+			continue
+		}
+		if _, err := s.annotations.Add(Annotation{
+			UserID:    key,
+			Date:      event.Date,
+			Note:      travelNote(event),
+			Tags:      []string{"travel"},
+			CreatedAt: time.Now(),
+		}); err != nil {
+			return logged, fmt.Errorf("failed to save annotation: %w", err)
+		}
+		logged++
+	}
+	return logged, nil
+}
+
+// executeSleepDebtTool implements the sleep-debt ledger tool
+func (s *MCPServer) executeSleepDebtTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input SleepDebtInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.recentRange("analyze_sleep_debt", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	unscored := make(UnscoredRecords)
+	sleepData, err := s.whoopClient.GetSleepData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get sleep data: %w", err)
+	}
+	sleepData = scoredSleeps(sleepData, unscored)
+	quality := assessDataQuality(&HealthData{Sleeps: sleepData}, startDate, endDate, unscored, "sleep")
+
+	return s.withReportContext(ctx, withDataQuality(s.healthAnalyzer.FormatSleepDebtLedger(s.healthAnalyzer.BuildSleepDebtLedger(sleepData)), quality), startDate, endDate, input.UserID), nil
+}
+
+// executeCorrelateMetricsTool implements the metric correlation tool
+func (s *MCPServer) executeCorrelateMetricsTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input CorrelateMetricsInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	metricX, ok := lookupMetric(input.MetricX)
+	if !ok {
+		return "", fmt.Errorf("unsupported metric_x: %s", input.MetricX)
+	}
+	metricY, ok := lookupMetric(input.MetricY)
+	if !ok {
+		return "", fmt.Errorf("unsupported metric_y: %s", input.MetricY)
+	}
+	if input.Lag < 0 || input.MaxLag < 0 || input.Lag > 7 || input.MaxLag > 7 {
+		return "", fmt.Errorf("lag and max_lag must be between 0 and 7")
+	}
+
+	startDate, endDate, err := s.recentRange("correlate_metrics", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	data, quality, err := s.fetchScoredHealthData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	lags := []int{input.Lag}
+	if input.MaxLag > 0 {
+		lags = nil
+		for lag := 0; lag <= input.MaxLag; lag++ {
+			lags = append(lags, lag)
+		}
+	}
+
+	var results []CorrelationResult
+	for _, lag := range lags {
+		results = append(results, s.healthAnalyzer.CorrelateMetrics(data, metricX, metricY, lag))
+	}
+
+	return s.withReportContext(ctx, withDataQuality(s.healthAnalyzer.FormatCorrelationResults(metricX, metricY, results), quality), startDate, endDate, input.UserID), nil
+}
+
+// executeWeeklyRhythmTool implements the day-of-week pattern tool
+func (s *MCPServer) executeWeeklyRhythmTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input WeeklyRhythmInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.recentRange("analyze_weekly_rhythm", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	data, quality, err := s.fetchScoredHealthData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.withReportContext(ctx, withDataQuality(s.healthAnalyzer.FormatWeeklyRhythm(s.healthAnalyzer.AnalyzeWeeklyRhythm(data)), quality), startDate, endDate, input.UserID), nil
+}
+
+// executeDetectAnomaliesTool implements the daily anomaly detection tool
+func (s *MCPServer) executeDetectAnomaliesTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input DetectAnomaliesInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.recentRange("detect_anomalies", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	// Fetch an extra baseline window so the first screened days can be judged
+	data, quality, err := s.fetchScoredHealthData(ctx, startDate.AddDate(0, 0, -anomalyBaselineDays), endDate, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	report := s.healthAnalyzer.DetectAnomalies(data, input.Method, input.Threshold)
+	cutoff := dayKey(startDate)
+	screened := report.Days[:0]
+	for _, day := range report.Days {
+		if day.Date >= cutoff {
+			screened = append(screened, day)
+		}
+	}
+	report.Days = screened
+
+	return s.withReportContext(ctx, withDataQuality(s.healthAnalyzer.FormatAnomalyReport(report), quality), startDate, endDate, input.UserID), nil
+}
+
+// executePersonalBaselinesTool implements the personal baseline tool
+func (s *MCPServer) executePersonalBaselinesTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input PersonalBaselinesInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	baseline := s.personalBaseline(ctx, input.UserID, input.Refresh)
+	if baseline == nil {
+		return "", fmt.Errorf("no personal baseline available; check the server log for fetch errors")
+	}
+
+	return s.healthAnalyzer.FormatPersonalBaseline(*baseline), nil
+}
+
+// executeTrainingLoadTool implements the ACWR training load tool
+func (s *MCPServer) executeTrainingLoadTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input TrainingLoadInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.recentRange("analyze_training_load", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	unscored := make(UnscoredRecords)
+	cycles, err := s.whoopClient.GetCycleData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cycle data: %w", err)
+	}
+	cycles = scoredCycles(cycles, unscored)
+	quality := assessDataQuality(&HealthData{Cycles: cycles}, startDate, endDate, unscored, "cycle")
+
+	return s.withReportContext(ctx, withDataQuality(s.healthAnalyzer.FormatTrainingLoad(s.healthAnalyzer.AnalyzeTrainingLoad(cycles)), quality), startDate, endDate, input.UserID), nil
+}
+
+// executeWorkoutDetailsTool implements the per-workout detail tool
+func (s *MCPServer) executeWorkoutDetailsTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input WorkoutDetailsInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	analyzer, err := s.analyzerFor("", input.Units)
+	if err != nil {
+		return "", err
+	}
+
+	startDate, endDate, err := s.parseDateRange(input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	workouts, err := s.whoopClient.GetWorkoutData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get workout data: %w", err)
+	}
+
+	return s.withReportContext(ctx, analyzer.FormatWorkoutDetails(analyzer.BuildWorkoutDetails(workouts, input.Sport)), startDate, endDate, input.UserID), nil
+}
+
+// executeEnergyAnalysisTool implements the energy expenditure tool
+func (s *MCPServer) executeEnergyAnalysisTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input EnergyAnalysisInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.recentRange("analyze_energy", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	data, quality, err := s.fetchScoredHealthData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	intake := make(map[string]float64)
+	if input.IntakeKcal > 0 {
+		for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+			intake[dayKey(day)] = input.IntakeKcal
+		}
+	}
+	for date, kcal := range input.IntakeByDate {
+		intake[date] = kcal
+	}
+
+	analysis := s.healthAnalyzer.AnalyzeEnergy(data.Cycles, data.Workouts, intake)
+	raised, _ := partitionRedFlags(analysis.RedFlags, s.acknowledgements(input.UserID), s.now())
+	s.notifyRedFlags(raised, endDate, input.UserID)
+	return s.withReportContext(ctx, withDataQuality(s.healthAnalyzer.FormatEnergyAnalysis(analysis), quality), startDate, endDate, input.UserID), nil
+}
+
+// executeRecoveryForecastTool implements the recovery forecasting tool
+func (s *MCPServer) executeRecoveryForecastTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input RecoveryForecastInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.recentRange("forecast_recovery", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	data, quality, err := s.fetchScoredHealthData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.withReportContext(ctx, withDataQuality(s.healthAnalyzer.FormatRecoveryForecast(s.healthAnalyzer.ForecastRecovery(data)), quality), startDate, endDate, input.UserID), nil
+}
+
+// executeLateNightImpactTool implements the alcohol/late-night impact tool
+func (s *MCPServer) executeLateNightImpactTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input LateNightImpactInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.recentRange("analyze_late_night_impact", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	unscored := make(UnscoredRecords)
+	recoveries, err := s.whoopClient.GetRecoveryData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get recovery data: %w", err)
+	}
+	recoveries = scoredRecoveries(recoveries, unscored)
+	sleepData, err := s.whoopClient.GetSleepDataFiltered(ctx, startDate, endDate, input.UserID, SleepMainOnly)
+	if err != nil {
+		return "", fmt.Errorf("failed to get sleep data: %w", err)
+	}
+	sleepData = scoredSleeps(sleepData, unscored)
+	quality := assessDataQuality(&HealthData{Recoveries: recoveries, Sleeps: sleepData}, startDate, endDate, unscored, "recovery", "sleep")
+
+	impact := s.healthAnalyzer.DetectLateNightImpact(recoveries, sleepData)
+	return s.withReportContext(ctx, withDataQuality(s.healthAnalyzer.FormatLateNightImpact(impact), quality), startDate, endDate, input.UserID), nil
+}
+
+// executeLateExerciseTool implements the evening training sleep-interference tool
+func (s *MCPServer) executeLateExerciseTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input LateExerciseInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	window, err := lateExerciseWindow(input.WindowHours)
+	if err != nil {
+		return "", err
+	}
+
+	startDate, endDate, err := s.recentRange("analyze_late_exercise", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	unscored := make(UnscoredRecords)
+	sleepData, err := s.whoopClient.GetSleepDataFiltered(ctx, startDate, endDate, input.UserID, SleepMainOnly)
+	if err != nil {
+		return "", fmt.Errorf("failed to get sleep data: %w", err)
+	}
+	sleepData = scoredSleeps(sleepData, unscored)
+	workouts, err := s.whoopClient.GetWorkoutData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get workout data: %w", err)
+	}
+	workouts = scoredWorkouts(workouts, unscored)
+	quality := assessDataQuality(&HealthData{Sleeps: sleepData, Workouts: workouts}, startDate, endDate, unscored, "sleep")
+
+	analysis := s.healthAnalyzer.AnalyzeLateExercise(sleepData, workouts, window)
+	return s.withReportContext(ctx, withDataQuality(s.healthAnalyzer.FormatLateExerciseAnalysis(analysis), quality), startDate, endDate, input.UserID), nil
+}
+
+// executeBurnoutRiskTool implements the burnout risk tool
+func (s *MCPServer) executeBurnoutRiskTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input BurnoutRiskInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	weeks := input.Weeks
+	if weeks == 0 {
+		weeks = 12
+	}
+
+	risk, err := s.burnoutRisk(ctx, weeks, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	startDate, endDate := s.lastDays(weeks * 7)
+	return s.withReportContext(ctx, s.healthAnalyzer.FormatBurnoutRisk(risk), startDate, endDate, input.UserID), nil
+}
+
+// burnoutRisk scores burnout over the last weeks and records this week's
+// snapshot. Storage failures are logged so the score is still returned.
+func (s *MCPServer) burnoutRisk(ctx context.Context, weeks int, userID *int) (BurnoutRisk, error) {
+	if weeks == 0 {
+		weeks = 12
+	}
+	key := 0
+	if userID != nil {
+		key = *userID
+	}
+
+	startDate, endDate := s.lastDays(weeks * 7)
+	data, _, err := s.fetchScoredHealthData(ctx, startDate, endDate, userID)
+	if err != nil {
+		return BurnoutRisk{}, err
+	}
+
+	risk := s.healthAnalyzer.AssessBurnoutRisk(data, key)
+	if risk.Current.Level == "insufficient_data" {
+		return risk, nil
+	}
+	history, err := s.burnout.Record(risk.Current)
+	if err != nil {
+		log.Printf("Failed to persist burnout snapshot: %v", err)
+		history = []BurnoutSnapshot{risk.Current}
+	}
+	risk.History = history
+	return risk, nil
+}
+
+// executeLogAnnotationTool implements the journal note tool
+func (s *MCPServer) executeLogAnnotationTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input LogAnnotationInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	note := strings.TrimSpace(input.Note)
+	if note == "" && input.Mood == nil && len(input.Symptoms) == 0 {
+		return "", fmt.Errorf("a note, mood, or symptoms are required")
+	}
+	if input.Mood != nil && !validRating(*input.Mood) {
+		return "", fmt.Errorf("mood must be between %d and %d", minRating, maxRating)
+	}
+	var symptoms map[string]int
+	for symptom, severity := range input.Symptoms {
+		if !validRating(severity) {
+			return "", fmt.Errorf("severity for %s must be between %d and %d", symptom, minRating, maxRating)
+		}
+		if symptom = strings.ToLower(strings.TrimSpace(symptom)); symptom == "" || symptom == "mood" {
+			return "", fmt.Errorf("invalid symptom name %q", symptom)
+		}
+		if symptoms == nil {
+			symptoms = make(map[string]int)
+		}
+		symptoms[symptom] = severity
+	}
+
+	date := dayKey(time.Now())
+	if input.Date != "" {
+		parsed, err := parseDate(input.Date, s.now())
+		if err != nil {
+			return "", fmt.Errorf("invalid date: %w", err)
+		}
+		date = dayKey(parsed)
+	}
+
+	userID := 0
+	if input.UserID != nil {
+		userID = *input.UserID
+	}
+
+	var tags []string
+	for _, tag := range input.Tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	annotation, err := s.annotations.Add(Annotation{
+		UserID:    userID,
+		Date:      date,
+		Note:      note,
+		Mood:      input.Mood,
+		Symptoms:  symptoms,
+		Tags:      tags,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to save annotation: %w", err)
+	}
+
+	return "Logged annotation:\n" + FormatAnnotations([]Annotation{annotation}), nil
+}
+
+// executeListAnnotationsTool implements the journal listing tool
+func (s *MCPServer) executeListAnnotationsTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input ListAnnotationsInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate := s.lastDays(90)
+	if input.StartDate != "" || input.EndDate != "" {
+		if input.StartDate == "" {
+			input.StartDate = dayKey(startDate)
+		}
+		var err error
+		startDate, endDate, err = s.parseDateRange(DateRangeInput{StartDate: input.StartDate, EndDate: input.EndDate})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	userID := 0
+	if input.UserID != nil {
+		userID = *input.UserID
+	}
+
+	annotations, err := s.annotations.List(userID, startDate, endDate)
+	if err != nil {
+		return "", fmt.Errorf("failed to load annotations: %w", err)
+	}
+	if input.Tag != "" {
+		filtered := annotations[:0]
+		for _, annotation := range annotations {
+			if annotation.HasTag(input.Tag) {
+				filtered = append(filtered, annotation)
+			}
+		}
+		annotations = filtered
+	}
+
+	var builder strings.Builder
+	builder.WriteString("# Journal Annotations\n\n")
+	builder.WriteString(fmt.Sprintf("**Period:** %s to %s\n\n", dayKey(startDate), dayKey(endDate)))
+	if len(annotations) == 0 {
+		builder.WriteString("No annotations recorded for this period.\n")
+		return builder.String(), nil
+	}
+	builder.WriteString(FormatAnnotations(annotations))
+	return builder.String(), nil
+}
+
+// executeCorrelateMoodTool implements the mood-physiology correlation tool
+func (s *MCPServer) executeCorrelateMoodTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input CorrelateMoodInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	scale := strings.ToLower(strings.TrimSpace(input.Scale))
+	if scale == "" {
+		scale = "mood"
+	}
+
+	startDate, endDate, err := s.recentRange("correlate_mood", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	userID := 0
+	if input.UserID != nil {
+		userID = *input.UserID
+	}
+
+	annotations, err := s.annotations.List(userID, startDate, endDate)
+	if err != nil {
+		return "", fmt.Errorf("failed to load annotations: %w", err)
+	}
+
+	data, quality, err := s.fetchScoredHealthData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	analysis := s.healthAnalyzer.CorrelateMood(data, annotations, scale)
+	return withDataQuality(s.healthAnalyzer.FormatMoodAnalysis(analysis, moodScales(annotations)), quality), nil
+}
+
+// executeInterventionTool implements the intervention effect tool
+func (s *MCPServer) executeInterventionTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input InterventionInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	userID := 0
+	if input.UserID != nil {
+		userID = *input.UserID
+	}
+
+	name := input.Name
+	startDate := input.StartDate
+	if input.AnnotationID != nil {
+		annotation, ok, err := s.annotations.Get(userID, *input.AnnotationID)
+		if err != nil {
+			return "", fmt.Errorf("failed to load annotations: %w", err)
+		}
+		if !ok {
+			return "", fmt.Errorf("annotation #%d not found", *input.AnnotationID)
+		}
+		startDate = annotation.Date
+		if name == "" {
+			name = annotation.Note
+		}
+	}
+	if startDate == "" {
+		return "", fmt.Errorf("start_date or annotation_id is required")
+	}
+	start, err := parseDate(startDate, s.now())
+	if err != nil {
+		return "", fmt.Errorf("invalid start_date: %w", err)
+	}
+	if name == "" {
+		name = "Change started " + dayKey(start)
+	}
+
+	window := input.WindowDays
+	if window == 0 {
+		window = defaultInterventionWindow
+	}
+	if input.WashoutDays < 0 {
+		return "", fmt.Errorf("washout_days must not be negative")
+	}
+
+	now := time.Now()
+	pre, post := interventionWindows(start, window, input.WashoutDays)
+	if !post.Start.Before(now) {
+		return "", fmt.Errorf("the after window starts %s, which has not begun yet", dayKey(post.Start))
+	}
+	fetchEnd := post.End.AddDate(0, 0, 1)
+	if fetchEnd.After(now) {
+		fetchEnd = now
+	}
+
+	data, quality, err := s.fetchScoredHealthData(ctx, pre.Start, fetchEnd, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	analysis := s.healthAnalyzer.AnalyzeIntervention(data, name, start, window, input.WashoutDays, now)
+	return s.withReportContext(ctx, withDataQuality(s.healthAnalyzer.FormatInterventionAnalysis(analysis), quality), pre.Start, post.End, input.UserID), nil
+}
+
+// executeSetGoalTool implements the goal creation and removal tool
+func (s *MCPServer) executeSetGoalTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input SetGoalInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	userID := 0
+	if input.UserID != nil {
+		userID = *input.UserID
+	}
+
+	if input.RemoveGoalID != nil {
+		removed, err := s.goals.Remove(userID, *input.RemoveGoalID)
+		if err != nil {
+			return "", fmt.Errorf("failed to remove goal: %w", err)
+		}
+		if !removed {
+			return "", fmt.Errorf("goal #%d not found", *input.RemoveGoalID)
+		}
+		return fmt.Sprintf("Removed goal #%d.", *input.RemoveGoalID), nil
+	}
+
+	goal := Goal{
+		UserID:      userID,
+		Metric:      input.Metric,
+		Type:        input.Type,
+		Target:      input.Target,
+		DaysPerWeek: input.DaysPerWeek,
+		CreatedAt:   time.Now(),
+	}
+	switch input.Comparison {
+	case "":
+		if metric, ok := lookupMetric(goal.Metric); ok {
+			goal.AtMost = !metric.HigherIsBetter
+		}
+	case "at_least":
+	case "at_most":
+		goal.AtMost = true
+	default:
+		return "", fmt.Errorf("comparison must be at_least or at_most")
+	}
+	if err := goal.Validate(); err != nil {
+		return "", err
+	}
+
+	goal, err := s.goals.Add(goal)
+	if err != nil {
+		return "", fmt.Errorf("failed to save goal: %w", err)
+	}
+	return fmt.Sprintf("Set goal #%d: %s. Track it with check_goals; it also appears in get_health_summary.", goal.ID, goal.Describe()), nil
+}
+
+// executeCheckGoalsTool implements the goal progress tool
+func (s *MCPServer) executeCheckGoalsTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input CheckGoalsInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	weeks := input.Weeks
+	if weeks == 0 {
+		weeks = 4
+	}
+
+	today := s.today()
+	startDate, endDate := localDayBounds(weekStart(today).AddDate(0, 0, -7*(weeks-1)), today)
+
+	data, quality, err := s.fetchScoredHealthData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	status := s.goalStatus(s.healthAnalyzer, data, startDate, endDate, input.UserID, "#")
+	if status == "" {
+		return FormatGoalProgress(s.healthAnalyzer.locale, nil, "#"), nil
+	}
+	return withDataQuality(status, quality), nil
+}
+
+// executeAcknowledgeRedFlagTool implements the red flag acknowledgement tool
+func (s *MCPServer) executeAcknowledgeRedFlagTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input AcknowledgeRedFlagInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	userID := 0
+	if input.UserID != nil {
+		userID = *input.UserID
+	}
+
+	if input.RemoveAcknowledgementID != nil {
+		removed, err := s.redFlagAcks.Remove(userID, *input.RemoveAcknowledgementID)
+		if err != nil {
+			return "", fmt.Errorf("failed to remove acknowledgement: %w", err)
+		}
+		if !removed {
+			return "", fmt.Errorf("acknowledgement #%d not found", *input.RemoveAcknowledgementID)
+		}
+		return fmt.Sprintf("Withdrew acknowledgement #%d; the flag will be raised again when detected.", *input.RemoveAcknowledgementID), nil
+	}
+
+	if !slices.Contains(redFlagTypes, input.FlagType) {
+		return "", fmt.Errorf("flag_type must be one of: %s", strings.Join(redFlagTypes, ", "))
+	}
+	ack := RedFlagAck{
+		UserID:         userID,
+		Type:           input.FlagType,
+		Reason:         strings.TrimSpace(input.Reason),
+		AcknowledgedAt: time.Now(),
+	}
+	if input.Until != "" {
+		until, err := parseDate(input.Until, s.now())
+		if err != nil {
+			return "", fmt.Errorf("invalid until: %w", err)
+		}
+		if dayKey(until) < dayKey(s.now()) {
+			return "", fmt.Errorf("until must not be in the past")
+		}
+		ack.Until = dayKey(until)
+	}
+
+	ack, err := s.redFlagAcks.Add(ack)
+	if err != nil {
+		return "", fmt.Errorf("failed to save acknowledgement: %w", err)
+	}
+	result := fmt.Sprintf("Acknowledged %s as #%d", redFlagLabel(ack.Type), ack.ID)
+	if ack.Until != "" {
+		result += " through " + ack.Until
+	}
+	return result + ". Reports will list it under Acknowledged Issues instead of raising it; withdraw it with remove_acknowledgement_id.", nil
+}
+
+// executeListRedFlagsTool implements the red flag listing tool
+func (s *MCPServer) executeListRedFlagsTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input ListRedFlagsInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.recentRange("list_red_flags", input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+	if days := rangeDays(startDate, endDate); days < 3 || days > 90 {
+		return "", fmt.Errorf("the range must cover between 3 and 90 days")
+	}
+
+	key := 0
+	if input.UserID != nil {
+		key = *input.UserID
+	}
+	acks, err := s.redFlagAcks.List(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to load acknowledgements: %w", err)
+	}
+
+	data, quality, err := s.fetchScoredHealthData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", err
+	}
+	summary, err := s.healthAnalyzer.AnalyzeHealthSummary(ctx, data.Recoveries, data.Sleeps, data.Workouts, data.Cycles, startDate, endDate, key, s.personalBaseline(ctx, input.UserID, false))
+	if err != nil {
+		return "", fmt.Errorf("failed to analyze health data: %w", err)
+	}
+	raised, acknowledged := partitionRedFlags(summary.RedFlags, acks, endDate)
+	s.notifyRedFlags(raised, endDate, input.UserID)
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("# Red Flags (%s to %s)\n\n## Raised\n", dayKey(startDate), dayKey(endDate)))
+	if len(raised) == 0 {
+		builder.WriteString("- None\n")
+	}
+	for _, flag := range raised {
+		builder.WriteString(formatSafetyNotice(flag))
+		builder.WriteString(fmt.Sprintf("- **%s** (`%s`, %s): %s\n", redFlagLabel(flag.Type), flag.Type, flag.Severity, flag.Description))
+	}
+	builder.WriteString("\n")
+	builder.WriteString(formatAcknowledgedFlags(nil, acknowledged, "##"))
+	builder.WriteString("## Acknowledgements\n")
+	builder.WriteString(FormatRedFlagAcks(acks, endDate))
+	return withDataQuality(builder.String(), quality), nil
+}
+
+// notifyRedFlags sends raised red flags from a period ending at end to the
+// configured notification channels in the background. Nothing is sent without
+// a notifier or when the period ended more than notificationMaxAge ago.
+func (s *MCPServer) notifyRedFlags(flags []RedFlag, end time.Time, userID *int) {
+	if s.notifier == nil || len(flags) == 0 || time.Since(end) > notificationMaxAge {
+		return
+	}
+	key := 0
+	if userID != nil {
+		key = *userID
+	}
+	go func() {
+		if err := s.notifier.Notify(key, flags, time.Now()); err != nil {
+			log.Printf("Failed to send red flag notification: %v", err)
+		}
+	}()
+}
+
+// acknowledgements loads the user's red flag acknowledgements. Storage
+// failures are logged so reports still raise every flag.
+func (s *MCPServer) acknowledgements(userID *int) []RedFlagAck {
+	key := 0
+	if userID != nil {
+		key = *userID
+	}
+	acks, err := s.redFlagAcks.List(key)
+	if err != nil {
+		log.Printf("Failed to load red flag acknowledgements: %v", err)
+	}
+	return acks
+}
+
+// goalStatus evaluates the user's goals over a period under a markdown heading,
+// returning an empty string when no goals are set. Storage failures are logged.
+func (s *MCPServer) goalStatus(analyzer *HealthAnalyzer, data *HealthData, startDate, endDate time.Time, userID *int, heading string) string {
+	key := 0
+	if userID != nil {
+		key = *userID
+	}
+
+	goals, err := s.goals.List(key)
+	if err != nil {
+		log.Printf("Failed to load goals: %v", err)
+		return ""
+	}
+	if len(goals) == 0 {
+		return ""
+	}
+
+	now := s.now()
+	var progress []GoalProgress
+	for _, goal := range goals {
+		progress = append(progress, analyzer.EvaluateGoal(goal, data, startDate, endDate, now))
+	}
+	return FormatGoalProgress(analyzer.locale, progress, heading)
+}
+
+// executeWeeklyReportTool implements the weekly report tool
+func (s *MCPServer) executeWeeklyReportTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input WeeklyReportInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	week := weekStart(s.today()).AddDate(0, 0, -7)
+	if input.WeekStart != "" {
+		parsed, err := parseDate(input.WeekStart, s.now())
+		if err != nil {
+			return "", fmt.Errorf("invalid week_start: %w", err)
+		}
+		week = parsed
+	}
+
+	analyzer, err := s.analyzerFor(input.Locale, "")
+	if err != nil {
+		return "", err
+	}
+	report, err := s.weeklyReport(ctx, analyzer, week, input.UserID)
+	if err != nil {
+		return "", err
+	}
+	return analyzer.FormatWeeklyReport(report), nil
+}
+
+// weeklyReport builds the report for the week containing week, fetching it
+// together with the week before for comparison, in analyzer's language
+func (s *MCPServer) weeklyReport(ctx context.Context, analyzer *HealthAnalyzer, week time.Time, userID *int) (WeeklyReport, error) {
+	now, today := s.now(), s.today()
+	start := weekStart(time.Date(week.Year(), week.Month(), week.Day(), 0, 0, 0, 0, today.Location()))
+	end := start.AddDate(0, 0, 7)
+	if start.After(today) {
+		return WeeklyReport{}, fmt.Errorf("the week of %s has not started yet", dayKey(start))
+	}
+	last := end.AddDate(0, 0, -1)
+	if last.After(today) {
+		last = today
+	}
+
+	fetchStart, fetchEnd := localDayBounds(start.AddDate(0, 0, -7), last)
+	data, _, err := s.fetchScoredHealthData(ctx, fetchStart, fetchEnd, userID)
+	if err != nil {
+		return WeeklyReport{}, err
+	}
+
+	key := 0
+	if userID != nil {
+		key = *userID
+	}
+	goals, err := s.goals.List(key)
+	if err != nil {
+		log.Printf("Failed to load goals: %v", err)
+	}
+	annotations, err := s.annotations.List(key, start, end.AddDate(0, 0, -1))
+	if err != nil {
+		log.Printf("Failed to load annotations: %v", err)
+	}
+
+	report := analyzer.BuildWeeklyReport(ctx, data, start, s.personalBaseline(ctx, userID, false), goals, annotations, s.acknowledgements(userID), now)
+	s.notifyRedFlags(report.RedFlags, fetchEnd, userID)
+	return report, nil
+}
+
+// monthlyReport renders the health summary for the calendar month starting at
+// month, with goal status and journal notes
+func (s *MCPServer) monthlyReport(ctx context.Context, month time.Time, userID *int) (string, error) {
+	start := bucketStart(month, granularityMonthly)
+	end := start.AddDate(0, 1, 0).Add(-time.Second)
+
+	data, quality, err := s.fetchScoredHealthData(ctx, start, end, userID)
+	if err != nil {
+		return "", err
+	}
+
+	key := 0
+	if userID != nil {
+		key = *userID
+	}
+	summary, err := s.healthAnalyzer.AnalyzeHealthSummary(ctx, data.Recoveries, data.Sleeps, data.Workouts, data.Cycles, start, end, key, s.personalBaseline(ctx, userID, false))
+	if err != nil {
+		return "", fmt.Errorf("failed to analyze health data: %w", err)
+	}
+	summary.RedFlags, summary.AcknowledgedFlags = partitionRedFlags(summary.RedFlags, s.acknowledgements(userID), s.now())
+	s.notifyRedFlags(summary.RedFlags, end, userID)
+
+	report := fmt.Sprintf("# Monthly Report: %s\n\n", start.Format("January 2006")) + s.healthAnalyzer.FormatInsightsForTherapy(summary)
+	if goals := s.goalStatus(s.healthAnalyzer, data, start, end, userID, "##"); goals != "" {
+		report += "\n\n" + goals
+	}
+	return s.withReportContext(ctx, withDataQuality(report, quality), start, end, userID), nil
+}
+
+// withAnnotations appends the journal notes dated within the analysis period to
+// a report. Storage failures are logged so the analysis is still returned.
+func (s *MCPServer) withAnnotations(report string, startDate, endDate time.Time, userID *int) string {
+	key := 0
+	if userID != nil {
+		key = *userID
+	}
+
+	annotations, err := s.annotations.List(key, startDate, endDate)
+	if err != nil {
+		log.Printf("Failed to load annotations: %v", err)
+		return report
+	}
+	if overlay := FormatAnnotationOverlay(annotations); overlay != "" {
+		return report + "\n\n" + overlay
+	}
+	return report
+}
+
+// executeStorageFootprintTool implements the local storage report
+func (s *MCPServer) executeStorageFootprintTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	entries, err := datastoreFootprint(s.settings)
+	if err != nil {
+		return "", err
+	}
+	return FormatStorageFootprint(entries, s.retention, s.cipher != nil, time.Now()), nil
+}
+
+// executeSeasonalAnalysisTool implements the seasonal pattern tool
+func (s *MCPServer) executeSeasonalAnalysisTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input SeasonalAnalysisInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	months := input.Months
+	if months == 0 {
+		months = 24 // Two of each season for year-over-year changes
+	}
+	if months < minSeasonalMonths || months > maxSeasonalMonths {
+		return "", fmt.Errorf("months must be between %d and %d", minSeasonalMonths, maxSeasonalMonths)
+	}
+	hemisphere := input.Hemisphere
+	switch hemisphere {
+	case "":
+		hemisphere = hemisphereNorth
+	case hemisphereNorth, hemisphereSouth:
+	default:
+		return "", fmt.Errorf("invalid hemisphere %q (expected %s or %s)", input.Hemisphere, hemisphereNorth, hemisphereSouth)
+	}
+
+	today := s.today()
+	startDate, endDate := localDayBounds(today.AddDate(0, -months, 1), today)
+
+	// Years of records are analyzed page by page rather than held in memory
+	seasons := newSeasonalAccumulator(hemisphere)
+	quality, err := s.streamScoredHealthData(ctx, startDate, endDate, input.UserID, seasons.add)
+	if err != nil {
+		return "", err
+	}
+
+	report := s.healthAnalyzer.FormatSeasonalAnalysis(seasons.analysis())
+	return s.withReportContext(ctx, withDataQuality(report, quality), startDate, endDate, input.UserID), nil
+}
+
+// executeRenderChartTool implements the chart tool, returning the image and a
+// one-line description of the plotted data
+func (s *MCPServer) executeRenderChartTool(ctx context.Context, arguments json.RawMessage) ([]map[string]interface{}, error) {
+	var input RenderChartInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	metric, ok := lookupMetric(input.Metric)
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q (expected one of: %s)", input.Metric, strings.Join(metricKeys(), ", "))
+	}
+
+	startDate, endDate, err := s.recentRange("render_chart", input.DateRangeInput)
+	if err != nil {
+		return nil, err
+	}
+	days := rangeDays(startDate, endDate)
+
+	data, err := s.fetchHealthData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	chart := buildChart(metric, data)
+	image, mimeType, err := chart.Render(input.Format)
+	if err != nil {
+		return nil, err
+	}
+	return []map[string]interface{}{
+		{"type": "image", "data": base64.StdEncoding.EncodeToString(image), "mimeType": mimeType},
+		{"type": "text", "text": chart.summary(days)},
+	}, nil
+}
+
+// executeExportDataTool implements the raw data CSV and NDJSON export tool
+func (s *MCPServer) executeExportDataTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input ExportDataInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	datasets, err := parseExportDatasets(input.Datasets)
+	if err != nil {
+		return "", err
+	}
+	analyzer, err := s.analyzerFor("", input.Units)
+	if err != nil {
+		return "", err
+	}
+	startDate, endDate, err := s.parseDateRange(input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	switch input.Format {
+	case "", "csv":
+	case "ndjson":
+		if input.Path == "" {
+			return "", fmt.Errorf("ndjson export requires a path directory")
+		}
+		if analyzer.units != unitsMetric && analyzer.units != "" {
+			return "", fmt.Errorf("ndjson archives keep Whoop's metric fields; omit units")
+		}
+		manifest, err := s.archive(ctx, input.Path, datasets, startDate, endDate, input.UserID)
+		if err != nil {
+			return "", err
+		}
+		return formatArchiveSummary(input.Path, manifest), nil
+	default:
+		return "", fmt.Errorf("invalid format %q (expected csv or ndjson)", input.Format)
+	}
+
+	data, err := s.fetchHealthData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	for _, dataset := range datasets {
+		table := buildExportTable(dataset, data.records(dataset), analyzer.units)
+		content, err := table.CSV()
+		if err != nil {
+			return "", fmt.Errorf("failed to encode %s records: %w", dataset, err)
+		}
+
+		if input.Path == "" {
+			builder.WriteString(fmt.Sprintf("## %s.csv (%d records)\n```csv\n%s```\n\n", dataset, len(table.Rows), content))
+			continue
+		}
+		path := exportPath(input.Path, dataset, len(datasets))
+		if err := writePrivateFile(path, content); err != nil {
+			return "", fmt.Errorf("failed to export %s records: %w", dataset, err)
+		}
+		builder.WriteString(fmt.Sprintf("Wrote %d %s records to %s\n", len(table.Rows), dataset, path))
+	}
+	return strings.TrimRight(builder.String(), "\n"), nil
+}
+
+// executeExportHealthArchiveTool implements the Apple Health / Google Fit export tool
+func (s *MCPServer) executeExportHealthArchiveTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input ExportHealthArchiveInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.parseDateRange(input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+	if !slices.Contains(healthArchiveFormats, input.Format) {
+		return "", fmt.Errorf("invalid format %q (expected apple_health, google_fit, or fhir)", input.Format)
+	}
+
+	userID, err := s.accountID(ctx, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := s.fetchHealthData(ctx, startDate, endDate, &userID)
+	if err != nil {
+		return "", err
+	}
+	content, name, err := encodeHealthArchive(input.Format, data, userID, s.healthAnalyzer.sports, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s archive: %w", input.Format, err)
+	}
+
+	summary := fmt.Sprintf("%d workouts and %d sleeps from %s", len(data.Workouts), len(data.Sleeps), formatPeriod(nil, DateRange{Start: startDate, End: endDate}))
+	if input.Format == "fhir" {
+		summary = fmt.Sprintf("%d recoveries and %d sleeps from %s", len(data.Recoveries), len(data.Sleeps), formatPeriod(nil, DateRange{Start: startDate, End: endDate}))
+	}
+	if input.Path == "" {
+		language := "json"
+		if input.Format == "apple_health" {
+			language = "xml"
+		}
+		return fmt.Sprintf("## %s (%s)\n```%s\n%s```", name, summary, language, content), nil
+	}
+
+	path := input.Path
+	if ext := filepath.Ext(path); ext != ".xml" && ext != ".json" {
+		path = filepath.Join(path, name)
+	}
+	if err := writePrivateFile(path, content); err != nil {
+		return "", fmt.Errorf("failed to write %s archive: %w", input.Format, err)
+	}
+	return fmt.Sprintf("Wrote %s to %s", summary, path), nil
+}
+
+// executeExportWorkoutTCXTool implements the per-workout TCX export tool
+func (s *MCPServer) executeExportWorkoutTCXTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input ExportWorkoutTCXInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.parseDateRange(input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	workouts, err := s.whoopClient.GetWorkoutData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get workout data: %w", err)
+	}
+	files, err := buildTCXFiles(workouts, s.healthAnalyzer.sports, input.Sport)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return fmt.Sprintf("No scored workouts with distance data from %s", formatPeriod(nil, DateRange{Start: startDate, End: endDate})), nil
+	}
+
+	var builder strings.Builder
+	if input.Path == "" {
+		for _, file := range files {
+			builder.WriteString(fmt.Sprintf("## %s\n```xml\n%s```\n\n", file.Name, file.Content))
+		}
+		return strings.TrimRight(builder.String(), "\n"), nil
+	}
+
+	paths, err := writeTCXFiles(input.Path, files)
+	if err != nil {
+		return "", err
+	}
+	builder.WriteString(fmt.Sprintf("Wrote %d TCX files to %s:", len(paths), input.Path))
+	for _, file := range files {
+		builder.WriteString(fmt.Sprintf("\n- %s (%s)", file.Name, file.Sport))
+	}
+	return builder.String(), nil
+}
+
+// executeExportCalendarTool implements the iCalendar export tool
+func (s *MCPServer) executeExportCalendarTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input ExportCalendarInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	analyzer, err := s.analyzerFor("", input.Units)
+	if err != nil {
+		return "", err
+	}
+
+	startDate, endDate, err := s.parseDateRange(input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+
+	workouts, err := s.whoopClient.GetWorkoutData(ctx, startDate, endDate, input.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get workout data: %w", err)
+	}
+	var sleeps []WhoopSleep
+	if input.IncludeSleep {
+		if sleeps, err = s.whoopClient.GetSleepData(ctx, startDate, endDate, input.UserID); err != nil {
+			return "", fmt.Errorf("failed to get sleep data: %w", err)
+		}
+	}
+
+	content := buildCalendar(workouts, sleeps, input.IncludeSleep, analyzer.sports, analyzer.units, time.Now())
+	summary := fmt.Sprintf("%d workouts", len(workouts))
+	if input.IncludeSleep {
+		summary += fmt.Sprintf(" and %d sleeps", len(sleeps))
+	}
+	if input.Path == "" {
+		return fmt.Sprintf("## whoop.ics (%s)\n```\n%s```", summary, content), nil
+	}
+
+	path := input.Path
+	if !strings.EqualFold(filepath.Ext(path), ".ics") {
+		path = filepath.Join(path, "whoop.ics")
+	}
+	if err := writePrivateFile(path, content); err != nil {
+		return "", fmt.Errorf("failed to write calendar: %w", err)
+	}
+	return fmt.Sprintf("Wrote %s to %s", summary, path), nil
+}
+
+// executeRawDataTool implements the get_raw_* record tools
+func (s *MCPServer) executeRawDataTool(ctx context.Context, dataset string, arguments json.RawMessage) (string, error) {
+	var input RawDataInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startDate, endDate, err := s.parseDateRange(input.DateRangeInput)
+	if err != nil {
+		return "", err
+	}
+	maxRecords := input.MaxRecords
+	if maxRecords == 0 {
+		maxRecords = defaultRawRecords
+	}
+	if maxRecords < 0 {
+		return "", fmt.Errorf("max_records must be positive")
+	}
+
+	var records interface{}
+	switch dataset {
+	case "recovery":
+		records, err = s.whoopClient.GetRecoveryData(ctx, startDate, endDate, input.UserID)
+	case "sleep":
+		records, err = s.whoopClient.GetSleepData(ctx, startDate, endDate, input.UserID)
+	case "workout":
+		records, err = s.whoopClient.GetWorkoutData(ctx, startDate, endDate, input.UserID)
+	default:
+		records, err = s.whoopClient.GetCycleData(ctx, startDate, endDate, input.UserID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s data: %w", dataset, err)
+	}
+
+	result, err := projectRecords(dataset, records, input.Fields, maxRecords)
+	if err != nil {
+		return "", err
+	}
+	content, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s records: %w", dataset, err)
+	}
+	return string(content), nil
+}
+
+// executeComparePeriodsTool implements the before/after period comparison tool
+func (s *MCPServer) executeComparePeriodsTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input ComparePeriodsInput
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	startA, endA, err := s.parseDateRange(DateRangeInput{StartDate: input.PeriodAStart, EndDate: input.PeriodAEnd})
+	if err != nil {
+		return "", fmt.Errorf("period A: %w", err)
+	}
+	startB, endB, err := s.parseDateRange(DateRangeInput{StartDate: input.PeriodBStart, EndDate: input.PeriodBEnd})
+	if err != nil {
+		return "", fmt.Errorf("period B: %w", err)
+	}
+
+	labelA := input.PeriodALabel
+	if labelA == "" {
+		labelA = "Before"
+	}
+	labelB := input.PeriodBLabel
+	if labelB == "" {
+		labelB = "After"
+	}
+
+	dataA, qualityA, err := s.fetchScoredHealthData(ctx, startA, endA, input.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s data: %w", labelA, err)
+	}
+	dataB, qualityB, err := s.fetchScoredHealthData(ctx, startB, endB, input.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s data: %w", labelB, err)
+	}
+	qualityA.Label, qualityB.Label = labelA, labelB
+
+	comparison := s.healthAnalyzer.ComparePeriods(
+		DateRange{Start: startA, End: endA},
+		DateRange{Start: startB, End: endB},
+		dataA, dataB, labelA, labelB,
+	)
+
+	// Cover the gap between the periods too, where the change being compared usually happened
+	first, last := startA, endB
+	if startB.Before(first) {
+		first = startB
+	}
+	if endA.After(last) {
+		last = endA
+	}
+	return s.withReportContext(ctx, withDataQuality(withDataQuality(s.healthAnalyzer.FormatPeriodComparison(comparison), qualityA), qualityB), first, last, input.UserID), nil
+}
+
+// readResource reads a specific resource
+func (s *MCPServer) readResource(ctx context.Context, uri string) (string, error) {
+	switch uri {
+	case "whoop://user/profile":
+		user, err := s.whoopClient.GetUser(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get user profile: %w", err)
+		}
+		data, err := json.MarshalIndent(user, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal user data: %w", err)
+		}
+		return string(data), nil
+
+	case "whoop://health/recent":
+		// Get recent data (last 7 days)
+		startDate, endDate := s.lastDays(7)
+
+		user, err := s.whoopClient.GetUser(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get user: %w", err)
+		}
+
+		userID := user.UserID
+		recovery, _ := s.whoopClient.GetRecoveryData(ctx, startDate, endDate, &userID)
+		sleep, _ := s.whoopClient.GetSleepData(ctx, startDate, endDate, &userID)
+		workouts, _ := s.whoopClient.GetWorkoutData(ctx, startDate, endDate, &userID)
+
+		recentData := map[string]interface{}{
+			"recovery": recovery,
+			"sleep":    sleep,
+			"workouts": workouts,
+		}
+
+		data, err := s.healthAnalyzer.units.MarshalStructured(recentData)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal recent data: %w", err)
+		}
+		return string(data), nil
+
+	case "whoop://health/today", "whoop://health/yesterday":
+		offset := 0
+		if uri == "whoop://health/yesterday" {
+			offset = 1
+		}
+		snapshot, err := s.dailySnapshot(ctx, offset)
+		if err != nil {
+			return "", err
+		}
+		data, err := s.healthAnalyzer.units.MarshalStructured(snapshot)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal snapshot: %w", err)
+		}
+		return string(data), nil
+
+	case "whoop://sports":
+		data, err := json.MarshalIndent(map[string]interface{}{
+			"sports": s.whoopClient.Sports().All(),
+		}, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal sports catalog: %w", err)
+		}
+		return string(data), nil
+
+	case "whoop://debug/rate-limit":
+		data, err := json.MarshalIndent(s.whoopClient.RateLimitStatus(), "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal rate limit status: %w", err)
+		}
+		return string(data), nil
+
+	case "whoop://accounts":
+		accounts := []AccountSummary{}
+		if store := s.whoopClient.Accounts(); store != nil {
+			accounts = store.Summaries()
+		}
+		data, err := json.MarshalIndent(map[string]interface{}{
+			"accounts": accounts,
+		}, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal accounts: %w", err)
+		}
+		return string(data), nil
+
+	case "whoop://auth/scopes":
+		data, err := json.MarshalIndent(s.whoopClient.Scopes(), "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal scope status: %w", err)
+		}
+		return string(data), nil
+
+	case "whoop://insights/burnout":
+		risk, err := s.burnoutRisk(ctx, 0, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to assess burnout risk: %w", err)
+		}
+		data, err := json.MarshalIndent(risk, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal burnout risk: %w", err)
+		}
+		return string(data), nil
+
+	case "whoop://reports/latest":
+		_, content, err := s.reports.Latest()
+		if err != nil {
+			return "", err
+		}
+		return content, nil
+
+	case "whoop://audit/log":
+		if s.audit == nil {
+			return "", fmt.Errorf("the audit log is off (WHOOP_AUDIT_LOG=off)")
+		}
+		entries, err := s.audit.Recent(auditLogLimit)
+		if err != nil {
+			return "", err
+		}
+		data, err := json.MarshalIndent(map[string]interface{}{
+			"session": s.audit.Session,
+			"entries": entries,
+		}, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal audit log: %w", err)
+		}
+		return string(data), nil
+
+	default:
+		return "", fmt.Errorf("unknown resource URI: %s", uri)
+	}
+}
+
+// trendSparkline is the daily sparkline shown in a trend report
+type trendSparkline struct {
+	Days int
+	Line string
+}
+
+func (s *MCPServer) formatRecoveryTrend(trend RecoveryTrend, days int, spark trendSparkline) string {
+	return s.healthAnalyzer.templates.Render(s.healthAnalyzer.locale, "recovery_trend", struct {
+		Days           int
+		Trend          RecoveryTrend
+		Scores         string
+		Interpretation string
+		SparkDays      int
+		Sparkline      string
+	}{days, trend, s.formatScoreList(trend.LastSevenDays), s.interpretRecoveryTrend(trend), spark.Days, spark.Line})
+}
+
+func (s *MCPServer) formatSleepTrend(analysis SleepAnalysis, days int, spark trendSparkline) string {
+	return s.healthAnalyzer.templates.Render(s.healthAnalyzer.locale, "sleep_trend", struct {
+		Days           int
+		Sleep          SleepAnalysis
+		Interpretation string
+		SparkDays      int
+		Sparkline      string
+	}{days, analysis, s.interpretSleepTrend(analysis), spark.Days, spark.Line})
+}
+
+func (s *MCPServer) formatStrainTrend(strains []float64, days int, spark trendSparkline) string {
+	if len(strains) == 0 {
+		return "No strain data available for the requested period."
+	}
+
+	avgStrain := 0.0
+	if len(strains) > 0 {
+		sum := 0.0
+		for _, strain := range strains {
+			sum += strain
+		}
+		avgStrain = sum / float64(len(strains))
+	}
+
+	return s.healthAnalyzer.templates.Render(s.healthAnalyzer.locale, "strain_trend", struct {
+		Days      int
+		Average   float64
+		Sessions  int
+		Min, Max  float64
+		Pattern   string
+		SparkDays int
+		Sparkline string
+	}{days, avgStrain, len(strains), s.findMin(strains), s.findMax(strains), s.interpretStrainPattern(strains), spark.Days, spark.Line})
+}
+
+func (s *MCPServer) formatScoreList(scores []float64) string {
+	if len(scores) == 0 {
+		return "No recent scores available"
+	}
+
+	var formatted []string
+	for i, score := range scores {
+		formatted = append(formatted, fmt.Sprintf("Day %d: %.1f%%", i+1, score))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func (s *MCPServer) interpretRecoveryTrend(trend RecoveryTrend) string {
+	interpretation := fmt.Sprintf("Recovery is showing a %s trend", trend.Trend)
+
+	if trend.Trend == "declining" {
+		interpretation += " which may indicate increasing stress, inadequate recovery, or developing health concerns"
+	} else if trend.Trend == "improving" {
+		interpretation += " suggesting effective stress management and recovery strategies"
+	}
+
+	if trend.ConsistencyScore < 0.6 {
+		interpretation += ". High variability in scores suggests inconsistent stressors or recovery practices"
+	}
+
+	return interpretation + "."
+}
+
+func (s *MCPServer) interpretSleepTrend(analysis SleepAnalysis) string {
+	interpretation := ""
+
+	if analysis.AverageHours < 7 {
+		interpretation += "Sleep duration is below optimal range for most adults. "
+	}
+
+	if analysis.AverageEfficiency < 0.85 {
+		interpretation += "Sleep efficiency suggests difficulty maintaining sleep. "
+	}
+
+	if analysis.SleepQualityTrend == "declining" {
+		interpretation += "Declining quality trend requires attention to identify contributing factors."
+	} else if analysis.SleepQualityTrend == "improving" {
+		interpretation += "Improving quality trend suggests positive changes in sleep habits or stress management."
+	}
+
+	if interpretation == "" {
+		interpretation = "Sleep patterns appear to be within healthy ranges."
+	}
+
+	return interpretation
+}
+
+func (s *MCPServer) interpretStrainPattern(strains []float64) string {
+	if len(strains) == 0 {
+		return "No strain data to analyze"
+	}
+
+	avg := 0.0
+	for _, strain := range strains {
+		avg += strain
+	}
+	avg /= float64(len(strains))
+
+	if avg > 15 {
+		return "High average strain may indicate intense training that could impact recovery"
+	} else if avg < 8 {
+		return "Low average strain suggests minimal physical stress, which may be appropriate for recovery phases"
+	}
+
+	return "Strain levels appear balanced for maintaining fitness while allowing recovery"
+}
+
+func (s *MCPServer) findMin(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	min := values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+func (s *MCPServer) findMax(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	max := values[0]
+	for _, v := range values[1:] {
+		if v > max {
+			max = v
+		}
+	}
+	return max
+}
+
+// executeWhoopAuthSetupTool helps users set up Whoop OAuth authentication
+func (s *MCPServer) executeWhoopAuthSetupTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var input struct {
+		ClientID          string `json:"client_id,omitempty"`
+		AuthorizationCode string `json:"authorization_code,omitempty"`
+		ClientSecret      string `json:"client_secret,omitempty"`
+		State             string `json:"state,omitempty"`
+		AutoCallback      bool   `json:"auto_callback,omitempty"`
+		CheckStatus       bool   `json:"check_status,omitempty"`
+		RevealTokens      bool   `json:"reveal_tokens,omitempty"`
+	}
+
+	if err := json.Unmarshal(arguments, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if input.CheckStatus {
+		return s.formatAuthFlowStatus(), nil
+	}
+
+	// Capture the code automatically via a temporary localhost callback server
+	if input.AutoCallback {
+		if input.ClientID == "" || input.ClientSecret == "" {
+			return "", fmt.Errorf("auto_callback requires client_id and client_secret")
+		}
+		return s.startAutomaticAuth(input.ClientID, input.ClientSecret)
+	}
+
+	// If only client_id provided, generate authorization URL
+	if input.ClientID != "" && input.AuthorizationCode == "" {
+		return s.generateAuthURL(input.ClientID)
+	}
+
+	// If authorization code provided, validate the state and exchange for tokens
+	if input.AuthorizationCode != "" && input.ClientSecret != "" {
+		if err := s.oauthStates.Consume(input.State); err != nil {
+			return "", err
+		}
+		return s.exchangeCodeForTokens(input.ClientID, input.ClientSecret, input.AuthorizationCode, input.RevealTokens)
+	}
+
+	// Otherwise, provide general setup instructions
+	return s.generateAuthInstructions(), nil
+}
+
+// startAutomaticAuth launches the callback-based auth flow in the background
+func (s *MCPServer) startAutomaticAuth(clientID, clientSecret string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.authFlow != nil && s.authFlow.Status().State == "waiting" {
+		s.authFlow.Cancel()
+	}
+
+	flow, err := startAuthFlow(clientID, clientSecret, s.whoopClient.InstallTokens)
+	if err != nil {
+		return "", err
+	}
+	s.authFlow = flow
+
+	return fmt.Sprintf(`# Whoop OAuth Setup - Automatic
+
+## 🔗 Authorization URL Generated
+
+**Open this URL to authorize your Whoop app:**
+
+%s
+
+## ✨ What Happens Next
+
+A temporary callback server is listening on %s for the next %s. As soon as you approve access, the authorization code is captured, exchanged, and the tokens are stored automatically - no copying required.
+
+Ask me to "check Whoop auth status" to confirm it finished.`, flow.Status().AuthURL, WhoopRedirectURI, callbackTimeout), nil
+}
+
+// formatAuthFlowStatus reports the state of the most recent automatic auth flow
+func (s *MCPServer) formatAuthFlowStatus() string {
+	s.mu.RLock()
+	flow := s.authFlow
+	s.mu.RUnlock()
+
+	if flow == nil {
+		return "No automatic Whoop authorization has been started. Use setup_whoop_auth with auto_callback, client_id, and client_secret."
+	}
+
+	status := flow.Status()
+	switch status.State {
+	case "completed":
+		return fmt.Sprintf("# ✅ Whoop Authorization Complete\n\nTokens were captured and stored. **Scopes:** %s", status.Scopes)
+	case "failed":
+		return fmt.Sprintf("# ❌ Whoop Authorization Failed\n\n**Error:** %s\n\nStart again with setup_whoop_auth and auto_callback.", status.Error)
+	default:
+		return fmt.Sprintf("# ⏳ Waiting for Whoop Authorization\n\nStarted %s ago. Open the authorization URL if you haven't yet:\n\n%s",
+			time.Since(status.StartedAt).Round(time.Second), status.AuthURL)
+	}
+}
+
+// generateAuthURL creates the Whoop OAuth authorization URL
+func (s *MCPServer) generateAuthURL(clientID string) (string, error) {
+	state, err := s.oauthStates.Issue()
+	if err != nil {
+		return "", err
+	}
+	authURL := buildAuthURL(clientID, state)
+
+	return fmt.Sprintf(`# Whoop OAuth Setup - Step 1
+
+## 🔗 Authorization URL Generated
+
+**Click this URL to authorize your Whoop app:**
+
+%s
+
+## 📋 Next Steps:
+
+1. **Open the URL above** in your browser
+2. **Log in to Whoop** and authorize the app
+3. **Copy the authorization code and state** from the callback URL
+4. **Ask me to exchange the code for tokens** by saying:
+   "Exchange my Whoop authorization code: [YOUR_CODE_HERE] with state: [YOUR_STATE_HERE]"
+
+## ⚠️ Note:
+The redirect URL may show an error page - that's normal! Just copy the 'code' and 'state' parameters from the URL bar.
+
+Example callback URL:
+http://localhost:3000/callback?code=ABC123...&state=%s
+
+The state must match this attempt and expires in %s.`, authURL, state, oauthStateTTL), nil
+}
+
+// exchangeCodeForTokens exchanges authorization code for access/refresh tokens.
+// Tokens go straight to the token store; the response only shows masked
+// previews unless reveal is set or they couldn't be stored.
+func (s *MCPServer) exchangeCodeForTokens(clientID, clientSecret, authCode string, reveal bool) (string, error) {
+	tokenResp, err := exchangeAuthorizationCode(http.DefaultClient, clientID, clientSecret, authCode)
+	if err != nil {
+		var exchangeErr *TokenExchangeError
+		if !errors.As(err, &exchangeErr) {
+			return "", err
+		}
+		return fmt.Sprintf(`# ❌ Token Exchange Failed
+
+**Error (status %d):**
+%s
+
+## Common Issues:
+- Authorization code already used (codes are single-use)
+- Authorization code expired (they expire quickly)
+- Wrong redirect URI (must match exactly)
+- Invalid client credentials
+
+## 🔄 Try Again:
+Ask me to generate a new authorization URL with your client_id.`, exchangeErr.StatusCode, exchangeErr.Body), nil
+	}
+
+	return s.installExchangedTokens(tokenResp, reveal), nil
+}
+
+// installExchangedTokens puts freshly exchanged tokens to use and reports
+// them. The authorization code they came from is single-use, so when they
+// can't be stored the report shows them in full for the user to save by hand.
+func (s *MCPServer) installExchangedTokens(tokenResp *OAuthTokenResponse, reveal bool) string {
+	// Resume normal operation immediately and persist to the token store
+	store := s.whoopClient.TokenStore()
+	err := s.whoopClient.InstallTokens(tokenResp)
+	if err == nil && store == nil {
+		err = errors.New("no token store is configured")
+	}
+	var storage string
+	if err == nil {
+		storage = fmt.Sprintf("Stored in **%s** - no manual configuration needed.", store.Name())
+	} else {
+		log.Printf("Failed to persist exchanged tokens: %v", err)
+		storage = fmt.Sprintf(`⚠️ Could not store tokens (%v). The server is using them for this session only, so they are shown in full above. To keep them, add them to your MCP client's environment for this server:
+
+`+"```"+`
+WHOOP_ACCESS_TOKEN=%s
+WHOOP_REFRESH_TOKEN=%s
+`+"```", err, tokenResp.AccessToken, tokenResp.RefreshToken)
+		reveal = true
+	}
+
+	accessToken := maskToken(tokenResp.AccessToken)
+	refreshToken := maskToken(tokenResp.RefreshToken)
+	if reveal {
+		accessToken = tokenResp.AccessToken
+		refreshToken = tokenResp.RefreshToken
+	}
+
+	return fmt.Sprintf(`# ✅ Success! Whoop Tokens Obtained
+
+## 🎉 Your Authentication is Complete!
+
+**Access Token:** %s
+**Refresh Token:** %s
+**Expires in:** %d seconds (%.1f hours)
+**Scopes:** %s
+
+## 🔒 Token Storage
+
+%s
+
+## 🚀 Next Steps:
+
+1. **This server is already using the new token** - restart Claude Desktop only if other sessions need it
+
+2. **Test your connection** by asking me:
+   "Analyze my Whoop data from yesterday"`,
+		accessToken,
+		refreshToken,
+		tokenResp.ExpiresIn,
+		float64(tokenResp.ExpiresIn)/3600,
+		tokenResp.Scope,
+		storage)
+}
+
+// generateAuthInstructions provides general setup instructions
+func (s *MCPServer) generateAuthInstructions() string {
+	return `# 🔐 Whoop OAuth Setup Guide
+
+## Prerequisites:
+1. **Whoop Developer Account** - Sign up at https://developer.whoop.com
+2. **Create an App** in the Whoop Developer Portal
+3. **Set Redirect URI** to: http://localhost:3000/callback
+
+## ⚡ Fastest Option
+Ask me: "Set up Whoop auth automatically with client_id: YOUR_CLIENT_ID and secret: YOUR_SECRET"
+I'll start a temporary callback server that captures the code for you. You can also run ` + "`whoop-mcp-server auth`" + ` from a terminal.
+
+## Manual Setup Process:
+
+### Step 1: Get Authorization URL
+Ask me: "Generate Whoop auth URL for client_id: YOUR_CLIENT_ID"
+
+### Step 2: Authorize App
+I'll provide a URL to authorize your app with Whoop
+
+### Step 3: Exchange Code
+After authorization, ask me: "Exchange Whoop code: YOUR_CODE with state: YOUR_STATE and secret: YOUR_SECRET"
+
+### Step 4: Done
+Tokens are saved to the configured token store automatically (only masked previews are shown)
+
+## 💡 Need Help?
+- Ask me to "Generate Whoop auth URL" if you have a client_id
+- Visit https://developer.whoop.com for app setup instructions
+- The process takes just a few minutes!`
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"sort"
+	"time"
+)
+
+// datedValue is a single observation of a metric on a given day
+type datedValue struct {
+	Date  time.Time
+	Value float64
+}
+
+// metricDefinition describes a daily metric that can be extracted from Whoop data
+type metricDefinition struct {
+	Key            string
+	Label          string
+	Unit           string
+	HigherIsBetter bool
+	dataset        string // the healthDatasets entry extract reads, if any
+	extract        func(data *HealthData) []datedValue
+}
+
+// dailyMetrics lists the metrics used by cross-period and cross-metric analyses
+var dailyMetrics = []metricDefinition{
+	{
+		Key: "recovery", Label: "Recovery", Unit: "%", HigherIsBetter: true, dataset: "recovery",
+		extract: func(data *HealthData) []datedValue {
+			return localRecoveryValues(data, func(r WhoopRecovery) float64 { return r.Score.RecoveryScore })
+		},
+	},
+	{
+		Key: "hrv", Label: "HRV (RMSSD)", Unit: "ms", HigherIsBetter: true, dataset: "recovery",
+		extract: func(data *HealthData) []datedValue {
+			return localRecoveryValues(data, func(r WhoopRecovery) float64 { return r.Score.HRVRmssd })
+		},
+	},
+	{
+		Key: "resting_hr", Label: "Resting HR", Unit: "bpm", HigherIsBetter: false, dataset: "recovery",
+		extract: func(data *HealthData) []datedValue {
+			return localRecoveryValues(data, func(r WhoopRecovery) float64 { return r.Score.RestingHeartRate })
+		},
+	},
+	{
+		Key: "sleep_hours", Label: "Sleep Duration", Unit: "h", HigherIsBetter: true, dataset: "sleep",
+		extract: func(data *HealthData) []datedValue {
+			return mainSleepValues(data.Sleeps, func(s WhoopSleep) float64 {
+				stages := s.Score.StageSummary
+				return float64(stages.TotalInBedTimeMilli-stages.TotalAwakeTimeMilli) / (1000 * 60 * 60)
+			})
+		},
+	},
+	{
+		Key: "sleep_performance", Label: "Sleep Performance", Unit: "%", HigherIsBetter: true, dataset: "sleep",
+		extract: func(data *HealthData) []datedValue {
+			return mainSleepValues(data.Sleeps, func(s WhoopSleep) float64 { return s.Score.SleepPerformancePercentage })
+		},
+	},
+	{
+		Key: "strain", Label: "Day Strain", Unit: "", HigherIsBetter: false, dataset: "cycle",
+		extract: func(data *HealthData) []datedValue {
+			var values []datedValue
+			for _, cycle := range data.Cycles {
+				if cycle.ScoreState != "" && cycle.ScoreState != "SCORED" {
+					continue
+				}
+				// Cycles begin at sleep onset the night before; date them by their midday
+				values = append(values, datedValue{Date: localTime(cycle.Start, cycle.TimezoneOffset).Add(12 * time.Hour), Value: cycle.Score.Strain})
+			}
+			return sortDatedValues(values)
+		},
+	},
+}
+
+// lookupMetric finds a metric definition by key
+func lookupMetric(key string) (metricDefinition, bool) {
+	for _, metric := range dailyMetrics {
+		if metric.Key == key {
+			return metric, true
+		}
+	}
+	return metricDefinition{}, false
+}
+
+// metricKeys lists the keys of all daily metrics, for tool schemas
+func metricKeys() []string {
+	keys := make([]string, len(dailyMetrics))
+	for i, metric := range dailyMetrics {
+		keys[i] = metric.Key
+	}
+	return keys
+}
+
+// recoveryValues extracts a scored recovery field as a dated series
+func recoveryValues(recoveries []WhoopRecovery, field func(WhoopRecovery) float64) []datedValue {
+	var values []datedValue
+	for _, recovery := range recoveries {
+		if recovery.ScoreState != "" && recovery.ScoreState != "SCORED" {
+			continue
+		}
+		values = append(values, datedValue{Date: recovery.CreatedAt, Value: field(recovery)})
+	}
+	return sortDatedValues(values)
+}
+
+// localRecoveryValues extracts a scored recovery field dated in the wearer's
+// local time, taken from the sleep each recovery belongs to
+func localRecoveryValues(data *HealthData, field func(WhoopRecovery) float64) []datedValue {
+	offsets := make(map[string]string, len(data.Sleeps))
+	for _, sleep := range data.Sleeps {
+		offsets[sleep.ID] = sleep.TimezoneOffset
+	}
+
+	var values []datedValue
+	for _, recovery := range data.Recoveries {
+		if recovery.ScoreState != "" && recovery.ScoreState != "SCORED" {
+			continue
+		}
+		values = append(values, datedValue{Date: localTime(recovery.CreatedAt, offsets[recovery.SleepID]), Value: field(recovery)})
+	}
+	return sortDatedValues(values)
+}
+
+// mainSleepValues extracts a scored main-sleep field as a dated series, dated by local wake time
+func mainSleepValues(sleeps []WhoopSleep, field func(WhoopSleep) float64) []datedValue {
+	var values []datedValue
+	for _, sleep := range sleeps {
+		if sleep.Nap || (sleep.ScoreState != "" && sleep.ScoreState != "SCORED") {
+			continue
+		}
+		values = append(values, datedValue{Date: localTime(sleep.End, sleep.TimezoneOffset), Value: field(sleep)})
+	}
+	return sortDatedValues(values)
+}
+
+// sortDatedValues orders a series chronologically
+func sortDatedValues(values []datedValue) []datedValue {
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Date.Before(values[j].Date)
+	})
+	return values
+}
+
+// dayKey identifies the calendar day of an observation
+func dayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// dailyAverages collapses a series to one value per calendar day
+func dailyAverages(series []datedValue) map[string]float64 {
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, v := range series {
+		key := dayKey(v.Date)
+		sums[key] += v.Value
+		counts[key]++
+	}
+	for key := range sums {
+		sums[key] /= float64(counts[key])
+	}
+	return sums
+}
+
+// valuesOf drops the dates from a series
+func valuesOf(series []datedValue) []float64 {
+	values := make([]float64, len(series))
+	for i, v := range series {
+		values[i] = v.Value
+	}
+	return values
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ToolHandler runs a tool call and returns its content blocks
+type ToolHandler func(ctx context.Context, name string, arguments json.RawMessage) ([]map[string]interface{}, error)
+
+// ToolMiddleware wraps a ToolHandler with behavior that applies across tools,
+// such as logging, access checks, or rate limiting. A middleware may inspect
+// or rewrite the call, short-circuit it with an error, or post-process the
+// content the rest of the chain returns.
+type ToolMiddleware func(next ToolHandler) ToolHandler
+
+// Use appends middlewares to the tool chain. They run in registration order,
+// inside the built-in ones (tracing, redaction, account aliases, auditing,
+// timing, read-only checks, result caching, rate limiting), so they see
+// restored arguments and unredacted output.
+func (s *MCPServer) Use(middlewares ...ToolMiddleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.middlewares = append(s.middlewares, middlewares...)
+	s.toolChain = nil
+}
+
+// chain returns the tool handler with every middleware applied, building it
+// on first use after a registration
+func (s *MCPServer) chain() ToolHandler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.toolChain != nil {
+		return s.toolChain
+	}
+
+	builtin := []ToolMiddleware{
+		tracingMiddleware,
+		s.redactionMiddleware,
+		s.accountAliasMiddleware,
+		s.auditMiddleware,
+		timingMiddleware,
+		s.modeMiddleware,
+	}
+	if s.toolCache != nil {
+		builtin = append(builtin, s.toolCache.middleware)
+	}
+	if s.toolLimits != nil {
+		builtin = append(builtin, s.toolLimits.middleware)
+	}
+	middlewares := append(builtin, s.middlewares...)
+
+	handler := ToolHandler(s.executeToolContent)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	s.toolChain = handler
+	return handler
+}
+
+// redactionMiddleware restores redacted placeholders in the arguments and
+// redacts the text the tool returns
+func (s *MCPServer) redactionMiddleware(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, name string, arguments json.RawMessage) ([]map[string]interface{}, error) {
+		if s.redactor != nil {
+			arguments = s.redactor.RestoreArguments(arguments)
+		}
+		content, err := next(ctx, name, arguments)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range content {
+			if text, ok := item["text"].(string); ok {
+				item["text"] = s.redact(text)
+			}
+		}
+		return content, nil
+	}
+}
+
+// accountAliasMiddleware swaps an account alias given as user_id for the
+// account's ID before the call is audited, cached, or run
+func (s *MCPServer) accountAliasMiddleware(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, name string, arguments json.RawMessage) ([]map[string]interface{}, error) {
+		arguments, err := s.whoopClient.Accounts().ResolveArguments(arguments)
+		if err != nil {
+			return nil, err
+		}
+		return next(ctx, name, arguments)
+	}
+}
+
+// auditMiddleware records the call in the audit log
+func (s *MCPServer) auditMiddleware(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, name string, arguments json.RawMessage) ([]map[string]interface{}, error) {
+		content, err := next(ctx, name, arguments)
+		s.auditToolCall(name, arguments, err)
+		return content, err
+	}
+}
+
+// modeMiddleware refuses calls that read-only mode does not allow
+func (s *MCPServer) modeMiddleware(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, name string, arguments json.RawMessage) ([]map[string]interface{}, error) {
+		if err := s.checkMode(name, arguments); err != nil {
+			return nil, err
+		}
+		return next(ctx, name, arguments)
+	}
+}
+
+// tracingMiddleware records a span for the call, under the MCP request's
+func tracingMiddleware(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, name string, arguments json.RawMessage) ([]map[string]interface{}, error) {
+		ctx, span := startSpan(ctx, "tool "+name, spanInternal)
+		span.SetAttribute("mcp.tool.name", name)
+		content, err := next(ctx, name, arguments)
+		span.End(err)
+		return content, err
+	}
+}
+
+// timingMiddleware logs how long each tool call took
+func timingMiddleware(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, name string, arguments json.RawMessage) ([]map[string]interface{}, error) {
+		started := time.Now()
+		content, err := next(ctx, name, arguments)
+		elapsed := time.Since(started).Round(time.Millisecond)
+		if err != nil {
+			log.Printf("Tool %s failed after %s: %v", name, elapsed, err)
+		} else {
+			log.Printf("Tool %s finished in %s", name, elapsed)
+		}
+		return content, err
+	}
+}
+
+// ToolRateLimitError is returned when a tool is called faster than
+// WHOOP_TOOL_RATE_LIMIT allows
+type ToolRateLimitError struct {
+	Tool      string
+	PerMinute int
+}
+
+func (e *ToolRateLimitError) Error() string {
+	return fmt.Sprintf("%s is limited to %d calls per minute; try again shortly", e.Tool, e.PerMinute)
+}
+
+// toolRateLimits keeps a separate limiter per tool, so one chatty tool can't
+// starve the others
+type toolRateLimits struct {
+	perMinute int
+	mu        sync.Mutex
+	limiters  map[string]*rate.Limiter
+}
+
+// NewToolRateLimitsFromEnv reads WHOOP_TOOL_RATE_LIMIT, the calls per minute
+// allowed for each tool; unset or 0 leaves tools unlimited
+func NewToolRateLimitsFromEnv(settings Settings) (*toolRateLimits, error) {
+	perMinute, err := intSettingFromEnv(settings, "WHOOP_TOOL_RATE_LIMIT", 0, 0, 1000)
+	if err != nil {
+		return nil, err
+	}
+	if perMinute == 0 {
+		return nil, nil
+	}
+	return newToolRateLimits(perMinute), nil
+}
+
+func newToolRateLimits(perMinute int) *toolRateLimits {
+	return &toolRateLimits{perMinute: perMinute, limiters: make(map[string]*rate.Limiter)}
+}
+
+// allow reports whether tool may run now
+func (l *toolRateLimits) allow(tool string) bool {
+	l.mu.Lock()
+	limiter, ok := l.limiters[tool]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(l.perMinute)), l.perMinute)
+		l.limiters[tool] = limiter
+	}
+	l.mu.Unlock()
+	return limiter.Allow()
+}
+
+func (l *toolRateLimits) middleware(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, name string, arguments json.RawMessage) ([]map[string]interface{}, error) {
+		if !l.allow(name) {
+			return nil, &ToolRateLimitError{Tool: name, PerMinute: l.perMinute}
+		}
+		return next(ctx, name, arguments)
+	}
+}
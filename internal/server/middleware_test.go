@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// stubTool answers every call without reaching a real tool
+func stubTool(text string) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, name string, arguments json.RawMessage) ([]map[string]interface{}, error) {
+			return []map[string]interface{}{{"type": "text", "text": text}}, nil
+		}
+	}
+}
+
+func TestUse_Order(t *testing.T) {
+	server := newMockServer(t, NewMockWhoopAPI())
+
+	var calls []string
+	trace := func(label string) ToolMiddleware {
+		return func(next ToolHandler) ToolHandler {
+			return func(ctx context.Context, name string, arguments json.RawMessage) ([]map[string]interface{}, error) {
+				calls = append(calls, label+" "+name)
+				content, err := next(ctx, name, arguments)
+				calls = append(calls, label+" done")
+				return content, err
+			}
+		}
+	}
+	server.Use(trace("outer"), trace("inner"))
+	server.Use(stubTool("stubbed"))
+
+	content, err := server.CallTool(context.Background(), "analyze_hrv", json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if text := content[0]["text"]; text != "stubbed" {
+		t.Errorf("text = %v, want the stub's answer", text)
+	}
+	if got := strings.Join(calls, ", "); got != "outer analyze_hrv, inner analyze_hrv, inner done, outer done" {
+		t.Errorf("calls = %s", got)
+	}
+}
+
+func TestUse_ReadOnlyRunsFirst(t *testing.T) {
+	server := newMockServer(t, NewMockWhoopAPI())
+	if err := server.SetMode(serverModeReadOnly); err != nil {
+		t.Fatal(err)
+	}
+	server.Use(stubTool("stubbed"))
+
+	if _, err := server.CallTool(context.Background(), "log_annotation", json.RawMessage(`{"note": "rough day"}`)); err == nil ||
+		!strings.Contains(err.Error(), "read-only") {
+		t.Errorf("log_annotation error = %v, want a read-only refusal before registered middleware", err)
+	}
+}
+
+func TestUse_AccountAlias(t *testing.T) {
+	accounts := filepath.Join(t.TempDir(), "accounts.json")
+	if err := os.WriteFile(accounts, []byte(`{"accounts":[{"user_id":42,"alias":"Client A","access_token":"a1"}]}`), 0600); err != nil {
+		t.Fatalf("failed to write accounts file: %v", err)
+	}
+	var hits atomic.Int64
+	api := fixtureAPI(t, &hits)
+	clearConfigEnv(t)
+	t.Setenv("WHOOP_API_BASE_URL", api.URL)
+	t.Setenv("WHOOP_ACCESS_TOKEN", "token")
+	t.Setenv("WHOOP_ACCOUNTS_FILE", accounts)
+	t.Setenv("WHOOP_DATA_DIR", t.TempDir())
+	server, err := NewMCPServer(os.Getenv)
+	if err != nil {
+		t.Fatalf("NewMCPServer() error = %v", err)
+	}
+
+	var received string
+	server.Use(func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, name string, arguments json.RawMessage) ([]map[string]interface{}, error) {
+			received = string(arguments)
+			return next(ctx, name, arguments)
+		}
+	}, stubTool("stubbed"))
+
+	if _, err := server.CallTool(context.Background(), "analyze_hrv", json.RawMessage(`{"user_id": "client a"}`)); err != nil || received != `{"user_id":42}` {
+		t.Errorf("CallTool() = %v with arguments %s, want the alias resolved to user 42", err, received)
+	}
+	if _, err := server.CallTool(context.Background(), "analyze_hrv", json.RawMessage(`{"user_id": "Client B"}`)); err == nil || !strings.Contains(err.Error(), "no account with alias") {
+		t.Errorf("CallTool() with an unknown alias error = %v", err)
+	}
+}
+
+func TestToolRateLimits(t *testing.T) {
+	t.Setenv("WHOOP_TOOL_RATE_LIMIT", "abc")
+	if _, err := NewToolRateLimitsFromEnv(os.Getenv); err == nil {
+		t.Error("expected an invalid WHOOP_TOOL_RATE_LIMIT to fail")
+	}
+	t.Setenv("WHOOP_TOOL_RATE_LIMIT", "0")
+	if limits, err := NewToolRateLimitsFromEnv(os.Getenv); err != nil || limits != nil {
+		t.Errorf("NewToolRateLimitsFromEnv() = %v, %v; want unlimited", limits, err)
+	}
+
+	server := newMockServer(t, NewMockWhoopAPI())
+	server.toolLimits = newToolRateLimits(2)
+	server.toolCache = nil
+	server.Use(stubTool("stubbed"))
+	server.initialized = true
+
+	call := func(tool string) *MCPResponse {
+		params, _ := json.Marshal(map[string]interface{}{"name": tool, "arguments": map[string]interface{}{}})
+		return server.handleToolsCall(context.Background(), &MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+	}
+	for i := 0; i < 2; i++ {
+		if response := call("analyze_hrv"); response.Error != nil || response.Result.(map[string]interface{})["isError"] != nil {
+			t.Fatalf("call %d was refused: %+v", i+1, response)
+		}
+	}
+	limited := call("analyze_hrv").Result.(map[string]interface{})
+	if limited["isError"] != true || !strings.Contains(limited["content"].([]map[string]interface{})[0]["text"].(string), "2 calls per minute") {
+		t.Errorf("third call = %v, want a rate limit error", limited)
+	}
+	// Limits are per tool
+	if response := call("analyze_vitals"); response.Result.(map[string]interface{})["isError"] != nil {
+		t.Errorf("analyze_vitals was limited by analyze_hrv's calls: %+v", response.Result)
+	}
+
+	_, err := server.CallTool(context.Background(), "analyze_hrv", json.RawMessage(`{}`))
+	var limitErr *ToolRateLimitError
+	if !errors.As(err, &limitErr) || limitErr.Tool != "analyze_hrv" {
+		t.Errorf("CallTool() error = %v, want a ToolRateLimitError", err)
+	}
+}
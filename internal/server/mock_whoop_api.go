@@ -0,0 +1,267 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockWhoopAPI is an in-memory WhoopAPI for testing tool handlers. Fill Data
+// directly or with GenerateHealthData; queries return the records whose start
+// (created_at for recoveries) falls in [startDate, endDate), as the API does.
+// Set the exported fields before handing the mock to NewMCPServerWithAPI.
+type MockWhoopAPI struct {
+	User WhoopUser
+	Data HealthData
+	// AccountData serves user_id queries for other accounts, by user ID
+	AccountData map[int]HealthData
+	// Err, when set, fails every health data call
+	Err error
+	// AuthErr, when set, puts the mock in the needs-auth state
+	AuthErr *AuthRequiredError
+	// ScopeStatus is what Scopes and DetectScopes report
+	ScopeStatus ScopeStatus
+	// Store is what TokenStore returns
+	Store TokenStore
+	// InstallErr, when set, is what InstallTokens reports after installing
+	// the tokens, as when the token store can't be written
+	InstallErr error
+
+	mu        sync.Mutex
+	calls     map[string]int
+	installed []*OAuthTokenResponse
+	readOnly  bool
+	meter     *requestMeter
+	sports    *SportsCatalog
+	timezone  *UserTimezone
+}
+
+var _ WhoopAPI = (*MockWhoopAPI)(nil)
+
+// NewMockWhoopAPI creates a mock with a demo user, every scope granted, UTC
+// as the user's timezone, and no records
+func NewMockWhoopAPI() *MockWhoopAPI {
+	granted := make([]string, 0, len(endpointScopes))
+	for _, entry := range endpointScopes {
+		granted = append(granted, entry.scope)
+	}
+	sort.Strings(granted)
+	return &MockWhoopAPI{
+		User:        WhoopUser{UserID: 1, Email: "athlete@example.com", FirstName: "Test", LastName: "Athlete"},
+		ScopeStatus: ScopeStatus{Source: "token_response", Granted: granted},
+		calls:       make(map[string]int),
+		meter:       newRequestMeter(),
+		sports:      NewSportsCatalog(),
+		timezone:    &UserTimezone{configured: time.UTC},
+	}
+}
+
+// NewSyntheticMockWhoopAPI creates a mock serving data generated from opts
+func NewSyntheticMockWhoopAPI(opts SyntheticOptions) *MockWhoopAPI {
+	mock := NewMockWhoopAPI()
+	mock.Data = GenerateHealthData(opts)
+	if opts.UserID != 0 {
+		mock.User.UserID = int(opts.UserID)
+	}
+	if opts.Location != nil {
+		mock.timezone = &UserTimezone{configured: opts.Location}
+	}
+	return mock
+}
+
+// Calls returns how many times method was called
+func (m *MockWhoopAPI) Calls(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[method]
+}
+
+// InstalledTokens returns the tokens passed to InstallTokens, oldest first
+func (m *MockWhoopAPI) InstalledTokens() []*OAuthTokenResponse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*OAuthTokenResponse(nil), m.installed...)
+}
+
+// ReadOnly reports whether SetReadOnly(true) was called last
+func (m *MockWhoopAPI) ReadOnly() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.readOnly
+}
+
+// record counts a call and returns the data it should read
+func (m *MockWhoopAPI) record(method string, userID *int) (HealthData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls[method]++
+	m.meter.Record(200, nil)
+
+	switch {
+	case m.Err != nil:
+		return HealthData{}, m.Err
+	case userID != nil && *userID != m.User.UserID:
+		data, ok := m.AccountData[*userID]
+		if !ok {
+			return HealthData{}, fmt.Errorf("no credentials configured for user %d", *userID)
+		}
+		return data, nil
+	case m.AuthErr != nil:
+		return HealthData{}, m.AuthErr
+	}
+	return m.Data, nil
+}
+
+func (m *MockWhoopAPI) GetUser(ctx context.Context) (*WhoopUser, error) {
+	if _, err := m.record("GetUser", nil); err != nil {
+		return nil, err
+	}
+	user := m.User
+	return &user, nil
+}
+
+func (m *MockWhoopAPI) GetRecoveryData(ctx context.Context, startDate, endDate time.Time, userID *int) ([]WhoopRecovery, error) {
+	data, err := m.record("GetRecoveryData", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recovery data: %w", err)
+	}
+	return recordsBetween(data.Recoveries, func(r WhoopRecovery) time.Time { return r.CreatedAt }, startDate, endDate), nil
+}
+
+func (m *MockWhoopAPI) GetSleepData(ctx context.Context, startDate, endDate time.Time, userID *int) ([]WhoopSleep, error) {
+	return m.GetSleepDataFiltered(ctx, startDate, endDate, userID, SleepAll)
+}
+
+func (m *MockWhoopAPI) GetSleepDataFiltered(ctx context.Context, startDate, endDate time.Time, userID *int, filter SleepFilter) ([]WhoopSleep, error) {
+	data, err := m.record("GetSleepData", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sleep data: %w", err)
+	}
+	var sleeps []WhoopSleep
+	for _, sleep := range recordsBetween(data.Sleeps, func(s WhoopSleep) time.Time { return s.Start }, startDate, endDate) {
+		if (filter == SleepMainOnly && sleep.Nap) || (filter == SleepNapsOnly && !sleep.Nap) {
+			continue
+		}
+		sleeps = append(sleeps, sleep)
+	}
+	return sleeps, nil
+}
+
+func (m *MockWhoopAPI) GetWorkoutData(ctx context.Context, startDate, endDate time.Time, userID *int) ([]WhoopWorkout, error) {
+	data, err := m.record("GetWorkoutData", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workout data: %w", err)
+	}
+	workouts := recordsBetween(data.Workouts, func(w WhoopWorkout) time.Time { return w.Start }, startDate, endDate)
+	m.sports.Learn(workouts)
+	return workouts, nil
+}
+
+func (m *MockWhoopAPI) GetCycleData(ctx context.Context, startDate, endDate time.Time, userID *int) ([]WhoopCycle, error) {
+	data, err := m.record("GetCycleData", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cycle data: %w", err)
+	}
+	return recordsBetween(data.Cycles, func(c WhoopCycle) time.Time { return c.Start }, startDate, endDate), nil
+}
+
+func (m *MockWhoopAPI) EachRecoveryPage(ctx context.Context, startDate, endDate time.Time, userID *int, page func([]WhoopRecovery) error) error {
+	recoveries, err := m.GetRecoveryData(ctx, startDate, endDate, userID)
+	if err != nil {
+		return err
+	}
+	return eachPage(recoveries, page)
+}
+
+func (m *MockWhoopAPI) EachSleepPage(ctx context.Context, startDate, endDate time.Time, userID *int, page func([]WhoopSleep) error) error {
+	sleeps, err := m.GetSleepData(ctx, startDate, endDate, userID)
+	if err != nil {
+		return err
+	}
+	return eachPage(sleeps, page)
+}
+
+func (m *MockWhoopAPI) EachWorkoutPage(ctx context.Context, startDate, endDate time.Time, userID *int, page func([]WhoopWorkout) error) error {
+	workouts, err := m.GetWorkoutData(ctx, startDate, endDate, userID)
+	if err != nil {
+		return err
+	}
+	return eachPage(workouts, page)
+}
+
+func (m *MockWhoopAPI) EachCyclePage(ctx context.Context, startDate, endDate time.Time, userID *int, page func([]WhoopCycle) error) error {
+	cycles, err := m.GetCycleData(ctx, startDate, endDate, userID)
+	if err != nil {
+		return err
+	}
+	return eachPage(cycles, page)
+}
+
+func (m *MockWhoopAPI) ValidateConnection() error {
+	if _, err := m.GetUser(context.Background()); err != nil {
+		return fmt.Errorf("API connection validation failed: %w", err)
+	}
+	return nil
+}
+
+func (m *MockWhoopAPI) AuthRequired() *AuthRequiredError {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.AuthErr
+}
+
+func (m *MockWhoopAPI) CanRefreshToken() bool { return false }
+
+func (m *MockWhoopAPI) TokenExpiry() *time.Time { return nil }
+
+func (m *MockWhoopAPI) TokenStore() TokenStore { return m.Store }
+
+func (m *MockWhoopAPI) ClientID() string { return "" }
+
+// TokenGeneration counts the tokens passed to InstallTokens
+func (m *MockWhoopAPI) TokenGeneration() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return uint64(len(m.installed))
+}
+
+// InstallTokens records the tokens and leaves the needs-auth state
+func (m *MockWhoopAPI) InstallTokens(tokens *OAuthTokenResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.installed = append(m.installed, tokens)
+	m.AuthErr = nil
+	if scope := strings.Fields(tokens.Scope); len(scope) > 0 {
+		m.ScopeStatus = ScopeStatus{Source: "token_response", Granted: scope}
+	}
+	return m.InstallErr
+}
+
+func (m *MockWhoopAPI) SetReadOnly(readOnly bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.readOnly = readOnly
+}
+
+func (m *MockWhoopAPI) DetectScopes() ScopeStatus { return m.Scopes() }
+
+func (m *MockWhoopAPI) Scopes() ScopeStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ScopeStatus
+}
+
+func (m *MockWhoopAPI) RateLimitStatus() RateLimitStatus {
+	return m.meter.Snapshot()
+}
+
+func (m *MockWhoopAPI) Sports() *SportsCatalog { return m.sports }
+
+func (m *MockWhoopAPI) Timezone() *UserTimezone { return m.timezone }
+
+func (m *MockWhoopAPI) Accounts() *CredentialStore { return nil }
+
+func (m *MockWhoopAPI) FixtureSource() string { return "" }
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newMockServer builds a server over mock with its datastore in a temp dir
+func newMockServer(t *testing.T, mock *MockWhoopAPI) *MCPServer {
+	t.Helper()
+	clearConfigEnv(t)
+	t.Setenv("WHOOP_DATA_DIR", t.TempDir())
+	server, err := NewMCPServerWithAPI(mock, os.Getenv)
+	if err != nil {
+		t.Fatalf("NewMCPServerWithAPI() error = %v", err)
+	}
+	return server
+}
+
+func TestMockWhoopAPI_Queries(t *testing.T) {
+	end := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	mock := NewSyntheticMockWhoopAPI(SyntheticOptions{Seed: 1, End: end, Days: 30, NapsPerWeek: 3})
+
+	week, err := mock.GetRecoveryData(context.Background(), end.AddDate(0, 0, -7), end, nil)
+	if err != nil || len(week) != 7 {
+		t.Fatalf("GetRecoveryData() = %d records, %v; want 7", len(week), err)
+	}
+	if !week[0].CreatedAt.After(week[6].CreatedAt) {
+		t.Error("Expected records newest first")
+	}
+	naps, _ := mock.GetSleepDataFiltered(context.Background(), time.Time{}, time.Time{}, nil, SleepNapsOnly)
+	for _, nap := range naps {
+		if !nap.Nap {
+			t.Error("SleepNapsOnly returned a main sleep")
+		}
+	}
+	if mock.Calls("GetRecoveryData") != 1 || mock.Calls("GetSleepData") != 1 {
+		t.Errorf("Calls = %d recovery, %d sleep; want 1 each", mock.Calls("GetRecoveryData"), mock.Calls("GetSleepData"))
+	}
+
+	other := 99
+	if _, err := mock.GetCycleData(context.Background(), time.Time{}, end, &other); err == nil || !strings.Contains(err.Error(), "user 99") {
+		t.Errorf("Expected an unknown account to fail, got %v", err)
+	}
+	mock.Err = errors.New("boom")
+	if _, err := mock.GetWorkoutData(context.Background(), time.Time{}, end, nil); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected Err to fail queries, got %v", err)
+	}
+}
+
+func TestMockWhoopAPI_ToolHandlers(t *testing.T) {
+	mock := NewSyntheticMockWhoopAPI(SyntheticOptions{Seed: 7, Days: 45})
+	server := newMockServer(t, mock)
+
+	output, err := server.executeTool(context.Background(), "get_health_summary", json.RawMessage(`{"start_date": "past 14 days", "end_date": "today"}`))
+	if err != nil {
+		t.Fatalf("get_health_summary error = %v", err)
+	}
+	for _, want := range []string{"Health Summary", "Recovery Trends", "Sleep Analysis"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected the summary to contain %q, got:\n%s", want, output)
+		}
+	}
+	if mock.Calls("GetRecoveryData") == 0 {
+		t.Error("Expected the handler to read recoveries from the mock")
+	}
+
+	mock.AuthErr = &AuthRequiredError{Reason: "refresh token revoked"}
+	if _, err := server.executeTool(context.Background(), "get_health_summary", json.RawMessage(`{"start_date": "past 14 days", "end_date": "today"}`)); err == nil {
+		t.Error("Expected handlers to surface the needs-auth state")
+	}
+	status, _ := server.executeTool(context.Background(), "server_status", json.RawMessage(`{}`))
+	if !strings.Contains(status, "refresh token revoked") {
+		t.Errorf("Expected server_status to report the auth problem, got:\n%s", status)
+	}
+
+	if err := server.SetMode(serverModeReadOnly); err != nil {
+		t.Fatalf("SetMode() error = %v", err)
+	}
+	if !mock.ReadOnly() {
+		t.Error("Expected read-only mode to reach the Whoop API")
+	}
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	minRating = 1
+	maxRating = 10
+)
+
+// moodPhysiologyMetrics are the daily metrics mood and symptoms are related to
+var moodPhysiologyMetrics = []string{"recovery", "hrv", "resting_hr", "sleep_hours", "sleep_performance"}
+
+// MoodCorrelation relates a subjective scale to one physiological metric
+type MoodCorrelation struct {
+	Metric  string            `json:"metric"`
+	Label   string            `json:"label"`
+	SameDay CorrelationResult `json:"same_day"`
+	NextDay CorrelationResult `json:"next_day"`
+}
+
+// MoodAnalysis relates logged mood or symptom ratings to physiology
+type MoodAnalysis struct {
+	Scale        string            `json:"scale"` // "mood" or a symptom name
+	Entries      int               `json:"entries"`
+	Average      float64           `json:"average"`
+	Correlations []MoodCorrelation `json:"correlations"`
+}
+
+// validRating reports whether v is on the 1-10 scale used for mood and symptoms
+func validRating(v int) bool {
+	return v >= minRating && v <= maxRating
+}
+
+// ratingMetric exposes a mood or symptom scale from annotations as a daily
+// metric so it can be paired with physiology by CorrelateMetrics
+func ratingMetric(annotations []Annotation, scale string) metricDefinition {
+	var values []datedValue
+	for _, annotation := range annotations {
+		date, err := time.Parse("2006-01-02", annotation.Date)
+		if err != nil {
+			continue
+		}
+		if scale == "mood" {
+			if annotation.Mood != nil {
+				values = append(values, datedValue{Date: date, Value: float64(*annotation.Mood)})
+			}
+			continue
+		}
+		if severity, ok := annotation.Symptoms[scale]; ok {
+			values = append(values, datedValue{Date: date, Value: float64(severity)})
+		}
+	}
+	values = sortDatedValues(values)
+
+	label := "Mood"
+	if scale != "mood" {
+		label = strings.ToUpper(scale[:1]) + scale[1:]
+	}
+	return metricDefinition{
+		Key:            scale,
+		Label:          label,
+		Unit:           "/10",
+		HigherIsBetter: scale == "mood",
+		extract:        func(*HealthData) []datedValue { return values },
+	}
+}
+
+// CorrelateMood relates a mood or symptom scale to recovery, HRV, resting HR,
+// and sleep on the same day and the following day
+func (h *HealthAnalyzer) CorrelateMood(data *HealthData, annotations []Annotation, scale string) MoodAnalysis {
+	rating := ratingMetric(annotations, scale)
+	ratings := valuesOf(rating.extract(data))
+
+	analysis := MoodAnalysis{Scale: scale, Entries: len(ratings), Average: h.calculateMean(ratings)}
+	for _, key := range moodPhysiologyMetrics {
+		metric, _ := lookupMetric(key)
+		analysis.Correlations = append(analysis.Correlations, MoodCorrelation{
+			Metric:  metric.Key,
+			Label:   metric.Label,
+			SameDay: h.CorrelateMetrics(data, rating, metric, 0),
+			NextDay: h.CorrelateMetrics(data, rating, metric, 1),
+		})
+	}
+	return analysis
+}
+
+// moodScales lists "mood" and every symptom logged in annotations
+func moodScales(annotations []Annotation) []string {
+	seen := make(map[string]bool)
+	var symptoms []string
+	for _, annotation := range annotations {
+		for symptom := range annotation.Symptoms {
+			if !seen[symptom] {
+				seen[symptom] = true
+				symptoms = append(symptoms, symptom)
+			}
+		}
+	}
+	sort.Strings(symptoms)
+	return append([]string{"mood"}, symptoms...)
+}
+
+// FormatMoodAnalysis renders the mood-physiology correlation table and the
+// relationships worth discussing
+func (h *HealthAnalyzer) FormatMoodAnalysis(analysis MoodAnalysis, available []string) string {
+	var builder strings.Builder
+	label := ratingMetric(nil, analysis.Scale).Label
+	builder.WriteString(fmt.Sprintf("# %s and Physiology\n\n", label))
+
+	if analysis.Entries < minCorrelationPairs {
+		builder.WriteString(fmt.Sprintf("Only %d %s rating(s) logged in this period; at least %d are needed to relate them to physiology. Log ratings with log_annotation.\n",
+			analysis.Entries, strings.ToLower(label), minCorrelationPairs))
+		if len(available) > 1 {
+			builder.WriteString(fmt.Sprintf("\nScales with entries: %s\n", strings.Join(available, ", ")))
+		}
+		return builder.String()
+	}
+
+	builder.WriteString(fmt.Sprintf("**Entries:** %d, averaging %.1f/10\n\n", analysis.Entries, analysis.Average))
+	builder.WriteString("| Metric | Same day r (p) | Next day r (p) |\n")
+	builder.WriteString("|---|---|---|\n")
+	for _, c := range analysis.Correlations {
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s |\n", c.Label, formatMoodCell(c.SameDay), formatMoodCell(c.NextDay)))
+	}
+
+	var findings []string
+	for _, c := range analysis.Correlations {
+		for _, result := range []CorrelationResult{c.SameDay, c.NextDay} {
+			if result.Confidence != "low" && result.Strength != "negligible" {
+				findings = append(findings, result.Interpretation)
+			}
+		}
+	}
+
+	builder.WriteString("\n## Findings\n")
+	if len(findings) == 0 {
+		builder.WriteString(fmt.Sprintf("No reliable relationship between %s and physiology yet. Subjective state may be driven by factors the wearable does not capture, or more entries are needed.\n", strings.ToLower(label)))
+	} else {
+		for _, finding := range findings {
+			builder.WriteString("- " + finding + "\n")
+		}
+		builder.WriteString("\nThese patterns can help separate body-driven low days from situational ones; they are associations to explore, not causes.\n")
+	}
+
+	if len(available) > 1 {
+		builder.WriteString(fmt.Sprintf("\n*Scales with entries: %s*\n", strings.Join(available, ", ")))
+	}
+	return builder.String()
+}
+
+// formatMoodCell renders one correlation as "r (p)", or a dash when there are too few pairs
+func formatMoodCell(result CorrelationResult) string {
+	if result.Pairs < minCorrelationPairs {
+		return fmt.Sprintf("— (%d pairs)", result.Pairs)
+	}
+	marker := ""
+	if result.PearsonP < 0.05 && math.Abs(result.Pearson) >= 0.1 {
+		marker = " *"
+	}
+	return fmt.Sprintf("%.2f (%.3f)%s", result.Pearson, result.PearsonP, marker)
+}
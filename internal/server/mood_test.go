@@ -0,0 +1,55 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_CorrelateMood(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	start := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+
+	recoveries := []float64{30, 80, 55, 90, 40, 70, 25, 85, 60, 45, 75, 35, 65, 50}
+	data := &HealthData{}
+	var annotations []Annotation
+	for i, score := range recoveries {
+		var recovery WhoopRecovery
+		recovery.CreatedAt = start.AddDate(0, 0, i)
+		recovery.Score.RecoveryScore = score
+		data.Recoveries = append(data.Recoveries, recovery)
+
+		mood := int(score / 10)
+		annotations = append(annotations, Annotation{
+			Date:     dayKey(start.AddDate(0, 0, i)),
+			Mood:     &mood,
+			Symptoms: map[string]int{"anxiety": 11 - mood},
+		})
+	}
+
+	analysis := analyzer.CorrelateMood(data, annotations, "mood")
+	if analysis.Entries != len(recoveries) {
+		t.Fatalf("Expected %d entries, got %d", len(recoveries), analysis.Entries)
+	}
+	if recovery := analysis.Correlations[0]; recovery.Metric != "recovery" || recovery.SameDay.Pearson < 0.95 {
+		t.Errorf("Expected a strong same-day mood/recovery correlation, got %+v", recovery.SameDay)
+	}
+
+	anxiety := analyzer.CorrelateMood(data, annotations, "anxiety")
+	if anxiety.Correlations[0].SameDay.Pearson > -0.95 {
+		t.Errorf("Expected anxiety to fall as recovery rises, got r=%.2f", anxiety.Correlations[0].SameDay.Pearson)
+	}
+
+	report := analyzer.FormatMoodAnalysis(analysis, moodScales(annotations))
+	if !strings.Contains(report, "Scales with entries: mood, anxiety") {
+		t.Errorf("Expected the available scales to be listed, got:\n%s", report)
+	}
+}
+
+func TestFormatAnnotations_Ratings(t *testing.T) {
+	mood := 6
+	out := FormatAnnotations([]Annotation{{ID: 3, Date: "2024-05-01", Mood: &mood, Symptoms: map[string]int{"headache": 3, "anxiety": 7}}})
+	if !strings.Contains(out, "mood 6/10, anxiety 7/10, headache 3/10") {
+		t.Errorf("Unexpected ratings rendering: %s", out)
+	}
+}
@@ -0,0 +1,50 @@
+package server
+
+const (
+	// napDeficitHours is how far short of need a night's main sleep must fall
+	// to count as a nighttime deficit
+	napDeficitHours = 1.0
+	// napCompensationMinNights is the fewest short nights followed by naps
+	// that make compensation a pattern
+	napCompensationMinNights = 3
+	// napCompensationShare is the share of nights that must be short, and of
+	// short nights that must be napped after, for compensation to be chronic
+	napCompensationShare = 0.5
+	// napNeedConcernShare is the share of sleep need met by naps above which
+	// the insight is raised as a concern
+	napNeedConcernShare = 0.15
+)
+
+// napCompensation is how much of the sleep need naps are covering
+type napCompensation struct {
+	needShare         float64 // naps' share of total sleep need
+	compensatedNights int     // short nights followed by naps the next day
+	chronic           bool    // most nights are short and most of those are napped after
+}
+
+// analyzeNapCompensation reads the sleep-debt ledger for nighttime deficits
+// being made up with naps. The ledger credits each night with the naps of the
+// day before it, so the naps after a short night are on the next entry.
+func analyzeNapCompensation(ledger SleepDebtLedger) napCompensation {
+	var result napCompensation
+	var napHours, needHours float64
+	shortNights := 0
+	for i, entry := range ledger.Entries {
+		napHours += entry.NapHours
+		needHours += entry.NeedHours
+		if entry.SleepHours > entry.NeedHours-napDeficitHours {
+			continue
+		}
+		shortNights++
+		if i+1 < len(ledger.Entries) && ledger.Entries[i+1].NapHours > 0 {
+			result.compensatedNights++
+		}
+	}
+	if needHours > 0 {
+		result.needShare = napHours / needHours
+	}
+	result.chronic = result.compensatedNights >= napCompensationMinNights &&
+		float64(shortNights) >= napCompensationShare*float64(len(ledger.Entries)) &&
+		float64(result.compensatedNights) >= napCompensationShare*float64(shortNights)
+	return result
+}
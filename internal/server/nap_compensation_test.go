@@ -0,0 +1,52 @@
+package server
+
+import (
+	"math"
+	"testing"
+)
+
+// napLedger builds ledger entries against an 8-hour need from nightly sleep
+// hours and the nap hours credited before each night
+func napLedger(sleepHours, napHours []float64) SleepDebtLedger {
+	var ledger SleepDebtLedger
+	for i := range sleepHours {
+		ledger.Entries = append(ledger.Entries, SleepDebtEntry{SleepHours: sleepHours[i], NapHours: napHours[i], NeedHours: 8})
+	}
+	return ledger
+}
+
+func TestAnalyzeNapCompensation(t *testing.T) {
+	sleep := []float64{6, 6, 6, 6, 8, 8}
+
+	// Naps the day after three of the four short nights
+	got := analyzeNapCompensation(napLedger(sleep, []float64{0, 1, 1, 1, 0, 0}))
+	if !got.chronic || got.compensatedNights != 3 || math.Abs(got.needShare-3.0/48) > 1e-9 {
+		t.Errorf("analyzeNapCompensation() = %+v, want chronic with 3 nights and 6.25%% of need", got)
+	}
+
+	// Naps only after the full nights are not compensation
+	got = analyzeNapCompensation(napLedger(sleep, []float64{0, 0, 0, 0, 0, 1}))
+	if got.chronic || got.compensatedNights != 0 {
+		t.Errorf("analyzeNapCompensation() = %+v, want no compensation", got)
+	}
+
+	if got := analyzeNapCompensation(SleepDebtLedger{}); got != (napCompensation{}) {
+		t.Errorf("analyzeNapCompensation(empty) = %+v, want zero", got)
+	}
+}
+
+func TestNapCompensationInsight(t *testing.T) {
+	sleep := SleepAnalysis{AverageHours: 7.5, AverageEfficiency: 0.9, ChronicNapCompensation: true, NapNeedShare: 0.2, NapCompensatedNights: 4}
+	insights := NewHealthAnalyzer().generateTherapyInsights(RecoveryTrend{}, sleep, StressIndicators{}, ActivityPatterns{})
+
+	for _, insight := range insights {
+		if insight.Topic != "nap_compensation" {
+			continue
+		}
+		if insight.Severity != "concern" {
+			t.Errorf("Severity = %q, want concern when naps cover 20%% of need", insight.Severity)
+		}
+		return
+	}
+	t.Errorf("no nap_compensation insight in %+v", insights)
+}
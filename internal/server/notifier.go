@@ -0,0 +1,282 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// notificationTimeout bounds each delivery attempt
+	notificationTimeout = 10 * time.Second
+	// notificationCooldown is how long a flag type stays quiet after an alert,
+	// since every analysis of an overlapping period detects it again
+	notificationCooldown = 24 * time.Hour
+	// notificationMaxAge is how recently an analyzed period must end for its
+	// red flags to be sent, so looking back through history raises no alerts
+	notificationMaxAge = 48 * time.Hour
+)
+
+// redFlagSeverityRank orders red flag severities for the notification threshold
+var redFlagSeverityRank = map[string]int{"moderate": 1, "high": 2, "critical": 3}
+
+// RedFlagAlert is one notification: the red flags newly detected for a user
+type RedFlagAlert struct {
+	UserID int       `json:"user_id"`
+	Flags  []RedFlag `json:"red_flags"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+// Title summarizes the alert in one line
+func (a RedFlagAlert) Title() string {
+	if len(a.Flags) == 1 {
+		return "WHOOP red flag: " + redFlagLabel(a.Flags[0].Type)
+	}
+	return fmt.Sprintf("WHOOP: %d red flags", len(a.Flags))
+}
+
+// Body lists each flag with its description and recommendation
+func (a RedFlagAlert) Body() string {
+	var builder strings.Builder
+	for _, flag := range a.Flags {
+		if flag.SafetyNotice != "" {
+			builder.WriteString(flag.SafetyNotice + "\n")
+		}
+		builder.WriteString(fmt.Sprintf("- %s (%s): %s\n", redFlagLabel(flag.Type), flag.Severity, flag.Description))
+		if flag.Recommendation != "" {
+			builder.WriteString("  Recommendation: " + flag.Recommendation + "\n")
+		}
+	}
+	builder.WriteString("\nAcknowledge a known issue with acknowledge_red_flag to stop these alerts.")
+	return builder.String()
+}
+
+// NotificationChannel delivers red flag alerts to one endpoint
+type NotificationChannel interface {
+	// Name identifies the channel in logs
+	Name() string
+	// Send delivers the alert, failing on any non-success response
+	Send(alert RedFlagAlert) error
+}
+
+// WebhookChannel posts the alert as JSON to a URL
+type WebhookChannel struct {
+	URL    string
+	Client *http.Client
+}
+
+func (w *WebhookChannel) Name() string { return "webhook" }
+
+func (w *WebhookChannel) Send(alert RedFlagAlert) error {
+	payload, err := json.Marshal(struct {
+		Title string `json:"title"`
+		Text  string `json:"text"`
+		RedFlagAlert
+	}{alert.Title(), alert.Body(), alert})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+	return postNotification(w.Client, w.URL, payload, map[string]string{"Content-Type": "application/json"})
+}
+
+// NtfyChannel publishes the alert to an ntfy topic URL such as https://ntfy.sh/my-topic
+type NtfyChannel struct {
+	URL    string
+	Token  string // optional access token for protected topics
+	Client *http.Client
+}
+
+func (n *NtfyChannel) Name() string { return "ntfy" }
+
+func (n *NtfyChannel) Send(alert RedFlagAlert) error {
+	priority := "high"
+	for _, flag := range alert.Flags {
+		if flag.Severity == "critical" {
+			priority = "urgent"
+		}
+	}
+	headers := map[string]string{"Title": alert.Title(), "Priority": priority, "Tags": "warning"}
+	if n.Token != "" {
+		headers["Authorization"] = "Bearer " + n.Token
+	}
+	return postNotification(n.Client, n.URL, []byte(alert.Body()), headers)
+}
+
+// postNotification POSTs body to url and treats any non-2xx status as a failure
+func postNotification(client *http.Client, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// EmailChannel sends the alert over SMTP
+type EmailChannel struct {
+	Addr     string // host:port
+	Username string
+	Password string
+	From     string
+	To       string
+}
+
+func (e *EmailChannel) Name() string { return "email" }
+
+func (e *EmailChannel) Send(alert RedFlagAlert) error {
+	var auth smtp.Auth
+	if e.Username != "" {
+		host, _, _ := net.SplitHostPort(e.Addr)
+		auth = smtp.PlainAuth("", e.Username, e.Password, host)
+	}
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		e.From, e.To, alert.Title(), strings.ReplaceAll(alert.Body(), "\n", "\r\n"))
+	return smtp.SendMail(e.Addr, auth, e.From, []string{e.To}, []byte(message))
+}
+
+// Notifier sends red flags at or above a severity to every configured
+// channel, at most once per flag type per notificationCooldown
+type Notifier struct {
+	Channels    []NotificationChannel
+	MinSeverity string
+	Path        string // when each flag type was last sent, per user
+	mu          sync.Mutex
+	// unsaved holds the send times read-only mode can't write to Path
+	unsaved map[string]map[string]time.Time
+	storeMode
+}
+
+// NewNotifierFromEnv configures channels from WHOOP_NOTIFY_WEBHOOK_URL,
+// WHOOP_NOTIFY_NTFY_URL (with optional WHOOP_NOTIFY_NTFY_TOKEN), and
+// WHOOP_NOTIFY_EMAIL_TO (with WHOOP_SMTP_HOST, WHOOP_SMTP_PORT,
+// WHOOP_SMTP_USERNAME, WHOOP_SMTP_PASSWORD, and WHOOP_SMTP_FROM).
+// WHOOP_NOTIFY_SEVERITY (critical, high, or moderate) sets the threshold,
+// defaulting to critical. It returns nil when no channel is configured.
+func NewNotifierFromEnv(settings Settings, cipher *DataCipher) (*Notifier, error) {
+	client := &http.Client{Timeout: notificationTimeout}
+	var channels []NotificationChannel
+	if url := strings.TrimSpace(settings("WHOOP_NOTIFY_WEBHOOK_URL")); url != "" {
+		channels = append(channels, &WebhookChannel{URL: url, Client: client})
+	}
+	if url := strings.TrimSpace(settings("WHOOP_NOTIFY_NTFY_URL")); url != "" {
+		channels = append(channels, &NtfyChannel{URL: url, Token: settings("WHOOP_NOTIFY_NTFY_TOKEN"), Client: client})
+	}
+	if to := strings.TrimSpace(settings("WHOOP_NOTIFY_EMAIL_TO")); to != "" {
+		host := strings.TrimSpace(settings("WHOOP_SMTP_HOST"))
+		if host == "" {
+			return nil, fmt.Errorf("WHOOP_NOTIFY_EMAIL_TO requires WHOOP_SMTP_HOST")
+		}
+		port := strings.TrimSpace(settings("WHOOP_SMTP_PORT"))
+		if port == "" {
+			port = "587"
+		} else if _, err := strconv.Atoi(port); err != nil {
+			return nil, fmt.Errorf("invalid WHOOP_SMTP_PORT %q", port)
+		}
+		username := settings("WHOOP_SMTP_USERNAME")
+		from := strings.TrimSpace(settings("WHOOP_SMTP_FROM"))
+		if from == "" {
+			from = username
+		}
+		if from == "" {
+			return nil, fmt.Errorf("WHOOP_NOTIFY_EMAIL_TO requires WHOOP_SMTP_FROM or WHOOP_SMTP_USERNAME")
+		}
+		channels = append(channels, &EmailChannel{Addr: net.JoinHostPort(host, port), Username: username,
+			Password: settings("WHOOP_SMTP_PASSWORD"), From: from, To: to})
+	}
+	if len(channels) == 0 {
+		return nil, nil
+	}
+
+	severity := strings.ToLower(strings.TrimSpace(settings("WHOOP_NOTIFY_SEVERITY")))
+	if severity == "" {
+		severity = "critical"
+	}
+	if _, ok := redFlagSeverityRank[severity]; !ok {
+		return nil, fmt.Errorf("unknown WHOOP_NOTIFY_SEVERITY %q (expected critical, high, or moderate)", severity)
+	}
+
+	path, err := localDataPath(settings, "WHOOP_NOTIFICATIONS_FILE", "notifications.json")
+	if err != nil {
+		return nil, err
+	}
+	return &Notifier{Channels: channels, MinSeverity: severity, Path: path, storeMode: storeMode{cipher: cipher}}, nil
+}
+
+// Notify sends the flags that meet the severity threshold and have not been
+// sent within the cooldown. A flag counts as sent once any channel delivers
+// it; failures from the other channels are returned together. Read-only mode
+// still sends alerts, keeping their cooldown in memory instead of on disk.
+func (n *Notifier) Notify(userID int, flags []RedFlag, now time.Time) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	sent := make(map[string]map[string]time.Time)
+	if err := readJSONFile(n.cipher, n.Path, &sent); err != nil {
+		return err
+	}
+	key := strconv.Itoa(userID)
+	if sent[key] == nil {
+		sent[key] = make(map[string]time.Time)
+	}
+	for flagType, at := range n.unsaved[key] {
+		if at.After(sent[key][flagType]) {
+			sent[key][flagType] = at
+		}
+	}
+
+	alert := RedFlagAlert{UserID: userID, SentAt: now}
+	for _, flag := range flags {
+		if redFlagSeverityRank[flag.Severity] < redFlagSeverityRank[n.MinSeverity] {
+			continue
+		}
+		if last, ok := sent[key][flag.Type]; ok && now.Sub(last) < notificationCooldown {
+			continue
+		}
+		alert.Flags = append(alert.Flags, flag)
+	}
+	if len(alert.Flags) == 0 {
+		return nil
+	}
+
+	var errs []error
+	delivered := false
+	for _, channel := range n.Channels {
+		if err := channel.Send(alert); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel.Name(), err))
+			continue
+		}
+		delivered = true
+	}
+	if delivered {
+		for _, flag := range alert.Flags {
+			sent[key][flag.Type] = now
+		}
+		if n.writable() != nil {
+			if n.unsaved == nil {
+				n.unsaved = make(map[string]map[string]time.Time)
+			}
+			n.unsaved[key] = sent[key]
+		} else if err := writeJSONFile(n.cipher, n.Path, sent); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingChannel collects alerts, or fails every send when err is set
+type recordingChannel struct {
+	alerts []RedFlagAlert
+	err    error
+}
+
+func (r *recordingChannel) Name() string { return "recording" }
+
+func (r *recordingChannel) Send(alert RedFlagAlert) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func TestNotifier_SeverityAndCooldown(t *testing.T) {
+	now := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+	channel := &recordingChannel{}
+	notifier := &Notifier{Channels: []NotificationChannel{channel}, MinSeverity: "high",
+		Path: filepath.Join(t.TempDir(), "notifications.json")}
+	flags := []RedFlag{
+		{Type: "severe_sleep_deprivation", Severity: "critical", Description: "Average sleep is critically low"},
+		{Type: "extended_poor_recovery", Severity: "high", Description: "Recovery has been poor for 8 days"},
+		{Type: "spo2_drop", Severity: "moderate", Description: "Blood oxygen dropped"},
+	}
+
+	if err := notifier.Notify(1, flags, now); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if len(channel.alerts) != 1 || len(channel.alerts[0].Flags) != 2 {
+		t.Fatalf("alerts = %+v, want one alert with the critical and high flags", channel.alerts)
+	}
+
+	// The same flags again within the cooldown send nothing
+	notifier.Notify(1, flags, now.Add(time.Hour))
+	if len(channel.alerts) != 1 {
+		t.Errorf("expected no alert within the cooldown, got %d alerts", len(channel.alerts))
+	}
+	// Another user has their own cooldown
+	notifier.Notify(2, flags, now.Add(time.Hour))
+	// After the cooldown the flags are sent again
+	notifier.Notify(1, flags, now.Add(notificationCooldown))
+	if len(channel.alerts) != 3 {
+		t.Errorf("expected alerts for user 2 and after the cooldown, got %d alerts", len(channel.alerts))
+	}
+}
+
+func TestNotifier_ReadOnly(t *testing.T) {
+	now := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+	channel := &recordingChannel{}
+	notifier := &Notifier{Channels: []NotificationChannel{channel}, MinSeverity: "critical",
+		Path: filepath.Join(t.TempDir(), "notifications.json")}
+	notifier.SetReadOnly(true)
+	flags := []RedFlag{{Type: "severe_sleep_deprivation", Severity: "critical"}}
+
+	if err := notifier.Notify(1, flags, now); err != nil || len(channel.alerts) != 1 {
+		t.Fatalf("Notify() = %v with %d alerts, want the alert sent in read-only mode", err, len(channel.alerts))
+	}
+	if _, err := os.Stat(notifier.Path); !os.IsNotExist(err) {
+		t.Errorf("read-only notifier wrote %s", notifier.Path)
+	}
+	notifier.Notify(1, flags, now.Add(time.Hour))
+	if len(channel.alerts) != 1 {
+		t.Errorf("expected the in-memory cooldown to hold back the repeat, got %d alerts", len(channel.alerts))
+	}
+}
+
+func TestNotifier_FailedDeliveryIsRetried(t *testing.T) {
+	now := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+	failing := &recordingChannel{err: errors.New("connection refused")}
+	notifier := &Notifier{Channels: []NotificationChannel{failing}, MinSeverity: "critical",
+		Path: filepath.Join(t.TempDir(), "notifications.json")}
+	flags := []RedFlag{{Type: "severe_sleep_deprivation", Severity: "critical"}}
+
+	if err := notifier.Notify(1, flags, now); err == nil || !strings.Contains(err.Error(), "recording: connection refused") {
+		t.Errorf("Notify error = %v, want the channel failure", err)
+	}
+	failing.err = nil
+	notifier.Notify(1, flags, now.Add(time.Minute))
+	if len(failing.alerts) != 1 {
+		t.Errorf("expected the undelivered flag to be sent on the next call, got %d alerts", len(failing.alerts))
+	}
+}
+
+func TestNotificationChannels_HTTP(t *testing.T) {
+	var headers http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	alert := RedFlagAlert{UserID: 1, Flags: []RedFlag{{Type: "spo2_drop", Severity: "critical", Description: "Blood oxygen dropped"}}}
+
+	webhook := &WebhookChannel{URL: server.URL, Client: server.Client()}
+	if err := webhook.Send(alert); err != nil {
+		t.Fatalf("webhook Send failed: %v", err)
+	}
+	var payload struct {
+		Title    string    `json:"title"`
+		RedFlags []RedFlag `json:"red_flags"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Title != "WHOOP red flag: Spo2 Drop" || len(payload.RedFlags) != 1 {
+		t.Errorf("webhook payload = %s (%v)", body, err)
+	}
+
+	ntfy := &NtfyChannel{URL: server.URL, Token: "tk_secret", Client: server.Client()}
+	if err := ntfy.Send(alert); err != nil {
+		t.Fatalf("ntfy Send failed: %v", err)
+	}
+	if headers.Get("Priority") != "urgent" || headers.Get("Authorization") != "Bearer tk_secret" ||
+		!strings.Contains(string(body), "Blood oxygen dropped") {
+		t.Errorf("ntfy request headers = %v, body = %q", headers, body)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer failing.Close()
+	if err := (&WebhookChannel{URL: failing.URL, Client: failing.Client()}).Send(alert); err == nil {
+		t.Error("expected a non-2xx response to fail")
+	}
+}
+
+func TestNewNotifierFromEnv(t *testing.T) {
+	t.Setenv("WHOOP_NOTIFICATIONS_FILE", filepath.Join(t.TempDir(), "notifications.json"))
+	if notifier, err := NewNotifierFromEnv(os.Getenv, nil); notifier != nil || err != nil {
+		t.Errorf("expected no notifier without channels, got %+v, %v", notifier, err)
+	}
+
+	t.Setenv("WHOOP_NOTIFY_NTFY_URL", "https://ntfy.sh/whoop-alerts")
+	notifier, err := NewNotifierFromEnv(os.Getenv, nil)
+	if err != nil || len(notifier.Channels) != 1 || notifier.MinSeverity != "critical" {
+		t.Errorf("got %+v, %v, want one ntfy channel at critical", notifier, err)
+	}
+
+	t.Setenv("WHOOP_NOTIFY_SEVERITY", "urgent")
+	if _, err := NewNotifierFromEnv(os.Getenv, nil); err == nil {
+		t.Error("expected an unknown severity to fail")
+	}
+	t.Setenv("WHOOP_NOTIFY_SEVERITY", "")
+	t.Setenv("WHOOP_NOTIFY_EMAIL_TO", "me@example.com")
+	if _, err := NewNotifierFromEnv(os.Getenv, nil); err == nil {
+		t.Error("expected email without WHOOP_SMTP_HOST to fail")
+	}
+}
@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// callbackTimeout bounds how long the temporary callback server waits for the browser
+const callbackTimeout = 5 * time.Minute
+
+// callbackResult is what the redirect handler captured
+type callbackResult struct {
+	Code  string
+	State string
+	Err   error
+}
+
+// listenOnRedirectURI binds the local address of the redirect URI and returns
+// the listener with the callback path
+func listenOnRedirectURI(redirectURI string) (net.Listener, string, error) {
+	redirect, err := url.Parse(redirectURI)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid redirect URI: %w", err)
+	}
+	if redirect.Scheme != "http" || (redirect.Hostname() != "localhost" && redirect.Hostname() != "127.0.0.1") {
+		return nil, "", fmt.Errorf("redirect URI %s is not a local http address", redirectURI)
+	}
+
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to listen on %s (is another auth flow running?): %w", redirect.Host, err)
+	}
+
+	path := redirect.Path
+	if path == "" {
+		path = "/"
+	}
+	return listener, path, nil
+}
+
+// waitForAuthorizationCode serves the callback path on listener until Whoop
+// redirects the browser back with an authorization code, the context is
+// cancelled, or callbackTimeout elapses. The listener is closed on return.
+func waitForAuthorizationCode(ctx context.Context, listener net.Listener, callbackPath, expectedState string) (string, error) {
+	results := make(chan callbackResult, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(callbackPath, func(rw http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		result := callbackResult{Code: query.Get("code"), State: query.Get("state")}
+
+		switch {
+		case query.Get("error") != "":
+			result.Err = fmt.Errorf("authorization denied: %s %s", query.Get("error"), query.Get("error_description"))
+		case result.Code == "":
+			result.Err = errors.New("callback did not include an authorization code")
+		case result.State != expectedState:
+			result.Err = errors.New("callback state did not match this authorization attempt")
+		}
+
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if result.Err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(rw, "<h1>Whoop authorization failed</h1><p>%s</p>", html.EscapeString(result.Err.Error()))
+		} else {
+			fmt.Fprint(rw, "<h1>Whoop authorization complete</h1><p>You can close this window and return to your assistant.</p>")
+		}
+
+		select {
+		case results <- result:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("OAuth callback server error: %v", err)
+		}
+	}()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	timer := time.NewTimer(callbackTimeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-results:
+		if result.Err != nil {
+			return "", result.Err
+		}
+		return result.Code, nil
+	case <-timer.C:
+		return "", fmt.Errorf("timed out after %s waiting for the Whoop authorization callback", callbackTimeout)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// AuthFlowStatus describes a background callback-based auth attempt
+type AuthFlowStatus struct {
+	State       string     `json:"state"` // "waiting", "completed", "failed"
+	AuthURL     string     `json:"auth_url"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	Scopes      string     `json:"scopes,omitempty"`
+}
+
+// authFlow runs the callback capture and token exchange in the background so
+// the setup_whoop_auth tool can return the URL immediately
+type authFlow struct {
+	status AuthFlowStatus
+	cancel context.CancelFunc
+	mu     sync.Mutex
+}
+
+// startAuthFlow launches a callback server and completes the exchange once the
+// browser is redirected back; onTokens installs and persists the result
+func startAuthFlow(clientID, clientSecret string, onTokens func(*OAuthTokenResponse) error) (*authFlow, error) {
+	// Each attempt gets its own random state, validated by the callback handler
+	state, err := generateOAuthState()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	flow := &authFlow{
+		status: AuthFlowStatus{
+			State:     "waiting",
+			AuthURL:   buildAuthURL(clientID, state),
+			StartedAt: time.Now(),
+		},
+		cancel: cancel,
+	}
+
+	// Bind synchronously so port conflicts are reported to the caller
+	listener, callbackPath, err := listenOnRedirectURI(WhoopRedirectURI)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	go func() {
+		defer cancel()
+
+		code, err := waitForAuthorizationCode(ctx, listener, callbackPath, state)
+		if err == nil {
+			var tokens *OAuthTokenResponse
+			tokens, err = exchangeAuthorizationCode(http.DefaultClient, clientID, clientSecret, code)
+			if err == nil {
+				err = onTokens(tokens)
+				flow.mu.Lock()
+				flow.status.Scopes = tokens.Scope
+				flow.mu.Unlock()
+			}
+		}
+
+		flow.mu.Lock()
+		defer flow.mu.Unlock()
+		completedAt := time.Now()
+		flow.status.CompletedAt = &completedAt
+		if err != nil {
+			flow.status.State = "failed"
+			flow.status.Error = err.Error()
+			log.Printf("OAuth callback flow failed: %v", err)
+			return
+		}
+		flow.status.State = "completed"
+		log.Printf("OAuth callback flow completed")
+	}()
+
+	return flow, nil
+}
+
+// Status returns a copy of the flow's current status
+func (f *authFlow) Status() AuthFlowStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.status
+}
+
+// Cancel stops a waiting flow
+func (f *authFlow) Cancel() {
+	f.cancel()
+}
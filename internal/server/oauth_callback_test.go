@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWaitForAuthorizationCode(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	callbackURL := "http://" + listener.Addr().String() + "/callback"
+
+	done := make(chan struct{})
+	var code string
+	var waitErr error
+	go func() {
+		defer close(done)
+		code, waitErr = waitForAuthorizationCode(context.Background(), listener, "/callback", "expected-state")
+	}()
+
+	resp, err := http.Get(callbackURL + "?code=abc123&state=expected-state")
+	if err != nil {
+		t.Fatalf("callback request failed: %v", err)
+	}
+	resp.Body.Close()
+	<-done
+
+	if waitErr != nil {
+		t.Fatalf("waitForAuthorizationCode() error = %v", waitErr)
+	}
+	if code != "abc123" {
+		t.Errorf("Expected code abc123, got %q", code)
+	}
+}
+
+func TestWaitForAuthorizationCode_RejectsWrongState(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := waitForAuthorizationCode(context.Background(), listener, "/callback", "expected-state")
+		done <- err
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/callback?code=abc&state=forged")
+	if err != nil {
+		t.Fatalf("callback request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for mismatched state, got %d", resp.StatusCode)
+	}
+	if err := <-done; err == nil {
+		t.Error("Expected mismatched state to be rejected")
+	}
+}
+
+func TestOAuthStateStore_Consume(t *testing.T) {
+	store := newOAuthStateStore(oauthStateTTL)
+
+	state, err := store.Issue()
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if len(state) < 32 {
+		t.Errorf("Expected a long random state, got %q", state)
+	}
+
+	if err := store.Consume("forged"); err == nil {
+		t.Error("Expected unknown state to be rejected")
+	}
+	if err := store.Consume(state); err != nil {
+		t.Errorf("Consume() error = %v", err)
+	}
+	if err := store.Consume(state); err == nil {
+		t.Error("Expected state to be single-use")
+	}
+}
+
+func TestReadAuthorizationCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"redirect URL", "http://localhost:3000/callback?code=abc&state=s1\n", "abc", false},
+		{"bare code", "  abc  \n", "abc", false},
+		{"no trailing newline", "abc", "abc", false},
+		{"state mismatch", "http://localhost:3000/callback?code=abc&state=other\n", "", true},
+		{"denied", "http://localhost:3000/callback?error=access_denied&state=s1\n", "", true},
+		{"empty", "\n", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readAuthorizationCode(strings.NewReader(tt.input), "s1")
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("readAuthorizationCode(%q) = %q, %v; want %q, error %v", tt.input, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInstallExchangedTokens(t *testing.T) {
+	tokens := &OAuthTokenResponse{AccessToken: "access-0123456789abcdef", RefreshToken: "refresh-0123456789abcdef", ExpiresIn: 3600, Scope: "offline read:recovery"}
+
+	mock := NewMockWhoopAPI()
+	mock.Store = &JSONFileTokenStore{Path: filepath.Join(t.TempDir(), "tokens.json")}
+	server := newMockServer(t, mock)
+	stored := server.installExchangedTokens(tokens, false)
+	if strings.Contains(stored, tokens.AccessToken) || strings.Contains(stored, tokens.RefreshToken) {
+		t.Errorf("Expected stored tokens to be masked:\n%s", stored)
+	}
+
+	// The authorization code is spent, so tokens that can't be stored are
+	// shown in full rather than asking for another exchange
+	mock.InstallErr = errors.New("keyring locked")
+	unstored := server.installExchangedTokens(tokens, false)
+	for _, want := range []string{"keyring locked", "WHOOP_ACCESS_TOKEN=" + tokens.AccessToken, "WHOOP_REFRESH_TOKEN=" + tokens.RefreshToken} {
+		if !strings.Contains(unstored, want) {
+			t.Errorf("Expected %q in:\n%s", want, unstored)
+		}
+	}
+	if strings.Contains(unstored, "reveal_tokens") {
+		t.Errorf("Expected no advice to exchange the code again:\n%s", unstored)
+	}
+	if len(mock.InstalledTokens()) != 2 {
+		t.Errorf("installed %d token sets, want both used for the session", len(mock.InstalledTokens()))
+	}
+}
@@ -0,0 +1,308 @@
+package server
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"whoop-mcp/internal/whoop"
+)
+
+// bundledFixtures holds three months of demo data for one athlete, served
+// in offline mode when WHOOP_FIXTURES_DIR is not set
+//
+//go:embed fixtures/*.json
+var bundledFixtures embed.FS
+
+// offlineToken stands in for an access token in offline and cassette replay
+// modes, where no request reaches the Whoop API
+const offlineToken = "offline"
+
+// FixtureSet is the data served in offline mode, one collection per endpoint
+type FixtureSet struct {
+	Source     string // "bundled" or the fixtures directory
+	Profile    WhoopUser
+	Recoveries []WhoopRecovery
+	Sleeps     []WhoopSleep
+	Workouts   []WhoopWorkout
+	Cycles     []WhoopCycle
+}
+
+// offlineFromEnv reports whether WHOOP_OFFLINE turns offline mode on
+func offlineFromEnv(settings Settings) (bool, error) {
+	raw := strings.TrimSpace(settings("WHOOP_OFFLINE"))
+	if raw == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid WHOOP_OFFLINE %q: expected 1, true, 0, or false", raw)
+	}
+	return enabled, nil
+}
+
+// LoadFixturesFromEnv generates the WHOOP_OFFLINE_SCENARIO dataset ending
+// now, loads WHOOP_FIXTURES_DIR as-is, or loads the bundled fixtures shifted
+// by whole days so their newest record falls within the last day
+func LoadFixturesFromEnv(settings Settings, now time.Time) (*FixtureSet, error) {
+	dir := strings.TrimSpace(settings("WHOOP_FIXTURES_DIR"))
+	if scenario := strings.TrimSpace(settings("WHOOP_OFFLINE_SCENARIO")); scenario != "" {
+		if dir != "" {
+			return nil, fmt.Errorf("WHOOP_FIXTURES_DIR and WHOOP_OFFLINE_SCENARIO cannot be used together")
+		}
+		opts, err := SyntheticScenario(scenario, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WHOOP_OFFLINE_SCENARIO: %w", err)
+		}
+		fixtures := syntheticFixtures(GenerateHealthData(opts), now)
+		fixtures.Source = "scenario " + scenario
+		return fixtures, nil
+	}
+
+	if dir != "" {
+		fixtures, err := LoadFixtures(os.DirFS(dir))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load WHOOP_FIXTURES_DIR: %w", err)
+		}
+		fixtures.Source = dir
+		return fixtures, nil
+	}
+
+	sub, err := fs.Sub(bundledFixtures, "fixtures")
+	if err != nil {
+		return nil, err
+	}
+	fixtures, err := LoadFixtures(sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bundled fixtures: %w", err)
+	}
+	fixtures.Source = "bundled"
+	if newest := fixtures.newest(); !newest.IsZero() && now.After(newest) {
+		fixtures.Shift(int(now.Sub(newest) / (24 * time.Hour)))
+	}
+	return fixtures, nil
+}
+
+// LoadFixtures reads profile.json, recovery.json, sleep.json, workout.json,
+// and cycle.json from fsys. Collections may be a JSON array of records or a
+// saved API page ({"records": [...]}); a missing file serves no records.
+func LoadFixtures(fsys fs.FS) (*FixtureSet, error) {
+	fixtures := &FixtureSet{}
+	if err := readFixture(fsys, "profile.json", &fixtures.Profile); err != nil {
+		return nil, err
+	}
+	collections := []struct {
+		name string
+		into any
+	}{
+		{"recovery.json", &fixtures.Recoveries},
+		{"sleep.json", &fixtures.Sleeps},
+		{"workout.json", &fixtures.Workouts},
+		{"cycle.json", &fixtures.Cycles},
+	}
+	for _, collection := range collections {
+		if err := readFixture(fsys, collection.name, collection.into); err != nil {
+			return nil, err
+		}
+	}
+
+	// Pages are served newest first, as the API does
+	sort.SliceStable(fixtures.Recoveries, func(i, j int) bool {
+		return fixtures.Recoveries[i].CreatedAt.After(fixtures.Recoveries[j].CreatedAt)
+	})
+	sort.SliceStable(fixtures.Sleeps, func(i, j int) bool { return fixtures.Sleeps[i].Start.After(fixtures.Sleeps[j].Start) })
+	sort.SliceStable(fixtures.Workouts, func(i, j int) bool { return fixtures.Workouts[i].Start.After(fixtures.Workouts[j].Start) })
+	sort.SliceStable(fixtures.Cycles, func(i, j int) bool { return fixtures.Cycles[i].Start.After(fixtures.Cycles[j].Start) })
+	return fixtures, nil
+}
+
+// syntheticFixtures serves generated data for a demo athlete, leaving out
+// records that would start after now
+func syntheticFixtures(data HealthData, now time.Time) *FixtureSet {
+	return &FixtureSet{
+		Profile:    WhoopUser{UserID: 1, Email: "demo@example.com", FirstName: "Demo", LastName: "Athlete"},
+		Recoveries: recordsBetween(data.Recoveries, func(r WhoopRecovery) time.Time { return r.CreatedAt }, time.Time{}, now),
+		Sleeps:     recordsBetween(data.Sleeps, func(s WhoopSleep) time.Time { return s.Start }, time.Time{}, now),
+		Workouts:   recordsBetween(data.Workouts, func(w WhoopWorkout) time.Time { return w.Start }, time.Time{}, now),
+		Cycles:     recordsBetween(data.Cycles, func(c WhoopCycle) time.Time { return c.Start }, time.Time{}, now),
+	}
+}
+
+// readFixture decodes one fixture file, unwrapping a saved API page
+func readFixture(fsys fs.FS, name string, into any) error {
+	data, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read fixture %s: %w", name, err)
+	}
+
+	var page struct {
+		Records json.RawMessage `json:"records"`
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' && name != "profile.json" {
+		if err := json.Unmarshal(trimmed, &page); err == nil && page.Records != nil {
+			data = page.Records
+		}
+	}
+	if err := whoop.Decode(data, into, "fixture "+name, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+// newest returns the latest timestamp in the set
+func (f *FixtureSet) newest() time.Time {
+	var latest time.Time
+	later := func(t time.Time) {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	if len(f.Recoveries) > 0 {
+		later(f.Recoveries[0].CreatedAt)
+	}
+	if len(f.Sleeps) > 0 {
+		later(f.Sleeps[0].End)
+	}
+	if len(f.Workouts) > 0 {
+		later(f.Workouts[0].End)
+	}
+	if len(f.Cycles) > 0 {
+		later(f.Cycles[0].Start)
+	}
+	return latest
+}
+
+// Shift moves every timestamp forward by days, keeping times of day intact
+func (f *FixtureSet) Shift(days int) {
+	shift := func(times ...*time.Time) {
+		for _, t := range times {
+			// An open cycle has no end
+			if !t.IsZero() {
+				*t = t.AddDate(0, 0, days)
+			}
+		}
+	}
+	for i := range f.Recoveries {
+		r := &f.Recoveries[i]
+		shift(&r.CreatedAt, &r.UpdatedAt)
+	}
+	for i := range f.Sleeps {
+		s := &f.Sleeps[i]
+		shift(&s.CreatedAt, &s.UpdatedAt, &s.Start, &s.End)
+	}
+	for i := range f.Workouts {
+		w := &f.Workouts[i]
+		shift(&w.CreatedAt, &w.UpdatedAt, &w.Start, &w.End)
+	}
+	for i := range f.Cycles {
+		c := &f.Cycles[i]
+		shift(&c.CreatedAt, &c.UpdatedAt, &c.Start, &c.End)
+	}
+}
+
+// fixtureTransport answers Whoop API requests from a FixtureSet, applying
+// the start, end, limit, and nextToken parameters the way the API does
+type fixtureTransport struct {
+	fixtures *FixtureSet
+	baseURL  string
+}
+
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := strings.TrimPrefix(req.URL.String(), t.baseURL)
+	if i := strings.IndexByte(endpoint, '?'); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+
+	var body any
+	var err error
+	switch endpoint {
+	case "/v2/user/profile/basic":
+		body = t.fixtures.Profile
+	case "/v2/recovery":
+		body, err = fixturePage(req, t.fixtures.Recoveries, func(r WhoopRecovery) time.Time { return r.CreatedAt })
+	case "/v2/activity/sleep":
+		body, err = fixturePage(req, t.fixtures.Sleeps, func(s WhoopSleep) time.Time { return s.Start })
+	case "/v2/activity/workout":
+		body, err = fixturePage(req, t.fixtures.Workouts, func(w WhoopWorkout) time.Time { return w.Start })
+	case "/v2/cycle":
+		body, err = fixturePage(req, t.fixtures.Cycles, func(c WhoopCycle) time.Time { return c.Start })
+	default:
+		return fixtureResponse(req, http.StatusNotFound, map[string]string{"error": "no offline fixture for " + endpoint})
+	}
+	if err != nil {
+		return fixtureResponse(req, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return fixtureResponse(req, http.StatusOK, body)
+}
+
+// fixtureRecords is one page of a collection, shaped like the Whoop*Response types
+type fixtureRecords[T any] struct {
+	Records   []T     `json:"records"`
+	NextToken *string `json:"next_token,omitempty"`
+}
+
+// fixturePage filters records to [start, end) and returns the page selected
+// by limit and nextToken; the token is the offset of the next record
+func fixturePage[T any](req *http.Request, records []T, at func(T) time.Time) (*fixtureRecords[T], error) {
+	query := req.URL.Query()
+	var start, end time.Time
+	var err error
+	if raw := query.Get("start"); raw != "" {
+		if start, err = time.Parse(time.RFC3339, raw); err != nil {
+			return nil, fmt.Errorf("invalid start %q", raw)
+		}
+	}
+	if raw := query.Get("end"); raw != "" {
+		if end, err = time.Parse(time.RFC3339, raw); err != nil {
+			return nil, fmt.Errorf("invalid end %q", raw)
+		}
+	}
+	limit := 10
+	if raw := query.Get("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil || limit < 1 || limit > 25 {
+			return nil, fmt.Errorf("invalid limit %q: expected 1-25", raw)
+		}
+	}
+	offset := 0
+	if raw := query.Get("nextToken"); raw != "" {
+		if offset, err = strconv.Atoi(raw); err != nil || offset < 0 {
+			return nil, fmt.Errorf("invalid nextToken %q", raw)
+		}
+	}
+
+	matched := recordsBetween(records, at, start, end)
+
+	page := &fixtureRecords[T]{Records: []T{}}
+	if offset < len(matched) {
+		page.Records = matched[offset:min(offset+limit, len(matched))]
+	}
+	if next := offset + limit; next < len(matched) {
+		token := strconv.Itoa(next)
+		page.NextToken = &token
+	}
+	return page, nil
+}
+
+// fixtureResponse builds a JSON response with the headers the client reads
+func fixtureResponse(req *http.Request, status int, body any) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode fixture: %w", err)
+	}
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	return newHTTPResponse(req, status, header, data), nil
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadFixturesFromEnv_Bundled(t *testing.T) {
+	t.Setenv("WHOOP_FIXTURES_DIR", "")
+	now := time.Date(2026, 5, 10, 9, 30, 0, 0, time.UTC)
+
+	fixtures, err := LoadFixturesFromEnv(os.Getenv, now)
+	if err != nil {
+		t.Fatalf("LoadFixturesFromEnv() error = %v", err)
+	}
+	if fixtures.Source != "bundled" || fixtures.Profile.UserID == 0 {
+		t.Fatalf("Expected the bundled profile, got %+v from %s", fixtures.Profile, fixtures.Source)
+	}
+	if len(fixtures.Cycles) < 60 || len(fixtures.Recoveries) < 60 || len(fixtures.Sleeps) < 60 || len(fixtures.Workouts) == 0 {
+		t.Fatalf("Expected months of bundled data, got %d cycles, %d recoveries, %d sleeps, %d workouts",
+			len(fixtures.Cycles), len(fixtures.Recoveries), len(fixtures.Sleeps), len(fixtures.Workouts))
+	}
+
+	newest := fixtures.newest()
+	if newest.After(now) || now.Sub(newest) >= 24*time.Hour {
+		t.Errorf("Expected the newest record within the last day of %s, got %s", now, newest)
+	}
+	if !fixtures.Cycles[0].End.IsZero() {
+		t.Errorf("Expected the open cycle to keep an empty end, got %s", fixtures.Cycles[0].End)
+	}
+}
+
+func TestOfflineClient_ServesFixtures(t *testing.T) {
+	t.Setenv("WHOOP_OFFLINE", "1")
+	t.Setenv("WHOOP_FIXTURES_DIR", "")
+	t.Setenv("WHOOP_ACCESS_TOKEN", "")
+	t.Setenv("WHOOP_API_KEY", "")
+	t.Setenv("WHOOP_DATA_DIR", t.TempDir())
+
+	client, err := NewWhoopClient(os.Getenv)
+	if err != nil {
+		t.Fatalf("NewWhoopClient() error = %v", err)
+	}
+
+	user, err := client.GetUser(context.Background())
+	if err != nil || user.FirstName != "Demo" {
+		t.Fatalf("GetUser() = %+v, %v; want the demo profile", user, err)
+	}
+
+	// Thirty days spans two pages of 25
+	end := time.Now()
+	cycles, err := client.GetCycleData(context.Background(), end.AddDate(0, 0, -30), end, nil)
+	if err != nil {
+		t.Fatalf("GetCycleData() error = %v", err)
+	}
+	if len(cycles) < 29 || len(cycles) > 31 {
+		t.Errorf("Expected about 30 cycles in 30 days, got %d", len(cycles))
+	}
+	seen := make(map[int64]bool)
+	for _, cycle := range cycles {
+		if seen[cycle.ID] {
+			t.Errorf("Cycle %d was served twice", cycle.ID)
+		}
+		seen[cycle.ID] = true
+	}
+}
+
+func TestOfflineClient_FixturesDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"profile.json": `{"user_id": 7, "email": "a@example.com", "first_name": "Ada", "last_name": "L"}`,
+		"recovery.json": `{"records": [
+			{"cycle_id": 1, "user_id": 7, "created_at": "2024-03-01T07:00:00Z", "score_state": "SCORED", "score": {"recovery_score": 40}},
+			{"cycle_id": 2, "user_id": 7, "created_at": "2024-03-02T07:00:00Z", "score_state": "SCORED", "score": {"recovery_score": 80}}
+		], "next_token": null}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	t.Setenv("WHOOP_OFFLINE", "true")
+	t.Setenv("WHOOP_FIXTURES_DIR", dir)
+	t.Setenv("WHOOP_DATA_DIR", t.TempDir())
+
+	client, err := NewWhoopClient(os.Getenv)
+	if err != nil {
+		t.Fatalf("NewWhoopClient() error = %v", err)
+	}
+
+	// User fixtures are served as recorded, not shifted
+	recoveries, err := client.GetRecoveryData(context.Background(), time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC), nil)
+	if err != nil {
+		t.Fatalf("GetRecoveryData() error = %v", err)
+	}
+	if len(recoveries) != 1 || recoveries[0].CycleID != 2 {
+		t.Errorf("Expected only the March 2 recovery, got %+v", recoveries)
+	}
+
+	// A missing collection file serves no records
+	sleeps, err := client.GetSleepData(context.Background(), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), nil)
+	if err != nil || len(sleeps) != 0 {
+		t.Errorf("GetSleepData() = %d sleeps, %v; want none", len(sleeps), err)
+	}
+}
+
+func TestOfflineFromEnv_Invalid(t *testing.T) {
+	t.Setenv("WHOOP_OFFLINE", "sometimes")
+	if _, err := offlineFromEnv(os.Getenv); err == nil {
+		t.Error("Expected WHOOP_OFFLINE=sometimes to be rejected")
+	}
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+// significanceLevel is the p-value threshold for calling a delta significant
+const significanceLevel = 0.05
+
+// MetricComparison is the before/after result for one metric
+type MetricComparison struct {
+	Metric        string  `json:"metric"`
+	Label         string  `json:"label"`
+	Unit          string  `json:"unit"`
+	MeanA         float64 `json:"mean_a"`
+	MeanB         float64 `json:"mean_b"`
+	Delta         float64 `json:"delta"`
+	PercentChange float64 `json:"percent_change"`
+	CountA        int     `json:"count_a"`
+	CountB        int     `json:"count_b"`
+	TStatistic    float64 `json:"t_statistic"`
+	PValue        float64 `json:"p_value"`
+	Significant   bool    `json:"significant"`
+	Direction     string  `json:"direction"` // "better", "worse", "unchanged", "insufficient_data"
+}
+
+// PeriodComparison contrasts two date ranges metric by metric
+type PeriodComparison struct {
+	LabelA  string             `json:"label_a"`
+	LabelB  string             `json:"label_b"`
+	PeriodA DateRange          `json:"period_a"`
+	PeriodB DateRange          `json:"period_b"`
+	Metrics []MetricComparison `json:"metrics"`
+}
+
+// ComparePeriods computes per-metric deltas between two periods, using
+// Welch's t-test to judge whether each delta is distinguishable from noise
+func (h *HealthAnalyzer) ComparePeriods(periodA, periodB DateRange, dataA, dataB *HealthData, labelA, labelB string) *PeriodComparison {
+	comparison := &PeriodComparison{
+		LabelA:  labelA,
+		LabelB:  labelB,
+		PeriodA: periodA,
+		PeriodB: periodB,
+	}
+
+	for _, metric := range dailyMetrics {
+		valuesA := valuesOf(metric.extract(dataA))
+		valuesB := valuesOf(metric.extract(dataB))
+
+		result := MetricComparison{
+			Metric: metric.Key,
+			Label:  metric.Label,
+			Unit:   metric.Unit,
+			CountA: len(valuesA),
+			CountB: len(valuesB),
+			PValue: 1,
+		}
+
+		if len(valuesA) == 0 || len(valuesB) == 0 {
+			result.Direction = "insufficient_data"
+			comparison.Metrics = append(comparison.Metrics, result)
+			continue
+		}
+
+		result.MeanA = h.calculateMean(valuesA)
+		result.MeanB = h.calculateMean(valuesB)
+		result.Delta = result.MeanB - result.MeanA
+		if result.MeanA != 0 {
+			result.PercentChange = result.Delta / math.Abs(result.MeanA) * 100
+		}
+
+		result.TStatistic, _, result.PValue = stats.WelchTTest(valuesA, valuesB)
+		result.Significant = result.PValue < significanceLevel
+
+		switch {
+		case len(valuesA) < 2 || len(valuesB) < 2:
+			result.Direction = "insufficient_data"
+		case !result.Significant:
+			result.Direction = "unchanged"
+		case (result.Delta > 0) == metric.HigherIsBetter:
+			result.Direction = "better"
+		default:
+			result.Direction = "worse"
+		}
+
+		comparison.Metrics = append(comparison.Metrics, result)
+	}
+
+	return comparison
+}
+
+// FormatPeriodComparison renders a side-by-side comparison table for therapy
+// and coaching conversations
+func (h *HealthAnalyzer) FormatPeriodComparison(comparison *PeriodComparison) string {
+	var builder strings.Builder
+
+	builder.WriteString("# Period Comparison\n\n")
+	builder.WriteString(fmt.Sprintf("**%s:** %s to %s\n", comparison.LabelA,
+		comparison.PeriodA.Start.Format("2006-01-02"), comparison.PeriodA.End.Format("2006-01-02")))
+	builder.WriteString(fmt.Sprintf("**%s:** %s to %s\n\n", comparison.LabelB,
+		comparison.PeriodB.Start.Format("2006-01-02"), comparison.PeriodB.End.Format("2006-01-02")))
+
+	builder.WriteString(fmt.Sprintf("| Metric | %s | %s | Change | p-value | |\n", comparison.LabelA, comparison.LabelB))
+	builder.WriteString("|---|---|---|---|---|---|\n")
+
+	var better, worse []string
+	for _, metric := range comparison.Metrics {
+		if metric.CountA == 0 || metric.CountB == 0 {
+			builder.WriteString(fmt.Sprintf("| %s | %s | %s | - | - | no data |\n", metric.Label,
+				formatMetricMean(metric.MeanA, metric.CountA, metric.Unit), formatMetricMean(metric.MeanB, metric.CountB, metric.Unit)))
+			continue
+		}
+
+		marker := ""
+		switch metric.Direction {
+		case "better":
+			marker = "✅ better"
+			better = append(better, metric.Label)
+		case "worse":
+			marker = "⚠️ worse"
+			worse = append(worse, metric.Label)
+		case "insufficient_data":
+			marker = "too few days"
+		}
+
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s | %+.1f%s (%+.0f%%) | %.3f | %s |\n",
+			metric.Label,
+			formatMetricMean(metric.MeanA, metric.CountA, metric.Unit),
+			formatMetricMean(metric.MeanB, metric.CountB, metric.Unit),
+			metric.Delta, metric.Unit, metric.PercentChange, metric.PValue, marker))
+	}
+
+	builder.WriteString("\n## Summary\n\n")
+	if len(better) == 0 && len(worse) == 0 {
+		builder.WriteString(fmt.Sprintf("No metric changed significantly (p < %.2f) between the two periods. Day-to-day variation is large relative to the differences observed.\n", significanceLevel))
+	} else {
+		if len(better) > 0 {
+			builder.WriteString(fmt.Sprintf("- **Improved:** %s\n", strings.Join(better, ", ")))
+		}
+		if len(worse) > 0 {
+			builder.WriteString(fmt.Sprintf("- **Declined:** %s\n", strings.Join(worse, ", ")))
+		}
+	}
+
+	builder.WriteString(fmt.Sprintf("\n*Significance uses Welch's t-test on daily values (p < %.2f). A significant change shows the periods differ, not what caused the difference.*\n", significanceLevel))
+
+	return builder.String()
+}
+
+// formatMetricMean renders a period mean with its day count
+func formatMetricMean(mean float64, count int, unit string) string {
+	if count == 0 {
+		return "no data"
+	}
+	return fmt.Sprintf("%.1f%s (n=%d)", mean, unit, count)
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_ComparePeriods(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	recoveriesWithScores := func(offset int, scores []float64) []WhoopRecovery {
+		var recoveries []WhoopRecovery
+		for i, score := range scores {
+			var r WhoopRecovery
+			r.ScoreState = "SCORED"
+			r.CreatedAt = start.AddDate(0, 0, offset+i)
+			r.Score.RecoveryScore = score
+			r.Score.HRVRmssd = score
+			r.Score.RestingHeartRate = 55
+			recoveries = append(recoveries, r)
+		}
+		return recoveries
+	}
+
+	dataA := &HealthData{Recoveries: recoveriesWithScores(0, []float64{40, 42, 38, 41, 39, 43, 40})}
+	dataB := &HealthData{Recoveries: recoveriesWithScores(14, []float64{70, 68, 72, 69, 71, 73, 70})}
+
+	comparison := analyzer.ComparePeriods(DateRange{}, DateRange{}, dataA, dataB, "Before", "After")
+
+	byKey := make(map[string]MetricComparison)
+	for _, metric := range comparison.Metrics {
+		byKey[metric.Metric] = metric
+	}
+
+	recovery := byKey["recovery"]
+	if !recovery.Significant || recovery.Direction != "better" {
+		t.Errorf("Expected significant recovery improvement, got %+v", recovery)
+	}
+	if rhr := byKey["resting_hr"]; rhr.Direction != "unchanged" {
+		t.Errorf("Expected unchanged resting HR, got %s", rhr.Direction)
+	}
+	if sleep := byKey["sleep_hours"]; sleep.Direction != "insufficient_data" {
+		t.Errorf("Expected insufficient sleep data, got %s", sleep.Direction)
+	}
+
+	output := analyzer.FormatPeriodComparison(comparison)
+	if !strings.Contains(output, "**Improved:** Recovery") {
+		t.Errorf("Expected improved recovery in summary, got:\n%s", output)
+	}
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Sexes the population norm tables are banded by
+const (
+	sexMale   = "male"
+	sexFemale = "female"
+)
+
+// normPercentiles are the population percentiles each norm band lists
+var normPercentiles = [5]float64{10, 25, 50, 75, 90}
+
+// normBand holds one age band's percentile points for a metric
+type normBand struct {
+	MinAge int
+	MaxAge int        // 0 for the open-ended oldest band
+	Points [5]float64 // values at normPercentiles
+}
+
+// populationNorms are approximate normative tables by metric and sex, rounded
+// from published reference ranges for sleep-time RMSSD, sleeping heart rate,
+// and wearable-measured sleep duration. They give coarse context only; the
+// personal baseline remains the reference for interpreting day-to-day changes.
+var populationNorms = map[string]map[string][]normBand{
+	"hrv": {
+		sexMale: {
+			{18, 29, [5]float64{35, 48, 65, 85, 110}},
+			{30, 39, [5]float64{28, 38, 52, 70, 90}},
+			{40, 49, [5]float64{22, 30, 42, 56, 72}},
+			{50, 59, [5]float64{18, 25, 34, 46, 60}},
+			{60, 0, [5]float64{15, 21, 29, 39, 52}},
+		},
+		sexFemale: {
+			{18, 29, [5]float64{33, 45, 62, 82, 105}},
+			{30, 39, [5]float64{27, 36, 50, 67, 86}},
+			{40, 49, [5]float64{21, 28, 39, 52, 68}},
+			{50, 59, [5]float64{17, 23, 32, 43, 56}},
+			{60, 0, [5]float64{14, 20, 27, 37, 49}},
+		},
+	},
+	"resting_hr": {
+		sexMale: {
+			{18, 29, [5]float64{46, 51, 57, 63, 69}},
+			{30, 39, [5]float64{47, 52, 58, 64, 70}},
+			{40, 49, [5]float64{48, 53, 59, 65, 71}},
+			{50, 59, [5]float64{48, 53, 59, 65, 71}},
+			{60, 0, [5]float64{47, 52, 58, 64, 70}},
+		},
+		sexFemale: {
+			{18, 29, [5]float64{50, 55, 61, 67, 73}},
+			{30, 39, [5]float64{51, 56, 62, 68, 74}},
+			{40, 49, [5]float64{52, 57, 63, 69, 75}},
+			{50, 59, [5]float64{52, 57, 62, 68, 74}},
+			{60, 0, [5]float64{51, 56, 61, 67, 73}},
+		},
+	},
+	"sleep_hours": {
+		sexMale: {
+			{18, 29, [5]float64{5.6, 6.3, 7.0, 7.6, 8.2}},
+			{30, 39, [5]float64{5.5, 6.2, 6.8, 7.4, 8.0}},
+			{40, 49, [5]float64{5.4, 6.1, 6.7, 7.3, 7.9}},
+			{50, 59, [5]float64{5.4, 6.1, 6.7, 7.3, 7.9}},
+			{60, 0, [5]float64{5.5, 6.2, 6.9, 7.5, 8.1}},
+		},
+		sexFemale: {
+			{18, 29, [5]float64{5.8, 6.5, 7.2, 7.8, 8.4}},
+			{30, 39, [5]float64{5.7, 6.4, 7.0, 7.6, 8.2}},
+			{40, 49, [5]float64{5.6, 6.3, 6.9, 7.5, 8.1}},
+			{50, 59, [5]float64{5.5, 6.2, 6.9, 7.5, 8.1}},
+			{60, 0, [5]float64{5.6, 6.3, 7.0, 7.6, 8.2}},
+		},
+	},
+}
+
+// populationMetrics are the dailyMetrics placed against population norms, in report order
+var populationMetrics = []string{"hrv", "resting_hr", "sleep_hours"}
+
+// PopulationNormsConfig enables percentile context against population norms
+// for the configured age and sex
+type PopulationNormsConfig struct {
+	Age int    `json:"age"`
+	Sex string `json:"sex"`
+}
+
+// LoadPopulationNormsConfigFromEnv reads WHOOP_POPULATION_NORMS (off, on),
+// WHOOP_AGE, and WHOOP_SEX (male, female). It returns nil when population
+// norms are off, the default, so reports stay self-referenced.
+func LoadPopulationNormsConfigFromEnv(settings Settings) (*PopulationNormsConfig, error) {
+	switch toggle := strings.ToLower(strings.TrimSpace(settings("WHOOP_POPULATION_NORMS"))); toggle {
+	case "", "off":
+		return nil, nil
+	case "on":
+	default:
+		return nil, fmt.Errorf("unknown WHOOP_POPULATION_NORMS %q (expected off or on)", toggle)
+	}
+
+	age := strings.TrimSpace(settings("WHOOP_AGE"))
+	years, err := strconv.Atoi(age)
+	if err != nil || years < 18 || years > 100 {
+		return nil, fmt.Errorf("WHOOP_POPULATION_NORMS=on requires WHOOP_AGE between 18 and 100, got %q", age)
+	}
+	sex := strings.ToLower(strings.TrimSpace(settings("WHOOP_SEX")))
+	if sex != sexMale && sex != sexFemale {
+		return nil, fmt.Errorf("WHOOP_POPULATION_NORMS=on requires WHOOP_SEX of male or female, got %q", sex)
+	}
+	return &PopulationNormsConfig{Age: years, Sex: sex}, nil
+}
+
+// band returns the norm band for the configured age and sex
+func (c *PopulationNormsConfig) band(metric string) (normBand, bool) {
+	for _, band := range populationNorms[metric][c.Sex] {
+		if c.Age >= band.MinAge && (band.MaxAge == 0 || c.Age <= band.MaxAge) {
+			return band, true
+		}
+	}
+	return normBand{}, false
+}
+
+// ages renders the band's age range, e.g. "30-39" or "60+"
+func (b normBand) ages() string {
+	if b.MaxAge == 0 {
+		return fmt.Sprintf("%d+", b.MinAge)
+	}
+	return fmt.Sprintf("%d-%d", b.MinAge, b.MaxAge)
+}
+
+// percentile interpolates where value falls in the band, clamped to the
+// outermost listed percentiles
+func (b normBand) percentile(value float64) float64 {
+	if value <= b.Points[0] {
+		return normPercentiles[0]
+	}
+	for i := 1; i < len(b.Points); i++ {
+		if value <= b.Points[i] {
+			fraction := (value - b.Points[i-1]) / (b.Points[i] - b.Points[i-1])
+			return normPercentiles[i-1] + fraction*(normPercentiles[i]-normPercentiles[i-1])
+		}
+	}
+	return normPercentiles[len(normPercentiles)-1]
+}
+
+// PopulationPercentile places one metric's average against the reference group
+type PopulationPercentile struct {
+	Metric     string  `json:"metric"`
+	Value      float64 `json:"value"`
+	Median     float64 `json:"population_median"`
+	Percentile float64 `json:"percentile"` // 10 and 90 also stand for beyond either end
+}
+
+// PopulationContext compares a period's averages with population norms
+type PopulationContext struct {
+	Sex     string                 `json:"sex"`
+	AgeBand string                 `json:"age_band"` // e.g. "30-39"
+	Metrics []PopulationPercentile `json:"metrics"`
+}
+
+// populationContext places the period's average HRV, resting HR, and sleep
+// duration against population norms; nil when norms are off or there is no data
+func (h *HealthAnalyzer) populationContext(data *HealthData) *PopulationContext {
+	if h.norms == nil {
+		return nil
+	}
+	context := &PopulationContext{Sex: h.norms.Sex}
+	for _, key := range populationMetrics {
+		band, ok := h.norms.band(key)
+		metric, _ := lookupMetric(key)
+		values := valuesOf(metric.extract(data))
+		if !ok || len(values) == 0 {
+			continue
+		}
+		mean := h.calculateMean(values)
+		context.AgeBand = band.ages()
+		context.Metrics = append(context.Metrics, PopulationPercentile{
+			Metric: key, Value: mean, Median: band.Points[2], Percentile: band.percentile(mean),
+		})
+	}
+	if len(context.Metrics) == 0 {
+		return nil
+	}
+	return context
+}
+
+// formatPercentile renders a percentile, marking the clamped ends
+func formatPercentile(percentile float64) string {
+	switch {
+	case percentile <= normPercentiles[0]:
+		return fmt.Sprintf("≤%.0f", normPercentiles[0])
+	case percentile >= normPercentiles[len(normPercentiles)-1]:
+		return fmt.Sprintf("≥%.0f", normPercentiles[len(normPercentiles)-1])
+	}
+	return fmt.Sprintf("%.0f", percentile)
+}
+
+// formatPopulationContext renders a "Population Context" section, or nothing
+// when population norms are off
+func formatPopulationContext(l *Localizer, context *PopulationContext) string {
+	if context == nil {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString(l.T("## Population Context") + "\n")
+	if context.Sex == sexFemale {
+		builder.WriteString(l.T("Compared with approximate published norms for women aged %s:", context.AgeBand) + "\n\n")
+	} else {
+		builder.WriteString(l.T("Compared with approximate published norms for men aged %s:", context.AgeBand) + "\n\n")
+	}
+	builder.WriteString(l.T("| Metric | You | Population Median | Percentile |") + "\n|---|---|---|---|\n")
+	for _, entry := range context.Metrics {
+		metric, _ := lookupMetric(entry.Metric)
+		builder.WriteString(fmt.Sprintf("| %s | %.1f %s | %.1f %s | %s |\n", l.T(metric.Label),
+			entry.Value, metric.Unit, entry.Median, metric.Unit, formatPercentile(entry.Percentile)))
+	}
+	builder.WriteString("\n" + l.T("_A lower resting HR percentile means a lower heart rate than most peers. Population norms are coarse context; changes against your own baseline matter more._") + "\n\n")
+	return builder.String()
+}
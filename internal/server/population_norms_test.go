@@ -0,0 +1,85 @@
+package server
+
+import (
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadPopulationNormsConfigFromEnv(t *testing.T) {
+	t.Run("off by default", func(t *testing.T) {
+		t.Setenv("WHOOP_POPULATION_NORMS", "")
+		t.Setenv("WHOOP_AGE", "35")
+		config, err := LoadPopulationNormsConfigFromEnv(os.Getenv)
+		if err != nil || config != nil {
+			t.Errorf("Expected nil config, got %+v, %v", config, err)
+		}
+	})
+
+	t.Run("on with age and sex", func(t *testing.T) {
+		t.Setenv("WHOOP_POPULATION_NORMS", "on")
+		t.Setenv("WHOOP_AGE", "35")
+		t.Setenv("WHOOP_SEX", "Female")
+		config, err := LoadPopulationNormsConfigFromEnv(os.Getenv)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.Age != 35 || config.Sex != sexFemale {
+			t.Errorf("Unexpected config: %+v", config)
+		}
+	})
+
+	t.Run("on needs an age", func(t *testing.T) {
+		t.Setenv("WHOOP_POPULATION_NORMS", "on")
+		t.Setenv("WHOOP_AGE", "")
+		t.Setenv("WHOOP_SEX", "male")
+		if _, err := LoadPopulationNormsConfigFromEnv(os.Getenv); err == nil {
+			t.Error("Expected an error without WHOOP_AGE")
+		}
+	})
+}
+
+func TestNormBandPercentile(t *testing.T) {
+	band, ok := (&PopulationNormsConfig{Age: 34, Sex: sexMale}).band("hrv")
+	if !ok || band.ages() != "30-39" {
+		t.Fatalf("band() = %+v, %v, want the 30-39 band", band, ok)
+	}
+	cases := map[float64]float64{20: 10, 38: 25, 45: 37.5, 52: 50, 200: 90}
+	for value, want := range cases {
+		if got := band.percentile(value); math.Abs(got-want) > 1e-9 {
+			t.Errorf("percentile(%.0f) = %.2f, want %.2f", value, got, want)
+		}
+	}
+
+	if band, _ := (&PopulationNormsConfig{Age: 72, Sex: sexFemale}).band("resting_hr"); band.ages() != "60+" {
+		t.Errorf("band(72) = %s, want 60+", band.ages())
+	}
+}
+
+func TestPopulationContext(t *testing.T) {
+	day := time.Date(2024, 6, 1, 7, 0, 0, 0, time.UTC)
+	recovery := WhoopRecovery{CreatedAt: day, ScoreState: "SCORED"}
+	recovery.Score.HRVRmssd = 70
+	recovery.Score.RestingHeartRate = 52
+	data := &HealthData{Recoveries: []WhoopRecovery{recovery}}
+
+	if context := NewHealthAnalyzer().populationContext(data); context != nil {
+		t.Errorf("populationContext() = %+v, want nil with norms off", context)
+	}
+
+	analyzer := NewHealthAnalyzer()
+	analyzer.norms = &PopulationNormsConfig{Age: 34, Sex: sexMale}
+	context := analyzer.populationContext(data)
+	if context == nil || len(context.Metrics) != 2 || context.Metrics[0].Percentile != 75 || context.Metrics[1].Percentile != 25 {
+		t.Fatalf("populationContext() = %+v, want HRV at the 75th and resting HR at the 25th percentile", context)
+	}
+
+	section := formatPopulationContext(nil, context)
+	for _, want := range []string{"norms for men aged 30-39", "| HRV (RMSSD) | 70.0 ms | 52.0 ms | 75 |"} {
+		if !strings.Contains(section, want) {
+			t.Errorf("section missing %q:\n%s", want, section)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	redactedEmail = "[email redacted]"
+	redactedName  = "[name redacted]"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// userIDField matches a JSON user_id field, which may hold any account's ID
+	userIDField = regexp.MustCompile(`("user_id"\s*:\s*)(\d+)`)
+)
+
+// Redactor masks personal details in everything sent to the client: email
+// addresses and the account holder's name are replaced, and Whoop user IDs
+// become small per-session pseudonyms that tools accept back as user_id.
+// Analysis runs on the real data; only the output text changes.
+type Redactor struct {
+	names      []*regexp.Regexp // longest first, so a full name is masked before its parts
+	pseudonyms map[int]int      // real user ID to pseudonym
+	real       map[int]int      // pseudonym to real user ID
+	idPattern  *regexp.Regexp   // known real IDs as whole numbers; nil until one is known
+	profiled   bool
+	mu         sync.Mutex
+}
+
+// NewRedactorFromEnv returns a Redactor when WHOOP_REDACT_PII is on, and nil
+// when it is unset or off
+func NewRedactorFromEnv(settings Settings) (*Redactor, error) {
+	switch toggle := strings.ToLower(strings.TrimSpace(settings("WHOOP_REDACT_PII"))); toggle {
+	case "", "off":
+		return nil, nil
+	case "on":
+		return &Redactor{pseudonyms: make(map[int]int), real: make(map[int]int)}, nil
+	default:
+		return nil, fmt.Errorf("unknown WHOOP_REDACT_PII %q (expected off or on)", toggle)
+	}
+}
+
+// NeedsProfile reports whether the account holder's name is still unknown
+func (r *Redactor) NeedsProfile() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.profiled
+}
+
+// LearnUser records the account holder's name and ID so later output masks them
+func (r *Redactor) LearnUser(user *WhoopUser) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.profiled {
+		return
+	}
+	r.profiled = true
+	first, last := strings.TrimSpace(user.FirstName), strings.TrimSpace(user.LastName)
+	for _, name := range []string{strings.TrimSpace(first + " " + last), first, last} {
+		// Single letters would mask half the report
+		if len([]rune(name)) >= 2 {
+			// \b only knows ASCII, so spell out the boundaries for names like José
+			r.names = append(r.names, regexp.MustCompile(`(?i)(^|[^\pL\pN])`+regexp.QuoteMeta(name)+`($|[^\pL\pN])`))
+		}
+	}
+	sort.SliceStable(r.names, func(i, j int) bool { return len(r.names[i].String()) > len(r.names[j].String()) })
+	r.pseudonymLocked(user.UserID)
+}
+
+// LearnAccounts assigns pseudonyms to every configured account
+func (r *Redactor) LearnAccounts(accounts []AccountSummary) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, account := range accounts {
+		r.pseudonymLocked(account.UserID)
+	}
+}
+
+// pseudonymLocked returns the pseudonym for a real user ID, assigning the next
+// one on first sight
+func (r *Redactor) pseudonymLocked(userID int) int {
+	if pseudonym, ok := r.pseudonyms[userID]; ok {
+		return pseudonym
+	}
+	pseudonym := len(r.pseudonyms) + 1
+	r.pseudonyms[userID] = pseudonym
+	r.real[pseudonym] = userID
+
+	ids := make([]string, 0, len(r.pseudonyms))
+	for id := range r.pseudonyms {
+		ids = append(ids, strconv.Itoa(id))
+	}
+	sort.Strings(ids)
+	r.idPattern = regexp.MustCompile(`\b(` + strings.Join(ids, "|") + `)\b`)
+	return pseudonym
+}
+
+// Redact masks emails, known names, and user IDs in text
+func (r *Redactor) Redact(text string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	text = emailPattern.ReplaceAllString(text, redactedEmail)
+	for _, name := range r.names {
+		text = name.ReplaceAllString(text, "${1}"+redactedName+"${2}")
+	}
+	// Map known IDs first so a user_id field cannot be pseudonymized twice
+	if r.idPattern != nil {
+		text = r.idPattern.ReplaceAllStringFunc(text, func(id string) string {
+			userID, _ := strconv.Atoi(id)
+			return strconv.Itoa(r.pseudonyms[userID])
+		})
+	}
+	return userIDField.ReplaceAllStringFunc(text, func(field string) string {
+		parts := userIDField.FindStringSubmatch(field)
+		userID, _ := strconv.Atoi(parts[2])
+		if _, isPseudonym := r.real[userID]; isPseudonym {
+			return field
+		}
+		return parts[1] + strconv.Itoa(r.pseudonymLocked(userID))
+	})
+}
+
+// RestoreArguments swaps a pseudonymous user_id in tool arguments back to the
+// real ID; arguments without one are returned unchanged
+func (r *Redactor) RestoreArguments(arguments json.RawMessage) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(arguments, &fields); err != nil {
+		return arguments
+	}
+	var pseudonym int
+	if err := json.Unmarshal(fields["user_id"], &pseudonym); err != nil {
+		return arguments
+	}
+
+	r.mu.Lock()
+	userID, ok := r.real[pseudonym]
+	r.mu.Unlock()
+	if !ok {
+		return arguments
+	}
+	fields["user_id"] = json.RawMessage(strconv.Itoa(userID))
+	restored, err := json.Marshal(fields)
+	if err != nil {
+		return arguments
+	}
+	return restored
+}
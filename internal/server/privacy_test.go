@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRedactor(t *testing.T) {
+	t.Setenv("WHOOP_REDACT_PII", "on")
+	redactor, err := NewRedactorFromEnv(os.Getenv)
+	if err != nil || redactor == nil {
+		t.Fatalf("NewRedactorFromEnv() = %v, %v", redactor, err)
+	}
+	redactor.LearnUser(&WhoopUser{UserID: 10129, Email: "jose.garcia@example.com", FirstName: "José", LastName: "García"})
+	redactor.LearnAccounts([]AccountSummary{{UserID: 10129}, {UserID: 55501, Alias: "client-b"}})
+
+	profile, _ := json.Marshal(WhoopUser{UserID: 10129, Email: "jose.garcia@example.com", FirstName: "José", LastName: "García"})
+	got := redactor.Redact(string(profile))
+	want := `{"user_id":1,"email":"[email redacted]","first_name":"[name redacted]","last_name":"[name redacted]"}`
+	if got != want {
+		t.Errorf("Redact(profile) = %s, want %s", got, want)
+	}
+
+	report := redactor.Redact("Summary for José García (user 55501): recovery 62%, José slept 7.1h. Josémaria is unrelated.")
+	if report != "Summary for [name redacted] (user 2): recovery 62%, [name redacted] slept 7.1h. Josémaria is unrelated." {
+		t.Errorf("Redact(report) = %q", report)
+	}
+
+	// An ID seen only in a record still gets a pseudonym, and keeps it
+	if got := redactor.Redact(`{"cycle_id": 884210, "user_id": 77777}`); got != `{"cycle_id": 884210, "user_id": 3}` {
+		t.Errorf("Redact(record) = %s", got)
+	}
+	if got := redactor.Redact("77777"); got != "3" {
+		t.Errorf("Redact(77777) = %s, want the same pseudonym", got)
+	}
+
+	restored := redactor.RestoreArguments(json.RawMessage(`{"days": 14, "user_id": 2}`))
+	var input HealthSummaryInput
+	if err := json.Unmarshal(restored, &input); err != nil || input.UserID == nil || *input.UserID != 55501 || input.Days != 14 {
+		t.Errorf("RestoreArguments() = %s", restored)
+	}
+	if unchanged := redactor.RestoreArguments(json.RawMessage(`{"days": 14}`)); !strings.Contains(string(unchanged), `"days": 14`) {
+		t.Errorf("RestoreArguments() without user_id = %s", unchanged)
+	}
+}
+
+func TestNewRedactorFromEnv(t *testing.T) {
+	if redactor, err := NewRedactorFromEnv(os.Getenv); redactor != nil || err != nil {
+		t.Errorf("default = %v, %v, want redaction off", redactor, err)
+	}
+	t.Setenv("WHOOP_REDACT_PII", "mask")
+	if _, err := NewRedactorFromEnv(os.Getenv); err == nil {
+		t.Error("expected an unknown value to fail")
+	}
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// runPurgeCommand implements `whoop-mcp-server purge`: it overwrites and
+// deletes every datastore file and removes the datastore key from the
+// keychain. OAuth tokens and report templates are left alone.
+func runPurgeCommand(settings Settings, args []string) error {
+	flags := flag.NewFlagSet("purge", flag.ContinueOnError)
+	yes := flags.Bool("yes", false, "Confirm that the local datastore should be wiped")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	paths, err := datastorePaths(settings)
+	if err != nil {
+		return err
+	}
+	if !*yes {
+		fmt.Println("This permanently wipes the local datastore:")
+		for _, path := range paths {
+			fmt.Println("  " + path)
+		}
+		return fmt.Errorf("re-run with -yes to confirm")
+	}
+
+	wiped, err := purgeDatastore(paths)
+	if err != nil {
+		return err
+	}
+	// Without a keychain there is no key to remove, so only keyring mode warns
+	err = keyring.Delete(keyringService, keyringDataKeyUser)
+	if mode := strings.ToLower(settings("WHOOP_DATA_ENCRYPTION")); err != nil && !errors.Is(err, keyring.ErrNotFound) &&
+		(mode == "keyring" || mode == "keychain") {
+		fmt.Printf("⚠️ Could not remove the datastore key from the keychain: %v\n", err)
+	}
+	fmt.Printf("🧹 Wiped %d file(s) from the local datastore\n", wiped)
+	return nil
+}
+
+// datastorePaths resolves every datastore entry in localDataFiles
+func datastorePaths(settings Settings) ([]string, error) {
+	paths := make([]string, 0, len(localDataFiles))
+	for _, entry := range localDataFiles {
+		path, err := localDataPath(settings, entry[0], entry[1])
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// purgeDatastore wipes each path, and every file under it when it is a
+// directory, along with leftover temporary files from interrupted writes.
+// Missing paths are skipped. It returns how many files were wiped.
+func purgeDatastore(paths []string) (int, error) {
+	wiped := 0
+	for _, path := range paths {
+		var files []string
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return wiped, fmt.Errorf("failed to list %s: %w", path, err)
+		}
+		if _, err := os.Stat(path + ".tmp"); err == nil {
+			files = append(files, path+".tmp")
+		}
+		sort.Strings(files)
+
+		for _, file := range files {
+			if err := wipeFile(file); err != nil {
+				return wiped, err
+			}
+			wiped++
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return wiped, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return wiped, nil
+}
+
+// wipeFile overwrites a file with random bytes, flushes it to disk, and
+// deletes it. Journaling and copy-on-write filesystems may keep old blocks, so
+// an encrypted datastore, whose key purge also removes, is the stronger guarantee.
+func wipeFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	noise := make([]byte, info.Size())
+	if _, err := rand.Read(noise); err != nil {
+		file.Close()
+		return err
+	}
+	_, err = file.WriteAt(noise, 0)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to overwrite %s: %w", path, err)
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// Whoop's published default quotas for developer apps
+	whoopQuotaPerMinute = 100
+	whoopQuotaPerDay    = 10000
+)
+
+// RateLimitStatus is a snapshot of the client's request budget
+type RateLimitStatus struct {
+	LimiterRatePerMinute  float64    `json:"limiter_rate_per_minute"`
+	LimiterBurst          int        `json:"limiter_burst"`
+	LimiterTokens         float64    `json:"limiter_tokens_available"`
+	RequestsLastMinute    int        `json:"requests_last_minute"`
+	RequestsLastDay       int        `json:"requests_last_day"`
+	RequestsTotal         int        `json:"requests_total"`
+	EstimatedMinuteRemain int        `json:"estimated_remaining_this_minute"`
+	EstimatedDayRemain    int        `json:"estimated_remaining_today"`
+	WhoopReportedLimit    *int       `json:"whoop_reported_limit,omitempty"`
+	WhoopReportedRemain   *int       `json:"whoop_reported_remaining,omitempty"`
+	WhoopReportedResetSec *int       `json:"whoop_reported_reset_seconds,omitempty"`
+	LastRequestAt         *time.Time `json:"last_request_at,omitempty"`
+	Throttled             int        `json:"throttled_responses"`
+}
+
+// requestMeter records outgoing API requests and the quota headers Whoop returns
+type requestMeter struct {
+	mu         sync.Mutex
+	timestamps []time.Time
+	total      int
+	throttled  int
+
+	reportedLimit  *int
+	reportedRemain *int
+	reportedReset  *int
+}
+
+// newRequestMeter creates an empty request meter
+func newRequestMeter() *requestMeter {
+	return &requestMeter{}
+}
+
+// Record notes a completed request and any X-RateLimit-* headers on the response
+func (m *requestMeter) Record(statusCode int, header http.Header) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.timestamps = append(m.timestamps, now)
+	m.total++
+	m.prune(now)
+
+	if statusCode == http.StatusTooManyRequests {
+		m.throttled++
+	}
+
+	if header == nil {
+		return
+	}
+	if v, ok := headerInt(header, "X-RateLimit-Limit"); ok {
+		m.reportedLimit = &v
+	}
+	if v, ok := headerInt(header, "X-RateLimit-Remaining"); ok {
+		m.reportedRemain = &v
+	}
+	if v, ok := headerInt(header, "X-RateLimit-Reset"); ok {
+		m.reportedReset = &v
+	}
+}
+
+// Snapshot summarizes recorded traffic; callers add limiter state
+func (m *requestMeter) Snapshot() RateLimitStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.prune(now)
+
+	lastMinute := 0
+	for _, ts := range m.timestamps {
+		if now.Sub(ts) <= time.Minute {
+			lastMinute++
+		}
+	}
+	lastDay := len(m.timestamps)
+
+	status := RateLimitStatus{
+		RequestsLastMinute:    lastMinute,
+		RequestsLastDay:       lastDay,
+		RequestsTotal:         m.total,
+		EstimatedMinuteRemain: maxInt(whoopQuotaPerMinute-lastMinute, 0),
+		EstimatedDayRemain:    maxInt(whoopQuotaPerDay-lastDay, 0),
+		WhoopReportedLimit:    m.reportedLimit,
+		WhoopReportedRemain:   m.reportedRemain,
+		WhoopReportedResetSec: m.reportedReset,
+		Throttled:             m.throttled,
+	}
+
+	if len(m.timestamps) > 0 {
+		last := m.timestamps[len(m.timestamps)-1]
+		status.LastRequestAt = &last
+	}
+
+	return status
+}
+
+// prune drops timestamps older than a day; callers must hold the lock
+func (m *requestMeter) prune(now time.Time) {
+	cutoff := 0
+	for cutoff < len(m.timestamps) && now.Sub(m.timestamps[cutoff]) > 24*time.Hour {
+		cutoff++
+	}
+	m.timestamps = m.timestamps[cutoff:]
+}
+
+// headerInt parses an integer header value
+func headerInt(header http.Header, key string) (int, bool) {
+	raw := header.Get(key)
+	if raw == "" {
+		return 0, false
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
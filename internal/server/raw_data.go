@@ -0,0 +1,149 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultRawRecords caps raw tool output when max_records is not given
+const defaultRawRecords = 25
+
+// rawDataTools maps each raw tool to the dataset it returns
+var rawDataTools = map[string]string{
+	"get_raw_recovery": "recovery",
+	"get_raw_sleep":    "sleep",
+	"get_raw_workouts": "workout",
+	"get_raw_cycles":   "cycle",
+}
+
+// RawDataResult is the JSON document the raw tools return
+type RawDataResult struct {
+	Dataset  string                   `json:"dataset"`
+	Total    int                      `json:"total"`    // records in the date range
+	Returned int                      `json:"returned"` // records after max_records
+	Fields   []string                 `json:"fields,omitempty"`
+	Records  []map[string]interface{} `json:"records"`
+}
+
+// rawDataTool defines the schema of one raw record tool
+func rawDataTool(name, dataset string) MCPTool {
+	fields := rawFieldPaths(reflect.TypeOf(new(HealthData).records(dataset)).Elem(), "")
+	return MCPTool{
+		Name: name,
+		Description: fmt.Sprintf("Get raw %s records exactly as returned by the Whoop API, newest first, optionally limited to selected fields, for precise numbers instead of narrative summaries",
+			dataset),
+		InputSchema: MCPInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"start_date": map[string]interface{}{
+					"type":        "string",
+					"description": "Start date in YYYY-MM-DD format or an expression such as \"last week\", \"past 30 days\", \"March\", or \"yesterday\"",
+				},
+				"end_date": map[string]interface{}{
+					"type":        "string",
+					"description": "End date in YYYY-MM-DD format or an expression such as \"yesterday\" (default: today, or the end of a range like \"last week\")",
+				},
+				"days": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of days starting at start_date, instead of end_date",
+					"minimum":     1,
+				},
+				"fields": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string", "enum": fields},
+					"description": "Dotted field paths to keep, e.g. score.strain; selecting an object keeps all of its fields (default: all fields)",
+				},
+				"max_records": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Maximum number of records to return (default: %d)", defaultRawRecords),
+					"minimum":     1,
+				},
+				"user_id": map[string]interface{}{
+					"type":        []string{"integer", "string"},
+					"description": "Optional user ID or alias from whoop://accounts (defaults to authenticated user)",
+				},
+			},
+			Required: []string{"start_date"},
+		},
+	}
+}
+
+// rawFieldPaths lists every dotted JSON path of a record type, objects
+// included, in declaration order
+func rawFieldPaths(t reflect.Type, prefix string) []string {
+	var paths []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		path := prefix + exportFieldName(field)
+		paths = append(paths, path)
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			paths = append(paths, rawFieldPaths(field.Type, path+".")...)
+		}
+	}
+	return paths
+}
+
+// projectRecords converts up to max records to JSON objects holding only the
+// selected dotted paths, or every field when none are selected
+func projectRecords(dataset string, records interface{}, fields []string, max int) (RawDataResult, error) {
+	valid := rawFieldPaths(reflect.TypeOf(records).Elem(), "")
+	for _, field := range fields {
+		if !slices.Contains(valid, field) {
+			return RawDataResult{}, fmt.Errorf("unknown %s field %q", dataset, field)
+		}
+	}
+
+	value := reflect.ValueOf(records)
+	result := RawDataResult{Dataset: dataset, Total: value.Len(), Fields: fields, Records: []map[string]interface{}{}}
+	for i := 0; i < value.Len() && i < max; i++ {
+		encoded, err := json.Marshal(value.Index(i).Interface())
+		if err != nil {
+			return RawDataResult{}, fmt.Errorf("failed to encode %s record: %w", dataset, err)
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal(encoded, &record); err != nil {
+			return RawDataResult{}, fmt.Errorf("failed to decode %s record: %w", dataset, err)
+		}
+		if len(fields) > 0 {
+			record = projectFields(record, fields)
+		}
+		result.Records = append(result.Records, record)
+	}
+	result.Returned = len(result.Records)
+	return result, nil
+}
+
+// projectFields copies the selected dotted paths of a record into a new
+// object with the same nesting
+func projectFields(record map[string]interface{}, fields []string) map[string]interface{} {
+	projected := make(map[string]interface{})
+	for _, field := range fields {
+		parts := strings.Split(field, ".")
+		source, target := record, projected
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := source[part].(map[string]interface{})
+			if !ok {
+				source = nil
+				break
+			}
+			source = next
+			child, ok := target[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				target[part] = child
+			}
+			target = child
+		}
+		if source == nil {
+			continue
+		}
+		if value, ok := source[parts[len(parts)-1]]; ok {
+			target[parts[len(parts)-1]] = value
+		}
+	}
+	return projected
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestRawFieldPaths(t *testing.T) {
+	paths := rawFieldPaths(reflect.TypeOf(WhoopRecovery{}), "")
+	for _, want := range []string{"cycle_id", "created_at", "score", "score.hrv_rmssd_milli"} {
+		if !slices.Contains(paths, want) {
+			t.Errorf("Expected path %s in %v", want, paths)
+		}
+	}
+	if slices.Contains(paths, "created_at.wall") {
+		t.Error("Expected timestamps to be leaves")
+	}
+}
+
+func TestProjectRecords(t *testing.T) {
+	workouts := healthArchiveFixture().Workouts
+
+	result, err := projectRecords("workout", workouts, []string{"id", "score.strain", "score.zone_durations"}, 1)
+	if err != nil {
+		t.Fatalf("projectRecords() error = %v", err)
+	}
+	if result.Total != 2 || result.Returned != 1 {
+		t.Errorf("Expected 1 of 2 records, got %d of %d", result.Returned, result.Total)
+	}
+	record := result.Records[0]
+	score := record["score"].(map[string]interface{})
+	if record["id"] != "workout-1" || score["strain"] != 12.4 || len(record) != 2 {
+		t.Errorf("Unexpected projection %v", record)
+	}
+	if _, ok := score["zone_durations"].(map[string]interface{}); !ok || len(score) != 2 {
+		t.Errorf("Expected strain and the whole zone_durations object, got %v", score)
+	}
+
+	all, err := projectRecords("workout", workouts, nil, 25)
+	if err != nil || all.Returned != 2 || all.Records[1]["score_state"] != "PENDING_SCORE" {
+		t.Errorf("Expected every field of every record, got %+v, %v", all, err)
+	}
+
+	if _, err := projectRecords("workout", workouts, []string{"score.bogus"}, 25); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+const (
+	// greenRecovery is the lower bound of Whoop's green recovery zone
+	greenRecovery = 67.0
+	// minForecastSamples is the fewest complete days needed to fit the model
+	minForecastSamples = 14
+	// forecastIntervalZ gives an 80% prediction interval
+	forecastIntervalZ = 1.28
+	// maxSleepHoursTarget caps the sleep recommendation at something achievable
+	maxSleepHoursTarget = 11.0
+)
+
+// forecastFactors are the model inputs, in the order they appear in a row
+var forecastFactors = []struct {
+	key   string
+	label string
+}{
+	{"strain", "yesterday's strain"},
+	{"sleep_hours", "last night's sleep"},
+	{"sleep_debt", "accumulated sleep debt"},
+	{"prior_recovery", "the previous day's recovery"},
+}
+
+// ForecastFactor is one input's contribution to the forecast relative to a typical day
+type ForecastFactor struct {
+	Key          string  `json:"key"`
+	Label        string  `json:"label"`
+	Value        float64 `json:"value"`
+	Typical      float64 `json:"typical"`
+	Coefficient  float64 `json:"coefficient"`
+	Contribution float64 `json:"contribution"` // recovery points vs typical
+}
+
+// RecoveryForecast projects tomorrow's recovery from today's inputs
+type RecoveryForecast struct {
+	Status            string           `json:"status"` // "ok" or "insufficient_data"
+	Samples           int              `json:"samples"`
+	Expected          float64          `json:"expected"`
+	Low               float64          `json:"low"`
+	High              float64          `json:"high"`
+	AssumedSleepHours float64          `json:"assumed_sleep_hours"`
+	SleepForGreen     float64          `json:"sleep_for_green_hours"` // -1 when no achievable amount reaches green
+	Factors           []ForecastFactor `json:"factors"`
+	Dominant          ForecastFactor   `json:"dominant"`
+}
+
+// ForecastRecovery fits next-morning recovery on the prior day's strain, the
+// night's sleep, the running sleep debt going into that night, and the prior
+// day's recovery, then projects tomorrow assuming a typical night of sleep.
+// It also solves for the sleep needed tonight to reach a green recovery.
+func (h *HealthAnalyzer) ForecastRecovery(data *HealthData) RecoveryForecast {
+	forecast := RecoveryForecast{Status: "insufficient_data", Factors: []ForecastFactor{}}
+
+	recoveryMetric, _ := lookupMetric("recovery")
+	strainMetric, _ := lookupMetric("strain")
+	recovery := dailyAverages(recoveryMetric.extract(data))
+	strain := dailyAverages(strainMetric.extract(data))
+
+	ledger := h.BuildSleepDebtLedger(data.Sleeps)
+	sleepHours := make(map[string]float64)
+	debtBefore := make(map[string]float64)
+	previousDebt := 0.0
+	for _, entry := range ledger.Entries {
+		sleepHours[entry.Date] = entry.SleepHours + entry.NapHours
+		debtBefore[entry.Date] = previousDebt
+		previousDebt = entry.Debt
+	}
+
+	dates := make([]string, 0, len(recovery))
+	for date := range recovery {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	if len(dates) == 0 {
+		return forecast
+	}
+
+	var rows [][]float64
+	var ys []float64
+	for _, date := range dates {
+		day, _ := time.Parse("2006-01-02", date)
+		yesterday := dayKey(day.AddDate(0, 0, -1))
+		s, okStrain := strain[yesterday]
+		hours, okSleep := sleepHours[date]
+		prior, okPrior := recovery[yesterday]
+		if !okStrain || !okSleep || !okPrior {
+			continue
+		}
+		rows = append(rows, []float64{s, hours, debtBefore[date], prior})
+		ys = append(ys, recovery[date])
+	}
+	forecast.Samples = len(rows)
+	if len(rows) < minForecastSamples {
+		return forecast
+	}
+
+	coefficients, residualSD, ok := stats.MultipleLinearRegression(rows, ys)
+	if !ok {
+		return forecast
+	}
+
+	// Today's inputs for tomorrow morning; tonight's sleep is assumed typical
+	latest := dates[len(dates)-1]
+	var recentSleep []float64
+	for i := len(ledger.Entries) - 1; i >= 0 && len(recentSleep) < sleepDebtRecentNights; i-- {
+		recentSleep = append(recentSleep, ledger.Entries[i].SleepHours+ledger.Entries[i].NapHours)
+	}
+	forecast.AssumedSleepHours = h.calculateMean(recentSleep)
+
+	todayStrain, ok := strain[latest]
+	if !ok {
+		todayStrain = h.calculateMean(columnOf(rows, 0))
+	}
+	inputs := []float64{todayStrain, forecast.AssumedSleepHours, ledger.CurrentDebtHours, recovery[latest]}
+
+	forecast.Status = "ok"
+	forecast.Expected = clamp(stats.PredictLinear(coefficients, inputs), 0, 100)
+	forecast.Low = clamp(forecast.Expected-forecastIntervalZ*residualSD, 0, 100)
+	forecast.High = clamp(forecast.Expected+forecastIntervalZ*residualSD, 0, 100)
+
+	for i, factor := range forecastFactors {
+		typical := h.calculateMean(columnOf(rows, i))
+		contribution := ForecastFactor{
+			Key:          factor.key,
+			Label:        factor.label,
+			Value:        inputs[i],
+			Typical:      typical,
+			Coefficient:  coefficients[i+1],
+			Contribution: coefficients[i+1] * (inputs[i] - typical),
+		}
+		forecast.Factors = append(forecast.Factors, contribution)
+		if math.Abs(contribution.Contribution) > math.Abs(forecast.Dominant.Contribution) {
+			forecast.Dominant = contribution
+		}
+	}
+
+	// Solve for the sleep that brings the expected value up to green
+	sleepCoefficient := coefficients[2]
+	forecast.SleepForGreen = -1
+	if sleepCoefficient > 0 {
+		withoutSleep := stats.PredictLinear(coefficients, inputs) - sleepCoefficient*forecast.AssumedSleepHours
+		needed := math.Max((greenRecovery-withoutSleep)/sleepCoefficient, 0)
+		if needed <= maxSleepHoursTarget {
+			forecast.SleepForGreen = needed
+		}
+	}
+
+	return forecast
+}
+
+// columnOf extracts one predictor across all rows
+func columnOf(rows [][]float64, index int) []float64 {
+	column := make([]float64, len(rows))
+	for i, row := range rows {
+		column[i] = row[index]
+	}
+	return column
+}
+
+// FormatRecoveryForecast renders the forecast, sleep target, and dominant factor
+func (h *HealthAnalyzer) FormatRecoveryForecast(forecast RecoveryForecast) string {
+	var builder strings.Builder
+	builder.WriteString("# Recovery Forecast for Tomorrow\n\n")
+
+	if forecast.Status != "ok" {
+		builder.WriteString(fmt.Sprintf("Not enough complete history to fit a forecast (%d usable days, %d needed). Days need a recovery, the prior day's strain, and the night's sleep.\n", forecast.Samples, minForecastSamples))
+		return builder.String()
+	}
+
+	builder.WriteString(fmt.Sprintf("**Expected recovery:** %.0f%% (likely range %.0f–%.0f%%)\n", forecast.Expected, forecast.Low, forecast.High))
+	builder.WriteString(fmt.Sprintf("*Assumes about %.1f hours of sleep tonight, your recent average. Model fit on %d days.*\n\n", forecast.AssumedSleepHours, forecast.Samples))
+
+	builder.WriteString("## Sleep Needed Tonight for Green\n")
+	switch {
+	case forecast.SleepForGreen < 0:
+		builder.WriteString(fmt.Sprintf("A green recovery (%.0f%%+) is unlikely tomorrow regardless of sleep; prioritize rest and keep strain low today.\n\n", greenRecovery))
+	case forecast.SleepForGreen == 0:
+		builder.WriteString("A typical night should already land in the green.\n\n")
+	default:
+		builder.WriteString(fmt.Sprintf("About **%.1f hours** of sleep should bring the expected recovery to %.0f%%.\n\n", forecast.SleepForGreen, greenRecovery))
+	}
+
+	builder.WriteString("## Why\n")
+	for _, factor := range forecast.Factors {
+		builder.WriteString(fmt.Sprintf("- %s: %.1f vs typical %.1f (%+.0f points)\n", strings.ToUpper(factor.Label[:1])+factor.Label[1:], factor.Value, factor.Typical, factor.Contribution))
+	}
+	if forecast.Dominant.Key != "" {
+		direction := "lifting"
+		if forecast.Dominant.Contribution < 0 {
+			direction = "dragging down"
+		}
+		builder.WriteString(fmt.Sprintf("\nThe dominant factor is **%s**, %s the forecast by about %.0f points.\n", forecast.Dominant.Label, direction, math.Abs(forecast.Dominant.Contribution)))
+	}
+
+	builder.WriteString("\n*Forecasts come from a simple model of your own history and cannot anticipate illness, alcohol, or acute stress.*\n")
+	return builder.String()
+}
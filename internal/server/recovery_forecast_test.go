@@ -0,0 +1,67 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_ForecastRecovery(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	hour := int(time.Hour / time.Millisecond)
+	start := time.Date(2024, 4, 1, 7, 0, 0, 0, time.UTC)
+
+	data := &HealthData{}
+	strains := make([]float64, 40)
+	for i := range strains {
+		wake := start.AddDate(0, 0, i)
+		hours := 6 + float64(i*7%5)*0.5
+		strains[i] = 8 + float64(i*3%7)*1.5
+
+		var sleep WhoopSleep
+		sleep.ID = fmt.Sprintf("sleep-%d", i)
+		sleep.Start = wake.Add(-time.Duration(hours * float64(time.Hour)))
+		sleep.End = wake
+		sleep.Score.StageSummary.TotalInBedTimeMilli = int(hours * float64(hour))
+		sleep.Score.SleepNeeded.BaselineMilli = 8 * hour
+		data.Sleeps = append(data.Sleeps, sleep)
+
+		var cycle WhoopCycle
+		cycle.Start = wake.Add(-8 * time.Hour)
+		cycle.Score.Strain = strains[i]
+		data.Cycles = append(data.Cycles, cycle)
+
+		if i == 0 {
+			continue
+		}
+		var recovery WhoopRecovery
+		recovery.SleepID = sleep.ID
+		recovery.CreatedAt = wake
+		recovery.Score.RecoveryScore = 40 + 5*hours - 1.5*strains[i-1]
+		data.Recoveries = append(data.Recoveries, recovery)
+	}
+
+	forecast := analyzer.ForecastRecovery(data)
+
+	if forecast.Status != "ok" {
+		t.Fatalf("Expected a forecast, got %s with %d samples", forecast.Status, forecast.Samples)
+	}
+	want := 40 + 5*forecast.AssumedSleepHours - 1.5*strains[39]
+	if math.Abs(forecast.Expected-want) > 0.5 {
+		t.Errorf("Expected recovery %.1f, got %.1f", want, forecast.Expected)
+	}
+	wantSleep := (greenRecovery - 40 + 1.5*strains[39]) / 5
+	if math.Abs(forecast.SleepForGreen-wantSleep) > 0.1 {
+		t.Errorf("Expected %.2f hours for green, got %.2f", wantSleep, forecast.SleepForGreen)
+	}
+	if forecast.Dominant.Key == "" {
+		t.Error("Expected a dominant factor")
+	}
+}
+
+func TestHealthAnalyzer_ForecastRecovery_InsufficientData(t *testing.T) {
+	if forecast := NewHealthAnalyzer().ForecastRecovery(&HealthData{}); forecast.Status != "insufficient_data" {
+		t.Errorf("Expected insufficient_data, got %s", forecast.Status)
+	}
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redFlagTypes lists the RedFlag.Type values reports can raise
+var redFlagTypes = []string{
+	"chronic_stress", "extended_poor_recovery", "severe_sleep_deprivation", "dramatic_recovery_decline",
+	"respiratory_rate_spike", "spo2_drop", "skin_temp_elevation", "possible_illness_onset", "under_fueling",
+}
+
+// RedFlagAck acknowledges a red flag type that is known and already being
+// discussed, so reports list it as acknowledged instead of raising it again
+type RedFlagAck struct {
+	ID             int       `json:"id"`
+	UserID         int       `json:"user_id"`
+	Type           string    `json:"type"` // a RedFlag.Type
+	Reason         string    `json:"reason,omitempty"`
+	Until          string    `json:"until,omitempty"` // last day covered, YYYY-MM-DD; empty until removed
+	AcknowledgedAt time.Time `json:"acknowledged_at"`
+}
+
+// ActiveOn reports whether the acknowledgement still covers now
+func (a RedFlagAck) ActiveOn(now time.Time) bool {
+	return a.Until == "" || dayKey(now) <= a.Until
+}
+
+// redFlagLabel turns a red flag type into its title, e.g. "Spo2 Drop"
+func redFlagLabel(flagType string) string {
+	return strings.Title(strings.ReplaceAll(flagType, "_", " "))
+}
+
+// partitionRedFlags splits flags into those still raised and those covered by
+// an active acknowledgement, which carry the acknowledgement's reason
+func partitionRedFlags(flags []RedFlag, acks []RedFlagAck, now time.Time) (raised, acknowledged []RedFlag) {
+	reasons := make(map[string]string)
+	for _, ack := range acks {
+		if ack.ActiveOn(now) {
+			reasons[ack.Type] = ack.Reason
+		}
+	}
+	for _, flag := range flags {
+		reason, ok := reasons[flag.Type]
+		if !ok {
+			raised = append(raised, flag)
+			continue
+		}
+		flag.Acknowledgement = reason
+		acknowledged = append(acknowledged, flag)
+	}
+	return raised, acknowledged
+}
+
+// formatAcknowledgedFlags renders acknowledged red flags as a short list under
+// heading, or nothing when there are none
+func formatAcknowledgedFlags(l *Localizer, flags []RedFlag, heading string) string {
+	if len(flags) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString(l.T("%s Acknowledged Issues", heading) + "\n")
+	for _, flag := range flags {
+		line := fmt.Sprintf("- **%s**: %s", l.T(redFlagLabel(flag.Type)), flag.Description)
+		if flag.Acknowledgement != "" {
+			line += l.T(" (acknowledged: %s)", flag.Acknowledgement)
+		}
+		builder.WriteString(line + "\n")
+	}
+	return builder.String() + "\n"
+}
+
+// FormatRedFlagAcks renders the acknowledgements table for list_red_flags
+func FormatRedFlagAcks(acks []RedFlagAck, now time.Time) string {
+	if len(acks) == 0 {
+		return "No red flags have been acknowledged."
+	}
+	var builder strings.Builder
+	builder.WriteString("| ID | Flag | Reason | Until | Acknowledged | Status |\n|---|---|---|---|---|---|\n")
+	for _, ack := range acks {
+		until, status := ack.Until, "active"
+		if until == "" {
+			until = "-"
+		}
+		if !ack.ActiveOn(now) {
+			status = "expired"
+		}
+		builder.WriteString(fmt.Sprintf("| #%d | %s | %s | %s | %s | %s |\n", ack.ID, redFlagLabel(ack.Type),
+			ack.Reason, until, ack.AcknowledgedAt.Format("2006-01-02"), status))
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// RedFlagAckStore persists red flag acknowledgements per user in the local datastore
+type RedFlagAckStore struct {
+	Path string
+	mu   sync.Mutex
+	storeMode
+}
+
+// NewRedFlagAckStoreFromEnv uses WHOOP_RED_FLAG_ACKS_FILE or the default datastore path,
+// encrypting it with cipher when it is set
+func NewRedFlagAckStoreFromEnv(settings Settings, cipher *DataCipher) (*RedFlagAckStore, error) {
+	path, err := localDataPath(settings, "WHOOP_RED_FLAG_ACKS_FILE", "red_flag_acks.json")
+	if err != nil {
+		return nil, err
+	}
+	return &RedFlagAckStore{Path: path, storeMode: storeMode{cipher: cipher}}, nil
+}
+
+// Add stores an acknowledgement, replacing any earlier one of the same flag
+// type for the user, and assigns it the next ID
+func (r *RedFlagAckStore) Add(ack RedFlagAck) (RedFlagAck, error) {
+	if err := r.writable(); err != nil {
+		return RedFlagAck{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := make(map[string][]RedFlagAck)
+	if err := readJSONFile(r.cipher, r.Path, &all); err != nil {
+		return RedFlagAck{}, err
+	}
+
+	key := strconv.Itoa(ack.UserID)
+	ack.ID = 1
+	kept := all[key][:0:0]
+	for _, existing := range all[key] {
+		if existing.ID >= ack.ID {
+			ack.ID = existing.ID + 1
+		}
+		if existing.Type != ack.Type {
+			kept = append(kept, existing)
+		}
+	}
+	all[key] = append(kept, ack)
+
+	if err := writeJSONFile(r.cipher, r.Path, all); err != nil {
+		return RedFlagAck{}, err
+	}
+	return ack, nil
+}
+
+// Remove deletes one of the user's acknowledgements, reporting whether it existed
+func (r *RedFlagAckStore) Remove(userID, id int) (bool, error) {
+	if err := r.writable(); err != nil {
+		return false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := make(map[string][]RedFlagAck)
+	if err := readJSONFile(r.cipher, r.Path, &all); err != nil {
+		return false, err
+	}
+
+	key := strconv.Itoa(userID)
+	kept := all[key][:0:0]
+	for _, ack := range all[key] {
+		if ack.ID != id {
+			kept = append(kept, ack)
+		}
+	}
+	if len(kept) == len(all[key]) {
+		return false, nil
+	}
+	all[key] = kept
+	return true, writeJSONFile(r.cipher, r.Path, all)
+}
+
+// List returns the user's acknowledgements in the order they were made
+func (r *RedFlagAckStore) List(userID int) ([]RedFlagAck, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := make(map[string][]RedFlagAck)
+	if err := readJSONFile(r.cipher, r.Path, &all); err != nil {
+		return nil, err
+	}
+	return all[strconv.Itoa(userID)], nil
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedFlagAckStore_AddRemove(t *testing.T) {
+	store := &RedFlagAckStore{Path: filepath.Join(t.TempDir(), "red_flag_acks.json")}
+	first, err := store.Add(RedFlagAck{UserID: 1, Type: "severe_sleep_deprivation", Reason: "newborn"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	second, _ := store.Add(RedFlagAck{UserID: 1, Type: "extended_poor_recovery"})
+	// Acknowledging the same type again replaces the earlier acknowledgement
+	third, _ := store.Add(RedFlagAck{UserID: 1, Type: "severe_sleep_deprivation", Reason: "newborn, night feeds"})
+	if first.ID != 1 || second.ID != 2 || third.ID != 3 {
+		t.Errorf("Expected IDs 1, 2, and 3, got %d, %d, and %d", first.ID, second.ID, third.ID)
+	}
+
+	acks, _ := store.List(1)
+	if len(acks) != 2 || acks[1].ID != 3 || acks[1].Reason != "newborn, night feeds" {
+		t.Errorf("Expected #2 and the replacement #3, got %+v", acks)
+	}
+	if removed, err := store.Remove(1, 2); err != nil || !removed {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if removed, _ := store.Remove(1, 2); removed {
+		t.Error("Expected a second Remove to find nothing")
+	}
+}
+
+func TestPartitionRedFlags(t *testing.T) {
+	now := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+	flags := []RedFlag{
+		{Type: "severe_sleep_deprivation", Severity: "critical", Description: "Average sleep in recent 3 days is critically low (4.2 hours)"},
+		{Type: "spo2_drop", Severity: "moderate", Description: "Blood oxygen dropped"},
+		{Type: "extended_poor_recovery", Severity: "high", Description: "Recovery scores have been poor for 8 consecutive days"},
+	}
+	acks := []RedFlagAck{
+		{Type: "severe_sleep_deprivation", Reason: "newborn"},
+		{Type: "extended_poor_recovery", Until: "2024-06-09"}, // expired yesterday
+	}
+
+	raised, acknowledged := partitionRedFlags(flags, acks, now)
+	if len(raised) != 2 || raised[0].Type != "spo2_drop" || raised[1].Type != "extended_poor_recovery" {
+		t.Errorf("raised = %+v, want spo2_drop and the expired extended_poor_recovery", raised)
+	}
+	if len(acknowledged) != 1 || acknowledged[0].Acknowledgement != "newborn" {
+		t.Fatalf("acknowledged = %+v, want severe_sleep_deprivation with its reason", acknowledged)
+	}
+
+	section := formatAcknowledgedFlags(nil, acknowledged, "##")
+	want := "## Acknowledged Issues\n- **Severe Sleep Deprivation**: Average sleep in recent 3 days is critically low (4.2 hours) (acknowledged: newborn)\n\n"
+	if section != want {
+		t.Errorf("formatAcknowledgedFlags() = %q, want %q", section, want)
+	}
+
+	summary := &HealthSummary{RedFlags: raised, AcknowledgedFlags: acknowledged}
+	report := NewHealthAnalyzer().FormatInsightsForTherapy(summary)
+	if !strings.Contains(report, "## Acknowledged Issues") || strings.Count(report, "critically low") != 1 {
+		t.Errorf("therapy report should list the acknowledged flag once, outside Red Flags:\n%s", report)
+	}
+}
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	audienceTherapist = "therapist"
+	audienceCoach     = "coach"
+	audienceSelf      = "self"
+)
+
+// audiences lists the supported audience values, default first
+var audiences = []string{audienceTherapist, audienceCoach, audienceSelf}
+
+// ReportFormatter renders analysis results for one audience. Each
+// implementation frames the same numbers for a different reader.
+type ReportFormatter interface {
+	HealthSummary(summary *HealthSummary) string
+	Stress(period DateRange, stress StressIndicators) string
+	Sleep(period DateRange, sessions int, analysis SleepAnalysis) string
+	Activity(period DateRange, workouts int, patterns ActivityPatterns) string
+}
+
+// reportFormatter returns the formatter for an audience; an empty audience
+// selects the therapist framing
+func (h *HealthAnalyzer) reportFormatter(audience string) (ReportFormatter, error) {
+	switch audience {
+	case "", audienceTherapist:
+		return therapistFormatter{analyzer: h}, nil
+	case audienceCoach:
+		return coachFormatter{l: h.locale}, nil
+	case audienceSelf:
+		return selfFormatter{l: h.locale}, nil
+	default:
+		return nil, fmt.Errorf("audience must be one of %s", strings.Join(audiences, ", "))
+	}
+}
+
+// audienceProperty is the shared input schema for the audience argument
+func audienceProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Who the report is for: therapist (session framing), coach (load, readiness, periodization), or self (plain self-tracking). Default: therapist",
+		"enum":        audiences,
+	}
+}
+
+// formatPeriod renders a date range as "start to end"
+func formatPeriod(l *Localizer, period DateRange) string {
+	return l.T("%s to %s", period.Start.Format("2006-01-02"), period.End.Format("2006-01-02"))
+}
+
+// trainingPhase suggests a periodization phase from readiness and load,
+// returning the phase name and a one-sentence rationale
+func trainingPhase(recovery RecoveryTrend, stress StressIndicators, activity ActivityPatterns) (string, string) {
+	switch {
+	case activity.OvertrainingRisk == "high" || stress.StressLevel == "critical" || stress.PoorRecoveryStreak >= 3:
+		return markMessage("Deload"), markMessage("Recovery is not keeping up with load; cut volume 30-50% and keep intensity low until recovery returns to green.")
+	case recovery.Trend == "declining" || stress.StressLevel == "high" || recovery.AverageScore < redRecovery:
+		return markMessage("Maintain"), markMessage("Hold current volume and avoid adding intensity until readiness stabilizes.")
+	case recovery.AverageScore >= greenRecovery && activity.OvertrainingRisk == "low":
+		return markMessage("Build"), markMessage("Readiness is strong; there is room to progress volume or intensity gradually.")
+	default:
+		return markMessage("Maintain"), markMessage("Readiness is adequate; keep load steady and progress only on green days.")
+	}
+}
+
+// readinessLabel describes an average recovery score in Whoop's color bands
+func readinessLabel(score float64) string {
+	switch {
+	case score >= greenRecovery:
+		return markMessage("green (primed for hard sessions)")
+	case score >= redRecovery:
+		return markMessage("yellow (moderate sessions)")
+	default:
+		return markMessage("red (prioritize recovery)")
+	}
+}
+
+// therapistFormatter frames results as discussion material for therapy sessions
+type therapistFormatter struct {
+	analyzer *HealthAnalyzer
+}
+
+func (f therapistFormatter) HealthSummary(summary *HealthSummary) string {
+	return f.analyzer.FormatInsightsForTherapy(summary)
+}
+
+func (f therapistFormatter) Stress(period DateRange, stress StressIndicators) string {
+	return f.analyzer.templates.Render(f.analyzer.locale, "stress_therapist", struct {
+		Period          string
+		Stress          StressIndicators
+		Recommendations string
+	}{formatPeriod(f.analyzer.locale, period), stress, f.stressRecommendations(stress)})
+}
+
+func (f therapistFormatter) Sleep(period DateRange, sessions int, analysis SleepAnalysis) string {
+	return f.analyzer.templates.Render(f.analyzer.locale, "sleep_therapist", struct {
+		Period          string
+		Sessions        int
+		Sleep           SleepAnalysis
+		Implications    string
+		Recommendations string
+	}{formatPeriod(f.analyzer.locale, period), sessions, analysis, f.sleepMentalHealthImplications(analysis), f.sleepRecommendations(analysis)})
+}
+
+func (f therapistFormatter) Activity(period DateRange, workouts int, patterns ActivityPatterns) string {
+	return f.analyzer.templates.Render(f.analyzer.locale, "activity_therapist", struct {
+		Period   string
+		Workouts int
+		Activity ActivityPatterns
+		Insights string
+	}{formatPeriod(f.analyzer.locale, period), workouts, patterns, f.activityBehavioralInsights(patterns)})
+}
+
+// stressRecommendations maps the stress level to a therapeutic next step
+func (f therapistFormatter) stressRecommendations(stress StressIndicators) string {
+	switch stress.StressLevel {
+	case "critical":
+		return f.analyzer.locale.T("Immediate intervention recommended. Consider reducing stressors, improving sleep hygiene, and potentially seeking medical evaluation for chronic stress impacts.")
+	case "high":
+		return f.analyzer.locale.T("Elevated stress levels detected. Focus on stress management techniques, relaxation practices, and identifying primary stressors in therapy.")
+	case "moderate":
+		return f.analyzer.locale.T("Moderate stress indicators present. Discuss stress management strategies and monitor for progression.")
+	default:
+		return f.analyzer.locale.T("Stress levels appear within normal range. Continue current coping strategies.")
+	}
+}
+
+func (f therapistFormatter) sleepMentalHealthImplications(analysis SleepAnalysis) string {
+	implications := []string{}
+
+	if analysis.AverageHours < 7 {
+		implications = append(implications, f.analyzer.locale.T("Insufficient sleep duration may contribute to mood instability, increased anxiety, and difficulty with emotional regulation"))
+	}
+
+	if analysis.AverageEfficiency < 0.8 {
+		implications = append(implications, f.analyzer.locale.T("Poor sleep efficiency suggests difficulty maintaining sleep, which can indicate anxiety, stress, or sleep disorders"))
+	}
+
+	if note := wakePatternNote(f.analyzer.locale, analysis.Wake); note != "" {
+		implications = append(implications, note)
+	}
+
+	if analysis.SleepQualityTrend == "declining" {
+		implications = append(implications, f.analyzer.locale.T("Declining sleep quality trend may reflect increasing stress, life changes, or developing mental health concerns"))
+	}
+
+	if len(implications) == 0 {
+		return f.analyzer.locale.T("Sleep patterns appear supportive of mental health and emotional regulation.")
+	}
+
+	return strings.Join(implications, ". ")
+}
+
+func (f therapistFormatter) sleepRecommendations(analysis SleepAnalysis) string {
+	recommendations := []string{}
+
+	if analysis.AverageHours < 7 {
+		recommendations = append(recommendations, f.analyzer.locale.T("Focus on extending sleep duration through earlier bedtime and consistent sleep schedule"))
+	}
+
+	if analysis.AverageEfficiency < 0.85 {
+		recommendations = append(recommendations, f.analyzer.locale.T("Explore sleep hygiene practices and factors affecting sleep maintenance"))
+	}
+
+	if scheduleConsistency(analysis) < 0.7 {
+		recommendations = append(recommendations, f.analyzer.locale.T("Work on sleep schedule consistency to improve circadian rhythm regulation"))
+	}
+
+	if len(recommendations) == 0 {
+		return f.analyzer.locale.T("Continue current sleep practices as they appear to be supporting good sleep quality.")
+	}
+
+	return strings.Join(recommendations, "; ")
+}
+
+func (f therapistFormatter) activityBehavioralInsights(patterns ActivityPatterns) string {
+	insights := []string{}
+
+	if patterns.WeeklyWorkouts == 0 {
+		insights = append(insights, f.analyzer.locale.T("Lack of recorded physical activity may indicate low motivation, energy, or potential depression symptoms"))
+	} else if patterns.WeeklyWorkouts > 7 {
+		insights = append(insights, f.analyzer.locale.T("High exercise frequency might indicate compulsive exercise behaviors or use of exercise as primary coping mechanism"))
+	}
+
+	if patterns.OvertrainingRisk == "high" {
+		insights = append(insights, f.analyzer.locale.T("High training load may contribute to physical and mental fatigue, potentially exacerbating stress and mood issues"))
+	}
+
+	if patterns.IntensityBalance == "high_intensity_focused" {
+		insights = append(insights, f.analyzer.locale.T("Preference for high-intensity exercise may reflect need for intense stimulation or avoidance behaviors"))
+	}
+
+	if len(insights) == 0 {
+		return f.analyzer.locale.T("Activity patterns suggest a balanced approach to exercise that likely supports mental health.")
+	}
+
+	return strings.Join(insights, ". ")
+}
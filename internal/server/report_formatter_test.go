@@ -0,0 +1,64 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportFormatter_Audiences(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	summary := &HealthSummary{
+		DateRange:        DateRange{Start: start, End: start.AddDate(0, 0, 13)},
+		RecoveryTrend:    RecoveryTrend{AverageScore: 72, Trend: "stable"},
+		SleepAnalysis:    SleepAnalysis{AverageHours: 7.4, AverageEfficiency: 0.9, ConsistencyScore: 0.8},
+		StressIndicators: StressIndicators{StressLevel: "low"},
+		ActivityPatterns: ActivityPatterns{WeeklyWorkouts: 4, OvertrainingRisk: "low", IntensityBalance: "balanced"},
+	}
+
+	headings := map[string]string{
+		"":                "# Health Summary for Therapy Session",
+		audienceTherapist: "# Health Summary for Therapy Session",
+		audienceCoach:     "# Athlete Readiness Report",
+		audienceSelf:      "# Your Health Summary",
+	}
+	for audience, heading := range headings {
+		formatter, err := analyzer.reportFormatter(audience)
+		if err != nil {
+			t.Fatalf("reportFormatter(%q) error: %v", audience, err)
+		}
+		if got := formatter.HealthSummary(summary); !strings.HasPrefix(got, heading) {
+			t.Errorf("audience %q: expected heading %q, got:\n%s", audience, heading, got)
+		}
+	}
+
+	coach, _ := analyzer.reportFormatter(audienceCoach)
+	if got := coach.HealthSummary(summary); !strings.Contains(got, "## Recommended Phase: Build") {
+		t.Errorf("Expected a build phase for green, low-risk readiness, got:\n%s", got)
+	}
+
+	if _, err := analyzer.reportFormatter("doctor"); err == nil {
+		t.Error("Expected an error for an unknown audience")
+	}
+}
+
+func TestTrainingPhase(t *testing.T) {
+	cases := []struct {
+		name     string
+		recovery RecoveryTrend
+		stress   StressIndicators
+		activity ActivityPatterns
+		want     string
+	}{
+		{"overtraining", RecoveryTrend{AverageScore: 70}, StressIndicators{}, ActivityPatterns{OvertrainingRisk: "high"}, "Deload"},
+		{"poor streak", RecoveryTrend{AverageScore: 50}, StressIndicators{PoorRecoveryStreak: 4}, ActivityPatterns{OvertrainingRisk: "low"}, "Deload"},
+		{"declining", RecoveryTrend{AverageScore: 70, Trend: "declining"}, StressIndicators{}, ActivityPatterns{OvertrainingRisk: "low"}, "Maintain"},
+		{"green", RecoveryTrend{AverageScore: 70, Trend: "stable"}, StressIndicators{StressLevel: "low"}, ActivityPatterns{OvertrainingRisk: "low"}, "Build"},
+	}
+	for _, c := range cases {
+		if got, _ := trainingPhase(c.recovery, c.stress, c.activity); got != c.want {
+			t.Errorf("%s: trainingPhase() = %s, want %s", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package server
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RetentionPolicy limits how long day-level journal entries stay in the local
+// datastore. Older entries are folded into weekly summaries without their
+// free-text notes. Whoop records are fetched live and never stored locally.
+type RetentionPolicy struct {
+	Months int
+}
+
+// LoadRetentionPolicyFromEnv reads WHOOP_RETENTION_MONTHS (e.g. 18); unset or
+// 0 keeps everything and returns nil
+func LoadRetentionPolicyFromEnv(settings Settings) (*RetentionPolicy, error) {
+	value := strings.TrimSpace(settings("WHOOP_RETENTION_MONTHS"))
+	if value == "" || value == "0" {
+		return nil, nil
+	}
+	months, err := strconv.Atoi(value)
+	if err != nil || months < 1 || months > 600 {
+		return nil, fmt.Errorf("invalid WHOOP_RETENTION_MONTHS %q (expected 1-600 months, or 0 to keep everything)", value)
+	}
+	return &RetentionPolicy{Months: months}, nil
+}
+
+// Cutoff returns the first day whose entries are kept in full. It falls on a
+// Monday so each summarized week is complete.
+func (p *RetentionPolicy) Cutoff(now time.Time) time.Time {
+	return bucketStart(now.AddDate(0, -p.Months, 0), granularityWeekly)
+}
+
+// AnnotationWeek summarizes one week of journal entries that aged out of retention
+type AnnotationWeek struct {
+	UserID      int            `json:"user_id"`
+	WeekStart   string         `json:"week_start"` // Monday, YYYY-MM-DD
+	Entries     int            `json:"entries"`
+	MoodTotal   int            `json:"mood_total,omitempty"`
+	MoodEntries int            `json:"mood_entries,omitempty"`
+	Tags        map[string]int `json:"tags,omitempty"`     // tag → entries carrying it
+	Symptoms    map[string]int `json:"symptoms,omitempty"` // symptom → entries reporting it
+}
+
+// MoodAverage returns the week's mean mood, or 0 when no entry rated it
+func (w AnnotationWeek) MoodAverage() float64 {
+	if w.MoodEntries == 0 {
+		return 0
+	}
+	return float64(w.MoodTotal) / float64(w.MoodEntries)
+}
+
+// add folds one annotation into the week
+func (w *AnnotationWeek) add(annotation Annotation) {
+	w.Entries++
+	if annotation.Mood != nil {
+		w.MoodTotal += *annotation.Mood
+		w.MoodEntries++
+	}
+	for _, tag := range annotation.Tags {
+		if w.Tags == nil {
+			w.Tags = make(map[string]int)
+		}
+		w.Tags[strings.ToLower(tag)]++
+	}
+	for symptom := range annotation.Symptoms {
+		if w.Symptoms == nil {
+			w.Symptoms = make(map[string]int)
+		}
+		w.Symptoms[symptom]++
+	}
+}
+
+// merge adds another summary of the same week
+func (w *AnnotationWeek) merge(other AnnotationWeek) {
+	w.Entries += other.Entries
+	w.MoodTotal += other.MoodTotal
+	w.MoodEntries += other.MoodEntries
+	for tag, count := range other.Tags {
+		if w.Tags == nil {
+			w.Tags = make(map[string]int)
+		}
+		w.Tags[tag] += count
+	}
+	for symptom, count := range other.Symptoms {
+		if w.Symptoms == nil {
+			w.Symptoms = make(map[string]int)
+		}
+		w.Symptoms[symptom] += count
+	}
+}
+
+// Compact summarizes every annotation dated before cutoff (YYYY-MM-DD) per
+// user and week, passes the summaries to keep, and removes the annotations
+// only once keep has stored them, so a failure never loses an entry. It
+// returns the summaries kept.
+func (a *AnnotationStore) Compact(cutoff string, keep func([]AnnotationWeek) error) ([]AnnotationWeek, error) {
+	if err := a.writable(); err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	all := make(map[string][]Annotation)
+	if err := readJSONFile(a.cipher, a.Path, &all); err != nil {
+		return nil, err
+	}
+
+	weeks := make(map[string]*AnnotationWeek)
+	for key, annotations := range all {
+		kept := annotations[:0:0]
+		for _, annotation := range annotations {
+			if annotation.Date >= cutoff {
+				kept = append(kept, annotation)
+				continue
+			}
+			date, err := time.Parse("2006-01-02", annotation.Date)
+			if err != nil {
+				kept = append(kept, annotation)
+				continue
+			}
+			weekStart := dayKey(bucketStart(date, granularityWeekly))
+			week, ok := weeks[key+"/"+weekStart]
+			if !ok {
+				week = &AnnotationWeek{UserID: annotation.UserID, WeekStart: weekStart}
+				weeks[key+"/"+weekStart] = week
+			}
+			week.add(annotation)
+		}
+		all[key] = kept
+	}
+	if len(weeks) == 0 {
+		return nil, nil
+	}
+
+	summaries := make([]AnnotationWeek, 0, len(weeks))
+	for _, week := range weeks {
+		summaries = append(summaries, *week)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].UserID != summaries[j].UserID {
+			return summaries[i].UserID < summaries[j].UserID
+		}
+		return summaries[i].WeekStart < summaries[j].WeekStart
+	})
+	if err := keep(summaries); err != nil {
+		return nil, fmt.Errorf("failed to store weekly summaries: %w", err)
+	}
+	if err := writeJSONFile(a.cipher, a.Path, all); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// AnnotationSummaryStore persists the weekly summaries of journal entries
+// that aged out of retention
+type AnnotationSummaryStore struct {
+	Path string
+	mu   sync.Mutex
+	storeMode
+}
+
+// NewAnnotationSummaryStoreFromEnv uses WHOOP_ANNOTATION_SUMMARIES_FILE or the default datastore path,
+// encrypting it with cipher when it is set
+func NewAnnotationSummaryStoreFromEnv(settings Settings, cipher *DataCipher) (*AnnotationSummaryStore, error) {
+	path, err := localDataPath(settings, "WHOOP_ANNOTATION_SUMMARIES_FILE", "annotation_summaries.json")
+	if err != nil {
+		return nil, err
+	}
+	return &AnnotationSummaryStore{Path: path, storeMode: storeMode{cipher: cipher}}, nil
+}
+
+// Merge adds weekly summaries, combining any with an existing summary of the
+// same user and week
+func (s *AnnotationSummaryStore) Merge(weeks []AnnotationWeek) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all := make(map[string][]AnnotationWeek)
+	if err := readJSONFile(s.cipher, s.Path, &all); err != nil {
+		return err
+	}
+	for _, week := range weeks {
+		key := strconv.Itoa(week.UserID)
+		merged := false
+		for i := range all[key] {
+			if all[key][i].WeekStart == week.WeekStart {
+				all[key][i].merge(week)
+				merged = true
+			}
+		}
+		if !merged {
+			all[key] = append(all[key], week)
+		}
+		sort.Slice(all[key], func(i, j int) bool { return all[key][i].WeekStart < all[key][j].WeekStart })
+	}
+	return writeJSONFile(s.cipher, s.Path, all)
+}
+
+// List returns the user's weekly summaries, oldest first
+func (s *AnnotationSummaryStore) List(userID int) ([]AnnotationWeek, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all := make(map[string][]AnnotationWeek)
+	if err := readJSONFile(s.cipher, s.Path, &all); err != nil {
+		return nil, err
+	}
+	return all[strconv.Itoa(userID)], nil
+}
+
+// PruneBefore drops notification records older than cutoff, which can no
+// longer hold back an alert
+func (n *Notifier) PruneBefore(cutoff time.Time) error {
+	if err := n.writable(); err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	sent := make(map[string]map[string]time.Time)
+	if err := readJSONFile(n.cipher, n.Path, &sent); err != nil {
+		return err
+	}
+	pruned := false
+	for _, types := range sent {
+		for flagType, at := range types {
+			if at.Before(cutoff) {
+				delete(types, flagType)
+				pruned = true
+			}
+		}
+	}
+	if !pruned {
+		return nil
+	}
+	return writeJSONFile(n.cipher, n.Path, sent)
+}
+
+// applyRetention enforces the retention policy on the datastore, logging
+// rather than failing since it runs in the background of normal operation
+func (s *MCPServer) applyRetention(now time.Time) {
+	if s.retention == nil || s.readOnly {
+		return
+	}
+	cutoff := s.retention.Cutoff(now)
+	weeks, err := s.annotations.Compact(dayKey(cutoff), s.annotationSummaries.Merge)
+	if err != nil {
+		log.Printf("Retention: failed to compact journal entries: %v", err)
+	} else if len(weeks) > 0 {
+		log.Printf("Retention: summarized journal entries before %s into %d week(s)", dayKey(cutoff), len(weeks))
+	}
+	if s.notifier != nil {
+		if err := s.notifier.PruneBefore(cutoff); err != nil {
+			log.Printf("Retention: failed to prune notification history: %v", err)
+		}
+	}
+}
+
+// StorageEntry is the on-disk footprint of one datastore entry
+type StorageEntry struct {
+	Path      string    `json:"path"`
+	Files     int       `json:"files"`
+	Bytes     int64     `json:"bytes"`
+	Encrypted bool      `json:"encrypted"` // every file is encrypted
+	Modified  time.Time `json:"modified,omitempty"`
+}
+
+// datastoreFootprint measures every datastore entry; missing ones are reported empty
+func datastoreFootprint(settings Settings) ([]StorageEntry, error) {
+	paths, err := datastorePaths(settings)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]StorageEntry, 0, len(paths))
+	for _, path := range paths {
+		entry := StorageEntry{Path: path, Encrypted: true}
+		filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			entry.Files++
+			entry.Bytes += info.Size()
+			if info.ModTime().After(entry.Modified) {
+				entry.Modified = info.ModTime()
+			}
+			if data, err := os.ReadFile(file); err != nil || !isEncrypted(data) {
+				entry.Encrypted = false
+			}
+			return nil
+		})
+		if entry.Files == 0 {
+			entry.Encrypted = false
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// FormatStorageFootprint renders the datastore footprint and retention settings
+func FormatStorageFootprint(entries []StorageEntry, policy *RetentionPolicy, encrypted bool, now time.Time) string {
+	var builder strings.Builder
+	builder.WriteString("# Local Storage Footprint\n\n")
+	builder.WriteString("| Entry | Files | Size | Encrypted | Last Modified |\n|---|---|---|---|---|\n")
+	var files int
+	var total int64
+	for _, entry := range entries {
+		modified, encryptedLabel := "-", "-"
+		if entry.Files > 0 {
+			modified = entry.Modified.Format("2006-01-02")
+			encryptedLabel = "no"
+			if entry.Encrypted {
+				encryptedLabel = "yes"
+			}
+		}
+		builder.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %s |\n", entry.Path, entry.Files, formatBytes(entry.Bytes), encryptedLabel, modified))
+		files += entry.Files
+		total += entry.Bytes
+	}
+	builder.WriteString(fmt.Sprintf("\n**Total:** %d file(s), %s\n\n", files, formatBytes(total)))
+
+	builder.WriteString("## Policy\n")
+	if policy == nil {
+		builder.WriteString("- **Retention:** keep everything (set WHOOP_RETENTION_MONTHS to limit it)\n")
+	} else {
+		builder.WriteString(fmt.Sprintf("- **Retention:** %d months; journal entries before %s are kept only as weekly summaries\n",
+			policy.Months, dayKey(policy.Cutoff(now))))
+	}
+	if encrypted {
+		builder.WriteString("- **Encryption:** on; files written from now on are encrypted\n")
+	} else {
+		builder.WriteString("- **Encryption:** off (set WHOOP_DATA_ENCRYPTION to keyring or passphrase)\n")
+	}
+	builder.WriteString("- Whoop records are fetched live and are not stored locally. Run `whoop-mcp-server purge -yes` to wipe this data.")
+	return builder.String()
+}
+
+// formatBytes renders a size in B, KB, or MB
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnnotationRetention(t *testing.T) {
+	dir := t.TempDir()
+	annotations := &AnnotationStore{Path: filepath.Join(dir, "annotations.json")}
+	summaries := &AnnotationSummaryStore{Path: filepath.Join(dir, "annotation_summaries.json")}
+	mood := func(v int) *int { return &v }
+	for _, annotation := range []Annotation{
+		{UserID: 1, Date: "2022-12-05", Note: "argument with manager", Mood: mood(3), Tags: []string{"Work"}},
+		{UserID: 1, Date: "2022-12-07", Note: "better day", Mood: mood(7), Tags: []string{"work"}, Symptoms: map[string]int{"headache": 4}},
+		{UserID: 1, Date: "2022-12-14", Note: "travel"},
+		{UserID: 1, Date: "2024-06-03", Note: "recent"},
+	} {
+		if _, err := annotations.Add(annotation); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	policy := &RetentionPolicy{Months: 18}
+	now := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+	cutoff := policy.Cutoff(now)
+	if dayKey(cutoff) != "2022-12-12" {
+		t.Fatalf("Cutoff() = %s, want the Monday on or before 2022-12-12", dayKey(cutoff))
+	}
+
+	// A failure to store the summaries leaves the journal untouched
+	if _, err := annotations.Compact(dayKey(cutoff), func([]AnnotationWeek) error { return errors.New("disk full") }); err == nil {
+		t.Fatal("expected Compact() to report the failed merge")
+	}
+	if all, _ := annotations.List(1, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), now); len(all) != 4 {
+		t.Fatalf("journal kept %d entries after the failed merge, want all 4", len(all))
+	}
+
+	weeks, err := annotations.Compact(dayKey(cutoff), summaries.Merge)
+	if err != nil {
+		t.Fatalf("Compact() error: %v", err)
+	}
+	if len(weeks) != 1 || weeks[0].WeekStart != "2022-12-05" || weeks[0].Entries != 2 ||
+		weeks[0].MoodAverage() != 5 || weeks[0].Tags["work"] != 2 || weeks[0].Symptoms["headache"] != 1 {
+		t.Errorf("weeks = %+v, want one summarized week with both entries", weeks)
+	}
+	// A late backfilled entry for the same week merges into its summary
+	if err := summaries.Merge([]AnnotationWeek{{UserID: 1, WeekStart: "2022-12-05", Entries: 1, MoodTotal: 8, MoodEntries: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := summaries.List(1)
+	if len(stored) != 1 || stored[0].Entries != 3 || stored[0].MoodAverage() != 6 {
+		t.Errorf("stored summaries = %+v", stored)
+	}
+
+	kept, _ := annotations.List(1, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), now)
+	if len(kept) != 2 || kept[0].Date != "2022-12-14" {
+		t.Errorf("kept = %+v, want the entries from the cutoff week on", kept)
+	}
+	raw, _ := os.ReadFile(summaries.Path)
+	if strings.Contains(string(raw), "manager") {
+		t.Error("weekly summaries should not keep free-text notes")
+	}
+}
+
+func TestLoadRetentionPolicyFromEnv(t *testing.T) {
+	if policy, err := LoadRetentionPolicyFromEnv(os.Getenv); policy != nil || err != nil {
+		t.Errorf("default = %+v, %v, want no policy", policy, err)
+	}
+	t.Setenv("WHOOP_RETENTION_MONTHS", "18")
+	if policy, err := LoadRetentionPolicyFromEnv(os.Getenv); err != nil || policy.Months != 18 {
+		t.Errorf("got %+v, %v, want 18 months", policy, err)
+	}
+	t.Setenv("WHOOP_RETENTION_MONTHS", "-3")
+	if _, err := LoadRetentionPolicyFromEnv(os.Getenv); err == nil {
+		t.Error("expected a negative retention to fail")
+	}
+}
+
+func TestFormatStorageFootprint(t *testing.T) {
+	entries := []StorageEntry{
+		{Path: "/data/annotations.json", Files: 1, Bytes: 2048, Encrypted: true, Modified: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)},
+		{Path: "/data/reports"},
+	}
+	got := FormatStorageFootprint(entries, &RetentionPolicy{Months: 18}, true, time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC))
+	for _, want := range []string{
+		"| /data/annotations.json | 1 | 2.0 KB | yes | 2024-06-10 |",
+		"| /data/reports | 0 | 0 B | - | - |",
+		"**Total:** 1 file(s), 2.0 KB",
+		"18 months; journal entries before 2022-12-12",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("footprint missing %q:\n%s", want, got)
+		}
+	}
+}
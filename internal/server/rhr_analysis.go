@@ -0,0 +1,280 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+const (
+	// rhrElevationMinBPM is the smallest rise over baseline counted as an overnight elevation
+	rhrElevationMinBPM = 3.0
+	// lateWorkoutWindow is how close to sleep onset a workout must end to count as late
+	lateWorkoutWindow = 3 * time.Hour
+	// highDayStrain marks a day whose strain is likely to raise the next morning's RHR
+	highDayStrain = 14.0
+)
+
+// RHRElevation is a morning whose resting heart rate was well above baseline
+type RHRElevation struct {
+	Date        time.Time `json:"date"`
+	RestingHR   float64   `json:"resting_hr"`
+	AboveBy     float64   `json:"above_baseline_by"`
+	LateWorkout bool      `json:"late_workout"`
+	PriorStrain float64   `json:"prior_day_strain"`
+	Explanation string    `json:"explanation"`
+}
+
+// PeriodDelta compares the most recent window with the one before it
+type PeriodDelta struct {
+	Current  float64 `json:"current"`
+	Previous float64 `json:"previous"`
+	Change   float64 `json:"change"`
+	Valid    bool    `json:"valid"`
+}
+
+// RHRAnalysis summarizes resting heart rate drift and overnight elevations
+type RHRAnalysis struct {
+	Days               int            `json:"days"`
+	Baseline           float64        `json:"baseline_bpm"`
+	BaselineStdDev     float64        `json:"baseline_std_dev_bpm"`
+	DriftPerWeek       float64        `json:"drift_bpm_per_week"`
+	DriftRSquared      float64        `json:"drift_r_squared"`
+	Latest             float64        `json:"latest_bpm"`
+	Elevations         []RHRElevation `json:"elevations"`
+	LateStrainMornings int            `json:"late_strain_mornings"`
+	LateStrainRHR      float64        `json:"late_strain_mean_rhr"`
+	OtherMornings      int            `json:"other_mornings"`
+	OtherRHR           float64        `json:"other_mean_rhr"`
+	WeekOverWeek       PeriodDelta    `json:"week_over_week"`
+	MonthOverMonth     PeriodDelta    `json:"month_over_month"`
+	Status             string         `json:"status"` // "no_data", "rising", "falling", "stable"
+}
+
+// AnalyzeRestingHR tracks RHR baseline drift and attributes overnight
+// elevations to late training or a hard prior day where possible. Elevations
+// without either are left unexplained; alcohol, illness, late meals, and
+// stress are the usual suspects.
+func (h *HealthAnalyzer) AnalyzeRestingHR(recoveries []WhoopRecovery, workouts []WhoopWorkout, cycles []WhoopCycle) RHRAnalysis {
+	var series []datedValue
+	var scored []WhoopRecovery
+	for _, recovery := range recoveries {
+		if recovery.ScoreState != "" && recovery.ScoreState != "SCORED" {
+			continue
+		}
+		if recovery.Score.RestingHeartRate <= 0 {
+			continue
+		}
+		scored = append(scored, recovery)
+	}
+	sort.Slice(scored, func(i, j int) bool {
+		return scored[i].CreatedAt.Before(scored[j].CreatedAt)
+	})
+	for _, recovery := range scored {
+		series = append(series, datedValue{Date: recovery.CreatedAt, Value: recovery.Score.RestingHeartRate})
+	}
+
+	if len(series) == 0 {
+		return RHRAnalysis{Status: "no_data"}
+	}
+
+	values := valuesOf(series)
+	analysis := RHRAnalysis{
+		Days:           len(values),
+		Baseline:       h.calculateMean(values),
+		BaselineStdDev: h.calculateStdDev(values),
+		Latest:         values[len(values)-1],
+		Elevations:     []RHRElevation{},
+	}
+
+	// Drift: least-squares slope of RHR against elapsed days
+	xs := make([]float64, len(series))
+	for i, v := range series {
+		xs[i] = v.Date.Sub(series[0].Date).Hours() / 24
+	}
+	slope, _, rSquared := stats.LinearRegression(xs, values)
+	analysis.DriftPerWeek = slope * 7
+	analysis.DriftRSquared = rSquared
+
+	switch {
+	case len(values) < 7:
+		analysis.Status = "stable"
+	case analysis.DriftPerWeek >= 0.5:
+		analysis.Status = "rising"
+	case analysis.DriftPerWeek <= -0.5:
+		analysis.Status = "falling"
+	default:
+		analysis.Status = "stable"
+	}
+
+	analysis.WeekOverWeek = windowDelta(series, 7)
+	analysis.MonthOverMonth = windowDelta(series, 30)
+
+	// Attribute each morning to late strain (late workout or hard prior day) or not
+	threshold := rhrElevationMinBPM
+	if 1.5*analysis.BaselineStdDev > threshold {
+		threshold = 1.5 * analysis.BaselineStdDev
+	}
+	sortedCycles := make([]WhoopCycle, len(cycles))
+	copy(sortedCycles, cycles)
+	sort.Slice(sortedCycles, func(i, j int) bool {
+		return sortedCycles[i].Start.Before(sortedCycles[j].Start)
+	})
+
+	var lateValues, otherValues []float64
+	for _, recovery := range scored {
+		sleepOnset, priorStrain := priorDayContext(recovery, sortedCycles)
+		lateWorkout := hasWorkoutEndingBefore(workouts, sleepOnset, lateWorkoutWindow)
+		lateStrain := lateWorkout || priorStrain >= highDayStrain
+
+		rhr := recovery.Score.RestingHeartRate
+		if lateStrain {
+			lateValues = append(lateValues, rhr)
+		} else {
+			otherValues = append(otherValues, rhr)
+		}
+
+		if rhr-analysis.Baseline < threshold {
+			continue
+		}
+
+		elevation := RHRElevation{
+			Date:        recovery.CreatedAt,
+			RestingHR:   rhr,
+			AboveBy:     rhr - analysis.Baseline,
+			LateWorkout: lateWorkout,
+			PriorStrain: priorStrain,
+		}
+		switch {
+		case lateWorkout:
+			elevation.Explanation = "late workout"
+		case priorStrain >= highDayStrain:
+			elevation.Explanation = "high strain the day before"
+		default:
+			elevation.Explanation = "unexplained (possible alcohol, illness, late meal, or stress)"
+		}
+		analysis.Elevations = append(analysis.Elevations, elevation)
+	}
+
+	analysis.LateStrainMornings = len(lateValues)
+	analysis.LateStrainRHR = h.calculateMean(lateValues)
+	analysis.OtherMornings = len(otherValues)
+	analysis.OtherRHR = h.calculateMean(otherValues)
+
+	return analysis
+}
+
+// priorDayContext returns the sleep onset preceding a recovery (the start of
+// its physiological cycle) and the strain of the cycle before it
+func priorDayContext(recovery WhoopRecovery, sortedCycles []WhoopCycle) (time.Time, float64) {
+	for i, cycle := range sortedCycles {
+		if cycle.ID != recovery.CycleID {
+			continue
+		}
+		if i > 0 {
+			return cycle.Start, sortedCycles[i-1].Score.Strain
+		}
+		return cycle.Start, 0
+	}
+	// Without the cycle, approximate sleep onset from the recovery timestamp
+	return recovery.CreatedAt.Add(-8 * time.Hour), 0
+}
+
+// hasWorkoutEndingBefore reports whether a workout ended within window before t
+func hasWorkoutEndingBefore(workouts []WhoopWorkout, t time.Time, window time.Duration) bool {
+	for _, workout := range workouts {
+		if !workout.End.After(t) && t.Sub(workout.End) <= window {
+			return true
+		}
+	}
+	return false
+}
+
+// windowDelta compares the mean of the last n days of a series with the n days before
+func windowDelta(series []datedValue, n int) PeriodDelta {
+	if len(series) == 0 {
+		return PeriodDelta{}
+	}
+	end := series[len(series)-1].Date
+	currentStart := end.AddDate(0, 0, -n)
+	previousStart := end.AddDate(0, 0, -2*n)
+
+	var current, previous []float64
+	for _, v := range series {
+		switch {
+		case v.Date.After(currentStart):
+			current = append(current, v.Value)
+		case v.Date.After(previousStart):
+			previous = append(previous, v.Value)
+		}
+	}
+	if len(current) == 0 || len(previous) == 0 {
+		return PeriodDelta{}
+	}
+
+	currentMean, _ := stats.SampleMeanVariance(current)
+	previousMean, _ := stats.SampleMeanVariance(previous)
+	return PeriodDelta{
+		Current:  currentMean,
+		Previous: previousMean,
+		Change:   currentMean - previousMean,
+		Valid:    true,
+	}
+}
+
+// FormatRHRAnalysis renders the resting heart rate trend report
+func (h *HealthAnalyzer) FormatRHRAnalysis(analysis RHRAnalysis) string {
+	if analysis.Status == "no_data" {
+		return "# Resting Heart Rate Analysis\n\nNo scored resting heart rate readings were found for this period."
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("# Resting Heart Rate Analysis (%d mornings)\n\n", analysis.Days))
+
+	builder.WriteString("## Baseline & Drift\n")
+	builder.WriteString(fmt.Sprintf("- **Baseline:** %.1f bpm (±%.1f)\n", analysis.Baseline, analysis.BaselineStdDev))
+	builder.WriteString(fmt.Sprintf("- **Latest:** %.0f bpm\n", analysis.Latest))
+	builder.WriteString(fmt.Sprintf("- **Drift:** %+.2f bpm/week (%s, R² %.2f)\n\n", analysis.DriftPerWeek, analysis.Status, analysis.DriftRSquared))
+
+	builder.WriteString("## Period Summaries\n")
+	builder.WriteString(formatPeriodDelta("Week over week", analysis.WeekOverWeek))
+	builder.WriteString(formatPeriodDelta("Month over month", analysis.MonthOverMonth))
+	builder.WriteString("\n")
+
+	builder.WriteString("## Late Strain Effect\n")
+	if analysis.LateStrainMornings > 0 && analysis.OtherMornings > 0 {
+		builder.WriteString(fmt.Sprintf("- After late workouts or high-strain days (%d mornings): %.1f bpm\n", analysis.LateStrainMornings, analysis.LateStrainRHR))
+		builder.WriteString(fmt.Sprintf("- Other mornings (%d): %.1f bpm\n", analysis.OtherMornings, analysis.OtherRHR))
+		builder.WriteString(fmt.Sprintf("- **Difference:** %+.1f bpm\n\n", analysis.LateStrainRHR-analysis.OtherRHR))
+	} else {
+		builder.WriteString("Not enough contrast between late-strain and other mornings to estimate an effect.\n\n")
+	}
+
+	builder.WriteString(fmt.Sprintf("## Overnight Elevations (%d)\n", len(analysis.Elevations)))
+	if len(analysis.Elevations) == 0 {
+		builder.WriteString("No mornings stood out above the usual range.\n")
+	}
+	unexplained := 0
+	for _, elevation := range analysis.Elevations {
+		builder.WriteString(fmt.Sprintf("- %s: %.0f bpm (+%.1f) — %s\n", elevation.Date.Format("Mon Jan 2"), elevation.RestingHR, elevation.AboveBy, elevation.Explanation))
+		if !elevation.LateWorkout && elevation.PriorStrain < highDayStrain {
+			unexplained++
+		}
+	}
+	if unexplained > 0 {
+		builder.WriteString(fmt.Sprintf("\n%d elevation(s) had no late workout or hard prior day. Evening alcohol, late meals, illness onset, and emotional stress are common causes worth asking about.\n", unexplained))
+	}
+
+	return builder.String()
+}
+
+// formatPeriodDelta renders one line of a period-over-period comparison
+func formatPeriodDelta(label string, delta PeriodDelta) string {
+	if !delta.Valid {
+		return fmt.Sprintf("- **%s:** not enough history\n", label)
+	}
+	return fmt.Sprintf("- **%s:** %.1f → %.1f bpm (%+.1f)\n", label, delta.Previous, delta.Current, delta.Change)
+}
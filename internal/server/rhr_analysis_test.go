@@ -0,0 +1,54 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_AnalyzeRestingHR(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	start := time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)
+
+	var recoveries []WhoopRecovery
+	var cycles []WhoopCycle
+	rhr := []float64{52, 53, 52, 51, 52, 53, 52, 62, 52, 53, 52, 61, 52, 53}
+	for i, value := range rhr {
+		var cycle WhoopCycle
+		cycle.ID = int64(i + 1)
+		cycle.Start = start.AddDate(0, 0, i)
+		cycle.End = cycle.Start.AddDate(0, 0, 1)
+		cycle.Score.Strain = 8
+		cycles = append(cycles, cycle)
+
+		var recovery WhoopRecovery
+		recovery.CycleID = cycle.ID
+		recovery.ScoreState = "SCORED"
+		recovery.CreatedAt = cycle.Start.Add(8 * time.Hour)
+		recovery.Score.RestingHeartRate = value
+		recoveries = append(recoveries, recovery)
+	}
+
+	// A workout ending an hour before sleep on the night before day 7
+	var workout WhoopWorkout
+	workout.Start = cycles[7].Start.Add(-2 * time.Hour)
+	workout.End = cycles[7].Start.Add(-time.Hour)
+
+	analysis := analyzer.AnalyzeRestingHR(recoveries, []WhoopWorkout{workout}, cycles)
+
+	if len(analysis.Elevations) != 2 {
+		t.Fatalf("Expected 2 elevations, got %d: %+v", len(analysis.Elevations), analysis.Elevations)
+	}
+	if analysis.Elevations[0].Explanation != "late workout" {
+		t.Errorf("Expected first elevation explained by late workout, got %q", analysis.Elevations[0].Explanation)
+	}
+	if !strings.HasPrefix(analysis.Elevations[1].Explanation, "unexplained") {
+		t.Errorf("Expected second elevation unexplained, got %q", analysis.Elevations[1].Explanation)
+	}
+	if analysis.LateStrainMornings != 1 {
+		t.Errorf("Expected 1 late-strain morning, got %d", analysis.LateStrainMornings)
+	}
+	if !analysis.WeekOverWeek.Valid {
+		t.Error("Expected a week-over-week comparison")
+	}
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+// Every analysis ends with the last week of each daily metric set against
+// the user's 4-week norm, the 28 days up to and including today
+const (
+	rollingWeekDays = 7
+	rollingNormDays = 28
+)
+
+// A metric needs minRollingWeekDays days this week and minRollingNormDays in
+// the norm window to be compared
+const (
+	minRollingWeekDays = 3
+	minRollingNormDays = 10
+)
+
+// rollingSteadyPercent is the change from the norm, in percent, below which a
+// week reads as steady
+const rollingSteadyPercent = 5.0
+
+// RollingComparison sets one metric's last 7 days against its 28-day norm
+type RollingComparison struct {
+	Metric        string  `json:"metric"`
+	Label         string  `json:"label"`
+	Unit          string  `json:"unit"`
+	Week          float64 `json:"week"`
+	Norm          float64 `json:"norm"`
+	Delta         float64 `json:"delta"`
+	PercentChange float64 `json:"percent_change"`
+	WeekDays      int     `json:"week_days"`
+	NormDays      int     `json:"norm_days"`
+	Direction     string  `json:"direction"` // "better", "worse", "steady"
+}
+
+// CompareRollingWindows compares each daily metric's mean over the 7 local
+// days ending on today with its mean over the 28 days ending on today,
+// skipping metrics with too few days in either window
+func (h *HealthAnalyzer) CompareRollingWindows(data *HealthData, today time.Time) []RollingComparison {
+	last := dayKey(today)
+	weekFirst := dayKey(today.AddDate(0, 0, -(rollingWeekDays - 1)))
+	normFirst := dayKey(today.AddDate(0, 0, -(rollingNormDays - 1)))
+
+	var comparisons []RollingComparison
+	for _, metric := range dailyMetrics {
+		var week, norm []float64
+		for _, value := range metric.extract(data) {
+			day := dayKey(value.Date)
+			if day < normFirst || day > last {
+				continue
+			}
+			norm = append(norm, value.Value)
+			if day >= weekFirst {
+				week = append(week, value.Value)
+			}
+		}
+		if len(week) < minRollingWeekDays || len(norm) < minRollingNormDays {
+			continue
+		}
+
+		comparison := RollingComparison{
+			Metric:    metric.Key,
+			Label:     metric.Label,
+			Unit:      metric.Unit,
+			Week:      h.calculateMean(week),
+			Norm:      h.calculateMean(norm),
+			WeekDays:  len(week),
+			NormDays:  len(norm),
+			Direction: "steady",
+		}
+		comparison.Delta = comparison.Week - comparison.Norm
+		if comparison.Norm != 0 {
+			comparison.PercentChange = comparison.Delta / math.Abs(comparison.Norm) * 100
+		}
+		if math.Abs(comparison.PercentChange) >= rollingSteadyPercent {
+			if (comparison.Delta > 0) == metric.HigherIsBetter {
+				comparison.Direction = "better"
+			} else {
+				comparison.Direction = "worse"
+			}
+		}
+		comparisons = append(comparisons, comparison)
+	}
+	return comparisons
+}
+
+// FormatRollingComparison renders the "this week vs your 4-week norm" section,
+// or an empty string when no metric has enough data
+func FormatRollingComparison(comparisons []RollingComparison, today time.Time) string {
+	if len(comparisons) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString("## This Week vs Your 4-Week Norm\n\n")
+	builder.WriteString(fmt.Sprintf("_Last %d days (%s to %s) against the %d days ending %s._\n\n", rollingWeekDays,
+		dayKey(today.AddDate(0, 0, -(rollingWeekDays-1))), dayKey(today), rollingNormDays, dayKey(today)))
+	builder.WriteString("| Metric | This Week | 4-Week Norm | Change | |\n")
+	builder.WriteString("|---|---|---|---|---|\n")
+	for _, c := range comparisons {
+		marker := ""
+		switch c.Direction {
+		case "better":
+			marker = "✅ better"
+		case "worse":
+			marker = "⚠️ worse"
+		}
+		builder.WriteString(fmt.Sprintf("| %s | %.1f%s | %.1f%s | %+.1f%s (%+.0f%%) | %s |\n",
+			c.Label, c.Week, c.Unit, c.Norm, c.Unit, c.Delta, c.Unit, c.PercentChange, marker))
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// withRollingComparison appends how the last week compares with the user's
+// 4-week norm, fetched separately so every report has it whatever its range.
+// Datasets that fail to fetch are logged and their metrics left out.
+func (s *MCPServer) withRollingComparison(ctx context.Context, report string, userID *int) string {
+	now := s.now()
+	first := now.AddDate(0, 0, -(rollingNormDays - 1))
+	start := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, now.Location())
+
+	data, gaps := s.fetchHealthDataPartial(ctx, start, now, userID)
+	for _, gap := range gaps {
+		log.Printf("Rolling comparison is missing %s: %v", gap.Dataset, gap.Err)
+	}
+	data, _ = scoredHealthData(data)
+
+	if section := FormatRollingComparison(s.healthAnalyzer.CompareRollingWindows(data, now), now); section != "" {
+		return report + "\n\n" + section
+	}
+	return report
+}
+
+// withReportContext appends the personal context every analysis ends with:
+// the rolling week-vs-norm comparison and the user's annotations
+func (s *MCPServer) withReportContext(ctx context.Context, report string, startDate, endDate time.Time, userID *int) string {
+	return s.withAnnotations(s.withRollingComparison(ctx, report, userID), startDate, endDate, userID)
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareRollingWindows(t *testing.T) {
+	// Four weeks at recovery 60 except the last week at 80
+	recoveries, sleeps, cycles := stressScenario(28, func(i int) (float64, float64, float64, float64, float64) {
+		recovery := 60.0
+		if i >= 21 {
+			recovery = 80
+		}
+		return 60, 52, recovery, 8, 10
+	})
+	data := &HealthData{Recoveries: recoveries, Sleeps: sleeps, Cycles: cycles}
+	today := time.Date(2024, 6, 28, 20, 0, 0, 0, time.UTC)
+
+	comparisons := NewHealthAnalyzer().CompareRollingWindows(data, today)
+	byMetric := make(map[string]RollingComparison)
+	for _, c := range comparisons {
+		byMetric[c.Metric] = c
+	}
+
+	recovery, ok := byMetric["recovery"]
+	if !ok {
+		t.Fatalf("no recovery comparison in %+v", comparisons)
+	}
+	if recovery.WeekDays != 7 || recovery.NormDays != 28 {
+		t.Errorf("recovery days = %d/%d, want 7/28", recovery.WeekDays, recovery.NormDays)
+	}
+	if recovery.Week != 80 || recovery.Norm != 65 || recovery.Delta != 15 {
+		t.Errorf("recovery = %.1f vs %.1f (%+.1f), want 80 vs 65 (+15)", recovery.Week, recovery.Norm, recovery.Delta)
+	}
+	if math.Abs(recovery.PercentChange-100.0*15/65) > 0.01 || recovery.Direction != "better" {
+		t.Errorf("recovery change = %+.1f%% %s, want +23.1%% better", recovery.PercentChange, recovery.Direction)
+	}
+	if strain := byMetric["strain"]; strain.Direction != "steady" || strain.Delta != 0 {
+		t.Errorf("strain = %+v, want steady", strain)
+	}
+
+	section := FormatRollingComparison(comparisons, today)
+	for _, want := range []string{
+		"## This Week vs Your 4-Week Norm",
+		"_Last 7 days (2024-06-22 to 2024-06-28) against the 28 days ending 2024-06-28._",
+		"| Recovery | 80.0% | 65.0% | +15.0% (+23%) | ✅ better |",
+	} {
+		if !strings.Contains(section, want) {
+			t.Errorf("section missing %q:\n%s", want, section)
+		}
+	}
+}
+
+func TestCompareRollingWindowsSkipsSparseMetrics(t *testing.T) {
+	recoveries, sleeps, cycles := stressScenario(14, func(i int) (float64, float64, float64, float64, float64) { return 60, 52, 70, 8, 10 })
+	data := &HealthData{Recoveries: recoveries, Sleeps: sleeps, Cycles: cycles}
+
+	// Nothing recorded in the last 10 days leaves no week to compare
+	today := time.Date(2024, 6, 24, 20, 0, 0, 0, time.UTC)
+	if comparisons := NewHealthAnalyzer().CompareRollingWindows(data, today); len(comparisons) != 0 {
+		t.Errorf("CompareRollingWindows() = %+v, want none", comparisons)
+	}
+	if section := FormatRollingComparison(nil, today); section != "" {
+		t.Errorf("FormatRollingComparison(nil) = %q, want empty", section)
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// crisisTerms mark recommendations that point toward medical or mental-health
+// care, in every shipped language. Red flags whose recommendation mentions one
+// carry the safety notice whatever their severity.
+var crisisTerms = []string{
+	"medical", "depression", "anxiety", "eating-disorder", "self-harm", "suicid", "emergency",
+	"médic", "depresi", "ansiedad", "trastorno alimentario", "autolesi", "emergencia",
+}
+
+// SafetyConfig controls the "not medical advice" notice placed before serious
+// red flags. The notice text comes from the safety_notice template, so
+// deployments reword it with a template override.
+type SafetyConfig struct {
+	// MinSeverity is the lowest red flag severity that always gets the notice
+	MinSeverity string
+}
+
+// LoadSafetyConfigFromEnv reads WHOOP_SAFETY_NOTICES (on by default; off for
+// clinical deployments where a clinician already reviews every report) and
+// WHOOP_SAFETY_SEVERITY (critical, high, or moderate; default critical). It
+// returns nil when notices are off.
+func LoadSafetyConfigFromEnv(settings Settings) (*SafetyConfig, error) {
+	switch toggle := strings.ToLower(strings.TrimSpace(settings("WHOOP_SAFETY_NOTICES"))); toggle {
+	case "off":
+		return nil, nil
+	case "", "on":
+	default:
+		return nil, fmt.Errorf("unknown WHOOP_SAFETY_NOTICES %q (expected on or off)", toggle)
+	}
+
+	severity := strings.ToLower(strings.TrimSpace(settings("WHOOP_SAFETY_SEVERITY")))
+	if severity == "" {
+		severity = "critical"
+	}
+	if _, ok := redFlagSeverityRank[severity]; !ok {
+		return nil, fmt.Errorf("unknown WHOOP_SAFETY_SEVERITY %q (expected critical, high, or moderate)", severity)
+	}
+	return &SafetyConfig{MinSeverity: severity}, nil
+}
+
+// needsNotice reports whether a red flag is severe enough, or its
+// recommendation close enough to crisis territory, to carry the notice
+func (c *SafetyConfig) needsNotice(flag RedFlag) bool {
+	if redFlagSeverityRank[flag.Severity] >= redFlagSeverityRank[c.MinSeverity] {
+		return true
+	}
+	recommendation := strings.ToLower(flag.Recommendation)
+	for _, term := range crisisTerms {
+		if strings.Contains(recommendation, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// reviewRedFlags sets the safety notice on each flag that needs one, rendered
+// in the analyzer's language. Nothing changes when notices are off.
+func (h *HealthAnalyzer) reviewRedFlags(flags []RedFlag) []RedFlag {
+	if h.safety == nil {
+		return flags
+	}
+	for i, flag := range flags {
+		if h.safety.needsNotice(flag) {
+			flags[i].SafetyNotice = h.templates.Render(h.locale, "safety_notice", flag)
+		}
+	}
+	return flags
+}
+
+// formatSafetyNotice renders a flag's safety notice as a quote to place
+// before it, or nothing when the flag has none
+func formatSafetyNotice(flag RedFlag) string {
+	if flag.SafetyNotice == "" {
+		return ""
+	}
+	return "> " + strings.ReplaceAll(flag.SafetyNotice, "\n", "\n> ") + "\n"
+}
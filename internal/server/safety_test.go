@@ -0,0 +1,68 @@
+package server
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReviewRedFlags(t *testing.T) {
+	flags := []RedFlag{
+		{Type: "severe_sleep_deprivation", Severity: "critical", Recommendation: "Immediate sleep assessment and intervention required"},
+		{Type: "respiratory_rate_spike", Severity: "moderate", Recommendation: "Monitor for symptoms and prioritize rest"},
+		{Type: "spo2_drop", Severity: "moderate", Recommendation: "Persistently low SpO2 warrants medical attention"},
+	}
+
+	analyzer := NewHealthAnalyzer()
+	reviewed := analyzer.reviewRedFlags(append([]RedFlag(nil), flags...))
+	if !strings.Contains(reviewed[0].SafetyNotice, "not medical advice") || !strings.Contains(reviewed[0].SafetyNotice, "soon") {
+		t.Errorf("critical flag notice = %q, want the urgent notice", reviewed[0].SafetyNotice)
+	}
+	if reviewed[1].SafetyNotice != "" {
+		t.Errorf("moderate flag without crisis language got a notice: %q", reviewed[1].SafetyNotice)
+	}
+	if reviewed[2].SafetyNotice == "" || strings.Contains(reviewed[2].SafetyNotice, "soon") {
+		t.Errorf("moderate flag recommending medical attention notice = %q, want the non-urgent notice", reviewed[2].SafetyNotice)
+	}
+
+	report := analyzer.FormatInsightsForTherapy(&HealthSummary{RedFlags: reviewed})
+	if !strings.Contains(report, "> ⚕️ **This is not medical advice.**") ||
+		strings.Index(report, "not medical advice") > strings.Index(report, "Severe Sleep Deprivation") {
+		t.Errorf("therapy report should open the critical flag with the notice:\n%s", report)
+	}
+
+	localizer, err := NewLocalizer("es")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spanish := analyzer.WithLocale(localizer).reviewRedFlags(append([]RedFlag(nil), flags...))
+	if !strings.Contains(spanish[0].SafetyNotice, "no es consejo médico") {
+		t.Errorf("Spanish notice = %q", spanish[0].SafetyNotice)
+	}
+
+	analyzer.safety = nil
+	if off := analyzer.reviewRedFlags(append([]RedFlag(nil), flags...)); off[0].SafetyNotice != "" {
+		t.Errorf("notices off still set %q", off[0].SafetyNotice)
+	}
+}
+
+func TestLoadSafetyConfigFromEnv(t *testing.T) {
+	config, err := LoadSafetyConfigFromEnv(os.Getenv)
+	if err != nil || config == nil || config.MinSeverity != "critical" {
+		t.Errorf("default = %+v, %v, want notices on at critical", config, err)
+	}
+
+	t.Setenv("WHOOP_SAFETY_SEVERITY", "high")
+	if config, _ := LoadSafetyConfigFromEnv(os.Getenv); config.MinSeverity != "high" {
+		t.Errorf("MinSeverity = %q, want high", config.MinSeverity)
+	}
+	t.Setenv("WHOOP_SAFETY_SEVERITY", "severe")
+	if _, err := LoadSafetyConfigFromEnv(os.Getenv); err == nil {
+		t.Error("expected an unknown severity to fail")
+	}
+
+	t.Setenv("WHOOP_SAFETY_NOTICES", "off")
+	if config, err := LoadSafetyConfigFromEnv(os.Getenv); config != nil || err != nil {
+		t.Errorf("off = %+v, %v, want nil", config, err)
+	}
+}
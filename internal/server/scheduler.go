@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	scheduleWeekly  = "weekly"
+	scheduleMonthly = "monthly"
+
+	// scheduledReportHour is the local hour reports are generated after a period ends
+	scheduledReportHour = 6
+	// scheduleRetryDelay is how long to wait after a failed run
+	scheduleRetryDelay = time.Hour
+)
+
+// ReportArchive is the directory scheduled reports are written to
+type ReportArchive struct {
+	Dir string
+	storeMode
+}
+
+// NewReportArchiveFromEnv uses WHOOP_REPORTS_DIR or a reports directory in
+// the default datastore, encrypting reports with cipher when it is set
+func NewReportArchiveFromEnv(settings Settings, cipher *DataCipher) (*ReportArchive, error) {
+	dir, err := localDataPath(settings, "WHOOP_REPORTS_DIR", "reports")
+	if err != nil {
+		return nil, err
+	}
+	return &ReportArchive{Dir: dir, storeMode: storeMode{cipher: cipher}}, nil
+}
+
+// Exists reports whether a report has already been written
+func (a *ReportArchive) Exists(name string) bool {
+	_, err := os.Stat(filepath.Join(a.Dir, name))
+	return err == nil
+}
+
+// Write stores a report and returns its path
+func (a *ReportArchive) Write(name, content string) (string, error) {
+	if err := a.writable(); err != nil {
+		return "", err
+	}
+	path := filepath.Join(a.Dir, name)
+	data, err := sealDatastore(a.cipher, []byte(content))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %w", name, err)
+	}
+	if err := writePrivateFile(path, data); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Latest returns the name and content of the most recently written report
+func (a *ReportArchive) Latest() (string, string, error) {
+	entries, err := os.ReadDir(a.Dir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", "", fmt.Errorf("failed to read reports directory: %w", err)
+	}
+
+	type report struct {
+		name    string
+		modTime time.Time
+	}
+	var reports []report
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		reports = append(reports, report{entry.Name(), info.ModTime()})
+	}
+	if len(reports) == 0 {
+		return "", "", fmt.Errorf("no reports in %s yet; run the server with --schedule weekly or --schedule monthly", a.Dir)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		if !reports[i].modTime.Equal(reports[j].modTime) {
+			return reports[i].modTime.After(reports[j].modTime)
+		}
+		return reports[i].name > reports[j].name
+	})
+	content, err := os.ReadFile(filepath.Join(a.Dir, reports[0].name))
+	if err == nil {
+		content, err = openDatastore(a.cipher, content)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %w", reports[0].name, err)
+	}
+	return reports[0].name, string(content), nil
+}
+
+// lastCompletedPeriod returns the start and archive file name of the most
+// recent week or month that has fully ended at now
+func lastCompletedPeriod(frequency string, now time.Time) (time.Time, string) {
+	if frequency == scheduleMonthly {
+		start := bucketStart(now, granularityMonthly).AddDate(0, -1, 0)
+		return start, fmt.Sprintf("monthly-%s.md", start.Format("2006-01"))
+	}
+	start := bucketStart(now, granularityWeekly).AddDate(0, 0, -7)
+	return start, fmt.Sprintf("weekly-%s.md", dayKey(start))
+}
+
+// nextScheduledRun returns the next report time after now: Monday morning for
+// weekly reports, the first of the month for monthly ones
+func nextScheduledRun(frequency string, now time.Time) time.Time {
+	day := time.Date(now.Year(), now.Month(), now.Day(), scheduledReportHour, 0, 0, 0, now.Location())
+	if frequency == scheduleMonthly {
+		next := time.Date(now.Year(), now.Month(), 1, scheduledReportHour, 0, 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 1, 0)
+		}
+		return next
+	}
+	daysUntilMonday := (8 - int(day.Weekday())) % 7
+	next := day.AddDate(0, 0, daysUntilMonday)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
+}
+
+// ReportScheduler writes a report for each completed week or month
+type ReportScheduler struct {
+	server    *MCPServer
+	archive   *ReportArchive
+	frequency string
+}
+
+// NewReportScheduler validates the frequency and archive for scheduler mode
+func NewReportScheduler(server *MCPServer, frequency string) (*ReportScheduler, error) {
+	if frequency != scheduleWeekly && frequency != scheduleMonthly {
+		return nil, fmt.Errorf("unsupported schedule %q (use weekly or monthly)", frequency)
+	}
+	return &ReportScheduler{server: server, archive: server.reports, frequency: frequency}, nil
+}
+
+// RunOnce writes the report for the last completed period unless it already
+// exists, returning the path written or an empty string
+func (r *ReportScheduler) RunOnce(now time.Time) (string, error) {
+	start, name := lastCompletedPeriod(r.frequency, now)
+	if r.archive.Exists(name) {
+		return "", nil
+	}
+
+	var content string
+	if r.frequency == scheduleMonthly {
+		report, err := r.server.monthlyReport(context.Background(), start, nil)
+		if err != nil {
+			return "", err
+		}
+		content = report
+	} else {
+		report, err := r.server.weeklyReport(context.Background(), r.server.healthAnalyzer, start, nil)
+		if err != nil {
+			return "", err
+		}
+		content = r.server.healthAnalyzer.FormatWeeklyReport(report)
+	}
+	return r.archive.Write(name, content)
+}
+
+// Run generates any missed report, then sleeps until each next period ends.
+// It runs until the process exits.
+func (r *ReportScheduler) Run() {
+	log.Printf("Scheduler mode: writing %s reports to %s", r.frequency, r.archive.Dir)
+	for {
+		now := time.Now()
+		wait := time.Until(nextScheduledRun(r.frequency, now))
+		r.server.applyRetention(now)
+
+		path, err := r.RunOnce(now)
+		switch {
+		case err != nil:
+			log.Printf("Failed to generate %s report: %v (retrying in %s)", r.frequency, err, scheduleRetryDelay)
+			wait = scheduleRetryDelay
+		case path != "":
+			log.Printf("Wrote %s", path)
+		}
+
+		time.Sleep(wait)
+	}
+}
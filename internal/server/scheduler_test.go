@@ -0,0 +1,74 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLastCompletedPeriod(t *testing.T) {
+	now := time.Date(2024, 3, 13, 9, 0, 0, 0, time.UTC) // a Wednesday
+
+	start, name := lastCompletedPeriod(scheduleWeekly, now)
+	if dayKey(start) != "2024-03-04" || name != "weekly-2024-03-04.md" {
+		t.Errorf("weekly = %s %s, want the week of 2024-03-04", dayKey(start), name)
+	}
+
+	start, name = lastCompletedPeriod(scheduleMonthly, now)
+	if dayKey(start) != "2024-02-01" || name != "monthly-2024-02.md" {
+		t.Errorf("monthly = %s %s, want February 2024", dayKey(start), name)
+	}
+}
+
+func TestNextScheduledRun(t *testing.T) {
+	tests := []struct {
+		frequency string
+		now       time.Time
+		want      time.Time
+	}{
+		{scheduleWeekly, time.Date(2024, 3, 13, 9, 0, 0, 0, time.UTC), time.Date(2024, 3, 18, 6, 0, 0, 0, time.UTC)},
+		{scheduleWeekly, time.Date(2024, 3, 18, 5, 0, 0, 0, time.UTC), time.Date(2024, 3, 18, 6, 0, 0, 0, time.UTC)},
+		{scheduleWeekly, time.Date(2024, 3, 18, 6, 0, 0, 0, time.UTC), time.Date(2024, 3, 25, 6, 0, 0, 0, time.UTC)},
+		{scheduleMonthly, time.Date(2024, 3, 13, 9, 0, 0, 0, time.UTC), time.Date(2024, 4, 1, 6, 0, 0, 0, time.UTC)},
+		{scheduleMonthly, time.Date(2024, 3, 1, 5, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := nextScheduledRun(tt.frequency, tt.now); !got.Equal(tt.want) {
+			t.Errorf("nextScheduledRun(%s, %s) = %s, want %s", tt.frequency, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestReportArchive_Latest(t *testing.T) {
+	archive := &ReportArchive{Dir: filepath.Join(t.TempDir(), "reports")}
+	if _, _, err := archive.Latest(); err == nil {
+		t.Error("Expected an error before any report is written")
+	}
+
+	older, err := archive.Write("weekly-2024-03-04.md", "# Week 1")
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(older, past, past); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := archive.Write("monthly-2024-02.md", "# February"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	name, content, err := archive.Latest()
+	if err != nil || name != "monthly-2024-02.md" || content != "# February" {
+		t.Errorf("Latest() = %s %q %v, want the February report", name, content, err)
+	}
+	if !archive.Exists("weekly-2024-03-04.md") || archive.Exists("weekly-2024-03-11.md") {
+		t.Error("Exists() does not match the archive contents")
+	}
+}
+
+func TestNewReportScheduler_RejectsUnknownFrequency(t *testing.T) {
+	if _, err := NewReportScheduler(&MCPServer{}, "daily"); err == nil {
+		t.Error("Expected an error for an unsupported schedule")
+	}
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// endpointScopes maps Whoop API path prefixes to the OAuth scope that grants them
+var endpointScopes = []struct {
+	prefix string
+	scope  string
+}{
+	{"/v2/user/profile", "read:profile"},
+	{"/v2/user/measurement/body", "read:body_measurement"},
+	{"/v2/recovery", "read:recovery"},
+	{"/v2/activity/sleep", "read:sleep"},
+	{"/v2/activity/workout", "read:workout"},
+	{"/v2/cycle", "read:cycles"},
+}
+
+// scopeProbeEndpoints are the collection endpoints probed when the granted
+// scopes were not reported by the token endpoint
+var scopeProbeEndpoints = []string{"/v2/recovery", "/v2/activity/sleep", "/v2/activity/workout", "/v2/cycle"}
+
+// requiredScope returns the scope needed for endpoint, or "" when none is known
+func requiredScope(endpoint string) string {
+	for _, entry := range endpointScopes {
+		if strings.HasPrefix(endpoint, entry.prefix) {
+			return entry.scope
+		}
+	}
+	return ""
+}
+
+// MissingScopeError reports that the token lacks the scope an endpoint needs
+type MissingScopeError struct {
+	Scope    string
+	Endpoint string
+}
+
+func (e *MissingScopeError) Error() string {
+	return fmt.Sprintf("missing scope %s: the Whoop token was not granted access to %s; re-authorize and approve %s", e.Scope, e.Endpoint, e.Scope)
+}
+
+// ScopeStatus describes what is known about the scopes granted to the default token
+type ScopeStatus struct {
+	Source    string    `json:"source"` // "unknown", "token_response", "probe"
+	Granted   []string  `json:"granted"`
+	Missing   []string  `json:"missing"`
+	Unused    []string  `json:"unused,omitempty"`
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+}
+
+// grantedScopes tracks which scopes the default token holds, learned either
+// from the token endpoint's scope field or from probing endpoints
+type grantedScopes struct {
+	granted   map[string]bool
+	denied    map[string]bool
+	source    string
+	checkedAt time.Time
+	mu        sync.RWMutex
+}
+
+// newGrantedScopes creates a tracker with nothing known yet
+func newGrantedScopes() *grantedScopes {
+	return &grantedScopes{
+		granted: make(map[string]bool),
+		denied:  make(map[string]bool),
+		source:  "unknown",
+	}
+}
+
+// Reset forgets everything, e.g. after new tokens are installed
+func (g *grantedScopes) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.granted = make(map[string]bool)
+	g.denied = make(map[string]bool)
+	g.source = "unknown"
+	g.checkedAt = time.Time{}
+}
+
+// SetFromTokenResponse replaces the known scopes with the space-separated list
+// reported by the token endpoint. An empty list leaves the tracker unchanged.
+func (g *grantedScopes) SetFromTokenResponse(scope string) {
+	fields := strings.Fields(scope)
+	if len(fields) == 0 {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.granted = make(map[string]bool)
+	g.denied = make(map[string]bool)
+	for _, s := range fields {
+		g.granted[s] = true
+	}
+	g.source = "token_response"
+	g.checkedAt = time.Now()
+}
+
+// Known reports whether any scope information has been gathered
+func (g *grantedScopes) Known() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.source != "unknown"
+}
+
+// Record notes the outcome of a request that required scope
+func (g *grantedScopes) Record(scope string, allowed bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if allowed {
+		g.granted[scope] = true
+		delete(g.denied, scope)
+	} else {
+		g.denied[scope] = true
+		delete(g.granted, scope)
+	}
+	if g.source == "unknown" {
+		g.source = "probe"
+	}
+	g.checkedAt = time.Now()
+}
+
+// Check fails fast when scope is known to be missing
+func (g *grantedScopes) Check(scope, endpoint string) error {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.denied[scope] || (g.source == "token_response" && !g.granted[scope]) {
+		return &MissingScopeError{Scope: scope, Endpoint: endpoint}
+	}
+	return nil
+}
+
+// Status summarizes granted and missing scopes relative to what the server
+// requests, and flags granted scopes the server never uses
+func (g *grantedScopes) Status() ScopeStatus {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	status := ScopeStatus{
+		Source:    g.source,
+		Granted:   []string{},
+		Missing:   []string{},
+		CheckedAt: g.checkedAt,
+	}
+
+	requested := make(map[string]bool)
+	for _, s := range strings.Fields(WhoopOAuthScopes) {
+		requested[s] = true
+		if g.denied[s] || (g.source == "token_response" && !g.granted[s]) {
+			status.Missing = append(status.Missing, s)
+		}
+	}
+	for s := range g.granted {
+		status.Granted = append(status.Granted, s)
+		if !requested[s] {
+			status.Unused = append(status.Unused, s)
+		}
+	}
+	sort.Strings(status.Granted)
+	sort.Strings(status.Unused)
+	return status
+}
+
+// logScopeWarnings reports missing scopes and broader-than-needed grants
+func logScopeWarnings(status ScopeStatus) {
+	if len(status.Missing) > 0 {
+		log.Printf("Warning: Whoop token is missing scopes %s; tools that need them will fail until you re-authorize", strings.Join(status.Missing, ", "))
+	}
+	if len(status.Unused) > 0 {
+		log.Printf("Warning: Whoop token grants scopes this server does not use (%s); consider re-authorizing with only %s", strings.Join(status.Unused, ", "), WhoopOAuthScopes)
+	}
+}
+
+// DetectScopes determines which scopes the default token holds. When the token
+// endpoint did not report them, each collection endpoint is probed with a
+// single-record request and 403 responses are recorded as missing scopes.
+func (w *WhoopClient) DetectScopes() ScopeStatus {
+	if !w.scopes.Known() {
+		params := url.Values{}
+		params.Set("limit", "1")
+		for _, endpoint := range scopeProbeEndpoints {
+			// makeRequest records the outcome; only missing scopes matter here
+			if _, err := w.makeRequest(context.Background(), endpoint, params, nil); err != nil {
+				log.Printf("Scope probe of %s failed: %v", endpoint, err)
+			}
+		}
+	}
+
+	status := w.scopes.Status()
+	logScopeWarnings(status)
+	return status
+}
+
+// Scopes reports what is known about the default token's granted scopes
+func (w *WhoopClient) Scopes() ScopeStatus {
+	return w.scopes.Status()
+}
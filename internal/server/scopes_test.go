@@ -0,0 +1,50 @@
+package server
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGrantedScopes_TokenResponse(t *testing.T) {
+	scopes := newGrantedScopes()
+	if err := scopes.Check("read:workout", "/v2/activity/workout"); err != nil {
+		t.Fatalf("Expected unknown scopes to pass, got %v", err)
+	}
+
+	scopes.SetFromTokenResponse("read:recovery read:sleep offline read:body_measurement")
+
+	err := scopes.Check("read:workout", "/v2/activity/workout")
+	var scopeErr *MissingScopeError
+	if !errors.As(err, &scopeErr) || scopeErr.Scope != "read:workout" {
+		t.Fatalf("Expected missing read:workout, got %v", err)
+	}
+	if err := scopes.Check("read:sleep", "/v2/activity/sleep"); err != nil {
+		t.Errorf("Expected read:sleep to be granted, got %v", err)
+	}
+
+	status := scopes.Status()
+	if len(status.Unused) != 1 || status.Unused[0] != "read:body_measurement" {
+		t.Errorf("Expected read:body_measurement flagged as unused, got %v", status.Unused)
+	}
+}
+
+func TestGrantedScopes_RecordDenied(t *testing.T) {
+	scopes := newGrantedScopes()
+	scopes.Record("read:cycles", false)
+
+	if err := scopes.Check("read:cycles", "/v2/cycle"); err == nil {
+		t.Error("Expected denied scope to fail fast")
+	}
+	if scopes.Status().Source != "probe" {
+		t.Errorf("Expected probe source, got %s", scopes.Status().Source)
+	}
+}
+
+func TestRequiredScope(t *testing.T) {
+	if got := requiredScope("/v2/activity/workout"); got != "read:workout" {
+		t.Errorf("requiredScope(workout) = %q", got)
+	}
+	if got := requiredScope("/v2/unknown"); got != "" {
+		t.Errorf("requiredScope(unknown) = %q", got)
+	}
+}
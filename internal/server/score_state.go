@@ -0,0 +1,169 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Whoop score states of records whose score fields are not populated
+const (
+	scoreStatePending    = "PENDING_SCORE"
+	scoreStateUnscorable = "UNSCORABLE"
+)
+
+// scoreStateCalibrating counts recoveries scored while Whoop was still
+// calibrating to the user (user_calibrating), during roughly the first weeks
+// of wear. Their scores are provisional and would set meaningless baselines
+// and thresholds.
+const scoreStateCalibrating = "CALIBRATING"
+
+// unscoredStateOrder is the order score states are listed in the unscored note
+var unscoredStateOrder = []string{scoreStatePending, scoreStateUnscorable, scoreStateCalibrating}
+
+// UnscoredRecords counts the records left out of an analysis because Whoop
+// has not scored them, or scored them while calibrating, by dataset and score
+// state
+type UnscoredRecords map[string]map[string]int
+
+// add counts count unscored records
+func (u UnscoredRecords) add(dataset, state string, count int) {
+	if u[dataset] == nil {
+		u[dataset] = make(map[string]int)
+	}
+	u[dataset][state] += count
+}
+
+// scoredRecoveries keeps the scored recoveries outside the calibration
+// period, counting the rest in unscored
+func scoredRecoveries(recoveries []WhoopRecovery, unscored UnscoredRecords) []WhoopRecovery {
+	kept := make([]WhoopRecovery, 0, len(recoveries))
+	for _, recovery := range recoveries {
+		switch {
+		case !scored(recovery.ScoreState):
+			unscored.add("recovery", recovery.ScoreState, 1)
+		case recovery.Score.UserCalibrating:
+			unscored.add("recovery", scoreStateCalibrating, 1)
+		default:
+			kept = append(kept, recovery)
+		}
+	}
+	return kept
+}
+
+// scoredSleeps keeps the scored sleeps, counting the rest in unscored
+func scoredSleeps(sleeps []WhoopSleep, unscored UnscoredRecords) []WhoopSleep {
+	kept := make([]WhoopSleep, 0, len(sleeps))
+	for _, sleep := range sleeps {
+		if scored(sleep.ScoreState) {
+			kept = append(kept, sleep)
+		} else {
+			unscored.add("sleep", sleep.ScoreState, 1)
+		}
+	}
+	return kept
+}
+
+// scoredWorkouts keeps the scored workouts, counting the rest in unscored
+func scoredWorkouts(workouts []WhoopWorkout, unscored UnscoredRecords) []WhoopWorkout {
+	kept := make([]WhoopWorkout, 0, len(workouts))
+	for _, workout := range workouts {
+		if scored(workout.ScoreState) {
+			kept = append(kept, workout)
+		} else {
+			unscored.add("workout", workout.ScoreState, 1)
+		}
+	}
+	return kept
+}
+
+// scoredCycles keeps the scored cycles, counting the rest in unscored. The
+// cycle in progress is PENDING_SCORE until it ends.
+func scoredCycles(cycles []WhoopCycle, unscored UnscoredRecords) []WhoopCycle {
+	kept := make([]WhoopCycle, 0, len(cycles))
+	for _, cycle := range cycles {
+		if scored(cycle.ScoreState) {
+			kept = append(kept, cycle)
+		} else {
+			unscored.add("cycle", cycle.ScoreState, 1)
+		}
+	}
+	return kept
+}
+
+// scoredHealthData returns a copy of data holding only scored records, so
+// unpopulated zero scores do not drag averages down
+func scoredHealthData(data *HealthData) (*HealthData, UnscoredRecords) {
+	unscored := make(UnscoredRecords)
+	return &HealthData{
+		Recoveries: scoredRecoveries(data.Recoveries, unscored),
+		Sleeps:     scoredSleeps(data.Sleeps, unscored),
+		Workouts:   scoredWorkouts(data.Workouts, unscored),
+		Cycles:     scoredCycles(data.Cycles, unscored),
+	}, unscored
+}
+
+// FormatUnscoredNote renders the note appended to analysis reports about
+// records left out because they were not scored or came from the calibration
+// period, or an empty string when no records were left out
+func FormatUnscoredNote(unscored UnscoredRecords) string {
+	var parts []string
+	notScored, calibrating := false, false
+	for _, dataset := range healthDatasets {
+		states := unscored[dataset.name]
+		var others []string
+		for state := range states {
+			if !slices.Contains(unscoredStateOrder, state) {
+				others = append(others, state)
+			}
+		}
+		sort.Strings(others)
+		for _, state := range append(append([]string(nil), unscoredStateOrder...), others...) {
+			if states[state] == 0 {
+				continue
+			}
+			parts = append(parts, fmt.Sprintf("%s %s", pluralRecords(states[state], dataset.name), unscoredLabel(state)))
+			if state == scoreStateCalibrating {
+				calibrating = true
+			} else {
+				notScored = true
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	note := "_Not included in this analysis: " + strings.Join(parts, ", ") + "."
+	if notScored {
+		note += " Whoop scores records some time after they end, and marks records without enough sensor data as unscorable."
+	}
+	if calibrating {
+		note += " Whoop calibrates to a new user over their first weeks of wear; recoveries from that period are provisional, " +
+			"so they are left out of baselines, trends, stress indicators, and red flags."
+	}
+	return note + "_"
+}
+
+// pluralRecords formats a count of dataset records, e.g. "2 sleep records"
+func pluralRecords(count int, dataset string) string {
+	if count == 1 {
+		return fmt.Sprintf("1 %s record", dataset)
+	}
+	return fmt.Sprintf("%d %s records", count, dataset)
+}
+
+// unscoredLabel describes a score state in the unscored note
+func unscoredLabel(state string) string {
+	switch state {
+	case scoreStatePending:
+		return "still pending scoring"
+	case scoreStateUnscorable:
+		return "unscorable"
+	case scoreStateCalibrating:
+		return "from the calibration period"
+	default:
+		return fmt.Sprintf("with score state %s", state)
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"testing"
+)
+
+func TestScoredHealthData(t *testing.T) {
+	data := &HealthData{
+		Recoveries: []WhoopRecovery{
+			{CycleID: 1, ScoreState: "SCORED"},
+			{CycleID: 2, ScoreState: scoreStatePending},
+			{CycleID: 3, ScoreState: scoreStateUnscorable},
+		},
+		Sleeps: []WhoopSleep{
+			{ID: "a", ScoreState: scoreStatePending},
+			{ID: "b"},
+		},
+		Cycles: []WhoopCycle{
+			{ID: 1, ScoreState: "SCORED"},
+			{ID: 2, ScoreState: scoreStatePending},
+		},
+	}
+
+	scoredData, unscored := scoredHealthData(data)
+	if len(scoredData.Recoveries) != 1 || scoredData.Recoveries[0].CycleID != 1 {
+		t.Errorf("Recoveries = %+v, want only the scored one", scoredData.Recoveries)
+	}
+	if len(scoredData.Sleeps) != 1 || scoredData.Sleeps[0].ID != "b" {
+		t.Errorf("Sleeps = %+v, want the sleep without a score state", scoredData.Sleeps)
+	}
+	if len(scoredData.Cycles) != 1 || len(data.Cycles) != 2 {
+		t.Errorf("Cycles = %d, source = %d; want 1 kept and the source untouched", len(scoredData.Cycles), len(data.Cycles))
+	}
+
+	want := "_Not included in this analysis: 1 recovery record still pending scoring, 1 recovery record unscorable, " +
+		"1 sleep record still pending scoring, 1 cycle record still pending scoring. " +
+		"Whoop scores records some time after they end, and marks records without enough sensor data as unscorable._"
+	if got := FormatUnscoredNote(unscored); got != want {
+		t.Errorf("FormatUnscoredNote() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCalibratingRecoveriesExcluded(t *testing.T) {
+	calibrating := WhoopRecovery{CycleID: 1, ScoreState: "SCORED"}
+	calibrating.Score.UserCalibrating = true
+	calibrating.Score.RecoveryScore = 5
+	calibrated := WhoopRecovery{CycleID: 2, ScoreState: "SCORED"}
+	calibrated.Score.RecoveryScore = 70
+
+	unscored := make(UnscoredRecords)
+	kept := scoredRecoveries([]WhoopRecovery{calibrating, calibrated}, unscored)
+	if len(kept) != 1 || kept[0].CycleID != 2 {
+		t.Fatalf("scoredRecoveries() = %+v, want only the calibrated recovery", kept)
+	}
+
+	want := "_Not included in this analysis: 1 recovery record from the calibration period. " +
+		"Whoop calibrates to a new user over their first weeks of wear; recoveries from that period are provisional, " +
+		"so they are left out of baselines, trends, stress indicators, and red flags._"
+	if got := FormatUnscoredNote(unscored); got != want {
+		t.Errorf("FormatUnscoredNote() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,299 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+const (
+	// minSeasonalMonths is the least history, in months, a seasonal analysis needs
+	minSeasonalMonths = 6
+	// maxSeasonalMonths is the longest history the seasonal tool fetches
+	maxSeasonalMonths = 36
+	// minSeasonDays is the fewest days with data for a season to be compared
+	minSeasonDays = 14
+)
+
+// Hemispheres for naming seasons
+const (
+	hemisphereNorth = "north"
+	hemisphereSouth = "south"
+)
+
+// seasonalMetrics are the dailyMetrics compared across seasons
+var seasonalMetrics = []string{"sleep_hours", "recovery", "hrv", "resting_hr", "strain"}
+
+// seasonNames are the meteorological seasons in the northern hemisphere,
+// starting with the one that begins in December
+var seasonNames = [4]string{"Winter", "Spring", "Summer", "Autumn"}
+
+// SeasonBucket aggregates one season of one year
+type SeasonBucket struct {
+	Label                string             `json:"label"` // e.g. "Winter 2023/24", "Summer 2024"
+	Season               string             `json:"season"`
+	Start                time.Time          `json:"start"`
+	Days                 int                `json:"days"` // days with a day strain record
+	Means                map[string]float64 `json:"means"`
+	WorkoutsPerWeek      float64            `json:"workouts_per_week"`
+	TrainingHoursPerWeek float64            `json:"training_hours_per_week"`
+}
+
+// SeasonalChange is a metric's change between the same season in consecutive years
+type SeasonalChange struct {
+	Season string  `json:"season"`
+	Metric string  `json:"metric"`
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Delta  float64 `json:"delta"`
+}
+
+// SeasonalAnalysis compares seasons within and across years
+type SeasonalAnalysis struct {
+	Hemisphere   string           `json:"hemisphere"`
+	Months       int              `json:"months"` // months spanned by the data
+	Sufficient   bool             `json:"sufficient"`
+	Buckets      []SeasonBucket   `json:"buckets"`
+	YearOverYear []SeasonalChange `json:"year_over_year"`
+}
+
+// seasonStart returns the first day of the meteorological season containing t
+func seasonStart(t time.Time) time.Time {
+	offset := int(t.Month()) % 3
+	return time.Date(t.Year(), t.Month()-time.Month(offset), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// seasonName names the season starting at start in the given hemisphere
+func seasonName(start time.Time, hemisphere string) string {
+	index := (int(start.Month()) % 12) / 3
+	if hemisphere == hemisphereSouth {
+		index = (index + 2) % 4
+	}
+	return seasonNames[index]
+}
+
+// seasonLabel labels a season with its year, or both years when it starts in December
+func seasonLabel(start time.Time, hemisphere string) string {
+	name := seasonName(start, hemisphere)
+	if start.Month() == time.December {
+		return fmt.Sprintf("%s %d/%02d", name, start.Year(), (start.Year()+1)%100)
+	}
+	return fmt.Sprintf("%s %d", name, start.Year())
+}
+
+// AnalyzeSeasonal groups daily metrics and training volume by season and
+// compares each season with the same season a year earlier. Data spanning
+// fewer than minSeasonalMonths months is reported as insufficient.
+func (h *HealthAnalyzer) AnalyzeSeasonal(data *HealthData, hemisphere string) SeasonalAnalysis {
+	seasons := newSeasonalAccumulator(hemisphere)
+	for _, dataset := range healthDatasets {
+		seasons.add(dataset.name, data)
+	}
+	return seasons.analysis()
+}
+
+// seasonalAccumulator builds a SeasonalAnalysis from records added a batch at
+// a time, keeping only running statistics per season, so years of history
+// can be analyzed a page of records at a time. It is safe for concurrent use.
+type seasonalAccumulator struct {
+	hemisphere string
+
+	mu          sync.Mutex
+	seasons     map[time.Time]*seasonTotals // by season start
+	first, last time.Time                   // earliest and latest day strain records
+}
+
+// seasonTotals are the running statistics of one season
+type seasonTotals struct {
+	metrics  map[string]*stats.Running
+	days     int // day strain records
+	workouts int
+	hours    float64
+}
+
+func newSeasonalAccumulator(hemisphere string) *seasonalAccumulator {
+	return &seasonalAccumulator{hemisphere: hemisphere, seasons: make(map[time.Time]*seasonTotals)}
+}
+
+// add accumulates the seasonal metrics read from dataset's records in data.
+// Recoveries are dated by the sleeps they belong to, so data must carry those
+// sleeps, though their IDs and timezone offsets are enough.
+func (a *seasonalAccumulator) add(dataset string, data *HealthData) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if dataset == "workout" {
+		for _, workout := range data.Workouts {
+			totals := a.season(localTime(workout.Start, workout.TimezoneOffset))
+			totals.workouts++
+			totals.hours += workout.End.Sub(workout.Start).Hours()
+		}
+		return
+	}
+	for _, key := range seasonalMetrics {
+		metric, _ := lookupMetric(key)
+		if metric.dataset != dataset {
+			continue
+		}
+		for _, value := range metric.extract(data) {
+			totals := a.season(value.Date)
+			running, ok := totals.metrics[key]
+			if !ok {
+				running = &stats.Running{}
+				totals.metrics[key] = running
+			}
+			running.Add(value.Value)
+			if key == "strain" {
+				totals.days++
+				if a.first.IsZero() || value.Date.Before(a.first) {
+					a.first = value.Date
+				}
+				if value.Date.After(a.last) {
+					a.last = value.Date
+				}
+			}
+		}
+	}
+}
+
+// season returns the totals of the season containing date. The caller holds a.mu.
+func (a *seasonalAccumulator) season(date time.Time) *seasonTotals {
+	start := seasonStart(date)
+	totals, ok := a.seasons[start]
+	if !ok {
+		totals = &seasonTotals{metrics: make(map[string]*stats.Running)}
+		a.seasons[start] = totals
+	}
+	return totals
+}
+
+// analysis summarizes the records added so far
+func (a *seasonalAccumulator) analysis() SeasonalAnalysis {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	analysis := SeasonalAnalysis{Hemisphere: a.hemisphere}
+	if !a.first.IsZero() {
+		// Calendar months from the first to the last day strain record
+		analysis.Months = (a.last.Year()-a.first.Year())*12 + int(a.last.Month()) - int(a.first.Month()) + 1
+	}
+	if analysis.Months < minSeasonalMonths {
+		return analysis
+	}
+	analysis.Sufficient = true
+
+	for start, totals := range a.seasons {
+		bucket := SeasonBucket{
+			Label: seasonLabel(start, a.hemisphere), Season: seasonName(start, a.hemisphere), Start: start,
+			Days: totals.days, Means: make(map[string]float64),
+			WorkoutsPerWeek: float64(totals.workouts), TrainingHoursPerWeek: totals.hours,
+		}
+		for key, running := range totals.metrics {
+			bucket.Means[key] = running.Mean()
+		}
+		if bucket.Days > 0 {
+			bucket.WorkoutsPerWeek *= 7 / float64(bucket.Days)
+			bucket.TrainingHoursPerWeek *= 7 / float64(bucket.Days)
+		}
+		analysis.Buckets = append(analysis.Buckets, bucket)
+	}
+	sort.Slice(analysis.Buckets, func(i, j int) bool { return analysis.Buckets[i].Start.Before(analysis.Buckets[j].Start) })
+
+	// The same season a year apart
+	for i, later := range analysis.Buckets {
+		if later.Days < minSeasonDays {
+			continue
+		}
+		for _, earlier := range analysis.Buckets[:i] {
+			if earlier.Days < minSeasonDays || !earlier.Start.AddDate(1, 0, 0).Equal(later.Start) {
+				continue
+			}
+			for _, key := range seasonalMetrics {
+				from, okFrom := earlier.Means[key]
+				to, okTo := later.Means[key]
+				if okFrom && okTo {
+					analysis.YearOverYear = append(analysis.YearOverYear, SeasonalChange{
+						Season: later.Season, Metric: key, From: earlier.Label, To: later.Label, Delta: to - from,
+					})
+				}
+			}
+		}
+	}
+	return analysis
+}
+
+// seasonalProfile averages the qualifying buckets of each season across years
+func seasonalProfile(buckets []SeasonBucket, metric string) map[string]float64 {
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, bucket := range buckets {
+		value, ok := bucket.Means[metric]
+		if !ok || bucket.Days < minSeasonDays {
+			continue
+		}
+		sums[bucket.Season] += value
+		counts[bucket.Season]++
+	}
+	for season := range sums {
+		sums[season] /= float64(counts[season])
+	}
+	return sums
+}
+
+// FormatSeasonalAnalysis renders the seasonal table, winter-versus-summer
+// contrasts, and year-over-year changes
+func (h *HealthAnalyzer) FormatSeasonalAnalysis(analysis SeasonalAnalysis) string {
+	var builder strings.Builder
+	builder.WriteString("# Seasonal Patterns\n\n")
+	if !analysis.Sufficient {
+		builder.WriteString(fmt.Sprintf("Seasonal analysis needs at least %d months of history; the available data spans %d.", minSeasonalMonths, analysis.Months))
+		return builder.String()
+	}
+
+	builder.WriteString(fmt.Sprintf("**History:** %d months (%s hemisphere seasons)\n\n", analysis.Months, analysis.Hemisphere))
+	builder.WriteString("| Season | Days | Sleep | Recovery | HRV | Resting HR | Day Strain | Workouts/wk | Training h/wk |\n")
+	builder.WriteString("|---|---|---|---|---|---|---|---|---|\n")
+	for _, bucket := range analysis.Buckets {
+		cell := func(key, format string) string {
+			if value, ok := bucket.Means[key]; ok {
+				return fmt.Sprintf(format, value)
+			}
+			return "-"
+		}
+		builder.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %s | %s | %s | %.1f | %.1f |\n", bucket.Label, bucket.Days,
+			cell("sleep_hours", "%.1fh"), cell("recovery", "%.0f%%"), cell("hrv", "%.0f ms"), cell("resting_hr", "%.0f bpm"),
+			cell("strain", "%.1f"), bucket.WorkoutsPerWeek, bucket.TrainingHoursPerWeek))
+	}
+
+	builder.WriteString("\n## Winter vs Summer\n\n")
+	contrasts := 0
+	for _, key := range seasonalMetrics {
+		profile := seasonalProfile(analysis.Buckets, key)
+		winter, okWinter := profile["Winter"]
+		summer, okSummer := profile["Summer"]
+		if !okWinter || !okSummer {
+			continue
+		}
+		metric, _ := lookupMetric(key)
+		builder.WriteString(fmt.Sprintf("- **%s:** %.1f%s in winter vs %.1f%s in summer (%+.1f%s)\n", metric.Label, winter, metric.Unit, summer, metric.Unit, winter-summer, metric.Unit))
+		contrasts++
+	}
+	if contrasts == 0 {
+		builder.WriteString(fmt.Sprintf("Both a winter and a summer with at least %d days of data are needed for this comparison.\n", minSeasonDays))
+	}
+
+	builder.WriteString("\n## Year over Year\n\n")
+	if len(analysis.YearOverYear) == 0 {
+		builder.WriteString("No season has data in two consecutive years yet.")
+		return builder.String()
+	}
+	for _, change := range analysis.YearOverYear {
+		metric, _ := lookupMetric(change.Metric)
+		builder.WriteString(fmt.Sprintf("- **%s, %s → %s:** %+.1f%s\n", metric.Label, change.From, change.To, change.Delta, metric.Unit))
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// seasonalHistory builds daily cycles and main sleeps from first through last,
+// with strain and sleep hours given by day, and a one-hour workout each Monday
+func seasonalHistory(first, last time.Time, day func(time.Time) (strain, sleepHours float64)) *HealthData {
+	hour := float64(time.Hour / time.Millisecond)
+	data := &HealthData{}
+	for date := first; !date.After(last); date = date.AddDate(0, 0, 1) {
+		strain, sleepHours := day(date)
+		wake := date.Add(7 * time.Hour)
+
+		sleep := WhoopSleep{Start: wake.Add(-time.Duration(sleepHours * float64(time.Hour))), End: wake, ScoreState: "SCORED"}
+		sleep.Score.StageSummary.TotalInBedTimeMilli = int(sleepHours * hour)
+		data.Sleeps = append(data.Sleeps, sleep)
+
+		cycle := WhoopCycle{Start: sleep.Start, ScoreState: "SCORED"}
+		cycle.Score.Strain = strain
+		data.Cycles = append(data.Cycles, cycle)
+
+		if date.Weekday() == time.Monday {
+			data.Workouts = append(data.Workouts, WhoopWorkout{Start: date.Add(18 * time.Hour), End: date.Add(19 * time.Hour), ScoreState: "SCORED"})
+		}
+	}
+	return data
+}
+
+func TestAnalyzeSeasonal(t *testing.T) {
+	first := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2024, 8, 31, 0, 0, 0, 0, time.UTC)
+	data := seasonalHistory(first, last, func(date time.Time) (float64, float64) {
+		switch seasonName(seasonStart(date), hemisphereNorth) {
+		case "Winter":
+			return 10, 8.5
+		case "Summer":
+			if date.Year() == 2024 {
+				return 14, 7.5
+			}
+			return 12, 7.5
+		default:
+			return 11, 7.5
+		}
+	})
+
+	analysis := NewHealthAnalyzer().AnalyzeSeasonal(data, hemisphereNorth)
+	if !analysis.Sufficient || analysis.Months != 15 {
+		t.Fatalf("Sufficient = %v over %d months, want true over 15", analysis.Sufficient, analysis.Months)
+	}
+
+	var labels []string
+	for _, bucket := range analysis.Buckets {
+		labels = append(labels, bucket.Label)
+	}
+	if got := strings.Join(labels, ", "); got != "Summer 2023, Autumn 2023, Winter 2023/24, Spring 2024, Summer 2024" {
+		t.Errorf("buckets = %s", got)
+	}
+	winter := analysis.Buckets[2]
+	if winter.Days != 91 || winter.Means["sleep_hours"] != 8.5 || math.Abs(winter.WorkoutsPerWeek-1) > 0.05 {
+		t.Errorf("winter = %+v, want 91 days of 8.5h sleep and about 1 workout a week", winter)
+	}
+
+	changes := make(map[string]SeasonalChange)
+	for _, change := range analysis.YearOverYear {
+		changes[change.Metric] = change
+	}
+	if change := changes["strain"]; change.From != "Summer 2023" || change.To != "Summer 2024" || change.Delta != 2 {
+		t.Errorf("strain change = %+v, want +2 from Summer 2023 to Summer 2024", change)
+	}
+
+	report := NewHealthAnalyzer().FormatSeasonalAnalysis(analysis)
+	for _, want := range []string{
+		"- **Sleep Duration:** 8.5h in winter vs 7.5h in summer (+1.0h)",
+		"- **Day Strain:** 10.0 in winter vs 13.0 in summer (-3.0)",
+		"- **Day Strain, Summer 2023 → Summer 2024:** +2.0",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestAnalyzeSeasonalNeedsSixMonths(t *testing.T) {
+	first := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	data := seasonalHistory(first, first.AddDate(0, 4, 0), func(time.Time) (float64, float64) { return 10, 8 })
+
+	analysis := NewHealthAnalyzer().AnalyzeSeasonal(data, hemisphereNorth)
+	if analysis.Sufficient || len(analysis.Buckets) != 0 {
+		t.Errorf("analysis = %+v, want insufficient", analysis)
+	}
+	if report := NewHealthAnalyzer().FormatSeasonalAnalysis(analysis); !strings.Contains(report, "needs at least 6 months of history; the available data spans 5") {
+		t.Errorf("report = %q", report)
+	}
+}
+
+func TestSeasonLabelSouthernHemisphere(t *testing.T) {
+	december := seasonStart(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	if got := seasonLabel(december, hemisphereSouth); got != "Summer 2023/24" {
+		t.Errorf("seasonLabel(January, south) = %q, want Summer 2023/24", got)
+	}
+	if got := seasonLabel(seasonStart(time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)), hemisphereSouth); got != "Winter 2024" {
+		t.Errorf("seasonLabel(July, south) = %q, want Winter 2024", got)
+	}
+}
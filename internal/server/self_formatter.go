@@ -0,0 +1,176 @@
+package server
+
+import (
+	"strings"
+)
+
+// selfFormatter frames results in plain language for someone tracking their
+// own data, without clinical or coaching vocabulary
+type selfFormatter struct {
+	l *Localizer
+}
+
+func (f selfFormatter) HealthSummary(summary *HealthSummary) string {
+	var builder strings.Builder
+	t := f.l.T
+
+	builder.WriteString(t("# Your Health Summary") + "\n\n")
+	builder.WriteString(formatPeriod(f.l, summary.DateRange) + "\n\n")
+
+	builder.WriteString(t("## At a Glance") + "\n")
+	builder.WriteString(t("- Recovery averaged **%.0f%%** and is %s.",
+		summary.RecoveryTrend.AverageScore, f.plainTrend(summary.RecoveryTrend.Trend)) + "\n")
+	builder.WriteString(t("- You slept **%.1f hours** a night on average (%.0f%% of time in bed asleep).",
+		summary.SleepAnalysis.AverageHours, summary.SleepAnalysis.AverageEfficiency*100) + "\n")
+	builder.WriteString(t("- You worked out about **%d times a week** with an average strain of %.1f.",
+		summary.ActivityPatterns.WeeklyWorkouts, summary.ActivityPatterns.AverageStrain) + "\n")
+	builder.WriteString(t("- Your body's stress signals look **%s**.", t(summary.StressIndicators.StressLevel)) + "\n\n")
+	builder.WriteString(formatSparklines(f.l, summary.Sparklines))
+	builder.WriteString(formatPopulationContext(f.l, summary.Population))
+
+	if len(summary.TherapyInsights) > 0 {
+		builder.WriteString(t("## Worth Paying Attention To") + "\n")
+		for _, insight := range summary.TherapyInsights {
+			builder.WriteString("- " + insight.Insight + "\n")
+		}
+		if len(summary.OmittedInsights) > 0 {
+			builder.WriteString(t("- …plus %d smaller thing(s)", len(summary.OmittedInsights)) + "\n")
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(summary.RedFlags) > 0 {
+		builder.WriteString(t("## ⚠️ Consider Talking to a Professional") + "\n")
+		for _, flag := range summary.RedFlags {
+			builder.WriteString(formatSafetyNotice(flag))
+			builder.WriteString("- " + flag.Description + "\n")
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString(formatAcknowledgedFlags(f.l, summary.AcknowledgedFlags, "##"))
+
+	builder.WriteString(t("## Things to Try") + "\n")
+	builder.WriteString(f.sleepTips(summary.SleepAnalysis))
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+func (f selfFormatter) Stress(period DateRange, stress StressIndicators) string {
+	var builder strings.Builder
+	t := f.l.T
+
+	builder.WriteString(t("# Your Stress Signals") + "\n\n")
+	builder.WriteString(formatPeriod(f.l, period) + "\n\n")
+	builder.WriteString(t("Overall your body's stress signals look **%s** (%.0f out of 100).", t(stress.StressLevel), stress.PhysiologicalStress) + "\n\n")
+	if stress.Model == stressModelLegacy {
+		builder.WriteString(t("- Days with unusual heart rate variability: %d", stress.ElevatedHRVDays) + "\n")
+	} else {
+		builder.WriteString(t("- Days with lower-than-usual heart rate variability: %d", stress.SuppressedHRVDays) + "\n")
+	}
+	builder.WriteString(t("- Days with a higher-than-usual resting heart rate: %d", stress.HighRestingHRDays) + "\n")
+	if stress.Model != stressModelLegacy && stress.SleepDebtHours >= 1 {
+		builder.WriteString(t("- Sleep you're behind on: %.1f hours", stress.SleepDebtHours) + "\n")
+	}
+	if stress.StrainMismatchDays > 0 {
+		builder.WriteString(t("- Hard days when your body hadn't recovered: %d", stress.StrainMismatchDays) + "\n")
+	}
+	if stress.PoorRecoveryStreak > 0 {
+		builder.WriteString(t("- Low-recovery days in a row: %d", stress.PoorRecoveryStreak) + "\n")
+	}
+	builder.WriteString("\n")
+	switch stress.StressLevel {
+	case "critical", "high":
+		builder.WriteString(t("Your body is showing signs of strain. Easier days, more sleep, and time to unwind usually help; if this lasts, check in with a doctor.") + "\n")
+	case "moderate":
+		builder.WriteString(t("A few signs of strain. Keep an eye on sleep and build in some downtime.") + "\n")
+	default:
+		builder.WriteString(t("Nothing unusual here. Keep doing what you're doing.") + "\n")
+	}
+	builder.WriteString("\n" + cycleNote(f.l, stress.CycleAdjustedDays, t("resting HR rise(s)")))
+	return builder.String()
+}
+
+func (f selfFormatter) Sleep(period DateRange, sessions int, analysis SleepAnalysis) string {
+	var builder strings.Builder
+	t := f.l.T
+
+	builder.WriteString(t("# Your Sleep") + "\n\n")
+	builder.WriteString(t("%s, %d sleeps recorded", formatPeriod(f.l, period), sessions) + "\n\n")
+	builder.WriteString(t("- You averaged **%.1f hours** a night, and sleep quality is %s.", analysis.AverageHours, f.plainTrend(analysis.SleepQualityTrend)) + "\n")
+	builder.WriteString(t("- You were asleep for %.0f%% of your time in bed and woke up about %.1f times a night.", analysis.AverageEfficiency*100, analysis.DisturbanceFrequency) + "\n")
+	builder.WriteString(t("- Once asleep, you spent about %.0f minutes a night awake.", analysis.Wake.AverageWASOMinutes) + "\n")
+	builder.WriteString(t("- You're carrying **%.1f hours** of sleep debt (%s).", analysis.SleepDebtHours, formatDaysToRepay(f.l, analysis.DebtLedger)) + "\n")
+	if analysis.WhoopConsistency > 0 && analysis.WhoopPerformance > 0 {
+		builder.WriteString(t("- Whoop rates your sleep consistency at %.0f%% and your sleep performance at %.0f%%.", analysis.WhoopConsistency*100, analysis.WhoopPerformance*100) + "\n")
+	}
+	if analysis.NapCount > 0 {
+		builder.WriteString(t("- You took %d naps, about %.0f minutes each.", analysis.NapCount, analysis.AverageNapMinutes) + "\n")
+	}
+	builder.WriteString("\n" + t("## Things to Try") + "\n")
+	builder.WriteString(f.sleepTips(analysis))
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+func (f selfFormatter) Activity(period DateRange, workouts int, patterns ActivityPatterns) string {
+	var builder strings.Builder
+	t := f.l.T
+
+	builder.WriteString(t("# Your Activity") + "\n\n")
+	builder.WriteString(t("%s, %d workouts recorded", formatPeriod(f.l, period), workouts) + "\n\n")
+	builder.WriteString(t("- About **%d workouts a week** with an average strain of %.1f.", patterns.WeeklyWorkouts, patterns.AverageStrain) + "\n")
+	if len(patterns.SportBreakdown) > 0 {
+		builder.WriteString(t("- Mostly: %s.", FormatSportBreakdown(patterns.SportBreakdown)) + "\n")
+	}
+	builder.WriteString(t("- Easy recovery days: %d.", patterns.ActiveRecoveryDays) + "\n")
+	balance := patterns.StrainBalance
+	if balance != nil {
+		builder.WriteString(t("- Your effort matched how recovered you were on %.0f%% of days.", balance.Score) + "\n")
+	}
+	builder.WriteString("\n")
+	switch {
+	case patterns.WeeklyWorkouts == 0:
+		builder.WriteString(t("No workouts recorded. Even short walks count, and they tend to help mood and sleep.") + "\n")
+	case patterns.OvertrainingRisk == "high":
+		builder.WriteString(t("You've been pushing hard. A few easier days will help you bounce back.") + "\n")
+	case balance != nil && balance.Tendency == strainBalanceOverreaching:
+		builder.WriteString(t("You often push harder than your body has recovered for. Save hard days for when you feel fresh.") + "\n")
+	default:
+		builder.WriteString(t("Your activity looks well balanced.") + "\n")
+	}
+	return builder.String()
+}
+
+// plainTrend phrases a trend label as a plain-language state
+func (f selfFormatter) plainTrend(trend string) string {
+	switch trend {
+	case "improving":
+		return f.l.T("getting better")
+	case "declining":
+		return f.l.T("getting worse")
+	default:
+		return f.l.T("holding steady")
+	}
+}
+
+// sleepTips lists simple sleep suggestions for the analysis
+func (f selfFormatter) sleepTips(analysis SleepAnalysis) string {
+	var tips []string
+	if analysis.AverageHours < 7 {
+		tips = append(tips, f.l.T("Aim for a bedtime that gives you at least 7 hours of sleep."))
+	}
+	if analysis.AverageEfficiency < 0.85 {
+		tips = append(tips, f.l.T("Keep the bedroom cool, dark, and quiet, and cut caffeine after lunch."))
+	}
+	if analysis.ChronicNapCompensation {
+		tips = append(tips, f.l.T("Naps are making up for short nights. Try moving that sleep into the night: a longer sleep window and short naps, if any, before mid-afternoon."))
+	}
+	if analysis.Wake.Pattern == wakePatternProlongedWake {
+		tips = append(tips, f.l.T("If you're awake for more than about 20 minutes, get up and do something calm in dim light until you feel sleepy."))
+	}
+	if scheduleConsistency(analysis) < 0.7 {
+		tips = append(tips, f.l.T("Go to bed and wake up at similar times, weekends included."))
+	}
+	if len(tips) == 0 {
+		return "- " + f.l.T("Your sleep habits are working; keep them up.") + "\n"
+	}
+	return "- " + strings.Join(tips, "\n- ") + "\n"
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Server modes selected with --mode
+const (
+	serverModeFull     = "full"
+	serverModeReadOnly = "readonly"
+)
+
+var serverModes = []string{serverModeFull, serverModeReadOnly}
+
+// mutatingTools change stored state or credentials, so read-only mode hides them
+var mutatingTools = map[string]bool{
+	"setup_whoop_auth": true, "log_annotation": true, "set_goal": true, "acknowledge_red_flag": true,
+}
+
+// mutatingArguments are the boolean arguments that make an otherwise
+// read-only tool write to the datastore; read-only mode removes them
+var mutatingArguments = map[string]string{"detect_travel": "annotate"}
+
+// mutatingCall reports whether a call changes stored state or credentials:
+// any call to a mutating tool, or one that sets a mutating argument
+func mutatingCall(toolName string, arguments json.RawMessage) bool {
+	if mutatingTools[toolName] {
+		return true
+	}
+	argument, ok := mutatingArguments[toolName]
+	if !ok {
+		return false
+	}
+	var args map[string]interface{}
+	json.Unmarshal(arguments, &args)
+	set, _ := args[argument].(bool)
+	return set
+}
+
+// pathArgument is the export argument naming a file to write; read-only mode
+// removes it so exports are only returned inline
+const pathArgument = "path"
+
+// SetMode switches the server between full access and read-only. Read-only
+// mode hides mutating tools and arguments, refuses exports to disk, skips
+// retention, makes every datastore store refuse writes, and keeps refreshed
+// OAuth tokens and red flag alert cooldowns in memory instead of on disk. The
+// audit log is still appended to, since it records the reads themselves.
+func (s *MCPServer) SetMode(mode string) error {
+	switch mode {
+	case serverModeFull:
+		s.readOnly = false
+	case serverModeReadOnly:
+		s.readOnly = true
+		s.tools = readOnlyTools(s.tools)
+		s.whoopClient.SetReadOnly(true)
+	default:
+		return fmt.Errorf("unknown mode %q (expected %s)", mode, strings.Join(serverModes, " or "))
+	}
+	for _, store := range s.datastores() {
+		store.SetReadOnly(s.readOnly)
+	}
+	return nil
+}
+
+// datastores returns the server's configured datastore stores
+func (s *MCPServer) datastores() []interface{ SetReadOnly(bool) } {
+	var stores []interface{ SetReadOnly(bool) }
+	if s.baselines != nil {
+		stores = append(stores, s.baselines)
+	}
+	if s.burnout != nil {
+		stores = append(stores, s.burnout)
+	}
+	if s.annotations != nil {
+		stores = append(stores, s.annotations)
+	}
+	if s.annotationSummaries != nil {
+		stores = append(stores, s.annotationSummaries)
+	}
+	if s.goals != nil {
+		stores = append(stores, s.goals)
+	}
+	if s.redFlagAcks != nil {
+		stores = append(stores, s.redFlagAcks)
+	}
+	if s.notifier != nil {
+		stores = append(stores, s.notifier)
+	}
+	if s.reports != nil {
+		stores = append(stores, s.reports)
+	}
+	return stores
+}
+
+// readOnlyTools drops mutating tools, mutating arguments, and the path
+// argument of exports
+func readOnlyTools(tools []MCPTool) []MCPTool {
+	kept := make([]MCPTool, 0, len(tools))
+	for _, tool := range tools {
+		if mutatingTools[tool.Name] {
+			continue
+		}
+		_, hasPath := tool.InputSchema.Properties[pathArgument]
+		argument, hasMutating := mutatingArguments[tool.Name]
+		if hasPath || hasMutating {
+			properties := make(map[string]interface{}, len(tool.InputSchema.Properties))
+			for name, property := range tool.InputSchema.Properties {
+				if name != pathArgument && name != argument {
+					properties[name] = property
+				}
+			}
+			tool.InputSchema.Properties = properties
+		}
+		kept = append(kept, tool)
+	}
+	return kept
+}
+
+// checkMode refuses calls that read-only mode does not allow
+func (s *MCPServer) checkMode(toolName string, arguments json.RawMessage) error {
+	if !s.readOnly {
+		return nil
+	}
+	if mutatingTools[toolName] {
+		return fmt.Errorf("%s is disabled because the server runs in read-only mode", toolName)
+	}
+	if mutatingCall(toolName, arguments) {
+		return fmt.Errorf("%s with %s is disabled because the server runs in read-only mode", toolName, mutatingArguments[toolName])
+	}
+	var args map[string]interface{}
+	json.Unmarshal(arguments, &args)
+	if path, _ := args[pathArgument].(string); path != "" {
+		return fmt.Errorf("writing exports to disk is disabled because the server runs in read-only mode; omit path to get the export inline")
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetModeReadOnly(t *testing.T) {
+	server := &MCPServer{tools: defineMCPTools(), whoopClient: &WhoopClient{}}
+	if err := server.SetMode("hosted"); err == nil {
+		t.Error("expected an unknown mode to fail")
+	}
+	if err := server.SetMode(serverModeReadOnly); err != nil {
+		t.Fatalf("SetMode() error: %v", err)
+	}
+
+	for _, tool := range server.tools {
+		if mutatingTools[tool.Name] {
+			t.Errorf("read-only tools still list %s", tool.Name)
+		}
+		if _, ok := tool.InputSchema.Properties[pathArgument]; ok {
+			t.Errorf("read-only %s still accepts a path", tool.Name)
+		}
+		if argument, ok := mutatingArguments[tool.Name]; ok {
+			if _, ok := tool.InputSchema.Properties[argument]; ok {
+				t.Errorf("read-only %s still accepts %s", tool.Name, argument)
+			}
+		}
+	}
+	// The full tool definitions are untouched
+	for _, tool := range defineMCPTools() {
+		if tool.Name == "export_calendar" {
+			if _, ok := tool.InputSchema.Properties[pathArgument]; !ok {
+				t.Error("readOnlyTools modified the shared export_calendar schema")
+			}
+		}
+	}
+
+	if _, err := server.CallTool(context.Background(), "log_annotation", json.RawMessage(`{"note": "rough day"}`)); err == nil ||
+		!strings.Contains(err.Error(), "read-only") {
+		t.Errorf("log_annotation error = %v, want a read-only refusal", err)
+	}
+	if err := server.checkMode("export_calendar", json.RawMessage(`{"start_date": "last week", "path": "/tmp"}`)); err == nil {
+		t.Error("expected an export to disk to be refused")
+	}
+	if err := server.checkMode("export_calendar", json.RawMessage(`{"start_date": "last week"}`)); err != nil {
+		t.Errorf("inline export refused: %v", err)
+	}
+	if err := server.checkMode("detect_travel", json.RawMessage(`{"annotate": true}`)); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("detect_travel with annotate error = %v, want a read-only refusal", err)
+	}
+	if err := server.checkMode("detect_travel", json.RawMessage(`{"annotate": false}`)); err != nil {
+		t.Errorf("detect_travel without annotate refused: %v", err)
+	}
+}
+
+func TestCredentialStoreReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	original := `{"accounts": [{"user_id": 55501, "access_token": "old", "refresh_token": "r1"}]}`
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+	store, err := LoadCredentialStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.SetReadOnly(true)
+	if err := store.UpdateTokens(55501, "new", "r2"); err != nil {
+		t.Fatalf("UpdateTokens() error: %v", err)
+	}
+	if account, _ := store.Lookup(55501); account.AccessToken != "new" {
+		t.Errorf("in-memory token = %q, want the rotated token", account.AccessToken)
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Errorf("read-only store rewrote the accounts file:\n%s", data)
+	}
+}
+
+func TestReadOnlyModeLeavesDatastoreUnchanged(t *testing.T) {
+	clearConfigEnv(t)
+	dataDir := t.TempDir()
+	t.Setenv("WHOOP_DATA_DIR", dataDir)
+	t.Setenv("WHOOP_RETENTION_MONTHS", "6")
+	// The audit log records reads, so read-only mode still appends to it
+	t.Setenv("WHOOP_AUDIT_LOG_FILE", filepath.Join(t.TempDir(), "audit.ndjson"))
+	server, err := NewMCPServerWithAPI(NewSyntheticMockWhoopAPI(SyntheticOptions{Seed: 5, Days: 120}), os.Getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := time.Now().AddDate(-1, 0, 0).Format("2006-01-02")
+	for name, arguments := range map[string]string{
+		"log_annotation":       `{"date": "` + old + `", "note": "aged out of retention", "mood": 4}`,
+		"set_goal":             `{"metric": "workouts", "type": "count", "target": 3}`,
+		"acknowledge_red_flag": `{"flag_type": "severe_sleep_deprivation", "reason": "newborn"}`,
+	} {
+		if _, err := server.CallTool(context.Background(), name, json.RawMessage(arguments)); err != nil {
+			t.Fatalf("seeding %s: %v", name, err)
+		}
+	}
+	if _, err := server.CallTool(context.Background(), "get_personal_baselines", json.RawMessage(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := server.SetMode(serverModeReadOnly); err != nil {
+		t.Fatal(err)
+	}
+	before := snapshotDir(t, dataDir)
+
+	for _, tool := range defineMCPTools() {
+		for _, arguments := range []string{`{}`, `{"days": 60, "refresh": true}`, `{"start_date": "past 60 days", "period_a": {"start_date": "past 60 days"}, "period_b": {"start_date": "past 30 days"}}`, `{"note": "x", "metric": "workouts", "type": "count", "target": 1, "flag_type": "severe_sleep_deprivation", "remove_goal_id": 1, "remove_acknowledgement_id": 1, "annotate": true}`} {
+			server.CallTool(context.Background(), tool.Name, json.RawMessage(arguments))
+		}
+	}
+	for _, resource := range server.resources {
+		server.readResource(context.Background(), resource.URI)
+	}
+	server.applyRetention(time.Now())
+	if err := server.Serve(context.Background(), strings.NewReader(""), io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond) // let red flag notifications finish
+
+	after := snapshotDir(t, dataDir)
+	for path, state := range after {
+		if before[path] != state {
+			t.Errorf("read-only mode changed %s", path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			t.Errorf("read-only mode removed %s", path)
+		}
+	}
+}
+
+// snapshotDir records the content and modification time of every file under dir
+func snapshotDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files[path] = info.ModTime().String() + "\n" + string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
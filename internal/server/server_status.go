@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ServerStatus is a snapshot of what can make queries fail: authorization,
+// quota, and stale local data
+type ServerStatus struct {
+	Version     string
+	Mode        string
+	Fixtures    string // fixture source in offline mode, empty when using the API
+	Uptime      time.Duration
+	AuthState   string // "ok", "expired", or "needs_auth"
+	AuthReason  string // why re-authorization is needed
+	TokenExpiry *time.Time
+	CanRefresh  bool
+	TokenStore  string
+	Scopes      ScopeStatus
+	RateLimit   RateLimitStatus
+	Sports      int // sport names cached from workouts
+	Accounts    int // additional accounts configured
+
+	BaselineComputedAt *time.Time
+	BurnoutRecordedAt  *time.Time
+	LatestReport       string
+	LatestReportAt     *time.Time
+}
+
+// serverStatus gathers the status of the authenticated account's session
+func (s *MCPServer) serverStatus(now time.Time) ServerStatus {
+	client := s.whoopClient
+	status := ServerStatus{
+		Version:     version,
+		Mode:        serverModeFull,
+		Uptime:      now.Sub(s.started),
+		AuthState:   "ok",
+		TokenExpiry: client.TokenExpiry(),
+		CanRefresh:  client.CanRefreshToken(),
+		Scopes:      client.Scopes(),
+		RateLimit:   client.RateLimitStatus(),
+		Sports:      len(client.Sports().All()),
+	}
+	if s.readOnly {
+		status.Mode = serverModeReadOnly
+	}
+	status.Fixtures = client.FixtureSource()
+	if store := client.TokenStore(); store != nil {
+		status.TokenStore = store.Name()
+	}
+	if client.Accounts() != nil {
+		status.Accounts = client.Accounts().Len()
+	}
+	if authErr := client.AuthRequired(); authErr != nil {
+		status.AuthState, status.AuthReason = "needs_auth", authErr.Reason
+	} else if status.TokenExpiry != nil && now.After(*status.TokenExpiry) {
+		status.AuthState = "expired"
+	}
+
+	if userID, err := s.accountID(context.Background(), nil); err == nil {
+		if baseline, err := s.baselines.Load(userID); err == nil && baseline != nil {
+			status.BaselineComputedAt = &baseline.ComputedAt
+		}
+	}
+	if history, err := s.burnout.History(0); err == nil && len(history) > 0 {
+		status.BurnoutRecordedAt = &history[len(history)-1].RecordedAt
+	}
+	if s.reports != nil {
+		if name, _, err := s.reports.Latest(); err == nil {
+			status.LatestReport = name
+			if info, err := os.Stat(filepath.Join(s.reports.Dir, name)); err == nil {
+				modified := info.ModTime()
+				status.LatestReportAt = &modified
+			}
+		}
+	}
+	return status
+}
+
+// FormatServerStatus renders the status with a hint for each problem found
+func FormatServerStatus(status ServerStatus, now time.Time) string {
+	var builder strings.Builder
+	var problems []string
+	builder.WriteString("# Server Status\n\n")
+	builder.WriteString(fmt.Sprintf("- **Version:** %s (%s mode, up %s)\n", status.Version, status.Mode, formatAge(status.Uptime)))
+	if status.Fixtures != "" {
+		builder.WriteString(fmt.Sprintf("- **Data source:** offline fixtures (%s); the Whoop API is not called\n", status.Fixtures))
+	}
+
+	builder.WriteString("\n## Authorization\n")
+	switch status.AuthState {
+	case "needs_auth":
+		builder.WriteString(fmt.Sprintf("- **State:** needs re-authorization (%s)\n", status.AuthReason))
+		problems = append(problems, "Every query fails until the account is re-authorized; call setup_whoop_auth.")
+	case "expired":
+		builder.WriteString("- **State:** access token expired\n")
+		if status.CanRefresh {
+			builder.WriteString("- The next query refreshes it automatically.\n")
+		} else {
+			problems = append(problems, "The access token expired and cannot be refreshed without a refresh token and client credentials; call setup_whoop_auth.")
+		}
+	default:
+		builder.WriteString("- **State:** authorized\n")
+	}
+	switch {
+	case status.TokenExpiry == nil:
+		builder.WriteString("- **Token expiry:** unknown (the token came from the environment)\n")
+	case now.Before(*status.TokenExpiry):
+		builder.WriteString(fmt.Sprintf("- **Token expiry:** in %s (%s)\n", formatAge(status.TokenExpiry.Sub(now)), status.TokenExpiry.Format(time.RFC3339)))
+	default:
+		builder.WriteString(fmt.Sprintf("- **Token expiry:** %s ago\n", formatAge(now.Sub(*status.TokenExpiry))))
+	}
+	if status.CanRefresh {
+		builder.WriteString("- **Auto-refresh:** configured\n")
+	} else {
+		builder.WriteString("- **Auto-refresh:** not configured (no refresh token or client credentials)\n")
+	}
+	if status.TokenStore != "" {
+		builder.WriteString(fmt.Sprintf("- **Token store:** %s\n", status.TokenStore))
+	}
+	if status.Accounts > 0 {
+		builder.WriteString(fmt.Sprintf("- **Additional accounts:** %d\n", status.Accounts))
+	}
+	switch {
+	case len(status.Scopes.Missing) > 0:
+		builder.WriteString(fmt.Sprintf("- **Scopes:** missing %s\n", strings.Join(status.Scopes.Missing, ", ")))
+		problems = append(problems, fmt.Sprintf("Queries needing %s fail until the account is re-authorized with those scopes.", strings.Join(status.Scopes.Missing, ", ")))
+	case status.Scopes.Source == "unknown":
+		builder.WriteString("- **Scopes:** not checked yet\n")
+	default:
+		builder.WriteString(fmt.Sprintf("- **Scopes:** %s\n", strings.Join(status.Scopes.Granted, ", ")))
+	}
+
+	rate := status.RateLimit
+	builder.WriteString("\n## Rate Limit\n")
+	if status.Fixtures != "" {
+		builder.WriteString("- Offline mode does not use the Whoop API quota.\n")
+	} else {
+		builder.WriteString(fmt.Sprintf("- **Client limit:** %.0f requests/minute, %.0f available now\n", rate.LimiterRatePerMinute, rate.LimiterTokens))
+		builder.WriteString(fmt.Sprintf("- **Used:** %d in the last minute, %d in the last day, %d since start\n", rate.RequestsLastMinute, rate.RequestsLastDay, rate.RequestsTotal))
+		if rate.WhoopReportedRemain != nil {
+			builder.WriteString(fmt.Sprintf("- **Whoop reports:** %d remaining\n", *rate.WhoopReportedRemain))
+		} else {
+			builder.WriteString(fmt.Sprintf("- **Estimated remaining:** %d this minute, %d today\n", rate.EstimatedMinuteRemain, rate.EstimatedDayRemain))
+		}
+		if rate.Throttled > 0 {
+			builder.WriteString(fmt.Sprintf("- **Throttled responses:** %d\n", rate.Throttled))
+		}
+		if rate.EstimatedDayRemain == 0 || (rate.WhoopReportedRemain != nil && *rate.WhoopReportedRemain == 0) {
+			problems = append(problems, "The Whoop API quota is used up; queries fail until it resets.")
+		}
+	}
+
+	builder.WriteString("\n## Caches and Sync\n")
+	builder.WriteString(fmt.Sprintf("- **Sport catalog:** %d sports\n", status.Sports))
+	builder.WriteString("- **Personal baseline:** " + formatFreshness(status.BaselineComputedAt, now) + "\n")
+	builder.WriteString("- **Burnout history:** " + formatFreshness(status.BurnoutRecordedAt, now) + "\n")
+	if status.LatestReport != "" {
+		builder.WriteString(fmt.Sprintf("- **Latest scheduled report:** %s, %s\n", status.LatestReport, formatFreshness(status.LatestReportAt, now)))
+	} else {
+		builder.WriteString("- **Latest scheduled report:** none\n")
+	}
+	if status.BaselineComputedAt != nil && now.Sub(*status.BaselineComputedAt) > baselineMaxAge {
+		builder.WriteString("- The baseline is recomputed on the next query that needs it, or run `whoop-mcp-server sync`.\n")
+	}
+
+	if len(problems) > 0 {
+		builder.WriteString("\n## Problems\n")
+		for _, problem := range problems {
+			builder.WriteString("- " + problem + "\n")
+		}
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// formatFreshness describes how long ago something was updated
+func formatFreshness(at *time.Time, now time.Time) string {
+	if at == nil {
+		return "never"
+	}
+	return "updated " + formatAge(now.Sub(*at)) + " ago"
+}
+
+// formatAge renders a duration in its largest whole unit
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	case d >= 2*time.Hour:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	case d >= 2*time.Minute:
+		return fmt.Sprintf("%d minutes", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%d seconds", int(d.Seconds()))
+}
+
+// executeServerStatusTool implements the server status tool
+func (s *MCPServer) executeServerStatusTool(ctx context.Context, arguments json.RawMessage) (string, error) {
+	now := time.Now()
+	return FormatServerStatus(s.serverStatus(now), now), nil
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatServerStatus_Healthy(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	expiry := now.Add(50 * time.Minute)
+	baseline := now.Add(-3 * time.Hour)
+	status := ServerStatus{
+		Version:            "1.2.3",
+		Mode:               serverModeFull,
+		Uptime:             5 * time.Minute,
+		AuthState:          "ok",
+		TokenExpiry:        &expiry,
+		CanRefresh:         true,
+		Scopes:             ScopeStatus{Source: "token_response", Granted: []string{"read:recovery", "read:sleep"}},
+		RateLimit:          RateLimitStatus{LimiterRatePerMinute: 100, LimiterTokens: 10, EstimatedMinuteRemain: 100, EstimatedDayRemain: 10000},
+		Sports:             12,
+		BaselineComputedAt: &baseline,
+	}
+
+	output := FormatServerStatus(status, now)
+	for _, want := range []string{"1.2.3 (full mode, up 5 minutes)", "**State:** authorized", "in 50 minutes", "read:recovery, read:sleep", "12 sports", "**Personal baseline:** updated 3 hours ago", "**Burnout history:** never"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected status to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "## Problems") {
+		t.Errorf("Expected no problems for a healthy server, got:\n%s", output)
+	}
+}
+
+func TestFormatServerStatus_Problems(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	expired := now.Add(-2 * time.Hour)
+	status := ServerStatus{
+		Version:     "1.2.3",
+		Mode:        serverModeReadOnly,
+		AuthState:   "expired",
+		TokenExpiry: &expired,
+		Scopes:      ScopeStatus{Source: "probe", Missing: []string{"read:sleep"}},
+		RateLimit:   RateLimitStatus{LimiterRatePerMinute: 100, EstimatedDayRemain: 0},
+	}
+
+	output := FormatServerStatus(status, now)
+	for _, want := range []string{"access token expired", "cannot be refreshed", "missing read:sleep", "quota is used up", "2 hours ago"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected status to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestTokenHolderExpiry(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if expiryFrom(0, now) != nil {
+		t.Error("Expected an unreported lifetime to leave the expiry unknown")
+	}
+
+	client := &WhoopClient{tokens: newTokenHolder("a", "r"), scopes: newGrantedScopes()}
+	client.tokens.SetExpiry(expiryFrom(3600, now))
+	if expiry := client.TokenExpiry(); expiry == nil || !expiry.Equal(now.Add(time.Hour)) {
+		t.Errorf("TokenExpiry() = %v, want %s", expiry, now.Add(time.Hour))
+	}
+	client.SetTokens("b", "")
+	if client.TokenExpiry() != nil {
+		t.Error("Expected manually installed tokens to clear the expiry")
+	}
+}
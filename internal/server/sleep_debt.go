@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// sleepDebtRecentNights is the window used to project repayment at current habits
+	sleepDebtRecentNights = 7
+	// sleepDebtTrendThreshold is the nightly balance (hours) that counts as repaying or accumulating
+	sleepDebtTrendThreshold = 0.25
+)
+
+// SleepDebtEntry is one night in the sleep-debt ledger
+type SleepDebtEntry struct {
+	Date       string  `json:"date"`
+	SleepHours float64 `json:"sleep_hours"`
+	NapHours   float64 `json:"nap_hours"`
+	NeedHours  float64 `json:"need_hours"`
+	Balance    float64 `json:"balance_hours"` // sleep obtained minus need; negative is a deficit
+	Debt       float64 `json:"debt_hours"`    // running debt after this night
+}
+
+// SleepDebtLedger accumulates nightly deficits and surpluses over a window
+type SleepDebtLedger struct {
+	Entries             []SleepDebtEntry `json:"entries"`
+	CurrentDebtHours    float64          `json:"current_debt_hours"`
+	PeakDebtHours       float64          `json:"peak_debt_hours"`
+	AverageBalanceHours float64          `json:"average_balance_hours"`
+	RecentBalanceHours  float64          `json:"recent_balance_hours"`
+	DaysToRepay         float64          `json:"days_to_repay"` // -1 when current habits do not repay the debt
+	Trend               string           `json:"trend"`         // "no_data", "clear", "repaying", "accumulating", "stable"
+}
+
+// BuildSleepDebtLedger walks main sleeps chronologically, crediting naps from
+// the preceding day, and keeps a running debt that surpluses pay down but
+// never push below zero (sleep cannot be banked indefinitely). The nightly need
+// is Whoop's baseline need only; the debt component of its need is what this
+// ledger tracks itself.
+func (h *HealthAnalyzer) BuildSleepDebtLedger(sleepData []WhoopSleep) SleepDebtLedger {
+	mainSleeps, naps := splitNaps(sleepData)
+	if len(mainSleeps) == 0 {
+		return SleepDebtLedger{Entries: []SleepDebtEntry{}, Trend: "no_data"}
+	}
+
+	sorted := append([]WhoopSleep(nil), mainSleeps...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	ledger := SleepDebtLedger{Entries: make([]SleepDebtEntry, 0, len(sorted))}
+	var balances []float64
+	debt := 0.0
+
+	for _, sleep := range sorted {
+		need := float64(sleep.Score.SleepNeeded.BaselineMilli) / (1000 * 60 * 60)
+		if need <= 0 {
+			need = defaultSleepNeedHours
+		}
+
+		entry := SleepDebtEntry{
+			Date:       localTime(sleep.End, sleep.TimezoneOffset).Format("2006-01-02"),
+			SleepHours: h.sleepHours(sleep),
+			NapHours:   h.napCreditBefore(sleep, naps),
+			NeedHours:  need,
+		}
+		entry.Balance = entry.SleepHours + entry.NapHours - need
+		debt = math.Max(debt-entry.Balance, 0)
+		entry.Debt = debt
+
+		ledger.PeakDebtHours = math.Max(ledger.PeakDebtHours, debt)
+		ledger.Entries = append(ledger.Entries, entry)
+		balances = append(balances, entry.Balance)
+	}
+
+	ledger.CurrentDebtHours = debt
+	ledger.AverageBalanceHours = h.calculateMean(balances)
+	recent := balances
+	if len(recent) > sleepDebtRecentNights {
+		recent = recent[len(recent)-sleepDebtRecentNights:]
+	}
+	ledger.RecentBalanceHours = h.calculateMean(recent)
+
+	switch {
+	case debt == 0:
+		ledger.DaysToRepay = 0
+	case ledger.RecentBalanceHours > 0:
+		ledger.DaysToRepay = math.Ceil(debt / ledger.RecentBalanceHours)
+	default:
+		ledger.DaysToRepay = -1
+	}
+
+	switch {
+	case debt == 0 && ledger.RecentBalanceHours >= 0:
+		ledger.Trend = "clear"
+	case ledger.RecentBalanceHours >= sleepDebtTrendThreshold:
+		ledger.Trend = "repaying"
+	case ledger.RecentBalanceHours <= -sleepDebtTrendThreshold:
+		ledger.Trend = "accumulating"
+	default:
+		ledger.Trend = "stable"
+	}
+
+	return ledger
+}
+
+// formatDaysToRepay describes the repayment projection
+func formatDaysToRepay(l *Localizer, ledger SleepDebtLedger) string {
+	switch {
+	case ledger.CurrentDebtHours == 0:
+		return l.T("no debt to repay")
+	case ledger.DaysToRepay < 0:
+		return l.T("not repaying at current habits")
+	default:
+		return l.T("~%.0f nights at current habits", ledger.DaysToRepay)
+	}
+}
+
+// FormatSleepDebtLedger renders the ledger summary with a chart-ready daily series
+func (h *HealthAnalyzer) FormatSleepDebtLedger(ledger SleepDebtLedger) string {
+	if ledger.Trend == "no_data" {
+		return "# Sleep Debt Ledger\n\nNo main sleep records were found for this period."
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("# Sleep Debt Ledger (%d nights)\n\n", len(ledger.Entries)))
+
+	builder.WriteString("## Balance\n")
+	builder.WriteString(fmt.Sprintf("- **Current debt:** %.1f hours (%s)\n", ledger.CurrentDebtHours, ledger.Trend))
+	builder.WriteString(fmt.Sprintf("- **Peak debt in window:** %.1f hours\n", ledger.PeakDebtHours))
+	builder.WriteString(fmt.Sprintf("- **Average nightly balance:** %+.1f hours (last %d nights: %+.1f)\n", ledger.AverageBalanceHours, sleepDebtRecentNights, ledger.RecentBalanceHours))
+	builder.WriteString(fmt.Sprintf("- **Projected repayment:** %s\n\n", formatDaysToRepay(h.locale, ledger)))
+
+	builder.WriteString("## Interpretation\n")
+	switch ledger.Trend {
+	case "accumulating":
+		builder.WriteString("Debt is still growing. Even 20-30 extra minutes a night would turn the trend around.\n\n")
+	case "repaying":
+		builder.WriteString("Recent nights are paying the debt down.\n\n")
+	case "clear":
+		builder.WriteString("No outstanding sleep debt.\n\n")
+	default:
+		builder.WriteString("Debt is holding roughly steady: nights meet need on average but are not paying down the backlog.\n\n")
+	}
+
+	builder.WriteString("## Daily Series\n\n```json\n")
+	series, err := json.MarshalIndent(ledger.Entries, "", "  ")
+	if err == nil {
+		builder.Write(series)
+	}
+	builder.WriteString("\n```\n")
+
+	return builder.String()
+}
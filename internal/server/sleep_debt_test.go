@@ -0,0 +1,44 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_BuildSleepDebtLedger(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	hour := int(time.Hour / time.Millisecond)
+	start := time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC)
+
+	// Four short nights (6h vs 8h need) then three long ones (9h)
+	hours := []int{6, 6, 6, 6, 9, 9, 9}
+	var sleeps []WhoopSleep
+	for i, h := range hours {
+		sleep := WhoopSleep{Start: start.AddDate(0, 0, i)}
+		sleep.End = sleep.Start.Add(time.Duration(h) * time.Hour)
+		sleep.Score.StageSummary.TotalInBedTimeMilli = h * hour
+		sleep.Score.SleepNeeded.BaselineMilli = 8 * hour
+		sleeps = append(sleeps, sleep)
+	}
+
+	ledger := analyzer.BuildSleepDebtLedger(sleeps)
+
+	if len(ledger.Entries) != 7 {
+		t.Fatalf("Expected 7 ledger entries, got %d", len(ledger.Entries))
+	}
+	if ledger.PeakDebtHours != 8 {
+		t.Errorf("Expected peak debt of 8h, got %.1f", ledger.PeakDebtHours)
+	}
+	if ledger.CurrentDebtHours != 5 {
+		t.Errorf("Expected 5h remaining debt, got %.1f", ledger.CurrentDebtHours)
+	}
+	// Recent balance averages (4*-2 + 3*1)/7 < 0, so the debt is not being repaid on average
+	if ledger.DaysToRepay != -1 {
+		t.Errorf("Expected no repayment projection, got %.0f", ledger.DaysToRepay)
+	}
+
+	// A surplus never drives the running debt below zero
+	if ledger.Entries[0].Debt != 2 || ledger.Entries[6].Debt != 5 {
+		t.Errorf("Unexpected running debt: first %.1f, last %.1f", ledger.Entries[0].Debt, ledger.Entries[6].Debt)
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"fmt"
+	"math"
+)
+
+// sleepScoreDivergence is the gap, as a fraction, between a computed sleep
+// score and Whoop's own that is flagged in reports
+const sleepScoreDivergence = 0.2
+
+// Sleep scores compared with Whoop's reported percentages
+const (
+	sleepScoreConsistency = "consistency"
+	sleepScorePerformance = "performance"
+)
+
+// SleepScoreDivergence is a computed sleep score that disagrees with the
+// percentage Whoop reports for the same nights
+type SleepScoreDivergence struct {
+	Metric   string  `json:"metric"` // "consistency" or "performance"
+	Computed float64 `json:"computed"`
+	Whoop    float64 `json:"whoop"`
+}
+
+// whoopSleepScores averages Whoop's sleep consistency and performance
+// percentages as fractions. Nights without a value (Whoop needs several
+// nights of history for consistency) are skipped.
+func (h *HealthAnalyzer) whoopSleepScores(mainSleeps []WhoopSleep) (consistency, performance float64) {
+	var consistencies, performances []float64
+	for _, sleep := range mainSleeps {
+		if value := sleep.Score.SleepConsistencyPercentage; value > 0 {
+			consistencies = append(consistencies, value/100)
+		}
+		if value := sleep.Score.SleepPerformancePercentage; value > 0 {
+			performances = append(performances, value/100)
+		}
+	}
+	if len(consistencies) > 0 {
+		consistency = h.calculateMean(consistencies)
+	}
+	if len(performances) > 0 {
+		performance = h.calculateMean(performances)
+	}
+	return consistency, performance
+}
+
+// computedSleepPerformance averages sleep obtained over the nightly baseline
+// need, capped at 100%. Whoop's own performance also counts debt, strain, and
+// naps in the need.
+func (h *HealthAnalyzer) computedSleepPerformance(mainSleeps []WhoopSleep) float64 {
+	if len(mainSleeps) == 0 {
+		return 0
+	}
+	performances := make([]float64, 0, len(mainSleeps))
+	for _, sleep := range mainSleeps {
+		need := float64(sleep.Score.SleepNeeded.BaselineMilli) / (1000 * 60 * 60)
+		if need <= 0 {
+			need = defaultSleepNeedHours
+		}
+		performances = append(performances, math.Min(h.sleepHours(sleep)/need, 1))
+	}
+	return h.calculateMean(performances)
+}
+
+// sleepScoreDivergences flags the computed scores that differ from Whoop's by
+// sleepScoreDivergence or more
+func sleepScoreDivergences(analysis SleepAnalysis) []SleepScoreDivergence {
+	var divergences []SleepScoreDivergence
+	for _, pair := range []SleepScoreDivergence{
+		{Metric: sleepScoreConsistency, Computed: analysis.ConsistencyScore, Whoop: analysis.WhoopConsistency},
+		{Metric: sleepScorePerformance, Computed: analysis.PerformanceScore, Whoop: analysis.WhoopPerformance},
+	} {
+		if pair.Whoop > 0 && math.Abs(pair.Computed-pair.Whoop) >= sleepScoreDivergence {
+			divergences = append(divergences, pair)
+		}
+	}
+	return divergences
+}
+
+// formatSleepDivergence explains a divergence between a computed sleep score
+// and Whoop's, and which one to read
+func formatSleepDivergence(l *Localizer, divergence SleepScoreDivergence) string {
+	computed, whoop := divergence.Computed*100, divergence.Whoop*100
+	if divergence.Metric == sleepScoreConsistency {
+		return l.T("Computed consistency (%.0f%%) differs from Whoop's (%.0f%%): the computed score only measures how much sleep duration varies, while Whoop's also compares bed and wake times, so prefer Whoop's.", computed, whoop)
+	}
+	return l.T("Computed performance (%.0f%%) differs from Whoop's (%.0f%%): the computed score compares sleep with the baseline need only, while Whoop's need also adds sleep debt and strain, so prefer Whoop's.", computed, whoop)
+}
+
+// scheduleConsistency is the sleep consistency recommendations act on:
+// Whoop's when reported, since it measures bed and wake timing, otherwise the
+// computed score
+func scheduleConsistency(analysis SleepAnalysis) float64 {
+	if analysis.WhoopConsistency > 0 {
+		return analysis.WhoopConsistency
+	}
+	return analysis.ConsistencyScore
+}
+
+// formatSleepScore renders a computed sleep score with Whoop's alongside,
+// e.g. "62.0% (Whoop: 85.0%)", or the computed score alone when Whoop did not
+// report one
+func formatSleepScore(l *Localizer, computed, whoop float64) string {
+	if whoop <= 0 {
+		return fmt.Sprintf("%.1f%%", computed*100)
+	}
+	return l.T("%.1f%% (Whoop: %.1f%%)", computed*100, whoop*100)
+}
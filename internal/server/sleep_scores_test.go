@@ -0,0 +1,70 @@
+package server
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scoredSleep builds a main sleep of hours against an 8-hour need with
+// Whoop's consistency and performance percentages
+func scoredSleep(day int, hours, consistency, performance float64) WhoopSleep {
+	hour := float64(time.Hour / time.Millisecond)
+	wake := time.Date(2024, 6, 1, 7, 0, 0, 0, time.UTC).AddDate(0, 0, day)
+	sleep := WhoopSleep{Start: wake.Add(-time.Duration(hours * float64(time.Hour))), End: wake, ScoreState: "SCORED"}
+	sleep.Score.StageSummary.TotalInBedTimeMilli = int(hours * hour)
+	sleep.Score.SleepNeeded.BaselineMilli = int(8 * hour)
+	sleep.Score.SleepConsistencyPercentage = consistency
+	sleep.Score.SleepPerformancePercentage = performance
+	return sleep
+}
+
+func TestSleepAnalysisReportsWhoopScores(t *testing.T) {
+	// Identical 6-hour nights: duration never varies, but Whoop rates the
+	// timing as irregular
+	var sleeps []WhoopSleep
+	for day := 0; day < 7; day++ {
+		consistency := 50.0
+		if day == 0 {
+			consistency = 0 // not enough history yet
+		}
+		sleeps = append(sleeps, scoredSleep(day, 6, consistency, 70))
+	}
+
+	analysis := NewHealthAnalyzer().analyzeSleepPatterns(sleeps)
+	if analysis.ConsistencyScore != 1 || analysis.WhoopConsistency != 0.5 {
+		t.Errorf("consistency = %.2f (Whoop %.2f), want 1 (Whoop 0.5)", analysis.ConsistencyScore, analysis.WhoopConsistency)
+	}
+	if analysis.PerformanceScore != 0.75 || math.Abs(analysis.WhoopPerformance-0.7) > 1e-9 {
+		t.Errorf("performance = %.2f (Whoop %.2f), want 0.75 (Whoop 0.7)", analysis.PerformanceScore, analysis.WhoopPerformance)
+	}
+	if len(analysis.ScoreDivergences) != 1 || analysis.ScoreDivergences[0].Metric != sleepScoreConsistency {
+		t.Fatalf("ScoreDivergences = %+v, want consistency only", analysis.ScoreDivergences)
+	}
+	if got := scheduleConsistency(analysis); got != 0.5 {
+		t.Errorf("scheduleConsistency() = %.2f, want Whoop's 0.5", got)
+	}
+
+	if got := formatSleepScore(nil, analysis.ConsistencyScore, analysis.WhoopConsistency); got != "100.0% (Whoop: 50.0%)" {
+		t.Errorf("formatSleepScore() = %q", got)
+	}
+	if got := formatSleepDivergence(nil, analysis.ScoreDivergences[0]); !strings.HasPrefix(got, "Computed consistency (100%) differs from Whoop's (50%)") {
+		t.Errorf("formatSleepDivergence() = %q", got)
+	}
+}
+
+func TestSleepAnalysisWithoutWhoopScores(t *testing.T) {
+	sleeps := []WhoopSleep{scoredSleep(0, 8, 0, 0), scoredSleep(1, 7, 0, 0)}
+
+	analysis := NewHealthAnalyzer().analyzeSleepPatterns(sleeps)
+	if analysis.WhoopConsistency != 0 || analysis.WhoopPerformance != 0 || len(analysis.ScoreDivergences) != 0 {
+		t.Errorf("analysis = %+v, want no Whoop scores or divergences", analysis)
+	}
+	if got := scheduleConsistency(analysis); got != analysis.ConsistencyScore {
+		t.Errorf("scheduleConsistency() = %.2f, want the computed %.2f", got, analysis.ConsistencyScore)
+	}
+	if got := formatSleepScore(nil, 0.9, 0); got != "90.0%" {
+		t.Errorf("formatSleepScore() = %q, want the computed score alone", got)
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+const (
+	// wasoElevatedMinutes is the nightly wake after sleep onset that insomnia
+	// research treats as clinically meaningful
+	wasoElevatedMinutes = 30.0
+	// briefAwakeningMinutes is the average awakening length below which a
+	// night's wake time is spread over many brief awakenings
+	briefAwakeningMinutes = 5.0
+	// wasoPatternShare is the share of nights with elevated WASO that makes
+	// wakefulness a pattern rather than the odd bad night
+	wasoPatternShare = 0.25
+)
+
+// Awakening patterns of a period
+const (
+	wakePatternConsolidated  = "consolidated"
+	wakePatternFragmented    = "fragmented"     // many brief awakenings
+	wakePatternProlongedWake = "prolonged_wake" // few long wake periods
+)
+
+// WakeAnalysis describes wakefulness after sleep onset (WASO) across main
+// sleeps. Whoop's awake time includes the minutes before falling asleep, so
+// WASO here slightly overstates time awake mid-night.
+type WakeAnalysis struct {
+	AverageWASOMinutes  float64       `json:"average_waso_minutes"`
+	MinutesPerAwakening float64       `json:"minutes_per_awakening"`
+	ElevatedWASONights  int           `json:"elevated_waso_nights"` // 30+ minutes awake
+	FragmentedNights    int           `json:"fragmented_nights"`    // elevated WASO in brief awakenings
+	ProlongedWakeNights int           `json:"prolonged_wake_nights"`
+	Pattern             string        `json:"pattern"`  // "consolidated", "fragmented", "prolonged_wake", "no_data"
+	Trend               string        `json:"trend"`    // "improving", "declining", "stable"
+	Estimate            TrendEstimate `json:"estimate"` // WASO minutes
+}
+
+// analyzeWake measures WASO per main sleep, classifies nights with elevated
+// WASO as fragmented (many brief awakenings) or prolonged wake (one or two
+// long ones), and fits a trend to nightly WASO
+func (h *HealthAnalyzer) analyzeWake(mainSleeps []WhoopSleep) WakeAnalysis {
+	if len(mainSleeps) == 0 {
+		return WakeAnalysis{Pattern: "no_data", Trend: "stable"}
+	}
+
+	analysis := WakeAnalysis{Trend: "stable"}
+	wasos := make([]float64, 0, len(mainSleeps))
+	var series []datedValue
+	totalAwakenings := 0
+	for _, sleep := range mainSleeps {
+		waso := float64(sleep.Score.StageSummary.TotalAwakeTimeMilli) / (1000 * 60)
+		awakenings := sleep.Score.StageSummary.DisturbanceCount
+		wasos = append(wasos, waso)
+		series = append(series, datedValue{Date: sleep.End, Value: waso})
+		totalAwakenings += awakenings
+
+		if waso < wasoElevatedMinutes {
+			continue
+		}
+		analysis.ElevatedWASONights++
+		if awakenings > 0 && waso/float64(awakenings) < briefAwakeningMinutes {
+			analysis.FragmentedNights++
+		} else {
+			analysis.ProlongedWakeNights++
+		}
+	}
+
+	analysis.AverageWASOMinutes = h.calculateMean(wasos)
+	if totalAwakenings > 0 {
+		analysis.MinutesPerAwakening = analysis.AverageWASOMinutes * float64(len(wasos)) / float64(totalAwakenings)
+	}
+
+	switch {
+	case float64(analysis.ElevatedWASONights) < wasoPatternShare*float64(len(wasos)):
+		analysis.Pattern = wakePatternConsolidated
+	case analysis.FragmentedNights >= analysis.ProlongedWakeNights:
+		analysis.Pattern = wakePatternFragmented
+	default:
+		analysis.Pattern = wakePatternProlongedWake
+	}
+
+	// Less time awake is an improvement
+	analysis.Estimate = estimateTrend(series)
+	analysis.Trend = analysis.Estimate.Label(false)
+	return analysis
+}
+
+// wakePatternNote explains an awakening pattern for insomnia discussions, or
+// returns an empty string when sleep is consolidated
+func wakePatternNote(l *Localizer, wake WakeAnalysis) string {
+	switch wake.Pattern {
+	case wakePatternFragmented:
+		return l.T("Averaging %.0f minutes awake after sleep onset across many brief awakenings (about %.0f minutes each), a fragmented pattern often linked to hyperarousal, pain, or breathing-related disturbances", wake.AverageWASOMinutes, wake.MinutesPerAwakening)
+	case wakePatternProlongedWake:
+		return l.T("Averaging %.0f minutes awake after sleep onset in long wake periods (about %.0f minutes each), the sleep-maintenance pattern typical of insomnia, where rumination often keeps people awake", wake.AverageWASOMinutes, wake.MinutesPerAwakening)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"math"
+	"testing"
+)
+
+// wakefulSleep is a main sleep with awakeMinutes awake over awakenings
+func wakefulSleep(day int, awakeMinutes float64, awakenings int) WhoopSleep {
+	sleep := scoredSleep(day, 8, 0, 0)
+	sleep.Score.StageSummary.TotalAwakeTimeMilli = int(awakeMinutes * 60 * 1000)
+	sleep.Score.StageSummary.DisturbanceCount = awakenings
+	return sleep
+}
+
+func TestAnalyzeWake(t *testing.T) {
+	var prolonged []WhoopSleep
+	for day := 0; day < 8; day++ {
+		if day < 4 {
+			prolonged = append(prolonged, wakefulSleep(day, 10, 2))
+		} else {
+			prolonged = append(prolonged, wakefulSleep(day, 45, 2))
+		}
+	}
+	var fragmented []WhoopSleep
+	for day := 0; day < 4; day++ {
+		fragmented = append(fragmented, wakefulSleep(day, 40, 12))
+	}
+
+	tests := []struct {
+		name   string
+		sleeps []WhoopSleep
+		want   WakeAnalysis
+	}{
+		{
+			name:   "long wake periods getting worse",
+			sleeps: prolonged,
+			want: WakeAnalysis{AverageWASOMinutes: 27.5, MinutesPerAwakening: 13.75, ElevatedWASONights: 4,
+				ProlongedWakeNights: 4, Pattern: wakePatternProlongedWake, Trend: "declining"},
+		},
+		{
+			name:   "many brief awakenings",
+			sleeps: fragmented,
+			want: WakeAnalysis{AverageWASOMinutes: 40, MinutesPerAwakening: 40.0 / 12, ElevatedWASONights: 4,
+				FragmentedNights: 4, Pattern: wakePatternFragmented, Trend: "stable"},
+		},
+		{
+			name:   "consolidated",
+			sleeps: []WhoopSleep{wakefulSleep(0, 10, 3), wakefulSleep(1, 15, 4), wakefulSleep(2, 5, 1), wakefulSleep(3, 35, 3), wakefulSleep(4, 12, 2)},
+			want: WakeAnalysis{AverageWASOMinutes: 15.4, MinutesPerAwakening: 77.0 / 13, ElevatedWASONights: 1,
+				ProlongedWakeNights: 1, Pattern: wakePatternConsolidated, Trend: "stable"},
+		},
+		{
+			name: "no sleeps",
+			want: WakeAnalysis{Pattern: "no_data", Trend: "stable"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewHealthAnalyzer().analyzeWake(tt.sleeps)
+			if math.Abs(got.AverageWASOMinutes-tt.want.AverageWASOMinutes) > 1e-9 || math.Abs(got.MinutesPerAwakening-tt.want.MinutesPerAwakening) > 1e-9 {
+				t.Errorf("WASO = %.2f min (%.2f per awakening), want %.2f (%.2f)", got.AverageWASOMinutes, got.MinutesPerAwakening,
+					tt.want.AverageWASOMinutes, tt.want.MinutesPerAwakening)
+			}
+			got.AverageWASOMinutes, got.MinutesPerAwakening = tt.want.AverageWASOMinutes, tt.want.MinutesPerAwakening
+			// The fitted trend itself is covered by TestEstimateTrend
+			got.Estimate = TrendEstimate{}
+			if got != tt.want {
+				t.Errorf("analyzeWake() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWakePatternNote(t *testing.T) {
+	if note := wakePatternNote(nil, WakeAnalysis{Pattern: wakePatternConsolidated}); note != "" {
+		t.Errorf("wakePatternNote(consolidated) = %q, want empty", note)
+	}
+	want := "Averaging 45 minutes awake after sleep onset in long wake periods (about 22 minutes each), the sleep-maintenance pattern typical of insomnia, where rumination often keeps people awake"
+	if note := wakePatternNote(nil, WakeAnalysis{AverageWASOMinutes: 45, MinutesPerAwakening: 22.4, Pattern: wakePatternProlongedWake}); note != want {
+		t.Errorf("wakePatternNote(prolonged_wake) = %q", note)
+	}
+}
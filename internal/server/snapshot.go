@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// snapshotLookbackDays is how far back the today/yesterday resources fetch so
+// the previous cycle and its sleep are always in range
+const snapshotLookbackDays = 3
+
+// DailySnapshot is the compact cycle, recovery, and sleep view served by the
+// whoop://health/today and whoop://health/yesterday resources
+type DailySnapshot struct {
+	Day      string            `json:"day"`  // "today" or "yesterday"
+	Date     string            `json:"date"` // local date the cycle started
+	Cycle    *SnapshotCycle    `json:"cycle"`
+	Recovery *SnapshotRecovery `json:"recovery"`
+	Sleep    *SnapshotSleep    `json:"sleep"`
+}
+
+// SnapshotCycle is a physiological cycle's day strain
+type SnapshotCycle struct {
+	Start            time.Time  `json:"start"`
+	End              *time.Time `json:"end,omitempty"` // omitted while the cycle is in progress
+	ScoreState       string     `json:"score_state"`
+	Strain           float64    `json:"strain"`
+	Kilojoule        float64    `json:"kilojoule"`
+	AverageHeartRate int        `json:"average_heart_rate"`
+	MaxHeartRate     int        `json:"max_heart_rate"`
+}
+
+// SnapshotRecovery is the recovery scored at the start of a cycle
+type SnapshotRecovery struct {
+	ScoreState       string  `json:"score_state"`
+	RecoveryScore    float64 `json:"recovery_score"`
+	HRVRmssd         float64 `json:"hrv_rmssd_milli"`
+	RestingHeartRate float64 `json:"resting_heart_rate"`
+	SpO2Percentage   float64 `json:"spo2_percentage,omitempty"`
+	SkinTempCelsius  float64 `json:"skin_temp_celsius,omitempty"`
+	Calibrating      bool    `json:"calibrating,omitempty"`
+}
+
+// SnapshotSleep is the main sleep that led into a cycle
+type SnapshotSleep struct {
+	Start                      time.Time `json:"start"`
+	End                        time.Time `json:"end"`
+	ScoreState                 string    `json:"score_state"`
+	HoursAsleep                float64   `json:"hours_asleep"`
+	SleepPerformancePercentage float64   `json:"sleep_performance_percentage"`
+	SleepEfficiencyPercentage  float64   `json:"sleep_efficiency_percentage"`
+	RespiratoryRate            float64   `json:"respiratory_rate"`
+}
+
+// buildDailySnapshot picks the latest cycle (offset 0) or the one before it
+// (offset 1) along with its recovery and the sleep that recovery was scored
+// from. Fields without a matching record are null.
+func buildDailySnapshot(data *HealthData, offset int) DailySnapshot {
+	snapshot := DailySnapshot{Day: "today"}
+	if offset == 1 {
+		snapshot.Day = "yesterday"
+	}
+
+	cycles := append([]WhoopCycle(nil), data.Cycles...)
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i].Start.After(cycles[j].Start) })
+	if offset >= len(cycles) {
+		return snapshot
+	}
+	cycle := cycles[offset]
+	snapshot.Date = dayKey(localTime(cycle.Start, cycle.TimezoneOffset))
+	snapshot.Cycle = &SnapshotCycle{Start: cycle.Start, ScoreState: cycle.ScoreState}
+	if !cycle.End.IsZero() {
+		end := cycle.End
+		snapshot.Cycle.End = &end
+	}
+	if scored(cycle.ScoreState) {
+		snapshot.Cycle.Strain = cycle.Score.Strain
+		snapshot.Cycle.Kilojoule = cycle.Score.Kilojoule
+		snapshot.Cycle.AverageHeartRate = cycle.Score.AverageHeartRate
+		snapshot.Cycle.MaxHeartRate = cycle.Score.MaxHeartRate
+	}
+
+	sleepID := ""
+	for _, recovery := range data.Recoveries {
+		if recovery.CycleID != cycle.ID {
+			continue
+		}
+		sleepID = recovery.SleepID
+		snapshot.Recovery = &SnapshotRecovery{ScoreState: recovery.ScoreState}
+		if scored(recovery.ScoreState) {
+			score := recovery.Score
+			snapshot.Recovery.RecoveryScore = score.RecoveryScore
+			snapshot.Recovery.HRVRmssd = score.HRVRmssd
+			snapshot.Recovery.RestingHeartRate = score.RestingHeartRate
+			snapshot.Recovery.SpO2Percentage = score.SpO2Percentage
+			snapshot.Recovery.SkinTempCelsius = score.SkinTempCelsius
+			snapshot.Recovery.Calibrating = score.UserCalibrating
+		}
+		break
+	}
+
+	for _, sleep := range data.Sleeps {
+		// Without a recovery, fall back to the main sleep that ended during the cycle
+		matches := sleep.ID == sleepID
+		if sleepID == "" {
+			matches = !sleep.Nap && !sleep.End.Before(cycle.Start) && (cycle.End.IsZero() || sleep.End.Before(cycle.End))
+		}
+		if !matches {
+			continue
+		}
+		snapshot.Sleep = &SnapshotSleep{Start: sleep.Start, End: sleep.End, ScoreState: sleep.ScoreState}
+		if scored(sleep.ScoreState) {
+			stages := sleep.Score.StageSummary
+			snapshot.Sleep.HoursAsleep = float64(stages.TotalInBedTimeMilli-stages.TotalAwakeTimeMilli) / float64(time.Hour/time.Millisecond)
+			snapshot.Sleep.SleepPerformancePercentage = sleep.Score.SleepPerformancePercentage
+			snapshot.Sleep.SleepEfficiencyPercentage = sleep.Score.SleepEfficiencyPercentage
+			snapshot.Sleep.RespiratoryRate = sleep.Score.RespiratoryRate
+		}
+		break
+	}
+	return snapshot
+}
+
+// dailySnapshot fetches the last few days of cycles, recoveries, and sleeps
+// for the authenticated user and builds the snapshot at offset
+func (s *MCPServer) dailySnapshot(ctx context.Context, offset int) (DailySnapshot, error) {
+	startDate, endDate := s.lastDays(snapshotLookbackDays + 1)
+
+	user, err := s.whoopClient.GetUser(ctx)
+	if err != nil {
+		return DailySnapshot{}, fmt.Errorf("failed to get user: %w", err)
+	}
+	userID := user.UserID
+
+	var data HealthData
+	if data.Cycles, err = s.whoopClient.GetCycleData(ctx, startDate, endDate, &userID); err != nil {
+		return DailySnapshot{}, fmt.Errorf("failed to get cycle data: %w", err)
+	}
+	if data.Recoveries, err = s.whoopClient.GetRecoveryData(ctx, startDate, endDate, &userID); err != nil {
+		return DailySnapshot{}, fmt.Errorf("failed to get recovery data: %w", err)
+	}
+	if data.Sleeps, err = s.whoopClient.GetSleepData(ctx, startDate, endDate, &userID); err != nil {
+		return DailySnapshot{}, fmt.Errorf("failed to get sleep data: %w", err)
+	}
+	return buildDailySnapshot(&data, offset), nil
+}
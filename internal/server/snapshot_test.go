@@ -0,0 +1,74 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func snapshotFixture() *HealthData {
+	day := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	data := &HealthData{}
+	for i, id := range []int64{1, 2} {
+		var cycle WhoopCycle
+		cycle.ID = id
+		cycle.Start = day.AddDate(0, 0, i).Add(4 * time.Hour)
+		if i == 0 {
+			cycle.End = cycle.Start.Add(24 * time.Hour)
+		}
+		cycle.ScoreState = "SCORED"
+		cycle.Score.Strain = 10 + float64(i)
+		data.Cycles = append(data.Cycles, cycle)
+
+		var sleep WhoopSleep
+		sleep.ID = []string{"s1", "s2"}[i]
+		sleep.Start, sleep.End = cycle.Start, cycle.Start.Add(7*time.Hour)
+		sleep.ScoreState = "SCORED"
+		sleep.Score.StageSummary.TotalInBedTimeMilli = 7 * 60 * 60 * 1000
+		data.Sleeps = append(data.Sleeps, sleep)
+	}
+
+	var recovery WhoopRecovery
+	recovery.CycleID = 2
+	recovery.SleepID = "s2"
+	recovery.ScoreState = "SCORED"
+	recovery.Score.RecoveryScore = 67
+	recovery.Score.SkinTempCelsius = 33.5
+	data.Recoveries = []WhoopRecovery{recovery}
+	return data
+}
+
+func TestBuildDailySnapshot(t *testing.T) {
+	data := snapshotFixture()
+
+	today := buildDailySnapshot(data, 0)
+	if today.Date != "2024-05-07" || today.Cycle.Strain != 11 || today.Cycle.End != nil {
+		t.Errorf("Expected the in-progress latest cycle, got %+v", today.Cycle)
+	}
+	if today.Recovery == nil || today.Recovery.RecoveryScore != 67 || today.Sleep == nil || today.Sleep.HoursAsleep != 7 {
+		t.Errorf("Expected the matching recovery and sleep, got %+v %+v", today.Recovery, today.Sleep)
+	}
+
+	// Yesterday has no recovery, so its sleep is matched by time
+	yesterday := buildDailySnapshot(data, 1)
+	if yesterday.Day != "yesterday" || yesterday.Date != "2024-05-06" || yesterday.Recovery != nil {
+		t.Errorf("Unexpected yesterday snapshot %+v", yesterday)
+	}
+	if yesterday.Sleep == nil || !yesterday.Sleep.Start.Equal(data.Sleeps[0].Start) {
+		t.Errorf("Expected the first sleep, got %+v", yesterday.Sleep)
+	}
+
+	if empty := buildDailySnapshot(&HealthData{}, 0); empty.Cycle != nil || empty.Sleep != nil {
+		t.Errorf("Expected an empty snapshot without data, got %+v", empty)
+	}
+}
+
+func TestDailySnapshot_Imperial(t *testing.T) {
+	content, err := unitsImperial.MarshalStructured(buildDailySnapshot(snapshotFixture(), 0))
+	if err != nil {
+		t.Fatalf("MarshalStructured() error = %v", err)
+	}
+	if !strings.Contains(string(content), `"skin_temp_fahrenheit": 92.3`) {
+		t.Errorf("Expected converted skin temperature, got %s", content)
+	}
+}
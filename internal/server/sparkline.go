@@ -0,0 +1,155 @@
+package server
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+// sparklineMaxDays caps how many days a sparkline covers so it stays one short line
+const sparklineMaxDays = 30
+
+// sparkBlocks are the eight block heights, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparklineMetrics are the daily metrics summarized with sparklines in reports
+var sparklineMetrics = []string{"recovery", "hrv", "resting_hr", "sleep_hours", "strain"}
+
+// MetricSparkline is a compact text rendering of one metric's recent days
+type MetricSparkline struct {
+	Key       string  `json:"key"`
+	Label     string  `json:"label"`
+	Unit      string  `json:"unit"`
+	Days      int     `json:"days"`
+	Sparkline string  `json:"sparkline"`
+	Min       float64 `json:"min"`
+	Max       float64 `json:"max"`
+	Latest    float64 `json:"latest"`
+	Direction string  `json:"direction"` // "improving", "declining", or "stable"
+}
+
+// sparkline renders values as block characters scaled between their minimum
+// and maximum; NaN values (days without data) become spaces
+func sparkline(values []float64) string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v):
+			b.WriteRune(' ')
+		case hi == lo:
+			b.WriteRune(sparkBlocks[len(sparkBlocks)/2])
+		default:
+			level := int((v - lo) / (hi - lo) * float64(len(sparkBlocks)-1))
+			b.WriteRune(sparkBlocks[level])
+		}
+	}
+	return b.String()
+}
+
+// trendGlyph is the arrow for a trend label
+func trendGlyph(trend string) string {
+	switch trend {
+	case "improving":
+		return "↗"
+	case "declining":
+		return "↘"
+	case "no_data":
+		return ""
+	default:
+		return "→"
+	}
+}
+
+// dailySeries lays a series out as one value per day ending on end, averaging
+// days with several readings and leaving NaN for days without any
+func dailySeries(series []datedValue, end time.Time, days int) []float64 {
+	averages := dailyAverages(series)
+	values := make([]float64, days)
+	for i := range values {
+		value, ok := averages[dayKey(end.AddDate(0, 0, i-days+1))]
+		if !ok {
+			value = math.NaN()
+		}
+		values[i] = value
+	}
+	return values
+}
+
+// buildSparklines summarizes the last days (at most sparklineMaxDays) of each
+// sparkline metric; metrics without data in the window are left out
+func buildSparklines(data *HealthData, end time.Time, days int) []MetricSparkline {
+	if days > sparklineMaxDays {
+		days = sparklineMaxDays
+	}
+	if days < 1 {
+		return nil
+	}
+	var lines []MetricSparkline
+	for _, key := range sparklineMetrics {
+		metric, _ := lookupMetric(key)
+		values := dailySeries(metric.extract(data), end, days)
+
+		var present []float64
+		for _, v := range values {
+			if !math.IsNaN(v) {
+				present = append(present, v)
+			}
+		}
+		if len(present) == 0 {
+			continue
+		}
+
+		line := MetricSparkline{
+			Key: key, Label: metric.Label, Unit: metric.Unit, Days: days,
+			Sparkline: sparkline(values),
+			Min:       present[0], Max: present[0], Latest: present[len(present)-1],
+			Direction: seriesDirection(present, metric.HigherIsBetter),
+		}
+		for _, v := range present {
+			line.Min, line.Max = math.Min(line.Min, v), math.Max(line.Max, v)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// seriesDirection compares the mean of the later half of a series with the
+// earlier half; changes under 5% count as stable
+func seriesDirection(values []float64, higherIsBetter bool) string {
+	if len(values) < 4 {
+		return "stable"
+	}
+	half := len(values) / 2
+	earlier, _ := stats.SampleMeanVariance(values[:half])
+	later, _ := stats.SampleMeanVariance(values[half:])
+	if earlier == 0 || math.Abs(later-earlier)/math.Abs(earlier) < 0.05 {
+		return "stable"
+	}
+	if (later > earlier) == higherIsBetter {
+		return "improving"
+	}
+	return "declining"
+}
+
+// formatSparklines renders a "Last N Days" section, or nothing without data
+func formatSparklines(l *Localizer, lines []MetricSparkline) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString(l.T("## Last %d Days", lines[0].Days) + "\n")
+	for _, line := range lines {
+		builder.WriteString(l.T("- **%s** `%s` %s %s-%s%s (latest %s%s)", l.T(line.Label), line.Sparkline, trendGlyph(line.Direction),
+			formatTick(line.Min), formatTick(line.Max), line.Unit, formatTick(line.Latest), line.Unit) + "\n")
+	}
+	return builder.String() + "\n"
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]float64{0, 1, 2, 3, 4, 5, 6, 7}); got != "▁▂▃▄▅▆▇█" {
+		t.Errorf("sparkline() = %q", got)
+	}
+	if got := sparkline([]float64{10, math.NaN(), 20}); got != "▁ █" {
+		t.Errorf("Expected a gap for missing days, got %q", got)
+	}
+	if got := sparkline([]float64{5, 5}); got != "▅▅" {
+		t.Errorf("Expected mid-height blocks for a flat series, got %q", got)
+	}
+}
+
+func TestDailySeries(t *testing.T) {
+	end := time.Date(2024, 7, 10, 9, 0, 0, 0, time.UTC)
+	series := []datedValue{
+		{Date: time.Date(2024, 7, 8, 7, 0, 0, 0, time.UTC), Value: 60},
+		{Date: time.Date(2024, 7, 10, 7, 0, 0, 0, time.UTC), Value: 70},
+		{Date: time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC), Value: 80},
+	}
+	values := dailySeries(series, end, 3)
+	if len(values) != 3 || values[0] != 60 || !math.IsNaN(values[1]) || values[2] != 75 {
+		t.Errorf("dailySeries() = %v", values)
+	}
+}
+
+func TestBuildSparklines(t *testing.T) {
+	end := time.Date(2024, 7, 14, 12, 0, 0, 0, time.UTC)
+	data := &HealthData{}
+	for i := 0; i < 14; i++ {
+		var recovery WhoopRecovery
+		recovery.ScoreState = "SCORED"
+		recovery.CreatedAt = end.AddDate(0, 0, i-13)
+		recovery.Score.RecoveryScore = float64(40 + 3*i)
+		data.Recoveries = append(data.Recoveries, recovery)
+	}
+
+	lines := buildSparklines(data, end, 60)
+	if len(lines) == 0 {
+		t.Fatal("Expected a recovery sparkline")
+	}
+	recovery := lines[0]
+	if recovery.Key != "recovery" || recovery.Days != sparklineMaxDays || recovery.Latest != 79 || recovery.Direction != "improving" {
+		t.Errorf("Unexpected sparkline %+v", recovery)
+	}
+	if !strings.HasSuffix(recovery.Sparkline, "█") || strings.Count(recovery.Sparkline, " ") != sparklineMaxDays-14 {
+		t.Errorf("Expected 14 days of blocks after the empty days, got %q", recovery.Sparkline)
+	}
+
+	section := formatSparklines(nil, lines)
+	if !strings.HasPrefix(section, "## Last 30 Days\n- **Recovery** `") || !strings.Contains(section, "↗ 40-79% (latest 79%)") {
+		t.Errorf("Unexpected section:\n%s", section)
+	}
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Sport is a single entry of the Whoop sport catalog
+type Sport struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// SportShare describes how much of the workout volume one sport accounts for
+type SportShare struct {
+	Sport       string  `json:"sport"`
+	Workouts    int     `json:"workouts"`
+	Percentage  float64 `json:"percentage"`
+	TotalStrain float64 `json:"total_strain"`
+}
+
+// defaultSports is the sport ID → name table published in the Whoop developer docs
+var defaultSports = map[int]string{
+	-1: "Activity", 0: "Running", 1: "Cycling", 16: "Baseball", 17: "Basketball",
+	18: "Rowing", 19: "Fencing", 20: "Field Hockey", 21: "Football", 22: "Golf",
+	24: "Ice Hockey", 25: "Lacrosse", 27: "Rugby", 28: "Sailing", 29: "Skiing",
+	30: "Soccer", 31: "Softball", 32: "Squash", 33: "Swimming", 34: "Tennis",
+	35: "Track & Field", 36: "Volleyball", 37: "Water Polo", 38: "Wrestling",
+	39: "Boxing", 42: "Dance", 43: "Pilates", 44: "Yoga", 45: "Weightlifting",
+	47: "Cross Country Skiing", 48: "Functional Fitness", 49: "Duathlon",
+	51: "Gymnastics", 52: "Hiking/Rucking", 53: "Horseback Riding", 55: "Kayaking",
+	56: "Martial Arts", 57: "Mountain Biking", 59: "Powerlifting", 60: "Rock Climbing",
+	61: "Paddleboarding", 62: "Triathlon", 63: "Walking", 64: "Surfing",
+	65: "Elliptical", 66: "Stairmaster", 70: "Meditation", 71: "Other", 73: "Diving",
+	74: "Operations - Tactical", 75: "Operations - Medical", 76: "Operations - Flying",
+	77: "Operations - Water", 82: "Ultimate", 83: "Climber", 84: "Jumping Rope",
+	85: "Australian Football", 86: "Skateboarding", 87: "Coaching", 88: "Ice Bath",
+	89: "Commuting", 90: "Gaming", 91: "Snowboarding", 92: "Motocross", 93: "Caddying",
+	94: "Obstacle Course Racing", 95: "Motor Racing", 96: "HIIT", 97: "Spin",
+	98: "Jiu Jitsu", 99: "Manual Labor", 100: "Cricket", 101: "Pickleball",
+	102: "Inline Skating", 103: "Box Fitness", 104: "Spikeball", 105: "Wheelchair Pushing",
+	106: "Paddle Tennis", 107: "Barre", 108: "Stage Performance", 109: "High Stress Work",
+	110: "Parkour", 111: "Gaelic Football", 112: "Hurling/Camogie", 113: "Circus Arts",
+	121: "Massage Therapy", 123: "Strength Trainer", 125: "Watching Sports",
+	126: "Assault Bike", 127: "Kickboxing", 128: "Stretching", 230: "Table Tennis",
+	231: "Badminton", 232: "Netball", 233: "Sauna", 234: "Disc Golf", 235: "Yard Work",
+	236: "Air Compression", 237: "Percussive Massage", 238: "Paintball",
+	239: "Ice Skating", 240: "Handball",
+}
+
+// SportsCatalog caches the Whoop sport ID → name mapping. It is seeded from the
+// documented table and updated with the names the API returns on workouts, so
+// newly added sports are picked up without a release.
+type SportsCatalog struct {
+	names map[int]string
+	mu    sync.RWMutex
+}
+
+// NewSportsCatalog creates a catalog seeded with the documented Whoop sports
+func NewSportsCatalog() *SportsCatalog {
+	names := make(map[int]string, len(defaultSports))
+	for id, name := range defaultSports {
+		names[id] = name
+	}
+	return &SportsCatalog{names: names}
+}
+
+// Learn records the sport ID → name pairs present on fetched workouts
+func (c *SportsCatalog) Learn(workouts []WhoopWorkout) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, workout := range workouts {
+		if workout.SportID == nil || workout.SportName == "" {
+			continue
+		}
+		c.names[*workout.SportID] = formatSportName(workout.SportName)
+	}
+}
+
+// Name returns the display name for a sport ID
+func (c *SportsCatalog) Name(id int) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	name, ok := c.names[id]
+	return name, ok
+}
+
+// All returns every known sport sorted by ID
+func (c *SportsCatalog) All() []Sport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	sports := make([]Sport, 0, len(c.names))
+	for id, name := range c.names {
+		sports = append(sports, Sport{ID: id, Name: name})
+	}
+	sort.Slice(sports, func(i, j int) bool {
+		return sports[i].ID < sports[j].ID
+	})
+	return sports
+}
+
+// SportFor resolves the display name of a workout's sport
+func (c *SportsCatalog) SportFor(workout WhoopWorkout) string {
+	if workout.SportName != "" {
+		return formatSportName(workout.SportName)
+	}
+	if workout.SportID != nil {
+		if name, ok := c.Name(*workout.SportID); ok {
+			return name
+		}
+		return fmt.Sprintf("Sport %d", *workout.SportID)
+	}
+	return "Unknown"
+}
+
+// formatSportName turns API sport names like "functional-fitness" into "Functional Fitness"
+func formatSportName(name string) string {
+	name = strings.TrimSpace(name)
+	if strings.ContainsAny(name, " &/") {
+		return name
+	}
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+	}
+	return strings.Join(words, " ")
+}
+
+// FormatSportBreakdown renders shares as "60% running, 30% strength, 10% yoga"
+func FormatSportBreakdown(shares []SportShare) string {
+	if len(shares) == 0 {
+		return "No workouts recorded"
+	}
+
+	parts := make([]string, 0, len(shares))
+	for _, share := range shares {
+		parts = append(parts, fmt.Sprintf("%.0f%% %s", share.Percentage, strings.ToLower(share.Sport)))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The strain a day's recovery supports rises linearly from strainTargetBase at
+// 0% recovery to 18 at 100%, in line with Whoop's strain targets
+const (
+	strainTargetBase        = 6.0
+	strainTargetPerRecovery = 0.12
+)
+
+// Day strain more than overreachMargin above the target is overreached; more
+// than underutilizedMargin below it leaves recovery unused
+const (
+	overreachMargin     = 3.0
+	underutilizedMargin = 4.0
+)
+
+// strainBalanceTendencyShare is the share of days overreached or
+// under-utilized that sets a period's tendency
+const strainBalanceTendencyShare = 0.25
+
+// Strain balance tendencies
+const (
+	strainBalanceOverreaching   = "overreaching"
+	strainBalanceUnderutilizing = "underutilizing"
+	strainBalanceInBalance      = "in_balance"
+)
+
+// strainTarget returns the day strain a recovery score supports
+func strainTarget(recovery float64) float64 {
+	return strainTargetBase + strainTargetPerRecovery*recovery
+}
+
+// analyzeStrainBalance compares each cycle's strain with the strain its
+// recovery supported, scoring each week by the share of days in balance. It
+// returns nil when no cycle has a matching recovery.
+func (h *HealthAnalyzer) analyzeStrainBalance(cycles []WhoopCycle, recoveries []WhoopRecovery) *StrainBalance {
+	recoveryByCycle := make(map[int64]float64, len(recoveries))
+	for _, recovery := range recoveries {
+		recoveryByCycle[recovery.CycleID] = recovery.Score.RecoveryScore
+	}
+
+	balance := &StrainBalance{}
+	weeks := make(map[time.Time]*StrainBalanceWeek)
+	for _, cycle := range cycles {
+		recovery, ok := recoveryByCycle[cycle.ID]
+		if !ok {
+			continue
+		}
+		// Cycles begin at sleep onset the night before; date them by their midday
+		day := localTime(cycle.Start, cycle.TimezoneOffset).Add(12 * time.Hour)
+		weekStart := bucketStart(day, granularityWeekly)
+		week, ok := weeks[weekStart]
+		if !ok {
+			week = &StrainBalanceWeek{WeekStart: dayKey(weekStart)}
+			weeks[weekStart] = week
+		}
+
+		target := strainTarget(recovery)
+		balance.Days++
+		week.Days++
+		switch {
+		case cycle.Score.Strain > target+overreachMargin:
+			balance.OverreachedDays++
+			week.OverreachedDays++
+		case cycle.Score.Strain < target-underutilizedMargin:
+			balance.UnderutilizedDays++
+			week.UnderutilizedDays++
+		}
+	}
+	if balance.Days == 0 {
+		return nil
+	}
+
+	for _, week := range weeks {
+		week.Score = balanceScore(week.Days, week.OverreachedDays, week.UnderutilizedDays)
+		balance.Weeks = append(balance.Weeks, *week)
+	}
+	sort.Slice(balance.Weeks, func(i, j int) bool {
+		return balance.Weeks[i].WeekStart < balance.Weeks[j].WeekStart
+	})
+	balance.Score = balanceScore(balance.Days, balance.OverreachedDays, balance.UnderutilizedDays)
+
+	overShare := float64(balance.OverreachedDays) / float64(balance.Days)
+	underShare := float64(balance.UnderutilizedDays) / float64(balance.Days)
+	switch {
+	case overShare >= strainBalanceTendencyShare && overShare >= underShare:
+		balance.Tendency = strainBalanceOverreaching
+	case underShare >= strainBalanceTendencyShare:
+		balance.Tendency = strainBalanceUnderutilizing
+	default:
+		balance.Tendency = strainBalanceInBalance
+	}
+	return balance
+}
+
+// balanceScore is the percentage of days whose strain matched recovery
+func balanceScore(days, overreached, underutilized int) float64 {
+	return float64(days-overreached-underutilized) / float64(days) * 100
+}
+
+// FormatWeeklyBalance renders weekly balance scores as
+// "2024-06-03 71/100, 2024-06-10 86/100"
+func FormatWeeklyBalance(weeks []StrainBalanceWeek) string {
+	parts := make([]string, 0, len(weeks))
+	for _, week := range weeks {
+		parts = append(parts, fmt.Sprintf("%s %.0f/100", week.WeekStart, week.Score))
+	}
+	return strings.Join(parts, ", ")
+}
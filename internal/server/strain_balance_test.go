@@ -0,0 +1,66 @@
+package server
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAnalyzeStrainBalance(t *testing.T) {
+	// Recovery 70 supports strain 14.4: a week and a half in balance, then
+	// four overreached days and three under-utilized ones
+	recoveries, _, cycles := stressScenario(14, func(i int) (float64, float64, float64, float64, float64) {
+		strain := 14.0
+		switch {
+		case i >= 11:
+			strain = 8
+		case i >= 7:
+			strain = 19
+		}
+		return 60, 52, 70, 8, strain
+	})
+	// A cycle without a recovery has no target to compare with
+	cycles = append(cycles, WhoopCycle{ID: 99, Start: cycles[len(cycles)-1].Start.AddDate(0, 0, 1), ScoreState: "SCORED"})
+
+	balance := NewHealthAnalyzer().analyzeStrainBalance(cycles, recoveries)
+	if balance == nil {
+		t.Fatal("analyzeStrainBalance() = nil, want a balance")
+	}
+	if balance.Days != 14 || balance.OverreachedDays != 4 || balance.UnderutilizedDays != 3 {
+		t.Errorf("days = %d (%d overreached, %d under-utilized), want 14 (4, 3)", balance.Days, balance.OverreachedDays, balance.UnderutilizedDays)
+	}
+	if balance.Score != 50 {
+		t.Errorf("Score = %.1f, want 50", balance.Score)
+	}
+	if balance.Tendency != strainBalanceOverreaching {
+		t.Errorf("Tendency = %q, want %q", balance.Tendency, strainBalanceOverreaching)
+	}
+
+	want := []StrainBalanceWeek{
+		{WeekStart: "2024-05-27", Days: 2, Score: 100},
+		{WeekStart: "2024-06-03", Days: 7, OverreachedDays: 2, Score: 500.0 / 7},
+		{WeekStart: "2024-06-10", Days: 5, OverreachedDays: 2, UnderutilizedDays: 3, Score: 0},
+	}
+	if len(balance.Weeks) != len(want) {
+		t.Fatalf("Weeks = %+v, want %d weeks", balance.Weeks, len(want))
+	}
+	for i, week := range balance.Weeks {
+		if week.WeekStart != want[i].WeekStart || week.Days != want[i].Days ||
+			week.OverreachedDays != want[i].OverreachedDays || week.UnderutilizedDays != want[i].UnderutilizedDays ||
+			math.Abs(week.Score-want[i].Score) > 0.01 {
+			t.Errorf("Weeks[%d] = %+v, want %+v", i, week, want[i])
+		}
+	}
+	if got := FormatWeeklyBalance(balance.Weeks); got != "2024-05-27 100/100, 2024-06-03 71/100, 2024-06-10 0/100" {
+		t.Errorf("FormatWeeklyBalance() = %q", got)
+	}
+}
+
+func TestAnalyzeStrainBalanceWithoutRecoveries(t *testing.T) {
+	_, _, cycles := stressScenario(7, func(i int) (float64, float64, float64, float64, float64) { return 60, 52, 70, 8, 12 })
+	if balance := NewHealthAnalyzer().analyzeStrainBalance(cycles, nil); balance != nil {
+		t.Errorf("analyzeStrainBalance() = %+v, want nil without recoveries", balance)
+	}
+	if patterns := NewHealthAnalyzer().analyzeActivityPatterns(nil, cycles, nil); patterns.StrainBalance != nil {
+		t.Errorf("StrainBalance = %+v, want nil without recoveries", patterns.StrainBalance)
+	}
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Stress models. The baseline model reads suppressed HRV, elevated resting
+// HR, sleep debt, and strain-recovery mismatch against the personal baseline;
+// the legacy model is the original scoring, kept for comparability.
+const (
+	stressModelBaseline = "baseline"
+	stressModelLegacy   = "legacy"
+)
+
+const (
+	// mismatchRecovery is the recovery score below which a high-strain day
+	// counts as a strain-recovery mismatch
+	mismatchRecovery = 50
+	// stressSleepDebtHours is the sleep debt that maxes out its stress weight
+	stressSleepDebtHours = 7
+)
+
+// stressModels lists the accepted values of the stress model setting
+var stressModels = []string{stressModelBaseline, stressModelLegacy}
+
+// ParseStressModel validates a stress model setting; an empty value selects
+// the baseline model
+func ParseStressModel(value string) (string, error) {
+	switch model := strings.ToLower(strings.TrimSpace(value)); model {
+	case "":
+		return stressModelBaseline, nil
+	case stressModelBaseline, stressModelLegacy:
+		return model, nil
+	default:
+		return "", fmt.Errorf("invalid stress model %q (expected baseline or legacy)", value)
+	}
+}
+
+// NewStressModelFromEnv uses WHOOP_STRESS_MODEL, defaulting to baseline
+func NewStressModelFromEnv(settings Settings) (string, error) {
+	return ParseStressModel(settings("WHOOP_STRESS_MODEL"))
+}
+
+// stressModelProperty is the shared input schema for the stress_model argument
+func stressModelProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Stress model: baseline (suppressed HRV, elevated resting HR, sleep debt, strain-recovery mismatch) or legacy (the original scoring, for comparison with earlier reports); defaults to WHOOP_STRESS_MODEL or baseline",
+		"enum":        stressModels,
+	}
+}
+
+// WithStressModel returns a copy of the analyzer that scores stress with the
+// given model, keeping the configured model when model is empty
+func (h *HealthAnalyzer) WithStressModel(model string) (*HealthAnalyzer, error) {
+	if model == "" {
+		return h, nil
+	}
+	parsed, err := ParseStressModel(model)
+	if err != nil {
+		return nil, err
+	}
+	scored := *h
+	scored.stressModel = parsed
+	return &scored, nil
+}
+
+// stressLevel buckets a 0-100 physiological stress score
+func stressLevel(score float64) string {
+	switch {
+	case score > 70:
+		return "critical"
+	case score > 50:
+		return "high"
+	case score > 30:
+		return "moderate"
+	default:
+		return "low"
+	}
+}
+
+// analyzeLegacyStressIndicators is the original stress model, kept so scores
+// stay comparable with earlier reports. It counts HRV above the baseline as
+// stress, which is backwards: suppressed HRV is the stress signal.
+func (h *HealthAnalyzer) analyzeLegacyStressIndicators(recoveries []WhoopRecovery, thresholds BaselineThresholds) StressIndicators {
+	if len(recoveries) == 0 {
+		return StressIndicators{
+			Model:       stressModelLegacy,
+			StressLevel: "unknown",
+		}
+	}
+
+	var hrvValues []float64
+	var restingHRValues []float64
+	var recoveryScores []float64
+
+	elevatedHRVDays := 0
+	highRestingHRDays := 0
+	cycleAdjustedDays := 0
+	luteal := h.lutealDays(recoveries)
+	poorRecoveryStreak := 0
+	currentPoorStreak := 0
+
+	for _, recovery := range recoveries {
+		hrv := recovery.Score.HRVRmssd
+		rhr := recovery.Score.RestingHeartRate
+		score := recovery.Score.RecoveryScore
+
+		hrvValues = append(hrvValues, hrv)
+		restingHRValues = append(restingHRValues, rhr)
+		recoveryScores = append(recoveryScores, score)
+
+		// Check for elevated HRV (indicating potential stress)
+		if thresholds.HRVHigh > 0 {
+			if hrv > thresholds.HRVHigh {
+				elevatedHRVDays++
+			}
+		} else if len(hrvValues) > 1 {
+			avgHRV := h.calculateMean(hrvValues[:len(hrvValues)-1])
+			if hrv > avgHRV*defaultHRVElevationRatio {
+				elevatedHRVDays++
+			}
+		}
+
+		// Check for elevated resting heart rate, allowing for the expected luteal rise
+		rhrLimit := 0.0
+		if thresholds.RHRHigh > 0 {
+			rhrLimit = thresholds.RHRHigh
+		} else if len(restingHRValues) > 1 {
+			rhrLimit = h.calculateMean(restingHRValues[:len(restingHRValues)-1]) + defaultRHRElevationBPM
+		}
+		if rhrLimit > 0 && rhr > rhrLimit {
+			if luteal[dayKey(recovery.CreatedAt)] && rhr <= rhrLimit+lutealRHRAllowance {
+				cycleAdjustedDays++
+			} else {
+				highRestingHRDays++
+			}
+		}
+
+		// Track poor recovery streaks
+		if score < thresholds.PoorRecovery {
+			currentPoorStreak++
+			if currentPoorStreak > poorRecoveryStreak {
+				poorRecoveryStreak = currentPoorStreak
+			}
+		} else {
+			currentPoorStreak = 0
+		}
+	}
+
+	// Calculate physiological stress score (0-100)
+	stressFactors := 0.0
+	if len(recoveries) > 0 {
+		stressFactors += float64(elevatedHRVDays) / float64(len(recoveries)) * 30   // 30% weight
+		stressFactors += float64(highRestingHRDays) / float64(len(recoveries)) * 25 // 25% weight
+		stressFactors += float64(poorRecoveryStreak) / 7.0 * 25                     // 25% weight
+
+		avgRecovery := h.calculateMean(recoveryScores)
+		if avgRecovery < 50 {
+			stressFactors += (50 - avgRecovery) / 50 * 20 // 20% weight
+		}
+	}
+
+	return StressIndicators{
+		Model:               stressModelLegacy,
+		ElevatedHRVDays:     elevatedHRVDays,
+		HighRestingHRDays:   highRestingHRDays,
+		CycleAdjustedDays:   cycleAdjustedDays,
+		PoorRecoveryStreak:  poorRecoveryStreak,
+		StressLevel:         stressLevel(stressFactors),
+		PhysiologicalStress: stressFactors,
+	}
+}
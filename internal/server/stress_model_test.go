@@ -0,0 +1,137 @@
+package server
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// stressScenario builds days of recoveries, main sleeps, and cycles; day i is
+// described by the returned values of day(i)
+func stressScenario(days int, day func(i int) (hrv, rhr, recovery, sleepHours, strain float64)) ([]WhoopRecovery, []WhoopSleep, []WhoopCycle) {
+	hour := float64(time.Hour / time.Millisecond)
+	start := time.Date(2024, 6, 1, 7, 0, 0, 0, time.UTC)
+
+	var recoveries []WhoopRecovery
+	var sleeps []WhoopSleep
+	var cycles []WhoopCycle
+	for i := 0; i < days; i++ {
+		hrv, rhr, score, sleepHours, strain := day(i)
+		wake := start.AddDate(0, 0, i)
+
+		recovery := WhoopRecovery{CycleID: int64(i + 1), CreatedAt: wake, ScoreState: "SCORED"}
+		recovery.Score.HRVRmssd = hrv
+		recovery.Score.RestingHeartRate = rhr
+		recovery.Score.RecoveryScore = score
+		recoveries = append(recoveries, recovery)
+
+		sleep := WhoopSleep{Start: wake.Add(-time.Duration(sleepHours * float64(time.Hour))), End: wake, ScoreState: "SCORED"}
+		sleep.Score.StageSummary.TotalInBedTimeMilli = int(sleepHours * hour)
+		sleep.Score.SleepNeeded.BaselineMilli = int(8 * hour)
+		sleeps = append(sleeps, sleep)
+
+		cycle := WhoopCycle{ID: int64(i + 1), Start: sleep.Start, ScoreState: "SCORED"}
+		cycle.Score.Strain = strain
+		cycles = append(cycles, cycle)
+	}
+	return recoveries, sleeps, cycles
+}
+
+func TestAnalyzeStressIndicatorsScenarios(t *testing.T) {
+	thresholds := BaselineThresholds{Source: "personal_baseline", HRVLow: 45, HRVHigh: 80, RHRHigh: 60, PoorRecovery: 33}
+
+	calm := func(i int) (float64, float64, float64, float64, float64) { return 60, 52, 70, 8, 10 }
+	overreached := func(i int) (float64, float64, float64, float64, float64) {
+		if i < 7 {
+			return calm(i)
+		}
+		return 35, 64, 25, 6, 16
+	}
+	highHRV := func(i int) (float64, float64, float64, float64, float64) { return 90, 52, 75, 8, 10 }
+
+	tests := []struct {
+		name       string
+		day        func(i int) (float64, float64, float64, float64, float64)
+		want       StressIndicators
+		wantLegacy string
+	}{
+		{
+			name:       "calm",
+			day:        calm,
+			want:       StressIndicators{Model: stressModelBaseline, StressLevel: "low"},
+			wantLegacy: "low",
+		},
+		{
+			// Suppressed HRV, raised RHR, short nights, and hard days on red recoveries
+			name: "overreached",
+			day:  overreached,
+			want: StressIndicators{
+				Model: stressModelBaseline, SuppressedHRVDays: 7, HighRestingHRDays: 7, SleepDebtHours: 14,
+				StrainMismatchDays: 7, PoorRecoveryStreak: 7, StressLevel: "high", PhysiologicalStress: 65,
+			},
+		},
+		{
+			// High HRV is a sign of good recovery, not stress
+			name:       "elevated HRV",
+			day:        highHRV,
+			want:       StressIndicators{Model: stressModelBaseline, StressLevel: "low"},
+			wantLegacy: "low",
+		},
+	}
+
+	analyzer := NewHealthAnalyzer()
+	for _, tt := range tests {
+		recoveries, sleeps, cycles := stressScenario(14, tt.day)
+		got := analyzer.analyzeStressIndicators(recoveries, sleeps, cycles, thresholds)
+		got.PhysiologicalStress = math.Round(got.PhysiologicalStress*10) / 10
+		if got != tt.want {
+			t.Errorf("%s: analyzeStressIndicators() =\n%+v\nwant\n%+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLegacyStressModel(t *testing.T) {
+	thresholds := BaselineThresholds{Source: "personal_baseline", HRVLow: 45, HRVHigh: 80, RHRHigh: 60, PoorRecovery: 33}
+	recoveries, sleeps, cycles := stressScenario(14, func(i int) (float64, float64, float64, float64, float64) {
+		return 90, 52, 75, 8, 10
+	})
+
+	legacy, err := NewHealthAnalyzer().WithStressModel("legacy")
+	if err != nil {
+		t.Fatalf("WithStressModel() error = %v", err)
+	}
+	got := legacy.analyzeStressIndicators(recoveries, sleeps, cycles, thresholds)
+
+	// The legacy model keeps counting elevated HRV as stress, for comparability
+	if got.Model != stressModelLegacy || got.ElevatedHRVDays != 14 || got.SuppressedHRVDays != 0 {
+		t.Errorf("legacy model = %+v, want 14 elevated HRV days", got)
+	}
+	if got.PhysiologicalStress != 30 || got.StressLevel != "low" {
+		t.Errorf("legacy score = %.1f (%s), want 30 (low)", got.PhysiologicalStress, got.StressLevel)
+	}
+}
+
+func TestAnalyzeStressIndicatorsRunningMean(t *testing.T) {
+	// Without a personal baseline, HRV 20% under the running mean counts as suppressed
+	recoveries, sleeps, cycles := stressScenario(10, func(i int) (float64, float64, float64, float64, float64) {
+		if i >= 8 {
+			return 40, 52, 60, 8, 10
+		}
+		return 60, 52, 60, 8, 10
+	})
+	got := NewHealthAnalyzer().analyzeStressIndicators(recoveries, sleeps, cycles, defaultThresholds())
+	if got.SuppressedHRVDays != 2 {
+		t.Errorf("SuppressedHRVDays = %d, want 2", got.SuppressedHRVDays)
+	}
+}
+
+func TestParseStressModel(t *testing.T) {
+	for value, want := range map[string]string{"": stressModelBaseline, "Legacy": stressModelLegacy, " baseline ": stressModelBaseline} {
+		if got, err := ParseStressModel(value); err != nil || got != want {
+			t.Errorf("ParseStressModel(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseStressModel("v2"); err == nil {
+		t.Error("ParseStressModel(\"v2\") should fail")
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// runSyncCommand implements `whoop-mcp-server sync`: it brings the local
+// datastore up to date without a client attached, recomputing each account's
+// personal baseline, recording this week's burnout score, and applying the
+// retention policy. It suits a daily cron job.
+func runSyncCommand(settings Settings, args []string) error {
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	userID := flags.Int("user-id", 0, "Only sync this user ID from whoop://accounts (default: every account)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	server, err := NewMCPServer(settings)
+	if err != nil {
+		return err
+	}
+
+	targets := []*int{userIDArg(*userID)}
+	if *userID == 0 && server.whoopClient.Accounts() != nil {
+		for _, account := range server.whoopClient.Accounts().Summaries() {
+			targets = append(targets, userIDArg(account.UserID))
+		}
+	}
+
+	now := time.Now()
+	failed := 0
+	for _, target := range targets {
+		label := "authenticated user"
+		if target != nil {
+			label = fmt.Sprintf("user %d", *target)
+		}
+
+		baseline, err := server.refreshBaseline(context.Background(), target, now)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", label, err)
+			failed++
+			continue
+		}
+		risk, err := server.burnoutRisk(context.Background(), 0, target)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", label, err)
+			failed++
+			continue
+		}
+		fmt.Printf("✅ %s: baseline from %d day(s), burnout %s\n", label, baseline.Long.Days, risk.Current.Level)
+	}
+
+	server.applyRetention(now)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d account(s) could not be synced", failed, len(targets))
+	}
+	return nil
+}
@@ -0,0 +1,444 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// syntheticSports are the activities generated workouts rotate through,
+// with a typical distance per minute (0 for stationary sports)
+var syntheticSports = []struct {
+	name            string
+	metersPerMinute float64
+}{
+	{"running", 170},
+	{"cycling", 420},
+	{"functional-fitness", 0},
+	{"yoga", 0},
+	{"walking", 90},
+}
+
+// Synthetic episode kinds
+const (
+	// EpisodeStress is a stretch of work or life stress: shorter, later, more
+	// disturbed sleep and suppressed recovery
+	EpisodeStress = "stress"
+	// EpisodeIllness is a viral illness: raised skin temperature, respiratory
+	// rate, and resting heart rate, crashed HRV, and no training
+	EpisodeIllness = "illness"
+	// EpisodeTravel is a trip across timezones, with jet lag on arrival and
+	// again on return
+	EpisodeTravel = "travel"
+	// EpisodeTraining is a training block: daily workouts with load building
+	// until recovery sags
+	EpisodeTraining = "training"
+)
+
+// SyntheticEpisode is a stretch of days that departs from the athlete's normal
+type SyntheticEpisode struct {
+	Kind       string
+	DaysAgo    int     // the episode starts this many days before the last generated day
+	Days       int     // default 7
+	Intensity  float64 // scales the effect, default 1
+	ShiftHours int     // travel only: timezone change, positive when traveling east
+}
+
+// SyntheticOptions shapes generated health data. Zero fields take the
+// defaults noted beside them.
+type SyntheticOptions struct {
+	Seed            int64          // the same seed generates the same records
+	UserID          int64          // default 1
+	End             time.Time      // the last day generated; default today
+	Days            int            // default 30
+	Location        *time.Location // wall clock for sleeps and workouts; default UTC
+	RecoveryMean    float64        // recovery score, default 65
+	HRVMean         float64        // RMSSD in ms, default 60
+	HRVTrend        float64        // HRV change over each 30 days, in ms
+	RestingHRMean   float64        // bpm, default 55
+	SleepHours      float64        // time in bed, default 7.5
+	WorkoutsPerWeek float64        // default 4
+	NapsPerWeek     float64        // default 0
+	Episodes        []SyntheticEpisode
+}
+
+// withDefaults fills in unset options
+func (o SyntheticOptions) withDefaults() SyntheticOptions {
+	if o.UserID == 0 {
+		o.UserID = 1
+	}
+	if o.Location == nil {
+		o.Location = time.UTC
+	}
+	if o.End.IsZero() {
+		o.End = time.Now()
+	}
+	if o.Days <= 0 {
+		o.Days = 30
+	}
+	if o.RecoveryMean == 0 {
+		o.RecoveryMean = 65
+	}
+	if o.HRVMean == 0 {
+		o.HRVMean = 60
+	}
+	if o.RestingHRMean == 0 {
+		o.RestingHRMean = 55
+	}
+	if o.SleepHours == 0 {
+		o.SleepHours = 7.5
+	}
+	if o.WorkoutsPerWeek == 0 {
+		o.WorkoutsPerWeek = 4
+	}
+	episodes := make([]SyntheticEpisode, len(o.Episodes))
+	for i, episode := range o.Episodes {
+		if episode.Days <= 0 {
+			episode.Days = 7
+		}
+		if episode.Intensity == 0 {
+			episode.Intensity = 1
+		}
+		episodes[i] = episode
+	}
+	o.Episodes = episodes
+	return o
+}
+
+// syntheticScenarios are named multi-month datasets for demos and tests,
+// each built from episodes counted back from the last day
+var syntheticScenarios = map[string][]SyntheticEpisode{
+	"steady":   nil,
+	"stress":   {{Kind: EpisodeStress, DaysAgo: 30, Days: 21}},
+	"illness":  {{Kind: EpisodeIllness, DaysAgo: 2, Days: 6}},
+	"travel":   {{Kind: EpisodeTravel, DaysAgo: 20, Days: 9, ShiftHours: 6}},
+	"training": {{Kind: EpisodeTraining, DaysAgo: 20, Days: 21}},
+	"demo": {
+		{Kind: EpisodeTraining, DaysAgo: 120, Days: 28},
+		{Kind: EpisodeTravel, DaysAgo: 75, Days: 10, ShiftHours: -8},
+		{Kind: EpisodeStress, DaysAgo: 40, Days: 18},
+		{Kind: EpisodeIllness, DaysAgo: 2, Days: 6},
+	},
+}
+
+// SyntheticScenarioNames lists the named scenarios, sorted
+func SyntheticScenarioNames() []string {
+	names := make([]string, 0, len(syntheticScenarios))
+	for name := range syntheticScenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SyntheticScenario returns six months of options for a named scenario,
+// ending at end
+func SyntheticScenario(name string, end time.Time) (SyntheticOptions, error) {
+	episodes, ok := syntheticScenarios[name]
+	if !ok {
+		return SyntheticOptions{}, fmt.Errorf("unknown scenario %q (expected one of %s)", name, strings.Join(SyntheticScenarioNames(), ", "))
+	}
+	return SyntheticOptions{Seed: 1, End: end, Days: 180, HRVTrend: 1, Episodes: episodes}, nil
+}
+
+// syntheticDay is how far one day departs from the athlete's normal, summed
+// over the episodes active on it
+type syntheticDay struct {
+	recovery     float64 // recovery score points
+	hrv          float64 // fraction of HRV lost
+	restingHR    float64 // bpm
+	skinTemp     float64 // °C
+	respiratory  float64 // breaths per minute
+	spo2         float64 // percentage points
+	sleepHours   float64
+	bedtime      time.Duration // later on the local clock when positive
+	disturbances int
+	strain       float64 // day strain
+	workoutOdds  float64 // added to the daily chance of a workout
+	resting      bool    // no workouts at all
+	offsetHours  int     // timezone shift from home
+}
+
+// SyntheticGenerator produces plausible Whoop records: a sleep ending each
+// morning, a physiological cycle starting at each wake, a recovery scored
+// from that sleep, and workouts on some days. Recovery, HRV, and resting
+// heart rate move together the way they do in real data, and episodes layer
+// stress, illness, travel, and training blocks on top.
+type SyntheticGenerator struct {
+	opts SyntheticOptions
+	rng  *rand.Rand
+	// today holds the effects applied to the records generated next
+	today syntheticDay
+	// trend is today's long-term HRV drift in ms
+	trend float64
+}
+
+// NewSyntheticGenerator creates a generator for opts
+func NewSyntheticGenerator(opts SyntheticOptions) *SyntheticGenerator {
+	opts = opts.withDefaults()
+	return &SyntheticGenerator{opts: opts, rng: rand.New(rand.NewSource(opts.Seed))}
+}
+
+// GenerateHealthData generates opts.Days days of records ending on opts.End
+func GenerateHealthData(opts SyntheticOptions) HealthData {
+	return NewSyntheticGenerator(opts).Generate()
+}
+
+// Generate returns every record for the configured days, oldest first
+func (g *SyntheticGenerator) Generate() HealthData {
+	var data HealthData
+	last := g.opts.End.In(g.opts.Location)
+	first := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -(g.opts.Days - 1))
+
+	for i := 0; i < g.opts.Days; i++ {
+		g.today = g.effects(g.opts.Days - 1 - i)
+		g.trend = g.opts.HRVTrend * float64(i-(g.opts.Days-1)) / 30
+		date := first.AddDate(0, 0, i)
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, g.location(date))
+
+		sleep := g.Sleep(day)
+		data.Sleeps = append(data.Sleeps, sleep)
+		if g.rng.Float64() < g.opts.NapsPerWeek/7 {
+			data.Sleeps = append(data.Sleeps, g.Nap(day))
+		}
+
+		cycle := g.Cycle(sleep.End)
+		if n := len(data.Cycles); n > 0 {
+			data.Cycles[n-1].End = cycle.Start
+		}
+		data.Cycles = append(data.Cycles, cycle)
+		data.Recoveries = append(data.Recoveries, g.Recovery(cycle, sleep))
+
+		if !g.today.resting && g.rng.Float64() < g.opts.WorkoutsPerWeek/7+g.today.workoutOdds {
+			data.Workouts = append(data.Workouts, g.Workout(day))
+		}
+	}
+	return data
+}
+
+// location returns the wall clock on date: home, or shifted while traveling
+func (g *SyntheticGenerator) location(date time.Time) *time.Location {
+	if g.today.offsetHours == 0 {
+		return g.opts.Location
+	}
+	_, home := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, g.opts.Location).Zone()
+	return time.FixedZone("", home+g.today.offsetHours*3600)
+}
+
+// effects sums the episodes active daysAgo days before the last day
+func (g *SyntheticGenerator) effects(daysAgo int) syntheticDay {
+	var day syntheticDay
+	for _, episode := range g.opts.Episodes {
+		// elapsed counts from 0 on the first day of the episode
+		elapsed := episode.DaysAgo - daysAgo
+		s := episode.Intensity
+
+		if episode.Kind == EpisodeTravel {
+			applyJetLag(&day, episode, elapsed)
+			continue
+		}
+		if elapsed < 0 || elapsed >= episode.Days {
+			continue
+		}
+		switch episode.Kind {
+		case EpisodeStress:
+			day.recovery -= 15 * s
+			day.hrv += 0.15 * s
+			day.restingHR += 3 * s
+			day.sleepHours -= 0.8 * s
+			day.bedtime += time.Duration(45*s) * time.Minute
+			day.disturbances += int(4 * s)
+			day.strain += 1.5 * s
+		case EpisodeIllness:
+			// Symptoms build over the first two days
+			onset := math.Min(float64(elapsed+1)/2, 1) * s
+			day.recovery -= 35 * onset
+			day.hrv += 0.3 * onset
+			day.restingHR += 8 * onset
+			day.skinTemp += 0.9 * onset
+			day.respiratory += 2 * onset
+			day.spo2 -= 2 * onset
+			day.sleepHours += 1.2 * onset
+			day.strain -= 4 * onset
+			day.resting = true
+		case EpisodeTraining:
+			// Load builds over the block and fatigue accumulates with it
+			progress := float64(elapsed+1) / float64(episode.Days) * s
+			day.strain += 2*s + 7*progress
+			day.workoutOdds += 1
+			day.recovery -= 15 * progress
+			day.hrv += 0.12 * progress
+			day.restingHR += 3 * progress
+		}
+	}
+	return day
+}
+
+// applyJetLag moves the clock for the length of a trip. Sleep timing lags the
+// new clock by an hour less each day, on arrival and again on return.
+func applyJetLag(day *syntheticDay, trip SyntheticEpisode, elapsed int) {
+	lag := func(shift, sinceChange int) {
+		remaining := math.Max(math.Abs(float64(shift))-float64(sinceChange), 0)
+		if remaining == 0 {
+			return
+		}
+		if shift < 0 {
+			remaining = -remaining
+		}
+		// Traveling east the body still keeps home time, so bed is later on the local clock
+		day.bedtime += time.Duration(remaining * float64(time.Hour))
+		day.recovery -= 3 * math.Abs(remaining) * trip.Intensity
+		day.sleepHours -= 0.1 * math.Abs(remaining)
+	}
+	switch {
+	case elapsed >= 0 && elapsed < trip.Days:
+		day.offsetHours += trip.ShiftHours
+		lag(trip.ShiftHours, elapsed)
+	case elapsed >= trip.Days:
+		lag(-trip.ShiftHours, elapsed-trip.Days)
+	}
+}
+
+// Sleep generates the main sleep ending on the morning of day
+func (g *SyntheticGenerator) Sleep(day time.Time) WhoopSleep {
+	bedtime := day.Add(-90*time.Minute + g.today.bedtime + g.jitter(40*time.Minute))
+	hours := math.Max(g.opts.SleepHours+g.today.sleepHours+g.rng.NormFloat64()*0.6, 3)
+	sleep := g.sleepRecord(bedtime, time.Duration(hours*float64(time.Hour)), false)
+	sleep.Score.StageSummary.DisturbanceCount += g.today.disturbances
+	sleep.Score.RespiratoryRate = round1(sleep.Score.RespiratoryRate + g.today.respiratory)
+	return sleep
+}
+
+// Nap generates an afternoon nap on day
+func (g *SyntheticGenerator) Nap(day time.Time) WhoopSleep {
+	start := day.Add(14*time.Hour + g.jitter(time.Hour))
+	return g.sleepRecord(start, time.Duration(15+g.rng.Intn(30))*time.Minute, true)
+}
+
+func (g *SyntheticGenerator) sleepRecord(start time.Time, inBed time.Duration, nap bool) WhoopSleep {
+	end := start.Add(inBed)
+	inBedMilli := int(inBed.Milliseconds())
+	awake := int(float64(inBedMilli) * (0.05 + g.rng.Float64()*0.07))
+	asleep := inBedMilli - awake
+	slowWave := int(float64(asleep) * (0.18 + g.rng.Float64()*0.06))
+	rem := int(float64(asleep) * (0.20 + g.rng.Float64()*0.06))
+	need := int((8 * time.Hour).Milliseconds())
+
+	sleep := WhoopSleep{
+		ID:             g.uuid(),
+		UserID:         g.opts.UserID,
+		CreatedAt:      end.Add(5 * time.Minute),
+		UpdatedAt:      end.Add(20 * time.Minute),
+		Start:          start,
+		End:            end,
+		TimezoneOffset: end.Format("-07:00"),
+		Nap:            nap,
+		ScoreState:     "SCORED",
+	}
+	stages := &sleep.Score.StageSummary
+	stages.TotalInBedTimeMilli = inBedMilli
+	stages.TotalAwakeTimeMilli = awake
+	stages.TotalSlowWaveSleepTimeMilli = slowWave
+	stages.TotalRemSleepTimeMilli = rem
+	stages.TotalLightSleepTimeMilli = asleep - slowWave - rem
+	stages.SleepCycleCount = max(1, asleep/int((90*time.Minute).Milliseconds()))
+	stages.DisturbanceCount = g.rng.Intn(12) + 2
+	sleep.Score.SleepNeeded.BaselineMilli = need
+	sleep.Score.SleepNeeded.NeedFromSleepDebtMilli = g.rng.Intn(int((30 * time.Minute).Milliseconds()))
+	sleep.Score.RespiratoryRate = round1(14.8 + g.rng.NormFloat64()*0.4)
+	sleep.Score.SleepPerformancePercentage = math.Min(100, math.Round(float64(asleep)/float64(need)*100))
+	sleep.Score.SleepConsistencyPercentage = math.Round(70 + g.rng.Float64()*25)
+	sleep.Score.SleepEfficiencyPercentage = round1(float64(asleep) / float64(inBedMilli) * 100)
+	return sleep
+}
+
+// Cycle generates the physiological cycle starting at wake; its end is set
+// when the next cycle starts, so the latest cycle stays open
+func (g *SyntheticGenerator) Cycle(wake time.Time) WhoopCycle {
+	cycle := WhoopCycle{
+		ID:             100000000 + wake.Unix()/86400, // one cycle per day
+		UserID:         g.opts.UserID,
+		CreatedAt:      wake.Add(2 * time.Minute),
+		UpdatedAt:      wake.Add(12 * time.Hour),
+		Start:          wake,
+		TimezoneOffset: wake.Format("-07:00"),
+		ScoreState:     "SCORED",
+	}
+	cycle.Score.Strain = round1(clamp(11+g.today.strain+g.rng.NormFloat64()*3, 2, 21))
+	cycle.Score.Kilojoule = round1(9000 + g.today.strain*400 + g.rng.NormFloat64()*1500)
+	cycle.Score.AverageHeartRate = int(math.Round(g.opts.RestingHRMean + g.today.restingHR + 12 + g.rng.NormFloat64()*3))
+	cycle.Score.MaxHeartRate = 140 + g.rng.Intn(45)
+	return cycle
+}
+
+// Recovery scores the morning of cycle from the sleep before it. One shared
+// deviation drives recovery up with HRV and down with resting heart rate.
+func (g *SyntheticGenerator) Recovery(cycle WhoopCycle, sleep WhoopSleep) WhoopRecovery {
+	z := g.rng.NormFloat64()
+	recovery := WhoopRecovery{
+		CycleID:    cycle.ID,
+		SleepID:    sleep.ID,
+		UserID:     g.opts.UserID,
+		CreatedAt:  sleep.End.Add(6 * time.Minute),
+		UpdatedAt:  sleep.End.Add(21 * time.Minute),
+		ScoreState: "SCORED",
+	}
+	hrv := (g.opts.HRVMean + g.trend) * (1 - g.today.hrv)
+	recovery.Score.RecoveryScore = math.Round(clamp(g.opts.RecoveryMean+g.today.recovery+z*15+g.rng.NormFloat64()*4, 1, 99))
+	recovery.Score.HRVRmssd = round1(math.Max(10, hrv*(1+0.15*z+g.rng.NormFloat64()*0.05)))
+	recovery.Score.RestingHeartRate = math.Round(g.opts.RestingHRMean + g.today.restingHR - 2*z + g.rng.NormFloat64())
+	recovery.Score.SkinTempCelsius = round1(33.8 + g.today.skinTemp + g.rng.NormFloat64()*0.2)
+	recovery.Score.SpO2Percentage = round1(clamp(96.5+g.today.spo2+g.rng.NormFloat64()*0.8, 90, 100))
+	return recovery
+}
+
+// Workout generates a workout at some point during day
+func (g *SyntheticGenerator) Workout(day time.Time) WhoopWorkout {
+	sport := syntheticSports[g.rng.Intn(len(syntheticSports))]
+	start := day.Add(time.Duration(7+g.rng.Intn(12))*time.Hour + g.jitter(30*time.Minute))
+	minutes := 30 + g.rng.Intn(60)
+	end := start.Add(time.Duration(minutes) * time.Minute)
+
+	workout := WhoopWorkout{
+		ID:             g.uuid(),
+		UserID:         g.opts.UserID,
+		CreatedAt:      end.Add(3 * time.Minute),
+		UpdatedAt:      end.Add(10 * time.Minute),
+		Start:          start,
+		End:            end,
+		TimezoneOffset: start.Format("-07:00"),
+		SportName:      sport.name,
+		ScoreState:     "SCORED",
+	}
+	score := &workout.Score
+	score.Strain = round1(clamp(9+g.today.strain/2+g.rng.NormFloat64()*3, 1, 21))
+	score.AverageHeartRate = 115 + g.rng.Intn(40)
+	score.MaxHeartRate = score.AverageHeartRate + 20 + g.rng.Intn(20)
+	score.Kilojoule = round1(float64(minutes) * (35 + g.rng.Float64()*15))
+	score.PercentRecorded = 100
+	score.DistanceMeter = round1(sport.metersPerMinute * float64(minutes) * (0.9 + g.rng.Float64()*0.2))
+	total := int((time.Duration(minutes) * time.Minute).Milliseconds())
+	zones := &score.ZoneDurations
+	zones.ZoneZeroMilli, zones.ZoneOneMilli, zones.ZoneTwoMilli = total*5/100, total*15/100, total*35/100
+	zones.ZoneThreeMilli, zones.ZoneFourMilli = total*30/100, total*12/100
+	zones.ZoneFiveMilli = total - zones.ZoneZeroMilli - zones.ZoneOneMilli - zones.ZoneTwoMilli - zones.ZoneThreeMilli - zones.ZoneFourMilli
+	return workout
+}
+
+// jitter returns a normally distributed offset with the given spread
+func (g *SyntheticGenerator) jitter(spread time.Duration) time.Duration {
+	return time.Duration(g.rng.NormFloat64() * float64(spread))
+}
+
+// uuid returns a random version 4 UUID drawn from the generator's seed
+func (g *SyntheticGenerator) uuid() string {
+	return fmt.Sprintf("%08x-%04x-4%03x-%04x-%012x",
+		g.rng.Uint32(), g.rng.Intn(1<<16), g.rng.Intn(1<<12), 0x8000|g.rng.Intn(1<<14), g.rng.Int63n(1<<48))
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
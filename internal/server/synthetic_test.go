@@ -0,0 +1,148 @@
+package server
+
+import (
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateHealthData(t *testing.T) {
+	end := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	opts := SyntheticOptions{Seed: 42, End: end, Days: 28, WorkoutsPerWeek: 7, NapsPerWeek: 7}
+	data := GenerateHealthData(opts)
+
+	if len(data.Cycles) != 28 || len(data.Recoveries) != 28 {
+		t.Fatalf("Expected a cycle and recovery per day, got %d and %d", len(data.Cycles), len(data.Recoveries))
+	}
+	if len(data.Workouts) != 28 || len(data.Sleeps) != 56 {
+		t.Errorf("Expected a workout and a nap every day, got %d workouts and %d sleeps", len(data.Workouts), len(data.Sleeps))
+	}
+	if !reflect.DeepEqual(data, GenerateHealthData(opts)) {
+		t.Error("Expected the same seed to generate the same records")
+	}
+
+	sleepIDs := make(map[string]bool)
+	for _, sleep := range data.Sleeps {
+		sleepIDs[sleep.ID] = true
+		if !sleep.End.After(sleep.Start) {
+			t.Errorf("Sleep %s ends before it starts", sleep.ID)
+		}
+	}
+	for i, recovery := range data.Recoveries {
+		if recovery.CycleID != data.Cycles[i].ID || !sleepIDs[recovery.SleepID] {
+			t.Errorf("Recovery %d is not linked to its cycle and sleep", i)
+		}
+		if score := recovery.Score.RecoveryScore; score < 1 || score > 99 {
+			t.Errorf("Recovery score %v is out of range", score)
+		}
+	}
+	for i := 1; i < len(data.Cycles); i++ {
+		if !data.Cycles[i-1].End.Equal(data.Cycles[i].Start) {
+			t.Errorf("Cycle %d does not end when cycle %d starts", i-1, i)
+		}
+	}
+	if !data.Cycles[len(data.Cycles)-1].End.IsZero() {
+		t.Error("Expected the latest cycle to be open")
+	}
+	if last := data.Cycles[len(data.Cycles)-1].Start; last.Format("2006-01-02") != "2024-03-31" {
+		t.Errorf("Expected the last cycle on the end date, got %s", last)
+	}
+}
+
+// scenarioData generates a named scenario ending at a fixed date
+func scenarioData(t *testing.T, name string) HealthData {
+	t.Helper()
+	opts, err := SyntheticScenario(name, time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("SyntheticScenario(%q) error = %v", name, err)
+	}
+	return GenerateHealthData(opts)
+}
+
+func TestSyntheticScenario_Illness(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	steady := scenarioData(t, "steady")
+	if risk := analyzer.PredictIllnessRisk(steady.Recoveries, steady.Sleeps); risk.Level != "low" {
+		t.Errorf("steady: illness risk = %s (%.0f), want low", risk.Level, risk.Score)
+	}
+	ill := scenarioData(t, "illness")
+	if risk := analyzer.PredictIllnessRisk(ill.Recoveries, ill.Sleeps); risk.Level != "elevated" && risk.Level != "high" {
+		t.Errorf("illness: illness risk = %s (%.0f), want elevated or high", risk.Level, risk.Score)
+	}
+}
+
+func TestSyntheticScenario_Travel(t *testing.T) {
+	data := scenarioData(t, "travel")
+	events := NewHealthAnalyzer().DetectTravel(data.Sleeps)
+	if len(events) != 2 {
+		t.Fatalf("DetectTravel() = %+v, want the outbound and return trips", events)
+	}
+	shifts := []float64{events[0].ShiftHours, events[1].ShiftHours}
+	if math.Abs(shifts[0]) != 6 || shifts[0] != -shifts[1] {
+		t.Errorf("Expected shifts of +6h and -6h, got %v", shifts)
+	}
+}
+
+func TestSyntheticScenario_Training(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	if load := analyzer.AnalyzeTrainingLoad(scenarioData(t, "training").Cycles); load.Zone != "overreaching" {
+		t.Errorf("training: zone = %s (ACWR %.2f), want overreaching", load.Zone, load.ACWR)
+	}
+	if load := analyzer.AnalyzeTrainingLoad(scenarioData(t, "steady").Cycles); load.Zone == "overreaching" {
+		t.Errorf("steady: zone = %s (ACWR %.2f), want not overreaching", load.Zone, load.ACWR)
+	}
+}
+
+func TestSyntheticScenario_Stress(t *testing.T) {
+	data := scenarioData(t, "stress")
+	last := data.Recoveries[len(data.Recoveries)-1].CreatedAt
+	episode := syntheticScenarios["stress"][0]
+	var inside, outside []float64
+	for _, recovery := range data.Recoveries {
+		daysAgo := int(last.Sub(recovery.CreatedAt).Hours()/24 + 0.5)
+		if daysAgo <= episode.DaysAgo && daysAgo > episode.DaysAgo-episode.Days {
+			inside = append(inside, recovery.Score.RecoveryScore)
+		} else {
+			outside = append(outside, recovery.Score.RecoveryScore)
+		}
+	}
+	analyzer := NewHealthAnalyzer()
+	if during, normal := analyzer.calculateMean(inside), analyzer.calculateMean(outside); during >= normal-10 {
+		t.Errorf("Expected recovery to drop during stress, got %.1f inside and %.1f outside", during, normal)
+	}
+}
+
+func TestSyntheticScenario_Unknown(t *testing.T) {
+	if _, err := SyntheticScenario("marathon", time.Now()); err == nil || !strings.Contains(err.Error(), "steady") {
+		t.Errorf("Expected an unknown scenario to list the known ones, got %v", err)
+	}
+}
+
+func TestLoadFixturesFromEnv_Scenario(t *testing.T) {
+	now := time.Date(2024, 6, 30, 3, 0, 0, 0, time.UTC)
+	t.Setenv("WHOOP_FIXTURES_DIR", "")
+	t.Setenv("WHOOP_OFFLINE_SCENARIO", "demo")
+	fixtures, err := LoadFixturesFromEnv(os.Getenv, now)
+	if err != nil {
+		t.Fatalf("LoadFixturesFromEnv() error = %v", err)
+	}
+	if fixtures.Source != "scenario demo" || len(fixtures.Recoveries) < 170 {
+		t.Errorf("Expected six months of demo data, got %q with %d recoveries", fixtures.Source, len(fixtures.Recoveries))
+	}
+	for _, sleep := range fixtures.Sleeps {
+		if sleep.Start.After(now) {
+			t.Errorf("Expected no sleeps after now, got one starting %s", sleep.Start)
+		}
+	}
+	if !fixtures.Cycles[0].Start.After(fixtures.Cycles[1].Start) {
+		t.Error("Expected fixtures newest first")
+	}
+
+	t.Setenv("WHOOP_FIXTURES_DIR", t.TempDir())
+	if _, err := LoadFixturesFromEnv(os.Getenv, now); err == nil {
+		t.Error("Expected a scenario and a fixtures directory together to be rejected")
+	}
+}
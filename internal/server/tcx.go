@@ -0,0 +1,163 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// tcxNamespace is the Garmin Training Center Database v2 schema
+const tcxNamespace = "http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+
+// tcxSports maps Whoop sports to the three TCX sport values; everything else
+// is exported as Other
+var tcxSports = map[string]string{
+	"Running": "Running", "Track & Field": "Running",
+	"Cycling": "Biking", "Spin": "Biking", "Mountain Biking": "Biking", "Assault Bike": "Biking",
+}
+
+type tcxDatabase struct {
+	XMLName    xml.Name      `xml:"TrainingCenterDatabase"`
+	Namespace  string        `xml:"xmlns,attr"`
+	Activities []tcxActivity `xml:"Activities>Activity"`
+}
+
+type tcxActivity struct {
+	Sport string `xml:"Sport,attr"`
+	ID    string `xml:"Id"`
+	Lap   tcxLap `xml:"Lap"`
+	Notes string `xml:"Notes,omitempty"`
+}
+
+type tcxLap struct {
+	StartTime        string          `xml:"StartTime,attr"`
+	TotalTimeSeconds float64         `xml:"TotalTimeSeconds"`
+	DistanceMeters   float64         `xml:"DistanceMeters"`
+	Calories         int             `xml:"Calories"`
+	AverageHeartRate *tcxHeartRate   `xml:"AverageHeartRateBpm,omitempty"`
+	MaximumHeartRate *tcxHeartRate   `xml:"MaximumHeartRateBpm,omitempty"`
+	Intensity        string          `xml:"Intensity"`
+	TriggerMethod    string          `xml:"TriggerMethod"`
+	Track            []tcxTrackpoint `xml:"Track>Trackpoint"`
+}
+
+type tcxHeartRate struct {
+	Value int `xml:"Value"`
+}
+
+type tcxTrackpoint struct {
+	Time           string        `xml:"Time"`
+	DistanceMeters float64       `xml:"DistanceMeters"`
+	HeartRate      *tcxHeartRate `xml:"HeartRateBpm,omitempty"`
+}
+
+// TCXFile is one exported workout
+type TCXFile struct {
+	Name    string // suggested file name, e.g. 2024-05-06_running_1a2b3c4d.tcx
+	Sport   string
+	Content []byte
+}
+
+// tcxEligible reports whether a workout has the scored distance a TCX summary needs
+func tcxEligible(workout WhoopWorkout) bool {
+	return scored(workout.ScoreState) && workout.Score.DistanceMeter > 0
+}
+
+// buildTCX renders one workout as a single-lap TCX activity. Whoop has no GPS
+// or per-second samples, so the track is two points at the start and end
+// carrying cumulative distance and the average heart rate; strain goes in the
+// activity notes.
+func buildTCX(workout WhoopWorkout, sport string) ([]byte, error) {
+	tcxSport, ok := tcxSports[sport]
+	if !ok {
+		tcxSport = "Other"
+	}
+	score := workout.Score
+	start, end := workout.Start.UTC().Format(time.RFC3339), workout.End.UTC().Format(time.RFC3339)
+
+	lap := tcxLap{
+		StartTime:        start,
+		TotalTimeSeconds: workout.End.Sub(workout.Start).Seconds(),
+		DistanceMeters:   math.Round(score.DistanceMeter*10) / 10,
+		Calories:         int(math.Round(kilojoulesToKcal(score.Kilojoule))),
+		Intensity:        "Active",
+		TriggerMethod:    "Manual",
+	}
+	var average *tcxHeartRate
+	if score.AverageHeartRate > 0 {
+		average = &tcxHeartRate{Value: score.AverageHeartRate}
+		lap.AverageHeartRate = average
+	}
+	if score.MaxHeartRate > 0 {
+		lap.MaximumHeartRate = &tcxHeartRate{Value: score.MaxHeartRate}
+	}
+	lap.Track = []tcxTrackpoint{
+		{Time: start, DistanceMeters: 0, HeartRate: average},
+		{Time: end, DistanceMeters: lap.DistanceMeters, HeartRate: average},
+	}
+
+	database := tcxDatabase{
+		Namespace: tcxNamespace,
+		Activities: []tcxActivity{{
+			Sport: tcxSport,
+			ID:    start,
+			Lap:   lap,
+			Notes: fmt.Sprintf("%s exported from WHOOP (strain %.1f)", sport, score.Strain),
+		}},
+	}
+	body, err := xml.MarshalIndent(database, "", " ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode TCX: %w", err)
+	}
+	return append([]byte(xml.Header), append(body, '\n')...), nil
+}
+
+// buildTCXFiles exports each scored workout with distance, optionally
+// filtered by sport name, as its own TCX file
+func buildTCXFiles(workouts []WhoopWorkout, sports *SportsCatalog, sportFilter string) ([]TCXFile, error) {
+	filter := strings.ToLower(strings.TrimSpace(sportFilter))
+	var files []TCXFile
+	for _, workout := range workouts {
+		sport := sports.SportFor(workout)
+		if !tcxEligible(workout) || (filter != "" && !strings.Contains(strings.ToLower(sport), filter)) {
+			continue
+		}
+		content, err := buildTCX(workout, sport)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, TCXFile{Name: tcxFileName(workout, sport), Sport: sport, Content: content})
+	}
+	return files, nil
+}
+
+// tcxFileName names a workout's file by local date, sport, and the start of its ID
+func tcxFileName(workout WhoopWorkout, sport string) string {
+	slug := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(sport))
+	id := workout.ID
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return fmt.Sprintf("%s_%s_%s.tcx", dayKey(localTime(workout.Start, workout.TimezoneOffset)), slug, id)
+}
+
+// writeTCXFiles writes the files into dir and returns their paths
+func writeTCXFiles(dir string, files []TCXFile) ([]string, error) {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		path := filepath.Join(dir, file.Name)
+		if err := writePrivateFile(path, file.Content); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", file.Name, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildTCXFiles(t *testing.T) {
+	workouts := healthArchiveFixture().Workouts
+	workouts[0].ID = "1a2b3c4d-0000-0000-0000-000000000000"
+
+	files, err := buildTCXFiles(workouts, NewSportsCatalog(), "")
+	if err != nil {
+		t.Fatalf("buildTCXFiles() error = %v", err)
+	}
+	// The unscored sauna session has no distance
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(files))
+	}
+	if files[0].Name != "2024-05-06_running_1a2b3c4d.tcx" {
+		t.Errorf("Unexpected file name %s", files[0].Name)
+	}
+
+	tcx := string(files[0].Content)
+	for _, want := range []string{
+		`<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">`,
+		`<Activity Sport="Running">`,
+		`<Lap StartTime="2024-05-06T11:00:00Z">`,
+		`<TotalTimeSeconds>2700</TotalTimeSeconds>`,
+		`<DistanceMeters>8000</DistanceMeters>`,
+		`<Calories>500</Calories>`,
+		`<MaximumHeartRateBpm>`,
+		`<Time>2024-05-06T11:45:00Z</Time>`,
+		`<Notes>Running exported from WHOOP (strain 12.4)</Notes>`,
+	} {
+		if !strings.Contains(tcx, want) {
+			t.Errorf("Expected TCX to contain %s\n%s", want, tcx)
+		}
+	}
+
+	if files, _ := buildTCXFiles(workouts, NewSportsCatalog(), "cycling"); len(files) != 0 {
+		t.Errorf("Expected the sport filter to drop the run, got %d files", len(files))
+	}
+}
+
+func TestWriteTCXFiles(t *testing.T) {
+	dir := t.TempDir()
+	paths, err := writeTCXFiles(dir, []TCXFile{{Name: "a.tcx", Content: []byte("<x/>")}})
+	if err != nil {
+		t.Fatalf("writeTCXFiles() error = %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "a.tcx")); err != nil || string(content) != "<x/>" || paths[0] != filepath.Join(dir, "a.tcx") {
+		t.Errorf("Unexpected output %v %q %v", paths, content, err)
+	}
+}
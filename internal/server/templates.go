@@ -0,0 +1,212 @@
+package server
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// embeddedTemplates holds the default report templates compiled into the
+// binary. English templates sit at the top level; translations live in a
+// subdirectory per locale and replace the English file of the same name.
+//
+//go:embed templates
+var embeddedTemplates embed.FS
+
+// templateFuncs are the helpers available to every report template, bound to
+// the template set's language
+func templateFuncs(l *Localizer) template.FuncMap {
+	return template.FuncMap{
+		"pct":             func(v float64) string { return fmt.Sprintf("%.1f", v*100) },
+		"daysToRepay":     func(ledger SleepDebtLedger) string { return formatDaysToRepay(l, ledger) },
+		"sleepScore":      func(computed, whoop float64) string { return formatSleepScore(l, computed, whoop) },
+		"sleepDivergence": func(divergence SleepScoreDivergence) string { return formatSleepDivergence(l, divergence) },
+		"sportMix":        FormatSportBreakdown,
+		"weeklyBalance":   FormatWeeklyBalance,
+		"cycleNote":       func(adjusted int, what string) string { return cycleNote(l, adjusted, l.T(what)) },
+		"t":               func(value string) string { return l.T(value) },
+		"inc":             func(i int) int { return i + 1 },
+		"flagType":        func(kind string) string { return strings.ReplaceAll(kind, "_", " ") },
+		"flagLabel":       redFlagLabel,
+		"safetyNotice":    formatSafetyNotice,
+		"acknowledgedFlags": func(flags []RedFlag) string {
+			return strings.TrimSuffix(formatAcknowledgedFlags(l, flags, "##"), "\n")
+		},
+		"goalProgress":  func(progress []GoalProgress) string { return FormatGoalProgress(l, progress, "##") },
+		"annotations":   FormatAnnotations,
+		"trendGlyph":    trendGlyph,
+		"trendEstimate": func(estimate TrendEstimate, unit string) string { return formatTrendEstimate(l, estimate, unit) },
+	}
+}
+
+// templateSet pairs the embedded templates for one language with the same
+// templates after user overrides are applied
+type templateSet struct {
+	defaults  *template.Template
+	templates *template.Template
+}
+
+// ReportTemplates renders report bodies from text/template files. Templates
+// in the override directory replace the embedded ones with the same file
+// name; overrideDir/<locale>/ does the same for one language.
+type ReportTemplates struct {
+	sets map[string]templateSet
+}
+
+// LoadReportTemplates parses the embedded templates and then any *.tmpl files
+// in overrideDir. A missing or empty overrideDir uses the defaults only.
+func LoadReportTemplates(overrideDir string) (*ReportTemplates, error) {
+	english, err := template.New("reports").Funcs(templateFuncs(nil)).ParseFS(embeddedTemplates, "templates/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse embedded templates: %w", err)
+	}
+	englishOverridden, err := overlayFiles(english, overrideDir)
+	if err != nil {
+		return nil, err
+	}
+	templates := &ReportTemplates{sets: map[string]templateSet{
+		defaultLocale: {defaults: english, templates: englishOverridden},
+	}}
+
+	for _, locale := range templateLocales(overrideDir) {
+		localizer, err := NewLocalizer(locale)
+		if err != nil {
+			log.Printf("Ignoring templates for %s: %v", locale, err)
+			continue
+		}
+
+		defaults, err := overlayEmbedded(english, localizer)
+		if err != nil {
+			return nil, err
+		}
+		overridden, err := overlayEmbedded(englishOverridden, localizer)
+		if err != nil {
+			return nil, err
+		}
+		if overrideDir != "" {
+			if overridden, err = overlayFiles(overridden, filepath.Join(overrideDir, locale)); err != nil {
+				return nil, err
+			}
+		}
+		templates.sets[locale] = templateSet{defaults: defaults, templates: overridden}
+	}
+	return templates, nil
+}
+
+// templateLocales lists the locale subdirectories of the embedded templates
+// and the override directory
+func templateLocales(overrideDir string) []string {
+	seen := make(map[string]bool)
+	var locales []string
+	add := func(entries []fs.DirEntry) {
+		for _, entry := range entries {
+			if entry.IsDir() && !seen[entry.Name()] {
+				seen[entry.Name()] = true
+				locales = append(locales, entry.Name())
+			}
+		}
+	}
+	entries, _ := embeddedTemplates.ReadDir("templates")
+	add(entries)
+	if overrideDir != "" {
+		entries, _ := os.ReadDir(overrideDir)
+		add(entries)
+	}
+	return locales
+}
+
+// overlayEmbedded clones base with the locale's helpers and parses its
+// embedded translations on top
+func overlayEmbedded(base *template.Template, localizer *Localizer) (*template.Template, error) {
+	set, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	set.Funcs(templateFuncs(localizer))
+	if matches, _ := fs.Glob(embeddedTemplates, "templates/"+localizer.Locale+"/*.tmpl"); len(matches) > 0 {
+		if set, err = set.ParseFS(embeddedTemplates, matches...); err != nil {
+			return nil, fmt.Errorf("failed to parse embedded %s templates: %w", localizer.Locale, err)
+		}
+	}
+	return set, nil
+}
+
+// overlayFiles clones base and parses the *.tmpl files in dir on top; base is
+// returned unchanged when dir has none
+func overlayFiles(base *template.Template, dir string) (*template.Template, error) {
+	if dir == "" {
+		return base, nil
+	}
+	overrides, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template directory: %w", err)
+	}
+	if len(overrides) == 0 {
+		return base, nil
+	}
+	set, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if set, err = set.ParseFiles(overrides...); err != nil {
+		return nil, fmt.Errorf("failed to parse templates in %s: %w", dir, err)
+	}
+	return set, nil
+}
+
+// NewReportTemplatesFromEnv loads overrides from WHOOP_TEMPLATE_DIR or a
+// templates directory in the default datastore; a missing directory is ignored
+func NewReportTemplatesFromEnv(settings Settings) (*ReportTemplates, error) {
+	dir, err := localDataPath(settings, "WHOOP_TEMPLATE_DIR", "templates")
+	if err != nil {
+		return nil, err
+	}
+	return LoadReportTemplates(dir)
+}
+
+// defaultReportTemplates returns the embedded templates, which are parsed
+// from files compiled into the binary and cannot fail at runtime
+func defaultReportTemplates() *ReportTemplates {
+	templates, err := LoadReportTemplates("")
+	if err != nil {
+		panic(err)
+	}
+	return templates
+}
+
+// Render executes the named template (file name without .tmpl) in the
+// localizer's language, falling back to English when there is no template set
+// for it. When a user-supplied override fails to execute, the embedded
+// default is used instead.
+func (t *ReportTemplates) Render(l *Localizer, name string, data interface{}) string {
+	set, ok := t.sets[l.Lang()]
+	if !ok {
+		set = t.sets[defaultLocale]
+	}
+
+	out, err := executeTemplate(set.templates, name+".tmpl", data)
+	if err != nil && set.templates != set.defaults {
+		log.Printf("Template %s failed, falling back to the default: %v", name, err)
+		out, err = executeTemplate(set.defaults, name+".tmpl", data)
+	}
+	if err != nil {
+		return fmt.Sprintf("Failed to render %s report: %v", name, err)
+	}
+	return out
+}
+
+// executeTemplate renders one template, dropping the file's final newline so
+// callers can append sections the same way they would to a literal string
+func executeTemplate(set *template.Template, name string, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := set.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportTemplates_Defaults(t *testing.T) {
+	templates := defaultReportTemplates()
+
+	got := templates.Render(nil, "sleep_trend", struct {
+		Days           int
+		Sleep          SleepAnalysis
+		Interpretation string
+		SparkDays      int
+		Sparkline      string
+	}{14, SleepAnalysis{AverageHours: 7.25, AverageEfficiency: 0.912, SleepQualityTrend: "stable", ConsistencyScore: 0.8,
+		QualityEstimate: TrendEstimate{SlopePerWeek: 0.4, CILow: -1.2, CIHigh: 2, EffectSize: 0.1, Magnitude: "negligible", Samples: 14}}, "Steady.", 3, "▃▅▇"})
+
+	want := `# Sleep Trend Analysis (14 days)
+
+## Sleep Summary
+- **Average Duration:** 7.2 hours
+- **Sleep Efficiency:** 91.2%
+- **Quality Trend:** stable → (+0.4%/week (95% CI -1.2 to +2.0), negligible effect, n=14)
+- **Daily Duration (last 3 days):** ` + "`▃▅▇`" + `
+- **Consistency:** 80.0%
+
+## Analysis
+Steady.`
+	if got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestReportTemplates_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "strain_trend.tmpl"), []byte("Strain moyenne : {{printf \"%.1f\" .Average}}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sleep_trend.tmpl"), []byte("{{.Missing}}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := LoadReportTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadReportTemplates() error: %v", err)
+	}
+
+	if got := templates.Render(nil, "strain_trend", struct{ Average float64 }{12.34}); got != "Strain moyenne : 12.3" {
+		t.Errorf("Expected the override to render, got %q", got)
+	}
+
+	// A broken override falls back to the embedded template
+	got := templates.Render(nil, "sleep_trend", struct {
+		Days           int
+		Sleep          SleepAnalysis
+		Interpretation string
+		SparkDays      int
+		Sparkline      string
+	}{7, SleepAnalysis{}, "", 7, ""})
+	if !strings.HasPrefix(got, "# Sleep Trend Analysis (7 days)") {
+		t.Errorf("Expected the default template after a failed override, got %q", got)
+	}
+
+	// Templates without an override are untouched
+	if got := templates.Render(nil, "recovery_trend", struct {
+		Days           int
+		Trend          RecoveryTrend
+		Scores         string
+		Interpretation string
+		SparkDays      int
+		Sparkline      string
+	}{7, RecoveryTrend{}, "", "", 7, ""}); !strings.HasPrefix(got, "# Recovery Trend Analysis") {
+		t.Errorf("Expected the embedded recovery template, got %q", got)
+	}
+}
+
+func TestReportTemplates_OverrideAnalyzerReports(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"hrv_analysis.tmpl":  "HRV baseline {{printf \"%.0f\" .Baseline}} ms\n",
+		"weekly_report.tmpl": "Week of {{.WeekStart.Format \"Jan 2\"}}\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	templates, err := LoadReportTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadReportTemplates() error: %v", err)
+	}
+	analyzer := NewHealthAnalyzer()
+	analyzer.templates = templates
+
+	if got := analyzer.FormatHRVAnalysis(HRVAnalysis{Status: "normal", Baseline: 61.6}); got != "HRV baseline 62 ms" {
+		t.Errorf("FormatHRVAnalysis() = %q, want the override", got)
+	}
+	week := WeeklyReport{WeekStart: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)}
+	if got := analyzer.FormatWeeklyReport(week); got != "Week of Jan 8" {
+		t.Errorf("FormatWeeklyReport() = %q, want the override", got)
+	}
+}
+
+func TestLoadReportTemplates_ParseError(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "strain_trend.tmpl"), []byte("{{.Average"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadReportTemplates(dir); err == nil {
+		t.Error("Expected a parse error for a malformed override")
+	}
+}
+
+func TestLoadReportTemplates_MissingDir(t *testing.T) {
+	if _, err := LoadReportTemplates(filepath.Join(t.TempDir(), "absent")); err != nil {
+		t.Errorf("Expected a missing override directory to be ignored, got %v", err)
+	}
+}
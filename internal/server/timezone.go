@@ -0,0 +1,71 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	// Embedded zone data so WHOOP_TIMEZONE works on hosts without tzdata
+	_ "time/tzdata"
+)
+
+// UserTimezone decides which timezone date arguments are interpreted in, so
+// "yesterday" means the user's yesterday rather than UTC's. WHOOP_TIMEZONE
+// (an IANA name such as America/Denver) wins and follows DST; otherwise the
+// timezone_offset of the most recent sleep is used; before any sleep has been
+// fetched the host's local timezone is assumed.
+type UserTimezone struct {
+	configured *time.Location
+
+	mu           sync.RWMutex
+	learned      *time.Location
+	learnedUntil time.Time // end of the sleep the learned offset came from
+}
+
+// NewUserTimezoneFromEnv reads WHOOP_TIMEZONE
+func NewUserTimezoneFromEnv(settings Settings) (*UserTimezone, error) {
+	name := settings("WHOOP_TIMEZONE")
+	if name == "" {
+		return &UserTimezone{}, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WHOOP_TIMEZONE %q: %w", name, err)
+	}
+	return &UserTimezone{configured: location}, nil
+}
+
+// Learn remembers the timezone offset of the latest sleep among sleeps
+func (z *UserTimezone) Learn(sleeps []WhoopSleep) {
+	if z == nil {
+		return
+	}
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	for _, sleep := range sleeps {
+		if sleep.TimezoneOffset == "" || !sleep.End.After(z.learnedUntil) {
+			continue
+		}
+		// localTime yields the record's fixed zone, or UTC when the offset is unparseable
+		if location := localTime(sleep.End, sleep.TimezoneOffset).Location(); location != time.UTC || sleep.TimezoneOffset == "Z" {
+			z.learned, z.learnedUntil = location, sleep.End
+		}
+	}
+}
+
+// Location is the timezone to build local-day boundaries in
+func (z *UserTimezone) Location() *time.Location {
+	if z == nil {
+		return time.Local
+	}
+	if z.configured != nil {
+		return z.configured
+	}
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	if z.learned != nil {
+		return z.learned
+	}
+	return time.Local
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLocalDayBounds_DST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		expr       string
+		start, end string
+		hours      float64
+	}{
+		{"spring forward", time.Date(2024, 3, 11, 1, 30, 0, 0, newYork), "yesterday", "2024-03-10T00:00:00-05:00", "2024-03-10T23:59:59-04:00", 23},
+		{"fall back", time.Date(2024, 11, 4, 8, 0, 0, 0, newYork), "yesterday", "2024-11-03T00:00:00-04:00", "2024-11-03T23:59:59-05:00", 25},
+		{"week across the change", time.Date(2024, 3, 12, 9, 0, 0, 0, newYork), "last week", "2024-03-04T00:00:00-05:00", "2024-03-10T23:59:59-04:00", 7*24 - 1},
+		// 01:30 UTC on the 11th is still the evening of the 10th in New York
+		{"evening west of UTC", time.Date(2024, 3, 11, 1, 30, 0, 0, time.UTC).In(newYork), "today", "2024-03-10T00:00:00-05:00", "2024-03-10T23:59:59-04:00", 23},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, last, err := resolveDateRange(DateRangeInput{StartDate: tt.expr}, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			start, end := localDayBounds(first, last)
+			if got := start.Format(time.RFC3339); got != tt.start {
+				t.Errorf("start = %s, want %s", got, tt.start)
+			}
+			if got := end.Format(time.RFC3339); got != tt.end {
+				t.Errorf("end = %s, want %s", got, tt.end)
+			}
+			if hours := end.Add(time.Second).Sub(start).Hours(); hours != tt.hours {
+				t.Errorf("Range covers %.0f hours, want %.0f", hours, tt.hours)
+			}
+		})
+	}
+}
+
+func TestUserTimezone(t *testing.T) {
+	t.Setenv("WHOOP_TIMEZONE", "")
+	zone, err := NewUserTimezoneFromEnv(os.Getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zone.Location() != time.Local {
+		t.Errorf("Expected the host timezone before any sleep, got %s", zone.Location())
+	}
+
+	older, newer := WhoopSleep{TimezoneOffset: "+01:00"}, WhoopSleep{TimezoneOffset: "-07:00"}
+	older.End = time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+	newer.End = older.End.AddDate(0, 0, 1)
+	zone.Learn([]WhoopSleep{newer, older})
+	if _, offset := time.Now().In(zone.Location()).Zone(); offset != -7*60*60 {
+		t.Errorf("Expected the latest sleep's offset, got %d", offset)
+	}
+
+	t.Setenv("WHOOP_TIMEZONE", "Europe/Madrid")
+	configured, err := NewUserTimezoneFromEnv(os.Getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configured.Learn([]WhoopSleep{newer})
+	if configured.Location().String() != "Europe/Madrid" {
+		t.Errorf("Expected WHOOP_TIMEZONE to win, got %s", configured.Location())
+	}
+
+	t.Setenv("WHOOP_TIMEZONE", "Mars/Olympus")
+	if _, err := NewUserTimezoneFromEnv(os.Getenv); err == nil {
+		t.Error("Expected an error for an unknown timezone")
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// tokenHolder guards the default access/refresh token pair so concurrent
+// requests (e.g. the health summary fan-out) read a consistent pair while a
+// refresh rotates it
+type tokenHolder struct {
+	accessToken  string
+	refreshToken string
+	authRequired *AuthRequiredError
+	expiresAt    *time.Time // when the access token expires; nil when unknown
+	mu           sync.RWMutex
+
+	// refreshMu serializes refreshes so only one goroutine hits the token endpoint
+	refreshMu sync.Mutex
+}
+
+// newTokenHolder creates a holder for the initial token pair
+func newTokenHolder(accessToken, refreshToken string) *tokenHolder {
+	return &tokenHolder{
+		accessToken:  accessToken,
+		refreshToken: refreshToken,
+	}
+}
+
+// Access returns the current access token
+func (t *tokenHolder) Access() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.accessToken
+}
+
+// Refresh returns the current refresh token
+func (t *tokenHolder) Refresh() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.refreshToken
+}
+
+// Set replaces the token pair and clears any needs-auth state. An empty
+// refresh token keeps the existing one.
+func (t *tokenHolder) Set(accessToken, refreshToken string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.accessToken = accessToken
+	if refreshToken != "" {
+		t.refreshToken = refreshToken
+	}
+	t.authRequired = nil
+}
+
+// SetExpiry records when the current access token expires; nil means unknown
+func (t *tokenHolder) SetExpiry(expiresAt *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expiresAt = expiresAt
+}
+
+// Expiry returns when the current access token expires, or nil when unknown
+func (t *tokenHolder) Expiry() *time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.expiresAt
+}
+
+// AuthRequired returns the pending re-authorization error, or nil when healthy
+func (t *tokenHolder) AuthRequired() *AuthRequiredError {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.authRequired
+}
+
+// RequireAuth records that the tokens are unusable until re-authorization
+func (t *tokenHolder) RequireAuth(reason string) *AuthRequiredError {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.authRequired = &AuthRequiredError{Reason: reason}
+	return t.authRequired
+}
+
+// Rotate refreshes the access token at most once per expiry. stale is the token
+// that was rejected; if another goroutine already replaced it while this one
+// waited, the new token is returned without calling refresh again.
+func (t *tokenHolder) Rotate(stale string, refresh func(refreshToken string) (string, string, error)) (string, error) {
+	t.refreshMu.Lock()
+	defer t.refreshMu.Unlock()
+
+	if current := t.Access(); current != stale {
+		return current, nil
+	}
+	if authErr := t.AuthRequired(); authErr != nil {
+		return "", authErr
+	}
+
+	accessToken, refreshToken, err := refresh(t.Refresh())
+	if err != nil {
+		return "", err
+	}
+
+	t.Set(accessToken, refreshToken)
+	return accessToken, nil
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTokenHolder_RotateRefreshesOnce(t *testing.T) {
+	holder := newTokenHolder("stale", "refresh-1")
+
+	var calls atomic.Int32
+	refresh := func(refreshToken string) (string, string, error) {
+		calls.Add(1)
+		if refreshToken != "refresh-1" {
+			t.Errorf("refresh called with %q", refreshToken)
+		}
+		return "fresh", "refresh-2", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := holder.Rotate("stale", refresh)
+			if err != nil || token != "fresh" {
+				t.Errorf("Rotate() = %q, %v", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single refresh, got %d", calls.Load())
+	}
+	if holder.Refresh() != "refresh-2" {
+		t.Errorf("Expected rotated refresh token, got %q", holder.Refresh())
+	}
+}
@@ -0,0 +1,352 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	keyringService = "whoop-mcp"
+	keyringUser    = "default"
+)
+
+// TokenSet is the OAuth token pair persisted between runs
+type TokenSet struct {
+	AccessToken  string     `json:"access_token"`
+	RefreshToken string     `json:"refresh_token,omitempty"`
+	Scope        string     `json:"scope,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TokenStore persists OAuth tokens so refreshed credentials survive restarts
+type TokenStore interface {
+	// Name identifies the backend in logs and diagnostics
+	Name() string
+	// Load returns the stored tokens, or nil when nothing has been stored yet
+	Load() (*TokenSet, error)
+	// Save persists tokens, replacing any previously stored pair
+	Save(tokens TokenSet) error
+}
+
+// NewTokenStoreFromEnv selects a backend via WHOOP_TOKEN_STORE (env, keyring, file)
+func NewTokenStoreFromEnv(settings Settings) (TokenStore, error) {
+	backend := strings.ToLower(strings.TrimSpace(settings("WHOOP_TOKEN_STORE")))
+
+	switch backend {
+	case "", "env":
+		path := settings("WHOOP_ENV_FILE")
+		if path == "" {
+			path = ".env"
+		}
+		return &EnvFileTokenStore{Path: path}, nil
+
+	case "keyring", "keychain":
+		return &KeyringTokenStore{Service: keyringService, User: keyringUser}, nil
+
+	case "file", "json":
+		path := settings("WHOOP_TOKEN_FILE")
+		if path == "" {
+			defaultPath, err := defaultTokenFilePath()
+			if err != nil {
+				return nil, err
+			}
+			path = defaultPath
+		}
+		return &JSONFileTokenStore{Path: path}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown WHOOP_TOKEN_STORE %q (expected env, keyring, or file)", backend)
+	}
+}
+
+// saveOAuthTokens persists a token endpoint response to store
+func saveOAuthTokens(store TokenStore, tokens *OAuthTokenResponse) error {
+	set := TokenSet{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		Scope:        tokens.Scope,
+		UpdatedAt:    time.Now(),
+	}
+	if tokens.ExpiresIn > 0 {
+		expiresAt := set.UpdatedAt.Add(time.Duration(tokens.ExpiresIn) * time.Second)
+		set.ExpiresAt = &expiresAt
+	}
+	if err := store.Save(set); err != nil {
+		return fmt.Errorf("failed to persist tokens to %s: %w", store.Name(), err)
+	}
+	return nil
+}
+
+// maskToken returns a short preview of a secret that is safe to show in chat
+func maskToken(token string) string {
+	if token == "" {
+		return "(none)"
+	}
+	if len(token) <= 12 {
+		return strings.Repeat("•", 8)
+	}
+	return fmt.Sprintf("%s…%s (%d chars)", token[:4], token[len(token)-4:], len(token))
+}
+
+// defaultTokenFilePath returns $XDG_CONFIG_HOME/whoop-mcp/tokens.json (or the OS equivalent)
+func defaultTokenFilePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, "whoop-mcp", "tokens.json"), nil
+}
+
+// EnvFileTokenStore keeps tokens in a dotenv file, merging into existing
+// content so custom entries and comments are preserved
+type EnvFileTokenStore struct {
+	Path string
+}
+
+// Name identifies the backend
+func (e *EnvFileTokenStore) Name() string {
+	return "env:" + e.Path
+}
+
+// Load reads the token entries from the dotenv file
+func (e *EnvFileTokenStore) Load() (*TokenSet, error) {
+	values, err := readEnvFile(e.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	accessToken := values["WHOOP_ACCESS_TOKEN"]
+	if accessToken == "" {
+		accessToken = values["WHOOP_API_KEY"]
+	}
+	if accessToken == "" {
+		return nil, nil
+	}
+
+	return &TokenSet{
+		AccessToken:  accessToken,
+		RefreshToken: values["WHOOP_REFRESH_TOKEN"],
+	}, nil
+}
+
+// Save updates the token entries in place, appending any that are missing
+func (e *EnvFileTokenStore) Save(tokens TokenSet) error {
+	data, err := os.ReadFile(e.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", e.Path, err)
+	}
+
+	if os.IsNotExist(err) {
+		return os.WriteFile(e.Path, []byte(envFileTemplate(tokens)), 0600)
+	}
+
+	updates := map[string]string{
+		"WHOOP_API_KEY": tokens.AccessToken,
+	}
+	if tokens.RefreshToken != "" {
+		updates["WHOOP_REFRESH_TOKEN"] = tokens.RefreshToken
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	written := make(map[string]bool)
+	for i, line := range lines {
+		key, _, ok := parseEnvLine(line)
+		if !ok {
+			continue
+		}
+		// Keep WHOOP_ACCESS_TOKEN in sync if the user chose that name, rather
+		// than adding a WHOOP_API_KEY it would shadow
+		if key == "WHOOP_ACCESS_TOKEN" {
+			lines[i] = key + "=" + tokens.AccessToken
+			written["WHOOP_API_KEY"] = true
+			continue
+		}
+		if value, managed := updates[key]; managed {
+			lines[i] = key + "=" + value
+			written[key] = true
+		}
+	}
+
+	for _, key := range []string{"WHOOP_API_KEY", "WHOOP_REFRESH_TOKEN"} {
+		if value, managed := updates[key]; managed && !written[key] {
+			lines = append(lines, key+"="+value)
+		}
+	}
+
+	// Replace the file atomically so an interrupted refresh cannot truncate
+	// the user's other settings; follow a symlinked .env to its target
+	path := e.Path
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	return writePrivateFile(path, []byte(strings.Join(lines, "\n")+"\n"))
+}
+
+// readEnvFile parses KEY=VALUE pairs from a dotenv file
+func readEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if key, value, ok := parseEnvLine(scanner.Text()); ok {
+			values[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// parseEnvLine splits a dotenv line into key and unquoted value
+func parseEnvLine(line string) (string, string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return "", "", false
+	}
+	trimmed = strings.TrimPrefix(trimmed, "export ")
+
+	key, value, found := strings.Cut(trimmed, "=")
+	if !found {
+		return "", "", false
+	}
+	key = strings.TrimSpace(key)
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	return key, value, key != ""
+}
+
+// envFileTemplate renders a fresh dotenv file for first-time setup
+func envFileTemplate(tokens TokenSet) string {
+	return fmt.Sprintf(`# Whoop MCP Server Configuration (V2 API)
+
+# Required: Your Whoop API access token
+WHOOP_API_KEY=%s
+
+# Optional: Refresh token for token renewal
+WHOOP_REFRESH_TOKEN=%s
+
+# Optional: OAuth credentials for auto-refresh
+# WHOOP_CLIENT_ID=your_client_id
+# WHOOP_CLIENT_SECRET=your_client_secret
+
+# Optional: Custom API base URL (defaults to production V2)
+# WHOOP_API_BASE_URL=https://api.prod.whoop.com/developer
+
+# Optional: Rate limiting configuration (requests per minute, 1-1000)
+# WHOOP_RATE_LIMIT=100
+
+# Optional: Request timeout in seconds (1-600)
+# WHOOP_REQUEST_TIMEOUT=30
+
+# Optional: Menstrual-cycle-aware analysis (off, calendar, or temperature)
+# WHOOP_CYCLE_MODE=calendar
+# WHOOP_CYCLE_LAST_PERIOD=2024-01-01
+# WHOOP_CYCLE_LENGTH=28
+`, tokens.AccessToken, tokens.RefreshToken)
+}
+
+// KeyringTokenStore keeps tokens in the OS keychain (macOS Keychain,
+// Windows Credential Manager, or the freedesktop Secret Service)
+type KeyringTokenStore struct {
+	Service string
+	User    string
+}
+
+// Name identifies the backend
+func (k *KeyringTokenStore) Name() string {
+	return "keyring:" + k.Service
+}
+
+// Load reads the token JSON from the keychain
+func (k *KeyringTokenStore) Load() (*TokenSet, error) {
+	secret, err := keyring.Get(k.Service, k.User)
+	if err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read tokens from keychain: %w", err)
+	}
+
+	var tokens TokenSet
+	if err := json.Unmarshal([]byte(secret), &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse tokens from keychain: %w", err)
+	}
+	return &tokens, nil
+}
+
+// Save writes the token JSON to the keychain
+func (k *KeyringTokenStore) Save(tokens TokenSet) error {
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tokens: %w", err)
+	}
+	if err := keyring.Set(k.Service, k.User, string(data)); err != nil {
+		return fmt.Errorf("failed to write tokens to keychain: %w", err)
+	}
+	return nil
+}
+
+// JSONFileTokenStore keeps tokens in a private JSON file
+type JSONFileTokenStore struct {
+	Path string
+}
+
+// Name identifies the backend
+func (j *JSONFileTokenStore) Name() string {
+	return "file:" + j.Path
+}
+
+// Load reads the token file
+func (j *JSONFileTokenStore) Load() (*TokenSet, error) {
+	data, err := os.ReadFile(j.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	var tokens TokenSet
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse token file: %w", err)
+	}
+	return &tokens, nil
+}
+
+// Save writes the token file with owner-only permissions
+func (j *JSONFileTokenStore) Save(tokens TokenSet) error {
+	if err := os.MkdirAll(filepath.Dir(j.Path), 0700); err != nil {
+		return fmt.Errorf("failed to create token directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal tokens: %w", err)
+	}
+
+	tmp := j.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	return os.Rename(tmp, j.Path)
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnvFileTokenStore_SaveMergesExistingEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	original := "# my settings\nWHOOP_CLIENT_ID=abc\nWHOOP_API_KEY=old\nCUSTOM_FLAG=1\n"
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatalf("failed to write env file: %v", err)
+	}
+
+	store := &EnvFileTokenStore{Path: path}
+	if err := store.Save(TokenSet{AccessToken: "new", RefreshToken: "refresh"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read env file: %v", err)
+	}
+	content := string(data)
+
+	for _, want := range []string{"# my settings", "WHOOP_CLIENT_ID=abc", "CUSTOM_FLAG=1", "WHOOP_API_KEY=new", "WHOOP_REFRESH_TOKEN=refresh"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected env file to contain %q, got:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "WHOOP_API_KEY=old") {
+		t.Error("Expected old access token to be replaced")
+	}
+
+	tokens, err := store.Load()
+	if err != nil || tokens == nil {
+		t.Fatalf("Load() = %v, %v", tokens, err)
+	}
+	if tokens.AccessToken != "new" || tokens.RefreshToken != "refresh" {
+		t.Errorf("Load() returned %+v", tokens)
+	}
+}
+
+func TestJSONFileTokenStore_RoundTrip(t *testing.T) {
+	store := &JSONFileTokenStore{Path: filepath.Join(t.TempDir(), "nested", "tokens.json")}
+
+	if tokens, err := store.Load(); err != nil || tokens != nil {
+		t.Fatalf("Expected empty store, got %v, %v", tokens, err)
+	}
+
+	if err := store.Save(TokenSet{AccessToken: "a", RefreshToken: "r"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	tokens, err := store.Load()
+	if err != nil || tokens == nil || tokens.AccessToken != "a" {
+		t.Errorf("Load() = %+v, %v", tokens, err)
+	}
+}
+
+func TestMaskToken(t *testing.T) {
+	token := "abcd1234567890wxyz"
+	masked := maskToken(token)
+	if strings.Contains(masked, "1234567890") {
+		t.Errorf("maskToken leaked the secret: %s", masked)
+	}
+	if !strings.HasPrefix(masked, "abcd") || !strings.Contains(masked, "wxyz") {
+		t.Errorf("maskToken(%q) = %q", token, masked)
+	}
+	if maskToken("short") == "short" {
+		t.Error("Expected short tokens to be fully masked")
+	}
+}
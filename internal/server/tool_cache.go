@@ -0,0 +1,237 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// forceRefreshArgument skips the result cache for one call
+const forceRefreshArgument = "force_refresh"
+
+// toolCacheSize bounds how many results the cache keeps
+const toolCacheSize = 256
+
+// uncachedTools change state, write files, or report live server state, so
+// their results are never reused
+var uncachedTools = map[string]bool{
+	"setup_whoop_auth": true, "log_annotation": true, "set_goal": true, "acknowledge_red_flag": true,
+	"export_data": true, "export_health_archive": true, "export_workout_tcx": true, "export_calendar": true,
+	"get_storage_footprint": true, "server_status": true,
+}
+
+// toolCache reuses the content of identical tool calls (same tool, arguments,
+// and scope) made within the TTL, so a question asked twice in one
+// conversation doesn't refetch and reanalyze the same data
+type toolCache struct {
+	ttl   time.Duration
+	clock func() time.Time
+	// scope returns what a call's result depends on besides its arguments;
+	// see MCPServer.toolCacheScope. nil leaves keys to the arguments.
+	scope func(ctx context.Context, name string, arguments json.RawMessage) (string, error)
+	// tokens reports the Whoop client's TokenGeneration; nil when not tracked
+	tokens func() uint64
+
+	mu         sync.Mutex
+	entries    map[string]toolCacheEntry
+	generation uint64 // the token generation the entries were fetched under
+}
+
+type toolCacheEntry struct {
+	content []map[string]interface{}
+	expires time.Time
+}
+
+// NewToolCacheFromEnv reads WHOOP_TOOL_CACHE_TTL, in seconds; unset caches
+// results for five minutes and 0 disables the cache
+func NewToolCacheFromEnv(settings Settings) (*toolCache, error) {
+	seconds, err := intSettingFromEnv(settings, "WHOOP_TOOL_CACHE_TTL", 300, 0, 86400)
+	if err != nil {
+		return nil, err
+	}
+	if seconds == 0 {
+		return nil, nil
+	}
+	return newToolCache(time.Duration(seconds) * time.Second), nil
+}
+
+func newToolCache(ttl time.Duration) *toolCache {
+	return &toolCache{ttl: ttl, clock: time.Now, entries: make(map[string]toolCacheEntry)}
+}
+
+// toolCacheKey hashes the tool name with its arguments in canonical form, so
+// key order and whitespace don't matter, and with the call's scope. ok is
+// false for arguments that are not a JSON object, which are left to the tool
+// to reject.
+func toolCacheKey(name string, arguments json.RawMessage, scope string) (key string, forceRefresh bool, ok bool) {
+	args := make(map[string]interface{})
+	if len(arguments) > 0 && string(arguments) != "null" {
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return "", false, false
+		}
+	}
+	forceRefresh, _ = args[forceRefreshArgument].(bool)
+	delete(args, forceRefreshArgument)
+
+	canonical, err := json.Marshal(args)
+	if err != nil {
+		return "", false, false
+	}
+	sum := sha256.Sum256(append([]byte(name+"\x00"+scope+"\x00"), canonical...))
+	return hex.EncodeToString(sum[:]), forceRefresh, true
+}
+
+func (c *toolCache) get(key string) ([]map[string]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !c.clock().Before(entry.expires) {
+		return nil, false
+	}
+	return copyContent(entry.content), true
+}
+
+// put caches content fetched under the token generation, unless the tokens
+// have changed since
+func (c *toolCache) put(key string, content []map[string]interface{}, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	now := c.clock()
+	oldest := ""
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		} else if oldest == "" || entry.expires.Before(c.entries[oldest].expires) {
+			oldest = k
+		}
+	}
+	if len(c.entries) >= toolCacheSize {
+		delete(c.entries, oldest)
+	}
+	c.entries[key] = toolCacheEntry{content: copyContent(content), expires: now.Add(c.ttl)}
+}
+
+// clear drops every cached result
+func (c *toolCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]toolCacheEntry)
+}
+
+// sync clears the cache when tokens were installed or rotated since its
+// entries were fetched, since new tokens may belong to another grant or
+// user, and returns the current token generation
+func (c *toolCache) sync() uint64 {
+	if c.tokens == nil {
+		return 0
+	}
+	generation := c.tokens()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		c.entries = make(map[string]toolCacheEntry)
+		c.generation = generation
+	}
+	return generation
+}
+
+// middleware answers repeated calls from the cache. A successful call to a
+// tool that changes state clears it, since journals, goals, and credentials
+// feed into other tools' results, and so do new or rotated tokens.
+func (c *toolCache) middleware(next ToolHandler) ToolHandler {
+	return func(ctx context.Context, name string, arguments json.RawMessage) ([]map[string]interface{}, error) {
+		generation := c.sync()
+		if mutates := mutatingCall(name, arguments); mutates || uncachedTools[name] {
+			content, err := next(ctx, name, arguments)
+			if err == nil && mutates {
+				c.clear()
+			}
+			return content, err
+		}
+
+		scope := ""
+		if c.scope != nil {
+			var err error
+			if scope, err = c.scope(ctx, name, arguments); err != nil {
+				return next(ctx, name, arguments) // the tool reports the failure
+			}
+		}
+		key, forceRefresh, ok := toolCacheKey(name, arguments, scope)
+		if !ok {
+			return next(ctx, name, arguments)
+		}
+		if !forceRefresh {
+			if content, hit := c.get(key); hit {
+				return content, nil
+			}
+		}
+		content, err := next(ctx, name, arguments)
+		if err != nil {
+			return nil, err
+		}
+		c.put(key, content, generation)
+		return content, nil
+	}
+}
+
+// toolCacheScope is what a tool's result depends on besides its arguments:
+// the account it reads, which for calls without user_id is whichever user
+// the default tokens belong to, and the local day and timezone relative
+// dates resolve against, with the ranges they resolve to, so "past 7 days"
+// cached late one evening isn't served the next morning
+func (s *MCPServer) toolCacheScope(ctx context.Context, name string, arguments json.RawMessage) (string, error) {
+	var input struct {
+		UserID *int `json:"user_id"`
+	}
+	json.Unmarshal(arguments, &input) // toolCacheKey rejects arguments that aren't an object
+
+	account, err := s.accountID(ctx, input.UserID)
+	if err != nil {
+		return "", err
+	}
+	return cacheScope(account, name, arguments, s.now()), nil
+}
+
+func cacheScope(account int, name string, arguments json.RawMessage, now time.Time) string {
+	return fmt.Sprintf("user %d, %s in %s\n%s", account, dayKey(now), now.Location(), describeResolvedDates(name, arguments, now))
+}
+
+// copyContent copies content blocks so callers, such as redaction, can
+// rewrite them without touching the cached ones
+func copyContent(content []map[string]interface{}) []map[string]interface{} {
+	copied := make([]map[string]interface{}, len(content))
+	for i, item := range content {
+		copied[i] = make(map[string]interface{}, len(item))
+		for k, v := range item {
+			copied[i][k] = v
+		}
+	}
+	return copied
+}
+
+// withForceRefresh adds the force_refresh argument to the tools whose
+// results are cached
+func withForceRefresh(tools []MCPTool) []MCPTool {
+	for i, tool := range tools {
+		if uncachedTools[tool.Name] {
+			continue
+		}
+		properties := make(map[string]interface{}, len(tool.InputSchema.Properties)+1)
+		for name, property := range tool.InputSchema.Properties {
+			properties[name] = property
+		}
+		properties[forceRefreshArgument] = map[string]interface{}{
+			"type":        "boolean",
+			"description": "Recompute instead of reusing a result from the last few minutes",
+		}
+		tools[i].InputSchema.Properties = properties
+	}
+	return tools
+}
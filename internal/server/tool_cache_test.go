@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestToolCacheKey(t *testing.T) {
+	a, force, ok := toolCacheKey("analyze_hrv", json.RawMessage(`{"days": 30, "start_date": "last week"}`), "")
+	if !ok || force {
+		t.Fatalf("toolCacheKey() = %q, %v, %v", a, force, ok)
+	}
+	b, force, _ := toolCacheKey("analyze_hrv", json.RawMessage(`{"start_date":"last week","days":30,"force_refresh":true}`), "")
+	if a != b || !force {
+		t.Errorf("Expected key order, whitespace, and force_refresh not to change the key")
+	}
+	if c, _, _ := toolCacheKey("analyze_vitals", json.RawMessage(`{"days": 30, "start_date": "last week"}`), ""); c == a {
+		t.Error("Expected different tools to get different keys")
+	}
+	if d, _, _ := toolCacheKey("analyze_hrv", json.RawMessage(`{"days": 14, "start_date": "last week"}`), ""); d == a {
+		t.Error("Expected different arguments to get different keys")
+	}
+	if empty, _, ok := toolCacheKey("server_status", nil, ""); !ok || empty == "" {
+		t.Error("Expected missing arguments to be cacheable")
+	}
+	if _, _, ok := toolCacheKey("analyze_hrv", json.RawMessage(`[1, 2]`), ""); ok {
+		t.Error("Expected non-object arguments to bypass the cache")
+	}
+	if e, _, _ := toolCacheKey("analyze_hrv", json.RawMessage(`{"days": 30, "start_date": "last week"}`), "user 2"); e == a {
+		t.Error("Expected different scopes to get different keys")
+	}
+}
+
+func TestToolCache_Scope(t *testing.T) {
+	mock := NewMockWhoopAPI()
+	server := newMockServer(t, mock)
+	if server.toolCache == nil {
+		t.Fatal("Expected the mock server to cache tool results")
+	}
+	args := json.RawMessage(`{"start_date": "past 7 days"}`)
+	scope := func(args json.RawMessage) string {
+		scope, err := server.toolCacheScope(context.Background(), "analyze_hrv", args)
+		if err != nil {
+			t.Fatalf("toolCacheScope() error = %v", err)
+		}
+		return scope
+	}
+	if scope(args) == scope(json.RawMessage(`{"start_date": "past 7 days", "user_id": 99}`)) {
+		t.Error("Expected another account to get another scope")
+	}
+	evening := time.Date(2024, 6, 1, 23, 30, 0, 0, time.UTC)
+	if cacheScope(1, "analyze_hrv", args, evening) == cacheScope(1, "analyze_hrv", args, evening.Add(time.Hour)) {
+		t.Error("Expected relative dates resolved on another day to get another scope")
+	}
+
+	runs := 0
+	handler := server.toolCache.middleware(func(ctx context.Context, name string, arguments json.RawMessage) ([]map[string]interface{}, error) {
+		runs++
+		return []map[string]interface{}{{"type": "text", "text": fmt.Sprintf("run %d", runs)}}, nil
+	})
+	handler(context.Background(), "analyze_hrv", args)
+	handler(context.Background(), "analyze_hrv", args)
+	if runs != 1 {
+		t.Fatalf("repeated call ran %d times, want once", runs)
+	}
+	if err := mock.InstallTokens(&OAuthTokenResponse{AccessToken: "new", RefreshToken: "rotated"}); err != nil {
+		t.Fatal(err)
+	}
+	handler(context.Background(), "analyze_hrv", args)
+	if runs != 2 {
+		t.Error("Expected new tokens to clear cached results")
+	}
+}
+
+func TestToolCache_Middleware(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := newToolCache(time.Minute)
+	cache.clock = func() time.Time { return now }
+
+	runs := 0
+	handler := cache.middleware(func(ctx context.Context, name string, arguments json.RawMessage) ([]map[string]interface{}, error) {
+		runs++
+		return []map[string]interface{}{{"type": "text", "text": fmt.Sprintf("run %d", runs)}}, nil
+	})
+	call := func(name, args string) string {
+		content, err := handler(context.Background(), name, json.RawMessage(args))
+		if err != nil {
+			t.Fatalf("%s error = %v", name, err)
+		}
+		return content[0]["text"].(string)
+	}
+
+	if got := call("analyze_hrv", `{"days": 30}`); got != "run 1" {
+		t.Fatalf("first call = %q", got)
+	}
+	first, _ := handler(context.Background(), "analyze_hrv", json.RawMessage(`{"days": 30}`))
+	first[0]["text"] = "rewritten by a caller"
+	if got := call("analyze_hrv", `{ "days":30 }`); got != "run 1" {
+		t.Errorf("repeated call = %q, want the cached run 1", got)
+	}
+	if got := call("analyze_hrv", `{"days": 30, "force_refresh": true}`); got != "run 2" {
+		t.Errorf("force_refresh call = %q, want a fresh run", got)
+	}
+	if got := call("analyze_hrv", `{"days": 30}`); got != "run 2" {
+		t.Errorf("call after force_refresh = %q, want its refreshed result", got)
+	}
+
+	now = now.Add(time.Minute)
+	if got := call("analyze_hrv", `{"days": 30}`); got != "run 3" {
+		t.Errorf("call after the TTL = %q, want a fresh run", got)
+	}
+
+	// Uncached tools always run, and a state change clears the cache
+	if call("server_status", `{}`) == call("server_status", `{}`) {
+		t.Error("Expected server_status to bypass the cache")
+	}
+	call("log_annotation", `{"note": "rough day"}`)
+	if got := call("analyze_hrv", `{"days": 30}`); got == "run 3" {
+		t.Error("Expected log_annotation to clear cached results")
+	}
+
+	// detect_travel only writes to the journal when asked to annotate
+	if call("detect_travel", `{"days": 30}`) != call("detect_travel", `{"days": 30}`) {
+		t.Error("Expected detect_travel without annotate to be cached")
+	}
+	annotated := call("detect_travel", `{"days": 30, "annotate": true}`)
+	if again := call("detect_travel", `{"days": 30, "annotate": true}`); again == annotated {
+		t.Error("Expected detect_travel with annotate to run every time")
+	}
+	if cached := call("detect_travel", `{"days": 30}`); cached == annotated || cached != fmt.Sprintf("run %d", runs) {
+		t.Errorf("detect_travel after annotating = %q, want a fresh run", cached)
+	}
+}
+
+func TestToolCache_Bounded(t *testing.T) {
+	cache := newToolCache(time.Hour)
+	for i := 0; i < toolCacheSize+10; i++ {
+		cache.put(fmt.Sprint(i), []map[string]interface{}{{"type": "text", "text": "x"}}, 0)
+	}
+	if len(cache.entries) != toolCacheSize {
+		t.Errorf("cache holds %d entries, want %d", len(cache.entries), toolCacheSize)
+	}
+}
+
+func TestNewToolCacheFromEnv(t *testing.T) {
+	t.Setenv("WHOOP_TOOL_CACHE_TTL", "")
+	if cache, err := NewToolCacheFromEnv(os.Getenv); err != nil || cache == nil || cache.ttl != 5*time.Minute {
+		t.Errorf("default cache = %+v, %v; want a five-minute TTL", cache, err)
+	}
+	t.Setenv("WHOOP_TOOL_CACHE_TTL", "0")
+	if cache, err := NewToolCacheFromEnv(os.Getenv); err != nil || cache != nil {
+		t.Errorf("WHOOP_TOOL_CACHE_TTL=0 gave %+v, %v; want no cache", cache, err)
+	}
+	t.Setenv("WHOOP_TOOL_CACHE_TTL", "-5")
+	if _, err := NewToolCacheFromEnv(os.Getenv); err == nil {
+		t.Error("expected a negative TTL to fail")
+	}
+
+	t.Setenv("WHOOP_TOOL_CACHE_TTL", "")
+	server := newMockServer(t, NewMockWhoopAPI())
+	for _, tool := range server.Tools() {
+		_, ok := tool.InputSchema.Properties[forceRefreshArgument]
+		if ok == uncachedTools[tool.Name] {
+			t.Errorf("%s: force_refresh advertised = %v", tool.Name, ok)
+		}
+	}
+}
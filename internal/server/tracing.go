@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span kinds
+const (
+	spanInternal = trace.SpanKindInternal
+	spanServer   = trace.SpanKindServer
+	spanClient   = trace.SpanKindClient
+)
+
+const (
+	// traceBatchSize is the most ended spans sent in one export
+	traceBatchSize = 256
+	// traceFlushInterval is how often ended spans are exported
+	traceFlushInterval = 5 * time.Second
+	// tracerName is the instrumentation scope of the server's spans
+	tracerName = "whoop-mcp"
+)
+
+// Tracer records OpenTelemetry spans and exports them in batches to an
+// OTLP/HTTP collector.
+//
+// A span travels in the context.Context of the work it covers: each request
+// starts a root span, or a child of a span the caller's ctx already carries,
+// and the tool, fetch, and analysis stages below it find their parent in the
+// ctx they are handed, so requests served at once over HTTP, and the
+// concurrent fetches within one, each keep their own parent.
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+// NewTracerFromEnv configures tracing from the standard OpenTelemetry
+// variables: OTEL_EXPORTER_OTLP_TRACES_ENDPOINT (used as-is) or
+// OTEL_EXPORTER_OTLP_ENDPOINT (with /v1/traces appended), plus
+// OTEL_EXPORTER_OTLP_HEADERS and OTEL_SERVICE_NAME. With neither endpoint
+// set, tracing is off and the result is nil.
+func NewTracerFromEnv(settings Settings) (*Tracer, error) {
+	endpoint := strings.TrimSpace(settings("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"))
+	if endpoint == "" {
+		if base := strings.TrimSpace(settings("OTEL_EXPORTER_OTLP_ENDPOINT")); base != "" {
+			endpoint = strings.TrimRight(base, "/") + "/v1/traces"
+		}
+	}
+	if endpoint == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		return nil, fmt.Errorf("invalid OTLP traces endpoint %q (expected an http:// or https:// URL)", endpoint)
+	}
+	protocol := strings.TrimSpace(settings("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL"))
+	if protocol == "" {
+		protocol = strings.TrimSpace(settings("OTEL_EXPORTER_OTLP_PROTOCOL"))
+	}
+	if protocol != "" && protocol != "http/protobuf" {
+		return nil, fmt.Errorf("unsupported OTLP protocol %q (only http/protobuf is supported)", protocol)
+	}
+
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(settings("OTEL_EXPORTER_OTLP_HEADERS"), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS entry %q (expected key=value)", pair)
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	service := strings.TrimSpace(settings("OTEL_SERVICE_NAME"))
+	if service == "" {
+		service = "whoop-mcp"
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpointURL(endpoint),
+		otlptracehttp.WithHeaders(headers),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	return newTracer(exporter, service), nil
+}
+
+// newTracer batches the server's spans to exporter, tagged with service
+func newTracer(exporter sdktrace.SpanExporter, service string) *Tracer {
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter,
+			sdktrace.WithBatchTimeout(traceFlushInterval),
+			sdktrace.WithMaxExportBatchSize(traceBatchSize),
+		),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", service),
+			attribute.String("service.version", version),
+		)),
+	)
+	return &Tracer{provider: provider, tracer: provider.Tracer(tracerName, trace.WithInstrumentationVersion(version))}
+}
+
+// Shutdown exports the spans still pending and stops the exporter
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	return t.provider.Shutdown(ctx)
+}
+
+// Span is one timed operation in a trace. A nil *Span is valid and records
+// nothing, so callers don't need to check whether tracing is on.
+type Span struct {
+	span trace.Span
+}
+
+// Start begins a span under the one ctx carries, or the root span of a new
+// trace when it carries none, and returns a ctx carrying the new span for
+// the work it covers. A nil Tracer only continues a trace ctx already has.
+func (t *Tracer) Start(ctx context.Context, name string, kind trace.SpanKind) (context.Context, *Span) {
+	if t == nil {
+		return startSpan(ctx, name, kind)
+	}
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(kind))
+	return ctx, &Span{span: span}
+}
+
+// startSpan begins a span under the one ctx carries and returns a ctx
+// carrying it. Without a recording span in ctx the work isn't being traced,
+// so it records nothing and returns ctx as is.
+func startSpan(ctx context.Context, name string, kind trace.SpanKind, opts ...trace.SpanStartOption) (context.Context, *Span) {
+	parent := trace.SpanFromContext(ctx)
+	if !parent.IsRecording() {
+		return ctx, nil
+	}
+	opts = append(opts, trace.WithSpanKind(kind))
+	ctx, span := parent.TracerProvider().Tracer(tracerName).Start(ctx, name, opts...)
+	return ctx, &Span{span: span}
+}
+
+// recordSpan records a span under the one ctx carries that began at start
+// and ends now, for time measured before it was worth a span
+func recordSpan(ctx context.Context, name string, start time.Time) {
+	_, span := startSpan(ctx, name, spanInternal, trace.WithTimestamp(start))
+	span.End(nil)
+}
+
+// SetAttribute records a string, integer, float, or boolean attribute
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	var attr attribute.KeyValue
+	switch v := value.(type) {
+	case bool:
+		attr = attribute.Bool(key, v)
+	case int:
+		attr = attribute.Int(key, v)
+	case int64:
+		attr = attribute.Int64(key, v)
+	case float64:
+		attr = attribute.Float64(key, v)
+	default:
+		attr = attribute.String(key, fmt.Sprint(v))
+	}
+	s.span.SetAttributes(attr)
+}
+
+// End finishes the span, marking it failed when err is not nil, and queues
+// it for export. Ending a span twice has no effect.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	if err != nil {
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
@@ -0,0 +1,255 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// spanCollector is an exporter keeping every span the tracer exports
+type spanCollector struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (c *spanCollector) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.spans = append(c.spans, spans...)
+	return nil
+}
+
+func (c *spanCollector) Shutdown(ctx context.Context) error { return nil }
+
+// byName indexes the collected spans, failing when one is missing
+func (c *spanCollector) byName(t *testing.T, names ...string) map[string]sdktrace.ReadOnlySpan {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range c.spans {
+		spans[span.Name()] = span
+	}
+	for _, name := range names {
+		if _, ok := spans[name]; !ok {
+			t.Fatalf("no %q span among %d exported", name, len(c.spans))
+		}
+	}
+	return spans
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key string) string {
+	for _, attr := range span.Attributes() {
+		if attr.Key == attribute.Key(key) {
+			return attr.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestNewTracerFromEnv(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "")
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	if traces, err := NewTracerFromEnv(os.Getenv); err != nil || traces != nil {
+		t.Errorf("NewTracerFromEnv() = %v, %v; want tracing off", traces, err)
+	}
+
+	// A collector receiving OTLP/HTTP protobuf exports
+	var mu sync.Mutex
+	var auth, team, service string
+	var names []string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request coltracepb.ExportTraceServiceRequest
+		if r.URL.Path != "/v1/traces" || proto.Unmarshal(body, &request) != nil {
+			http.Error(w, "bad export", http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		auth, team = r.Header.Get("Authorization"), r.Header.Get("x-team")
+		for _, resource := range request.ResourceSpans {
+			for _, attr := range resource.Resource.Attributes {
+				if attr.Key == "service.name" {
+					service = attr.Value.GetStringValue()
+				}
+			}
+			for _, scope := range resource.ScopeSpans {
+				for _, span := range scope.Spans {
+					names = append(names, span.Name)
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	defer collector.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", collector.URL+"/")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Bearer abc, x-team = health")
+	traces, err := NewTracerFromEnv(os.Getenv)
+	if err != nil {
+		t.Fatalf("NewTracerFromEnv() error = %v", err)
+	}
+	_, span := traces.Start(context.Background(), "mcp tools/call", spanServer)
+	span.End(nil)
+	if err := traces.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	mu.Lock()
+	if len(names) != 1 || names[0] != "mcp tools/call" || auth != "Bearer abc" || team != "health" || service != "whoop-mcp" {
+		t.Errorf("collector received spans %v from %q with auth %q and team %q", names, service, auth, team)
+	}
+	mu.Unlock()
+
+	for name, value := range map[string]string{
+		"OTEL_EXPORTER_OTLP_PROTOCOL":        "grpc",
+		"OTEL_EXPORTER_OTLP_HEADERS":         "no-equals-sign",
+		"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "localhost:4318",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if _, err := NewTracerFromEnv(os.Getenv); err == nil {
+				t.Errorf("expected %s=%q to fail", name, value)
+			}
+		})
+	}
+}
+
+func TestTracing_ToolCall(t *testing.T) {
+	collector := &spanCollector{}
+	server := newMockServer(t, NewSyntheticMockWhoopAPI(SyntheticOptions{Seed: 3, End: time.Now(), Days: 30}))
+	server.initialized = true
+	server.tracer = newTracer(collector, "whoop-test")
+
+	response := server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "get_health_summary", "arguments": {"start_date": "past 14 days", "end_date": "today"}}}`))
+	if response == nil || response.Error != nil {
+		t.Fatalf("tools/call = %+v", response)
+	}
+	if err := server.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	spans := collector.byName(t, "mcp tools/call", "tool get_health_summary", "fetch health data",
+		"analyze health summary", "analyze recovery trend", "format health summary")
+	request, tool := spans["mcp tools/call"], spans["tool get_health_summary"]
+	if request.Parent().IsValid() || request.SpanKind() != spanServer || spanAttribute(request, "rpc.method") != "tools/call" {
+		t.Errorf("request span = %s %v %v", request.Name(), request.Parent(), request.SpanKind())
+	}
+	for child, parent := range map[string]string{
+		"tool get_health_summary": "mcp tools/call",
+		"fetch health data":       "tool get_health_summary",
+		"analyze health summary":  "tool get_health_summary",
+		"analyze recovery trend":  "analyze health summary",
+		"format health summary":   "tool get_health_summary",
+	} {
+		if spans[child].Parent().SpanID() != spans[parent].SpanContext().SpanID() || spans[child].SpanContext().TraceID() != request.SpanContext().TraceID() {
+			t.Errorf("%s is not a child of %s", child, parent)
+		}
+	}
+	if spanAttribute(tool, "mcp.tool.name") != "get_health_summary" || tool.Status().Code == codes.Error {
+		t.Errorf("tool span attributes = %v, status = %v", tool.Attributes(), tool.Status())
+	}
+	if got, _ := request.Resource().Set().Value("service.name"); got.AsString() != "whoop-test" {
+		t.Errorf("service.name = %q, want whoop-test", got.AsString())
+	}
+}
+
+func TestTracing_WhoopRequests(t *testing.T) {
+	var hits atomic.Int64
+	api := fixtureAPI(t, &hits)
+	collector := &spanCollector{}
+	clearConfigEnv(t)
+	t.Setenv("WHOOP_API_BASE_URL", api.URL)
+	t.Setenv("WHOOP_RATE_LIMIT", "1000")
+	t.Setenv("WHOOP_ACCESS_TOKEN", "token")
+	client, err := NewWhoopClient(os.Getenv)
+	if err != nil {
+		t.Fatalf("NewWhoopClient() error = %v", err)
+	}
+	traces := newTracer(collector, "whoop-mcp")
+
+	ctx, stage := traces.Start(context.Background(), "fetch", spanInternal)
+	recoveries, err := client.GetRecoveryData(ctx, time.Now().AddDate(0, 0, -40), time.Now(), nil)
+	stage.End(err)
+	if err != nil {
+		t.Fatalf("GetRecoveryData() error = %v", err)
+	}
+	traces.Shutdown(context.Background())
+
+	spans := collector.byName(t, "fetch", "whoop recovery", "GET /v2/recovery")
+	fetch, page := spans["whoop recovery"], spans["GET /v2/recovery"]
+	if fetch.Parent().SpanID() != spans["fetch"].SpanContext().SpanID() || page.Parent().SpanID() != fetch.SpanContext().SpanID() {
+		t.Error("Expected fetch → whoop recovery → GET spans")
+	}
+	if got := spanAttribute(fetch, "whoop.records"); got != strconv.Itoa(len(recoveries)) {
+		t.Errorf("whoop.records = %v, want %d", got, len(recoveries))
+	}
+	if got := spanAttribute(fetch, "whoop.pages"); got != strconv.Itoa(int(hits.Load())) {
+		t.Errorf("whoop.pages = %v, want %d", got, hits.Load())
+	}
+	if page.SpanKind() != spanClient || spanAttribute(page, "http.response.status_code") != "200" {
+		t.Errorf("request span kind = %v, attributes = %v", page.SpanKind(), page.Attributes())
+	}
+}
+
+func TestTracer_ConcurrentRequests(t *testing.T) {
+	traces := newTracer(&spanCollector{}, "whoop-mcp")
+	defer traces.Shutdown(context.Background())
+	parent := func(span *Span) trace.SpanContext { return span.span.(sdktrace.ReadOnlySpan).Parent() }
+
+	firstCtx, first := traces.Start(context.Background(), "mcp tools/call", spanServer)
+	secondCtx, second := traces.Start(context.Background(), "mcp tools/call", spanServer)
+	if parent(second).IsValid() || second.span.SpanContext().TraceID() == first.span.SpanContext().TraceID() {
+		t.Error("Expected an overlapping request to start its own trace")
+	}
+	toolCtx, tool := traces.Start(firstCtx, "tool analyze_hrv", spanInternal)
+	if _, fetch := startSpan(secondCtx, "GET /v2/recovery", spanClient); parent(fetch).SpanID() != second.span.SpanContext().SpanID() {
+		t.Error("Expected a span to nest under the request its context carries")
+	}
+
+	fetches := make([]*Span, 4)
+	var wg sync.WaitGroup
+	for i := range fetches {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, fetches[i] = startSpan(toolCtx, "GET /v2/activity/sleep", spanClient)
+			fetches[i].End(nil)
+		}(i)
+	}
+	wg.Wait()
+	for _, fetch := range fetches {
+		if parent(fetch).SpanID() != tool.span.SpanContext().SpanID() || fetch.span.SpanContext().TraceID() != first.span.SpanContext().TraceID() {
+			t.Error("Expected concurrent fetches to nest under the tool that started them")
+		}
+	}
+	tool.End(nil)
+	second.End(nil)
+	first.End(nil)
+
+	ctx := context.Background()
+	if untraced, span := startSpan(ctx, "GET /v2/recovery", spanClient); span != nil || untraced != ctx {
+		t.Error("Expected no span for work outside a traced request")
+	}
+	var disabled *Tracer
+	_, span := disabled.Start(ctx, "noop", spanInternal)
+	span.SetAttribute("ignored", true)
+	span.End(nil)
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// acuteLoadDays and chronicLoadDays are the ACWR rolling windows
+	acuteLoadDays   = 7
+	chronicLoadDays = 28
+	// acwrDetrainingBelow and acwrOverreachingAbove bound the optimal "sweet spot"
+	acwrDetrainingBelow   = 0.8
+	acwrOverreachingAbove = 1.3
+	// acwrSpikeAbove is the ratio associated with a sharp rise in injury risk
+	acwrSpikeAbove = 1.5
+	// highMonotony flags a week with too little day-to-day variation in load
+	highMonotony = 2.0
+	// trainingLoadHistoryDays is how many recent daily ratios are reported
+	trainingLoadHistoryDays = 14
+)
+
+// Training load zones
+const (
+	loadZoneDetraining   = "detraining"
+	loadZoneOptimal      = "optimal"
+	loadZoneOverreaching = "overreaching"
+)
+
+// DailyLoadRatio is the acute:chronic ratio as of one day
+type DailyLoadRatio struct {
+	Date  string  `json:"date"`
+	Load  float64 `json:"load"`
+	ACWR  float64 `json:"acwr"`
+	Zone  string  `json:"zone"`
+	Valid bool    `json:"valid"` // false until a full chronic window exists
+}
+
+// TrainingLoad is the acute:chronic workload analysis over day strain
+type TrainingLoad struct {
+	AcuteLoad      float64          `json:"acute_load"`   // mean daily strain, last 7 days
+	ChronicLoad    float64          `json:"chronic_load"` // mean daily strain, last 28 days
+	ACWR           float64          `json:"acwr"`
+	Zone           string           `json:"zone"` // "detraining", "optimal", "overreaching", or "insufficient_data"
+	Spike          bool             `json:"spike"`
+	Monotony       float64          `json:"monotony"`        // mean / SD of the last 7 days
+	WeeklyStrain   float64          `json:"weekly_strain"`   // weekly load × monotony
+	StrainVariance float64          `json:"strain_variance"` // coefficient of variation of the last 7 days, %
+	History        []DailyLoadRatio `json:"history"`
+}
+
+// loadZone classifies an acute:chronic ratio
+func loadZone(acwr float64) string {
+	switch {
+	case acwr < acwrDetrainingBelow:
+		return loadZoneDetraining
+	case acwr > acwrOverreachingAbove:
+		return loadZoneOverreaching
+	default:
+		return loadZoneOptimal
+	}
+}
+
+// AnalyzeTrainingLoad computes the rolling-average acute:chronic workload
+// ratio, Foster's monotony and weekly strain, and strain variability from day
+// strain. Days without a scored cycle are skipped rather than counted as rest.
+func (h *HealthAnalyzer) AnalyzeTrainingLoad(cycles []WhoopCycle) TrainingLoad {
+	load := TrainingLoad{Zone: "insufficient_data", History: []DailyLoadRatio{}}
+
+	metric, _ := lookupMetric("strain")
+	daily := dailyAverages(metric.extract(&HealthData{Cycles: cycles}))
+	if len(daily) == 0 {
+		return load
+	}
+
+	days := make([]string, 0, len(daily))
+	for day := range daily {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	// window returns the loads in the n calendar days ending on end
+	window := func(end time.Time, n int) []float64 {
+		var values []float64
+		for i := 0; i < n; i++ {
+			if v, ok := daily[dayKey(end.AddDate(0, 0, -i))]; ok {
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+
+	first, _ := time.Parse("2006-01-02", days[0])
+	last, _ := time.Parse("2006-01-02", days[len(days)-1])
+	for day := last.AddDate(0, 0, -(trainingLoadHistoryDays - 1)); !day.After(last); day = day.AddDate(0, 0, 1) {
+		value, ok := daily[dayKey(day)]
+		if !ok {
+			continue
+		}
+		entry := DailyLoadRatio{Date: dayKey(day), Load: value}
+		chronic := h.calculateMean(window(day, chronicLoadDays))
+		if chronic > 0 {
+			entry.ACWR = h.calculateMean(window(day, acuteLoadDays)) / chronic
+			entry.Zone = loadZone(entry.ACWR)
+			entry.Valid = day.Sub(first) >= (chronicLoadDays-1)*24*time.Hour
+		}
+		load.History = append(load.History, entry)
+	}
+
+	acute := window(last, acuteLoadDays)
+	load.AcuteLoad = h.calculateMean(acute)
+	load.ChronicLoad = h.calculateMean(window(last, chronicLoadDays))
+
+	if stdDev := h.calculateStdDev(acute); stdDev > 0 {
+		load.Monotony = load.AcuteLoad / stdDev
+		load.StrainVariance = stdDev / load.AcuteLoad * 100
+	}
+	load.WeeklyStrain = load.AcuteLoad * float64(len(acute)) * load.Monotony
+
+	// A ratio needs a full chronic window to mean anything
+	if load.ChronicLoad > 0 && last.Sub(first) >= (chronicLoadDays-1)*24*time.Hour {
+		load.ACWR = load.AcuteLoad / load.ChronicLoad
+		load.Zone = loadZone(load.ACWR)
+		load.Spike = load.ACWR > acwrSpikeAbove
+	}
+
+	return load
+}
+
+// FormatTrainingLoad renders the training load report with zone guidance
+func (h *HealthAnalyzer) FormatTrainingLoad(load TrainingLoad) string {
+	var builder strings.Builder
+	builder.WriteString("# Training Load Analysis\n\n")
+
+	if load.Zone == "insufficient_data" {
+		builder.WriteString(fmt.Sprintf("At least %d days of strain history are needed for an acute:chronic workload ratio.\n", chronicLoadDays))
+		if load.AcuteLoad > 0 {
+			builder.WriteString(fmt.Sprintf("\nCurrent 7-day average strain: %.1f\n", load.AcuteLoad))
+		}
+		return builder.String()
+	}
+
+	icon := map[string]string{loadZoneDetraining: "🔵", loadZoneOptimal: "🟢", loadZoneOverreaching: "🟠"}[load.Zone]
+	if load.Spike {
+		icon = "🔴"
+	}
+
+	builder.WriteString("## Workload Ratio\n")
+	builder.WriteString(fmt.Sprintf("- **ACWR:** %.2f %s %s\n", load.ACWR, icon, strings.ToUpper(load.Zone)))
+	builder.WriteString(fmt.Sprintf("- **Acute load (7-day avg strain):** %.1f\n", load.AcuteLoad))
+	builder.WriteString(fmt.Sprintf("- **Chronic load (28-day avg strain):** %.1f\n", load.ChronicLoad))
+	builder.WriteString(fmt.Sprintf("- **Zones:** <%.1f detraining · %.1f–%.1f optimal · >%.1f overreaching\n\n", acwrDetrainingBelow, acwrDetrainingBelow, acwrOverreachingAbove, acwrOverreachingAbove))
+
+	builder.WriteString("## Load Structure (last 7 days)\n")
+	builder.WriteString(fmt.Sprintf("- **Monotony:** %.2f", load.Monotony))
+	if load.Monotony > highMonotony {
+		builder.WriteString(" ⚠️ (little variation between hard and easy days)")
+	}
+	builder.WriteString("\n")
+	builder.WriteString(fmt.Sprintf("- **Weekly training strain:** %.0f\n", load.WeeklyStrain))
+	builder.WriteString(fmt.Sprintf("- **Strain variability (CV):** %.0f%%\n\n", load.StrainVariance))
+
+	if len(load.History) > 0 {
+		builder.WriteString("## Recent Ratios\n")
+		for _, entry := range load.History {
+			if !entry.Valid {
+				builder.WriteString(fmt.Sprintf("- %s: strain %.1f (building chronic baseline)\n", entry.Date, entry.Load))
+				continue
+			}
+			builder.WriteString(fmt.Sprintf("- %s: strain %.1f, ACWR %.2f (%s)\n", entry.Date, entry.Load, entry.ACWR, entry.Zone))
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("## Guidance\n")
+	switch {
+	case load.Spike:
+		builder.WriteString(fmt.Sprintf("Load this week is more than %.1f× what the body is conditioned for — the range most associated with injury and illness. Pull back to bring the ratio under %.1f over the next few days.\n", acwrSpikeAbove, acwrOverreachingAbove))
+	case load.Zone == loadZoneOverreaching:
+		builder.WriteString("Load is climbing faster than fitness. Short overreaching blocks are fine if planned; otherwise hold this week's volume steady rather than adding more.\n")
+	case load.Zone == loadZoneDetraining:
+		builder.WriteString("Recent load is well below what the body is used to. Planned deloads are fine, but a prolonged dip erodes fitness; increase gradually (about 10% per week) when ready.\n")
+	default:
+		builder.WriteString("Load is in the sweet spot: enough stimulus to build fitness without outpacing recovery.\n")
+	}
+	if load.Monotony > highMonotony {
+		builder.WriteString("\nDays are too uniform. Alternating hard and easy days lowers monotony and illness risk even at the same weekly volume.\n")
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func trainingCycles(start time.Time, strains []float64) []WhoopCycle {
+	var cycles []WhoopCycle
+	for i, strain := range strains {
+		var cycle WhoopCycle
+		cycle.Start = start.AddDate(0, 0, i)
+		cycle.ScoreState = "SCORED"
+		cycle.Score.Strain = strain
+		cycles = append(cycles, cycle)
+	}
+	return cycles
+}
+
+func TestHealthAnalyzer_AnalyzeTrainingLoad(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("insufficient history", func(t *testing.T) {
+		load := analyzer.AnalyzeTrainingLoad(trainingCycles(start, []float64{10, 12, 14}))
+		if load.Zone != "insufficient_data" {
+			t.Errorf("Expected insufficient_data, got %s", load.Zone)
+		}
+	})
+
+	t.Run("spike after steady block", func(t *testing.T) {
+		var strains []float64
+		for i := 0; i < 35; i++ {
+			if i >= 28 {
+				strains = append(strains, 18+float64(i%2))
+			} else {
+				strains = append(strains, 8+float64(i%3))
+			}
+		}
+		load := analyzer.AnalyzeTrainingLoad(trainingCycles(start, strains))
+
+		if load.Zone != loadZoneOverreaching || !load.Spike {
+			t.Errorf("Expected an overreaching spike, got %s (ACWR %.2f)", load.Zone, load.ACWR)
+		}
+		if len(load.History) != trainingLoadHistoryDays {
+			t.Errorf("Expected %d history entries, got %d", trainingLoadHistoryDays, len(load.History))
+		}
+	})
+
+	t.Run("uniform load is monotonous", func(t *testing.T) {
+		var strains []float64
+		for i := 0; i < 30; i++ {
+			strains = append(strains, 12+0.2*float64(i%2))
+		}
+		load := analyzer.AnalyzeTrainingLoad(trainingCycles(start, strains))
+
+		if load.Zone != loadZoneOptimal {
+			t.Errorf("Expected optimal zone, got %s", load.Zone)
+		}
+		if load.Monotony <= highMonotony {
+			t.Errorf("Expected high monotony, got %.2f", load.Monotony)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+const (
+	// jetLagToleranceMinutes is how close a night's sleep midpoint must come to
+	// the pre-travel midpoint, on the new local clock, to count as adapted
+	jetLagToleranceMinutes = 60.0
+	// jetLagStableNights is how many nights in a row must be within tolerance
+	jetLagStableNights = 2
+	// jetLagReferenceNights is how many nights before travel set the usual midpoint
+	jetLagReferenceNights = 7
+	// travelBufferDays are excluded from baselines after a timezone change even
+	// when sleep timing adapts sooner, since HRV and resting HR lag sleep timing
+	travelBufferDays = 2
+	// maxTravelExcludedDays caps the days excluded after a timezone change
+	maxTravelExcludedDays = 7
+)
+
+// TravelEvent is a timezone change between consecutive main sleeps
+type TravelEvent struct {
+	Date           string  `json:"date"` // local wake date of the first sleep in the new timezone
+	FromOffset     string  `json:"from_offset"`
+	ToOffset       string  `json:"to_offset"`
+	ShiftHours     float64 `json:"shift_hours"`     // positive when traveling east
+	AdaptationDays int     `json:"adaptation_days"` // nights until sleep timing restabilized; -1 when it has not yet
+}
+
+// offsetSeconds parses a Whoop timezone_offset such as "-05:00"
+func offsetSeconds(offset string) (int, bool) {
+	if offset == "Z" {
+		return 0, true
+	}
+	parsed, err := time.Parse("-07:00", offset)
+	if err != nil {
+		return 0, false
+	}
+	_, seconds := parsed.Zone()
+	return seconds, true
+}
+
+// DetectTravel finds timezone changes across main sleeps and how many nights
+// sleep timing took to settle back to its pre-travel local clock time
+func (h *HealthAnalyzer) DetectTravel(sleepData []WhoopSleep) []TravelEvent {
+	mainSleeps, _ := splitNaps(sleepData)
+	var sleeps []WhoopSleep
+	for _, sleep := range mainSleeps {
+		if _, ok := offsetSeconds(sleep.TimezoneOffset); ok {
+			sleeps = append(sleeps, sleep)
+		}
+	}
+	sort.Slice(sleeps, func(i, j int) bool { return sleeps[i].Start.Before(sleeps[j].Start) })
+
+	midpoint := func(sleep WhoopSleep) float64 {
+		mid := sleep.Start.Add(sleep.End.Sub(sleep.Start) / 2)
+		return minutesAfterNoon(localTime(mid, sleep.TimezoneOffset))
+	}
+
+	var events []TravelEvent
+	for i := 1; i < len(sleeps); i++ {
+		from, to := sleeps[i-1].TimezoneOffset, sleeps[i].TimezoneOffset
+		fromSeconds, _ := offsetSeconds(from)
+		toSeconds, _ := offsetSeconds(to)
+		if fromSeconds == toSeconds {
+			continue
+		}
+
+		var reference []float64
+		for j := i - 1; j >= 0 && j >= i-jetLagReferenceNights && sleeps[j].TimezoneOffset == from; j-- {
+			reference = append(reference, midpoint(sleeps[j]))
+		}
+		usual := stats.Median(reference)
+
+		event := TravelEvent{
+			Date:           dayKey(localTime(sleeps[i].End, to)),
+			FromOffset:     from,
+			ToOffset:       to,
+			ShiftHours:     float64(toSeconds-fromSeconds) / 3600,
+			AdaptationDays: -1,
+		}
+		stable := 0
+		for j := i; j < len(sleeps) && sleeps[j].TimezoneOffset == to; j++ {
+			if math.Abs(midpoint(sleeps[j])-usual) > jetLagToleranceMinutes {
+				stable = 0
+				continue
+			}
+			stable++
+			if stable == jetLagStableNights {
+				event.AdaptationDays = j - i - jetLagStableNights + 1
+				break
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// travelExcludedDays returns the local days around timezone changes to leave
+// out of baselines: the day before each change through its adaptation, at
+// least travelBufferDays and at most maxTravelExcludedDays after it
+func travelExcludedDays(events []TravelEvent) map[string]bool {
+	excluded := make(map[string]bool)
+	for _, event := range events {
+		day, err := time.Parse("2006-01-02", event.Date)
+		if err != nil {
+			continue
+		}
+		after := event.AdaptationDays
+		if after < 0 {
+			after = maxTravelExcludedDays
+		}
+		after = int(clamp(float64(after), travelBufferDays, maxTravelExcludedDays))
+		for offset := -1; offset <= after; offset++ {
+			excluded[dayKey(day.AddDate(0, 0, offset))] = true
+		}
+	}
+	return excluded
+}
+
+// formatOffset renders a timezone offset as "UTC-05:00"
+func formatOffset(offset string) string {
+	if offset == "Z" {
+		return "UTC"
+	}
+	return "UTC" + offset
+}
+
+// travelNote describes a timezone change for the journal
+func travelNote(event TravelEvent) string {
+	note := fmt.Sprintf("Travel: %s to %s (%+.0fh)", formatOffset(event.FromOffset), formatOffset(event.ToOffset), event.ShiftHours)
+	if event.AdaptationDays >= 0 {
+		note += fmt.Sprintf(", sleep timing adapted after %d nights", event.AdaptationDays)
+	}
+	return note
+}
+
+// FormatTravelAnalysis renders detected timezone changes and jet-lag adaptation
+func (h *HealthAnalyzer) FormatTravelAnalysis(events []TravelEvent) string {
+	var builder strings.Builder
+	builder.WriteString("# Travel and Jet Lag\n\n")
+	if len(events) == 0 {
+		builder.WriteString("No timezone changes were found in sleep records for this period.")
+		return builder.String()
+	}
+
+	builder.WriteString("| Date | From | To | Shift | Sleep Timing Adapted |\n|---|---|---|---|---|\n")
+	for _, event := range events {
+		adapted := "not yet"
+		if event.AdaptationDays >= 0 {
+			adapted = fmt.Sprintf("after %d nights", event.AdaptationDays)
+		}
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s | %+.0fh | %s |\n", event.Date,
+			formatOffset(event.FromOffset), formatOffset(event.ToOffset), event.ShiftHours, adapted))
+	}
+	builder.WriteString(fmt.Sprintf("\nSleep timing counts as adapted once the sleep midpoint stays within %.0f minutes of its pre-travel local time for %d nights. "+
+		"Days around each change are left out of personal baselines so jet lag does not skew them.", jetLagToleranceMinutes, jetLagStableNights))
+	return builder.String()
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// zonedSleep is a main sleep from bedtime to wake on the local clock of offset,
+// day days after 2024-06-01
+func zonedSleep(day int, offset string, bedtime, wake time.Duration) WhoopSleep {
+	seconds, _ := offsetSeconds(offset)
+	zone := time.FixedZone(offset, seconds)
+	midnight := time.Date(2024, 6, 1, 0, 0, 0, 0, zone).AddDate(0, 0, day)
+	return WhoopSleep{
+		Start:          midnight.Add(bedtime - 24*time.Hour),
+		End:            midnight.Add(wake),
+		TimezoneOffset: offset,
+		ScoreState:     "SCORED",
+	}
+}
+
+func TestDetectTravel(t *testing.T) {
+	var sleeps []WhoopSleep
+	// A week in New York sleeping 23:00-07:00
+	for day := 0; day < 7; day++ {
+		sleeps = append(sleeps, zonedSleep(day, "-04:00", 23*time.Hour, 7*time.Hour))
+	}
+	// Then London: two nights on New York time (04:00-12:00 local), then settled
+	sleeps = append(sleeps,
+		zonedSleep(7, "+01:00", 28*time.Hour, 12*time.Hour),
+		zonedSleep(8, "+01:00", 26*time.Hour, 10*time.Hour),
+		zonedSleep(9, "+01:00", 23*time.Hour+30*time.Minute, 7*time.Hour+30*time.Minute),
+		zonedSleep(10, "+01:00", 23*time.Hour, 7*time.Hour),
+		zonedSleep(11, "+01:00", 23*time.Hour, 7*time.Hour),
+	)
+	// A nap in another zone is not travel
+	nap := zonedSleep(11, "+09:00", 14*time.Hour, 15*time.Hour)
+	nap.Nap = true
+	sleeps = append(sleeps, nap)
+
+	events := NewHealthAnalyzer().DetectTravel(sleeps)
+	want := []TravelEvent{{Date: "2024-06-08", FromOffset: "-04:00", ToOffset: "+01:00", ShiftHours: 5, AdaptationDays: 2}}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("DetectTravel() = %+v, want %+v", events, want)
+	}
+
+	excluded := travelExcludedDays(events)
+	for _, day := range []string{"2024-06-07", "2024-06-08", "2024-06-10"} {
+		if !excluded[day] {
+			t.Errorf("%s not excluded", day)
+		}
+	}
+	if excluded["2024-06-06"] || excluded["2024-06-11"] {
+		t.Errorf("excluded = %v, want 2024-06-07 through 2024-06-10", excluded)
+	}
+
+	if note := travelNote(events[0]); note != "Travel: UTC-04:00 to UTC+01:00 (+5h), sleep timing adapted after 2 nights" {
+		t.Errorf("travelNote() = %q", note)
+	}
+}
+
+func TestDetectTravelNotAdapted(t *testing.T) {
+	sleeps := []WhoopSleep{
+		zonedSleep(0, "+01:00", 23*time.Hour, 7*time.Hour),
+		zonedSleep(1, "+01:00", 23*time.Hour, 7*time.Hour),
+		zonedSleep(2, "-07:00", 19*time.Hour, 3*time.Hour),
+	}
+	events := NewHealthAnalyzer().DetectTravel(sleeps)
+	if len(events) != 1 || events[0].AdaptationDays != -1 || events[0].ShiftHours != -8 {
+		t.Fatalf("DetectTravel() = %+v, want one unadapted -8h change", events)
+	}
+	if excluded := travelExcludedDays(events); len(excluded) != maxTravelExcludedDays+2 {
+		t.Errorf("excluded %d days, want %d for an unadapted change", len(excluded), maxTravelExcludedDays+2)
+	}
+}
+
+func TestComputeBaselineExcludesTravelDays(t *testing.T) {
+	var sleeps []WhoopSleep
+	for day := 0; day < 20; day++ {
+		offset := "-04:00"
+		if day >= 10 {
+			offset = "+01:00"
+		}
+		sleep := zonedSleep(day, offset, 23*time.Hour, 7*time.Hour)
+		sleep.Score.SleepNeeded.BaselineMilli = int(8 * time.Hour / time.Millisecond)
+		sleeps = append(sleeps, sleep)
+	}
+
+	now := time.Date(2024, 6, 21, 12, 0, 0, 0, time.UTC)
+	baseline := NewHealthAnalyzer().ComputeBaseline(&HealthData{Sleeps: sleeps}, 0, now)
+	// Adapted at once: the day before through two buffer days after
+	want := []string{"2024-06-10", "2024-06-11", "2024-06-12", "2024-06-13"}
+	if !reflect.DeepEqual(baseline.TravelDays, want) {
+		t.Errorf("TravelDays = %v, want %v", baseline.TravelDays, want)
+	}
+	if got := baseline.Short.SleepNeed.Samples; got != 16 {
+		t.Errorf("sleep need samples = %d, want 16 with travel days left out", got)
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"math"
+	"sort"
+
+	"whoop-mcp/internal/analysis/stats"
+)
+
+// minTrendSamples is the fewest values a trend is fitted to; below it the
+// confidence interval is too wide to say anything
+const minTrendSamples = 5
+
+// TrendEstimate quantifies a trend instead of only labeling it: the
+// least-squares slope per week with its 95% confidence interval, and the
+// standardized difference (Cohen's d) between the second and first half of
+// the period. Slope and interval are zero below minTrendSamples.
+type TrendEstimate struct {
+	SlopePerWeek float64 `json:"slope_per_week"`
+	CILow        float64 `json:"ci_low"`  // 95% lower bound, per week
+	CIHigh       float64 `json:"ci_high"` // 95% upper bound, per week
+	EffectSize   float64 `json:"effect_size"`
+	Magnitude    string  `json:"magnitude"` // "negligible", "small", "medium", "large", or "insufficient_data"
+	Samples      int     `json:"samples"`
+}
+
+// estimateTrend fits a trend to a daily series, which need not be sorted
+func estimateTrend(series []datedValue) TrendEstimate {
+	estimate := TrendEstimate{Magnitude: "insufficient_data", Samples: len(series)}
+	if len(series) < minTrendSamples {
+		return estimate
+	}
+
+	sorted := append([]datedValue(nil), series...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+	xs := make([]float64, len(sorted))
+	for i, v := range sorted {
+		xs[i] = v.Date.Sub(sorted[0].Date).Hours() / 24
+	}
+	ys := valuesOf(sorted)
+
+	slope, _, _ := stats.LinearRegression(xs, ys)
+	low, high := stats.RegressionSlopeCI(xs, ys)
+	if math.IsInf(low, 0) || math.IsInf(high, 0) || math.IsNaN(low) {
+		// All values on one day: no slope to speak of
+		return estimate
+	}
+	estimate.SlopePerWeek, estimate.CILow, estimate.CIHigh = slope*7, low*7, high*7
+	first, second := ys[:len(ys)/2], ys[len(ys)/2:]
+	estimate.EffectSize = stats.CohensD(first, second)
+	if estimate.EffectSize == 0 {
+		// Constant halves have no pooled spread; standardize by the whole series
+		meanFirst, _ := stats.SampleMeanVariance(first)
+		meanSecond, _ := stats.SampleMeanVariance(second)
+		if _, variance := stats.SampleMeanVariance(ys); variance > 0 {
+			estimate.EffectSize = (meanSecond - meanFirst) / math.Sqrt(variance)
+		}
+	}
+	estimate.Magnitude = stats.EffectSizeMagnitude(estimate.EffectSize)
+	return estimate
+}
+
+// Label returns "improving" or "declining" only when the confidence interval
+// excludes zero and the effect is more than negligible, otherwise "stable"
+func (e TrendEstimate) Label(higherIsBetter bool) string {
+	if e.Samples < minTrendSamples || e.Magnitude == "negligible" || e.Magnitude == "insufficient_data" {
+		return "stable"
+	}
+	switch {
+	case e.CILow > 0:
+		if higherIsBetter {
+			return "improving"
+		}
+		return "declining"
+	case e.CIHigh < 0:
+		if higherIsBetter {
+			return "declining"
+		}
+		return "improving"
+	}
+	return "stable"
+}
+
+// formatTrendEstimate renders the slope, its confidence interval, and the
+// effect size, e.g. "+2.1%/week (95% CI +0.4 to +3.8), medium effect, n=14"
+func formatTrendEstimate(l *Localizer, estimate TrendEstimate, unit string) string {
+	if estimate.Magnitude == "insufficient_data" {
+		return l.T("too few days for a trend (n=%d)", estimate.Samples)
+	}
+	return l.T("%+.1f%s/week (95%% CI %+.1f to %+.1f), %s effect, n=%d", estimate.SlopePerWeek, unit,
+		estimate.CILow, estimate.CIHigh, l.T(estimate.Magnitude), estimate.Samples)
+}
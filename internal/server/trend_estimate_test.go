@@ -0,0 +1,56 @@
+package server
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// consecutiveDays dates values on consecutive days from 2024-06-01
+func consecutiveDays(values ...float64) []datedValue {
+	series := make([]datedValue, len(values))
+	for i, value := range values {
+		series[i] = datedValue{Date: time.Date(2024, 6, 1, 7, 0, 0, 0, time.UTC).AddDate(0, 0, i), Value: value}
+	}
+	return series
+}
+
+func TestEstimateTrend(t *testing.T) {
+	// A steady climb of one point a day
+	rising := estimateTrend(consecutiveDays(50, 51.5, 52, 53.5, 54, 55.5, 56, 57.5))
+	if math.Abs(rising.SlopePerWeek-7) > 0.5 || rising.CILow <= 0 || rising.Magnitude != "large" || rising.Samples != 8 {
+		t.Errorf("rising = %+v, want about +7/week with a CI above zero and a large effect", rising)
+	}
+	if got := rising.Label(true); got != "improving" {
+		t.Errorf("Label(higher is better) = %q, want improving", got)
+	}
+	if got := rising.Label(false); got != "declining" {
+		t.Errorf("Label(lower is better) = %q, want declining", got)
+	}
+
+	// Five noisy points: a slope, but an interval spanning zero
+	noisy := estimateTrend(consecutiveDays(60, 40, 70, 35, 68))
+	if noisy.CILow >= 0 || noisy.CIHigh <= 0 {
+		t.Errorf("noisy CI = [%.1f, %.1f], want it to span zero", noisy.CILow, noisy.CIHigh)
+	}
+	if got := noisy.Label(true); got != "stable" {
+		t.Errorf("noisy Label() = %q, want stable", got)
+	}
+
+	// A step between constant halves still has an effect size
+	step := estimateTrend(consecutiveDays(10, 10, 10, 40, 40, 40))
+	if step.Magnitude != "large" || step.Label(false) != "declining" {
+		t.Errorf("step = %+v, want a large declining effect", step)
+	}
+
+	short := estimateTrend(consecutiveDays(50, 70, 90))
+	if short.Magnitude != "insufficient_data" || short.Label(true) != "stable" {
+		t.Errorf("short = %+v, want insufficient data", short)
+	}
+	if got := formatTrendEstimate(nil, short, "%"); got != "too few days for a trend (n=3)" {
+		t.Errorf("formatTrendEstimate(short) = %q", got)
+	}
+	if got := formatTrendEstimate(nil, TrendEstimate{SlopePerWeek: 2.1, CILow: 0.4, CIHigh: 3.8, Magnitude: "medium", Samples: 14}, "%"); got != "+2.1%/week (95% CI +0.4 to +3.8), medium effect, n=14" {
+		t.Errorf("formatTrendEstimate() = %q", got)
+	}
+}
@@ -0,0 +1,444 @@
+package server
+
+import (
+	"time"
+
+	"whoop-mcp/internal/mcp"
+	"whoop-mcp/internal/whoop"
+)
+
+// MCP protocol types, defined in internal/mcp
+type (
+	MCPRequest     = mcp.Request
+	MCPResponse    = mcp.Response
+	MCPError       = mcp.Error
+	MCPTool        = mcp.Tool
+	MCPInputSchema = mcp.InputSchema
+	MCPResource    = mcp.Resource
+)
+
+// Whoop API response types, defined in internal/whoop
+type (
+	WhoopUser              = whoop.User
+	WhoopRecovery          = whoop.Recovery
+	WhoopRecoveryScore     = whoop.RecoveryScore
+	WhoopSleep             = whoop.Sleep
+	WhoopSleepScore        = whoop.SleepScore
+	WhoopSleepStageSummary = whoop.SleepStageSummary
+	WhoopSleepNeeded       = whoop.SleepNeeded
+	WhoopWorkout           = whoop.Workout
+	WhoopWorkoutScore      = whoop.WorkoutScore
+	WhoopZoneDurations     = whoop.ZoneDurations
+	WhoopCycle             = whoop.Cycle
+	WhoopCycleScore        = whoop.CycleScore
+	WhoopRecoveryResponse  = whoop.RecoveryResponse
+	WhoopSleepResponse     = whoop.SleepResponse
+	WhoopWorkoutResponse   = whoop.WorkoutResponse
+	WhoopCycleResponse     = whoop.CycleResponse
+)
+
+// HealthData bundles the raw Whoop records fetched for one date range
+type HealthData struct {
+	Recoveries []WhoopRecovery
+	Sleeps     []WhoopSleep
+	Workouts   []WhoopWorkout
+	Cycles     []WhoopCycle
+}
+
+// Health Analysis Types
+type HealthSummary struct {
+	UserID            int                `json:"user_id"`
+	DateRange         DateRange          `json:"date_range"`
+	RecoveryTrend     RecoveryTrend      `json:"recovery_trend"`
+	SleepAnalysis     SleepAnalysis      `json:"sleep_analysis"`
+	StressIndicators  StressIndicators   `json:"stress_indicators"`
+	ActivityPatterns  ActivityPatterns   `json:"activity_patterns"`
+	TherapyInsights   []TherapyInsight   `json:"therapy_insights"`
+	OmittedInsights   []TherapyInsight   `json:"omitted_insights,omitempty"` // lower-priority insights beyond the cap
+	RedFlags          []RedFlag          `json:"red_flags"`
+	AcknowledgedFlags []RedFlag          `json:"acknowledged_flags,omitempty"` // red flags demoted by acknowledge_red_flag
+	Sparklines        []MetricSparkline  `json:"sparklines,omitempty"`         // recent daily values per metric
+	Thresholds        BaselineThresholds `json:"thresholds"`
+	Population        *PopulationContext `json:"population,omitempty"` // nil unless population norms are on
+}
+
+type DateRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type RecoveryTrend struct {
+	AverageScore     float64       `json:"average_score"`
+	Trend            string        `json:"trend"` // "improving", "declining", "stable"; see Estimate
+	Estimate         TrendEstimate `json:"estimate"`
+	WeeklyChange     float64       `json:"weekly_change"`
+	ConsistencyScore float64       `json:"consistency_score"`
+	LastSevenDays    []float64     `json:"last_seven_days"`
+}
+
+type SleepAnalysis struct {
+	AverageHours           float64                `json:"average_hours"`
+	AverageEfficiency      float64                `json:"average_efficiency"`
+	SleepDebtHours         float64                `json:"sleep_debt_hours"`  // running debt at the end of the window
+	DaysToRepay            float64                `json:"days_to_repay"`     // -1 when current habits do not repay the debt
+	ConsistencyScore       float64                `json:"consistency_score"` // computed from sleep duration variability
+	WhoopConsistency       float64                `json:"whoop_consistency"` // Whoop's sleep consistency, 0 when not reported
+	PerformanceScore       float64                `json:"performance_score"` // computed: sleep over baseline need
+	WhoopPerformance       float64                `json:"whoop_performance"` // Whoop's sleep performance, 0 when not reported
+	ScoreDivergences       []SleepScoreDivergence `json:"score_divergences,omitempty"`
+	DisturbanceFrequency   float64                `json:"disturbance_frequency"`
+	Wake                   WakeAnalysis           `json:"wake"`
+	OptimalBedtime         string                 `json:"optimal_bedtime"`
+	SleepQualityTrend      string                 `json:"sleep_quality_trend"`
+	QualityEstimate        TrendEstimate          `json:"quality_estimate"` // sleep efficiency, percentage points
+	NapCount               int                    `json:"nap_count"`
+	NapsPerWeek            float64                `json:"naps_per_week"`
+	AverageNapMinutes      float64                `json:"average_nap_minutes"`
+	NapCompensationHours   float64                `json:"nap_compensation_hours"` // average nap hours credited per night
+	NapNeedShare           float64                `json:"nap_need_share"`         // share of sleep need met by naps
+	NapCompensatedNights   int                    `json:"nap_compensated_nights"` // short nights made up with naps
+	ChronicNapCompensation bool                   `json:"chronic_nap_compensation"`
+	DebtLedger             SleepDebtLedger        `json:"debt_ledger"`
+}
+
+type StressIndicators struct {
+	Model               string  `json:"model"`                       // "baseline" or "legacy"
+	SuppressedHRVDays   int     `json:"suppressed_hrv_days"`         // HRV below the personal baseline
+	ElevatedHRVDays     int     `json:"elevated_hrv_days,omitempty"` // legacy model only
+	HighRestingHRDays   int     `json:"high_resting_hr_days"`
+	CycleAdjustedDays   int     `json:"cycle_adjusted_days,omitempty"` // RHR rises attributed to the luteal phase
+	SleepDebtHours      float64 `json:"sleep_debt_hours"`
+	StrainMismatchDays  int     `json:"strain_mismatch_days"` // high strain on a poor recovery
+	PoorRecoveryStreak  int     `json:"poor_recovery_streak"`
+	StressLevel         string  `json:"stress_level"` // "low", "moderate", "high", "critical"
+	PhysiologicalStress float64 `json:"physiological_stress"`
+}
+
+type ActivityPatterns struct {
+	WeeklyWorkouts     int            `json:"weekly_workouts"`
+	AverageStrain      float64        `json:"average_strain"`
+	WorkoutConsistency float64        `json:"workout_consistency"`
+	OvertrainingRisk   string         `json:"overtraining_risk"` // "low", "moderate", "high"
+	ActiveRecoveryDays int            `json:"active_recovery_days"`
+	IntensityBalance   string         `json:"intensity_balance"`
+	SportBreakdown     []SportShare   `json:"sport_breakdown,omitempty"`
+	StrainBalance      *StrainBalance `json:"strain_balance,omitempty"`
+}
+
+// StrainBalance compares day strain with the strain recovery supported
+type StrainBalance struct {
+	Days              int                 `json:"days"`
+	OverreachedDays   int                 `json:"overreached_days"`
+	UnderutilizedDays int                 `json:"underutilized_days"`
+	Score             float64             `json:"score"`    // 0-100, share of days with strain matching recovery
+	Tendency          string              `json:"tendency"` // "overreaching", "underutilizing", "in_balance"
+	Weeks             []StrainBalanceWeek `json:"weeks"`
+}
+
+// StrainBalanceWeek is the strain-recovery balance of one Monday-start week
+type StrainBalanceWeek struct {
+	WeekStart         string  `json:"week_start"`
+	Days              int     `json:"days"`
+	OverreachedDays   int     `json:"overreached_days"`
+	UnderutilizedDays int     `json:"underutilized_days"`
+	Score             float64 `json:"score"`
+}
+
+type TherapyInsight struct {
+	Category   string   `json:"category"` // "sleep", "recovery", "stress", "activity"
+	Insight    string   `json:"insight"`
+	Severity   string   `json:"severity"` // "info", "concern", "alert"
+	Priority   float64  `json:"priority"` // 0-100, higher is more pressing
+	Topic      string   `json:"topic,omitempty"`
+	Related    []string `json:"related,omitempty"` // merged insights on the same topic
+	Actionable bool     `json:"actionable"`
+	Suggestion string   `json:"suggestion,omitempty"`
+}
+
+type RedFlag struct {
+	Type           string    `json:"type"`
+	Description    string    `json:"description"`
+	Severity       string    `json:"severity"` // "moderate", "high", "critical"
+	DetectedAt     time.Time `json:"detected_at"`
+	Recommendation string    `json:"recommendation"`
+	// Acknowledgement is the reason given when the flag type was acknowledged;
+	// set only on flags listed as acknowledged rather than raised
+	Acknowledgement string `json:"acknowledgement,omitempty"`
+	// SafetyNotice is the "not medical advice" notice shown before the flag;
+	// empty when the flag does not need one or notices are off
+	SafetyNotice string `json:"safety_notice,omitempty"`
+}
+
+// Tool Input Types
+
+// DateRangeInput is the date range shared by range-based tools: start_date
+// through end_date, or start_date plus days. Both accept date expressions.
+type DateRangeInput struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date,omitempty"` // defaults to today, or the end of a range expression
+	Days      int    `json:"days,omitempty"`     // alternative to end_date
+}
+
+type HealthSummaryInput struct {
+	DateRangeInput
+	MinSeverity string `json:"min_severity,omitempty"`
+	MaxInsights int    `json:"max_insights,omitempty"`
+	Audience    string `json:"audience,omitempty"`
+	Locale      string `json:"locale,omitempty"`
+	Units       string `json:"units,omitempty"`
+	StressModel string `json:"stress_model,omitempty"`
+	UserID      *int   `json:"user_id,omitempty"`
+}
+
+type StressAnalysisInput struct {
+	DateRangeInput
+	Audience    string `json:"audience,omitempty"`
+	Locale      string `json:"locale,omitempty"`
+	StressModel string `json:"stress_model,omitempty"`
+	UserID      *int   `json:"user_id,omitempty"`
+}
+
+type SleepAnalysisInput struct {
+	DateRangeInput
+	Audience string `json:"audience,omitempty"`
+	Locale   string `json:"locale,omitempty"`
+	UserID   *int   `json:"user_id,omitempty"`
+}
+
+type WorkoutDetailsInput struct {
+	DateRangeInput
+	Sport  string `json:"sport,omitempty"` // optional sport name filter
+	Units  string `json:"units,omitempty"`
+	UserID *int   `json:"user_id,omitempty"`
+}
+
+type RenderChartInput struct {
+	Metric string `json:"metric"` // key from dailyMetrics
+	DateRangeInput
+	Format string `json:"format,omitempty"` // png or svg
+	UserID *int   `json:"user_id,omitempty"`
+}
+
+type ExportDataInput struct {
+	DateRangeInput
+	Datasets []string `json:"datasets,omitempty"` // recovery, sleep, workout, cycle
+	Format   string   `json:"format,omitempty"`   // csv or ndjson
+	Path     string   `json:"path,omitempty"`     // file or directory to write; empty returns inline
+	Units    string   `json:"units,omitempty"`
+	UserID   *int     `json:"user_id,omitempty"`
+}
+
+type ExportHealthArchiveInput struct {
+	DateRangeInput
+	Format string `json:"format"`         // apple_health, google_fit, or fhir
+	Path   string `json:"path,omitempty"` // file or directory to write; empty returns inline
+	UserID *int   `json:"user_id,omitempty"`
+}
+
+type ExportWorkoutTCXInput struct {
+	DateRangeInput
+	Sport  string `json:"sport,omitempty"` // optional sport name filter
+	Path   string `json:"path,omitempty"`  // directory to write; empty returns inline
+	UserID *int   `json:"user_id,omitempty"`
+}
+
+type ExportCalendarInput struct {
+	DateRangeInput
+	IncludeSleep bool   `json:"include_sleep,omitempty"`
+	Path         string `json:"path,omitempty"` // .ics file or directory to write; empty returns inline
+	Units        string `json:"units,omitempty"`
+	UserID       *int   `json:"user_id,omitempty"`
+}
+
+type RawDataInput struct {
+	DateRangeInput
+	Fields     []string `json:"fields,omitempty"` // dotted JSON paths, e.g. score.strain
+	MaxRecords int      `json:"max_records,omitempty"`
+	UserID     *int     `json:"user_id,omitempty"`
+}
+
+type ComparePeriodsInput struct {
+	PeriodAStart string `json:"period_a_start"`
+	PeriodAEnd   string `json:"period_a_end"`
+	PeriodALabel string `json:"period_a_label,omitempty"`
+	PeriodBStart string `json:"period_b_start"`
+	PeriodBEnd   string `json:"period_b_end"`
+	PeriodBLabel string `json:"period_b_label,omitempty"`
+	UserID       *int   `json:"user_id,omitempty"`
+}
+
+type HRVAnalysisInput struct {
+	DateRangeInput
+	UserID *int   `json:"user_id,omitempty"`
+	Locale string `json:"locale,omitempty"`
+}
+
+type RHRAnalysisInput struct {
+	DateRangeInput
+	UserID *int `json:"user_id,omitempty"`
+}
+
+type VitalsAnalysisInput struct {
+	DateRangeInput
+	Units  string `json:"units,omitempty"`
+	UserID *int   `json:"user_id,omitempty"`
+}
+
+type IllnessRiskInput struct {
+	DateRangeInput
+	Units  string `json:"units,omitempty"`
+	UserID *int   `json:"user_id,omitempty"`
+}
+
+type CircadianAnalysisInput struct {
+	DateRangeInput
+	UserID *int `json:"user_id,omitempty"`
+}
+
+type DetectTravelInput struct {
+	DateRangeInput
+	Annotate bool `json:"annotate,omitempty"`
+	UserID   *int `json:"user_id,omitempty"`
+}
+
+type SeasonalAnalysisInput struct {
+	Months     int    `json:"months"`               // months of history to analyze
+	Hemisphere string `json:"hemisphere,omitempty"` // north or south
+	UserID     *int   `json:"user_id,omitempty"`
+}
+
+type SleepDebtInput struct {
+	DateRangeInput
+	UserID *int `json:"user_id,omitempty"`
+}
+
+type CorrelateMetricsInput struct {
+	MetricX string `json:"metric_x"`
+	MetricY string `json:"metric_y"`
+	Lag     int    `json:"lag"`     // days metric_y is measured after metric_x
+	MaxLag  int    `json:"max_lag"` // when set, scan lags 0..max_lag
+	DateRangeInput
+	UserID *int `json:"user_id,omitempty"`
+}
+
+type WeeklyRhythmInput struct {
+	DateRangeInput
+	UserID *int `json:"user_id,omitempty"`
+}
+
+type DetectAnomaliesInput struct {
+	DateRangeInput
+	Method    string  `json:"method,omitempty"`    // "mad" or "zscore"
+	Threshold float64 `json:"threshold,omitempty"` // score beyond which a day is flagged
+	UserID    *int    `json:"user_id,omitempty"`
+}
+
+type PersonalBaselinesInput struct {
+	Refresh bool `json:"refresh"` // recompute instead of using the stored baseline
+	UserID  *int `json:"user_id,omitempty"`
+}
+
+type TrainingLoadInput struct {
+	DateRangeInput
+	UserID *int `json:"user_id,omitempty"`
+}
+
+type EnergyAnalysisInput struct {
+	DateRangeInput
+	IntakeKcal   float64            `json:"intake_kcal,omitempty"`    // typical daily intake
+	IntakeByDate map[string]float64 `json:"intake_by_date,omitempty"` // YYYY-MM-DD → kcal, overrides intake_kcal
+	UserID       *int               `json:"user_id,omitempty"`
+}
+
+type RecoveryForecastInput struct {
+	DateRangeInput
+	UserID *int `json:"user_id,omitempty"`
+}
+
+type BurnoutRiskInput struct {
+	Weeks  int  `json:"weeks"` // weeks of history to analyze
+	UserID *int `json:"user_id,omitempty"`
+}
+
+type LogAnnotationInput struct {
+	Note     string         `json:"note,omitempty"`
+	Date     string         `json:"date,omitempty"` // YYYY-MM-DD, defaults to today
+	Mood     *int           `json:"mood,omitempty"`
+	Symptoms map[string]int `json:"symptoms,omitempty"`
+	Tags     []string       `json:"tags,omitempty"`
+	UserID   *int           `json:"user_id,omitempty"`
+}
+
+type ListAnnotationsInput struct {
+	StartDate string `json:"start_date,omitempty"`
+	EndDate   string `json:"end_date,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+	UserID    *int   `json:"user_id,omitempty"`
+}
+
+type CorrelateMoodInput struct {
+	Scale string `json:"scale,omitempty"` // "mood" or a symptom name
+	DateRangeInput
+	UserID *int `json:"user_id,omitempty"`
+}
+
+type InterventionInput struct {
+	StartDate    string `json:"start_date,omitempty"`    // YYYY-MM-DD
+	AnnotationID *int   `json:"annotation_id,omitempty"` // use a logged annotation's date and note
+	Name         string `json:"name,omitempty"`
+	WindowDays   int    `json:"window_days"`
+	WashoutDays  int    `json:"washout_days"`
+	UserID       *int   `json:"user_id,omitempty"`
+}
+
+type LateNightImpactInput struct {
+	DateRangeInput
+	UserID *int `json:"user_id,omitempty"`
+}
+
+type LateExerciseInput struct {
+	WindowHours float64 `json:"window_hours"` // hours before bed that count as late training
+	DateRangeInput
+	UserID *int `json:"user_id,omitempty"`
+}
+
+type SetGoalInput struct {
+	Metric       string  `json:"metric"` // daily metric key or "workouts"
+	Type         string  `json:"type"`   // "average", "days", "count"
+	Target       float64 `json:"target"`
+	DaysPerWeek  int     `json:"days_per_week,omitempty"`
+	Comparison   string  `json:"comparison,omitempty"` // "at_least", "at_most"
+	RemoveGoalID *int    `json:"remove_goal_id,omitempty"`
+	UserID       *int    `json:"user_id,omitempty"`
+}
+
+type AcknowledgeRedFlagInput struct {
+	FlagType                string `json:"flag_type"`
+	Reason                  string `json:"reason,omitempty"`
+	Until                   string `json:"until,omitempty"` // date expression, last day covered
+	RemoveAcknowledgementID *int   `json:"remove_acknowledgement_id,omitempty"`
+	UserID                  *int   `json:"user_id,omitempty"`
+}
+
+type ListRedFlagsInput struct {
+	DateRangeInput
+	UserID *int `json:"user_id,omitempty"`
+}
+
+type CheckGoalsInput struct {
+	Weeks  int  `json:"weeks"` // weeks of history including the current one
+	UserID *int `json:"user_id,omitempty"`
+}
+
+type WeeklyReportInput struct {
+	WeekStart string `json:"week_start,omitempty"` // any date in the week, YYYY-MM-DD
+	UserID    *int   `json:"user_id,omitempty"`
+	Locale    string `json:"locale,omitempty"`
+}
+
+type TrendAnalysisInput struct {
+	Metric string `json:"metric"` // "recovery", "sleep", "strain"
+	DateRangeInput
+	Granularity string `json:"granularity,omitempty"` // "weekly", "monthly", "quarterly"
+	UserID      *int   `json:"user_id,omitempty"`
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// UnitSystem selects how measurements are displayed. Whoop reports metric
+// values (kilojoules, meters, °C); imperial output converts them when formatting.
+type UnitSystem string
+
+const (
+	unitsMetric   UnitSystem = "metric"
+	unitsImperial UnitSystem = "imperial"
+)
+
+const (
+	metersPerMile = 1609.344
+	feetPerMeter  = 3.28084
+)
+
+// unitSystems lists the accepted values of the units setting
+var unitSystems = []string{string(unitsMetric), string(unitsImperial)}
+
+// ParseUnitSystem validates a units setting; an empty value selects metric
+func ParseUnitSystem(value string) (UnitSystem, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", string(unitsMetric):
+		return unitsMetric, nil
+	case string(unitsImperial):
+		return unitsImperial, nil
+	default:
+		return "", fmt.Errorf("invalid units %q (expected metric or imperial)", value)
+	}
+}
+
+// NewUnitSystemFromEnv uses WHOOP_UNITS, defaulting to metric
+func NewUnitSystemFromEnv(settings Settings) (UnitSystem, error) {
+	return ParseUnitSystem(settings("WHOOP_UNITS"))
+}
+
+// unitsProperty is the shared input schema for the units argument
+func unitsProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Measurement units: metric (km, °C) or imperial (mi, °F); defaults to WHOOP_UNITS or metric",
+		"enum":        unitSystems,
+	}
+}
+
+// Distance converts kilometers to the display unit
+func (u UnitSystem) Distance(km float64) float64 {
+	if u == unitsImperial {
+		return km * 1000 / metersPerMile
+	}
+	return km
+}
+
+// DistanceUnit is the abbreviation for Distance values
+func (u UnitSystem) DistanceUnit() string {
+	if u == unitsImperial {
+		return "mi"
+	}
+	return "km"
+}
+
+// Temperature converts an absolute temperature in °C to the display unit
+func (u UnitSystem) Temperature(celsius float64) float64 {
+	if u == unitsImperial {
+		return celsius*9/5 + 32
+	}
+	return celsius
+}
+
+// TemperatureDelta converts a temperature difference in °C to the display unit
+func (u UnitSystem) TemperatureDelta(celsius float64) float64 {
+	if u == unitsImperial {
+		return celsius * 9 / 5
+	}
+	return celsius
+}
+
+// TemperatureUnit is the symbol for Temperature values
+func (u UnitSystem) TemperatureUnit() string {
+	if u == unitsImperial {
+		return "°F"
+	}
+	return "°C"
+}
+
+// displayVital converts a skin temperature summary to the display unit; other
+// vitals are unit-independent and returned unchanged
+func (u UnitSystem) displayVital(vital VitalSign) VitalSign {
+	if vital.Key != "skin_temp" || u != unitsImperial {
+		return vital
+	}
+	vital.Unit = u.TemperatureUnit()
+	vital.Baseline = u.Temperature(vital.Baseline)
+	vital.Latest = u.Temperature(vital.Latest)
+	vital.StdDev = u.TemperatureDelta(vital.StdDev)
+	anomalies := make([]VitalAnomaly, len(vital.Anomalies))
+	for i, anomaly := range vital.Anomalies {
+		anomaly.Value = u.Temperature(anomaly.Value)
+		anomaly.Deviation = u.TemperatureDelta(anomaly.Deviation)
+		anomalies[i] = anomaly
+	}
+	vital.Anomalies = anomalies
+	return vital
+}
+
+// imperialFields maps the metric fields of Whoop records to their imperial
+// replacement and conversion
+var imperialFields = map[string]struct {
+	name    string
+	convert func(float64) float64
+}{
+	"kilojoule":             {"calories", kilojoulesToKcal},
+	"distance_meter":        {"distance_miles", func(m float64) float64 { return m / metersPerMile }},
+	"altitude_gain_meter":   {"altitude_gain_feet", func(m float64) float64 { return m * feetPerMeter }},
+	"altitude_change_meter": {"altitude_change_feet", func(m float64) float64 { return m * feetPerMeter }},
+	"skin_temp_celsius":     {"skin_temp_fahrenheit", unitsImperial.Temperature},
+}
+
+// MarshalStructured encodes a structured result as indented JSON. Imperial
+// output renames and converts the metric fields of Whoop records, e.g.
+// kilojoule becomes calories and distance_meter becomes distance_miles.
+func (u UnitSystem) MarshalStructured(v interface{}) ([]byte, error) {
+	if u != unitsImperial {
+		return json.MarshalIndent(v, "", "  ")
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(convertImperialFields(generic), "", "  ")
+}
+
+// convertImperialFields walks decoded JSON and applies imperialFields
+func convertImperialFields(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, field := range v {
+			if replacement, ok := imperialFields[key]; ok {
+				if number, ok := field.(float64); ok {
+					converted[replacement.name] = replacement.convert(number)
+					continue
+				}
+			}
+			converted[key] = convertImperialFields(field)
+		}
+		return converted
+	case []interface{}:
+		for i, item := range v {
+			v[i] = convertImperialFields(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseUnitSystem(t *testing.T) {
+	for input, want := range map[string]UnitSystem{"": unitsMetric, "metric": unitsMetric, " Imperial ": unitsImperial} {
+		got, err := ParseUnitSystem(input)
+		if err != nil || got != want {
+			t.Errorf("ParseUnitSystem(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseUnitSystem("furlongs"); err == nil {
+		t.Error("Expected an error for an unknown unit system")
+	}
+}
+
+func TestUnitSystem_Conversions(t *testing.T) {
+	if got := unitsImperial.Distance(1.609344); math.Abs(got-1) > 1e-9 {
+		t.Errorf("Distance() = %.4f mi, want 1", got)
+	}
+	if got := unitsImperial.Temperature(37); math.Abs(got-98.6) > 1e-9 {
+		t.Errorf("Temperature() = %.2f°F, want 98.6", got)
+	}
+	if got := unitsImperial.TemperatureDelta(0.5); math.Abs(got-0.9) > 1e-9 {
+		t.Errorf("TemperatureDelta() = %.2f°F, want 0.9", got)
+	}
+	if got := unitsMetric.Distance(5); got != 5 {
+		t.Errorf("Metric Distance() should be unchanged, got %.2f", got)
+	}
+}
+
+func TestUnitSystem_MarshalStructured(t *testing.T) {
+	var workout WhoopWorkout
+	workout.Score.Kilojoule = 418.4
+	workout.Score.DistanceMeter = 1609.344
+
+	data, err := unitsImperial.MarshalStructured(map[string]interface{}{"workouts": []WhoopWorkout{workout}})
+	if err != nil {
+		t.Fatalf("MarshalStructured() error: %v", err)
+	}
+	var decoded struct {
+		Workouts []struct {
+			Score map[string]interface{} `json:"score"`
+		} `json:"workouts"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	score := decoded.Workouts[0].Score
+	calories, _ := score["calories"].(float64)
+	if math.Abs(calories-100) > 1e-6 || score["distance_miles"] != 1.0 {
+		t.Errorf("Expected converted calories and miles, got %v", score)
+	}
+	if _, ok := score["kilojoule"]; ok {
+		t.Errorf("Expected kilojoule to be replaced, got %v", score)
+	}
+}
+
+func TestImperialReports(t *testing.T) {
+	analyzer := NewHealthAnalyzer().WithUnits(unitsImperial)
+
+	var run WhoopWorkout
+	run.SportName = "running"
+	run.Start = time.Date(2024, 7, 1, 7, 0, 0, 0, time.UTC)
+	run.End = run.Start.Add(30 * time.Minute)
+	run.ScoreState = "SCORED"
+	run.Score.DistanceMeter = 8046.72
+	report := analyzer.FormatWorkoutDetails(analyzer.BuildWorkoutDetails([]WhoopWorkout{run}, ""))
+	if !strings.Contains(report, "5.0 mi (6:00/mi)") || !strings.Contains(report, "5.00 mi") {
+		t.Errorf("Expected distances in miles, got:\n%s", report)
+	}
+
+	vitals := analyzer.FormatVitalsAnalysis(VitalsAnalysis{SkinTemp: VitalSign{Key: "skin_temp", Label: "Skin Temperature", Unit: "°C", Readings: 10, Baseline: 33, Latest: 34}})
+	if !strings.Contains(vitals, "91.40°F") || !strings.Contains(vitals, "(+1.80)") {
+		t.Errorf("Expected skin temperature in °F, got:\n%s", vitals)
+	}
+}
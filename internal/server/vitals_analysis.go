@@ -0,0 +1,223 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// vitalsRecentReadings is how many of the latest readings are checked for anomalies
+	vitalsRecentReadings = 3
+	// respiratoryRateSpike is the rise in breaths/min over baseline treated as a spike
+	respiratoryRateSpike = 1.0
+	// spo2DropPoints is the fall in SpO2 percentage points below baseline treated as a drop
+	spo2DropPoints = 2.0
+	// spo2Floor is the absolute SpO2 below which a reading is always flagged
+	spo2Floor = 94.0
+	// skinTempElevation is the rise in °C over baseline treated as an elevation
+	skinTempElevation = 0.5
+)
+
+// VitalAnomaly is a single out-of-range vital sign reading
+type VitalAnomaly struct {
+	Date      time.Time `json:"date"`
+	Value     float64   `json:"value"`
+	Deviation float64   `json:"deviation"`
+}
+
+// VitalSign summarizes one overnight vital against the user's baseline
+type VitalSign struct {
+	Key        string         `json:"key"`
+	Label      string         `json:"label"`
+	Unit       string         `json:"unit"`
+	Readings   int            `json:"readings"`
+	Baseline   float64        `json:"baseline"`
+	StdDev     float64        `json:"std_dev"`
+	Latest     float64        `json:"latest"`
+	LatestDate time.Time      `json:"latest_date"`
+	Anomalies  []VitalAnomaly `json:"anomalies"` // within the most recent readings
+}
+
+// VitalsAnalysis covers respiratory rate, SpO2, and skin temperature
+type VitalsAnalysis struct {
+	RespiratoryRate VitalSign `json:"respiratory_rate"`
+	SpO2            VitalSign `json:"spo2"`
+	SkinTemp        VitalSign `json:"skin_temp"`
+	PossibleIllness bool      `json:"possible_illness"`
+	CycleAdjusted   int       `json:"cycle_adjusted,omitempty"` // skin temperature rises attributed to the luteal phase
+}
+
+// AnalyzeVitals compares recent respiratory rate, SpO2, and skin temperature
+// readings with the user's baseline. Baselines exclude the most recent
+// readings so an illness in progress does not mask itself.
+func (h *HealthAnalyzer) AnalyzeVitals(recoveries []WhoopRecovery, sleepData []WhoopSleep) VitalsAnalysis {
+	respiratory := mainSleepValues(sleepData, func(s WhoopSleep) float64 { return s.Score.RespiratoryRate })
+	spo2 := recoveryValues(recoveries, func(r WhoopRecovery) float64 { return r.Score.SpO2Percentage })
+	skinTemp := recoveryValues(recoveries, func(r WhoopRecovery) float64 { return r.Score.SkinTempCelsius })
+
+	analysis := VitalsAnalysis{
+		RespiratoryRate: h.summarizeVital("respiratory_rate", "Respiratory Rate", "brpm", respiratory, func(v, baseline float64) bool {
+			return v-baseline >= respiratoryRateSpike
+		}),
+		SpO2: h.summarizeVital("spo2", "Blood Oxygen (SpO2)", "%", spo2, func(v, baseline float64) bool {
+			return v < spo2Floor || baseline-v >= spo2DropPoints
+		}),
+		SkinTemp: h.summarizeVital("skin_temp", "Skin Temperature", "°C", skinTemp, func(v, baseline float64) bool {
+			return v-baseline >= skinTempElevation
+		}),
+	}
+
+	// The luteal phase raises skin temperature; only larger rises count then
+	if luteal := h.lutealDays(recoveries); len(luteal) > 0 {
+		kept := analysis.SkinTemp.Anomalies[:0]
+		for _, anomaly := range analysis.SkinTemp.Anomalies {
+			if luteal[dayKey(anomaly.Date)] && anomaly.Deviation < skinTempElevation+lutealTempAllowance {
+				analysis.CycleAdjusted++
+				continue
+			}
+			kept = append(kept, anomaly)
+		}
+		analysis.SkinTemp.Anomalies = kept
+	}
+
+	// Several vitals moving together is the classic pattern of illness onset
+	anomalous := 0
+	for _, vital := range []VitalSign{analysis.RespiratoryRate, analysis.SpO2, analysis.SkinTemp} {
+		if len(vital.Anomalies) > 0 {
+			anomalous++
+		}
+	}
+	analysis.PossibleIllness = anomalous >= 2
+
+	return analysis
+}
+
+// summarizeVital computes a baseline and checks the latest readings with isAnomaly
+func (h *HealthAnalyzer) summarizeVital(key, label, unit string, series []datedValue, isAnomaly func(value, baseline float64) bool) VitalSign {
+	// Zero means the reading was not captured
+	var readings []datedValue
+	for _, v := range series {
+		if v.Value > 0 {
+			readings = append(readings, v)
+		}
+	}
+
+	vital := VitalSign{Key: key, Label: label, Unit: unit, Readings: len(readings), Anomalies: []VitalAnomaly{}}
+	if len(readings) == 0 {
+		return vital
+	}
+
+	values := valuesOf(readings)
+	baselineValues := values
+	if len(values) > 2*vitalsRecentReadings {
+		baselineValues = values[:len(values)-vitalsRecentReadings]
+	}
+	vital.Baseline = h.calculateMean(baselineValues)
+	vital.StdDev = h.calculateStdDev(baselineValues)
+	vital.Latest = values[len(values)-1]
+	vital.LatestDate = readings[len(readings)-1].Date
+
+	// Too little history to know what normal looks like
+	if len(values) <= 2*vitalsRecentReadings {
+		return vital
+	}
+
+	for _, reading := range readings[len(readings)-vitalsRecentReadings:] {
+		if isAnomaly(reading.Value, vital.Baseline) {
+			vital.Anomalies = append(vital.Anomalies, VitalAnomaly{
+				Date:      reading.Date,
+				Value:     reading.Value,
+				Deviation: reading.Value - vital.Baseline,
+			})
+		}
+	}
+	return vital
+}
+
+// vitalsRedFlags converts recent vital-sign anomalies into red flags
+func (h *HealthAnalyzer) vitalsRedFlags(vitals VitalsAnalysis) []RedFlag {
+	var redFlags []RedFlag
+
+	if anomalies := vitals.RespiratoryRate.Anomalies; len(anomalies) > 0 {
+		latest := anomalies[len(anomalies)-1]
+		redFlags = append(redFlags, RedFlag{
+			Type:           "respiratory_rate_spike",
+			Description:    h.locale.T("Overnight respiratory rate rose to %.1f brpm (%+.1f vs baseline %.1f)", latest.Value, latest.Deviation, vitals.RespiratoryRate.Baseline),
+			Severity:       "moderate",
+			DetectedAt:     latest.Date,
+			Recommendation: h.locale.T("An elevated breathing rate during sleep often precedes respiratory illness; monitor for symptoms and prioritize rest"),
+		})
+	}
+
+	if anomalies := vitals.SpO2.Anomalies; len(anomalies) > 0 {
+		latest := anomalies[len(anomalies)-1]
+		severity := "moderate"
+		if latest.Value < 92 {
+			severity = "high"
+		}
+		redFlags = append(redFlags, RedFlag{
+			Type:           "spo2_drop",
+			Description:    h.locale.T("Blood oxygen dropped to %.1f%% (baseline %.1f%%)", latest.Value, vitals.SpO2.Baseline),
+			Severity:       severity,
+			DetectedAt:     latest.Date,
+			Recommendation: h.locale.T("Persistently low SpO2 warrants medical attention, especially with shortness of breath"),
+		})
+	}
+
+	if anomalies := vitals.SkinTemp.Anomalies; len(anomalies) > 0 {
+		latest := anomalies[len(anomalies)-1]
+		redFlags = append(redFlags, RedFlag{
+			Type:           "skin_temp_elevation",
+			Description:    h.locale.T("Skin temperature %+.1f%s above baseline", h.units.TemperatureDelta(latest.Deviation), h.units.TemperatureUnit()),
+			Severity:       "moderate",
+			DetectedAt:     latest.Date,
+			Recommendation: h.locale.T("Elevated skin temperature can signal fever or illness onset; check for other symptoms"),
+		})
+	}
+
+	if vitals.PossibleIllness {
+		redFlags = append(redFlags, RedFlag{
+			Type:           "possible_illness_onset",
+			Description:    h.locale.T("Multiple overnight vital signs deviated from baseline at the same time"),
+			Severity:       "high",
+			DetectedAt:     time.Now(),
+			Recommendation: h.locale.T("Combined vital-sign changes are a common early sign of illness; reduce training load and consider medical advice if symptoms appear"),
+		})
+	}
+
+	return redFlags
+}
+
+// FormatVitalsAnalysis renders the vitals report
+func (h *HealthAnalyzer) FormatVitalsAnalysis(vitals VitalsAnalysis) string {
+	var builder strings.Builder
+	builder.WriteString("# Overnight Vitals Analysis\n\n")
+
+	for _, vital := range []VitalSign{vitals.RespiratoryRate, vitals.SpO2, vitals.SkinTemp} {
+		vital = h.units.displayVital(vital)
+		builder.WriteString(fmt.Sprintf("## %s\n", vital.Label))
+		if vital.Readings == 0 {
+			builder.WriteString("No readings available.\n\n")
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("- **Baseline:** %.2f%s (±%.2f, %d readings)\n", vital.Baseline, vital.Unit, vital.StdDev, vital.Readings))
+		builder.WriteString(fmt.Sprintf("- **Latest:** %.2f%s on %s (%+.2f)\n", vital.Latest, vital.Unit, vital.LatestDate.Format("Jan 2"), vital.Latest-vital.Baseline))
+		if len(vital.Anomalies) == 0 {
+			builder.WriteString("- ✅ Recent readings within normal range\n\n")
+			continue
+		}
+		for _, anomaly := range vital.Anomalies {
+			builder.WriteString(fmt.Sprintf("- ⚠️ %s: %.2f%s (%+.2f vs baseline)\n", anomaly.Date.Format("Jan 2"), anomaly.Value, vital.Unit, anomaly.Deviation))
+		}
+		builder.WriteString("\n")
+	}
+
+	if vitals.PossibleIllness {
+		builder.WriteString("## 🚨 Possible Illness Onset\nSeveral vitals shifted together, a pattern that often appears a day or two before symptoms. Consider lighter activity and extra sleep.\n\n")
+	}
+
+	builder.WriteString(cycleNote(h.locale, vitals.CycleAdjusted, h.locale.T("skin temperature rise(s)")))
+	builder.WriteString("*Vitals from wearables are screening signals, not diagnoses. Seek medical care for concerning symptoms.*\n")
+	return builder.String()
+}
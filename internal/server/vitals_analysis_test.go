@@ -0,0 +1,60 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_AnalyzeVitals(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	start := time.Date(2024, 2, 1, 7, 0, 0, 0, time.UTC)
+
+	var recoveries []WhoopRecovery
+	var sleeps []WhoopSleep
+	for i := 0; i < 14; i++ {
+		var recovery WhoopRecovery
+		recovery.ScoreState = "SCORED"
+		recovery.CreatedAt = start.AddDate(0, 0, i)
+		recovery.Score.SpO2Percentage = 97
+		recovery.Score.SkinTempCelsius = 33.5
+
+		var sleep WhoopSleep
+		sleep.ScoreState = "SCORED"
+		sleep.End = start.AddDate(0, 0, i)
+		sleep.Score.RespiratoryRate = 14.5
+
+		// The last two nights look like the start of an illness
+		if i >= 12 {
+			recovery.Score.SkinTempCelsius = 34.4
+			sleep.Score.RespiratoryRate = 16.2
+		}
+		recoveries = append(recoveries, recovery)
+		sleeps = append(sleeps, sleep)
+	}
+
+	vitals := analyzer.AnalyzeVitals(recoveries, sleeps)
+
+	if len(vitals.RespiratoryRate.Anomalies) != 2 {
+		t.Errorf("Expected 2 respiratory anomalies, got %d", len(vitals.RespiratoryRate.Anomalies))
+	}
+	if len(vitals.SkinTemp.Anomalies) != 2 {
+		t.Errorf("Expected 2 skin temperature anomalies, got %d", len(vitals.SkinTemp.Anomalies))
+	}
+	if len(vitals.SpO2.Anomalies) != 0 {
+		t.Errorf("Expected no SpO2 anomalies, got %d", len(vitals.SpO2.Anomalies))
+	}
+	if !vitals.PossibleIllness {
+		t.Error("Expected possible illness onset")
+	}
+
+	flags := analyzer.vitalsRedFlags(vitals)
+	types := make(map[string]bool)
+	for _, flag := range flags {
+		types[flag.Type] = true
+	}
+	for _, want := range []string{"respiratory_rate_spike", "skin_temp_elevation", "possible_illness_onset"} {
+		if !types[want] {
+			t.Errorf("Expected red flag %s, got %v", want, types)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package server
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+const (
+	// notableWeeklyChange is the percent change vs last week worth calling out
+	notableWeeklyChange = 5.0
+	// redRecovery is the top of Whoop's red recovery band
+	redRecovery = 34.0
+)
+
+// WeeklyMetric compares one daily metric's week average against the week before
+type WeeklyMetric struct {
+	Key            string  `json:"key"`
+	Label          string  `json:"label"`
+	Unit           string  `json:"unit"`
+	HigherIsBetter bool    `json:"higher_is_better"`
+	ThisWeek       float64 `json:"this_week"`
+	LastWeek       float64 `json:"last_week"`
+	Days           int     `json:"days"`
+	LastWeekDays   int     `json:"last_week_days"`
+	PercentChange  float64 `json:"percent_change"`
+}
+
+// WeeklyReport is a Monday-to-Sunday digest for a therapy or coaching agenda
+type WeeklyReport struct {
+	WeekStart      time.Time      `json:"week_start"`
+	WeekEnd        time.Time      `json:"week_end"`
+	Complete       bool           `json:"complete"`
+	Metrics        []WeeklyMetric `json:"metrics"`
+	GreenDays      int            `json:"green_days"`
+	YellowDays     int            `json:"yellow_days"`
+	RedDays        int            `json:"red_days"`
+	ShortNights    int            `json:"short_nights"` // main sleeps under 7 hours
+	Workouts       int            `json:"workouts"`
+	WorkoutHours   float64        `json:"workout_hours"`
+	Highlights     []string       `json:"highlights"`
+	RedFlags       []RedFlag      `json:"red_flags"`
+	Acknowledged   []RedFlag      `json:"acknowledged_flags,omitempty"`
+	Goals          []GoalProgress `json:"goals"`
+	Annotations    []Annotation   `json:"annotations"`
+	Prompts        []string       `json:"discussion_prompts"`
+	StressLevel    string         `json:"stress_level"`
+	SleepDebtHours float64        `json:"sleep_debt_hours"`
+	BestRecovery   *datedValue    `json:"-"`
+	WorstRecovery  *datedValue    `json:"-"`
+}
+
+// filterHealthData keeps records that begin (or, for recoveries, are created)
+// within [start, end)
+func filterHealthData(data *HealthData, start, end time.Time) *HealthData {
+	in := func(t time.Time) bool { return !t.Before(start) && t.Before(end) }
+	filtered := &HealthData{}
+	for _, r := range data.Recoveries {
+		if in(r.CreatedAt) {
+			filtered.Recoveries = append(filtered.Recoveries, r)
+		}
+	}
+	for _, s := range data.Sleeps {
+		if in(s.End) {
+			filtered.Sleeps = append(filtered.Sleeps, s)
+		}
+	}
+	for _, w := range data.Workouts {
+		if in(w.Start) {
+			filtered.Workouts = append(filtered.Workouts, w)
+		}
+	}
+	for _, c := range data.Cycles {
+		if in(c.Start) {
+			filtered.Cycles = append(filtered.Cycles, c)
+		}
+	}
+	return filtered
+}
+
+// BuildWeeklyReport summarizes the Monday-start week containing weekStart,
+// comparing it with the week before. data must cover both weeks.
+func (h *HealthAnalyzer) BuildWeeklyReport(ctx context.Context, data *HealthData, weekStart time.Time, baseline *PersonalBaseline, goals []Goal, annotations []Annotation, acks []RedFlagAck, now time.Time) WeeklyReport {
+	start := bucketStart(weekStart, granularityWeekly)
+	end := start.AddDate(0, 0, 7)
+	report := WeeklyReport{
+		WeekStart:   start,
+		WeekEnd:     end.AddDate(0, 0, -1),
+		Complete:    !now.Before(end),
+		Annotations: annotations,
+	}
+
+	thisWeek, lastWeek := dayKey(start), dayKey(start.AddDate(0, 0, -7))
+	for _, metric := range dailyMetrics {
+		var current, previous []float64
+		for _, v := range metric.extract(data) {
+			switch dayKey(bucketStart(v.Date, granularityWeekly)) {
+			case thisWeek:
+				current = append(current, v.Value)
+			case lastWeek:
+				previous = append(previous, v.Value)
+			}
+		}
+		entry := WeeklyMetric{
+			Key: metric.Key, Label: metric.Label, Unit: metric.Unit, HigherIsBetter: metric.HigherIsBetter,
+			ThisWeek: h.calculateMean(current), LastWeek: h.calculateMean(previous),
+			Days: len(current), LastWeekDays: len(previous),
+		}
+		if len(current) > 0 && len(previous) > 0 && entry.LastWeek != 0 {
+			entry.PercentChange = (entry.ThisWeek - entry.LastWeek) / math.Abs(entry.LastWeek) * 100
+		}
+		report.Metrics = append(report.Metrics, entry)
+
+		if metric.Key == "recovery" {
+			for _, v := range metric.extract(data) {
+				if dayKey(bucketStart(v.Date, granularityWeekly)) != thisWeek {
+					continue
+				}
+				v := v
+				switch {
+				case v.Value >= greenRecovery:
+					report.GreenDays++
+				case v.Value >= redRecovery:
+					report.YellowDays++
+				default:
+					report.RedDays++
+				}
+				if report.BestRecovery == nil || v.Value > report.BestRecovery.Value {
+					report.BestRecovery = &v
+				}
+				if report.WorstRecovery == nil || v.Value < report.WorstRecovery.Value {
+					report.WorstRecovery = &v
+				}
+			}
+		}
+		if metric.Key == "sleep_hours" {
+			for _, value := range current {
+				if value < 7 {
+					report.ShortNights++
+				}
+			}
+		}
+	}
+
+	week := filterHealthData(data, start, end)
+	for _, workout := range week.Workouts {
+		report.Workouts++
+		report.WorkoutHours += workout.End.Sub(workout.Start).Hours()
+	}
+
+	summary, _ := h.AnalyzeHealthSummary(ctx, week.Recoveries, week.Sleeps, week.Workouts, week.Cycles, start, end, 0, baseline)
+	report.RedFlags, report.Acknowledged = partitionRedFlags(summary.RedFlags, acks, now)
+	report.StressLevel = summary.StressIndicators.StressLevel
+	report.SleepDebtHours = summary.SleepAnalysis.SleepDebtHours
+
+	for _, goal := range goals {
+		report.Goals = append(report.Goals, h.EvaluateGoal(goal, data, start, report.WeekEnd, now))
+	}
+
+	report.Highlights = weeklyHighlights(h.locale, report)
+	report.Prompts = weeklyPrompts(h.locale, report)
+	return report
+}
+
+// weeklyHighlights picks out the week's notable days and changes, in the
+// localizer's language
+func weeklyHighlights(l *Localizer, report WeeklyReport) []string {
+	var highlights []string
+	if report.BestRecovery != nil {
+		highlights = append(highlights, l.T("Best recovery: %.0f%% on %s", report.BestRecovery.Value, l.T(report.BestRecovery.Date.Weekday().String())))
+	}
+	if report.WorstRecovery != nil && report.WorstRecovery.Value != report.BestRecovery.Value {
+		highlights = append(highlights, l.T("Lowest recovery: %.0f%% on %s", report.WorstRecovery.Value, l.T(report.WorstRecovery.Date.Weekday().String())))
+	}
+	for _, metric := range report.Metrics {
+		if metric.Days == 0 || metric.LastWeekDays == 0 || math.Abs(metric.PercentChange) < notableWeeklyChange {
+			continue
+		}
+		verdict := markMessage("improved")
+		if (metric.PercentChange > 0) != metric.HigherIsBetter {
+			verdict = markMessage("worsened")
+		}
+		if metric.Key == "strain" {
+			verdict = markMessage("rose")
+			if metric.PercentChange < 0 {
+				verdict = markMessage("fell")
+			}
+		}
+		highlights = append(highlights, l.T("%s %s %.0f%% vs last week", l.T(metric.Label), l.T(verdict), math.Abs(metric.PercentChange)))
+	}
+	for _, goal := range report.Goals {
+		if n := len(goal.Weeks); n > 0 && goal.Weeks[n-1].Met && goal.CurrentStreak >= 2 {
+			highlights = append(highlights, l.T("Goal streak: %s, %d weeks running", goal.Description, goal.CurrentStreak))
+		}
+	}
+	return highlights
+}
+
+// weeklyPrompts suggests conversation openers grounded in the week's data, in
+// the localizer's language
+func weeklyPrompts(l *Localizer, report WeeklyReport) []string {
+	var prompts []string
+	if report.WorstRecovery != nil && report.WorstRecovery.Value < redRecovery {
+		prompts = append(prompts, l.T("Recovery dipped to %.0f%% on %s. What was happening the day before?", report.WorstRecovery.Value, l.T(report.WorstRecovery.Date.Weekday().String())))
+	}
+	if report.ShortNights >= 3 {
+		prompts = append(prompts, l.T("%d nights were under 7 hours. What got in the way of sleep?", report.ShortNights))
+	}
+	for _, flag := range report.RedFlags {
+		prompts = append(prompts, l.T("%s How does that match how the week felt?", flag.Description+"."))
+	}
+	for _, goal := range report.Goals {
+		if n := len(goal.Weeks); n > 0 && !goal.Weeks[n-1].Met {
+			prompts = append(prompts, l.T("The goal \"%s\" was missed. What made it hard this week?", goal.Description))
+		}
+	}
+	if len(report.Annotations) > 0 {
+		prompts = append(prompts, l.T("Review the journal notes below. Did those events line up with the physiological changes?"))
+	}
+	if report.GreenDays >= 4 {
+		prompts = append(prompts, l.T("%d green recovery days. What went well that is worth repeating?", report.GreenDays))
+	}
+	if len(prompts) == 0 {
+		prompts = append(prompts, l.T("A steady week. Which routines are keeping things stable?"))
+	}
+	return prompts
+}
+
+// FormatWeeklyReport renders the report in a fixed layout so successive weeks
+// read the same way
+func (h *HealthAnalyzer) FormatWeeklyReport(report WeeklyReport) string {
+	return h.templates.Render(h.locale, "weekly_report", report)
+}
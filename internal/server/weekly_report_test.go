@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_BuildWeeklyReport(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	hour := int(time.Hour / time.Millisecond)
+	lastWeek := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) // a Monday
+	week := lastWeek.AddDate(0, 0, 7)
+	now := week.AddDate(0, 0, 8)
+
+	data := &HealthData{}
+	for i := 0; i < 14; i++ {
+		wake := lastWeek.AddDate(0, 0, i).Add(7 * time.Hour)
+		hours, recovery := 7.5, 70.0
+		if i >= 7 {
+			hours, recovery = 6.5, 50
+		}
+		if i == 10 {
+			recovery = 20 // Thursday crash
+		}
+
+		var sleep WhoopSleep
+		sleep.ID = fmt.Sprintf("sleep-%d", i)
+		sleep.Start = wake.Add(-time.Duration(hours * float64(time.Hour)))
+		sleep.End = wake
+		sleep.Score.StageSummary.TotalInBedTimeMilli = int(hours * float64(hour))
+		sleep.Score.SleepNeeded.BaselineMilli = 8 * hour
+		data.Sleeps = append(data.Sleeps, sleep)
+
+		var r WhoopRecovery
+		r.SleepID = sleep.ID
+		r.CreatedAt = wake
+		r.Score.RecoveryScore = recovery
+		data.Recoveries = append(data.Recoveries, r)
+	}
+	var workout WhoopWorkout
+	workout.Start = week.Add(30 * time.Hour)
+	workout.End = workout.Start.Add(90 * time.Minute)
+	data.Workouts = append(data.Workouts, workout)
+
+	goals := []Goal{{ID: 1, Metric: "sleep_hours", Type: goalAverage, Target: 7}}
+	annotations := []Annotation{{ID: 1, Date: "2024-01-10", Note: "big work deadline"}}
+	report := analyzer.BuildWeeklyReport(context.Background(), data, week.AddDate(0, 0, 3), nil, goals, annotations, nil, now)
+
+	if dayKey(report.WeekStart) != "2024-01-08" || dayKey(report.WeekEnd) != "2024-01-14" || !report.Complete {
+		t.Fatalf("Unexpected week bounds: %s to %s", dayKey(report.WeekStart), dayKey(report.WeekEnd))
+	}
+	if report.YellowDays != 6 || report.RedDays != 1 || report.ShortNights != 7 {
+		t.Errorf("Expected 6 yellow, 1 red, 7 short nights; got %d/%d/%d", report.YellowDays, report.RedDays, report.ShortNights)
+	}
+	if report.Workouts != 1 || report.WorkoutHours != 1.5 {
+		t.Errorf("Expected one 1.5h workout, got %d (%.1fh)", report.Workouts, report.WorkoutHours)
+	}
+
+	out := analyzer.FormatWeeklyReport(report)
+	for _, want := range []string{
+		"# Weekly Report: Mon Jan 8 – Sun Jan 14, 2024",
+		"Lowest recovery: 20% on Thursday",
+		"Sleep Duration worsened 13% vs last week",
+		"big work deadline",
+		"The goal \"Average sleep duration ≥ 7h\" was missed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected report to contain %q:\n%s", want, out)
+		}
+	}
+
+	spanish, err := NewLocalizer("es")
+	if err != nil {
+		t.Fatal(err)
+	}
+	localized := analyzer.WithLocale(spanish)
+	out = localized.FormatWeeklyReport(localized.BuildWeeklyReport(context.Background(), data, week.AddDate(0, 0, 3), nil, goals, annotations, nil, now))
+	for _, want := range []string{
+		"# Informe semanal: 2024-01-08 – 2024-01-14",
+		"Recuperación más baja: 20% el jueves",
+		"Duración del sueño empeoró un 13% respecto a la semana anterior",
+		"No se cumplió el objetivo \"Media de duración del sueño ≥ 7h\"",
+		"## Objetivos",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected the Spanish report to contain %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// weekdayDeviationSD is how far (in SDs of the metric) a weekday mean must sit from
+	// the overall mean to be called out as a pattern
+	weekdayDeviationSD = 0.5
+	// minWeekdaySamples is the fewest observations of a weekday needed to call a pattern
+	minWeekdaySamples = 2
+)
+
+// weeklyRhythmMetrics are the metrics broken down by weekday
+var weeklyRhythmMetrics = []string{"recovery", "hrv", "sleep_hours", "sleep_performance", "strain"}
+
+// WeekdayStat is one metric's average on one weekday
+type WeekdayStat struct {
+	Weekday   string  `json:"weekday"`
+	Mean      float64 `json:"mean"`
+	Count     int     `json:"count"`
+	Deviation float64 `json:"deviation_sd"` // from the metric's overall mean, in SDs
+}
+
+// MetricWeeklyRhythm is one metric's weekday breakdown
+type MetricWeeklyRhythm struct {
+	Metric   string        `json:"metric"`
+	Label    string        `json:"label"`
+	Unit     string        `json:"unit"`
+	Mean     float64       `json:"overall_mean"`
+	StdDev   float64       `json:"std_dev"`
+	Weekdays []WeekdayStat `json:"weekdays"` // Monday first
+}
+
+// WeeklyPattern is an actionable weekday pattern such as "Sunday-night insomnia"
+type WeeklyPattern struct {
+	Name        string  `json:"name"`
+	Metric      string  `json:"metric"`
+	Weekday     string  `json:"weekday"`
+	Deviation   float64 `json:"deviation_sd"`
+	Description string  `json:"description"`
+	Adverse     bool    `json:"adverse"`
+}
+
+// WeeklyRhythm is the full day-of-week analysis
+type WeeklyRhythm struct {
+	Metrics  []MetricWeeklyRhythm `json:"metrics"`
+	Patterns []WeeklyPattern      `json:"patterns"`
+}
+
+// mondayFirst orders weekdays as people read a calendar week
+var mondayFirst = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+
+// AnalyzeWeeklyRhythm breaks metrics down by weekday and names the weekdays
+// that sit well away from the user's overall average
+func (h *HealthAnalyzer) AnalyzeWeeklyRhythm(data *HealthData) WeeklyRhythm {
+	rhythm := WeeklyRhythm{Patterns: []WeeklyPattern{}}
+
+	for _, key := range weeklyRhythmMetrics {
+		metric, ok := lookupMetric(key)
+		if !ok {
+			continue
+		}
+		series := metric.extract(data)
+		values := valuesOf(series)
+
+		breakdown := MetricWeeklyRhythm{
+			Metric: metric.Key,
+			Label:  metric.Label,
+			Unit:   metric.Unit,
+			Mean:   h.calculateMean(values),
+			StdDev: h.calculateStdDev(values),
+		}
+
+		byWeekday := make(map[time.Weekday][]float64)
+		for _, v := range series {
+			byWeekday[v.Date.Weekday()] = append(byWeekday[v.Date.Weekday()], v.Value)
+		}
+
+		for _, weekday := range mondayFirst {
+			dayValues := byWeekday[weekday]
+			stat := WeekdayStat{Weekday: weekday.String(), Count: len(dayValues)}
+			if len(dayValues) > 0 {
+				stat.Mean = h.calculateMean(dayValues)
+				if breakdown.StdDev > 0 {
+					stat.Deviation = (stat.Mean - breakdown.Mean) / breakdown.StdDev
+				}
+			}
+			breakdown.Weekdays = append(breakdown.Weekdays, stat)
+
+			if stat.Count >= minWeekdaySamples && math.Abs(stat.Deviation) >= weekdayDeviationSD {
+				rhythm.Patterns = append(rhythm.Patterns, describeWeeklyPattern(metric, weekday, stat))
+			}
+		}
+
+		rhythm.Metrics = append(rhythm.Metrics, breakdown)
+	}
+
+	return rhythm
+}
+
+// describeWeeklyPattern names a weekday deviation in therapy-friendly terms.
+// Sleep is dated by wake-up, so a short Monday sleep is "Sunday night".
+func describeWeeklyPattern(metric metricDefinition, weekday time.Weekday, stat WeekdayStat) WeeklyPattern {
+	high := stat.Deviation > 0
+	pattern := WeeklyPattern{
+		Metric:    metric.Key,
+		Weekday:   weekday.String(),
+		Deviation: stat.Deviation,
+		Adverse:   high != metric.HigherIsBetter,
+	}
+
+	night := (weekday + 6) % 7 // the evening before the wake-up day
+	switch metric.Key {
+	case "sleep_hours":
+		if high {
+			pattern.Name = fmt.Sprintf("%s-night catch-up sleep", night)
+		} else {
+			pattern.Name = fmt.Sprintf("%s-night short sleep", night)
+		}
+	case "sleep_performance":
+		if high {
+			pattern.Name = fmt.Sprintf("%s-night good sleep", night)
+		} else {
+			pattern.Name = fmt.Sprintf("%s-night insomnia", night)
+		}
+	case "recovery", "hrv":
+		if high {
+			pattern.Name = fmt.Sprintf("%s %s peak", weekday, strings.ToLower(metric.Label))
+		} else {
+			pattern.Name = fmt.Sprintf("%s %s crash", weekday, strings.ToLower(metric.Label))
+		}
+	case "strain":
+		if high {
+			pattern.Name = fmt.Sprintf("%s strain spike", weekday)
+		} else {
+			pattern.Name = fmt.Sprintf("%s low-activity day", weekday)
+		}
+	default:
+		pattern.Name = fmt.Sprintf("%s %s pattern", weekday, metric.Label)
+	}
+
+	direction := "above"
+	if !high {
+		direction = "below"
+	}
+	pattern.Description = fmt.Sprintf("%s averages %.1f%s on %ss, %.1f SD %s the usual level (%d weeks observed)",
+		metric.Label, stat.Mean, metric.Unit, weekday, math.Abs(stat.Deviation), direction, stat.Count)
+	return pattern
+}
+
+// FormatWeeklyRhythm renders the weekday table and named patterns
+func (h *HealthAnalyzer) FormatWeeklyRhythm(rhythm WeeklyRhythm) string {
+	var builder strings.Builder
+	builder.WriteString("# Weekly Rhythm Analysis\n\n")
+
+	builder.WriteString("| Metric | Mon | Tue | Wed | Thu | Fri | Sat | Sun |\n")
+	builder.WriteString("|---|---|---|---|---|---|---|---|\n")
+	for _, metric := range rhythm.Metrics {
+		builder.WriteString("| " + metric.Label)
+		for _, day := range metric.Weekdays {
+			if day.Count == 0 {
+				builder.WriteString(" | -")
+				continue
+			}
+			builder.WriteString(fmt.Sprintf(" | %.1f", day.Mean))
+		}
+		builder.WriteString(" |\n")
+	}
+	builder.WriteString("\n*Sleep is shown on the day you woke up.*\n\n")
+
+	builder.WriteString("## Patterns\n")
+	if len(rhythm.Patterns) == 0 {
+		builder.WriteString("No weekday stands out; the week looks evenly balanced.\n")
+		return builder.String()
+	}
+
+	for _, pattern := range rhythm.Patterns {
+		icon := "📈"
+		if pattern.Adverse {
+			icon = "⚠️"
+		}
+		builder.WriteString(fmt.Sprintf("- %s **%s** — %s\n", icon, pattern.Name, pattern.Description))
+	}
+
+	builder.WriteString("\n## Therapy Notes\n")
+	builder.WriteString("Recurring weekday patterns usually trace back to routines: anticipatory stress before the work week, weekend alcohol or late nights, or a fixed heavy training day. Ask what typically happens the day before each flagged pattern.\n")
+	return builder.String()
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_AnalyzeWeeklyRhythm(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	hour := int(time.Hour / time.Millisecond)
+	monday := time.Date(2024, 9, 2, 7, 0, 0, 0, time.UTC)
+
+	data := &HealthData{}
+	for i := 0; i < 28; i++ {
+		wake := monday.AddDate(0, 0, i)
+
+		var sleep WhoopSleep
+		sleep.ID = wake.Format("2006-01-02")
+		sleep.End = wake
+		sleep.Start = wake.Add(-8 * time.Hour)
+		sleep.Score.StageSummary.TotalInBedTimeMilli = 8 * hour
+		sleep.Score.SleepPerformancePercentage = 90
+
+		var recovery WhoopRecovery
+		recovery.SleepID = sleep.ID
+		recovery.CreatedAt = wake
+		recovery.Score.RecoveryScore = 65
+
+		if wake.Weekday() == time.Monday {
+			sleep.Score.StageSummary.TotalInBedTimeMilli = 5 * hour
+			sleep.Score.SleepPerformancePercentage = 55
+			recovery.Score.RecoveryScore = 30
+		}
+		data.Sleeps = append(data.Sleeps, sleep)
+		data.Recoveries = append(data.Recoveries, recovery)
+	}
+
+	rhythm := analyzer.AnalyzeWeeklyRhythm(data)
+
+	names := make(map[string]bool)
+	for _, pattern := range rhythm.Patterns {
+		names[pattern.Name] = true
+		if pattern.Weekday != "Monday" {
+			t.Errorf("Unexpected pattern on %s: %s", pattern.Weekday, pattern.Name)
+		}
+	}
+	for _, want := range []string{"Sunday-night insomnia", "Sunday-night short sleep", "Monday recovery crash"} {
+		if !names[want] {
+			t.Errorf("Expected pattern %q, got %v", want, names)
+		}
+	}
+}
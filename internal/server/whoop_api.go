@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// WhoopAPI is the Whoop access MCPServer depends on. *WhoopClient implements
+// it against the live API; MockWhoopAPI serves records from memory so tool
+// handlers can be tested without a network or credentials.
+type WhoopAPI interface {
+	// Health data. userID selects an account from whoop://accounts; nil
+	// means the authenticated user.
+	GetUser(ctx context.Context) (*WhoopUser, error)
+	GetRecoveryData(ctx context.Context, startDate, endDate time.Time, userID *int) ([]WhoopRecovery, error)
+	GetSleepData(ctx context.Context, startDate, endDate time.Time, userID *int) ([]WhoopSleep, error)
+	GetSleepDataFiltered(ctx context.Context, startDate, endDate time.Time, userID *int, filter SleepFilter) ([]WhoopSleep, error)
+	GetWorkoutData(ctx context.Context, startDate, endDate time.Time, userID *int) ([]WhoopWorkout, error)
+	GetCycleData(ctx context.Context, startDate, endDate time.Time, userID *int) ([]WhoopCycle, error)
+	ValidateConnection() error
+
+	// Page-at-a-time health data, for analyses over long ranges that keep
+	// running statistics rather than every record. page sees each page once,
+	// newest first; an error from page stops the fetch and is returned.
+	EachRecoveryPage(ctx context.Context, startDate, endDate time.Time, userID *int, page func([]WhoopRecovery) error) error
+	EachSleepPage(ctx context.Context, startDate, endDate time.Time, userID *int, page func([]WhoopSleep) error) error
+	EachWorkoutPage(ctx context.Context, startDate, endDate time.Time, userID *int, page func([]WhoopWorkout) error) error
+	EachCyclePage(ctx context.Context, startDate, endDate time.Time, userID *int, page func([]WhoopCycle) error) error
+
+	// Authorization and quota
+	AuthRequired() *AuthRequiredError
+	CanRefreshToken() bool
+	TokenExpiry() *time.Time
+	TokenStore() TokenStore
+	ClientID() string
+	InstallTokens(tokens *OAuthTokenResponse) error
+	TokenGeneration() uint64
+	SetReadOnly(readOnly bool)
+	DetectScopes() ScopeStatus
+	Scopes() ScopeStatus
+	RateLimitStatus() RateLimitStatus
+
+	// State shared with the analyzers
+	Sports() *SportsCatalog
+	Timezone() *UserTimezone
+	Accounts() *CredentialStore
+	FixtureSource() string
+}
+
+var _ WhoopAPI = (*WhoopClient)(nil)
+
+// apiPageSize is how many records the Whoop API returns per page
+const apiPageSize = 25
+
+// eachPage passes records to page in apiPageSize chunks, as the API would
+// page them
+func eachPage[T any](records []T, page func([]T) error) error {
+	for len(records) > 0 {
+		n := min(apiPageSize, len(records))
+		if err := page(records[:n]); err != nil {
+			return err
+		}
+		records = records[n:]
+	}
+	return nil
+}
+
+// recordsBetween returns the records whose time falls in [start, end), newest
+// first; a zero start or end leaves that side open
+func recordsBetween[T any](records []T, at func(T) time.Time, start, end time.Time) []T {
+	matched := make([]T, 0, len(records))
+	for _, record := range records {
+		t := at(record)
+		if (start.IsZero() || !t.Before(start)) && (end.IsZero() || t.Before(end)) {
+			matched = append(matched, record)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return at(matched[i]).After(at(matched[j])) })
+	return matched
+}
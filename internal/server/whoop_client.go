@@ -0,0 +1,1022 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"whoop-mcp/internal/whoop"
+)
+
+const (
+	WhoopAPIBaseURL = whoop.APIBaseURL
+	WhoopAPIVersion = "v2"
+)
+
+// WhoopClient handles all interactions with the Whoop API
+type WhoopClient struct {
+	client       *http.Client
+	rateLimiter  *rate.Limiter
+	tokens       *tokenHolder
+	clientID     string
+	clientSecret string
+	baseURL      string
+
+	// tokenStore persists rotated default tokens
+	tokenStore TokenStore
+	// scopes tracks which OAuth scopes the default token was granted
+	scopes *grantedScopes
+	// meter tracks request volume for quota reporting
+	meter *requestMeter
+	// sports caches the sport ID → name mapping seen on workouts
+	sports *SportsCatalog
+	// timezone resolves local-day boundaries, learning offsets from fetched sleeps
+	timezone *UserTimezone
+	// accounts holds per-user tokens for multi-account access (nil when not configured)
+	accounts *CredentialStore
+	// defaultUserID is the user behind the default token, learned from the profile endpoint
+	defaultUserID atomic.Int64
+	// profile caches the default user's profile until the tokens change
+	profile atomic.Pointer[WhoopUser]
+	// tokenGeneration counts the tokens installed or rotated; see TokenGeneration
+	tokenGeneration atomic.Uint64
+	// accountRefreshMu serializes refreshes of multi-account tokens
+	accountRefreshMu sync.Mutex
+	// readOnly keeps refreshed tokens in memory instead of persisting them
+	readOnly atomic.Bool
+	// fixtures serves requests instead of the API in offline mode (nil otherwise)
+	fixtures *FixtureSet
+	// strictDecode rejects responses with fields the models do not know
+	strictDecode bool
+}
+
+// NewWhoopClient creates a new Whoop API client with rate limiting
+func NewWhoopClient(settings Settings) (*WhoopClient, error) {
+	tokenStore, err := NewTokenStoreFromEnv(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Offline mode serves fixtures, so no credentials are needed
+	offline, err := offlineFromEnv(settings)
+	if err != nil {
+		return nil, err
+	}
+	cassetteMode, cassettePath, err := cassetteFromEnv(settings)
+	if err != nil {
+		return nil, err
+	}
+	if offline && cassetteMode != "" {
+		return nil, fmt.Errorf("WHOOP_OFFLINE and WHOOP_CASSETTE cannot be used together")
+	}
+	strictDecode, err := strictDecodeFromEnv(settings)
+	if err != nil {
+		return nil, err
+	}
+	var fixtures *FixtureSet
+	if offline {
+		if fixtures, err = LoadFixturesFromEnv(settings, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Try access token first (OAuth), then fall back to API key
+	apiKey := settings("WHOOP_ACCESS_TOKEN")
+	if apiKey == "" {
+		apiKey = settings("WHOOP_API_KEY")
+	}
+
+	// Get refresh token and OAuth credentials for auto-refresh
+	refreshToken := settings("WHOOP_REFRESH_TOKEN")
+	if offline || cassetteMode == cassetteReplay {
+		apiKey, refreshToken = offlineToken, ""
+	}
+
+	// Fall back to the token store when the environment carries no token
+	grantedScope := ""
+	var expiresAt *time.Time
+	if apiKey == "" {
+		stored, err := tokenStore.Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tokens from %s: %w", tokenStore.Name(), err)
+		}
+		if stored == nil || stored.AccessToken == "" {
+			return nil, fmt.Errorf("WHOOP_ACCESS_TOKEN or WHOOP_API_KEY environment variable is required (or tokens in %s)", tokenStore.Name())
+		}
+		apiKey = stored.AccessToken
+		grantedScope = stored.Scope
+		expiresAt = stored.ExpiresAt
+		if refreshToken == "" {
+			refreshToken = stored.RefreshToken
+		}
+	}
+
+	baseURL := strings.TrimRight(strings.TrimSpace(settings("WHOOP_API_BASE_URL")), "/")
+	if baseURL == "" {
+		baseURL = WhoopAPIBaseURL
+	}
+
+	clientID := settings("WHOOP_CLIENT_ID")
+	clientSecret := settings("WHOOP_CLIENT_SECRET")
+
+	// Optional: additional consenting accounts queried via user_id
+	var accounts *CredentialStore
+	if accountsFile := settings("WHOOP_ACCOUNTS_FILE"); accountsFile != "" {
+		store, err := LoadCredentialStore(accountsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load WHOOP_ACCOUNTS_FILE: %w", err)
+		}
+		accounts = store
+		log.Printf("Loaded %d additional Whoop account(s) from %s", store.Len(), accountsFile)
+	}
+
+	timezone, err := NewUserTimezoneFromEnv(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	perMinute, timeout, err := clientLimitsFromEnv(settings)
+	if err != nil {
+		return nil, err
+	}
+	// Allow short bursts of up to 10 requests without exceeding the per-minute rate
+	rateLimiter := rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMinute)), min(perMinute, 10))
+	log.Printf("Whoop API limits: %d requests/minute, %s request timeout", perMinute, timeout)
+
+	scopes := newGrantedScopes()
+	scopes.SetFromTokenResponse(grantedScope)
+
+	tokens := newTokenHolder(apiKey, refreshToken)
+	tokens.SetExpiry(expiresAt)
+
+	httpClient := newHTTPClient(timeout)
+	if fixtures != nil {
+		httpClient.Transport = &fixtureTransport{fixtures: fixtures, baseURL: baseURL}
+		rateLimiter = rate.NewLimiter(rate.Inf, 1)
+		log.Printf("Offline mode: serving %s fixtures instead of the Whoop API", fixtures.Source)
+	}
+	if cassetteMode != "" {
+		transport, err := newCassetteTransport(cassetteMode, cassettePath, baseURL, httpClient.Transport)
+		if err != nil {
+			return nil, err
+		}
+		httpClient.Transport = transport
+		if cassetteMode == cassetteReplay {
+			rateLimiter = rate.NewLimiter(rate.Inf, 1)
+		}
+		log.Printf("Cassette %s mode: Whoop API traffic goes through %s", cassetteMode, cassettePath)
+	}
+
+	return &WhoopClient{
+		client:       httpClient,
+		rateLimiter:  rateLimiter,
+		tokens:       tokens,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		baseURL:      baseURL,
+		accounts:     accounts,
+		sports:       NewSportsCatalog(),
+		timezone:     timezone,
+		meter:        newRequestMeter(),
+		tokenStore:   tokenStore,
+		scopes:       scopes,
+		fixtures:     fixtures,
+		strictDecode: strictDecode,
+	}, nil
+}
+
+// strictDecodeFromEnv reads WHOOP_STRICT_DECODE, which rejects responses
+// carrying fields the models do not know
+func strictDecodeFromEnv(settings Settings) (bool, error) {
+	raw := strings.TrimSpace(settings("WHOOP_STRICT_DECODE"))
+	if raw == "" {
+		return false, nil
+	}
+	strict, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid WHOOP_STRICT_DECODE %q: expected 1, true, 0, or false", raw)
+	}
+	return strict, nil
+}
+
+// clientLimitsFromEnv reads WHOOP_RATE_LIMIT (requests per minute, default
+// 100) and WHOOP_REQUEST_TIMEOUT (seconds, default 30)
+func clientLimitsFromEnv(settings Settings) (int, time.Duration, error) {
+	perMinute, err := intSettingFromEnv(settings, "WHOOP_RATE_LIMIT", 100, 1, 1000)
+	if err != nil {
+		return 0, 0, err
+	}
+	seconds, err := intSettingFromEnv(settings, "WHOOP_REQUEST_TIMEOUT", 30, 1, 600)
+	if err != nil {
+		return 0, 0, err
+	}
+	return perMinute, time.Duration(seconds) * time.Second, nil
+}
+
+// intSettingFromEnv parses a whole-number variable within [low, high], using
+// fallback when it is unset
+func intSettingFromEnv(settings Settings, name string, fallback, low, high int) (int, error) {
+	raw := strings.TrimSpace(settings(name))
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < low || value > high {
+		return 0, fmt.Errorf("invalid %s %q (expected %d-%d)", name, raw, low, high)
+	}
+	return value, nil
+}
+
+// newHTTPClient builds an HTTP client tuned for paginated Whoop API fetches.
+// Idle connections are kept alive and pooled per host so consecutive pages
+// reuse the same TLS (and HTTP/2) connection instead of dialing a new one.
+// Compression is left enabled so the transport negotiates gzip and
+// transparently decompresses response bodies.
+func newHTTPClient(timeout time.Duration) *http.Client {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          20,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		DisableCompression:    false,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+}
+
+// resolveAccount picks the credentials to use for a user-scoped request.
+// A nil result means the default (environment) token should be used, which
+// is only the case without a user_id or for the default token's own user.
+func (w *WhoopClient) resolveAccount(ctx context.Context, userID *int) (*AccountCredentials, error) {
+	if userID == nil || *userID == 0 {
+		return nil, nil
+	}
+
+	if w.accounts != nil {
+		if account, ok := w.accounts.Lookup(*userID); ok {
+			return &account, nil
+		}
+	}
+
+	// The default token may be requested explicitly by its own user ID
+	defaultUserID := int(w.defaultUserID.Load())
+	if defaultUserID == 0 {
+		user, err := w.GetUser(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check credentials for user_id %d: %w", *userID, err)
+		}
+		defaultUserID = user.UserID
+	}
+	if *userID == defaultUserID {
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("no credentials for user_id %d", *userID)
+}
+
+// makeRequest performs an HTTP request to the Whoop API on behalf of userID
+func (w *WhoopClient) makeRequest(ctx context.Context, endpoint string, params url.Values, userID *int) ([]byte, error) {
+	account, err := w.resolveAccount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fail fast while waiting for re-authorization instead of burning quota on 401s
+	if account == nil {
+		if authErr := w.tokens.AuthRequired(); authErr != nil {
+			return nil, authErr
+		}
+	}
+
+	// Fail fast with a precise message when the default token lacks the scope
+	scope := requiredScope(endpoint)
+	if account == nil && scope != "" {
+		if err := w.scopes.Check(scope, endpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	// Wait for rate limiter
+	waitStarted := time.Now()
+	if err := w.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limiter error: %w", err)
+	}
+	if time.Since(waitStarted) >= time.Millisecond {
+		recordSpan(ctx, "whoop rate limit wait", waitStarted)
+	}
+
+	fullURL := w.baseURL + endpoint
+	if len(params) > 0 {
+		fullURL += "?" + params.Encode()
+	}
+
+	token := w.tokens.Access()
+	if account != nil {
+		token = account.AccessToken
+	}
+
+	// Try the request
+	body, statusCode, err := w.doRequest(ctx, fullURL, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// If unauthorized and we have refresh capabilities, try to refresh token
+	if statusCode == 401 {
+		refreshed := false
+
+		if account != nil && account.RefreshToken != "" && w.hasClientCredentials() {
+			newToken, err := w.refreshAccountToken(account.UserID, token)
+			if err != nil {
+				return nil, &AuthRequiredError{Reason: fmt.Sprintf("token refresh for user %d failed: %v", account.UserID, err)}
+			}
+
+			token = newToken
+			refreshed = true
+		} else if account == nil && w.CanRefreshToken() {
+			newToken, err := w.refreshAccessToken(token)
+			if err != nil {
+				var authErr *AuthRequiredError
+				if errors.As(err, &authErr) {
+					return nil, authErr
+				}
+				return nil, w.requireAuth(fmt.Sprintf("token refresh failed: %v", err))
+			}
+
+			token = newToken
+			refreshed = true
+		}
+
+		if refreshed {
+			log.Printf("Successfully refreshed access token")
+
+			// Retry the original request with new token
+			body, statusCode, err = w.doRequest(ctx, fullURL, token)
+			if err != nil {
+				return nil, err
+			}
+
+			if statusCode == 401 {
+				if account != nil {
+					return nil, &AuthRequiredError{Reason: fmt.Sprintf("access token for user %d rejected even after refresh", account.UserID)}
+				}
+				return nil, w.requireAuth("access token rejected even after refresh")
+			}
+		} else if account == nil {
+			return nil, w.requireAuth("access token rejected and no refresh token or OAuth client credentials are configured")
+		} else {
+			return nil, &AuthRequiredError{Reason: fmt.Sprintf("access token for user %d rejected and cannot be refreshed", account.UserID)}
+		}
+	}
+
+	if account == nil && scope != "" && (statusCode == http.StatusOK || statusCode == http.StatusForbidden) {
+		w.scopes.Record(scope, statusCode == http.StatusOK)
+	}
+	if statusCode == http.StatusForbidden && scope != "" {
+		return nil, &MissingScopeError{Scope: scope, Endpoint: endpoint}
+	}
+
+	if statusCode != 200 {
+		return nil, fmt.Errorf("API request failed with status %d: %s", statusCode, string(body))
+	}
+
+	return body, nil
+}
+
+// requireAuth moves the client into the degraded "needs auth" state
+func (w *WhoopClient) requireAuth(reason string) *AuthRequiredError {
+	log.Printf("Whoop credentials unusable, entering needs-auth state: %s", reason)
+	return w.tokens.RequireAuth(reason)
+}
+
+// AuthRequired returns the pending re-authorization error, or nil when healthy
+func (w *WhoopClient) AuthRequired() *AuthRequiredError {
+	return w.tokens.AuthRequired()
+}
+
+// TokenStore returns the backend used to persist default tokens
+func (w *WhoopClient) TokenStore() TokenStore {
+	return w.tokenStore
+}
+
+// ClientID returns the configured OAuth client ID (may be empty)
+func (w *WhoopClient) ClientID() string {
+	return w.clientID
+}
+
+// SetTokens installs freshly obtained default tokens and leaves the needs-auth state
+func (w *WhoopClient) SetTokens(accessToken, refreshToken string) {
+	w.tokens.Set(accessToken, refreshToken)
+	w.tokens.SetExpiry(nil)
+	// A new grant may carry different scopes, or belong to another user
+	w.scopes.Reset()
+	w.profile.Store(nil)
+	w.tokenGeneration.Add(1)
+}
+
+// TokenGeneration changes whenever a token is installed or rotated, for
+// callers holding results fetched with the earlier tokens
+func (w *WhoopClient) TokenGeneration() uint64 {
+	return w.tokenGeneration.Load()
+}
+
+// TokenExpiry returns when the default access token expires, or nil when unknown
+func (w *WhoopClient) TokenExpiry() *time.Time {
+	return w.tokens.Expiry()
+}
+
+// SetGrantedScopes records the scope list reported alongside new tokens
+func (w *WhoopClient) SetGrantedScopes(scope string) {
+	w.scopes.SetFromTokenResponse(scope)
+	logScopeWarnings(w.scopes.Status())
+}
+
+// SetReadOnly stops refreshed tokens from being written to the token store or
+// accounts file; they stay valid in memory until the process exits
+func (w *WhoopClient) SetReadOnly(readOnly bool) {
+	w.readOnly.Store(readOnly)
+	if w.accounts != nil {
+		w.accounts.SetReadOnly(readOnly)
+	}
+}
+
+// InstallTokens activates tokens from a completed OAuth exchange and persists
+// them to the token store
+func (w *WhoopClient) InstallTokens(tokens *OAuthTokenResponse) error {
+	w.SetTokens(tokens.AccessToken, tokens.RefreshToken)
+	w.SetGrantedScopes(tokens.Scope)
+	w.tokens.SetExpiry(expiryFrom(tokens.ExpiresIn, time.Now()))
+
+	if w.tokenStore == nil {
+		return nil
+	}
+	return saveOAuthTokens(w.tokenStore, tokens)
+}
+
+// RateLimitStatus reports limiter state alongside observed request volume
+func (w *WhoopClient) RateLimitStatus() RateLimitStatus {
+	status := w.meter.Snapshot()
+	status.LimiterRatePerMinute = float64(w.rateLimiter.Limit()) * 60
+	status.LimiterBurst = w.rateLimiter.Burst()
+	status.LimiterTokens = w.rateLimiter.Tokens()
+	return status
+}
+
+// Sports returns the cached sport catalog
+func (w *WhoopClient) Sports() *SportsCatalog {
+	return w.sports
+}
+
+// Timezone returns the user's timezone resolver
+func (w *WhoopClient) Timezone() *UserTimezone {
+	return w.timezone
+}
+
+// Accounts returns the configured multi-account store (nil when not configured)
+func (w *WhoopClient) Accounts() *CredentialStore {
+	return w.accounts
+}
+
+// FixtureSource names the fixtures served in offline mode, or is empty when
+// requests go to the Whoop API
+func (w *WhoopClient) FixtureSource() string {
+	if w.fixtures == nil {
+		return ""
+	}
+	return w.fixtures.Source
+}
+
+// handleAPIError processes API error responses and returns user-friendly errors
+func (w *WhoopClient) handleAPIError(statusCode int, body []byte) error {
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return fmt.Errorf("authentication failed: invalid API key")
+	case http.StatusForbidden:
+		return fmt.Errorf("access denied: insufficient permissions")
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("rate limit exceeded: too many requests")
+	case http.StatusBadRequest:
+		return fmt.Errorf("bad request: check your parameters")
+	case http.StatusNotFound:
+		return fmt.Errorf("resource not found")
+	case http.StatusInternalServerError:
+		return fmt.Errorf("Whoop API internal error")
+	case http.StatusServiceUnavailable:
+		return fmt.Errorf("Whoop API temporarily unavailable")
+	default:
+		return fmt.Errorf("API error (status %d): %s", statusCode, string(body))
+	}
+}
+
+// GetUser returns the authenticated user's profile. It is fetched once and
+// cached until new tokens are installed; refreshes of the same grant keep it.
+func (w *WhoopClient) GetUser(ctx context.Context) (*WhoopUser, error) {
+	if cached := w.profile.Load(); cached != nil {
+		user := *cached
+		return &user, nil
+	}
+	return w.fetchUser(ctx)
+}
+
+// fetchUser requests the profile from the API and caches it
+func (w *WhoopClient) fetchUser(ctx context.Context) (*WhoopUser, error) {
+	body, err := w.makeRequest(ctx, "/v2/user/profile/basic", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	var user WhoopUser
+	if err := whoop.Decode(body, &user, "user profile", w.strictDecode); err != nil {
+		return nil, err
+	}
+
+	w.defaultUserID.Store(int64(user.UserID))
+	cached := user
+	w.profile.Store(&cached)
+
+	return &user, nil
+}
+
+// GetRecoveryData retrieves recovery data for a date range
+func (w *WhoopClient) GetRecoveryData(ctx context.Context, startDate, endDate time.Time, userID *int) ([]WhoopRecovery, error) {
+	return fetchAll[WhoopRecovery](ctx, w, "recovery", "/v2/recovery", startDate, endDate, userID)
+}
+
+// EachRecoveryPage passes each page of recoveries in a date range to page,
+// newest first, without keeping them
+func (w *WhoopClient) EachRecoveryPage(ctx context.Context, startDate, endDate time.Time, userID *int, page func([]WhoopRecovery) error) error {
+	return fetchPages(ctx, w, "recovery", "/v2/recovery", startDate, endDate, userID, page)
+}
+
+// SleepFilter selects which kinds of sleep records GetSleepDataFiltered returns
+type SleepFilter int
+
+const (
+	// SleepAll returns both main sleeps and naps
+	SleepAll SleepFilter = iota
+	// SleepMainOnly excludes naps
+	SleepMainOnly
+	// SleepNapsOnly returns only naps
+	SleepNapsOnly
+)
+
+// GetSleepData retrieves sleep data (main sleeps and naps) for a date range
+func (w *WhoopClient) GetSleepData(ctx context.Context, startDate, endDate time.Time, userID *int) ([]WhoopSleep, error) {
+	return w.GetSleepDataFiltered(ctx, startDate, endDate, userID, SleepAll)
+}
+
+// GetSleepDataFiltered retrieves sleep data for a date range, keeping only the
+// records selected by filter
+func (w *WhoopClient) GetSleepDataFiltered(ctx context.Context, startDate, endDate time.Time, userID *int, filter SleepFilter) ([]WhoopSleep, error) {
+	account, err := w.resolveAccount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sleeps, err := fetchAll[WhoopSleep](ctx, w, "sleep", "/v2/activity/sleep", startDate, endDate, userID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		w.timezone.Learn(sleeps)
+	}
+
+	var allSleeps []WhoopSleep
+	for _, sleep := range sleeps {
+		if filter == SleepMainOnly && sleep.Nap {
+			continue
+		}
+		if filter == SleepNapsOnly && !sleep.Nap {
+			continue
+		}
+		allSleeps = append(allSleeps, sleep)
+	}
+	return allSleeps, nil
+}
+
+// EachSleepPage passes each page of sleeps (main sleeps and naps) in a date
+// range to page, newest first, without keeping them. Only the default
+// account's sleeps teach the timezone, since it is the one date arguments
+// are read in.
+func (w *WhoopClient) EachSleepPage(ctx context.Context, startDate, endDate time.Time, userID *int, page func([]WhoopSleep) error) error {
+	account, err := w.resolveAccount(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return fetchPages(ctx, w, "sleep", "/v2/activity/sleep", startDate, endDate, userID, func(sleeps []WhoopSleep) error {
+		if account == nil {
+			w.timezone.Learn(sleeps)
+		}
+		return page(sleeps)
+	})
+}
+
+// GetWorkoutData retrieves workout data for a date range
+func (w *WhoopClient) GetWorkoutData(ctx context.Context, startDate, endDate time.Time, userID *int) ([]WhoopWorkout, error) {
+	workouts, err := fetchAll[WhoopWorkout](ctx, w, "workout", "/v2/activity/workout", startDate, endDate, userID)
+	if err != nil {
+		return nil, err
+	}
+	w.sports.Learn(workouts)
+	return workouts, nil
+}
+
+// EachWorkoutPage passes each page of workouts in a date range to page,
+// newest first, without keeping them
+func (w *WhoopClient) EachWorkoutPage(ctx context.Context, startDate, endDate time.Time, userID *int, page func([]WhoopWorkout) error) error {
+	return fetchPages(ctx, w, "workout", "/v2/activity/workout", startDate, endDate, userID, func(workouts []WhoopWorkout) error {
+		w.sports.Learn(workouts)
+		return page(workouts)
+	})
+}
+
+// GetCycleData retrieves physiological cycle data for a date range
+func (w *WhoopClient) GetCycleData(ctx context.Context, startDate, endDate time.Time, userID *int) ([]WhoopCycle, error) {
+	return fetchAll[WhoopCycle](ctx, w, "cycle", "/v2/cycle", startDate, endDate, userID)
+}
+
+// EachCyclePage passes each page of cycles in a date range to page, newest
+// first, without keeping them
+func (w *WhoopClient) EachCyclePage(ctx context.Context, startDate, endDate time.Time, userID *int, page func([]WhoopCycle) error) error {
+	return fetchPages(ctx, w, "cycle", "/v2/cycle", startDate, endDate, userID, page)
+}
+
+// pageDedup removes records repeated across pages while they are streamed.
+// Repeats appear when Whoop adds or rescores records while a range is paged
+// through, shifting the records after them onto later pages. Each page is
+// held back until the next one arrives; of two copies, the one with the
+// latest updated_at is passed on, in the first copy's place. A copy arriving
+// after its record was passed on, which takes a shift of more than a page,
+// can no longer replace it and is dropped; fetchAll, which keeps every
+// record, replaces it instead.
+type pageDedup[T whoop.Record] struct {
+	held    []T
+	index   map[string]int  // position in held, by ID
+	passed  map[string]bool // IDs already passed on
+	started bool            // a page is held
+}
+
+func newPageDedup[T whoop.Record]() *pageDedup[T] {
+	return &pageDedup[T]{passed: make(map[string]bool)}
+}
+
+// push adds the next page, returning the page it releases, if any
+func (d *pageDedup[T]) push(page []T) (released []T, ok bool) {
+	released, releasedIndex, ok := d.held, d.index, d.started
+	d.held, d.index, d.started = make([]T, 0, len(page)), make(map[string]int), true
+
+	for _, record := range page {
+		id := record.RecordID()
+		if i, repeat := releasedIndex[id]; repeat {
+			if record.LastUpdated().After(released[i].LastUpdated()) {
+				released[i] = record
+			}
+			continue
+		}
+		if d.passed[id] {
+			continue
+		}
+		if i, repeat := d.index[id]; repeat {
+			if record.LastUpdated().After(d.held[i].LastUpdated()) {
+				d.held[i] = record
+			}
+			continue
+		}
+		d.index[id] = len(d.held)
+		d.held = append(d.held, record)
+	}
+
+	for id := range releasedIndex {
+		d.passed[id] = true
+	}
+	return released, ok
+}
+
+// flush releases the last page, if any
+func (d *pageDedup[T]) flush() (released []T, ok bool) {
+	released, ok = d.held, d.started
+	d.held, d.index, d.started = nil, nil, false
+	return released, ok
+}
+
+// fetchPages walks the pages of a collection endpoint over a date range,
+// passing each page's records to page once repeats are removed (see
+// pageDedup). An error from page stops the walk.
+func fetchPages[T whoop.Record](ctx context.Context, w *WhoopClient, collection, endpoint string, startDate, endDate time.Time, userID *int, page func([]T) error) error {
+	dedup := newPageDedup[T]()
+	err := walkPages(ctx, w, collection, endpoint, startDate, endDate, userID, func(records []T) error {
+		if released, ok := dedup.push(records); ok {
+			return page(released)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if last, ok := dedup.flush(); ok {
+		return page(last)
+	}
+	return nil
+}
+
+// fetchAll collects every record of a collection endpoint over a date range.
+// A record repeated anywhere in the walk is kept once, in its first copy's
+// place, as the copy with the latest updated_at.
+func fetchAll[T whoop.Record](ctx context.Context, w *WhoopClient, collection, endpoint string, startDate, endDate time.Time, userID *int) ([]T, error) {
+	var all []T
+	index := make(map[string]int) // position in all, by ID
+	err := walkPages(ctx, w, collection, endpoint, startDate, endDate, userID, func(records []T) error {
+		for _, record := range records {
+			id := record.RecordID()
+			if i, repeat := index[id]; repeat {
+				if record.LastUpdated().After(all[i].LastUpdated()) {
+					all[i] = record
+				}
+				continue
+			}
+			index[id] = len(all)
+			all = append(all, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// walkPages requests each page of a collection endpoint over a date range in
+// turn and passes its records, repeats and all, to page. An error from page
+// stops the walk.
+func walkPages[T whoop.Record](ctx context.Context, w *WhoopClient, collection, endpoint string, startDate, endDate time.Time, userID *int, page func([]T) error) (err error) {
+	params := url.Values{}
+	params.Set("start", startDate.Format(time.RFC3339))
+	params.Set("end", endDate.Format(time.RFC3339))
+	params.Set("limit", strconv.Itoa(apiPageSize)) // Maximum per request
+
+	ctx, span := startSpan(ctx, "whoop "+collection, spanInternal)
+	pages, records := 0, 0
+	defer func() { endFetchSpan(span, pages, records, err) }()
+
+	nextToken := ""
+	for {
+		if nextToken != "" {
+			params.Set("nextToken", nextToken)
+		}
+
+		body, err := w.makeRequest(ctx, endpoint, params, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get %s data: %w", collection, err)
+		}
+		pages++
+
+		var response whoop.Page[T]
+		if err := whoop.Decode(body, &response, collection+" data", w.strictDecode); err != nil {
+			return err
+		}
+		records += len(response.Data)
+		if err := page(response.Data); err != nil {
+			return err
+		}
+
+		// Check if there are more pages
+		if response.NextToken == nil || *response.NextToken == "" {
+			break
+		}
+		nextToken = *response.NextToken
+	}
+	return nil
+}
+
+// endFetchSpan finishes the span of a paginated collection fetch
+func endFetchSpan(span *Span, pages, records int, err error) {
+	span.SetAttribute("whoop.pages", pages)
+	span.SetAttribute("whoop.records", records)
+	span.End(err)
+}
+
+// doRequest performs the actual HTTP request with the given bearer token
+func (w *WhoopClient) doRequest(ctx context.Context, fullURL, token string) (body []byte, statusCode int, err error) {
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	_, span := startSpan(ctx, "GET "+req.URL.Path, spanClient)
+	span.SetAttribute("http.request.method", "GET")
+	span.SetAttribute("server.address", req.URL.Hostname())
+	span.SetAttribute("url.path", req.URL.Path)
+	defer func() {
+		if statusCode != 0 {
+			span.SetAttribute("http.response.status_code", statusCode)
+		}
+		if err == nil && statusCode >= 400 {
+			span.End(fmt.Errorf("HTTP %d", statusCode))
+			return
+		}
+		span.End(err)
+	}()
+
+	// Add authentication header
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Whoop-MCP-Server/1.0")
+
+	// Execute request
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	w.meter.Record(resp.StatusCode, resp.Header)
+
+	// Read response body
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return body, resp.StatusCode, nil
+}
+
+// CanRefreshToken checks if we have the necessary credentials for token refresh
+func (w *WhoopClient) CanRefreshToken() bool {
+	return w.tokens.Refresh() != "" && w.hasClientCredentials()
+}
+
+// hasClientCredentials reports whether the OAuth app credentials are configured
+func (w *WhoopClient) hasClientCredentials() bool {
+	return w.clientID != "" && w.clientSecret != ""
+}
+
+// tokenRefreshResponse is the OAuth token endpoint response for a refresh grant
+type tokenRefreshResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// refreshAccessToken rotates the default token after stale was rejected. Only
+// one goroutine refreshes at a time; concurrent callers reuse its result.
+func (w *WhoopClient) refreshAccessToken(stale string) (string, error) {
+	return w.tokens.Rotate(stale, func(refreshToken string) (string, string, error) {
+		log.Printf("Access token expired, attempting to refresh...")
+
+		tokenResp, err := w.requestTokenRefresh(refreshToken)
+		if err != nil {
+			return "", "", err
+		}
+
+		// Keep the current refresh token unless a new one was provided
+		newRefreshToken := refreshToken
+		if tokenResp.RefreshToken != "" {
+			newRefreshToken = tokenResp.RefreshToken
+		}
+
+		// Persist the rotated tokens (best-effort - don't fail the request if we can't)
+		w.scopes.SetFromTokenResponse(tokenResp.Scope)
+		w.tokens.SetExpiry(expiryFrom(tokenResp.ExpiresIn, time.Now()))
+		w.persistTokens(tokenResp.AccessToken, newRefreshToken, tokenResp.ExpiresIn)
+		w.tokenGeneration.Add(1)
+
+		return tokenResp.AccessToken, newRefreshToken, nil
+	})
+}
+
+// refreshAccountToken rotates a multi-account token after stale was rejected,
+// skipping the refresh if another goroutine already rotated it
+func (w *WhoopClient) refreshAccountToken(userID int, stale string) (string, error) {
+	w.accountRefreshMu.Lock()
+	defer w.accountRefreshMu.Unlock()
+
+	account, ok := w.accounts.Lookup(userID)
+	if !ok {
+		return "", fmt.Errorf("no credentials configured for user %d", userID)
+	}
+	if account.AccessToken != stale {
+		return account.AccessToken, nil
+	}
+
+	log.Printf("Access token for user %d expired, attempting to refresh...", userID)
+
+	tokens, err := w.requestTokenRefresh(account.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+	if err := w.accounts.UpdateTokens(userID, tokens.AccessToken, tokens.RefreshToken); err != nil {
+		log.Printf("Warning: Could not persist refreshed tokens for user %d: %v", userID, err)
+	}
+	w.tokenGeneration.Add(1)
+
+	return tokens.AccessToken, nil
+}
+
+// persistTokens saves the default tokens to the configured token store
+func (w *WhoopClient) persistTokens(accessToken, refreshToken string, expiresIn int) {
+	if w.tokenStore == nil || w.readOnly.Load() {
+		return
+	}
+
+	tokens := TokenSet{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		UpdatedAt:    time.Now(),
+	}
+	if status := w.scopes.Status(); status.Source == "token_response" {
+		tokens.Scope = strings.Join(status.Granted, " ")
+	}
+	if expiresIn > 0 {
+		expiresAt := tokens.UpdatedAt.Add(time.Duration(expiresIn) * time.Second)
+		tokens.ExpiresAt = &expiresAt
+	}
+
+	if err := w.tokenStore.Save(tokens); err != nil {
+		log.Printf("Warning: Could not persist tokens to %s: %v", w.tokenStore.Name(), err)
+	} else {
+		log.Printf("Persisted refreshed tokens to %s", w.tokenStore.Name())
+	}
+}
+
+// expiryFrom converts a token lifetime in seconds to an expiry time; nil when
+// the lifetime was not reported
+func expiryFrom(expiresIn int, now time.Time) *time.Time {
+	if expiresIn <= 0 {
+		return nil
+	}
+	expiresAt := now.Add(time.Duration(expiresIn) * time.Second)
+	return &expiresAt
+}
+
+// requestTokenRefresh exchanges a refresh token for a new token pair
+func (w *WhoopClient) requestTokenRefresh(refreshToken string) (*tokenRefreshResponse, error) {
+	data := url.Values{}
+	data.Set("grant_type", "refresh_token")
+	data.Set("refresh_token", refreshToken)
+	data.Set("client_id", w.clientID)
+	data.Set("client_secret", w.clientSecret)
+	data.Set("scope", "offline")
+
+	req, err := http.NewRequest("POST", WhoopTokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refresh request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make refresh request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read refresh response: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("token refresh failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResp tokenRefreshResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to parse refresh response: %w", err)
+	}
+
+	return &tokenResp, nil
+}
+
+// ValidateConnection tests the API connection and authentication
+func (w *WhoopClient) ValidateConnection() error {
+	// Always reach the API: a cached profile says nothing about the tokens
+	_, err := w.fetchUser(context.Background())
+	if err != nil {
+		return fmt.Errorf("API connection validation failed: %w", err)
+	}
+	return nil
+}
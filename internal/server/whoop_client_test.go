@@ -0,0 +1,273 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWhoopClient_GetUserCached(t *testing.T) {
+	clearConfigEnv(t)
+	var hits atomic.Int64
+	api := fixtureAPI(t, &hits)
+	t.Setenv("WHOOP_API_BASE_URL", api.URL)
+	t.Setenv("WHOOP_RATE_LIMIT", "1000")
+	t.Setenv("WHOOP_ACCESS_TOKEN", "token")
+
+	client, err := NewWhoopClient(os.Getenv)
+	if err != nil {
+		t.Fatalf("NewWhoopClient() error = %v", err)
+	}
+	first, err := client.GetUser(context.Background())
+	if err != nil {
+		t.Fatalf("GetUser() error = %v", err)
+	}
+	first.FirstName = "changed by a caller"
+	second, err := client.GetUser(context.Background())
+	if err != nil || hits.Load() != 1 {
+		t.Fatalf("second GetUser() = %v after %d requests, want the cached profile", err, hits.Load())
+	}
+	if second.FirstName == first.FirstName {
+		t.Error("Expected callers to get their own copy of the cached profile")
+	}
+
+	if err := client.ValidateConnection(); err != nil || hits.Load() != 2 {
+		t.Errorf("ValidateConnection() = %v after %d requests, want it to reach the API", err, hits.Load())
+	}
+
+	client.SetTokens("new-token", "")
+	if _, err := client.GetUser(context.Background()); err != nil || hits.Load() != 3 {
+		t.Errorf("GetUser() after new tokens = %v after %d requests, want a fresh fetch", err, hits.Load())
+	}
+}
+
+func TestWhoopClient_EachRecoveryPage(t *testing.T) {
+	clearConfigEnv(t)
+	var hits atomic.Int64
+	api := fixtureAPI(t, &hits)
+	t.Setenv("WHOOP_API_BASE_URL", api.URL)
+	t.Setenv("WHOOP_RATE_LIMIT", "1000")
+	t.Setenv("WHOOP_ACCESS_TOKEN", "token")
+
+	client, err := NewWhoopClient(os.Getenv)
+	if err != nil {
+		t.Fatalf("NewWhoopClient() error = %v", err)
+	}
+	start, end := time.Now().AddDate(0, 0, -60), time.Now()
+	recoveries, err := client.GetRecoveryData(context.Background(), start, end, nil)
+	if err != nil {
+		t.Fatalf("GetRecoveryData() error = %v", err)
+	}
+	fetched := hits.Load()
+	if fetched < 2 {
+		t.Fatalf("fixtures served %d page(s), want several", fetched)
+	}
+
+	pages, records := 0, 0
+	err = client.EachRecoveryPage(context.Background(), start, end, nil, func(page []WhoopRecovery) error {
+		pages++
+		records += len(page)
+		return nil
+	})
+	if err != nil || int64(pages) != fetched || records != len(recoveries) {
+		t.Errorf("EachRecoveryPage() = %v over %d pages and %d records, want %d and %d", err, pages, records, fetched, len(recoveries))
+	}
+
+	// An error from page stops the walk. Each page is held until the next
+	// arrives, to settle repeats, so the first callback follows two requests.
+	stop := errors.New("enough")
+	hits.Store(0)
+	if err := client.EachRecoveryPage(context.Background(), start, end, nil, func([]WhoopRecovery) error { return stop }); err != stop || hits.Load() != 2 {
+		t.Errorf("EachRecoveryPage() = %v after %d requests, want the page error after two", err, hits.Load())
+	}
+}
+
+func TestWhoopClient_DedupesAcrossPages(t *testing.T) {
+	// A workout rescored mid-pagination is served again on the second page
+	first, rescored := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC), time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	pages := map[string]string{
+		"": `{"records": [
+			{"id": "a", "updated_at": "2024-05-01T08:00:00Z", "sport_name": "running"},
+			{"id": "b", "updated_at": "2024-05-01T08:00:00Z", "sport_name": "running"}
+		], "next_token": "page2"}`,
+		"page2": `{"records": [
+			{"id": "b", "updated_at": "2024-05-01T09:00:00Z", "sport_name": "cycling"},
+			{"id": "c", "updated_at": "2024-05-01T08:00:00Z", "sport_name": "running"}
+		]}`,
+	}
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, pages[r.URL.Query().Get("nextToken")])
+	}))
+	t.Cleanup(api.Close)
+	clearConfigEnv(t)
+	t.Setenv("WHOOP_API_BASE_URL", api.URL)
+	t.Setenv("WHOOP_RATE_LIMIT", "1000")
+	t.Setenv("WHOOP_ACCESS_TOKEN", "token")
+	client, err := NewWhoopClient(os.Getenv)
+	if err != nil {
+		t.Fatalf("NewWhoopClient() error = %v", err)
+	}
+
+	workouts, err := client.GetWorkoutData(context.Background(), first.AddDate(0, 0, -7), first, nil)
+	if err != nil {
+		t.Fatalf("GetWorkoutData() error = %v", err)
+	}
+	var ids []string
+	for _, workout := range workouts {
+		ids = append(ids, workout.ID)
+	}
+	if strings.Join(ids, ",") != "a,b,c" || !workouts[1].UpdatedAt.Equal(rescored) || workouts[1].SportName != "cycling" {
+		t.Errorf("workouts = %+v, want a, the rescored b, and c", workouts)
+	}
+
+	// Streaming consumers get the same records, the rescored copy included
+	var streamed []WhoopWorkout
+	err = client.EachWorkoutPage(context.Background(), first.AddDate(0, 0, -7), first, nil, func(page []WhoopWorkout) error {
+		streamed = append(streamed, page...)
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(streamed, workouts) {
+		t.Errorf("EachWorkoutPage() streamed %+v (error %v), want %+v", streamed, err, workouts)
+	}
+}
+
+func TestWhoopClient_DedupesAcrossWalk(t *testing.T) {
+	// Workout b is rescored while two pages are fetched, so its newer copy
+	// turns up two pages after the first
+	rescored := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	pages := map[string]string{
+		"": `{"records": [
+			{"id": "a", "updated_at": "2024-05-01T08:00:00Z", "sport_name": "running"},
+			{"id": "b", "updated_at": "2024-05-01T08:00:00Z", "sport_name": "running"}
+		], "next_token": "page2"}`,
+		"page2": `{"records": [
+			{"id": "c", "updated_at": "2024-05-01T08:00:00Z", "sport_name": "running"}
+		], "next_token": "page3"}`,
+		"page3": `{"records": [
+			{"id": "b", "updated_at": "2024-05-01T09:00:00Z", "sport_name": "cycling"},
+			{"id": "a", "updated_at": "2024-05-01T07:00:00Z", "sport_name": "walking"},
+			{"id": "d", "updated_at": "2024-05-01T08:00:00Z", "sport_name": "running"}
+		]}`,
+	}
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, pages[r.URL.Query().Get("nextToken")])
+	}))
+	t.Cleanup(api.Close)
+	clearConfigEnv(t)
+	t.Setenv("WHOOP_API_BASE_URL", api.URL)
+	t.Setenv("WHOOP_RATE_LIMIT", "1000")
+	t.Setenv("WHOOP_ACCESS_TOKEN", "token")
+	client, err := NewWhoopClient(os.Getenv)
+	if err != nil {
+		t.Fatalf("NewWhoopClient() error = %v", err)
+	}
+
+	end := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	workouts, err := client.GetWorkoutData(context.Background(), end.AddDate(0, 0, -7), end, nil)
+	if err != nil {
+		t.Fatalf("GetWorkoutData() error = %v", err)
+	}
+	var ids []string
+	for _, workout := range workouts {
+		ids = append(ids, workout.ID+"="+workout.SportName)
+	}
+	if strings.Join(ids, ",") != "a=running,b=cycling,c=running,d=running" || !workouts[1].UpdatedAt.Equal(rescored) {
+		t.Errorf("workouts = %v, want the rescored b in its first place and the stale a ignored", ids)
+	}
+
+	// Streaming consumers have been passed b already, so they keep one copy
+	var streamed []string
+	err = client.EachWorkoutPage(context.Background(), end.AddDate(0, 0, -7), end, nil, func(page []WhoopWorkout) error {
+		for _, workout := range page {
+			streamed = append(streamed, workout.ID)
+		}
+		return nil
+	})
+	if err != nil || strings.Join(streamed, ",") != "a,b,c,d" {
+		t.Errorf("EachWorkoutPage() streamed %v (error %v), want each workout once", streamed, err)
+	}
+}
+
+func TestWhoopClient_LearnsTimezoneFromDefaultAccount(t *testing.T) {
+	// The default account sleeps in Denver, the other account in Tokyo
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset := "-06:00"
+		if r.Header.Get("Authorization") == "Bearer a1" {
+			offset = "+09:00"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"records": [{"id": "s%s", "end": "2024-05-01T08:00:00Z", "timezone_offset": %q}]}`, offset, offset)
+	}))
+	t.Cleanup(api.Close)
+	accounts := filepath.Join(t.TempDir(), "accounts.json")
+	if err := os.WriteFile(accounts, []byte(`{"accounts":[{"user_id":42,"access_token":"a1"}]}`), 0600); err != nil {
+		t.Fatalf("failed to write accounts file: %v", err)
+	}
+	clearConfigEnv(t)
+	t.Setenv("WHOOP_API_BASE_URL", api.URL)
+	t.Setenv("WHOOP_RATE_LIMIT", "1000")
+	t.Setenv("WHOOP_ACCESS_TOKEN", "token")
+	t.Setenv("WHOOP_ACCOUNTS_FILE", accounts)
+	client, err := NewWhoopClient(os.Getenv)
+	if err != nil {
+		t.Fatalf("NewWhoopClient() error = %v", err)
+	}
+
+	start, end := time.Date(2024, 4, 24, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	other := 42
+	if _, err := client.GetSleepData(context.Background(), start, end, &other); err != nil {
+		t.Fatalf("GetSleepData(42) error = %v", err)
+	}
+	if client.Timezone().Location() != time.Local {
+		t.Errorf("Location() = %v after another account's sleeps, want the host timezone", client.Timezone().Location())
+	}
+	if _, err := client.GetSleepData(context.Background(), start, end, nil); err != nil {
+		t.Fatalf("GetSleepData() error = %v", err)
+	}
+	if _, offset := end.In(client.Timezone().Location()).Zone(); offset != -6*3600 {
+		t.Errorf("Location() offset = %ds, want the default account's -06:00", offset)
+	}
+}
+
+func TestPageDedup(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2024, 5, 1, hour, 0, 0, 0, time.UTC) }
+	cycle := func(id int64, hour int) WhoopCycle { return WhoopCycle{ID: id, UpdatedAt: at(hour)} }
+	describe := func(cycles []WhoopCycle) string {
+		parts := make([]string, len(cycles))
+		for i, c := range cycles {
+			parts[i] = fmt.Sprintf("%d@%d", c.ID, c.UpdatedAt.Hour())
+		}
+		return strings.Join(parts, " ")
+	}
+
+	dedup := newPageDedup[WhoopCycle]()
+	var released []string
+	for _, page := range [][]WhoopCycle{
+		{cycle(1, 8), cycle(2, 8), cycle(2, 9)}, // repeated within a page
+		{cycle(2, 7), cycle(3, 8)},              // an older repeat of a held record
+		{cycle(3, 9), cycle(4, 8)},              // a newer repeat of a held record
+		{cycle(1, 10), cycle(5, 8)},             // too late: 1 was passed on already
+	} {
+		if out, ok := dedup.push(page); ok {
+			released = append(released, describe(out))
+		}
+	}
+	if out, ok := dedup.flush(); ok {
+		released = append(released, describe(out))
+	}
+	want := []string{"1@8 2@9", "3@9", "4@8", "5@8"}
+	if !reflect.DeepEqual(released, want) {
+		t.Errorf("released pages = %q, want %q", released, want)
+	}
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// defaultFetchWorkers is how many Whoop fetches run at once across all
+	// tool calls
+	defaultFetchWorkers = 4
+	// defaultFetchesPerRequest is how many of those one tool call may use
+	defaultFetchesPerRequest = 4
+)
+
+// workerPool bounds the Whoop fetches running at once across every tool call,
+// so concurrent tools queue for a worker instead of all drawing on the rate
+// limiter's burst at the same moment
+type workerPool struct {
+	workers    chan struct{}
+	perRequest int
+}
+
+// NewWorkerPoolFromEnv reads WHOOP_FETCH_WORKERS, the fetches allowed to run
+// at once across all tool calls, and WHOOP_FETCHES_PER_REQUEST, how many of
+// them a single tool call may use
+func NewWorkerPoolFromEnv(settings Settings) (*workerPool, error) {
+	workers, err := intSettingFromEnv(settings, "WHOOP_FETCH_WORKERS", defaultFetchWorkers, 1, 64)
+	if err != nil {
+		return nil, err
+	}
+	perRequest, err := intSettingFromEnv(settings, "WHOOP_FETCHES_PER_REQUEST", defaultFetchesPerRequest, 1, 64)
+	if err != nil {
+		return nil, err
+	}
+	return newWorkerPool(workers, perRequest), nil
+}
+
+func newWorkerPool(workers, perRequest int) *workerPool {
+	return &workerPool{workers: make(chan struct{}, workers), perRequest: perRequest}
+}
+
+// group starts a fetchGroup for one request, returning it with a context
+// that is canceled when a fetch first fails or Wait returns, so the other
+// fetches can stop instead of spending quota on a request that has already
+// failed. A nil pool gives an unbounded group, for servers built without one.
+func (p *workerPool) group(ctx context.Context) (*fetchGroup, context.Context) {
+	group, groupCtx := errgroup.WithContext(ctx)
+	if p != nil {
+		group.SetLimit(p.perRequest)
+	}
+	return &fetchGroup{Group: group, pool: p, parent: ctx, ctx: groupCtx}, groupCtx
+}
+
+// fetchGroup is an errgroup.Group for one request's fetches, limited to the
+// request's share of the pool: Go blocks while the request already has its
+// share running, and each fetch also waits for one of the pool's workers
+type fetchGroup struct {
+	*errgroup.Group
+	pool   *workerPool
+	parent context.Context // the request's context
+	ctx    context.Context // canceled with the group
+}
+
+// Go runs fetch on a worker once the request and the pool both have room.
+// A fetch still waiting for a worker when the group's context is done gives
+// up with its error instead of running.
+func (g *fetchGroup) Go(fetch func() error) {
+	g.Group.Go(func() error {
+		if g.pool != nil {
+			select {
+			case g.pool.workers <- struct{}{}:
+			case <-g.ctx.Done():
+				return g.ctx.Err()
+			}
+			defer func() { <-g.pool.workers }()
+		}
+		return fetch()
+	})
+}
+
+// stopped reports whether err only says a fetch gave up because another one
+// failed first, rather than because the request itself was canceled
+func (g *fetchGroup) stopped(err error) bool {
+	return g.ctx.Err() != nil && g.parent.Err() == nil && errors.Is(err, context.Canceled)
+}
+
+// collectPages returns an Each*Page callback appending each page to records,
+// which stops the fetch once ctx is done
+func collectPages[T any](ctx context.Context, records *[]T) func([]T) error {
+	return func(page []T) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		*records = append(*records, page...)
+		return nil
+	}
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// concurrency records the most calls of a fetch running at once
+type concurrency struct {
+	running, peak atomic.Int64
+}
+
+func (c *concurrency) fetch() error {
+	n := c.running.Add(1)
+	for {
+		peak := c.peak.Load()
+		if n <= peak || c.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	c.running.Add(-1)
+	return nil
+}
+
+func TestWorkerPool_Limits(t *testing.T) {
+	pool := newWorkerPool(3, 2)
+
+	var one concurrency
+	group, _ := pool.group(context.Background())
+	for i := 0; i < 6; i++ {
+		group.Go(one.fetch)
+	}
+	if err := group.Wait(); err != nil || one.peak.Load() != 2 {
+		t.Errorf("one request ran %d fetches at once (error %v), want its limit of 2", one.peak.Load(), err)
+	}
+
+	var all concurrency
+	var requests sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		requests.Add(1)
+		go func() {
+			defer requests.Done()
+			group, _ := pool.group(context.Background())
+			for i := 0; i < 4; i++ {
+				group.Go(all.fetch)
+			}
+			group.Wait()
+		}()
+	}
+	requests.Wait()
+	if peak := all.peak.Load(); peak > 3 {
+		t.Errorf("requests ran %d fetches at once, want at most the pool's 3", peak)
+	}
+}
+
+func TestFetchGroup_FirstError(t *testing.T) {
+	first := errors.New("first")
+	for _, pool := range []*workerPool{newWorkerPool(4, 4), nil} {
+		group, _ := pool.group(context.Background())
+		group.Go(func() error { return first })
+		group.Go(func() error {
+			time.Sleep(10 * time.Millisecond)
+			return errors.New("second")
+		})
+		group.Go(func() error { return nil })
+		if err := group.Wait(); err != first {
+			t.Errorf("Wait() = %v, want the first error", err)
+		}
+	}
+}
+
+func TestNewWorkerPoolFromEnv(t *testing.T) {
+	t.Setenv("WHOOP_FETCH_WORKERS", "")
+	t.Setenv("WHOOP_FETCHES_PER_REQUEST", "")
+	pool, err := NewWorkerPoolFromEnv(os.Getenv)
+	if err != nil || cap(pool.workers) != defaultFetchWorkers || pool.perRequest != defaultFetchesPerRequest {
+		t.Errorf("default pool = %+v, %v", pool, err)
+	}
+	t.Setenv("WHOOP_FETCHES_PER_REQUEST", "0")
+	if _, err := NewWorkerPoolFromEnv(os.Getenv); err == nil {
+		t.Error("expected WHOOP_FETCHES_PER_REQUEST=0 to fail")
+	}
+}
+
+func TestFetchGroup_CancelsOnFirstError(t *testing.T) {
+	group, ctx := newWorkerPool(4, 4).group(context.Background())
+	failure := errors.New("quota exhausted")
+	group.Go(func() error { return failure })
+	group.Go(func() error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := group.Wait(); err != failure {
+		t.Errorf("Wait() = %v, want the first failure", err)
+	}
+	if !group.stopped(context.Canceled) || group.stopped(failure) {
+		t.Error("Expected only cancellation to count as stopping")
+	}
+
+	request, cancel := context.WithCancel(context.Background())
+	group, _ = newWorkerPool(4, 4).group(request)
+	cancel()
+	if group.stopped(context.Canceled) {
+		t.Error("Expected a canceled request not to count as another fetch failing")
+	}
+}
+
+func TestFetchGroup_CancelWhileWaitingForWorker(t *testing.T) {
+	pool := newWorkerPool(1, 1)
+	busy, release := make(chan struct{}), make(chan struct{})
+	holder, _ := pool.group(context.Background())
+	holder.Go(func() error {
+		close(busy)
+		<-release
+		return nil
+	})
+	<-busy
+
+	request, cancel := context.WithCancel(context.Background())
+	group, _ := pool.group(request)
+	var ran atomic.Bool
+	group.Go(func() error {
+		ran.Store(true)
+		return nil
+	})
+	cancel()
+	done := make(chan error, 1)
+	go func() { done <- group.Wait() }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) || ran.Load() {
+			t.Errorf("Wait() = %v (fetch ran: %v), want the cancellation without running", err, ran.Load())
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected a canceled request to stop waiting for a worker")
+	}
+
+	close(release)
+	if err := holder.Wait(); err != nil {
+		t.Errorf("holder Wait() = %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// kilojoulesPerKcal converts Whoop's energy expenditure to dietary calories
+const kilojoulesPerKcal = 4.184
+
+// WorkoutDetail is one workout as reported to the user
+type WorkoutDetail struct {
+	ID              string    `json:"id"`
+	Start           time.Time `json:"start"`
+	Sport           string    `json:"sport"`
+	DurationMinutes float64   `json:"duration_minutes"`
+	Strain          float64   `json:"strain"`
+	AverageHR       int       `json:"average_hr"`
+	MaxHR           int       `json:"max_hr"`
+	Calories        float64   `json:"calories"`
+	DistanceKm      float64   `json:"distance_km,omitempty"`
+	Scored          bool      `json:"scored"`
+}
+
+// SportSummary aggregates every workout of one sport
+type SportSummary struct {
+	Sport           string  `json:"sport"`
+	Workouts        int     `json:"workouts"`
+	TotalMinutes    float64 `json:"total_minutes"`
+	AverageMinutes  float64 `json:"average_minutes"`
+	AverageStrain   float64 `json:"average_strain"`
+	AverageHR       float64 `json:"average_hr"`
+	PeakHR          int     `json:"peak_hr"`
+	TotalCalories   float64 `json:"total_calories"`
+	TotalDistanceKm float64 `json:"total_distance_km,omitempty"`
+	// PaceMinPerKm is only set for sports with recorded distance
+	PaceMinPerKm float64 `json:"pace_min_per_km,omitempty"`
+}
+
+// WorkoutDetails lists workouts in a range with per-sport aggregates
+type WorkoutDetails struct {
+	Workouts []WorkoutDetail `json:"workouts"` // chronological
+	Sports   []SportSummary  `json:"sports"`   // most sessions first
+}
+
+// BuildWorkoutDetails converts workouts to detail rows and per-sport aggregates.
+// A non-empty sport filter keeps only workouts whose sport name contains it.
+func (h *HealthAnalyzer) BuildWorkoutDetails(workouts []WhoopWorkout, sport string) WorkoutDetails {
+	details := WorkoutDetails{Workouts: []WorkoutDetail{}, Sports: []SportSummary{}}
+	filter := strings.ToLower(strings.TrimSpace(sport))
+
+	bySport := make(map[string]*SportSummary)
+	hrTotals := make(map[string]float64)
+	hrCounts := make(map[string]int)
+	strainTotals := make(map[string]float64)
+	strainCounts := make(map[string]int)
+	paceMinutes := make(map[string]float64)
+
+	for _, workout := range workouts {
+		name := h.sports.SportFor(workout)
+		if filter != "" && !strings.Contains(strings.ToLower(name), filter) {
+			continue
+		}
+
+		detail := WorkoutDetail{
+			ID:              workout.ID,
+			Start:           workout.Start,
+			Sport:           name,
+			DurationMinutes: workout.End.Sub(workout.Start).Minutes(),
+			Scored:          workout.ScoreState == "" || workout.ScoreState == "SCORED",
+		}
+		if detail.Scored {
+			detail.Strain = workout.Score.Strain
+			detail.AverageHR = workout.Score.AverageHeartRate
+			detail.MaxHR = workout.Score.MaxHeartRate
+			detail.Calories = kilojoulesToKcal(workout.Score.Kilojoule)
+			detail.DistanceKm = workout.Score.DistanceMeter / 1000
+		}
+		details.Workouts = append(details.Workouts, detail)
+
+		summary, ok := bySport[name]
+		if !ok {
+			summary = &SportSummary{Sport: name}
+			bySport[name] = summary
+		}
+		summary.Workouts++
+		summary.TotalMinutes += detail.DurationMinutes
+		summary.TotalCalories += detail.Calories
+		summary.TotalDistanceKm += detail.DistanceKm
+		if detail.MaxHR > summary.PeakHR {
+			summary.PeakHR = detail.MaxHR
+		}
+		if detail.Scored {
+			strainTotals[name] += detail.Strain
+			strainCounts[name]++
+		}
+		if detail.AverageHR > 0 {
+			hrTotals[name] += float64(detail.AverageHR)
+			hrCounts[name]++
+		}
+		if detail.DistanceKm > 0 {
+			paceMinutes[name] += detail.DurationMinutes
+		}
+	}
+
+	sort.Slice(details.Workouts, func(i, j int) bool {
+		return details.Workouts[i].Start.Before(details.Workouts[j].Start)
+	})
+
+	for name, summary := range bySport {
+		summary.AverageMinutes = summary.TotalMinutes / float64(summary.Workouts)
+		if strainCounts[name] > 0 {
+			summary.AverageStrain = strainTotals[name] / float64(strainCounts[name])
+		}
+		if hrCounts[name] > 0 {
+			summary.AverageHR = hrTotals[name] / float64(hrCounts[name])
+		}
+		if summary.TotalDistanceKm > 0 {
+			summary.PaceMinPerKm = paceMinutes[name] / summary.TotalDistanceKm
+		}
+		details.Sports = append(details.Sports, *summary)
+	}
+	sort.Slice(details.Sports, func(i, j int) bool {
+		if details.Sports[i].Workouts != details.Sports[j].Workouts {
+			return details.Sports[i].Workouts > details.Sports[j].Workouts
+		}
+		return details.Sports[i].Sport < details.Sports[j].Sport
+	})
+
+	return details
+}
+
+// FormatWorkoutDetails renders the per-sport comparison and the workout list
+func (h *HealthAnalyzer) FormatWorkoutDetails(details WorkoutDetails) string {
+	var builder strings.Builder
+	builder.WriteString("# Workout Details\n\n")
+
+	if len(details.Workouts) == 0 {
+		builder.WriteString("No workouts recorded in this period.\n")
+		return builder.String()
+	}
+
+	builder.WriteString("## By Sport\n")
+	builder.WriteString("| Sport | Sessions | Avg Duration | Avg Strain | Avg HR | Peak HR | Calories | Distance |\n")
+	builder.WriteString("|---|---|---|---|---|---|---|---|\n")
+	for _, sport := range details.Sports {
+		distance := "-"
+		if sport.TotalDistanceKm > 0 {
+			unit := h.units.DistanceUnit()
+			distance = fmt.Sprintf("%.1f %s (%s/%s)", h.units.Distance(sport.TotalDistanceKm), unit, formatPace(sport.PaceMinPerKm/h.units.Distance(1)), unit)
+		}
+		builder.WriteString(fmt.Sprintf("| %s | %d | %.0f min | %.1f | %.0f | %d | %.0f | %s |\n",
+			sport.Sport, sport.Workouts, sport.AverageMinutes, sport.AverageStrain, sport.AverageHR, sport.PeakHR, sport.TotalCalories, distance))
+	}
+
+	builder.WriteString("\n## Workouts\n")
+	for _, workout := range details.Workouts {
+		line := fmt.Sprintf("- **%s** %s: %.0f min", workout.Start.Format("Mon Jan 2 15:04"), workout.Sport, workout.DurationMinutes)
+		if !workout.Scored {
+			builder.WriteString(line + " (not scored)\n")
+			continue
+		}
+		line += fmt.Sprintf(", strain %.1f, HR %d avg / %d max, %.0f kcal", workout.Strain, workout.AverageHR, workout.MaxHR, workout.Calories)
+		if workout.DistanceKm > 0 {
+			line += fmt.Sprintf(", %.2f %s", h.units.Distance(workout.DistanceKm), h.units.DistanceUnit())
+		}
+		builder.WriteString(line + "\n")
+	}
+
+	return builder.String()
+}
+
+// formatPace renders minutes per km as m:ss
+func formatPace(minutesPerKm float64) string {
+	seconds := int(minutesPerKm*60 + 0.5)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
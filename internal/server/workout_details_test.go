@@ -0,0 +1,56 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthAnalyzer_BuildWorkoutDetails(t *testing.T) {
+	analyzer := NewHealthAnalyzer()
+	start := time.Date(2024, 7, 1, 7, 0, 0, 0, time.UTC)
+
+	workout := func(day int, sport string, minutes int, strain, kj, meters float64) WhoopWorkout {
+		var w WhoopWorkout
+		w.SportName = sport
+		w.Start = start.AddDate(0, 0, day)
+		w.End = w.Start.Add(time.Duration(minutes) * time.Minute)
+		w.ScoreState = "SCORED"
+		w.Score.Strain = strain
+		w.Score.AverageHeartRate = 140
+		w.Score.MaxHeartRate = 170 + day
+		w.Score.Kilojoule = kj
+		w.Score.DistanceMeter = meters
+		return w
+	}
+
+	workouts := []WhoopWorkout{
+		workout(2, "running", 50, 12, 2092, 10000),
+		workout(0, "running", 30, 10, 1255.2, 5000),
+		workout(1, "weightlifting", 60, 8, 1046, 0),
+	}
+
+	details := analyzer.BuildWorkoutDetails(workouts, "")
+
+	if len(details.Workouts) != 3 || details.Workouts[0].Sport != "Running" || !details.Workouts[0].Start.Equal(start) {
+		t.Fatalf("Expected chronological workouts starting with a run, got %+v", details.Workouts)
+	}
+	if details.Workouts[0].Calories != 300 {
+		t.Errorf("Expected 300 kcal, got %.2f", details.Workouts[0].Calories)
+	}
+
+	running := details.Sports[0]
+	if running.Sport != "Running" || running.Workouts != 2 {
+		t.Fatalf("Expected running first with 2 sessions, got %+v", running)
+	}
+	if running.AverageStrain != 11 || running.PeakHR != 172 || running.TotalDistanceKm != 15 {
+		t.Errorf("Unexpected running aggregates: %+v", running)
+	}
+	if formatPace(running.PaceMinPerKm) != "5:20" {
+		t.Errorf("Expected 5:20/km pace, got %s", formatPace(running.PaceMinPerKm))
+	}
+
+	filtered := analyzer.BuildWorkoutDetails(workouts, "weight")
+	if len(filtered.Workouts) != 1 || filtered.Sports[0].PaceMinPerKm != 0 {
+		t.Errorf("Expected one lifting session without pace, got %+v", filtered)
+	}
+}
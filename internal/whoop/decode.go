@@ -0,0 +1,110 @@
+package whoop
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DecodeError reports a Whoop response that does not fit the models in
+// this package, naming the offending field so API changes are easy to pin down
+type DecodeError struct {
+	What  string // what was being decoded, e.g. "recovery data"
+	Field string // dotted JSON path, e.g. "records.0.score.resting_heart_rate"
+	Got   string // the JSON value received, e.g. "number 55.5"
+	Want  string // the Go type the model expects
+	Err   error
+}
+
+func (e *DecodeError) Error() string {
+	switch {
+	case e.Field != "" && e.Want != "":
+		return fmt.Sprintf("failed to parse %s: field %s: got %s, want %s", e.What, e.Field, e.Got, e.Want)
+	case e.Field != "":
+		return fmt.Sprintf("failed to parse %s: unexpected field %s", e.What, e.Field)
+	}
+	return fmt.Sprintf("failed to parse %s: %v", e.What, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// Decode decodes a Whoop response into v, describing it as what in errors. Whole numbers written as
+// decimals (55.0) are accepted for integer fields; anything else that does not
+// fit, and unknown fields when strict, is returned as a *DecodeError.
+func Decode(data []byte, v any, what string, strict bool) error {
+	err := decodeJSON(data, v, strict)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && strings.HasPrefix(typeErr.Value, "number") {
+		if normalized, ok := integralNumbers(data); ok {
+			target := reflect.ValueOf(v).Elem()
+			target.Set(reflect.Zero(target.Type()))
+			err = decodeJSON(normalized, v, strict)
+		}
+	}
+	if err == nil {
+		return nil
+	}
+
+	decodeErr := &DecodeError{What: what, Err: err}
+	if errors.As(err, &typeErr) {
+		decodeErr.Field = typeErr.Field
+		decodeErr.Got = typeErr.Value
+		decodeErr.Want = typeErr.Type.String()
+	} else if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		decodeErr.Field = strings.Trim(field, `"`)
+	}
+	return decodeErr
+}
+
+// decodeJSON decodes exactly one JSON value from data into v
+func decodeJSON(data []byte, v any, strict bool) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder.Decode(v)
+}
+
+// integralNumbers rewrites numbers with whole values, such as 55.0 or 5.5e1,
+// as integers so they decode into int fields
+func integralNumbers(data []byte) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+	normalized, err := json.Marshal(normalizeNumbers(value))
+	if err != nil {
+		return nil, false
+	}
+	return normalized, true
+}
+
+// normalizeNumbers walks a decoded JSON value, rewriting whole numbers
+func normalizeNumbers(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+	case json.Number:
+		if !strings.ContainsAny(string(v), ".eE") {
+			return v
+		}
+		f, err := v.Float64()
+		if err == nil && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			return json.Number(strconv.FormatInt(int64(f), 10))
+		}
+	}
+	return value
+}
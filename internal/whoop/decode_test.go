@@ -0,0 +1,65 @@
+package whoop
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeWhoopJSON_NumericTolerance(t *testing.T) {
+	body := `{"records": [{"cycle_id": 7, "score": {"resting_heart_rate": 54.5, "recovery_score": 81}},
+		{"cycle_id": 8.0, "score": {"resting_heart_rate": 56}}], "next_token": "abc"}`
+	var response RecoveryResponse
+	if err := Decode([]byte(body), &response, "recovery data", false); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(response.Data) != 2 || response.Data[1].CycleID != 8 {
+		t.Fatalf("Expected two records with 8.0 decoded as 8, got %+v", response.Data)
+	}
+	if response.Data[0].Score.RestingHeartRate != 54.5 || response.Data[1].Score.RestingHeartRate != 56 {
+		t.Errorf("Expected fractional and whole resting heart rates, got %v and %v",
+			response.Data[0].Score.RestingHeartRate, response.Data[1].Score.RestingHeartRate)
+	}
+	if response.NextToken == nil || *response.NextToken != "abc" {
+		t.Errorf("Expected the next token to survive the retry, got %v", response.NextToken)
+	}
+
+	var sleeps SleepResponse
+	if err := Decode([]byte(`{"records": [{"score": {"stage_summary": {"disturbance_count": 1.2e1}}}]}`), &sleeps, "sleep data", false); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got := sleeps.Data[0].Score.StageSummary.DisturbanceCount; got != 12 {
+		t.Errorf("DisturbanceCount = %d, want 12", got)
+	}
+}
+
+func TestDecodeWhoopJSON_NamesTheField(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		strict bool
+		want   string
+	}{
+		{"fraction in an integer field", `{"records": [{"score": {"max_heart_rate": 171.5}}]}`, false, "score.max_heart_rate: got number 171.5, want int"},
+		{"string in a number field", `{"records": [{"score": {"strain": "high"}}]}`, false, "score.strain: got string, want float64"},
+		{"unknown field when strict", `{"records": [{"score": {"strain": 9, "mood": 3}}]}`, true, "unexpected field mood"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response CycleResponse
+			err := Decode([]byte(tt.body), &response, "cycle data", tt.strict)
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("Expected a *DecodeError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) || !strings.HasPrefix(err.Error(), "failed to parse cycle data") {
+				t.Errorf("Error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+
+	var response CycleResponse
+	if err := Decode([]byte(`{"records": [{"score": {"strain": 9, "mood": 3}}]}`), &response, "cycle data", false); err != nil {
+		t.Errorf("Expected unknown fields to be ignored when not strict, got %v", err)
+	}
+}
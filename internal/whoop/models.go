@@ -0,0 +1,197 @@
+// Package whoop holds the Whoop API v2 data models and decodes API responses
+// into them. It has no client: internal/server fetches the records.
+package whoop
+
+import (
+	"strconv"
+	"time"
+)
+
+// APIBaseURL is the production Whoop developer API
+const APIBaseURL = "https://api.prod.whoop.com/developer"
+
+// User is the basic profile of the authenticated account
+type User struct {
+	UserID    int    `json:"user_id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// Recovery is the morning recovery scored for a physiological cycle
+type Recovery struct {
+	CycleID    int64         `json:"cycle_id"`
+	SleepID    string        `json:"sleep_id"` // UUID in V2
+	UserID     int64         `json:"user_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	ScoreState string        `json:"score_state"`
+	Score      RecoveryScore `json:"score"`
+}
+
+// Sleep is one sleep or nap
+type Sleep struct {
+	ID             string     `json:"id"`              // UUID in V2
+	V1ID           *int64     `json:"v1_id,omitempty"` // Legacy ID for migration
+	UserID         int64      `json:"user_id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	Start          time.Time  `json:"start"`
+	End            time.Time  `json:"end"`
+	TimezoneOffset string     `json:"timezone_offset"`
+	Nap            bool       `json:"nap"`
+	ScoreState     string     `json:"score_state"`
+	Score          SleepScore `json:"score"`
+}
+
+// Workout is one recorded activity
+type Workout struct {
+	ID             string       `json:"id"`              // UUID in V2
+	V1ID           *int64       `json:"v1_id,omitempty"` // Legacy ID for migration
+	UserID         int64        `json:"user_id"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	Start          time.Time    `json:"start"`
+	End            time.Time    `json:"end"`
+	TimezoneOffset string       `json:"timezone_offset"`
+	SportName      string       `json:"sport_name"`
+	SportID        *int         `json:"sport_id,omitempty"` // Legacy field
+	ScoreState     string       `json:"score_state"`
+	Score          WorkoutScore `json:"score"`
+}
+
+// Cycle is a physiological day, from waking to waking. The current
+// cycle has no End yet.
+type Cycle struct {
+	ID             int64      `json:"id"` // Still integer ID in V2
+	UserID         int64      `json:"user_id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	Start          time.Time  `json:"start"`
+	End            time.Time  `json:"end"`
+	TimezoneOffset string     `json:"timezone_offset"`
+	ScoreState     string     `json:"score_state"`
+	Score          CycleScore `json:"score"`
+}
+
+// RecoveryScore is the scored part of a recovery. Every measurement is
+// fractional in the API, resting heart rate included.
+type RecoveryScore struct {
+	UserCalibrating  bool    `json:"user_calibrating"`
+	RecoveryScore    float64 `json:"recovery_score"`
+	RestingHeartRate float64 `json:"resting_heart_rate"`
+	HRVRmssd         float64 `json:"hrv_rmssd_milli"`
+	SkinTempCelsius  float64 `json:"skin_temp_celsius"`
+	SpO2Percentage   float64 `json:"spo2_percentage"`
+}
+
+// SleepScore is the scored part of a sleep
+type SleepScore struct {
+	StageSummary               SleepStageSummary `json:"stage_summary"`
+	SleepNeeded                SleepNeeded       `json:"sleep_needed"`
+	RespiratoryRate            float64           `json:"respiratory_rate"`
+	SleepPerformancePercentage float64           `json:"sleep_performance_percentage"`
+	SleepConsistencyPercentage float64           `json:"sleep_consistency_percentage"`
+	SleepEfficiencyPercentage  float64           `json:"sleep_efficiency_percentage"`
+}
+
+// SleepStageSummary splits time in bed by sleep stage
+type SleepStageSummary struct {
+	TotalInBedTimeMilli         int `json:"total_in_bed_time_milli"`
+	TotalAwakeTimeMilli         int `json:"total_awake_time_milli"`
+	TotalNoDataTimeMilli        int `json:"total_no_data_time_milli"`
+	TotalLightSleepTimeMilli    int `json:"total_light_sleep_time_milli"`
+	TotalSlowWaveSleepTimeMilli int `json:"total_slow_wave_sleep_time_milli"`
+	TotalRemSleepTimeMilli      int `json:"total_rem_sleep_time_milli"`
+	SleepCycleCount             int `json:"sleep_cycle_count"`
+	DisturbanceCount            int `json:"disturbance_count"`
+}
+
+// SleepNeeded breaks down how much sleep Whoop recommended
+type SleepNeeded struct {
+	BaselineMilli             int `json:"baseline_milli"`
+	NeedFromSleepDebtMilli    int `json:"need_from_sleep_debt_milli"`
+	NeedFromRecentStrainMilli int `json:"need_from_recent_strain_milli"`
+	NeedFromRecentNapMilli    int `json:"need_from_recent_nap_milli"`
+}
+
+// WorkoutScore is the scored part of a workout
+type WorkoutScore struct {
+	Strain              float64       `json:"strain"`
+	AverageHeartRate    int           `json:"average_heart_rate"`
+	MaxHeartRate        int           `json:"max_heart_rate"`
+	Kilojoule           float64       `json:"kilojoule"`
+	PercentRecorded     float64       `json:"percent_recorded"`
+	DistanceMeter       float64       `json:"distance_meter"`
+	AltitudeGainMeter   float64       `json:"altitude_gain_meter"`
+	AltitudeChangeMeter float64       `json:"altitude_change_meter"`
+	ZoneDurations       ZoneDurations `json:"zone_durations"`
+}
+
+// ZoneDurations is time spent in each heart rate zone
+type ZoneDurations struct {
+	ZoneZeroMilli  int `json:"zone_zero_milli"`
+	ZoneOneMilli   int `json:"zone_one_milli"`
+	ZoneTwoMilli   int `json:"zone_two_milli"`
+	ZoneThreeMilli int `json:"zone_three_milli"`
+	ZoneFourMilli  int `json:"zone_four_milli"`
+	ZoneFiveMilli  int `json:"zone_five_milli"`
+}
+
+// CycleScore is the day strain and energy of a cycle
+type CycleScore struct {
+	Strain           float64 `json:"strain"`
+	Kilojoule        float64 `json:"kilojoule"`
+	AverageHeartRate int     `json:"average_heart_rate"`
+	MaxHeartRate     int     `json:"max_heart_rate"`
+}
+
+// Page is one page of a paginated collection
+type Page[T any] struct {
+	Data      []T     `json:"records"`
+	NextToken *string `json:"next_token,omitempty"`
+}
+
+// RecoveryResponse is one page of /v2/recovery
+type RecoveryResponse = Page[Recovery]
+
+// SleepResponse is one page of /v2/activity/sleep
+type SleepResponse = Page[Sleep]
+
+// WorkoutResponse is one page of /v2/activity/workout
+type WorkoutResponse = Page[Workout]
+
+// CycleResponse is one page of /v2/cycle
+type CycleResponse = Page[Cycle]
+
+// Record is a paginated Whoop record: what identifies it across pages, and
+// when Whoop last changed it
+type Record interface {
+	RecordID() string
+	LastUpdated() time.Time
+}
+
+// RecordID returns the cycle the recovery was scored for; each cycle has at
+// most one recovery
+func (r Recovery) RecordID() string { return strconv.FormatInt(r.CycleID, 10) }
+
+// LastUpdated returns updated_at
+func (r Recovery) LastUpdated() time.Time { return r.UpdatedAt }
+
+// RecordID returns the sleep's UUID
+func (s Sleep) RecordID() string { return s.ID }
+
+// LastUpdated returns updated_at
+func (s Sleep) LastUpdated() time.Time { return s.UpdatedAt }
+
+// RecordID returns the workout's UUID
+func (w Workout) RecordID() string { return w.ID }
+
+// LastUpdated returns updated_at
+func (w Workout) LastUpdated() time.Time { return w.UpdatedAt }
+
+// RecordID returns the cycle's ID
+func (c Cycle) RecordID() string { return strconv.FormatInt(c.ID, 10) }
+
+// LastUpdated returns updated_at
+func (c Cycle) LastUpdated() time.Time { return c.UpdatedAt }
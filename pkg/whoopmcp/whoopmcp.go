@@ -0,0 +1,169 @@
+// Package whoopmcp embeds the Whoop MCP server in another Go program: serve it
+// on its own over stdio or HTTP, mount its HTTP handler on an existing mux, or
+// list and call its tools from a host that speaks MCP itself.
+//
+// The server resolves its settings as the whoop-mcp-server command does, from
+// the config file and WHOOP_* environment variables. Options layer their
+// settings over those in the server's own configuration and never change the
+// process environment.
+package whoopmcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+
+	"whoop-mcp/internal/config"
+	"whoop-mcp/internal/mcp"
+	"whoop-mcp/internal/server"
+)
+
+// Tool describes one of the server's tools, as tools/list reports it
+type Tool = mcp.Tool
+
+// Content is one block of a tool result, such as {"type": "text", "text": ...}
+type Content = map[string]interface{}
+
+// ToolHandler runs a tool call and returns its content
+type ToolHandler = server.ToolHandler
+
+// Middleware wraps every tool call; see Server.Use
+type Middleware = server.ToolMiddleware
+
+// Server is an embedded Whoop MCP server
+type Server struct {
+	server *server.MCPServer
+}
+
+type options struct {
+	configFile string
+	settings   map[string]string
+	readOnly   bool
+}
+
+// Option configures NewServer
+type Option func(*options)
+
+// WithConfigFile reads settings from a config file instead of WHOOP_CONFIG or
+// the default location
+func WithConfigFile(path string) Option {
+	return func(o *options) { o.configFile = path }
+}
+
+// WithSetting sets the WHOOP_* setting name for this server, overriding the
+// config file and the environment
+func WithSetting(name, value string) Option {
+	return func(o *options) { o.settings[name] = value }
+}
+
+// WithOffline serves fixture data instead of calling the Whoop API: the named
+// generated scenario, or the bundled demo data when scenario is empty
+func WithOffline(scenario string) Option {
+	return func(o *options) {
+		o.settings["WHOOP_OFFLINE"] = "true"
+		if scenario != "" {
+			o.settings["WHOOP_OFFLINE_SCENARIO"] = scenario
+		}
+	}
+}
+
+// WithReadOnly drops the tools that write tokens, journals, goals, or files
+func WithReadOnly() Option {
+	return func(o *options) { o.readOnly = true }
+}
+
+// NewServer resolves the configuration and creates a server
+func NewServer(opts ...Option) (*Server, error) {
+	o := &options{settings: make(map[string]string)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	resolved, err := config.Resolve(o.configFile, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	names := make([]string, 0, len(o.settings))
+	for name := range o.settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := resolved.Set(name, o.settings[name]); err != nil {
+			return nil, fmt.Errorf("invalid configuration: %w", err)
+		}
+	}
+
+	mcpServer, err := server.NewMCPServer(resolved.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	if o.readOnly {
+		if err := mcpServer.SetMode("readonly"); err != nil {
+			return nil, err
+		}
+	}
+	return &Server{server: mcpServer}, nil
+}
+
+// ServeStdio serves MCP on the process's stdin and stdout until stdin closes
+// or ctx is done
+func (s *Server) ServeStdio(ctx context.Context) error {
+	return s.server.Serve(ctx, os.Stdin, os.Stdout)
+}
+
+// Serve serves newline-delimited JSON-RPC from in to out until in closes or
+// ctx is done
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	return s.server.Serve(ctx, in, out)
+}
+
+// ServeHTTP listens on addr and serves MCP over HTTP until ctx is done. Each
+// POST carries one JSON-RPC message. There is no authentication, so bind to
+// a loopback address unless something in front of it authenticates clients.
+func (s *Server) ServeHTTP(ctx context.Context, addr string) error {
+	return s.server.ListenAndServe(ctx, addr)
+}
+
+// Handler returns the HTTP handler ServeHTTP serves, for mounting on a mux
+func (s *Server) Handler() http.Handler {
+	return s.server.HTTPHandler()
+}
+
+// HandleMessage answers one JSON-RPC message, returning the encoded response,
+// or nil when none is due. Its trace spans nest under any span ctx carries.
+func (s *Server) HandleMessage(ctx context.Context, message []byte) ([]byte, error) {
+	response := s.server.HandleMessage(ctx, message)
+	if response == nil {
+		return nil, nil
+	}
+	return json.Marshal(response)
+}
+
+// Tools lists the server's tools
+func (s *Server) Tools() []Tool {
+	return s.server.Tools()
+}
+
+// CallTool runs a tool with JSON arguments and returns its content
+func (s *Server) CallTool(ctx context.Context, name string, arguments json.RawMessage) ([]Content, error) {
+	return s.server.CallTool(ctx, name, arguments)
+}
+
+// Close flushes the trace spans the server still holds to the collector and
+// stops its exporter, giving up when ctx is done. Call it once the server has
+// stopped serving.
+func (s *Server) Close(ctx context.Context) error {
+	return s.server.Close(ctx)
+}
+
+// Use adds middleware around every tool call, whether it arrives over MCP or
+// through CallTool. Middleware runs in registration order, after the
+// server's own redaction, auditing, read-only, and rate limit checks.
+func (s *Server) Use(middlewares ...Middleware) {
+	s.server.Use(middlewares...)
+}
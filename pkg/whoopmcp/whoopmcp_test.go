@@ -0,0 +1,167 @@
+package whoopmcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newOfflineServer builds a server over generated data in a scratch home
+func newOfflineServer(t *testing.T, opts ...Option) *Server {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("WHOOP_CONFIG", "")
+	t.Setenv("WHOOP_DATA_DIR", filepath.Join(home, "data"))
+	t.Setenv("WHOOP_OFFLINE", "")
+	t.Setenv("WHOOP_OFFLINE_SCENARIO", "")
+	server, err := NewServer(append([]Option{WithOffline("steady")}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	return server
+}
+
+func TestServer_ToolsAndCallTool(t *testing.T) {
+	server := newOfflineServer(t)
+
+	names := make(map[string]bool)
+	for _, tool := range server.Tools() {
+		names[tool.Name] = true
+	}
+	if !names["get_health_summary"] || !names["set_goal"] {
+		t.Errorf("Expected the full tool set, got %v", names)
+	}
+
+	content, err := server.CallTool(context.Background(), "get_health_summary", json.RawMessage(`{"start_date": "past 14 days", "end_date": "today"}`))
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if len(content) == 0 || !strings.Contains(content[0]["text"].(string), "Health Summary") {
+		t.Errorf("Expected a health summary, got %v", content)
+	}
+	if _, err := server.CallTool(context.Background(), "no_such_tool", nil); err == nil {
+		t.Error("Expected an unknown tool to fail")
+	}
+}
+
+func TestNewServer_LeavesEnvironment(t *testing.T) {
+	newOfflineServer(t, WithSetting("WHOOP_UNITS", "imperial"))
+	for _, name := range []string{"WHOOP_OFFLINE", "WHOOP_OFFLINE_SCENARIO", "WHOOP_UNITS"} {
+		if value := os.Getenv(name); value != "" {
+			t.Errorf("%s = %q, want options kept out of the process environment", name, value)
+		}
+	}
+
+	if _, err := NewServer(WithSetting("WHOOP_RATE_LIMIT", "fast")); err == nil {
+		t.Error("Expected an invalid setting to be rejected")
+	}
+}
+
+func TestServer_ReadOnly(t *testing.T) {
+	server := newOfflineServer(t, WithReadOnly())
+	for _, tool := range server.Tools() {
+		if tool.Name == "set_goal" {
+			t.Error("Expected read-only mode to drop set_goal")
+		}
+	}
+}
+
+func TestServer_Use(t *testing.T) {
+	server := newOfflineServer(t)
+	var seen []string
+	server.Use(func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, name string, arguments json.RawMessage) ([]Content, error) {
+			seen = append(seen, name)
+			if name == "export_data" {
+				return nil, errors.New("exports are disabled here")
+			}
+			return next(ctx, name, arguments)
+		}
+	})
+
+	if _, err := server.CallTool(context.Background(), "export_data", json.RawMessage(`{"start_date": "past 7 days"}`)); err == nil || !strings.Contains(err.Error(), "disabled here") {
+		t.Errorf("CallTool(export_data) error = %v, want the middleware's refusal", err)
+	}
+	if _, err := server.CallTool(context.Background(), "get_health_summary", json.RawMessage(`{"start_date": "past 7 days", "end_date": "today"}`)); err != nil {
+		t.Errorf("CallTool(get_health_summary) error = %v", err)
+	}
+	if strings.Join(seen, ",") != "export_data,get_health_summary" {
+		t.Errorf("middleware saw %v", seen)
+	}
+}
+
+func TestServer_Serve(t *testing.T) {
+	server := newOfflineServer(t)
+	in := strings.NewReader(`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {}}` + "\n" +
+		`{"jsonrpc": "2.0", "id": 2, "method": "tools/list"}` + "\n")
+	var out bytes.Buffer
+	if err := server.Serve(context.Background(), in, &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"id":2`) || !strings.Contains(lines[1], "get_health_summary") {
+		t.Errorf("Expected initialize and tools/list responses, got:\n%s", out.String())
+	}
+}
+
+func TestServer_Handler(t *testing.T) {
+	server := newOfflineServer(t)
+	api := httptest.NewServer(server.Handler())
+	defer api.Close()
+
+	post := func(body string, header http.Header) (*http.Response, string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, api.URL, strings.NewReader(body))
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST error = %v", err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp, string(data)
+	}
+
+	if resp, body := post(`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {}}`, nil); resp.StatusCode != http.StatusOK || !strings.Contains(body, "whoop-mcp-server") {
+		t.Errorf("initialize = %d %s", resp.StatusCode, body)
+	}
+	if resp, _ := post(`{"jsonrpc": "2.0", "method": "notifications/initialized"}`, nil); resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Notification status = %d, want 202", resp.StatusCode)
+	}
+	if resp, _ := post(`{"jsonrpc": "2.0", "id": 2, "method": "tools/list"}`, http.Header{"Origin": {"https://evil.example"}}); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Cross-origin status = %d, want 403", resp.StatusCode)
+	}
+	if resp, err := http.Get(api.URL); err != nil || resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET = %v, %v; want 405", resp, err)
+	}
+}
+
+func TestServer_ServeHTTPStopsWithContext(t *testing.T) {
+	server := newOfflineServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.ServeHTTP(ctx, "127.0.0.1:0") }()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("ServeHTTP() error = %v, want a clean shutdown", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected ServeHTTP to return when the context is done")
+	}
+}